@@ -3,20 +3,47 @@ package main
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"sort"
+	"strings"
 	"syscall"
+	"time"
 
+	auditquery "github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/audit/query"
 	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/inventory/command"
 	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/inventory/query"
+	outboxcommand "github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/outbox/command"
+	outboxquery "github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/outbox/query"
 	productcommand "github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/product/command"
 	productquery "github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/product/query"
+	webhookcommand "github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/webhook/command"
+	webhookquery "github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/webhook/query"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/event"
+	inventorydomain "github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	productdomain "github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/cache"
 	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/config"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/database"
 	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/delivery"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/memory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/messaging"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/migration"
 	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/persistence"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/sqlite"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/timeout"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/bus"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/eventbus"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/idempotency"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/outbox"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/webhook"
 	"github.com/gin-gonic/gin"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
 )
 
 func main() {
@@ -26,68 +53,161 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Initialize database connection
-	db, err := initDatabase(cfg)
+	// Schema migrations run before the schema check, so they work on an outdated database
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(context.Background(), cfg, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
+	// Initialize repositories (CQRS: separate command and query repositories)
+	repos, closeStorage, err := initRepositories(cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		log.Fatalf("Failed to initialize storage: %v", err)
 	}
-	defer db.Close()
+	defer closeStorage()
 
-	log.Println("Database connection established")
+	// Administrative commands run against the configured storage and exit
+	if len(os.Args) > 1 {
+		if err := runCommand(context.Background(), cfg, repos, os.Args[1:]); err != nil {
+			closeStorage()
+			log.Fatalf("Command failed: %v", err)
+		}
+		return
+	}
 
-	// Initialize repositories (CQRS: separate command and query repositories)
-	productCmdRepo := persistence.NewProductCommandRepository(db)
-	productQueryRepo := persistence.NewProductQueryRepository(db)
-	inventoryCmdRepo := persistence.NewInventoryCommandRepository(db)
-	inventoryQueryRepo := persistence.NewInventoryQueryRepository(db)
+	productQueryRepo := repos.productQueries
+	inventoryQueryRepo := repos.inventoryQueries
+	stocktakeQueryRepo := repos.stocktakeQueries
+	valuationCmdRepo := repos.valuationCommands
+	valuationQueryRepo := repos.valuationQueries
+	idempotencyStore := repos.idempotency
+
+	// Every product and inventory command is recorded in the audit trail in its own transaction
+	auditRecorder := audit.NewRecorder(repos.audit)
+
+	// Domain events raised by products and inventory are dispatched once their changes are stored
+	eventDispatcher := eventbus.NewDispatcher()
+	eventDispatcher.SubscribeAsync(eventbus.AllEvents, logEvent)
 
-	// STEP 1: Initialize product queries (without inventory integration first)
-	getProductQueryBasic := productquery.NewGetProductQuery(productQueryRepo)
+	// Costing method for newly valued products
+	costingMethod, err := inventorydomain.ParseCostingMethod(cfg.Inventory.CostingMethod)
+	if err != nil {
+		log.Fatalf("Invalid inventory configuration: %v", err)
+	}
+	stockValuator := command.NewStockValuator(valuationCmdRepo, valuationQueryRepo, costingMethod)
+
+	// Units of work for commands spanning several writes, including their audit entries
+	unitOfWork := repos.unitOfWork
+	productUnitOfWork := repos.productUnitOfWork
 
-	// STEP 2: Create adapter for Inventory → Product communication
-	productQueryAdapter := query.NewProductQueryAdapter(getProductQueryBasic)
+	// Modules talk to each other through the module bus: each module answers its own contract
+	// messages, and asks the other module's without knowing how that module is built
+	moduleBus := bus.New()
+	productquery.RegisterContractHandlers(moduleBus, productQueryRepo)
+	query.RegisterContractHandlers(moduleBus, inventoryQueryRepo)
 
-	// STEP 3: Initialize inventory commands and queries with product query adapter injection
+	// STEP 1: Create adapters for Inventory → Product communication
+	productQueryAdapter := query.NewProductQueryAdapter(moduleBus)
+	productBatchQueryAdapter := query.NewProductBatchQueryAdapter(moduleBus)
+
+	// STEP 2: Initialize inventory commands and queries with product query adapter injection
 	// This demonstrates Inventory → Product module communication
 	createInventoryCommand := command.NewCreateInventoryCommand(
-		inventoryCmdRepo,
-		inventoryQueryRepo,
+		unitOfWork,
 		productQueryAdapter,
+		stockValuator,
+		auditRecorder,
 	)
 	getInventoryQuery := query.NewGetInventoryQuery(
 		inventoryQueryRepo,
 		productQueryAdapter,
 	)
-	adjustInventoryCommand := command.NewAdjustInventoryCommand(
-		inventoryCmdRepo,
+	listInventoryQuery := query.NewListInventoryQuery(
 		inventoryQueryRepo,
+		productBatchQueryAdapter,
+	)
+	adjustInventoryCommand := command.NewAdjustInventoryCommand(
+		unitOfWork,
 		productQueryAdapter,
+		stockValuator,
+		auditRecorder,
+		eventDispatcher,
+	)
+	batchAdjustInventoryCommand := command.NewBatchAdjustInventoryCommand(
+		unitOfWork,
+		productBatchQueryAdapter,
+		stockValuator,
+		auditRecorder,
+		eventDispatcher,
 	)
+	receiveStockCommand := command.NewReceiveStockCommand(adjustInventoryCommand)
+	getValuationReportQuery := query.NewGetValuationReportQuery(valuationQueryRepo, productBatchQueryAdapter)
 
-	// STEP 4: Create adapter for Product → Inventory communication
-	// Wrap GetInventoryQuery.Execute to match the function signature expected by ProductInventoryAdapter
-	inventoryAdapterFunc := func(ctx context.Context, productID string) (*productquery.InventoryOutput, error) {
-		output, err := getInventoryQuery.Execute(ctx, productID)
-		if err != nil {
-			return nil, err
-		}
-		return &productquery.InventoryOutput{
-			Quantity:          output.Quantity,
-			AvailableQuantity: output.AvailableQuantity,
-		}, nil
-	}
-	inventoryAdapter := productquery.NewProductInventoryAdapter(inventoryAdapterFunc)
+	reserveInventoryCommand := command.NewReserveInventoryCommand(unitOfWork, auditRecorder, eventDispatcher)
+	releaseInventoryCommand := command.NewReleaseInventoryCommand(unitOfWork, auditRecorder, eventDispatcher)
+	setStockPolicyCommand := command.NewSetStockPolicyCommand(unitOfWork, auditRecorder)
+	updateInventoryCommand := command.NewUpdateInventoryCommand(unitOfWork, auditRecorder)
+	deleteInventoryCommand := command.NewDeleteInventoryCommand(unitOfWork, stockValuator, auditRecorder)
+
+	// Initialize stocktake (cycle count) commands and queries
+	openStocktakeCommand := command.NewOpenStocktakeCommand(unitOfWork, auditRecorder)
+	recordStocktakeCountsCommand := command.NewRecordStocktakeCountsCommand(unitOfWork, auditRecorder)
+	approveStocktakeCommand := command.NewApproveStocktakeCommand(unitOfWork, auditRecorder)
+	applyStocktakeCommand := command.NewApplyStocktakeCommand(unitOfWork, stockValuator, auditRecorder, eventDispatcher)
+	cancelStocktakeCommand := command.NewCancelStocktakeCommand(unitOfWork, auditRecorder)
+	getStocktakeQuery := query.NewGetStocktakeQuery(stocktakeQueryRepo)
+
+	// STEP 3: Product reads come from the product catalog read model
+	// It holds product and stock data together, so a product read costs one lookup instead of
+	// asking the Inventory module for a GetStockLevel message on the module bus
+	getProductQuery := productquery.NewGetProductQueryWithCatalog(repos.catalogQueries)
 
-	// STEP 5: Re-initialize product query WITH inventory integration
-	// This demonstrates Product → Inventory bidirectional module communication
-	getProductQuery := productquery.NewGetProductQueryWithInventory(productQueryRepo, inventoryAdapter)
+	// STEP 4: Product lists read stock levels for a whole page through the Inventory module
+	inventoryBatchQueryAdapter := productquery.NewProductInventoryBatchAdapter(moduleBus)
+	listProductsQuery := productquery.NewListProductsQuery(productQueryRepo, inventoryBatchQueryAdapter)
 
 	// Initialize product command
-	createProductCommand := productcommand.NewCreateProductCommand(productCmdRepo)
+	createProductCommand := productcommand.NewCreateProductCommand(productUnitOfWork, auditRecorder, eventDispatcher)
 
 	// Initialize handlers
-	productHandler := delivery.NewProductHandler(createProductCommand, getProductQuery)
-	inventoryHandler := delivery.NewInventoryHandler(createInventoryCommand, getInventoryQuery, adjustInventoryCommand)
+	productHandler := delivery.NewProductHandler(createProductCommand, getProductQuery, listProductsQuery)
+	inventoryHandler := delivery.NewInventoryHandler(
+		createInventoryCommand,
+		getInventoryQuery,
+		listInventoryQuery,
+		updateInventoryCommand,
+		deleteInventoryCommand,
+		adjustInventoryCommand,
+		batchAdjustInventoryCommand,
+		reserveInventoryCommand,
+		releaseInventoryCommand,
+		setStockPolicyCommand,
+	)
+	stocktakeHandler := delivery.NewStocktakeHandler(
+		openStocktakeCommand,
+		recordStocktakeCountsCommand,
+		approveStocktakeCommand,
+		applyStocktakeCommand,
+		cancelStocktakeCommand,
+		getStocktakeQuery,
+	)
+	valuationHandler := delivery.NewValuationHandler(receiveStockCommand, getValuationReportQuery)
+	auditHandler := delivery.NewAuditHandler(auditquery.NewListAuditEntriesQuery(repos.audit))
+	outboxHandler := delivery.NewOutboxHandler(
+		outboxquery.NewListDeadLettersQuery(repos.outbox),
+		outboxcommand.NewReplayDeadLetterCommand(repos.outbox),
+	)
+	webhookHandler := delivery.NewWebhookHandler(
+		webhookcommand.NewCreateSubscriptionCommand(repos.webhooks),
+		webhookcommand.NewUpdateSubscriptionCommand(repos.webhooks),
+		webhookcommand.NewDeleteSubscriptionCommand(repos.webhooks),
+		webhookcommand.NewRedeliverCommand(repos.webhooks),
+		webhookquery.NewGetSubscriptionQuery(repos.webhooks),
+		webhookquery.NewListSubscriptionsQuery(repos.webhooks),
+		webhookquery.NewListDeliveriesQuery(repos.webhooks),
+	)
 
 	// Set Gin mode based on environment
 	if cfg.App.Env == "production" {
@@ -102,9 +222,64 @@ func main() {
 	router.Use(delivery.LoggerMiddleware())
 	router.Use(delivery.ErrorHandlerMiddleware())
 	router.Use(delivery.CORSMiddleware())
+	router.Use(delivery.ReadYourWritesMiddleware(cfg.Database.ReadYourWritesWindow))
+	// The tenant must be known before idempotency keys are looked up, as they are scoped by tenant
+	router.Use(delivery.TenantMiddleware(tenant.NewRegistry(cfg.Tenant.Defaults, cfg.Tenant.Tenants), cfg.Tenant.Required))
+	router.Use(delivery.AuditMiddleware())
+	router.Use(delivery.IdempotencyMiddleware(idempotencyStore, cfg.Idempotency.TTL))
 
 	// Register routes
-	registerRoutes(router, productHandler, inventoryHandler)
+	registerRoutes(router, productHandler, inventoryHandler, stocktakeHandler, valuationHandler, auditHandler, outboxHandler, webhookHandler)
+
+	// The relay publishes the events stored in the outbox alongside the changes that raised them
+	publisher, closePublisher, err := newPublisher(cfg.Outbox)
+	if err != nil {
+		log.Fatalf("Failed to initialize outbox publisher: %v", err)
+	}
+	defer closePublisher()
+	if cfg.Webhook.Enabled {
+		// Webhook subscriptions get every event too, as deliveries the dispatcher sends
+		publisher = messaging.Publishers{publisher, messaging.NewWebhookFanout(repos.webhooks)}
+	}
+	relay := messaging.NewRelay(repos.outbox, publisher, messaging.RelayOptions{
+		PollInterval:   cfg.Outbox.PollInterval,
+		BatchSize:      cfg.Outbox.BatchSize,
+		MaxAttempts:    cfg.Outbox.MaxAttempts,
+		RetryBaseDelay: cfg.Outbox.RetryBaseDelay,
+		RetryMaxDelay:  cfg.Outbox.RetryMaxDelay,
+		Lease:          cfg.Outbox.Lease,
+		PublishTimeout: cfg.Outbox.PublishTimeout,
+		Retention:      cfg.Outbox.Retention,
+	})
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		log.Printf("Starting outbox relay (publisher: %s)", cfg.Outbox.Publisher)
+		relay.Run(relayCtx)
+	}()
+
+	dispatcherCtx, stopDispatcher := context.WithCancel(context.Background())
+	dispatcherDone := make(chan struct{})
+	go func() {
+		defer close(dispatcherDone)
+		if !cfg.Webhook.Enabled {
+			return
+		}
+		dispatcher := messaging.NewWebhookDispatcher(repos.webhooks, nil, messaging.WebhookOptions{
+			PollInterval:   cfg.Webhook.PollInterval,
+			BatchSize:      cfg.Webhook.BatchSize,
+			MaxAttempts:    cfg.Webhook.MaxAttempts,
+			RetryBaseDelay: cfg.Webhook.RetryBaseDelay,
+			RetryMaxDelay:  cfg.Webhook.RetryMaxDelay,
+			Lease:          cfg.Webhook.Lease,
+			Timeout:        cfg.Webhook.Timeout,
+			DisableAfter:   cfg.Webhook.DisableAfter,
+			Retention:      cfg.Webhook.Retention,
+		})
+		log.Println("Starting webhook dispatcher")
+		dispatcher.Run(dispatcherCtx)
+	}()
 
 	// Start server in a goroutine
 	serverAddr := cfg.GetServerAddress()
@@ -121,31 +296,486 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down server...")
+	stopRelay()
+	stopDispatcher()
+	<-relayDone
+	<-dispatcherDone
+	eventDispatcher.Wait()
+}
+
+// logEvent logs a dispatched domain event
+func logEvent(ctx context.Context, e event.Event) error {
+	log.Printf("Domain event %s of %q (tenant: %s)", e.EventName(), e.AggregateID(), tenant.ID(ctx))
+	return nil
+}
+
+// newPublisher builds the publisher the outbox relay publishes events through
+// The returned function closes its connection.
+func newPublisher(cfg config.OutboxConfig) (messaging.Publisher, func(), error) {
+	switch cfg.Publisher {
+	case config.PublisherHTTP:
+		return messaging.NewHTTPPublisher(cfg.HTTPURL, nil), func() {}, nil
+	case config.PublisherNATS:
+		publisher, err := messaging.NewNATSPublisher(cfg.NATSURL, cfg.NATSSubjectPrefix)
+		if err != nil {
+			return nil, nil, err
+		}
+		return publisher, func() { publisher.Close() }, nil
+	case config.PublisherKafka:
+		return messaging.NewKafkaPublisher(cfg.KafkaRESTURL, cfg.KafkaTopic, nil), func() {}, nil
+	default:
+		return messaging.NewLogPublisher(), func() {}, nil
+	}
+}
+
+// repositories groups the storage adapters the application is wired with
+type repositories struct {
+	productCommands   productdomain.ProductCommandRepository
+	productQueries    productdomain.ProductQueryRepository
+	inventoryCommands inventorydomain.InventoryCommandRepository
+	inventoryQueries  inventorydomain.InventoryQueryRepository
+	stocktakeCommands inventorydomain.StocktakeCommandRepository
+	stocktakeQueries  inventorydomain.StocktakeQueryRepository
+	valuationCommands inventorydomain.ValuationCommandRepository
+	valuationQueries  inventorydomain.ValuationQueryRepository
+	catalogQueries    productdomain.CatalogQueryRepository
+	catalogProjection productdomain.CatalogProjection
+	idempotency       idempotency.Store
+	audit             audit.Store
+	outbox            outbox.Store
+	webhooks          webhook.Store
+	unitOfWork        inventorydomain.UnitOfWork
+	productUnitOfWork productdomain.UnitOfWork
+}
+
+// runCommand executes an administrative command given on the command line
+func runCommand(ctx context.Context, cfg *config.Config, repos *repositories, args []string) error {
+	switch strings.Join(args, " ") {
+	case "catalog rebuild":
+		output, err := productcommand.NewRebuildCatalogCommand(repos.catalogProjection).Execute(ctx)
+		if err != nil {
+			return err
+		}
+		log.Printf("Product catalog rebuilt with %d entries", output.Entries)
+		return nil
+	case "audit verify":
+		return verifyAuditTrails(ctx, cfg, repos.audit)
+	default:
+		return fmt.Errorf("unknown command %q (available: catalog rebuild, audit verify, migrate up|down|status|version)", strings.Join(args, " "))
+	}
+}
+
+// verifyAuditTrails checks the hash chain of the audit trail of every configured tenant
+// Each tenant has a chain of its own, so an unknown tenant's trail is only checked when it is configured
+func verifyAuditTrails(ctx context.Context, cfg *config.Config, store audit.Store) error {
+	ids := []string{tenant.DefaultID}
+	for id := range cfg.Tenant.Tenants {
+		if id != tenant.DefaultID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids[1:])
+
+	verify := auditquery.NewVerifyAuditTrailQuery(store)
+	for _, id := range ids {
+		output, err := verify.Execute(tenant.NewContext(ctx, tenant.Tenant{ID: id}))
+		if err != nil {
+			return fmt.Errorf("tenant %q: %w", id, err)
+		}
+		log.Printf("Audit trail of tenant %q verified: %d entries", id, output.Entries)
+	}
+	return nil
+}
+
+// runMigrate applies, rolls back or reports the embedded schema migrations
+// It uses the database configured for the server
+func runMigrate(ctx context.Context, cfg *config.Config, args []string) error {
+	if cfg.Storage.Backend == config.StorageMemory {
+		return fmt.Errorf("migrations need a database; STORAGE=%s has no schema", config.StorageMemory)
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: migrate up|down|status|version")
+	}
+
+	db, err := initMigrationDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := migration.New(db, cfg.Database.Driver)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		results, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		for _, r := range results {
+			log.Printf("Applied %s (%v)", r.Name, r.Duration.Round(time.Millisecond))
+		}
+		if len(results) == 0 {
+			log.Println("Schema is up to date")
+		}
+	case "down":
+		result, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		log.Printf("Rolled back %s (%v)", result.Name, result.Duration.Round(time.Millisecond))
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%-50s %s\n", s.Name, state)
+		}
+	case "version":
+		current, latest, err := migrator.Version(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("database version %d, latest migration %d\n", current, latest)
+	default:
+		return fmt.Errorf("unknown migrate command %q (available: up, down, status, version)", args[0])
+	}
+	return nil
 }
 
-// initDatabase initializes and returns a database connection
+// prepareSchema applies pending migrations when DB_AUTO_MIGRATE is set and
+// refuses to continue if the database schema is older than the application
+func prepareSchema(ctx context.Context, cfg *config.Config) error {
+	db, err := initMigrationDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := migration.New(db, cfg.Database.Driver)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Database.AutoMigrate {
+		results, err := migrator.Up(ctx)
+		if err != nil {
+			return fmt.Errorf("auto-migrate: %w", err)
+		}
+		for _, r := range results {
+			log.Printf("Applied migration %s", r.Name)
+		}
+	}
+	return migrator.EnsureCurrent(ctx)
+}
+
+// initRepositories builds the repositories for the configured storage backend
+// The returned function releases the backend's resources
+func initRepositories(cfg *config.Config) (*repositories, func(), error) {
+	if cfg.Storage.Backend == config.StorageMemory {
+		log.Println("Using in-memory storage; data is lost on shutdown")
+		store := memory.NewDatabase()
+		repos := &repositories{
+			productCommands:   memory.NewProductCommandRepository(store),
+			productQueries:    memory.NewProductQueryRepository(store),
+			inventoryCommands: memory.NewInventoryCommandRepository(store),
+			inventoryQueries:  memory.NewInventoryQueryRepository(store),
+			stocktakeCommands: memory.NewStocktakeCommandRepository(store),
+			stocktakeQueries:  memory.NewStocktakeQueryRepository(store),
+			valuationCommands: memory.NewValuationCommandRepository(store),
+			valuationQueries:  memory.NewValuationQueryRepository(store),
+			catalogQueries:    memory.NewCatalogQueryRepository(store),
+			catalogProjection: memory.NewCatalogProjection(store),
+			idempotency:       memory.NewIdempotencyStore(store),
+			audit:             memory.NewAuditStore(store),
+			outbox:            memory.NewOutboxStore(store),
+			webhooks:          memory.NewWebhookStore(store),
+			unitOfWork:        memory.NewUnitOfWork(store),
+			productUnitOfWork: memory.NewProductUnitOfWork(store),
+		}
+		if cfg.Inventory.EventSourced {
+			repos.inventoryCommands = memory.NewEventSourcedInventoryCommandRepository(store, cfg.Inventory.SnapshotEvery)
+			repos.inventoryQueries = memory.NewEventSourcedInventoryQueryRepository(store)
+			repos.unitOfWork = memory.NewEventSourcedUnitOfWork(store, cfg.Inventory.SnapshotEvery)
+		}
+		return repos, func() {}, nil
+	}
+
+	repos, closeDB, err := initSQLRepositories(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	repos = withQueryTimeout(repos, cfg.Database.QueryTimeout)
+
+	repos, closeCache, err := withCache(repos, cfg.Cache)
+	if err != nil {
+		closeDB()
+		return nil, nil, err
+	}
+	return repos, func() {
+		closeCache()
+		closeDB()
+	}, nil
+}
+
+// initSQLRepositories connects to the configured database and builds its repositories
+// The returned function closes the database connections
+func initSQLRepositories(cfg *config.Config) (*repositories, func(), error) {
+	db, err := initDatabase(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Printf("Database connection established (driver: %s)", cfg.Database.Driver)
+	closeDB := func() { db.Close() }
+
+	if err := prepareSchema(context.Background(), cfg); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	if cfg.Database.Driver == config.DriverSQLite {
+		repos := &repositories{
+			productCommands:   sqlite.NewProductCommandRepository(db),
+			productQueries:    sqlite.NewProductQueryRepository(db),
+			inventoryCommands: sqlite.NewInventoryCommandRepository(db),
+			inventoryQueries:  sqlite.NewInventoryQueryRepository(db),
+			stocktakeCommands: sqlite.NewStocktakeCommandRepository(db),
+			stocktakeQueries:  sqlite.NewStocktakeQueryRepository(db),
+			valuationCommands: sqlite.NewValuationCommandRepository(db),
+			valuationQueries:  sqlite.NewValuationQueryRepository(db),
+			catalogQueries:    sqlite.NewCatalogQueryRepository(db),
+			catalogProjection: sqlite.NewCatalogProjection(db),
+			idempotency:       sqlite.NewIdempotencyStore(db),
+			audit:             sqlite.NewAuditStore(db),
+			outbox:            sqlite.NewOutboxStore(db),
+			webhooks:          sqlite.NewWebhookStore(db),
+			unitOfWork:        sqlite.NewUnitOfWork(db, cfg.Database.TxMaxAttempts),
+			productUnitOfWork: sqlite.NewProductUnitOfWork(db, cfg.Database.TxMaxAttempts),
+		}
+		if cfg.Inventory.EventSourced {
+			repos.inventoryCommands = sqlite.NewEventSourcedInventoryCommandRepository(db, cfg.Inventory.SnapshotEvery)
+			repos.inventoryQueries = sqlite.NewEventSourcedInventoryQueryRepository(db)
+			repos.unitOfWork = sqlite.NewEventSourcedUnitOfWork(db, cfg.Database.TxMaxAttempts, cfg.Inventory.SnapshotEvery)
+		}
+		return repos, closeDB, nil
+	}
+
+	isolation, err := inventorydomain.ParseIsolationLevel(cfg.Database.TxIsolation)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	// Query repositories read through the router so configured replicas take read traffic
+	reads, stopReplicaChecks, err := initReadRouter(cfg, db)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	repos := &repositories{
+		productCommands:   persistence.NewProductCommandRepository(db),
+		productQueries:    persistence.NewProductQueryRepository(reads),
+		inventoryCommands: persistence.NewInventoryCommandRepository(db),
+		inventoryQueries:  persistence.NewInventoryQueryRepository(reads),
+		stocktakeCommands: persistence.NewStocktakeCommandRepository(db),
+		stocktakeQueries:  persistence.NewStocktakeQueryRepository(reads),
+		valuationCommands: persistence.NewValuationCommandRepository(db),
+		valuationQueries:  persistence.NewValuationQueryRepository(reads),
+		catalogQueries:    persistence.NewCatalogQueryRepository(reads),
+		catalogProjection: persistence.NewCatalogProjection(db),
+		idempotency:       persistence.NewIdempotencyStore(db),
+		audit:             persistence.NewAuditStore(db),
+		outbox:            persistence.NewOutboxStore(db),
+		webhooks:          persistence.NewWebhookStore(db),
+		unitOfWork:        persistence.NewUnitOfWork(db, isolation, cfg.Database.TxMaxAttempts),
+		productUnitOfWork: persistence.NewProductUnitOfWork(db, cfg.Database.TxMaxAttempts),
+	}
+	if cfg.Inventory.EventSourced {
+		repos.inventoryCommands = persistence.NewEventSourcedInventoryCommandRepository(db, cfg.Inventory.SnapshotEvery)
+		repos.inventoryQueries = persistence.NewEventSourcedInventoryQueryRepository(reads)
+		repos.unitOfWork = persistence.NewEventSourcedUnitOfWork(db, isolation, cfg.Database.TxMaxAttempts, cfg.Inventory.SnapshotEvery)
+	}
+	return repos, func() {
+		stopReplicaChecks()
+		reads.Close()
+		closeDB()
+	}, nil
+}
+
+// withQueryTimeout bounds every repository call by the configured query timeout
+// The catalog projection is left unbounded, since a rebuild touches every product
+func withQueryTimeout(repos *repositories, d time.Duration) *repositories {
+	if d <= 0 {
+		return repos
+	}
+	return &repositories{
+		productCommands:   timeout.NewProductCommandRepository(repos.productCommands, d),
+		productQueries:    timeout.NewProductQueryRepository(repos.productQueries, d),
+		inventoryCommands: timeout.NewInventoryCommandRepository(repos.inventoryCommands, d),
+		inventoryQueries:  timeout.NewInventoryQueryRepository(repos.inventoryQueries, d),
+		stocktakeCommands: timeout.NewStocktakeCommandRepository(repos.stocktakeCommands, d),
+		stocktakeQueries:  timeout.NewStocktakeQueryRepository(repos.stocktakeQueries, d),
+		valuationCommands: timeout.NewValuationCommandRepository(repos.valuationCommands, d),
+		valuationQueries:  timeout.NewValuationQueryRepository(repos.valuationQueries, d),
+		catalogQueries:    timeout.NewCatalogQueryRepository(repos.catalogQueries, d),
+		catalogProjection: repos.catalogProjection,
+		idempotency:       timeout.NewIdempotencyStore(repos.idempotency, d),
+		audit:             timeout.NewAuditStore(repos.audit, d),
+		outbox:            timeout.NewOutboxStore(repos.outbox, d),
+		webhooks:          timeout.NewWebhookStore(repos.webhooks, d),
+		unitOfWork:        timeout.NewUnitOfWork(repos.unitOfWork, d),
+		productUnitOfWork: timeout.NewProductUnitOfWork(repos.productUnitOfWork, d),
+	}
+}
+
+// withCache serves product and inventory lookups from the configured cache
+// Command repositories and the unit of work are wrapped too, so writes drop what they change.
+// The returned function closes the cache connection.
+func withCache(repos *repositories, cfg config.CacheConfig) (*repositories, func(), error) {
+	var (
+		store      cache.Cache
+		closeCache = func() {}
+	)
+	switch cfg.Backend {
+	case config.CacheNone:
+		return repos, closeCache, nil
+	case config.CacheRedis:
+		client := redis.NewClient(&redis.Options{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		})
+		if err := client.Ping(context.Background()).Err(); err != nil {
+			client.Close()
+			return nil, nil, fmt.Errorf("redis cache unreachable: %w", err)
+		}
+		store = cache.NewRedis(client, cfg.KeyPrefix)
+		closeCache = func() { client.Close() }
+	default:
+		store = cache.NewLRU(cfg.Size)
+	}
+	log.Printf("Caching product and inventory reads (backend: %s, ttl: %v)", cfg.Backend, cfg.TTL)
+
+	options := cache.Options{TTL: cfg.TTL, NegativeTTL: cfg.NegativeTTL}
+	cached := *repos
+	cached.productCommands = cache.NewProductCommandRepository(repos.productCommands, store)
+	cached.productQueries = cache.NewProductQueryRepository(repos.productQueries, store, options)
+	cached.inventoryCommands = cache.NewInventoryCommandRepository(repos.inventoryCommands, store)
+	cached.inventoryQueries = cache.NewInventoryQueryRepository(repos.inventoryQueries, store, options)
+	cached.unitOfWork = cache.NewUnitOfWork(repos.unitOfWork, store)
+	cached.productUnitOfWork = cache.NewProductUnitOfWork(repos.productUnitOfWork, store)
+	return &cached, closeCache, nil
+}
+
+// initReadRouter opens the configured read replicas and starts their health and lag checks
+// Without replicas every read goes to the primary
+func initReadRouter(cfg *config.Config, primary *sql.DB) (*persistence.ReadRouter, context.CancelFunc, error) {
+	replicas := make([]*sql.DB, 0, len(cfg.Database.ReadReplicas))
+	for _, dsn := range cfg.Database.ReadReplicas {
+		replica, err := openPostgres(cfg, dsn)
+		if err != nil {
+			for _, opened := range replicas {
+				opened.Close()
+			}
+			return nil, nil, err
+		}
+		configurePool(replica, cfg)
+		replicas = append(replicas, replica)
+	}
+
+	router := persistence.NewReadRouter(primary, replicas, persistence.ReplicaOptions{
+		MaxLag:        cfg.Database.ReplicaMaxLag,
+		CheckInterval: cfg.Database.ReplicaCheckInterval,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	if len(replicas) > 0 {
+		router.CheckReplicas(ctx)
+		router.Start(ctx)
+		log.Printf("Read replicas configured: %d (%d healthy)", len(replicas), router.HealthyReplicas())
+	}
+	return router, cancel, nil
+}
+
+// initDatabase initializes and returns a connection to the configured database driver
 func initDatabase(cfg *config.Config) (*sql.DB, error) {
-	dsn := cfg.GetDatabaseDSN()
-	db, err := sql.Open("postgres", dsn)
+	var (
+		db  *sql.DB
+		err error
+	)
+	switch cfg.Database.Driver {
+	case config.DriverSQLite:
+		db, err = sqlite.Open(cfg.Database.Path)
+	default:
+		db, err = openPostgres(cfg, cfg.GetDatabaseDSN())
+	}
+	if err != nil {
+		return nil, err
+	}
+	configurePool(db, cfg)
+
+	// Wait for the database, which may still be starting alongside the application
+	err = database.Connect(context.Background(), db, database.RetryOptions{
+		Timeout:    cfg.Database.ConnectTimeout,
+		Backoff:    cfg.Database.ConnectBackoff,
+		MaxBackoff: cfg.Database.ConnectMaxBackoff,
+	})
 	if err != nil {
+		db.Close()
 		return nil, err
 	}
 
-	// Test the connection
-	if err := db.Ping(); err != nil {
+	return db, nil
+}
+
+// openPostgres opens a PostgreSQL database
+// With row-level security enabled, every connection carries the tenant of the request using it
+func openPostgres(cfg *config.Config, dsn string) (*sql.DB, error) {
+	if !cfg.Database.RowLevelSecurity {
+		return sql.Open("postgres", dsn)
+	}
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
 		return nil, err
 	}
+	return sql.OpenDB(database.NewTenantConnector(connector)), nil
+}
 
-	// Configure connection pool
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
-	// db.SetConnMaxLifetime(5 * time.Minute) // Uncomment if needed
+// initMigrationDatabase connects for schema migrations, which must not be cut short by statement_timeout
+// Migrations span every tenant, so their connections carry none
+func initMigrationDatabase(cfg *config.Config) (*sql.DB, error) {
+	migrationCfg := *cfg
+	migrationCfg.Database.StatementTimeout = 0
+	migrationCfg.Database.RowLevelSecurity = false
+	return initDatabase(&migrationCfg)
+}
 
-	return db, nil
+// configurePool applies the configured connection pool limits and lifetimes
+func configurePool(db *sql.DB, cfg *config.Config) {
+	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.Database.ConnMaxIdleTime)
 }
 
 // registerRoutes registers all API routes
-func registerRoutes(router *gin.Engine, productHandler *delivery.ProductHandler, inventoryHandler *delivery.InventoryHandler) {
+func registerRoutes(
+	router *gin.Engine,
+	productHandler *delivery.ProductHandler,
+	inventoryHandler *delivery.InventoryHandler,
+	stocktakeHandler *delivery.StocktakeHandler,
+	valuationHandler *delivery.ValuationHandler,
+	auditHandler *delivery.AuditHandler,
+	outboxHandler *delivery.OutboxHandler,
+	webhookHandler *delivery.WebhookHandler,
+) {
 	// Health check endpoint
 	router.GET("/health", delivery.HealthCheck)
 
@@ -156,6 +786,7 @@ func registerRoutes(router *gin.Engine, productHandler *delivery.ProductHandler,
 		products := v1.Group("/products")
 		{
 			products.POST("", productHandler.Create)
+			products.GET("", productHandler.List)
 			products.GET("/:id", productHandler.Get)
 		}
 
@@ -163,8 +794,50 @@ func registerRoutes(router *gin.Engine, productHandler *delivery.ProductHandler,
 		inventoryGroup := v1.Group("/inventory")
 		{
 			inventoryGroup.POST("", inventoryHandler.Create)
+			inventoryGroup.GET("", inventoryHandler.List)
 			inventoryGroup.GET("/:productId", inventoryHandler.Get)
+			inventoryGroup.PATCH("/:productId", inventoryHandler.Update)
+			inventoryGroup.DELETE("/:productId", inventoryHandler.Delete)
 			inventoryGroup.PATCH("/adjust", inventoryHandler.Adjust)
+			inventoryGroup.POST("/adjustments:batch", inventoryHandler.AdjustBatch)
+			inventoryGroup.POST("/reserve", inventoryHandler.Reserve)
+			inventoryGroup.POST("/release", inventoryHandler.Release)
+			inventoryGroup.PUT("/:productId/stock-policy", inventoryHandler.SetStockPolicy)
+			inventoryGroup.POST("/receipts", valuationHandler.Receive)
+			inventoryGroup.GET("/valuation", valuationHandler.Report)
+		}
+
+		// Stocktake (cycle count) routes
+		stocktakes := v1.Group("/stocktakes")
+		{
+			stocktakes.POST("", stocktakeHandler.Open)
+			stocktakes.GET("/:id", stocktakeHandler.Get)
+			stocktakes.POST("/:id/counts", stocktakeHandler.RecordCounts)
+			stocktakes.POST("/:id/approve", stocktakeHandler.Approve)
+			stocktakes.POST("/:id/apply", stocktakeHandler.Apply)
+			stocktakes.POST("/:id/cancel", stocktakeHandler.Cancel)
+		}
+
+		// Audit trail routes
+		v1.GET("/audit", auditHandler.List)
+
+		// Outbox administration routes
+		outboxGroup := v1.Group("/outbox")
+		{
+			outboxGroup.GET("/dead-letters", outboxHandler.ListDeadLetters)
+			outboxGroup.POST("/dead-letters/:id/replay", outboxHandler.ReplayDeadLetter)
+		}
+
+		// Webhook subscription routes
+		webhooks := v1.Group("/webhooks")
+		{
+			webhooks.POST("", webhookHandler.Create)
+			webhooks.GET("", webhookHandler.List)
+			webhooks.GET("/:id", webhookHandler.Get)
+			webhooks.PATCH("/:id", webhookHandler.Update)
+			webhooks.DELETE("/:id", webhookHandler.Delete)
+			webhooks.GET("/:id/deliveries", webhookHandler.ListDeliveries)
+			webhooks.POST("/:id/deliveries/:deliveryId/redeliver", webhookHandler.Redeliver)
 		}
 	}
 }
@@ -0,0 +1,15 @@
+// Package db holds the SQL schema of every supported database driver
+// The migrations are embedded so the binary can apply them without the source tree.
+package db
+
+import "embed"
+
+// PostgresMigrations holds the goose migrations for PostgreSQL under migrations/
+//
+//go:embed migrations/*.sql
+var PostgresMigrations embed.FS
+
+// SQLiteMigrations holds the goose migrations for SQLite under sqlite/migrations/
+//
+//go:embed sqlite/migrations/*.sql
+var SQLiteMigrations embed.FS
@@ -0,0 +1,40 @@
+-- +goose Up
+-- Create stocktake sessions table
+CREATE TABLE IF NOT EXISTS stocktakes (
+    id VARCHAR(36) PRIMARY KEY,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    applied_at TIMESTAMP,
+    CONSTRAINT check_stocktake_status CHECK (status IN ('open', 'applied', 'cancelled'))
+);
+
+-- Create stocktake lines table (one frozen snapshot row per counted product)
+CREATE TABLE IF NOT EXISTS stocktake_lines (
+    stocktake_id VARCHAR(36) NOT NULL,
+    product_id VARCHAR(36) NOT NULL,
+    location VARCHAR(255),
+    expected_quantity INTEGER NOT NULL,
+    counted_quantity INTEGER,
+    count_attempts INTEGER NOT NULL DEFAULT 0,
+    approved BOOLEAN NOT NULL DEFAULT FALSE,
+    counted_at TIMESTAMP,
+    PRIMARY KEY (stocktake_id, product_id),
+    CONSTRAINT fk_stocktake
+        FOREIGN KEY (stocktake_id)
+        REFERENCES stocktakes(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_stocktake_product
+        FOREIGN KEY (product_id)
+        REFERENCES products(id)
+        ON DELETE CASCADE,
+    CONSTRAINT check_counted_quantity_positive CHECK (counted_quantity IS NULL OR counted_quantity >= 0)
+);
+
+CREATE INDEX idx_stocktakes_status ON stocktakes(status);
+CREATE INDEX idx_inventory_location ON inventory(location);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_inventory_location;
+DROP TABLE IF EXISTS stocktake_lines CASCADE;
+DROP TABLE IF EXISTS stocktakes CASCADE;
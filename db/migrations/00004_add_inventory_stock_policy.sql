@@ -0,0 +1,17 @@
+-- +goose Up
+-- Add per-product stock policy and separately tracked backorders
+ALTER TABLE inventory ADD COLUMN stock_policy VARCHAR(20) NOT NULL DEFAULT 'strict';
+ALTER TABLE inventory ADD COLUMN backorder_limit INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE inventory ADD COLUMN backordered_quantity INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE inventory ADD CONSTRAINT check_stock_policy CHECK (stock_policy IN ('strict', 'backorder', 'unlimited'));
+ALTER TABLE inventory ADD CONSTRAINT check_backorder_limit_positive CHECK (backorder_limit >= 0);
+ALTER TABLE inventory ADD CONSTRAINT check_backordered_positive CHECK (backordered_quantity >= 0);
+
+-- +goose Down
+ALTER TABLE inventory DROP CONSTRAINT IF EXISTS check_backordered_positive;
+ALTER TABLE inventory DROP CONSTRAINT IF EXISTS check_backorder_limit_positive;
+ALTER TABLE inventory DROP CONSTRAINT IF EXISTS check_stock_policy;
+ALTER TABLE inventory DROP COLUMN IF EXISTS backordered_quantity;
+ALTER TABLE inventory DROP COLUMN IF EXISTS backorder_limit;
+ALTER TABLE inventory DROP COLUMN IF EXISTS stock_policy;
@@ -0,0 +1,41 @@
+-- +goose Up
+-- Inventory valuation: running totals per product plus FIFO cost layers
+CREATE TABLE IF NOT EXISTS inventory_valuations (
+    product_id VARCHAR(36) PRIMARY KEY,
+    costing_method VARCHAR(20) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    quantity INTEGER NOT NULL DEFAULT 0,
+    total_cost DECIMAL(19, 4) NOT NULL DEFAULT 0,
+    consumed_cost DECIMAL(19, 4) NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_inventory_valuations_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT check_costing_method CHECK (costing_method IN ('fifo', 'weighted_average')),
+    CONSTRAINT check_valuation_quantity_positive CHECK (quantity >= 0),
+    CONSTRAINT check_valuation_total_cost_positive CHECK (total_cost >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS inventory_cost_layers (
+    id VARCHAR(36) PRIMARY KEY,
+    product_id VARCHAR(36) NOT NULL,
+    received_quantity INTEGER NOT NULL,
+    remaining_quantity INTEGER NOT NULL,
+    unit_cost DECIMAL(19, 4) NOT NULL,
+    received_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk_inventory_cost_layers_valuation FOREIGN KEY (product_id) REFERENCES inventory_valuations(product_id) ON DELETE CASCADE,
+    CONSTRAINT check_layer_remaining CHECK (remaining_quantity >= 0 AND remaining_quantity <= received_quantity),
+    CONSTRAINT check_layer_unit_cost_positive CHECK (unit_cost >= 0)
+);
+
+CREATE INDEX idx_inventory_cost_layers_open ON inventory_cost_layers(product_id, received_at) WHERE remaining_quantity > 0;
+
+-- Existing stock starts with a valuation in the product's price currency; its units carry no cost
+INSERT INTO inventory_valuations (product_id, costing_method, currency, quantity, updated_at)
+SELECT i.product_id, 'fifo', p.price_currency, 0, CURRENT_TIMESTAMP
+FROM inventory i
+JOIN products p ON p.id = i.product_id
+ON CONFLICT (product_id) DO NOTHING;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_inventory_cost_layers_open;
+DROP TABLE IF EXISTS inventory_cost_layers;
+DROP TABLE IF EXISTS inventory_valuations;
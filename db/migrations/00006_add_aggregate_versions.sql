@@ -0,0 +1,8 @@
+-- +goose Up
+-- Version columns for optimistic concurrency control
+ALTER TABLE inventory ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE products ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE products DROP COLUMN IF EXISTS version;
+ALTER TABLE inventory DROP COLUMN IF EXISTS version;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Support sorting and filtering of inventory lists
+CREATE INDEX idx_inventory_quantity ON inventory(quantity);
+CREATE INDEX idx_inventory_updated_at ON inventory(updated_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_inventory_updated_at;
+DROP INDEX IF EXISTS idx_inventory_quantity;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Free-form inventory attributes such as aisle, bin or supplier SKU
+ALTER TABLE inventory ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+-- +goose Down
+ALTER TABLE inventory DROP COLUMN IF EXISTS metadata;
@@ -0,0 +1,93 @@
+-- +goose Up
+-- Denormalized product-with-stock read model served to product queries
+-- Triggers keep it in step with products and inventory inside the writing transaction
+CREATE TABLE IF NOT EXISTS product_catalog_view (
+    product_id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    price_amount DECIMAL(19, 4) NOT NULL,
+    price_currency VARCHAR(3) NOT NULL,
+    has_inventory BOOLEAN NOT NULL DEFAULT FALSE,
+    quantity INTEGER NOT NULL DEFAULT 0,
+    reserved_quantity INTEGER NOT NULL DEFAULT 0,
+    available_quantity INTEGER NOT NULL DEFAULT 0,
+    location VARCHAR(255),
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk_product_catalog_view_product
+        FOREIGN KEY (product_id)
+        REFERENCES products(id)
+        ON DELETE CASCADE
+);
+
+-- +goose StatementBegin
+-- Recomputes the catalog entry of one product from the source tables
+CREATE OR REPLACE FUNCTION refresh_product_catalog_view(target_product_id VARCHAR) RETURNS VOID AS $$
+BEGIN
+    INSERT INTO product_catalog_view (
+        product_id, name, price_amount, price_currency,
+        has_inventory, quantity, reserved_quantity, available_quantity, location,
+        created_at, updated_at
+    )
+    SELECT
+        p.id, p.name, p.price_amount, p.price_currency,
+        i.id IS NOT NULL,
+        COALESCE(i.quantity, 0),
+        COALESCE(i.reserved_quantity, 0),
+        COALESCE(i.quantity - i.reserved_quantity, 0),
+        i.location,
+        p.created_at, p.updated_at
+    FROM products p
+    LEFT JOIN inventory i ON i.product_id = p.id
+    WHERE p.id = target_product_id
+    ON CONFLICT (product_id) DO UPDATE SET
+        name = EXCLUDED.name,
+        price_amount = EXCLUDED.price_amount,
+        price_currency = EXCLUDED.price_currency,
+        has_inventory = EXCLUDED.has_inventory,
+        quantity = EXCLUDED.quantity,
+        reserved_quantity = EXCLUDED.reserved_quantity,
+        available_quantity = EXCLUDED.available_quantity,
+        location = EXCLUDED.location,
+        created_at = EXCLUDED.created_at,
+        updated_at = EXCLUDED.updated_at;
+
+    -- The product is gone (e.g. inventory removed by a cascading delete)
+    IF NOT FOUND THEN
+        DELETE FROM product_catalog_view WHERE product_id = target_product_id;
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION sync_product_catalog_view() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_TABLE_NAME = 'products' THEN
+        PERFORM refresh_product_catalog_view(NEW.id);
+    ELSIF TG_OP = 'DELETE' THEN
+        PERFORM refresh_product_catalog_view(OLD.product_id);
+    ELSE
+        PERFORM refresh_product_catalog_view(NEW.product_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER trg_products_catalog_view
+    AFTER INSERT OR UPDATE ON products
+    FOR EACH ROW EXECUTE FUNCTION sync_product_catalog_view();
+
+CREATE TRIGGER trg_inventory_catalog_view
+    AFTER INSERT OR UPDATE OR DELETE ON inventory
+    FOR EACH ROW EXECUTE FUNCTION sync_product_catalog_view();
+
+-- Backfill existing products
+SELECT refresh_product_catalog_view(id) FROM products;
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trg_inventory_catalog_view ON inventory;
+DROP TRIGGER IF EXISTS trg_products_catalog_view ON products;
+DROP FUNCTION IF EXISTS sync_product_catalog_view();
+DROP FUNCTION IF EXISTS refresh_product_catalog_view(VARCHAR);
+DROP TABLE IF EXISTS product_catalog_view;
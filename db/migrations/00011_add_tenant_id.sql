@@ -0,0 +1,161 @@
+-- +goose Up
+-- Every business record belongs to a tenant; existing rows go to the default tenant
+-- Product IDs stay globally unique, and (tenant_id, id) keys let child rows reference
+-- only products of their own tenant
+ALTER TABLE products ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE products ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE products ADD CONSTRAINT products_tenant_id_id_key UNIQUE (tenant_id, id);
+CREATE INDEX idx_products_tenant_created_at ON products(tenant_id, created_at);
+
+ALTER TABLE inventory ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE inventory ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE inventory DROP CONSTRAINT fk_product;
+ALTER TABLE inventory DROP CONSTRAINT inventory_product_id_key;
+ALTER TABLE inventory ADD CONSTRAINT inventory_tenant_id_product_id_key UNIQUE (tenant_id, product_id);
+ALTER TABLE inventory ADD CONSTRAINT fk_product
+    FOREIGN KEY (tenant_id, product_id) REFERENCES products(tenant_id, id) ON DELETE CASCADE;
+
+ALTER TABLE stocktakes ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE stocktakes ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX idx_stocktakes_tenant_id ON stocktakes(tenant_id);
+
+ALTER TABLE stocktake_lines ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE stocktake_lines ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE stocktake_lines DROP CONSTRAINT fk_stocktake_product;
+ALTER TABLE stocktake_lines ADD CONSTRAINT fk_stocktake_product
+    FOREIGN KEY (tenant_id, product_id) REFERENCES products(tenant_id, id) ON DELETE CASCADE;
+
+ALTER TABLE inventory_valuations ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE inventory_valuations ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE inventory_valuations DROP CONSTRAINT fk_inventory_valuations_product;
+ALTER TABLE inventory_valuations ADD CONSTRAINT fk_inventory_valuations_product
+    FOREIGN KEY (tenant_id, product_id) REFERENCES products(tenant_id, id) ON DELETE CASCADE;
+
+ALTER TABLE inventory_cost_layers ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE inventory_cost_layers ALTER COLUMN tenant_id DROP DEFAULT;
+
+-- The same idempotency key may be used by different tenants
+ALTER TABLE idempotency_keys ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE idempotency_keys ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (tenant_id, key);
+
+ALTER TABLE product_catalog_view ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE product_catalog_view ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE product_catalog_view DROP CONSTRAINT fk_product_catalog_view_product;
+ALTER TABLE product_catalog_view ADD CONSTRAINT fk_product_catalog_view_product
+    FOREIGN KEY (tenant_id, product_id) REFERENCES products(tenant_id, id) ON DELETE CASCADE;
+
+-- +goose StatementBegin
+-- Recomputes the catalog entry of one product from the source tables, keeping its tenant
+CREATE OR REPLACE FUNCTION refresh_product_catalog_view(target_product_id VARCHAR) RETURNS VOID AS $$
+BEGIN
+    INSERT INTO product_catalog_view (
+        product_id, tenant_id, name, price_amount, price_currency,
+        has_inventory, quantity, reserved_quantity, available_quantity, location,
+        created_at, updated_at
+    )
+    SELECT
+        p.id, p.tenant_id, p.name, p.price_amount, p.price_currency,
+        i.id IS NOT NULL,
+        COALESCE(i.quantity, 0),
+        COALESCE(i.reserved_quantity, 0),
+        COALESCE(i.quantity - i.reserved_quantity, 0),
+        i.location,
+        p.created_at, p.updated_at
+    FROM products p
+    LEFT JOIN inventory i ON i.tenant_id = p.tenant_id AND i.product_id = p.id
+    WHERE p.id = target_product_id
+    ON CONFLICT (product_id) DO UPDATE SET
+        name = EXCLUDED.name,
+        price_amount = EXCLUDED.price_amount,
+        price_currency = EXCLUDED.price_currency,
+        has_inventory = EXCLUDED.has_inventory,
+        quantity = EXCLUDED.quantity,
+        reserved_quantity = EXCLUDED.reserved_quantity,
+        available_quantity = EXCLUDED.available_quantity,
+        location = EXCLUDED.location,
+        created_at = EXCLUDED.created_at,
+        updated_at = EXCLUDED.updated_at;
+
+    -- The product is gone (e.g. inventory removed by a cascading delete)
+    IF NOT FOUND THEN
+        DELETE FROM product_catalog_view WHERE product_id = target_product_id;
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION refresh_product_catalog_view(target_product_id VARCHAR) RETURNS VOID AS $$
+BEGIN
+    INSERT INTO product_catalog_view (
+        product_id, name, price_amount, price_currency,
+        has_inventory, quantity, reserved_quantity, available_quantity, location,
+        created_at, updated_at
+    )
+    SELECT
+        p.id, p.name, p.price_amount, p.price_currency,
+        i.id IS NOT NULL,
+        COALESCE(i.quantity, 0),
+        COALESCE(i.reserved_quantity, 0),
+        COALESCE(i.quantity - i.reserved_quantity, 0),
+        i.location,
+        p.created_at, p.updated_at
+    FROM products p
+    LEFT JOIN inventory i ON i.product_id = p.id
+    WHERE p.id = target_product_id
+    ON CONFLICT (product_id) DO UPDATE SET
+        name = EXCLUDED.name,
+        price_amount = EXCLUDED.price_amount,
+        price_currency = EXCLUDED.price_currency,
+        has_inventory = EXCLUDED.has_inventory,
+        quantity = EXCLUDED.quantity,
+        reserved_quantity = EXCLUDED.reserved_quantity,
+        available_quantity = EXCLUDED.available_quantity,
+        location = EXCLUDED.location,
+        created_at = EXCLUDED.created_at,
+        updated_at = EXCLUDED.updated_at;
+
+    IF NOT FOUND THEN
+        DELETE FROM product_catalog_view WHERE product_id = target_product_id;
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+ALTER TABLE product_catalog_view DROP CONSTRAINT fk_product_catalog_view_product;
+ALTER TABLE product_catalog_view ADD CONSTRAINT fk_product_catalog_view_product
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE;
+ALTER TABLE product_catalog_view DROP COLUMN tenant_id;
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys DROP COLUMN tenant_id;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (key);
+
+ALTER TABLE inventory_cost_layers DROP COLUMN tenant_id;
+
+ALTER TABLE inventory_valuations DROP CONSTRAINT fk_inventory_valuations_product;
+ALTER TABLE inventory_valuations ADD CONSTRAINT fk_inventory_valuations_product
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE;
+ALTER TABLE inventory_valuations DROP COLUMN tenant_id;
+
+ALTER TABLE stocktake_lines DROP CONSTRAINT fk_stocktake_product;
+ALTER TABLE stocktake_lines ADD CONSTRAINT fk_stocktake_product
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE;
+ALTER TABLE stocktake_lines DROP COLUMN tenant_id;
+
+DROP INDEX IF EXISTS idx_stocktakes_tenant_id;
+ALTER TABLE stocktakes DROP COLUMN tenant_id;
+
+ALTER TABLE inventory DROP CONSTRAINT fk_product;
+ALTER TABLE inventory DROP CONSTRAINT inventory_tenant_id_product_id_key;
+ALTER TABLE inventory ADD CONSTRAINT inventory_product_id_key UNIQUE (product_id);
+ALTER TABLE inventory ADD CONSTRAINT fk_product
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE;
+ALTER TABLE inventory DROP COLUMN tenant_id;
+
+DROP INDEX IF EXISTS idx_products_tenant_created_at;
+ALTER TABLE products DROP CONSTRAINT products_tenant_id_id_key;
+ALTER TABLE products DROP COLUMN tenant_id;
@@ -0,0 +1,54 @@
+-- +goose Up
+-- Row-level security limits each connection to the tenant named by its app.tenant_id setting
+-- Table owners bypass these policies, so migrations and catalog rebuilds are unaffected.
+-- They take effect when the API connects as a role that does not own the tables,
+-- with DB_ROW_LEVEL_SECURITY=true so every connection carries its request's tenant.
+ALTER TABLE products ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON products
+    USING (tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE inventory ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON inventory
+    USING (tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE stocktakes ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON stocktakes
+    USING (tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE stocktake_lines ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON stocktake_lines
+    USING (tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE inventory_valuations ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON inventory_valuations
+    USING (tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE inventory_cost_layers ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON inventory_cost_layers
+    USING (tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE idempotency_keys ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON idempotency_keys
+    USING (tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE product_catalog_view ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON product_catalog_view
+    USING (tenant_id = current_setting('app.tenant_id', true));
+
+-- +goose Down
+DROP POLICY IF EXISTS tenant_isolation ON product_catalog_view;
+ALTER TABLE product_catalog_view DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON idempotency_keys;
+ALTER TABLE idempotency_keys DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON inventory_cost_layers;
+ALTER TABLE inventory_cost_layers DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON inventory_valuations;
+ALTER TABLE inventory_valuations DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON stocktake_lines;
+ALTER TABLE stocktake_lines DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON stocktakes;
+ALTER TABLE stocktakes DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON inventory;
+ALTER TABLE inventory DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON products;
+ALTER TABLE products DISABLE ROW LEVEL SECURITY;
@@ -0,0 +1,53 @@
+-- +goose Up
+-- Append-only trail of the commands run against products and inventory
+-- Each tenant's entries form a hash chain ordered by sequence: hash covers the entry's
+-- content and previous_hash, so tampering with a stored entry breaks the chain.
+-- JSON columns keep the recorded text byte for byte, which the hashes depend on.
+CREATE TABLE IF NOT EXISTS audit_log (
+    tenant_id VARCHAR(64) NOT NULL,
+    sequence BIGINT NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    command VARCHAR(100) NOT NULL,
+    aggregate_type VARCHAR(50) NOT NULL,
+    aggregate_id VARCHAR(255) NOT NULL,
+    input JSON NOT NULL,
+    before_state JSON NOT NULL,
+    after_state JSON NOT NULL,
+    request_id VARCHAR(255) NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    previous_hash VARCHAR(64) NOT NULL,
+    hash VARCHAR(64) NOT NULL,
+    PRIMARY KEY (tenant_id, sequence)
+);
+
+CREATE INDEX idx_audit_log_aggregate ON audit_log(tenant_id, aggregate_type, aggregate_id, sequence);
+CREATE INDEX idx_audit_log_actor ON audit_log(tenant_id, actor, sequence);
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION reject_audit_log_change() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER trg_audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION reject_audit_log_change();
+
+CREATE TRIGGER trg_audit_log_no_truncate
+    BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_log_change();
+
+ALTER TABLE audit_log ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON audit_log
+    USING (tenant_id = current_setting('app.tenant_id', true));
+
+-- +goose Down
+DROP POLICY IF EXISTS tenant_isolation ON audit_log;
+DROP TRIGGER IF EXISTS trg_audit_log_no_truncate ON audit_log;
+DROP TRIGGER IF EXISTS trg_audit_log_append_only ON audit_log;
+DROP FUNCTION IF EXISTS reject_audit_log_change();
+DROP INDEX IF EXISTS idx_audit_log_actor;
+DROP INDEX IF EXISTS idx_audit_log_aggregate;
+DROP TABLE IF EXISTS audit_log;
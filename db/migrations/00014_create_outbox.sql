@@ -0,0 +1,35 @@
+-- +goose Up
+-- Events waiting to be published, written in the transactions of the changes that raise them
+-- The relay publishes every tenant's messages, so the table has no row-level security policy;
+-- tenant-facing reads filter on tenant_id themselves.
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    aggregate_type VARCHAR(50) NOT NULL,
+    aggregate_id VARCHAR(255) NOT NULL,
+    event_name VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    published_at TIMESTAMP,
+    CONSTRAINT chk_outbox_status CHECK (status IN ('pending', 'published', 'dead')),
+    CONSTRAINT chk_outbox_attempts CHECK (attempts >= 0)
+);
+
+-- Due messages, and the unpublished messages that hold back later ones of their aggregate
+CREATE INDEX idx_outbox_due ON outbox(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_outbox_aggregate ON outbox(tenant_id, aggregate_type, aggregate_id, id) WHERE status <> 'published';
+CREATE INDEX idx_outbox_tenant_status ON outbox(tenant_id, status, id);
+CREATE INDEX idx_outbox_published_at ON outbox(published_at) WHERE status = 'published';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_outbox_published_at;
+DROP INDEX IF EXISTS idx_outbox_tenant_status;
+DROP INDEX IF EXISTS idx_outbox_aggregate;
+DROP INDEX IF EXISTS idx_outbox_due;
+DROP TABLE IF EXISTS outbox;
@@ -0,0 +1,54 @@
+-- +goose Up
+-- Endpoints partner systems registered to receive domain events
+-- The dispatcher delivers to every tenant's subscriptions, so like the outbox these tables
+-- have no row-level security policy; tenant-facing queries filter on tenant_id themselves.
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id VARCHAR(255) PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    url TEXT NOT NULL,
+    event_types JSONB NOT NULL,
+    secret TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    disabled_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    CONSTRAINT chk_webhook_subscriptions_failures CHECK (consecutive_failures >= 0)
+);
+
+CREATE INDEX idx_webhook_subscriptions_tenant ON webhook_subscriptions(tenant_id, created_at);
+
+-- Every event sent, or to be sent, to a subscription: its delivery log
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    subscription_id VARCHAR(255) NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    message_id BIGINT NOT NULL,
+    event_name VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP,
+    last_status_code INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    last_attempt_at TIMESTAMP,
+    delivered_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    -- A message handed to the webhooks more than once is still delivered once
+    CONSTRAINT uq_webhook_deliveries_message UNIQUE (subscription_id, message_id),
+    CONSTRAINT chk_webhook_deliveries_status CHECK (status IN ('pending', 'delivered', 'failed')),
+    CONSTRAINT chk_webhook_deliveries_attempts CHECK (attempts >= 0)
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_subscription ON webhook_deliveries(tenant_id, subscription_id, id);
+CREATE INDEX idx_webhook_deliveries_delivered_at ON webhook_deliveries(delivered_at) WHERE status = 'delivered';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_webhook_deliveries_delivered_at;
+DROP INDEX IF EXISTS idx_webhook_deliveries_subscription;
+DROP INDEX IF EXISTS idx_webhook_deliveries_due;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP INDEX IF EXISTS idx_webhook_subscriptions_tenant;
+DROP TABLE IF EXISTS webhook_subscriptions;
@@ -0,0 +1,44 @@
+-- +goose Up
+-- Event streams of inventory aggregates, one per product, for the event-sourced inventory option
+-- Versions number the events of a stream from 1 without gaps, so the primary key rejects a
+-- second writer appending the same version. The inventory table is kept as their projection.
+CREATE TABLE IF NOT EXISTS inventory_events (
+    tenant_id VARCHAR(64) NOT NULL,
+    product_id VARCHAR(36) NOT NULL,
+    version INTEGER NOT NULL,
+    event_name VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    recorded_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, product_id, version),
+    CONSTRAINT fk_inventory_events_product
+        FOREIGN KEY (tenant_id, product_id) REFERENCES products(tenant_id, id) ON DELETE CASCADE,
+    CONSTRAINT chk_inventory_events_version CHECK (version >= 1)
+);
+
+-- The latest snapshot of each stream; rehydration replays only the events after it
+CREATE TABLE IF NOT EXISTS inventory_snapshots (
+    tenant_id VARCHAR(64) NOT NULL,
+    product_id VARCHAR(36) NOT NULL,
+    version INTEGER NOT NULL,
+    state JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, product_id),
+    CONSTRAINT fk_inventory_snapshots_product
+        FOREIGN KEY (tenant_id, product_id) REFERENCES products(tenant_id, id) ON DELETE CASCADE,
+    CONSTRAINT chk_inventory_snapshots_version CHECK (version >= 1)
+);
+
+ALTER TABLE inventory_events ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON inventory_events
+    USING (tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE inventory_snapshots ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON inventory_snapshots
+    USING (tenant_id = current_setting('app.tenant_id', true));
+
+-- +goose Down
+DROP POLICY IF EXISTS tenant_isolation ON inventory_snapshots;
+DROP POLICY IF EXISTS tenant_isolation ON inventory_events;
+DROP TABLE IF EXISTS inventory_snapshots;
+DROP TABLE IF EXISTS inventory_events;
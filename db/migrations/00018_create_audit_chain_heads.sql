@@ -0,0 +1,21 @@
+-- +goose Up
+-- The last entry of each tenant's audit chain
+-- An append locks its tenant's head to take the next sequence, so concurrent appends
+-- queue behind one another instead of racing for the sequence and retrying.
+CREATE TABLE IF NOT EXISTS audit_chain_heads (
+    tenant_id VARCHAR(64) PRIMARY KEY,
+    sequence BIGINT NOT NULL,
+    hash VARCHAR(64) NOT NULL
+);
+
+INSERT INTO audit_chain_heads (tenant_id, sequence, hash)
+SELECT a.tenant_id, a.sequence, a.hash FROM audit_log a
+WHERE a.sequence = (SELECT MAX(l.sequence) FROM audit_log l WHERE l.tenant_id = a.tenant_id);
+
+ALTER TABLE audit_chain_heads ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON audit_chain_heads
+    USING (tenant_id = current_setting('app.tenant_id', true));
+
+-- +goose Down
+DROP POLICY IF EXISTS tenant_isolation ON audit_chain_heads;
+DROP TABLE IF EXISTS audit_chain_heads;
@@ -0,0 +1,55 @@
+-- name: LockAuditChainHead :one
+-- Returns the last sequence and hash of the tenant's chain, 0 and empty for a new chain
+-- The upsert locks the head until the appending transaction ends.
+INSERT INTO audit_chain_heads (tenant_id, sequence, hash)
+VALUES (sqlc.arg(tenant_id), 0, '')
+ON CONFLICT (tenant_id) DO UPDATE SET sequence = audit_chain_heads.sequence
+RETURNING sequence, hash;
+
+-- name: AdvanceAuditChainHead :exec
+UPDATE audit_chain_heads
+SET
+    sequence = sqlc.arg(sequence),
+    hash = sqlc.arg(hash)
+WHERE tenant_id = sqlc.arg(tenant_id);
+
+-- name: InsertAuditEntry :exec
+INSERT INTO audit_log (
+    tenant_id,
+    sequence,
+    actor,
+    command,
+    aggregate_type,
+    aggregate_id,
+    input,
+    before_state,
+    after_state,
+    request_id,
+    occurred_at,
+    previous_hash,
+    hash
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+);
+
+-- name: ListAuditEntries :many
+SELECT * FROM audit_log
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(aggregate_type)::text IS NULL OR aggregate_type = sqlc.narg(aggregate_type)::text)
+  AND (sqlc.narg(aggregate_id)::text IS NULL OR aggregate_id = sqlc.narg(aggregate_id)::text)
+  AND (sqlc.narg(actor)::text IS NULL OR actor = sqlc.narg(actor)::text)
+ORDER BY sequence DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: CountAuditEntries :one
+SELECT COUNT(*) FROM audit_log
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(aggregate_type)::text IS NULL OR aggregate_type = sqlc.narg(aggregate_type)::text)
+  AND (sqlc.narg(aggregate_id)::text IS NULL OR aggregate_id = sqlc.narg(aggregate_id)::text)
+  AND (sqlc.narg(actor)::text IS NULL OR actor = sqlc.narg(actor)::text);
+
+-- name: ListAuditChain :many
+SELECT * FROM audit_log
+WHERE tenant_id = sqlc.arg(tenant_id) AND sequence > sqlc.arg(after_sequence)
+ORDER BY sequence
+LIMIT sqlc.arg(row_limit);
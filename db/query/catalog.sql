@@ -0,0 +1,47 @@
+-- name: GetProductCatalogEntry :one
+SELECT
+    product_id,
+    name,
+    price_amount,
+    price_currency,
+    has_inventory,
+    quantity,
+    reserved_quantity,
+    available_quantity,
+    location,
+    created_at,
+    updated_at
+FROM product_catalog_view
+WHERE tenant_id = $1 AND product_id = $2;
+
+-- name: ClearProductCatalog :exec
+DELETE FROM product_catalog_view;
+
+-- name: RebuildProductCatalog :execrows
+-- Regenerates every catalog entry of every tenant from the source tables
+INSERT INTO product_catalog_view (
+    product_id, tenant_id, name, price_amount, price_currency,
+    has_inventory, quantity, reserved_quantity, available_quantity, location,
+    created_at, updated_at
+)
+SELECT
+    p.id, p.tenant_id, p.name, p.price_amount, p.price_currency,
+    i.id IS NOT NULL,
+    COALESCE(i.quantity, 0),
+    COALESCE(i.reserved_quantity, 0),
+    COALESCE(i.quantity - i.reserved_quantity, 0),
+    i.location,
+    p.created_at, p.updated_at
+FROM products p
+LEFT JOIN inventory i ON i.tenant_id = p.tenant_id AND i.product_id = p.id
+ON CONFLICT (product_id) DO UPDATE SET
+    name = EXCLUDED.name,
+    price_amount = EXCLUDED.price_amount,
+    price_currency = EXCLUDED.price_currency,
+    has_inventory = EXCLUDED.has_inventory,
+    quantity = EXCLUDED.quantity,
+    reserved_quantity = EXCLUDED.reserved_quantity,
+    available_quantity = EXCLUDED.available_quantity,
+    location = EXCLUDED.location,
+    created_at = EXCLUDED.created_at,
+    updated_at = EXCLUDED.updated_at;
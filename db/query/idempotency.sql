@@ -0,0 +1,38 @@
+-- name: ReserveIdempotencyKey :execrows
+-- An expired record is replaced; an unexpired one keeps the key
+INSERT INTO idempotency_keys (
+    tenant_id,
+    key,
+    fingerprint,
+    created_at,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (tenant_id, key) DO UPDATE
+SET
+    fingerprint = EXCLUDED.fingerprint,
+    status_code = NULL,
+    content_type = '',
+    response_body = NULL,
+    created_at = EXCLUDED.created_at,
+    completed_at = NULL,
+    expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= EXCLUDED.created_at;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE tenant_id = $1 AND key = $2 AND expires_at > sqlc.arg(now);
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET
+    status_code = $3,
+    content_type = sqlc.arg(content_type),
+    response_body = $4,
+    completed_at = $5
+WHERE tenant_id = $1 AND key = $2;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE tenant_id = $1 AND key = $2;
@@ -1,37 +1,88 @@
 -- name: CreateInventory :exec
 INSERT INTO inventory (
     id,
+    tenant_id,
     product_id,
     quantity,
     reserved_quantity,
     location,
     created_at,
-    updated_at
+    updated_at,
+    stock_policy,
+    backorder_limit,
+    backordered_quantity,
+    version,
+    metadata
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
 );
 
 -- name: GetInventoryByProductID :one
 SELECT * FROM inventory
-WHERE product_id = $1;
+WHERE tenant_id = $1 AND product_id = $2;
 
--- name: UpdateInventory :exec
+-- name: ListInventoryByProductIDs :many
+SELECT * FROM inventory
+WHERE tenant_id = sqlc.arg(tenant_id) AND product_id = ANY(sqlc.arg(product_ids)::varchar[]);
+
+-- name: UpdateInventory :execrows
+-- Only succeeds if the row still has the version the caller loaded
 UPDATE inventory
 SET
-    quantity = $2,
-    reserved_quantity = $3,
-    location = $4,
-    updated_at = $5
-WHERE product_id = $1;
+    quantity = $3,
+    reserved_quantity = $4,
+    location = $5,
+    updated_at = $6,
+    stock_policy = $7,
+    backorder_limit = $8,
+    backordered_quantity = $9,
+    metadata = $10,
+    version = version + 1
+WHERE tenant_id = $1 AND product_id = $2 AND version = sqlc.arg(expected_version);
 
--- name: DeleteInventory :exec
+-- name: DeleteInventory :execrows
+-- Only succeeds if the row still has the version the caller loaded and nothing is reserved or backordered
 DELETE FROM inventory
-WHERE product_id = $1;
+WHERE tenant_id = $1 AND product_id = $2 AND version = sqlc.arg(expected_version)
+    AND reserved_quantity = 0 AND backordered_quantity = 0;
 
 -- name: AdjustInventoryQuantity :exec
+-- Incoming stock is allocated to open backorders first, mirroring Inventory.AdjustQuantity
 UPDATE inventory
 SET
-    quantity = quantity + $2,
-    updated_at = $3
-WHERE product_id = $1;
+    quantity = quantity + sqlc.arg(adjustment)::int,
+    reserved_quantity = reserved_quantity + LEAST(backordered_quantity, GREATEST(quantity + sqlc.arg(adjustment)::int - reserved_quantity, 0)),
+    backordered_quantity = backordered_quantity - LEAST(backordered_quantity, GREATEST(quantity + sqlc.arg(adjustment)::int - reserved_quantity, 0)),
+    updated_at = sqlc.arg(updated_at),
+    version = version + 1
+WHERE tenant_id = sqlc.arg(tenant_id) AND product_id = sqlc.arg(product_id);
+
+
+-- name: ListInventoryByLocation :many
+SELECT * FROM inventory
+WHERE tenant_id = $1 AND location = $2
+ORDER BY product_id;
+
+-- name: ListInventory :many
+SELECT * FROM inventory
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(location)::text IS NULL OR location = sqlc.narg(location)::text)
+  AND (NOT sqlc.arg(zero_stock_only)::bool OR quantity = 0)
+  AND (sqlc.narg(available_below)::int IS NULL OR quantity - reserved_quantity < sqlc.narg(available_below)::int)
+  AND (sqlc.narg(updated_since)::timestamp IS NULL OR updated_at >= sqlc.narg(updated_since)::timestamp)
+ORDER BY
+    CASE WHEN sqlc.arg(sort_by)::text = 'quantity' AND NOT sqlc.arg(sort_desc)::bool THEN quantity END ASC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'quantity' AND sqlc.arg(sort_desc)::bool THEN quantity END DESC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'updated_at' AND NOT sqlc.arg(sort_desc)::bool THEN updated_at END ASC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'updated_at' AND sqlc.arg(sort_desc)::bool THEN updated_at END DESC,
+    CASE WHEN sqlc.arg(sort_desc)::bool THEN product_id END DESC,
+    product_id ASC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
 
+-- name: CountInventory :one
+SELECT COUNT(*) FROM inventory
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(location)::text IS NULL OR location = sqlc.narg(location)::text)
+  AND (NOT sqlc.arg(zero_stock_only)::bool OR quantity = 0)
+  AND (sqlc.narg(available_below)::int IS NULL OR quantity - reserved_quantity < sqlc.narg(available_below)::int)
+  AND (sqlc.narg(updated_since)::timestamp IS NULL OR updated_at >= sqlc.narg(updated_since)::timestamp);
@@ -0,0 +1,74 @@
+-- name: AppendInventoryEvent :exec
+INSERT INTO inventory_events (
+    tenant_id,
+    product_id,
+    version,
+    event_name,
+    payload,
+    occurred_at,
+    recorded_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+);
+
+-- name: ListInventoryEvents :many
+-- The events of a stream recorded after a version, in order
+SELECT * FROM inventory_events
+WHERE tenant_id = sqlc.arg(tenant_id) AND product_id = sqlc.arg(product_id) AND version > sqlc.arg(after_version)::int
+ORDER BY version;
+
+-- name: ListInventoryEventsByProductIDs :many
+-- The events of several streams recorded after each stream's snapshot, in stream order
+SELECT * FROM inventory_events e
+WHERE e.tenant_id = sqlc.arg(tenant_id) AND e.product_id = ANY(sqlc.arg(product_ids)::varchar[])
+    AND e.version > COALESCE((
+        SELECT s.version FROM inventory_snapshots s
+        WHERE s.tenant_id = e.tenant_id AND s.product_id = e.product_id
+    ), 0)
+ORDER BY e.product_id, e.version;
+
+-- name: GetInventoryEventsVersion :one
+-- The version of the last event of a stream, 0 for a stream without events
+SELECT COALESCE(MAX(version), 0)::int FROM inventory_events
+WHERE tenant_id = $1 AND product_id = $2;
+
+-- name: GetInventorySnapshot :one
+SELECT * FROM inventory_snapshots
+WHERE tenant_id = $1 AND product_id = $2;
+
+-- name: ListInventorySnapshotsByProductIDs :many
+SELECT * FROM inventory_snapshots
+WHERE tenant_id = sqlc.arg(tenant_id) AND product_id = ANY(sqlc.arg(product_ids)::varchar[]);
+
+-- name: SaveInventorySnapshot :exec
+-- Replaces the snapshot of a stream unless it already has a later one
+INSERT INTO inventory_snapshots (
+    tenant_id,
+    product_id,
+    version,
+    state,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (tenant_id, product_id) DO UPDATE
+SET
+    version = EXCLUDED.version,
+    state = EXCLUDED.state,
+    created_at = EXCLUDED.created_at
+WHERE inventory_snapshots.version < EXCLUDED.version;
+
+-- name: ProjectInventory :execrows
+-- Writes the state of an event-sourced inventory at a stream version over the version the caller loaded
+UPDATE inventory
+SET
+    quantity = $3,
+    reserved_quantity = $4,
+    location = $5,
+    updated_at = $6,
+    stock_policy = $7,
+    backorder_limit = $8,
+    backordered_quantity = $9,
+    metadata = $10,
+    version = sqlc.arg(version)
+WHERE tenant_id = $1 AND product_id = $2 AND version = sqlc.arg(expected_version);
@@ -0,0 +1,69 @@
+-- name: InsertOutboxMessage :exec
+INSERT INTO outbox (
+    tenant_id,
+    aggregate_type,
+    aggregate_id,
+    event_name,
+    payload,
+    occurred_at,
+    status,
+    next_attempt_at,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+);
+
+-- name: ClaimOutboxMessages :many
+-- Leases the oldest unpublished message of each aggregate when it is due
+-- Rows claimed by a concurrent relay are skipped rather than waited for
+UPDATE outbox
+SET locked_until = sqlc.arg(locked_until)
+WHERE id IN (
+    SELECT o.id FROM outbox o
+    WHERE o.status = 'pending'
+      AND o.next_attempt_at <= sqlc.arg(now)
+      AND (o.locked_until IS NULL OR o.locked_until <= sqlc.arg(now))
+      AND NOT EXISTS (
+          SELECT 1 FROM outbox earlier
+          WHERE earlier.tenant_id = o.tenant_id
+            AND earlier.aggregate_type = o.aggregate_type
+            AND earlier.aggregate_id = o.aggregate_id
+            AND earlier.id < o.id
+            AND earlier.status <> 'published'
+      )
+    ORDER BY o.id
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkOutboxMessagePublished :exec
+UPDATE outbox
+SET status = 'published', published_at = $2, locked_until = NULL
+WHERE id = $1;
+
+-- name: MarkOutboxMessageFailed :exec
+UPDATE outbox
+SET attempts = $2, next_attempt_at = $3, last_error = $4, status = $5, locked_until = NULL
+WHERE id = $1;
+
+-- name: ListOutboxMessages :many
+SELECT * FROM outbox
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (status = sqlc.narg(status) OR sqlc.narg(status) IS NULL)
+ORDER BY id
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: CountOutboxMessages :one
+SELECT COUNT(*) FROM outbox
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (status = sqlc.narg(status) OR sqlc.narg(status) IS NULL);
+
+-- name: ReplayOutboxMessage :execrows
+UPDATE outbox
+SET status = 'pending', attempts = 0, next_attempt_at = $3, last_error = '', locked_until = NULL
+WHERE tenant_id = $1 AND id = $2 AND status = 'dead';
+
+-- name: DeletePublishedOutboxMessages :execrows
+DELETE FROM outbox
+WHERE status = 'published' AND published_at < $1;
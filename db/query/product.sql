@@ -1,13 +1,15 @@
 -- name: CreateProduct :exec
 INSERT INTO products (
     id, 
+    tenant_id,
     name, 
     price_amount, 
     price_currency, 
     created_at, 
-    updated_at
+    updated_at,
+    version
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
+    $1, $2, $3, $4, $5, $6, $7, $8
 );
 
 -- name: GetProductByID :one
@@ -17,22 +19,39 @@ SELECT
     price_amount, 
     price_currency, 
     created_at, 
-    updated_at
+    updated_at,
+    version,
+    tenant_id
 FROM products
-WHERE id = $1;
+WHERE tenant_id = $1 AND id = $2;
 
--- name: UpdateProduct :exec
+-- name: ListProductsByIDs :many
+SELECT 
+    id, 
+    name, 
+    price_amount, 
+    price_currency, 
+    created_at, 
+    updated_at,
+    version,
+    tenant_id
+FROM products
+WHERE tenant_id = sqlc.arg(tenant_id) AND id = ANY(sqlc.arg(ids)::varchar[]);
+
+-- name: UpdateProduct :execrows
+-- Only succeeds if the row still has the version the caller loaded
 UPDATE products
 SET 
-    name = $2,
-    price_amount = $3,
-    price_currency = $4,
-    updated_at = $5
-WHERE id = $1;
+    name = $3,
+    price_amount = $4,
+    price_currency = $5,
+    updated_at = $6,
+    version = version + 1
+WHERE tenant_id = $1 AND id = $2 AND version = sqlc.arg(expected_version);
 
 -- name: DeleteProduct :exec
 DELETE FROM products
-WHERE id = $1;
+WHERE tenant_id = $1 AND id = $2;
 
 -- name: ListProducts :many
 SELECT 
@@ -41,8 +60,11 @@ SELECT
     price_amount, 
     price_currency, 
     created_at, 
-    updated_at
+    updated_at,
+    version,
+    tenant_id
 FROM products
+WHERE tenant_id = $1
 ORDER BY created_at DESC
-LIMIT $1 OFFSET $2;
+LIMIT $2 OFFSET $3;
 
@@ -0,0 +1,48 @@
+-- name: CreateStocktake :exec
+INSERT INTO stocktakes (
+    id,
+    tenant_id,
+    status,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5
+);
+
+-- name: GetStocktakeByID :one
+SELECT * FROM stocktakes
+WHERE tenant_id = $1 AND id = $2;
+
+-- name: UpdateStocktake :execrows
+-- Only succeeds while the session still has the status the caller loaded
+UPDATE stocktakes
+SET
+    status = sqlc.arg(status),
+    updated_at = sqlc.arg(updated_at),
+    applied_at = sqlc.arg(applied_at)
+WHERE tenant_id = sqlc.arg(tenant_id) AND id = sqlc.arg(id) AND status = sqlc.arg(expected_status);
+
+-- name: CreateStocktakeLine :exec
+INSERT INTO stocktake_lines (
+    tenant_id,
+    stocktake_id,
+    product_id,
+    location,
+    expected_quantity
+) VALUES (
+    $1, $2, $3, $4, $5
+);
+
+-- name: ListStocktakeLines :many
+SELECT * FROM stocktake_lines
+WHERE tenant_id = $1 AND stocktake_id = $2
+ORDER BY product_id;
+
+-- name: UpdateStocktakeLine :exec
+UPDATE stocktake_lines
+SET
+    counted_quantity = $4,
+    count_attempts = $5,
+    approved = $6,
+    counted_at = $7
+WHERE tenant_id = $1 AND stocktake_id = $2 AND product_id = $3;
@@ -0,0 +1,60 @@
+-- name: UpsertInventoryValuation :exec
+INSERT INTO inventory_valuations (
+    product_id,
+    tenant_id,
+    costing_method,
+    currency,
+    quantity,
+    total_cost,
+    consumed_cost,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT (product_id) DO UPDATE
+SET
+    quantity = EXCLUDED.quantity,
+    total_cost = EXCLUDED.total_cost,
+    consumed_cost = EXCLUDED.consumed_cost,
+    updated_at = EXCLUDED.updated_at
+WHERE inventory_valuations.tenant_id = EXCLUDED.tenant_id;
+
+-- name: GetInventoryValuation :one
+SELECT * FROM inventory_valuations
+WHERE tenant_id = $1 AND product_id = $2;
+
+-- name: ListInventoryValuations :many
+SELECT * FROM inventory_valuations
+WHERE tenant_id = $1
+ORDER BY product_id;
+
+-- name: UpsertCostLayer :exec
+INSERT INTO inventory_cost_layers (
+    id,
+    tenant_id,
+    product_id,
+    received_quantity,
+    remaining_quantity,
+    unit_cost,
+    received_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (id) DO UPDATE
+SET remaining_quantity = EXCLUDED.remaining_quantity
+WHERE inventory_cost_layers.tenant_id = EXCLUDED.tenant_id;
+
+-- name: ListOpenCostLayers :many
+SELECT * FROM inventory_cost_layers
+WHERE tenant_id = $1 AND product_id = $2 AND remaining_quantity > 0
+ORDER BY received_at, id;
+
+-- name: ListAllOpenCostLayers :many
+SELECT * FROM inventory_cost_layers
+WHERE tenant_id = $1 AND remaining_quantity > 0
+ORDER BY product_id, received_at, id;
+
+-- name: DeleteInventoryValuation :exec
+-- Cost layers are removed by ON DELETE CASCADE
+DELETE FROM inventory_valuations
+WHERE tenant_id = $1 AND product_id = $2;
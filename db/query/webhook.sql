@@ -0,0 +1,115 @@
+-- name: CreateWebhookSubscription :exec
+INSERT INTO webhook_subscriptions (
+    id,
+    tenant_id,
+    url,
+    event_types,
+    secret,
+    active,
+    consecutive_failures,
+    disabled_at,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+);
+
+-- name: GetWebhookSubscription :one
+SELECT * FROM webhook_subscriptions
+WHERE tenant_id = $1 AND id = $2;
+
+-- name: ListWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+WHERE tenant_id = $1
+ORDER BY created_at, id;
+
+-- name: UpdateWebhookSubscription :exec
+UPDATE webhook_subscriptions
+SET url = $3, event_types = $4, secret = $5, active = $6, consecutive_failures = $7, disabled_at = $8, updated_at = $9
+WHERE tenant_id = $1 AND id = $2;
+
+-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions
+WHERE tenant_id = $1 AND id = $2;
+
+-- name: InsertWebhookDelivery :exec
+INSERT INTO webhook_deliveries (
+    tenant_id,
+    subscription_id,
+    message_id,
+    event_name,
+    payload,
+    status,
+    next_attempt_at,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT (subscription_id, message_id) DO NOTHING;
+
+-- name: ClaimWebhookDeliveries :many
+-- Leases due deliveries of active subscriptions, oldest first
+-- Rows claimed by a concurrent dispatcher are skipped rather than waited for
+UPDATE webhook_deliveries
+SET locked_until = sqlc.arg(locked_until)
+WHERE id IN (
+    SELECT d.id FROM webhook_deliveries d
+    JOIN webhook_subscriptions s ON s.id = d.subscription_id
+    WHERE d.status = 'pending'
+      AND s.active
+      AND d.next_attempt_at <= sqlc.arg(now)
+      AND (d.locked_until IS NULL OR d.locked_until <= sqlc.arg(now))
+    ORDER BY d.id
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE OF d SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkWebhookDeliveryDelivered :exec
+UPDATE webhook_deliveries
+SET status = 'delivered', attempts = $2, last_status_code = $3, last_error = '', last_attempt_at = $4, delivered_at = $4, locked_until = NULL
+WHERE id = $1;
+
+-- name: MarkWebhookDeliveryFailed :exec
+UPDATE webhook_deliveries
+SET status = $2, attempts = $3, last_status_code = $4, last_error = $5, last_attempt_at = $6, next_attempt_at = $7, locked_until = NULL
+WHERE id = $1;
+
+-- name: ResetWebhookSubscriptionFailures :exec
+UPDATE webhook_subscriptions
+SET consecutive_failures = 0
+WHERE tenant_id = $1 AND id = $2 AND consecutive_failures <> 0;
+
+-- name: IncrementWebhookSubscriptionFailures :one
+UPDATE webhook_subscriptions
+SET consecutive_failures = consecutive_failures + 1
+WHERE tenant_id = $1 AND id = $2
+RETURNING consecutive_failures, active;
+
+-- name: DisableWebhookSubscription :execrows
+UPDATE webhook_subscriptions
+SET active = FALSE, disabled_at = $3, updated_at = $3
+WHERE tenant_id = $1 AND id = $2 AND active;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND subscription_id = sqlc.arg(subscription_id)
+  AND (status = sqlc.narg(status) OR sqlc.narg(status) IS NULL)
+ORDER BY id DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: CountWebhookDeliveries :one
+SELECT COUNT(*) FROM webhook_deliveries
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND subscription_id = sqlc.arg(subscription_id)
+  AND (status = sqlc.narg(status) OR sqlc.narg(status) IS NULL);
+
+-- name: RedeliverWebhookDelivery :execrows
+UPDATE webhook_deliveries
+SET status = 'pending', attempts = 0, next_attempt_at = $4, delivered_at = NULL, locked_until = NULL
+WHERE tenant_id = $1 AND subscription_id = $2 AND id = $3;
+
+-- name: DeleteDeliveredWebhookDeliveries :execrows
+DELETE FROM webhook_deliveries
+WHERE status = 'delivered' AND delivered_at < $1;
@@ -0,0 +1,126 @@
+-- +goose Up
+-- SQLite schema matching the PostgreSQL migrations up to 00009
+-- Timestamps are stored as UTC text, costs as REAL and metadata as JSON text
+CREATE TABLE IF NOT EXISTS products (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    price_amount REAL NOT NULL,
+    price_currency TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    CONSTRAINT products_price_amount_check CHECK (price_amount >= 0)
+);
+
+CREATE INDEX idx_products_created_at ON products(created_at);
+CREATE INDEX idx_products_name ON products(name);
+
+CREATE TABLE IF NOT EXISTS inventory (
+    id TEXT PRIMARY KEY,
+    product_id TEXT NOT NULL UNIQUE,
+    quantity INTEGER NOT NULL DEFAULT 0,
+    reserved_quantity INTEGER NOT NULL DEFAULT 0,
+    location TEXT,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    stock_policy TEXT NOT NULL DEFAULT 'strict',
+    backorder_limit INTEGER NOT NULL DEFAULT 0,
+    backordered_quantity INTEGER NOT NULL DEFAULT 0,
+    version INTEGER NOT NULL DEFAULT 1,
+    metadata TEXT NOT NULL DEFAULT '{}',
+    CONSTRAINT fk_product
+        FOREIGN KEY (product_id)
+        REFERENCES products(id)
+        ON DELETE CASCADE,
+    CONSTRAINT check_quantity_positive CHECK (quantity >= 0),
+    CONSTRAINT check_reserved_positive CHECK (reserved_quantity >= 0),
+    CONSTRAINT check_reserved_lte_quantity CHECK (reserved_quantity <= quantity),
+    CONSTRAINT check_stock_policy CHECK (stock_policy IN ('strict', 'backorder', 'unlimited')),
+    CONSTRAINT check_backorder_limit_positive CHECK (backorder_limit >= 0),
+    CONSTRAINT check_backordered_positive CHECK (backordered_quantity >= 0)
+);
+
+CREATE INDEX idx_inventory_location ON inventory(location);
+CREATE INDEX idx_inventory_quantity ON inventory(quantity);
+CREATE INDEX idx_inventory_updated_at ON inventory(updated_at);
+
+CREATE TABLE IF NOT EXISTS stocktakes (
+    id TEXT PRIMARY KEY,
+    status TEXT NOT NULL DEFAULT 'open',
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    applied_at DATETIME,
+    CONSTRAINT check_stocktake_status CHECK (status IN ('open', 'applied', 'cancelled'))
+);
+
+CREATE INDEX idx_stocktakes_status ON stocktakes(status);
+
+CREATE TABLE IF NOT EXISTS stocktake_lines (
+    stocktake_id TEXT NOT NULL,
+    product_id TEXT NOT NULL,
+    location TEXT,
+    expected_quantity INTEGER NOT NULL,
+    counted_quantity INTEGER,
+    count_attempts INTEGER NOT NULL DEFAULT 0,
+    approved BOOLEAN NOT NULL DEFAULT FALSE,
+    counted_at DATETIME,
+    PRIMARY KEY (stocktake_id, product_id),
+    CONSTRAINT fk_stocktake
+        FOREIGN KEY (stocktake_id)
+        REFERENCES stocktakes(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_stocktake_product
+        FOREIGN KEY (product_id)
+        REFERENCES products(id)
+        ON DELETE CASCADE,
+    CONSTRAINT check_counted_quantity_positive CHECK (counted_quantity IS NULL OR counted_quantity >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS inventory_valuations (
+    product_id TEXT PRIMARY KEY,
+    costing_method TEXT NOT NULL,
+    currency TEXT NOT NULL,
+    quantity INTEGER NOT NULL DEFAULT 0,
+    total_cost REAL NOT NULL DEFAULT 0,
+    consumed_cost REAL NOT NULL DEFAULT 0,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_inventory_valuations_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT check_costing_method CHECK (costing_method IN ('fifo', 'weighted_average')),
+    CONSTRAINT check_valuation_quantity_positive CHECK (quantity >= 0),
+    CONSTRAINT check_valuation_total_cost_positive CHECK (total_cost >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS inventory_cost_layers (
+    id TEXT PRIMARY KEY,
+    product_id TEXT NOT NULL,
+    received_quantity INTEGER NOT NULL,
+    remaining_quantity INTEGER NOT NULL,
+    unit_cost REAL NOT NULL,
+    received_at DATETIME NOT NULL,
+    CONSTRAINT fk_inventory_cost_layers_valuation FOREIGN KEY (product_id) REFERENCES inventory_valuations(product_id) ON DELETE CASCADE,
+    CONSTRAINT check_layer_remaining CHECK (remaining_quantity >= 0 AND remaining_quantity <= received_quantity),
+    CONSTRAINT check_layer_unit_cost_positive CHECK (unit_cost >= 0)
+);
+
+CREATE INDEX idx_inventory_cost_layers_open ON inventory_cost_layers(product_id, received_at) WHERE remaining_quantity > 0;
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key TEXT PRIMARY KEY,
+    fingerprint TEXT NOT NULL,
+    status_code INTEGER,
+    response_body BLOB,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at DATETIME,
+    expires_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS idempotency_keys;
+DROP TABLE IF EXISTS inventory_cost_layers;
+DROP TABLE IF EXISTS inventory_valuations;
+DROP TABLE IF EXISTS stocktake_lines;
+DROP TABLE IF EXISTS stocktakes;
+DROP TABLE IF EXISTS inventory;
+DROP TABLE IF EXISTS products;
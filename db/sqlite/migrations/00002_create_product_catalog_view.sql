@@ -0,0 +1,155 @@
+-- +goose Up
+-- Denormalized product-with-stock read model served to product queries
+-- Triggers keep it in step with products and inventory inside the writing transaction
+CREATE TABLE IF NOT EXISTS product_catalog_view (
+    product_id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    price_amount REAL NOT NULL,
+    price_currency TEXT NOT NULL,
+    has_inventory BOOLEAN NOT NULL DEFAULT FALSE,
+    quantity INTEGER NOT NULL DEFAULT 0,
+    reserved_quantity INTEGER NOT NULL DEFAULT 0,
+    available_quantity INTEGER NOT NULL DEFAULT 0,
+    location TEXT,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    CONSTRAINT fk_product_catalog_view_product
+        FOREIGN KEY (product_id)
+        REFERENCES products(id)
+        ON DELETE CASCADE
+);
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_products_catalog_view_insert AFTER INSERT ON products
+BEGIN
+    INSERT OR REPLACE INTO product_catalog_view (
+        product_id, name, price_amount, price_currency,
+        has_inventory, quantity, reserved_quantity, available_quantity, location,
+        created_at, updated_at
+    )
+    SELECT
+        p.id, p.name, p.price_amount, p.price_currency,
+        i.id IS NOT NULL,
+        COALESCE(i.quantity, 0),
+        COALESCE(i.reserved_quantity, 0),
+        COALESCE(i.quantity - i.reserved_quantity, 0),
+        i.location,
+        p.created_at, p.updated_at
+    FROM products p
+    LEFT JOIN inventory i ON i.product_id = p.id
+    WHERE p.id = NEW.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_products_catalog_view_update AFTER UPDATE ON products
+BEGIN
+    INSERT OR REPLACE INTO product_catalog_view (
+        product_id, name, price_amount, price_currency,
+        has_inventory, quantity, reserved_quantity, available_quantity, location,
+        created_at, updated_at
+    )
+    SELECT
+        p.id, p.name, p.price_amount, p.price_currency,
+        i.id IS NOT NULL,
+        COALESCE(i.quantity, 0),
+        COALESCE(i.reserved_quantity, 0),
+        COALESCE(i.quantity - i.reserved_quantity, 0),
+        i.location,
+        p.created_at, p.updated_at
+    FROM products p
+    LEFT JOIN inventory i ON i.product_id = p.id
+    WHERE p.id = NEW.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_inventory_catalog_view_insert AFTER INSERT ON inventory
+BEGIN
+    INSERT OR REPLACE INTO product_catalog_view (
+        product_id, name, price_amount, price_currency,
+        has_inventory, quantity, reserved_quantity, available_quantity, location,
+        created_at, updated_at
+    )
+    SELECT
+        p.id, p.name, p.price_amount, p.price_currency,
+        i.id IS NOT NULL,
+        COALESCE(i.quantity, 0),
+        COALESCE(i.reserved_quantity, 0),
+        COALESCE(i.quantity - i.reserved_quantity, 0),
+        i.location,
+        p.created_at, p.updated_at
+    FROM products p
+    LEFT JOIN inventory i ON i.product_id = p.id
+    WHERE p.id = NEW.product_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_inventory_catalog_view_update AFTER UPDATE ON inventory
+BEGIN
+    INSERT OR REPLACE INTO product_catalog_view (
+        product_id, name, price_amount, price_currency,
+        has_inventory, quantity, reserved_quantity, available_quantity, location,
+        created_at, updated_at
+    )
+    SELECT
+        p.id, p.name, p.price_amount, p.price_currency,
+        i.id IS NOT NULL,
+        COALESCE(i.quantity, 0),
+        COALESCE(i.reserved_quantity, 0),
+        COALESCE(i.quantity - i.reserved_quantity, 0),
+        i.location,
+        p.created_at, p.updated_at
+    FROM products p
+    LEFT JOIN inventory i ON i.product_id = p.id
+    WHERE p.id = NEW.product_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_inventory_catalog_view_delete AFTER DELETE ON inventory
+BEGIN
+    INSERT OR REPLACE INTO product_catalog_view (
+        product_id, name, price_amount, price_currency,
+        has_inventory, quantity, reserved_quantity, available_quantity, location,
+        created_at, updated_at
+    )
+    SELECT
+        p.id, p.name, p.price_amount, p.price_currency,
+        i.id IS NOT NULL,
+        COALESCE(i.quantity, 0),
+        COALESCE(i.reserved_quantity, 0),
+        COALESCE(i.quantity - i.reserved_quantity, 0),
+        i.location,
+        p.created_at, p.updated_at
+    FROM products p
+    LEFT JOIN inventory i ON i.product_id = p.id
+    WHERE p.id = OLD.product_id;
+END;
+-- +goose StatementEnd
+
+-- Backfill existing products
+INSERT OR REPLACE INTO product_catalog_view (
+    product_id, name, price_amount, price_currency,
+    has_inventory, quantity, reserved_quantity, available_quantity, location,
+    created_at, updated_at
+)
+SELECT
+    p.id, p.name, p.price_amount, p.price_currency,
+    i.id IS NOT NULL,
+    COALESCE(i.quantity, 0),
+    COALESCE(i.reserved_quantity, 0),
+    COALESCE(i.quantity - i.reserved_quantity, 0),
+    i.location,
+    p.created_at, p.updated_at
+FROM products p
+LEFT JOIN inventory i ON i.product_id = p.id;
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trg_inventory_catalog_view_delete;
+DROP TRIGGER IF EXISTS trg_inventory_catalog_view_update;
+DROP TRIGGER IF EXISTS trg_inventory_catalog_view_insert;
+DROP TRIGGER IF EXISTS trg_products_catalog_view_update;
+DROP TRIGGER IF EXISTS trg_products_catalog_view_insert;
+DROP TABLE IF EXISTS product_catalog_view;
@@ -0,0 +1,356 @@
+-- +goose Up
+-- Every business record belongs to a tenant; existing rows go to the default tenant
+-- SQLite cannot alter constraints in place, so cross-tenant product references are
+-- rejected by triggers instead of composite foreign keys
+ALTER TABLE products ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+CREATE INDEX idx_products_tenant_created_at ON products(tenant_id, created_at);
+
+ALTER TABLE inventory ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE stocktakes ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+CREATE INDEX idx_stocktakes_tenant_id ON stocktakes(tenant_id);
+ALTER TABLE stocktake_lines ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE inventory_valuations ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE inventory_cost_layers ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE product_catalog_view ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+
+-- The same idempotency key may be used by different tenants
+CREATE TABLE idempotency_keys_by_tenant (
+    tenant_id TEXT NOT NULL,
+    key TEXT NOT NULL,
+    fingerprint TEXT NOT NULL,
+    status_code INTEGER,
+    response_body BLOB,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at DATETIME,
+    expires_at DATETIME NOT NULL,
+    PRIMARY KEY (tenant_id, key)
+);
+INSERT INTO idempotency_keys_by_tenant (
+    tenant_id, key, fingerprint, status_code, response_body, created_at, completed_at, expires_at
+)
+SELECT 'default', key, fingerprint, status_code, response_body, created_at, completed_at, expires_at
+FROM idempotency_keys;
+DROP TABLE idempotency_keys;
+ALTER TABLE idempotency_keys_by_tenant RENAME TO idempotency_keys;
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_inventory_tenant_product_insert BEFORE INSERT ON inventory
+WHEN NOT EXISTS (SELECT 1 FROM products WHERE id = NEW.product_id AND tenant_id = NEW.tenant_id)
+BEGIN
+    SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed');
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_inventory_tenant_product_update BEFORE UPDATE ON inventory
+WHEN NOT EXISTS (SELECT 1 FROM products WHERE id = NEW.product_id AND tenant_id = NEW.tenant_id)
+BEGIN
+    SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed');
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_stocktake_lines_tenant_product_insert BEFORE INSERT ON stocktake_lines
+WHEN NOT EXISTS (SELECT 1 FROM products WHERE id = NEW.product_id AND tenant_id = NEW.tenant_id)
+BEGIN
+    SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed');
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_stocktake_lines_tenant_product_update BEFORE UPDATE ON stocktake_lines
+WHEN NOT EXISTS (SELECT 1 FROM products WHERE id = NEW.product_id AND tenant_id = NEW.tenant_id)
+BEGIN
+    SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed');
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_inventory_valuations_tenant_product_insert BEFORE INSERT ON inventory_valuations
+WHEN NOT EXISTS (SELECT 1 FROM products WHERE id = NEW.product_id AND tenant_id = NEW.tenant_id)
+BEGIN
+    SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed');
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_inventory_valuations_tenant_product_update BEFORE UPDATE ON inventory_valuations
+WHEN NOT EXISTS (SELECT 1 FROM products WHERE id = NEW.product_id AND tenant_id = NEW.tenant_id)
+BEGIN
+    SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed');
+END;
+-- +goose StatementEnd
+
+-- The catalog triggers copy the tenant of the product
+DROP TRIGGER IF EXISTS trg_products_catalog_view_insert;
+DROP TRIGGER IF EXISTS trg_products_catalog_view_update;
+DROP TRIGGER IF EXISTS trg_inventory_catalog_view_insert;
+DROP TRIGGER IF EXISTS trg_inventory_catalog_view_update;
+DROP TRIGGER IF EXISTS trg_inventory_catalog_view_delete;
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_products_catalog_view_insert AFTER INSERT ON products
+BEGIN
+    INSERT OR REPLACE INTO product_catalog_view (
+        product_id, tenant_id, name, price_amount, price_currency,
+        has_inventory, quantity, reserved_quantity, available_quantity, location,
+        created_at, updated_at
+    )
+    SELECT
+        p.id, p.tenant_id, p.name, p.price_amount, p.price_currency,
+        i.id IS NOT NULL,
+        COALESCE(i.quantity, 0),
+        COALESCE(i.reserved_quantity, 0),
+        COALESCE(i.quantity - i.reserved_quantity, 0),
+        i.location,
+        p.created_at, p.updated_at
+    FROM products p
+    LEFT JOIN inventory i ON i.tenant_id = p.tenant_id AND i.product_id = p.id
+    WHERE p.id = NEW.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_products_catalog_view_update AFTER UPDATE ON products
+BEGIN
+    INSERT OR REPLACE INTO product_catalog_view (
+        product_id, tenant_id, name, price_amount, price_currency,
+        has_inventory, quantity, reserved_quantity, available_quantity, location,
+        created_at, updated_at
+    )
+    SELECT
+        p.id, p.tenant_id, p.name, p.price_amount, p.price_currency,
+        i.id IS NOT NULL,
+        COALESCE(i.quantity, 0),
+        COALESCE(i.reserved_quantity, 0),
+        COALESCE(i.quantity - i.reserved_quantity, 0),
+        i.location,
+        p.created_at, p.updated_at
+    FROM products p
+    LEFT JOIN inventory i ON i.tenant_id = p.tenant_id AND i.product_id = p.id
+    WHERE p.id = NEW.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_inventory_catalog_view_insert AFTER INSERT ON inventory
+BEGIN
+    INSERT OR REPLACE INTO product_catalog_view (
+        product_id, tenant_id, name, price_amount, price_currency,
+        has_inventory, quantity, reserved_quantity, available_quantity, location,
+        created_at, updated_at
+    )
+    SELECT
+        p.id, p.tenant_id, p.name, p.price_amount, p.price_currency,
+        i.id IS NOT NULL,
+        COALESCE(i.quantity, 0),
+        COALESCE(i.reserved_quantity, 0),
+        COALESCE(i.quantity - i.reserved_quantity, 0),
+        i.location,
+        p.created_at, p.updated_at
+    FROM products p
+    LEFT JOIN inventory i ON i.tenant_id = p.tenant_id AND i.product_id = p.id
+    WHERE p.id = NEW.product_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_inventory_catalog_view_update AFTER UPDATE ON inventory
+BEGIN
+    INSERT OR REPLACE INTO product_catalog_view (
+        product_id, tenant_id, name, price_amount, price_currency,
+        has_inventory, quantity, reserved_quantity, available_quantity, location,
+        created_at, updated_at
+    )
+    SELECT
+        p.id, p.tenant_id, p.name, p.price_amount, p.price_currency,
+        i.id IS NOT NULL,
+        COALESCE(i.quantity, 0),
+        COALESCE(i.reserved_quantity, 0),
+        COALESCE(i.quantity - i.reserved_quantity, 0),
+        i.location,
+        p.created_at, p.updated_at
+    FROM products p
+    LEFT JOIN inventory i ON i.tenant_id = p.tenant_id AND i.product_id = p.id
+    WHERE p.id = NEW.product_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_inventory_catalog_view_delete AFTER DELETE ON inventory
+BEGIN
+    INSERT OR REPLACE INTO product_catalog_view (
+        product_id, tenant_id, name, price_amount, price_currency,
+        has_inventory, quantity, reserved_quantity, available_quantity, location,
+        created_at, updated_at
+    )
+    SELECT
+        p.id, p.tenant_id, p.name, p.price_amount, p.price_currency,
+        i.id IS NOT NULL,
+        COALESCE(i.quantity, 0),
+        COALESCE(i.reserved_quantity, 0),
+        COALESCE(i.quantity - i.reserved_quantity, 0),
+        i.location,
+        p.created_at, p.updated_at
+    FROM products p
+    LEFT JOIN inventory i ON i.tenant_id = p.tenant_id AND i.product_id = p.id
+    WHERE p.id = OLD.product_id;
+END;
+-- +goose StatementEnd
+
+UPDATE product_catalog_view
+SET tenant_id = (SELECT p.tenant_id FROM products p WHERE p.id = product_catalog_view.product_id);
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trg_products_catalog_view_insert;
+DROP TRIGGER IF EXISTS trg_products_catalog_view_update;
+DROP TRIGGER IF EXISTS trg_inventory_catalog_view_insert;
+DROP TRIGGER IF EXISTS trg_inventory_catalog_view_update;
+DROP TRIGGER IF EXISTS trg_inventory_catalog_view_delete;
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_products_catalog_view_insert AFTER INSERT ON products
+BEGIN
+    INSERT OR REPLACE INTO product_catalog_view (
+        product_id, name, price_amount, price_currency,
+        has_inventory, quantity, reserved_quantity, available_quantity, location,
+        created_at, updated_at
+    )
+    SELECT
+        p.id, p.name, p.price_amount, p.price_currency,
+        i.id IS NOT NULL,
+        COALESCE(i.quantity, 0),
+        COALESCE(i.reserved_quantity, 0),
+        COALESCE(i.quantity - i.reserved_quantity, 0),
+        i.location,
+        p.created_at, p.updated_at
+    FROM products p
+    LEFT JOIN inventory i ON i.product_id = p.id
+    WHERE p.id = NEW.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_products_catalog_view_update AFTER UPDATE ON products
+BEGIN
+    INSERT OR REPLACE INTO product_catalog_view (
+        product_id, name, price_amount, price_currency,
+        has_inventory, quantity, reserved_quantity, available_quantity, location,
+        created_at, updated_at
+    )
+    SELECT
+        p.id, p.name, p.price_amount, p.price_currency,
+        i.id IS NOT NULL,
+        COALESCE(i.quantity, 0),
+        COALESCE(i.reserved_quantity, 0),
+        COALESCE(i.quantity - i.reserved_quantity, 0),
+        i.location,
+        p.created_at, p.updated_at
+    FROM products p
+    LEFT JOIN inventory i ON i.product_id = p.id
+    WHERE p.id = NEW.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_inventory_catalog_view_insert AFTER INSERT ON inventory
+BEGIN
+    INSERT OR REPLACE INTO product_catalog_view (
+        product_id, name, price_amount, price_currency,
+        has_inventory, quantity, reserved_quantity, available_quantity, location,
+        created_at, updated_at
+    )
+    SELECT
+        p.id, p.name, p.price_amount, p.price_currency,
+        i.id IS NOT NULL,
+        COALESCE(i.quantity, 0),
+        COALESCE(i.reserved_quantity, 0),
+        COALESCE(i.quantity - i.reserved_quantity, 0),
+        i.location,
+        p.created_at, p.updated_at
+    FROM products p
+    LEFT JOIN inventory i ON i.product_id = p.id
+    WHERE p.id = NEW.product_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_inventory_catalog_view_update AFTER UPDATE ON inventory
+BEGIN
+    INSERT OR REPLACE INTO product_catalog_view (
+        product_id, name, price_amount, price_currency,
+        has_inventory, quantity, reserved_quantity, available_quantity, location,
+        created_at, updated_at
+    )
+    SELECT
+        p.id, p.name, p.price_amount, p.price_currency,
+        i.id IS NOT NULL,
+        COALESCE(i.quantity, 0),
+        COALESCE(i.reserved_quantity, 0),
+        COALESCE(i.quantity - i.reserved_quantity, 0),
+        i.location,
+        p.created_at, p.updated_at
+    FROM products p
+    LEFT JOIN inventory i ON i.product_id = p.id
+    WHERE p.id = NEW.product_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_inventory_catalog_view_delete AFTER DELETE ON inventory
+BEGIN
+    INSERT OR REPLACE INTO product_catalog_view (
+        product_id, name, price_amount, price_currency,
+        has_inventory, quantity, reserved_quantity, available_quantity, location,
+        created_at, updated_at
+    )
+    SELECT
+        p.id, p.name, p.price_amount, p.price_currency,
+        i.id IS NOT NULL,
+        COALESCE(i.quantity, 0),
+        COALESCE(i.reserved_quantity, 0),
+        COALESCE(i.quantity - i.reserved_quantity, 0),
+        i.location,
+        p.created_at, p.updated_at
+    FROM products p
+    LEFT JOIN inventory i ON i.product_id = p.id
+    WHERE p.id = OLD.product_id;
+END;
+-- +goose StatementEnd
+
+DROP TRIGGER IF EXISTS trg_inventory_valuations_tenant_product_update;
+DROP TRIGGER IF EXISTS trg_inventory_valuations_tenant_product_insert;
+DROP TRIGGER IF EXISTS trg_stocktake_lines_tenant_product_update;
+DROP TRIGGER IF EXISTS trg_stocktake_lines_tenant_product_insert;
+DROP TRIGGER IF EXISTS trg_inventory_tenant_product_update;
+DROP TRIGGER IF EXISTS trg_inventory_tenant_product_insert;
+
+CREATE TABLE idempotency_keys_by_key (
+    key TEXT PRIMARY KEY,
+    fingerprint TEXT NOT NULL,
+    status_code INTEGER,
+    response_body BLOB,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at DATETIME,
+    expires_at DATETIME NOT NULL
+);
+INSERT OR IGNORE INTO idempotency_keys_by_key (
+    key, fingerprint, status_code, response_body, created_at, completed_at, expires_at
+)
+SELECT key, fingerprint, status_code, response_body, created_at, completed_at, expires_at
+FROM idempotency_keys
+WHERE tenant_id = 'default';
+DROP TABLE idempotency_keys;
+ALTER TABLE idempotency_keys_by_key RENAME TO idempotency_keys;
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+ALTER TABLE product_catalog_view DROP COLUMN tenant_id;
+ALTER TABLE inventory_cost_layers DROP COLUMN tenant_id;
+ALTER TABLE inventory_valuations DROP COLUMN tenant_id;
+ALTER TABLE stocktake_lines DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_stocktakes_tenant_id;
+ALTER TABLE stocktakes DROP COLUMN tenant_id;
+ALTER TABLE inventory DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_products_tenant_created_at;
+ALTER TABLE products DROP COLUMN tenant_id;
@@ -0,0 +1,44 @@
+-- +goose Up
+-- Append-only trail of the commands run against products and inventory
+-- Each tenant's entries form a hash chain ordered by sequence: hash covers the entry's
+-- content and previous_hash, so tampering with a stored entry breaks the chain.
+CREATE TABLE IF NOT EXISTS audit_log (
+    tenant_id TEXT NOT NULL,
+    sequence INTEGER NOT NULL,
+    actor TEXT NOT NULL,
+    command TEXT NOT NULL,
+    aggregate_type TEXT NOT NULL,
+    aggregate_id TEXT NOT NULL,
+    input TEXT NOT NULL,
+    before_state TEXT NOT NULL,
+    after_state TEXT NOT NULL,
+    request_id TEXT NOT NULL,
+    occurred_at DATETIME NOT NULL,
+    previous_hash TEXT NOT NULL,
+    hash TEXT NOT NULL,
+    PRIMARY KEY (tenant_id, sequence)
+);
+
+CREATE INDEX idx_audit_log_aggregate ON audit_log(tenant_id, aggregate_type, aggregate_id, sequence);
+CREATE INDEX idx_audit_log_actor ON audit_log(tenant_id, actor, sequence);
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_audit_log_no_delete BEFORE DELETE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trg_audit_log_no_delete;
+DROP TRIGGER IF EXISTS trg_audit_log_no_update;
+DROP INDEX IF EXISTS idx_audit_log_actor;
+DROP INDEX IF EXISTS idx_audit_log_aggregate;
+DROP TABLE IF EXISTS audit_log;
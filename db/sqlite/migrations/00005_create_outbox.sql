@@ -0,0 +1,30 @@
+-- +goose Up
+-- Events waiting to be published, written in the transactions of the changes that raise them
+CREATE TABLE IF NOT EXISTS outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id TEXT NOT NULL,
+    aggregate_type TEXT NOT NULL,
+    aggregate_id TEXT NOT NULL,
+    event_name TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    occurred_at DATETIME NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL,
+    locked_until DATETIME,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    published_at DATETIME,
+    CONSTRAINT chk_outbox_status CHECK (status IN ('pending', 'published', 'dead')),
+    CONSTRAINT chk_outbox_attempts CHECK (attempts >= 0)
+);
+
+CREATE INDEX idx_outbox_due ON outbox(status, next_attempt_at);
+CREATE INDEX idx_outbox_aggregate ON outbox(tenant_id, aggregate_type, aggregate_id, id);
+CREATE INDEX idx_outbox_tenant_status ON outbox(tenant_id, status, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_outbox_tenant_status;
+DROP INDEX IF EXISTS idx_outbox_aggregate;
+DROP INDEX IF EXISTS idx_outbox_due;
+DROP TABLE IF EXISTS outbox;
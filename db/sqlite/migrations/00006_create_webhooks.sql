@@ -0,0 +1,50 @@
+-- +goose Up
+-- Endpoints partner systems registered to receive domain events
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    url TEXT NOT NULL,
+    event_types TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    disabled_at DATETIME,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    CONSTRAINT chk_webhook_subscriptions_failures CHECK (consecutive_failures >= 0)
+);
+
+CREATE INDEX idx_webhook_subscriptions_tenant ON webhook_subscriptions(tenant_id, created_at);
+
+-- Every event sent, or to be sent, to a subscription: its delivery log
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id TEXT NOT NULL,
+    subscription_id TEXT NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    message_id INTEGER NOT NULL,
+    event_name TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL,
+    locked_until DATETIME,
+    last_status_code INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    last_attempt_at DATETIME,
+    delivered_at DATETIME,
+    created_at DATETIME NOT NULL,
+    -- A message handed to the webhooks more than once is still delivered once
+    CONSTRAINT uq_webhook_deliveries_message UNIQUE (subscription_id, message_id),
+    CONSTRAINT chk_webhook_deliveries_status CHECK (status IN ('pending', 'delivered', 'failed')),
+    CONSTRAINT chk_webhook_deliveries_attempts CHECK (attempts >= 0)
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+CREATE INDEX idx_webhook_deliveries_subscription ON webhook_deliveries(tenant_id, subscription_id, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_webhook_deliveries_subscription;
+DROP INDEX IF EXISTS idx_webhook_deliveries_due;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP INDEX IF EXISTS idx_webhook_subscriptions_tenant;
+DROP TABLE IF EXISTS webhook_subscriptions;
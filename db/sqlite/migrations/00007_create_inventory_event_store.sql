@@ -0,0 +1,30 @@
+-- +goose Up
+-- Event streams of inventory aggregates, one per product, for the event-sourced inventory option
+-- Versions number the events of a stream from 1 without gaps, so the primary key rejects a
+-- second writer appending the same version. The inventory table is kept as their projection.
+CREATE TABLE IF NOT EXISTS inventory_events (
+    tenant_id TEXT NOT NULL,
+    product_id TEXT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    event_name TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    occurred_at DATETIME NOT NULL,
+    recorded_at DATETIME NOT NULL,
+    PRIMARY KEY (tenant_id, product_id, version),
+    CONSTRAINT chk_inventory_events_version CHECK (version >= 1)
+);
+
+-- The latest snapshot of each stream; rehydration replays only the events after it
+CREATE TABLE IF NOT EXISTS inventory_snapshots (
+    tenant_id TEXT NOT NULL,
+    product_id TEXT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    state TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (tenant_id, product_id),
+    CONSTRAINT chk_inventory_snapshots_version CHECK (version >= 1)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS inventory_snapshots;
+DROP TABLE IF EXISTS inventory_events;
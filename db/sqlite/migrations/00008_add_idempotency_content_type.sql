@@ -0,0 +1,7 @@
+-- +goose Up
+-- The content type of the stored response, replayed with it
+-- Records completed before this column replay as JSON, the only type responses had then
+ALTER TABLE idempotency_keys ADD COLUMN content_type TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE idempotency_keys DROP COLUMN content_type;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- The last entry of each tenant's audit chain
+-- An append takes the next sequence from its tenant's head inside its transaction,
+-- so concurrent appends queue behind one another instead of racing for the sequence.
+CREATE TABLE IF NOT EXISTS audit_chain_heads (
+    tenant_id TEXT PRIMARY KEY,
+    sequence INTEGER NOT NULL,
+    hash TEXT NOT NULL
+);
+
+INSERT INTO audit_chain_heads (tenant_id, sequence, hash)
+SELECT a.tenant_id, a.sequence, a.hash FROM audit_log a
+WHERE a.sequence = (SELECT MAX(l.sequence) FROM audit_log l WHERE l.tenant_id = a.tenant_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_chain_heads;
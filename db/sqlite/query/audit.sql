@@ -0,0 +1,55 @@
+-- name: LockAuditChainHead :one
+-- Returns the last sequence and hash of the tenant's chain, 0 and empty for a new chain
+-- The upsert locks the head until the appending transaction ends.
+INSERT INTO audit_chain_heads (tenant_id, sequence, hash)
+VALUES (sqlc.arg(tenant_id), 0, '')
+ON CONFLICT (tenant_id) DO UPDATE SET sequence = audit_chain_heads.sequence
+RETURNING sequence, hash;
+
+-- name: AdvanceAuditChainHead :exec
+UPDATE audit_chain_heads
+SET
+    sequence = sqlc.arg(sequence),
+    hash = sqlc.arg(hash)
+WHERE tenant_id = sqlc.arg(tenant_id);
+
+-- name: InsertAuditEntry :exec
+INSERT INTO audit_log (
+    tenant_id,
+    sequence,
+    actor,
+    command,
+    aggregate_type,
+    aggregate_id,
+    input,
+    before_state,
+    after_state,
+    request_id,
+    occurred_at,
+    previous_hash,
+    hash
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: ListAuditEntries :many
+SELECT * FROM audit_log
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (aggregate_type = sqlc.narg(aggregate_type) OR sqlc.narg(aggregate_type) IS NULL)
+  AND (aggregate_id = sqlc.narg(aggregate_id) OR sqlc.narg(aggregate_id) IS NULL)
+  AND (actor = sqlc.narg(actor) OR sqlc.narg(actor) IS NULL)
+ORDER BY sequence DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: CountAuditEntries :one
+SELECT COUNT(*) FROM audit_log
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (aggregate_type = sqlc.narg(aggregate_type) OR sqlc.narg(aggregate_type) IS NULL)
+  AND (aggregate_id = sqlc.narg(aggregate_id) OR sqlc.narg(aggregate_id) IS NULL)
+  AND (actor = sqlc.narg(actor) OR sqlc.narg(actor) IS NULL);
+
+-- name: ListAuditChain :many
+SELECT * FROM audit_log
+WHERE tenant_id = sqlc.arg(tenant_id) AND sequence > sqlc.arg(after_sequence)
+ORDER BY sequence
+LIMIT sqlc.arg(row_limit);
@@ -0,0 +1,38 @@
+-- name: ReserveIdempotencyKey :execrows
+-- An expired record is replaced; an unexpired one keeps the key
+INSERT INTO idempotency_keys (
+    tenant_id,
+    key,
+    fingerprint,
+    created_at,
+    expires_at
+) VALUES (
+    ?, ?, ?, ?, ?
+)
+ON CONFLICT (tenant_id, key) DO UPDATE
+SET
+    fingerprint = excluded.fingerprint,
+    status_code = NULL,
+    content_type = '',
+    response_body = NULL,
+    created_at = excluded.created_at,
+    completed_at = NULL,
+    expires_at = excluded.expires_at
+WHERE idempotency_keys.expires_at <= excluded.created_at;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE tenant_id = sqlc.arg(tenant_id) AND key = sqlc.arg(key) AND expires_at > sqlc.arg(now);
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET
+    status_code = sqlc.arg(status_code),
+    content_type = sqlc.arg(content_type),
+    response_body = sqlc.arg(response_body),
+    completed_at = sqlc.arg(completed_at)
+WHERE tenant_id = sqlc.arg(tenant_id) AND key = sqlc.arg(key);
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE tenant_id = ? AND key = ?;
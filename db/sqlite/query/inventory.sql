@@ -0,0 +1,91 @@
+-- name: CreateInventory :exec
+INSERT INTO inventory (
+    id,
+    tenant_id,
+    product_id,
+    quantity,
+    reserved_quantity,
+    location,
+    created_at,
+    updated_at,
+    stock_policy,
+    backorder_limit,
+    backordered_quantity,
+    version,
+    metadata
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: GetInventoryByProductID :one
+SELECT * FROM inventory
+WHERE tenant_id = ? AND product_id = ?;
+
+-- name: ListInventoryByProductIDs :many
+SELECT * FROM inventory
+WHERE tenant_id = sqlc.arg(tenant_id) AND product_id IN (sqlc.slice(product_ids));
+
+-- name: UpdateInventory :execrows
+-- Only succeeds if the row still has the version the caller loaded
+UPDATE inventory
+SET
+    quantity = sqlc.arg(quantity),
+    reserved_quantity = sqlc.arg(reserved_quantity),
+    location = sqlc.arg(location),
+    updated_at = sqlc.arg(updated_at),
+    stock_policy = sqlc.arg(stock_policy),
+    backorder_limit = sqlc.arg(backorder_limit),
+    backordered_quantity = sqlc.arg(backordered_quantity),
+    metadata = sqlc.arg(metadata),
+    version = version + 1
+WHERE tenant_id = sqlc.arg(tenant_id) AND product_id = sqlc.arg(product_id) AND version = sqlc.arg(expected_version);
+
+-- name: DeleteInventory :execrows
+-- Only succeeds if the row still has the version the caller loaded and nothing is reserved or backordered
+DELETE FROM inventory
+WHERE tenant_id = sqlc.arg(tenant_id) AND product_id = sqlc.arg(product_id) AND version = sqlc.arg(expected_version)
+    AND reserved_quantity = 0 AND backordered_quantity = 0;
+
+-- name: AdjustInventoryQuantity :exec
+-- Incoming stock is allocated to open backorders first, mirroring Inventory.AdjustQuantity
+UPDATE inventory
+SET
+    quantity = quantity + sqlc.arg(adjustment),
+    reserved_quantity = reserved_quantity + MIN(backordered_quantity, MAX(quantity + sqlc.arg(adjustment) - reserved_quantity, 0)),
+    backordered_quantity = backordered_quantity - MIN(backordered_quantity, MAX(quantity + sqlc.arg(adjustment) - reserved_quantity, 0)),
+    updated_at = sqlc.arg(updated_at),
+    version = version + 1
+WHERE tenant_id = sqlc.arg(tenant_id) AND product_id = sqlc.arg(product_id);
+
+-- name: ListInventoryByLocation :many
+SELECT * FROM inventory
+WHERE tenant_id = ? AND location = ?
+ORDER BY product_id;
+
+-- name: ListInventory :many
+-- sqlc cannot bind parameters inside ORDER BY for SQLite, so the sort options are selected as columns
+WITH sort_options AS (
+    SELECT CAST(sqlc.arg(sort_by) AS TEXT) AS sort_by, CAST(sqlc.arg(sort_desc) AS BOOLEAN) AS sort_desc
+)
+SELECT inventory.* FROM inventory, sort_options
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (location = sqlc.narg(location) OR sqlc.narg(location) IS NULL)
+  AND (quantity = 0 OR CAST(sqlc.arg(zero_stock_only) AS BOOLEAN) = FALSE)
+  AND (quantity - reserved_quantity < sqlc.narg(available_below) OR sqlc.narg(available_below) IS NULL)
+  AND (updated_at >= sqlc.narg(updated_since) OR sqlc.narg(updated_since) IS NULL)
+ORDER BY
+    CASE WHEN sort_options.sort_by = 'quantity' AND NOT sort_options.sort_desc THEN quantity END ASC,
+    CASE WHEN sort_options.sort_by = 'quantity' AND sort_options.sort_desc THEN quantity END DESC,
+    CASE WHEN sort_options.sort_by = 'updated_at' AND NOT sort_options.sort_desc THEN updated_at END ASC,
+    CASE WHEN sort_options.sort_by = 'updated_at' AND sort_options.sort_desc THEN updated_at END DESC,
+    CASE WHEN sort_options.sort_desc THEN product_id END DESC,
+    product_id ASC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: CountInventory :one
+SELECT COUNT(*) FROM inventory
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (location = sqlc.narg(location) OR sqlc.narg(location) IS NULL)
+  AND (quantity = 0 OR CAST(sqlc.arg(zero_stock_only) AS BOOLEAN) = FALSE)
+  AND (quantity - reserved_quantity < sqlc.narg(available_below) OR sqlc.narg(available_below) IS NULL)
+  AND (updated_at >= sqlc.narg(updated_since) OR sqlc.narg(updated_since) IS NULL);
@@ -0,0 +1,74 @@
+-- name: AppendInventoryEvent :exec
+INSERT INTO inventory_events (
+    tenant_id,
+    product_id,
+    version,
+    event_name,
+    payload,
+    occurred_at,
+    recorded_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: ListInventoryEvents :many
+-- The events of a stream recorded after a version, in order
+SELECT * FROM inventory_events
+WHERE tenant_id = sqlc.arg(tenant_id) AND product_id = sqlc.arg(product_id) AND version > sqlc.arg(after_version)
+ORDER BY version;
+
+-- name: ListInventoryEventsByProductIDs :many
+-- The events of several streams recorded after each stream's snapshot, in stream order
+SELECT e.* FROM inventory_events e
+WHERE e.tenant_id = sqlc.arg(tenant_id) AND e.product_id IN (sqlc.slice(product_ids))
+    AND e.version > COALESCE((
+        SELECT s.version FROM inventory_snapshots s
+        WHERE s.tenant_id = e.tenant_id AND s.product_id = e.product_id
+    ), 0)
+ORDER BY e.product_id, e.version;
+
+-- name: GetInventoryEventsVersion :one
+-- The version of the last event of a stream, 0 for a stream without events
+SELECT CAST(COALESCE(MAX(version), 0) AS INTEGER) FROM inventory_events
+WHERE tenant_id = ? AND product_id = ?;
+
+-- name: GetInventorySnapshot :one
+SELECT * FROM inventory_snapshots
+WHERE tenant_id = ? AND product_id = ?;
+
+-- name: ListInventorySnapshotsByProductIDs :many
+SELECT * FROM inventory_snapshots
+WHERE tenant_id = sqlc.arg(tenant_id) AND product_id IN (sqlc.slice(product_ids));
+
+-- name: SaveInventorySnapshot :exec
+-- Replaces the snapshot of a stream unless it already has a later one
+INSERT INTO inventory_snapshots (
+    tenant_id,
+    product_id,
+    version,
+    state,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?
+)
+ON CONFLICT (tenant_id, product_id) DO UPDATE
+SET
+    version = EXCLUDED.version,
+    state = EXCLUDED.state,
+    created_at = EXCLUDED.created_at
+WHERE inventory_snapshots.version < EXCLUDED.version;
+
+-- name: ProjectInventory :execrows
+-- Writes the state of an event-sourced inventory at a stream version over the version the caller loaded
+UPDATE inventory
+SET
+    quantity = sqlc.arg(quantity),
+    reserved_quantity = sqlc.arg(reserved_quantity),
+    location = sqlc.arg(location),
+    updated_at = sqlc.arg(updated_at),
+    stock_policy = sqlc.arg(stock_policy),
+    backorder_limit = sqlc.arg(backorder_limit),
+    backordered_quantity = sqlc.arg(backordered_quantity),
+    metadata = sqlc.arg(metadata),
+    version = sqlc.arg(version)
+WHERE tenant_id = sqlc.arg(tenant_id) AND product_id = sqlc.arg(product_id) AND version = sqlc.arg(expected_version);
@@ -0,0 +1,67 @@
+-- name: InsertOutboxMessage :exec
+INSERT INTO outbox (
+    tenant_id,
+    aggregate_type,
+    aggregate_id,
+    event_name,
+    payload,
+    occurred_at,
+    status,
+    next_attempt_at,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: ClaimOutboxMessages :many
+-- Leases the oldest unpublished message of each aggregate when it is due
+UPDATE outbox
+SET locked_until = sqlc.arg(locked_until)
+WHERE id IN (
+    SELECT o.id FROM outbox o
+    WHERE o.status = 'pending'
+      AND o.next_attempt_at <= sqlc.arg(now)
+      AND (o.locked_until IS NULL OR o.locked_until <= sqlc.arg(now))
+      AND NOT EXISTS (
+          SELECT 1 FROM outbox earlier
+          WHERE earlier.tenant_id = o.tenant_id
+            AND earlier.aggregate_type = o.aggregate_type
+            AND earlier.aggregate_id = o.aggregate_id
+            AND earlier.id < o.id
+            AND earlier.status <> 'published'
+      )
+    ORDER BY o.id
+    LIMIT sqlc.arg(batch_size)
+)
+RETURNING *;
+
+-- name: MarkOutboxMessagePublished :exec
+UPDATE outbox
+SET status = 'published', published_at = ?, locked_until = NULL
+WHERE id = ?;
+
+-- name: MarkOutboxMessageFailed :exec
+UPDATE outbox
+SET attempts = ?, next_attempt_at = ?, last_error = ?, status = ?, locked_until = NULL
+WHERE id = ?;
+
+-- name: ListOutboxMessages :many
+SELECT * FROM outbox
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (status = sqlc.narg(status) OR sqlc.narg(status) IS NULL)
+ORDER BY id
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: CountOutboxMessages :one
+SELECT COUNT(*) FROM outbox
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (status = sqlc.narg(status) OR sqlc.narg(status) IS NULL);
+
+-- name: ReplayOutboxMessage :execrows
+UPDATE outbox
+SET status = 'pending', attempts = 0, next_attempt_at = ?, last_error = '', locked_until = NULL
+WHERE tenant_id = ? AND id = ? AND status = 'dead';
+
+-- name: DeletePublishedOutboxMessages :execrows
+DELETE FROM outbox
+WHERE status = 'published' AND published_at < ?;
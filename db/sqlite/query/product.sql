@@ -0,0 +1,69 @@
+-- name: CreateProduct :exec
+INSERT INTO products (
+    id,
+    tenant_id,
+    name,
+    price_amount,
+    price_currency,
+    created_at,
+    updated_at,
+    version
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: GetProductByID :one
+SELECT
+    id,
+    name,
+    price_amount,
+    price_currency,
+    created_at,
+    updated_at,
+    version,
+    tenant_id
+FROM products
+WHERE tenant_id = ? AND id = ?;
+
+-- name: ListProductsByIDs :many
+SELECT
+    id,
+    name,
+    price_amount,
+    price_currency,
+    created_at,
+    updated_at,
+    version,
+    tenant_id
+FROM products
+WHERE tenant_id = sqlc.arg(tenant_id) AND id IN (sqlc.slice(ids));
+
+-- name: UpdateProduct :execrows
+-- Only succeeds if the row still has the version the caller loaded
+UPDATE products
+SET
+    name = sqlc.arg(name),
+    price_amount = sqlc.arg(price_amount),
+    price_currency = sqlc.arg(price_currency),
+    updated_at = sqlc.arg(updated_at),
+    version = version + 1
+WHERE tenant_id = sqlc.arg(tenant_id) AND id = sqlc.arg(id) AND version = sqlc.arg(expected_version);
+
+-- name: DeleteProduct :exec
+DELETE FROM products
+WHERE tenant_id = ? AND id = ?;
+
+-- name: ListProducts :many
+SELECT
+    id,
+    name,
+    price_amount,
+    price_currency,
+    created_at,
+    updated_at,
+    version,
+    tenant_id
+FROM products
+WHERE tenant_id = ?
+ORDER BY created_at DESC
+LIMIT ? OFFSET ?;
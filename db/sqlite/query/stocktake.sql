@@ -0,0 +1,48 @@
+-- name: CreateStocktake :exec
+INSERT INTO stocktakes (
+    id,
+    tenant_id,
+    status,
+    created_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?
+);
+
+-- name: GetStocktakeByID :one
+SELECT * FROM stocktakes
+WHERE tenant_id = ? AND id = ?;
+
+-- name: UpdateStocktake :execrows
+-- Only succeeds while the session still has the status the caller loaded
+UPDATE stocktakes
+SET
+    status = sqlc.arg(status),
+    updated_at = sqlc.arg(updated_at),
+    applied_at = sqlc.arg(applied_at)
+WHERE tenant_id = sqlc.arg(tenant_id) AND id = sqlc.arg(id) AND status = sqlc.arg(expected_status);
+
+-- name: CreateStocktakeLine :exec
+INSERT INTO stocktake_lines (
+    tenant_id,
+    stocktake_id,
+    product_id,
+    location,
+    expected_quantity
+) VALUES (
+    ?, ?, ?, ?, ?
+);
+
+-- name: ListStocktakeLines :many
+SELECT * FROM stocktake_lines
+WHERE tenant_id = ? AND stocktake_id = ?
+ORDER BY product_id;
+
+-- name: UpdateStocktakeLine :exec
+UPDATE stocktake_lines
+SET
+    counted_quantity = sqlc.arg(counted_quantity),
+    count_attempts = sqlc.arg(count_attempts),
+    approved = sqlc.arg(approved),
+    counted_at = sqlc.arg(counted_at)
+WHERE tenant_id = sqlc.arg(tenant_id) AND stocktake_id = sqlc.arg(stocktake_id) AND product_id = sqlc.arg(product_id);
@@ -0,0 +1,60 @@
+-- name: UpsertInventoryValuation :exec
+INSERT INTO inventory_valuations (
+    product_id,
+    tenant_id,
+    costing_method,
+    currency,
+    quantity,
+    total_cost,
+    consumed_cost,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT (product_id) DO UPDATE
+SET
+    quantity = excluded.quantity,
+    total_cost = excluded.total_cost,
+    consumed_cost = excluded.consumed_cost,
+    updated_at = excluded.updated_at
+WHERE inventory_valuations.tenant_id = excluded.tenant_id;
+
+-- name: GetInventoryValuation :one
+SELECT * FROM inventory_valuations
+WHERE tenant_id = ? AND product_id = ?;
+
+-- name: ListInventoryValuations :many
+SELECT * FROM inventory_valuations
+WHERE tenant_id = ?
+ORDER BY product_id;
+
+-- name: UpsertCostLayer :exec
+INSERT INTO inventory_cost_layers (
+    id,
+    tenant_id,
+    product_id,
+    received_quantity,
+    remaining_quantity,
+    unit_cost,
+    received_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT (id) DO UPDATE
+SET remaining_quantity = excluded.remaining_quantity
+WHERE inventory_cost_layers.tenant_id = excluded.tenant_id;
+
+-- name: ListOpenCostLayers :many
+SELECT * FROM inventory_cost_layers
+WHERE tenant_id = ? AND product_id = ? AND remaining_quantity > 0
+ORDER BY received_at, id;
+
+-- name: ListAllOpenCostLayers :many
+SELECT * FROM inventory_cost_layers
+WHERE tenant_id = ? AND remaining_quantity > 0
+ORDER BY product_id, received_at, id;
+
+-- name: DeleteInventoryValuation :exec
+-- Cost layers are removed by ON DELETE CASCADE
+DELETE FROM inventory_valuations
+WHERE tenant_id = ? AND product_id = ?;
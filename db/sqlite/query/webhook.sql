@@ -0,0 +1,113 @@
+-- name: CreateWebhookSubscription :exec
+INSERT INTO webhook_subscriptions (
+    id,
+    tenant_id,
+    url,
+    event_types,
+    secret,
+    active,
+    consecutive_failures,
+    disabled_at,
+    created_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: GetWebhookSubscription :one
+SELECT * FROM webhook_subscriptions
+WHERE tenant_id = ? AND id = ?;
+
+-- name: ListWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+WHERE tenant_id = ?
+ORDER BY created_at, id;
+
+-- name: UpdateWebhookSubscription :exec
+UPDATE webhook_subscriptions
+SET url = ?, event_types = ?, secret = ?, active = ?, consecutive_failures = ?, disabled_at = ?, updated_at = ?
+WHERE tenant_id = ? AND id = ?;
+
+-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions
+WHERE tenant_id = ? AND id = ?;
+
+-- name: InsertWebhookDelivery :exec
+INSERT INTO webhook_deliveries (
+    tenant_id,
+    subscription_id,
+    message_id,
+    event_name,
+    payload,
+    status,
+    next_attempt_at,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT (subscription_id, message_id) DO NOTHING;
+
+-- name: ClaimWebhookDeliveries :many
+-- Leases due deliveries of active subscriptions, oldest first
+UPDATE webhook_deliveries
+SET locked_until = sqlc.arg(locked_until)
+WHERE id IN (
+    SELECT d.id FROM webhook_deliveries d
+    JOIN webhook_subscriptions s ON s.id = d.subscription_id
+    WHERE d.status = 'pending'
+      AND s.active
+      AND d.next_attempt_at <= sqlc.arg(now)
+      AND (d.locked_until IS NULL OR d.locked_until <= sqlc.arg(now))
+    ORDER BY d.id
+    LIMIT sqlc.arg(batch_size)
+)
+RETURNING *;
+
+-- name: MarkWebhookDeliveryDelivered :exec
+UPDATE webhook_deliveries
+SET status = 'delivered', attempts = ?, last_status_code = ?, last_error = '', last_attempt_at = ?, delivered_at = ?, locked_until = NULL
+WHERE id = ?;
+
+-- name: MarkWebhookDeliveryFailed :exec
+UPDATE webhook_deliveries
+SET status = ?, attempts = ?, last_status_code = ?, last_error = ?, last_attempt_at = ?, next_attempt_at = ?, locked_until = NULL
+WHERE id = ?;
+
+-- name: ResetWebhookSubscriptionFailures :exec
+UPDATE webhook_subscriptions
+SET consecutive_failures = 0
+WHERE tenant_id = ? AND id = ? AND consecutive_failures <> 0;
+
+-- name: IncrementWebhookSubscriptionFailures :one
+UPDATE webhook_subscriptions
+SET consecutive_failures = consecutive_failures + 1
+WHERE tenant_id = ? AND id = ?
+RETURNING consecutive_failures, active;
+
+-- name: DisableWebhookSubscription :execrows
+UPDATE webhook_subscriptions
+SET active = FALSE, disabled_at = ?, updated_at = ?
+WHERE tenant_id = ? AND id = ? AND active;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND subscription_id = sqlc.arg(subscription_id)
+  AND (status = sqlc.narg(status) OR sqlc.narg(status) IS NULL)
+ORDER BY id DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: CountWebhookDeliveries :one
+SELECT COUNT(*) FROM webhook_deliveries
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND subscription_id = sqlc.arg(subscription_id)
+  AND (status = sqlc.narg(status) OR sqlc.narg(status) IS NULL);
+
+-- name: RedeliverWebhookDelivery :execrows
+UPDATE webhook_deliveries
+SET status = 'pending', attempts = 0, next_attempt_at = ?, delivered_at = NULL, locked_until = NULL
+WHERE tenant_id = ? AND subscription_id = ? AND id = ?;
+
+-- name: DeleteDeliveredWebhookDeliveries :execrows
+DELETE FROM webhook_deliveries
+WHERE status = 'delivered' AND delivered_at < ?;
@@ -0,0 +1,121 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+const (
+	// DefaultAuditPageSize is used when no page size is requested
+	DefaultAuditPageSize = 20
+	// MaxAuditPageSize bounds how many entries a single page may contain
+	MaxAuditPageSize = 100
+)
+
+// ListAuditEntriesInput represents the filters and paging for listing the audit trail
+type ListAuditEntriesInput struct {
+	AggregateType string `form:"aggregate_type" validate:"omitempty,max=50"`
+	AggregateID   string `form:"aggregate_id" validate:"omitempty,max=255"`
+	Actor         string `form:"actor" validate:"omitempty,max=255"`
+	Page          int    `form:"page" validate:"min=0"`
+	PageSize      int    `form:"page_size" validate:"min=0,max=100"`
+}
+
+// AuditEntryOutput represents one recorded command
+// Before is null for commands that created the aggregate and After for commands that removed it
+type AuditEntryOutput struct {
+	Sequence      int64           `json:"sequence"`
+	Actor         string          `json:"actor"`
+	Command       string          `json:"command"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	Input         json.RawMessage `json:"input"`
+	Before        json.RawMessage `json:"before"`
+	After         json.RawMessage `json:"after"`
+	RequestID     string          `json:"request_id,omitempty"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	PreviousHash  string          `json:"previous_hash"`
+	Hash          string          `json:"hash"`
+}
+
+// ListAuditEntriesOutput represents a page of the audit trail, newest first
+type ListAuditEntriesOutput struct {
+	Items    []*AuditEntryOutput `json:"items"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+	Total    int                 `json:"total"`
+}
+
+// ListAuditEntriesQuery handles the business logic for listing the audit trail
+type ListAuditEntriesQuery struct {
+	store audit.Store
+}
+
+// NewListAuditEntriesQuery creates a new instance of ListAuditEntriesQuery
+func NewListAuditEntriesQuery(store audit.Store) *ListAuditEntriesQuery {
+	return &ListAuditEntriesQuery{
+		store: store,
+	}
+}
+
+// Execute performs the list audit entries operation
+func (q *ListAuditEntriesQuery) Execute(ctx context.Context, input ListAuditEntriesInput) (*ListAuditEntriesOutput, error) {
+	// Apply paging defaults
+	page := input.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := input.PageSize
+	if pageSize < 1 {
+		pageSize = DefaultAuditPageSize
+	}
+	if pageSize > MaxAuditPageSize {
+		return nil, apperrors.Newf(apperrors.CodeInvalidInput, "page size cannot exceed %d", MaxAuditPageSize)
+	}
+
+	filter := audit.Filter{
+		AggregateType: input.AggregateType,
+		AggregateID:   input.AggregateID,
+		Actor:         input.Actor,
+		Limit:         pageSize,
+		Offset:        (page - 1) * pageSize,
+	}
+
+	entries, err := q.store.List(ctx, filter)
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	total, err := q.store.Count(ctx, filter)
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	items := make([]*AuditEntryOutput, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, &AuditEntryOutput{
+			Sequence:      entry.Sequence,
+			Actor:         entry.Actor,
+			Command:       entry.Command,
+			AggregateType: entry.AggregateType,
+			AggregateID:   entry.AggregateID,
+			Input:         entry.Input,
+			Before:        entry.Before,
+			After:         entry.After,
+			RequestID:     entry.RequestID,
+			OccurredAt:    entry.OccurredAt,
+			PreviousHash:  entry.PreviousHash,
+			Hash:          entry.Hash,
+		})
+	}
+
+	return &ListAuditEntriesOutput{
+		Items:    items,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}, nil
+}
@@ -0,0 +1,55 @@
+package query
+
+import (
+	"context"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// verifyBatchSize is how many entries are read from the store at a time
+const verifyBatchSize = 500
+
+// VerifyAuditTrailOutput represents the outcome of a successful verification
+type VerifyAuditTrailOutput struct {
+	Entries int `json:"entries"`
+}
+
+// VerifyAuditTrailQuery checks the hash chain of a tenant's audit trail
+type VerifyAuditTrailQuery struct {
+	store audit.Store
+}
+
+// NewVerifyAuditTrailQuery creates a new instance of VerifyAuditTrailQuery
+func NewVerifyAuditTrailQuery(store audit.Store) *VerifyAuditTrailQuery {
+	return &VerifyAuditTrailQuery{
+		store: store,
+	}
+}
+
+// Execute walks the audit trail of the context's tenant from its first entry
+// It fails with CodeAuditChainBroken at the first entry that was altered, removed or reordered
+func (q *VerifyAuditTrailQuery) Execute(ctx context.Context) (*VerifyAuditTrailOutput, error) {
+	var (
+		last  *audit.Entry
+		total int
+	)
+	for {
+		afterSequence := int64(0)
+		if last != nil {
+			afterSequence = last.Sequence
+		}
+		entries, err := q.store.Chain(ctx, afterSequence, verifyBatchSize)
+		if err != nil {
+			return nil, apperrors.WrapDatabaseError(err)
+		}
+		if err := audit.Verify(last, entries); err != nil {
+			return nil, err
+		}
+		total += len(entries)
+		if len(entries) < verifyBatchSize {
+			return &VerifyAuditTrailOutput{Entries: total}, nil
+		}
+		last = entries[len(entries)-1]
+	}
+}
@@ -30,13 +30,47 @@ func (m *MockInventoryRepository) GetByProductID(ctx context.Context, productID
 	return args.Get(0).(*inventory.Inventory), args.Error(1)
 }
 
+func (m *MockInventoryRepository) GetByProductIDs(ctx context.Context, productIDs []string) ([]*inventory.Inventory, error) {
+	args := m.Called(ctx, productIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*inventory.Inventory), args.Error(1)
+}
+
+func (m *MockInventoryRepository) ListByLocation(ctx context.Context, location string) ([]*inventory.Inventory, error) {
+	args := m.Called(ctx, location)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*inventory.Inventory), args.Error(1)
+}
+
+func (m *MockInventoryRepository) List(ctx context.Context, filter inventory.InventoryFilter) ([]*inventory.Inventory, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*inventory.Inventory), args.Error(1)
+}
+
+func (m *MockInventoryRepository) Count(ctx context.Context, filter inventory.InventoryFilter) (int, error) {
+	args := m.Called(ctx, filter)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockInventoryRepository) Update(ctx context.Context, inv *inventory.Inventory) error {
 	args := m.Called(ctx, inv)
 	return args.Error(0)
 }
 
-func (m *MockInventoryRepository) Delete(ctx context.Context, productID string) error {
-	args := m.Called(ctx, productID)
+func (m *MockInventoryRepository) UpdateBatch(ctx context.Context, inventories []*inventory.Inventory) error {
+	args := m.Called(ctx, inventories)
+	return args.Error(0)
+}
+
+func (m *MockInventoryRepository) Delete(ctx context.Context, inv *inventory.Inventory) error {
+	args := m.Called(ctx, inv)
 	return args.Error(0)
 }
 
@@ -81,9 +115,13 @@ func TestAdjustInventoryUseCase_Execute(t *testing.T) {
 					"product-123",
 					100,
 					10,
+					0,
+					inventory.StrictStockPolicy(),
 					"Warehouse A",
+					nil,
 					time.Now(),
 					time.Now(),
+					1,
 				)
 				mockProduct.On("Execute", mock.Anything, "product-123").
 					Return(&product.GetProductOutput{
@@ -114,9 +152,13 @@ func TestAdjustInventoryUseCase_Execute(t *testing.T) {
 					"product-123",
 					100,
 					10,
+					0,
+					inventory.StrictStockPolicy(),
 					"Warehouse A",
+					nil,
 					time.Now(),
 					time.Now(),
+					1,
 				)
 				mockProduct.On("Execute", mock.Anything, "product-123").
 					Return(&product.GetProductOutput{
@@ -176,9 +218,13 @@ func TestAdjustInventoryUseCase_Execute(t *testing.T) {
 					"product-123",
 					50,
 					10,
+					0,
+					inventory.StrictStockPolicy(),
 					"Warehouse A",
+					nil,
 					time.Now(),
 					time.Now(),
+					1,
 				)
 				mockProduct.On("Execute", mock.Anything, "product-123").
 					Return(&product.GetProductOutput{ID: "product-123", Name: "Test Product"}, nil)
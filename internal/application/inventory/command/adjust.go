@@ -6,51 +6,69 @@ import (
 
 	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/inventory/query"
 	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/eventbus"
 	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
 )
 
 // AdjustInventoryInput represents the input for adjusting inventory
+// UnitCost only applies to inbound adjustments; without it the current average cost is used
 type AdjustInventoryInput struct {
-	ProductID  string `json:"product_id" validate:"required"`
-	Adjustment int    `json:"adjustment" validate:"required"`
-	Reason     string `json:"reason"`
+	ProductID  string   `json:"product_id" validate:"required"`
+	Adjustment int      `json:"adjustment" validate:"required"`
+	Reason     string   `json:"reason"`
+	UnitCost   *float64 `json:"unit_cost" validate:"omitempty,min=0"`
 }
 
 // AdjustInventoryOutput represents the output after adjusting inventory
 type AdjustInventoryOutput struct {
-	ID                string    `json:"id"`
-	ProductID         string    `json:"product_id"`
-	ProductName       string    `json:"product_name"`
-	Quantity          int       `json:"quantity"`
-	ReservedQuantity  int       `json:"reserved_quantity"`
-	AvailableQuantity int       `json:"available_quantity"`
-	Location          string    `json:"location"`
-	UpdatedAt         time.Time `json:"updated_at"`
+	ID                  string    `json:"id"`
+	ProductID           string    `json:"product_id"`
+	ProductName         string    `json:"product_name"`
+	Quantity            int       `json:"quantity"`
+	ReservedQuantity    int       `json:"reserved_quantity"`
+	AvailableQuantity   int       `json:"available_quantity"`
+	Location            string    `json:"location"`
+	CostOfGoodsConsumed float64   `json:"cost_of_goods_consumed"`
+	UpdatedAt           time.Time `json:"updated_at"`
 }
 
 // AdjustInventoryCommand handles the business logic for adjusting inventory quantities
 type AdjustInventoryCommand struct {
-	inventoryCmdRepo  inventory.InventoryCommandRepository
-	inventoryQueryRepo inventory.InventoryQueryRepository
-	productQuery      query.ProductQueryInterface
+	uow          inventory.UnitOfWork
+	productQuery query.ProductQueryInterface
+	valuator     *StockValuator
+	recorder     *audit.Recorder
+	dispatcher   *eventbus.Dispatcher
 }
 
 // NewAdjustInventoryCommand creates a new instance of AdjustInventoryCommand
 // This demonstrates module communication: Inventory → Product
+// The stock change and its valuation are written in one transaction
 func NewAdjustInventoryCommand(
-	inventoryCmdRepo inventory.InventoryCommandRepository,
-	inventoryQueryRepo inventory.InventoryQueryRepository,
+	uow inventory.UnitOfWork,
 	productQuery query.ProductQueryInterface,
+	valuator *StockValuator,
+	recorder *audit.Recorder,
+	dispatcher *eventbus.Dispatcher,
 ) *AdjustInventoryCommand {
 	return &AdjustInventoryCommand{
-		inventoryCmdRepo:  inventoryCmdRepo,
-		inventoryQueryRepo: inventoryQueryRepo,
-		productQuery:      productQuery,
+		uow:          uow,
+		productQuery: productQuery,
+		valuator:     valuator,
+		recorder:     recorder,
+		dispatcher:   dispatcher,
 	}
 }
 
 // Execute performs the adjust inventory operation
 func (c *AdjustInventoryCommand) Execute(ctx context.Context, input AdjustInventoryInput) (*AdjustInventoryOutput, error) {
+	return c.execute(ctx, input, "AdjustInventory", input)
+}
+
+// execute adjusts the inventory and records the change in the audit trail
+// as command, with the input the caller received
+func (c *AdjustInventoryCommand) execute(ctx context.Context, input AdjustInventoryInput, command string, recordedInput any) (*AdjustInventoryOutput, error) {
 	// Validate input
 	if input.ProductID == "" {
 		return nil, apperrors.New(apperrors.CodeInvalidInput, "product ID is required")
@@ -58,6 +76,9 @@ func (c *AdjustInventoryCommand) Execute(ctx context.Context, input AdjustInvent
 	if input.Adjustment == 0 {
 		return nil, apperrors.New(apperrors.CodeInvalidAdjustment, "adjustment cannot be zero")
 	}
+	if input.UnitCost != nil && input.Adjustment < 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidAdjustment, "unit cost only applies to inbound adjustments")
+	}
 
 	// MODULE COMMUNICATION: Verify product exists
 	productOutput, err := c.productQuery.Execute(ctx, input.ProductID)
@@ -68,44 +89,64 @@ func (c *AdjustInventoryCommand) Execute(ctx context.Context, input AdjustInvent
 		return nil, err
 	}
 
-	// First, check if inventory exists and validate business rules
-	inv, err := c.inventoryQueryRepo.GetByProductID(ctx, input.ProductID)
-	if err != nil {
-		return nil, apperrors.WrapDatabaseError(err)
-	}
+	// Load, validate and save with a version check; a concurrent change
+	// (e.g. a reservation) rolls back the transaction and the whole step is retried
+	var updatedInv *inventory.Inventory
+	var before *inventoryState
+	var costOfGoods float64
+	err = retryOnConflict(ctx, func() error {
+		return c.uow.Do(ctx, func(ctx context.Context, repos inventory.TxRepositories) error {
+			inv, err := repos.InventoryQueries.GetByProductID(ctx, input.ProductID)
+			if err != nil {
+				return apperrors.WrapDatabaseError(err)
+			}
+			if inv == nil {
+				return inventory.ErrInventoryNotFound
+			}
+			before = inventoryStateOf(inv)
 
-	if inv == nil {
-		return nil, inventory.ErrInventoryNotFound
-	}
+			// Apply adjustment to the entity (business rules and backorder allocation)
+			if err := inv.AdjustQuantity(input.Adjustment); err != nil {
+				return err
+			}
 
-	// Validate adjustment against business rules (using in-memory entity)
-	// This checks if the adjustment would result in invalid state
-	if err := inv.AdjustQuantity(input.Adjustment); err != nil {
-		return nil, err
-	}
+			if err := repos.InventoryCommands.Update(ctx, inv); err != nil {
+				return apperrors.WrapDatabaseError(err)
+			}
 
-	// Use atomic database operation to prevent race conditions
-	// AdjustStock performs: UPDATE inventory SET quantity = quantity + $adjustment
-	// This is atomic and thread-safe at the database level
-	if err := c.inventoryCmdRepo.AdjustStock(ctx, input.ProductID, input.Adjustment); err != nil {
-		return nil, apperrors.WrapDatabaseError(err)
-	}
+			// Value the movement: inbound adds cost, outbound consumes it
+			costOfGoods, err = c.valuator.InTx(repos).Record(ctx, input.ProductID, input.Adjustment, input.UnitCost)
+			if err != nil {
+				return err
+			}
+			updatedInv = inv
 
-	// Retrieve updated inventory to return accurate data
-	updatedInv, err := c.inventoryQueryRepo.GetByProductID(ctx, input.ProductID)
+			return c.recorder.InTx(repos.Audit).Record(ctx, audit.Change{
+				Command:       command,
+				Input:         recordedInput,
+				AggregateType: AuditInventory,
+				AggregateID:   inv.ProductID(),
+				Before:        before,
+				After:         inventoryStateOf(inv),
+			})
+		})
+	})
 	if err != nil {
-		return nil, apperrors.WrapDatabaseError(err)
+		return nil, err
 	}
 
+	c.dispatcher.Dispatch(ctx, updatedInv.PullEvents()...)
+
 	// Return output DTO
 	return &AdjustInventoryOutput{
-		ID:                updatedInv.ID(),
-		ProductID:         updatedInv.ProductID(),
-		ProductName:       productOutput.Name,
-		Quantity:          updatedInv.Quantity(),
-		ReservedQuantity:  updatedInv.ReservedQuantity(),
-		AvailableQuantity: updatedInv.AvailableQuantity(),
-		Location:          updatedInv.Location(),
-		UpdatedAt:         updatedInv.UpdatedAt(),
+		ID:                  updatedInv.ID(),
+		ProductID:           updatedInv.ProductID(),
+		ProductName:         productOutput.Name,
+		Quantity:            updatedInv.Quantity(),
+		ReservedQuantity:    updatedInv.ReservedQuantity(),
+		AvailableQuantity:   updatedInv.AvailableQuantity(),
+		Location:            updatedInv.Location(),
+		CostOfGoodsConsumed: costOfGoods,
+		UpdatedAt:           updatedInv.UpdatedAt(),
 	}, nil
 }
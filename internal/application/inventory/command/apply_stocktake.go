@@ -0,0 +1,153 @@
+package command
+
+import (
+	"context"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/inventory/query"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/eventbus"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// ApplyStocktakeCommand posts approved stocktake variances as cycle count adjustments
+type ApplyStocktakeCommand struct {
+	uow        inventory.UnitOfWork
+	valuator   *StockValuator
+	recorder   *audit.Recorder
+	dispatcher *eventbus.Dispatcher
+}
+
+// NewApplyStocktakeCommand creates a new instance of ApplyStocktakeCommand
+// Adjustments, the closed session and the valuation changes share one transaction
+func NewApplyStocktakeCommand(
+	uow inventory.UnitOfWork,
+	valuator *StockValuator,
+	recorder *audit.Recorder,
+	dispatcher *eventbus.Dispatcher,
+) *ApplyStocktakeCommand {
+	return &ApplyStocktakeCommand{
+		uow:        uow,
+		valuator:   valuator,
+		recorder:   recorder,
+		dispatcher: dispatcher,
+	}
+}
+
+// Execute applies every approved variance and closes the stocktake
+// Variances are applied to the current inventory, so movements made after the snapshot are preserved
+func (c *ApplyStocktakeCommand) Execute(ctx context.Context, stocktakeID string) (*query.StocktakeOutput, error) {
+	// Validate input
+	if stocktakeID == "" {
+		return nil, apperrors.New(apperrors.CodeInvalidInput, "stocktake ID is required")
+	}
+
+	var (
+		output *query.StocktakeOutput
+		// adjusted holds the inventories the variances were posted to, with their events
+		adjusted []*inventory.Inventory
+	)
+	// Load, adjust and save with a version check; a concurrent change
+	// (e.g. a reservation) rolls back the transaction and the whole step is retried
+	err := retryOnConflict(ctx, func() error {
+		return c.uow.Do(ctx, func(ctx context.Context, repos inventory.TxRepositories) error {
+			adjusted = nil
+			st, err := repos.StocktakeQueries.GetByID(ctx, stocktakeID)
+			if err != nil {
+				return apperrors.WrapDatabaseError(err)
+			}
+			if st == nil {
+				return inventory.ErrStocktakeNotFound
+			}
+			before := query.NewStocktakeOutput(st)
+
+			variances := st.ApprovedVariances()
+			productIDs := make([]string, 0, len(variances))
+			for _, line := range variances {
+				productIDs = append(productIDs, line.ProductID())
+			}
+			inventories, err := repos.InventoryQueries.GetByProductIDs(ctx, productIDs)
+			if err != nil {
+				return apperrors.WrapDatabaseError(err)
+			}
+			byProduct := make(map[string]*inventory.Inventory, len(inventories))
+			for _, inv := range inventories {
+				byProduct[inv.ProductID()] = inv
+			}
+
+			// changes records how each adjusted inventory changed
+			var changes []audit.Change
+			// Apply every variance to the current inventory (business rules and backorder allocation)
+			for _, line := range variances {
+				inv := byProduct[line.ProductID()]
+				if inv == nil {
+					return apperrors.Newf(apperrors.CodeInventoryNotFound, "inventory not found for product %s", line.ProductID())
+				}
+				invBefore := inventoryStateOf(inv)
+				if err := inv.AdjustQuantity(line.Variance()); err != nil {
+					return apperrors.Wrapf(err, apperrors.GetCode(err), "cannot apply %s variance for product %s: %s",
+						inventory.AdjustmentReasonCycleCount, line.ProductID(), apperrors.GetMessage(err))
+				}
+				changes = append(changes, audit.Change{
+					Input: stocktakeVarianceInput{
+						StocktakeID: stocktakeID,
+						Adjustment:  line.Variance(),
+						Reason:      inventory.AdjustmentReasonCycleCount,
+					},
+					AggregateType: AuditInventory,
+					AggregateID:   line.ProductID(),
+					Before:        invBefore,
+					After:         inventoryStateOf(inv),
+				})
+				adjusted = append(adjusted, inv)
+			}
+
+			if err := st.MarkApplied(); err != nil {
+				return err
+			}
+
+			// Save the adjusted inventories, with their events, and close the session
+			if err := repos.InventoryCommands.UpdateBatch(ctx, adjusted); err != nil {
+				return apperrors.WrapDatabaseError(err)
+			}
+			if err := repos.StocktakeCommands.Apply(ctx, st); err != nil {
+				return apperrors.WrapDatabaseError(err)
+			}
+
+			// Value the posted variances: gains at average cost, losses as consumed cost
+			valuator := c.valuator.InTx(repos)
+			for _, line := range variances {
+				if _, err := valuator.Record(ctx, line.ProductID(), line.Variance(), nil); err != nil {
+					return err
+				}
+			}
+
+			// The stocktake is recorded first, followed by every inventory its variances adjusted
+			// with the cycle count adjustment it received
+			output = query.NewStocktakeOutput(st)
+			changes = append([]audit.Change{{
+				Input:         stocktakeInput{StocktakeID: stocktakeID},
+				AggregateType: AuditStocktake,
+				AggregateID:   st.ID(),
+				Before:        before,
+				After:         output,
+			}}, changes...)
+			recorder := c.recorder.InTx(repos.Audit)
+			for _, change := range changes {
+				change.Command = "ApplyStocktake"
+				if err := recorder.Record(ctx, change); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, inv := range adjusted {
+		c.dispatcher.Dispatch(ctx, inv.PullEvents()...)
+	}
+	return output, nil
+}
@@ -0,0 +1,76 @@
+package command
+
+import (
+	"context"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/inventory/query"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// ApproveStocktakeInput represents the input for approving stocktake variances
+// An empty product list approves every counted line
+type ApproveStocktakeInput struct {
+	StocktakeID string   `json:"-"`
+	ProductIDs  []string `json:"product_ids"`
+}
+
+// ApproveStocktakeCommand handles the business logic for approving stocktake variances
+type ApproveStocktakeCommand struct {
+	uow      inventory.UnitOfWork
+	recorder *audit.Recorder
+}
+
+// NewApproveStocktakeCommand creates a new instance of ApproveStocktakeCommand
+func NewApproveStocktakeCommand(
+	uow inventory.UnitOfWork,
+	recorder *audit.Recorder,
+) *ApproveStocktakeCommand {
+	return &ApproveStocktakeCommand{
+		uow:      uow,
+		recorder: recorder,
+	}
+}
+
+// Execute approves the selected variances
+func (c *ApproveStocktakeCommand) Execute(ctx context.Context, input ApproveStocktakeInput) (*query.StocktakeOutput, error) {
+	// Validate input
+	if input.StocktakeID == "" {
+		return nil, apperrors.New(apperrors.CodeInvalidInput, "stocktake ID is required")
+	}
+
+	var output *query.StocktakeOutput
+	err := c.uow.Do(ctx, func(ctx context.Context, repos inventory.TxRepositories) error {
+		st, err := repos.StocktakeQueries.GetByID(ctx, input.StocktakeID)
+		if err != nil {
+			return apperrors.WrapDatabaseError(err)
+		}
+		if st == nil {
+			return inventory.ErrStocktakeNotFound
+		}
+		before := query.NewStocktakeOutput(st)
+
+		if err := st.Approve(input.ProductIDs...); err != nil {
+			return err
+		}
+
+		if err := repos.StocktakeCommands.Update(ctx, st); err != nil {
+			return apperrors.WrapDatabaseError(err)
+		}
+
+		output = query.NewStocktakeOutput(st)
+		return c.recorder.InTx(repos.Audit).Record(ctx, audit.Change{
+			Command:       "ApproveStocktake",
+			Input:         input,
+			AggregateType: AuditStocktake,
+			AggregateID:   input.StocktakeID,
+			Before:        before,
+			After:         output,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
+}
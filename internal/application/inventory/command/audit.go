@@ -0,0 +1,57 @@
+package command
+
+import (
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+)
+
+// Aggregate types of the inventory module in the audit trail
+const (
+	AuditInventory = "inventory"
+	AuditStocktake = "stocktake"
+)
+
+// inventoryState is the state of an inventory record as the audit trail records it
+type inventoryState struct {
+	ID                  string            `json:"id"`
+	ProductID           string            `json:"product_id"`
+	Quantity            int               `json:"quantity"`
+	ReservedQuantity    int               `json:"reserved_quantity"`
+	BackorderedQuantity int               `json:"backordered_quantity"`
+	StockPolicy         string            `json:"stock_policy"`
+	BackorderLimit      int               `json:"backorder_limit"`
+	Location            string            `json:"location"`
+	Metadata            map[string]string `json:"metadata"`
+	UpdatedAt           time.Time         `json:"updated_at"`
+}
+
+// inventoryStateOf captures the current state of an inventory record
+// The result does not change with the record, so it can be taken before a change is applied
+func inventoryStateOf(inv *inventory.Inventory) *inventoryState {
+	return &inventoryState{
+		ID:                  inv.ID(),
+		ProductID:           inv.ProductID(),
+		Quantity:            inv.Quantity(),
+		ReservedQuantity:    inv.ReservedQuantity(),
+		BackorderedQuantity: inv.BackorderedQuantity(),
+		StockPolicy:         string(inv.StockPolicy().Type()),
+		BackorderLimit:      inv.StockPolicy().BackorderLimit(),
+		Location:            inv.Location(),
+		Metadata:            inv.Metadata(),
+		UpdatedAt:           inv.UpdatedAt(),
+	}
+}
+
+// stocktakeInput is the input of commands taking only a stocktake ID as the audit trail records it
+type stocktakeInput struct {
+	StocktakeID string `json:"stocktake_id"`
+}
+
+// stocktakeVarianceInput is the adjustment an applied stocktake posts to one inventory,
+// recorded with its reason like a manual adjustment
+type stocktakeVarianceInput struct {
+	StocktakeID string `json:"stocktake_id"`
+	Adjustment  int    `json:"adjustment"`
+	Reason      string `json:"reason"`
+}
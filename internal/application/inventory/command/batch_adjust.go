@@ -0,0 +1,349 @@
+package command
+
+import (
+	"context"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/inventory/query"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/eventbus"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// MaxBatchAdjustmentLines bounds how many lines a single batch may contain
+const MaxBatchAdjustmentLines = 1000
+
+// BatchMode controls what happens to a batch when some of its lines fail
+type BatchMode string
+
+const (
+	// BatchModeAllOrNothing writes nothing if any line fails (the default)
+	BatchModeAllOrNothing BatchMode = "all_or_nothing"
+	// BatchModeBestEffort writes the valid lines and reports the failed ones
+	BatchModeBestEffort BatchMode = "best_effort"
+)
+
+// Batch line statuses
+const (
+	BatchLineApplied  = "applied"
+	BatchLineFailed   = "failed"
+	BatchLineRejected = "rejected"
+)
+
+// BatchAdjustmentLine represents a single stock delta within a batch
+type BatchAdjustmentLine struct {
+	ProductID  string   `json:"product_id" validate:"required"`
+	Adjustment int      `json:"adjustment" validate:"required"`
+	Reason     string   `json:"reason"`
+	UnitCost   *float64 `json:"unit_cost" validate:"omitempty,min=0"`
+}
+
+// BatchAdjustInventoryInput represents the input for adjusting many inventory records at once
+type BatchAdjustInventoryInput struct {
+	Mode  BatchMode             `json:"mode" validate:"omitempty,oneof=all_or_nothing best_effort"`
+	Lines []BatchAdjustmentLine `json:"lines" validate:"required,min=1,max=1000,dive"`
+}
+
+// BatchLineError describes why a line was not applied
+type BatchLineError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// BatchAdjustmentResult represents the outcome of a single line
+type BatchAdjustmentResult struct {
+	Line                int             `json:"line"`
+	ProductID           string          `json:"product_id"`
+	Status              string          `json:"status"`
+	Quantity            int             `json:"quantity,omitempty"`
+	AvailableQuantity   int             `json:"available_quantity,omitempty"`
+	CostOfGoodsConsumed float64         `json:"cost_of_goods_consumed,omitempty"`
+	Error               *BatchLineError `json:"error,omitempty"`
+}
+
+// BatchAdjustInventoryOutput represents the outcome of a batch
+// Committed is false when an all-or-nothing batch was rejected
+type BatchAdjustInventoryOutput struct {
+	Mode      BatchMode                `json:"mode"`
+	Committed bool                     `json:"committed"`
+	Applied   int                      `json:"applied"`
+	Failed    int                      `json:"failed"`
+	Results   []*BatchAdjustmentResult `json:"results"`
+}
+
+// BatchAdjustInventoryCommand handles the business logic for bulk stock adjustments
+type BatchAdjustInventoryCommand struct {
+	uow           inventory.UnitOfWork
+	productsQuery query.ProductBatchQueryInterface
+	valuator      *StockValuator
+	recorder      *audit.Recorder
+	dispatcher    *eventbus.Dispatcher
+}
+
+// NewBatchAdjustInventoryCommand creates a new instance of BatchAdjustInventoryCommand
+// Products of the whole batch are verified with one call to the Product module
+func NewBatchAdjustInventoryCommand(
+	uow inventory.UnitOfWork,
+	productsQuery query.ProductBatchQueryInterface,
+	valuator *StockValuator,
+	recorder *audit.Recorder,
+	dispatcher *eventbus.Dispatcher,
+) *BatchAdjustInventoryCommand {
+	return &BatchAdjustInventoryCommand{
+		uow:           uow,
+		productsQuery: productsQuery,
+		valuator:      valuator,
+		recorder:      recorder,
+		dispatcher:    dispatcher,
+	}
+}
+
+// Execute applies the lines in order and writes every touched record and its valuation in a single transaction
+// Lines for the same product are applied cumulatively
+func (c *BatchAdjustInventoryCommand) Execute(ctx context.Context, input BatchAdjustInventoryInput) (*BatchAdjustInventoryOutput, error) {
+	// Validate input
+	mode := input.Mode
+	if mode == "" {
+		mode = BatchModeAllOrNothing
+	}
+	if mode != BatchModeAllOrNothing && mode != BatchModeBestEffort {
+		return nil, apperrors.Newf(apperrors.CodeInvalidInput, "unknown batch mode %q", input.Mode)
+	}
+	if len(input.Lines) == 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidInput, "batch must contain at least one line")
+	}
+	if len(input.Lines) > MaxBatchAdjustmentLines {
+		return nil, apperrors.Newf(apperrors.CodeInvalidInput, "batch cannot exceed %d lines", MaxBatchAdjustmentLines)
+	}
+
+	// MODULE COMMUNICATION: Verify every product exists with one Product module call
+	knownProducts, err := c.existingProducts(ctx, input.Lines)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply, save and value the lines in one transaction; a concurrent change
+	// rolls it back and the whole batch is re-evaluated against fresh data
+	var (
+		output  *BatchAdjustInventoryOutput
+		written []*inventory.Inventory
+	)
+	err = retryOnConflict(ctx, func() error {
+		return c.uow.Do(ctx, func(ctx context.Context, repos inventory.TxRepositories) error {
+			result, touched, before, err := c.apply(ctx, repos.InventoryQueries, mode, input.Lines, knownProducts)
+			if err != nil {
+				return err
+			}
+			output, written = result, nil
+			if !output.Committed || len(touched) == 0 {
+				return nil
+			}
+			written = touched
+			if err := repos.InventoryCommands.UpdateBatch(ctx, touched); err != nil {
+				return apperrors.WrapDatabaseError(err)
+			}
+
+			// Value the applied movements: inbound adds cost, outbound consumes it
+			valuator := c.valuator.InTx(repos)
+			appliedLines := make(map[string][]BatchAdjustmentLine, len(touched))
+			for i, lineResult := range output.Results {
+				if lineResult.Status != BatchLineApplied {
+					continue
+				}
+				line := input.Lines[i]
+				costOfGoods, err := valuator.Record(ctx, line.ProductID, line.Adjustment, line.UnitCost)
+				if err != nil {
+					return err
+				}
+				lineResult.CostOfGoodsConsumed = costOfGoods
+				appliedLines[line.ProductID] = append(appliedLines[line.ProductID], line)
+			}
+
+			// Each product's entry records only the lines that changed it
+			recorder := c.recorder.InTx(repos.Audit)
+			for _, inv := range touched {
+				err := recorder.Record(ctx, audit.Change{
+					Command:       "BatchAdjustInventory",
+					Input:         BatchAdjustInventoryInput{Mode: mode, Lines: appliedLines[inv.ProductID()]},
+					AggregateType: AuditInventory,
+					AggregateID:   inv.ProductID(),
+					Before:        before[inv.ProductID()],
+					After:         inventoryStateOf(inv),
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, inv := range written {
+		c.dispatcher.Dispatch(ctx, inv.PullEvents()...)
+	}
+	return output, nil
+}
+
+// apply validates every line against freshly loaded inventory and returns the records to write,
+// along with the state of every loaded record before the batch changed it
+func (c *BatchAdjustInventoryCommand) apply(
+	ctx context.Context,
+	inventoryQueryRepo inventory.InventoryQueryRepository,
+	mode BatchMode,
+	lines []BatchAdjustmentLine,
+	knownProducts map[string]bool,
+) (*BatchAdjustInventoryOutput, []*inventory.Inventory, map[string]*inventoryState, error) {
+	output := &BatchAdjustInventoryOutput{
+		Mode:    mode,
+		Results: make([]*BatchAdjustmentResult, 0, len(lines)),
+	}
+	loaded, err := c.loadInventories(ctx, inventoryQueryRepo, knownProducts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	before := make(map[string]*inventoryState, len(loaded))
+	for productID, inv := range loaded {
+		before[productID] = inventoryStateOf(inv)
+	}
+	var touched []*inventory.Inventory
+
+	for i, line := range lines {
+		result := &BatchAdjustmentResult{Line: i + 1, ProductID: line.ProductID}
+		output.Results = append(output.Results, result)
+
+		inv, err := c.loadLine(line, knownProducts, loaded)
+		if err == nil {
+			err = inv.AdjustQuantity(line.Adjustment)
+		}
+		if err != nil {
+			if !isLineError(err) {
+				return nil, nil, nil, err
+			}
+			result.Status = BatchLineFailed
+			result.Error = &BatchLineError{
+				Code:    string(apperrors.GetCode(err)),
+				Message: apperrors.GetMessage(err),
+			}
+			output.Failed++
+			continue
+		}
+
+		if !containsInventory(touched, inv) {
+			touched = append(touched, inv)
+		}
+		result.Status = BatchLineApplied
+		result.Quantity = inv.Quantity()
+		result.AvailableQuantity = inv.AvailableQuantity()
+		output.Applied++
+	}
+
+	output.Committed = mode == BatchModeBestEffort || output.Failed == 0
+	if !output.Committed {
+		// Nothing is written, so no line counts as applied
+		for _, result := range output.Results {
+			if result.Status == BatchLineApplied {
+				result.Status = BatchLineRejected
+				result.Quantity = 0
+				result.AvailableQuantity = 0
+			}
+		}
+		output.Applied = 0
+	}
+	return output, touched, before, nil
+}
+
+// loadInventories reads the inventory of every known batch product in a single repository call
+func (c *BatchAdjustInventoryCommand) loadInventories(
+	ctx context.Context,
+	inventoryQueryRepo inventory.InventoryQueryRepository,
+	knownProducts map[string]bool,
+) (map[string]*inventory.Inventory, error) {
+	loaded := make(map[string]*inventory.Inventory, len(knownProducts))
+	if len(knownProducts) == 0 {
+		return loaded, nil
+	}
+
+	productIDs := make([]string, 0, len(knownProducts))
+	for productID := range knownProducts {
+		productIDs = append(productIDs, productID)
+	}
+	inventories, err := inventoryQueryRepo.GetByProductIDs(ctx, productIDs)
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	for _, inv := range inventories {
+		loaded[inv.ProductID()] = inv
+	}
+	return loaded, nil
+}
+
+// loadLine validates a line and returns its inventory from the records loaded for the batch
+func (c *BatchAdjustInventoryCommand) loadLine(
+	line BatchAdjustmentLine,
+	knownProducts map[string]bool,
+	loaded map[string]*inventory.Inventory,
+) (*inventory.Inventory, error) {
+	if line.ProductID == "" {
+		return nil, apperrors.New(apperrors.CodeInvalidInput, "product ID is required")
+	}
+	if line.Adjustment == 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidAdjustment, "adjustment cannot be zero")
+	}
+	if line.UnitCost != nil && line.Adjustment < 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidAdjustment, "unit cost only applies to inbound adjustments")
+	}
+	if !knownProducts[line.ProductID] {
+		return nil, apperrors.New(apperrors.CodeProductNotFound, "cannot adjust inventory: product not found")
+	}
+
+	inv, ok := loaded[line.ProductID]
+	if !ok {
+		return nil, inventory.ErrInventoryNotFound
+	}
+	return inv, nil
+}
+
+// existingProducts returns the set of batch product IDs known to the Product module
+func (c *BatchAdjustInventoryCommand) existingProducts(ctx context.Context, lines []BatchAdjustmentLine) (map[string]bool, error) {
+	seen := make(map[string]bool)
+	productIDs := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line.ProductID != "" && !seen[line.ProductID] {
+			seen[line.ProductID] = true
+			productIDs = append(productIDs, line.ProductID)
+		}
+	}
+
+	known := make(map[string]bool, len(productIDs))
+	if len(productIDs) == 0 {
+		return known, nil
+	}
+	productOutputs, err := c.productsQuery.Execute(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, productOutput := range productOutputs {
+		known[productOutput.ID] = true
+	}
+	return known, nil
+}
+
+// isLineError reports whether an error belongs to a single line rather than the whole batch
+// Infrastructure failures abort the batch in either mode
+func isLineError(err error) bool {
+	status := apperrors.GetHTTPStatus(err)
+	return status >= 400 && status < 500
+}
+
+// containsInventory reports whether the record is already scheduled for writing
+func containsInventory(inventories []*inventory.Inventory, inv *inventory.Inventory) bool {
+	for _, existing := range inventories {
+		if existing == inv {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package command
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	productcontract "github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/product/contract"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBatchInventoryRepository serves fresh inventory copies and records batch writes
+type fakeBatchInventoryRepository struct {
+	inventory.InventoryRepository
+	quantities map[string]int
+	batches    [][]*inventory.Inventory
+	lookups    int
+}
+
+func (r *fakeBatchInventoryRepository) GetByProductID(ctx context.Context, productID string) (*inventory.Inventory, error) {
+	quantity, ok := r.quantities[productID]
+	if !ok {
+		return nil, nil
+	}
+	now := time.Now()
+	return inventory.ReconstructInventory("inv-"+productID, productID, quantity, 0, 0,
+		inventory.StrictStockPolicy(), "Warehouse A", nil, now, now, 1), nil
+}
+
+func (r *fakeBatchInventoryRepository) GetByProductIDs(ctx context.Context, productIDs []string) ([]*inventory.Inventory, error) {
+	r.lookups++
+	var inventories []*inventory.Inventory
+	for _, productID := range productIDs {
+		if inv, _ := r.GetByProductID(ctx, productID); inv != nil {
+			inventories = append(inventories, inv)
+		}
+	}
+	return inventories, nil
+}
+
+func (r *fakeBatchInventoryRepository) UpdateBatch(ctx context.Context, inventories []*inventory.Inventory) error {
+	r.batches = append(r.batches, inventories)
+	return nil
+}
+
+// fakeUnitOfWork runs the function directly against the fake repositories
+type fakeUnitOfWork struct {
+	repos inventory.TxRepositories
+}
+
+func (u *fakeUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos inventory.TxRepositories) error) error {
+	return u.DoWithIsolation(ctx, inventory.IsolationDefault, fn)
+}
+
+func (u *fakeUnitOfWork) DoWithIsolation(ctx context.Context, level inventory.IsolationLevel, fn func(ctx context.Context, repos inventory.TxRepositories) error) error {
+	return fn(ctx, u.repos)
+}
+
+// fakeAuditStore keeps the entries appended to it
+type fakeAuditStore struct {
+	audit.Store
+	entries []*audit.Entry
+}
+
+func (s *fakeAuditStore) Append(ctx context.Context, entry *audit.Entry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+// fakeBatchProducts knows a fixed set of products
+type fakeBatchProducts []string
+
+func (p fakeBatchProducts) Execute(ctx context.Context, productIDs []string) ([]*productcontract.ProductSummary, error) {
+	outputs := make([]*productcontract.ProductSummary, 0, len(p))
+	for _, id := range p {
+		outputs = append(outputs, &productcontract.ProductSummary{ID: id})
+	}
+	return outputs, nil
+}
+
+func newBatchFixture() (*BatchAdjustInventoryCommand, *fakeBatchInventoryRepository) {
+	repo := &fakeBatchInventoryRepository{quantities: map[string]int{"p1": 10, "p2": 5}}
+	uow := &fakeUnitOfWork{repos: inventory.TxRepositories{InventoryCommands: repo, InventoryQueries: repo}}
+	return NewBatchAdjustInventoryCommand(uow, fakeBatchProducts{"p1", "p2"}, nil, nil, nil), repo
+}
+
+func TestBatchAdjustInventoryCommand_AllOrNothing(t *testing.T) {
+	cmd, repo := newBatchFixture()
+
+	output, err := cmd.Execute(context.Background(), BatchAdjustInventoryInput{
+		Lines: []BatchAdjustmentLine{
+			{ProductID: "p1", Adjustment: 5},
+			{ProductID: "p2", Adjustment: -6},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.False(t, output.Committed)
+	assert.Empty(t, repo.batches)
+	assert.Equal(t, 0, output.Applied)
+	assert.Equal(t, 1, output.Failed)
+	assert.Equal(t, BatchLineRejected, output.Results[0].Status)
+	assert.Equal(t, BatchLineFailed, output.Results[1].Status)
+	assert.Equal(t, string(apperrors.CodeInvalidQuantity), output.Results[1].Error.Code)
+}
+
+func TestBatchAdjustInventoryCommand_BestEffort(t *testing.T) {
+	cmd, repo := newBatchFixture()
+
+	output, err := cmd.Execute(context.Background(), BatchAdjustInventoryInput{
+		Mode: BatchModeBestEffort,
+		Lines: []BatchAdjustmentLine{
+			{ProductID: "p1", Adjustment: 5},
+			{ProductID: "unknown", Adjustment: 1},
+			{ProductID: "p1", Adjustment: -12},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, output.Committed)
+	assert.Equal(t, 2, output.Applied)
+	assert.Equal(t, string(apperrors.CodeProductNotFound), output.Results[1].Error.Code)
+
+	// Lines for the same product accumulate and are written once, in one transaction
+	assert.Equal(t, 3, output.Results[2].Quantity)
+	require.Len(t, repo.batches, 1)
+	require.Len(t, repo.batches[0], 1)
+	assert.Equal(t, 3, repo.batches[0][0].Quantity())
+
+	// Inventory for the whole batch is read in one lookup
+	assert.Equal(t, 1, repo.lookups)
+}
+
+func TestBatchAdjustInventoryCommand_BatchSizeBound(t *testing.T) {
+	cmd, _ := newBatchFixture()
+
+	_, err := cmd.Execute(context.Background(), BatchAdjustInventoryInput{
+		Lines: make([]BatchAdjustmentLine, MaxBatchAdjustmentLines+1),
+	})
+	assert.True(t, apperrors.Is(err, apperrors.CodeInvalidInput))
+}
+
+func TestBatchAdjustInventoryCommand_AuditsEachProductWithItsLines(t *testing.T) {
+	repo := &fakeBatchInventoryRepository{quantities: map[string]int{"p1": 10, "p2": 5}}
+	store := &fakeAuditStore{}
+	uow := &fakeUnitOfWork{repos: inventory.TxRepositories{InventoryCommands: repo, InventoryQueries: repo, Audit: store}}
+	cmd := NewBatchAdjustInventoryCommand(uow, fakeBatchProducts{"p1", "p2"}, nil, audit.NewRecorder(store), nil)
+
+	_, err := cmd.Execute(context.Background(), BatchAdjustInventoryInput{
+		Mode: BatchModeBestEffort,
+		Lines: []BatchAdjustmentLine{
+			{ProductID: "p1", Adjustment: 5},
+			{ProductID: "p2", Adjustment: 1},
+			{ProductID: "p1", Adjustment: -12},
+			{ProductID: "p2", Adjustment: -50},
+		},
+	})
+	require.NoError(t, err)
+
+	// Each entry holds only the applied lines of its own product
+	inputs := make(map[string]string, len(store.entries))
+	for _, entry := range store.entries {
+		inputs[entry.AggregateID] = string(entry.Input)
+	}
+	require.Len(t, inputs, 2)
+	assert.JSONEq(t, `{"mode":"best_effort","lines":[
+		{"product_id":"p1","adjustment":5,"reason":"","unit_cost":null},
+		{"product_id":"p1","adjustment":-12,"reason":"","unit_cost":null}]}`, inputs["p1"])
+	assert.JSONEq(t, `{"mode":"best_effort","lines":[
+		{"product_id":"p2","adjustment":1,"reason":"","unit_cost":null}]}`, inputs["p2"])
+}
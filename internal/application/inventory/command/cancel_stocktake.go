@@ -0,0 +1,69 @@
+package command
+
+import (
+	"context"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/inventory/query"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// CancelStocktakeCommand closes a stocktake session without posting any variance
+type CancelStocktakeCommand struct {
+	uow      inventory.UnitOfWork
+	recorder *audit.Recorder
+}
+
+// NewCancelStocktakeCommand creates a new instance of CancelStocktakeCommand
+func NewCancelStocktakeCommand(
+	uow inventory.UnitOfWork,
+	recorder *audit.Recorder,
+) *CancelStocktakeCommand {
+	return &CancelStocktakeCommand{
+		uow:      uow,
+		recorder: recorder,
+	}
+}
+
+// Execute cancels the stocktake
+func (c *CancelStocktakeCommand) Execute(ctx context.Context, stocktakeID string) (*query.StocktakeOutput, error) {
+	// Validate input
+	if stocktakeID == "" {
+		return nil, apperrors.New(apperrors.CodeInvalidInput, "stocktake ID is required")
+	}
+
+	var output *query.StocktakeOutput
+	err := c.uow.Do(ctx, func(ctx context.Context, repos inventory.TxRepositories) error {
+		st, err := repos.StocktakeQueries.GetByID(ctx, stocktakeID)
+		if err != nil {
+			return apperrors.WrapDatabaseError(err)
+		}
+		if st == nil {
+			return inventory.ErrStocktakeNotFound
+		}
+		before := query.NewStocktakeOutput(st)
+
+		if err := st.Cancel(); err != nil {
+			return err
+		}
+
+		if err := repos.StocktakeCommands.Update(ctx, st); err != nil {
+			return apperrors.WrapDatabaseError(err)
+		}
+
+		output = query.NewStocktakeOutput(st)
+		return c.recorder.InTx(repos.Audit).Record(ctx, audit.Change{
+			Command:       "CancelStocktake",
+			Input:         stocktakeInput{StocktakeID: stocktakeID},
+			AggregateType: AuditStocktake,
+			AggregateID:   stocktakeID,
+			Before:        before,
+			After:         output,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
+}
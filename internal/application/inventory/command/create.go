@@ -6,15 +6,21 @@ import (
 
 	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/inventory/query"
 	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
 	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
 	"github.com/google/uuid"
 )
 
 // CreateInventoryInput represents the input for creating inventory
+// StockPolicy defaults to strict; BackorderLimit only applies to the backorder policy
+// UnitCost values the opening stock in the product's price currency (zero when omitted)
 type CreateInventoryInput struct {
-	ProductID string `json:"product_id" validate:"required"`
-	Quantity  int    `json:"quantity" validate:"required,min=0"`
-	Location  string `json:"location"`
+	ProductID      string   `json:"product_id" validate:"required"`
+	Quantity       int      `json:"quantity" validate:"required,min=0"`
+	Location       string   `json:"location"`
+	StockPolicy    string   `json:"stock_policy" validate:"omitempty,oneof=strict backorder unlimited"`
+	BackorderLimit int      `json:"backorder_limit" validate:"min=0"`
+	UnitCost       *float64 `json:"unit_cost" validate:"omitempty,min=0"`
 }
 
 // CreateInventoryOutput represents the output after creating inventory
@@ -25,6 +31,8 @@ type CreateInventoryOutput struct {
 	Quantity          int       `json:"quantity"`
 	ReservedQuantity  int       `json:"reserved_quantity"`
 	AvailableQuantity int       `json:"available_quantity"`
+	StockPolicy       string    `json:"stock_policy"`
+	BackorderLimit    int       `json:"backorder_limit"`
 	Location          string    `json:"location"`
 	CreatedAt         time.Time `json:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at"`
@@ -32,22 +40,26 @@ type CreateInventoryOutput struct {
 
 // CreateInventoryCommand handles the business logic for creating inventory
 type CreateInventoryCommand struct {
-	inventoryCmdRepo inventory.InventoryCommandRepository
-	inventoryQueryRepo inventory.InventoryQueryRepository
-	productQuery  query.ProductQueryInterface
+	uow          inventory.UnitOfWork
+	productQuery query.ProductQueryInterface
+	valuator     *StockValuator
+	recorder     *audit.Recorder
 }
 
 // NewCreateInventoryCommand creates a new instance of CreateInventoryCommand
 // This demonstrates module communication: Inventory → Product
+// The existence check, the insert and the opening valuation share one transaction
 func NewCreateInventoryCommand(
-	inventoryCmdRepo inventory.InventoryCommandRepository,
-	inventoryQueryRepo inventory.InventoryQueryRepository,
+	uow inventory.UnitOfWork,
 	productQuery query.ProductQueryInterface,
+	valuator *StockValuator,
+	recorder *audit.Recorder,
 ) *CreateInventoryCommand {
 	return &CreateInventoryCommand{
-		inventoryCmdRepo:  inventoryCmdRepo,
-		inventoryQueryRepo: inventoryQueryRepo,
-		productQuery:  productQuery,
+		uow:          uow,
+		productQuery: productQuery,
+		valuator:     valuator,
+		recorder:     recorder,
 	}
 }
 
@@ -60,6 +72,10 @@ func (c *CreateInventoryCommand) Execute(ctx context.Context, input CreateInvent
 	if input.Quantity < 0 {
 		return nil, inventory.ErrInvalidQuantity
 	}
+	stockPolicy, err := inventory.NewStockPolicy(inventory.StockPolicyType(input.StockPolicy), input.BackorderLimit)
+	if err != nil {
+		return nil, err
+	}
 
 	// MODULE COMMUNICATION: Call Product module to verify product exists
 	productOutput, err := c.productQuery.Execute(ctx, input.ProductID)
@@ -70,15 +86,6 @@ func (c *CreateInventoryCommand) Execute(ctx context.Context, input CreateInvent
 		return nil, err
 	}
 
-	// Check if inventory already exists for this product
-	existingInventory, err := c.inventoryQueryRepo.GetByProductID(ctx, input.ProductID)
-	if err != nil {
-		return nil, apperrors.WrapDatabaseError(err)
-	}
-	if existingInventory != nil {
-		return nil, inventory.ErrInventoryExists
-	}
-
 	// Create new inventory entity
 	inv, err := inventory.NewInventory(
 		uuid.New().String(),
@@ -89,10 +96,40 @@ func (c *CreateInventoryCommand) Execute(ctx context.Context, input CreateInvent
 	if err != nil {
 		return nil, err
 	}
+	if err := inv.SetStockPolicy(stockPolicy); err != nil {
+		return nil, err
+	}
 
-	// Save to repository
-	if err := c.inventoryCmdRepo.Create(ctx, inv); err != nil {
-		return nil, apperrors.WrapDatabaseError(err)
+	err = c.uow.Do(ctx, func(ctx context.Context, repos inventory.TxRepositories) error {
+		// Check if inventory already exists for this product
+		existingInventory, err := repos.InventoryQueries.GetByProductID(ctx, input.ProductID)
+		if err != nil {
+			return apperrors.WrapDatabaseError(err)
+		}
+		if existingInventory != nil {
+			return inventory.ErrInventoryExists
+		}
+
+		// Save to repository
+		if err := repos.InventoryCommands.Create(ctx, inv); err != nil {
+			return apperrors.WrapDatabaseError(err)
+		}
+
+		// Start valuing the stock in the product's price currency
+		if err := c.valuator.InTx(repos).Open(ctx, inv.ProductID(), productOutput.PriceCurrency, inv.Quantity(), input.UnitCost); err != nil {
+			return err
+		}
+
+		return c.recorder.InTx(repos.Audit).Record(ctx, audit.Change{
+			Command:       "CreateInventory",
+			Input:         input,
+			AggregateType: AuditInventory,
+			AggregateID:   inv.ProductID(),
+			After:         inventoryStateOf(inv),
+		})
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Return output DTO with product information
@@ -103,9 +140,10 @@ func (c *CreateInventoryCommand) Execute(ctx context.Context, input CreateInvent
 		Quantity:          inv.Quantity(),
 		ReservedQuantity:  inv.ReservedQuantity(),
 		AvailableQuantity: inv.AvailableQuantity(),
+		StockPolicy:       string(inv.StockPolicy().Type()),
+		BackorderLimit:    inv.StockPolicy().BackorderLimit(),
 		Location:          inv.Location(),
 		CreatedAt:         inv.CreatedAt(),
 		UpdatedAt:         inv.UpdatedAt(),
 	}, nil
 }
-
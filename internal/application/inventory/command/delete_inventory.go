@@ -0,0 +1,80 @@
+package command
+
+import (
+	"context"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// DeleteInventoryCommand handles the business logic for removing an obsolete inventory record
+type DeleteInventoryCommand struct {
+	uow      inventory.UnitOfWork
+	valuator *StockValuator
+	recorder *audit.Recorder
+}
+
+// NewDeleteInventoryCommand creates a new instance of DeleteInventoryCommand
+// The record and its valuation are removed in one transaction
+func NewDeleteInventoryCommand(
+	uow inventory.UnitOfWork,
+	valuator *StockValuator,
+	recorder *audit.Recorder,
+) *DeleteInventoryCommand {
+	return &DeleteInventoryCommand{
+		uow:      uow,
+		valuator: valuator,
+		recorder: recorder,
+	}
+}
+
+// Execute performs the delete inventory operation
+// Removal is refused while any stock is reserved or backordered
+func (c *DeleteInventoryCommand) Execute(ctx context.Context, productID string) error {
+	// Validate input
+	if productID == "" {
+		return apperrors.New(apperrors.CodeInvalidInput, "product ID is required")
+	}
+
+	// The delete checks the loaded version, so stock reserved or backordered after the check
+	// fails it; the retry reloads the inventory and refuses the removal
+	return retryOnConflict(ctx, func() error {
+		return c.uow.Do(ctx, func(ctx context.Context, repos inventory.TxRepositories) error {
+			inv, err := repos.InventoryQueries.GetByProductID(ctx, productID)
+			if err != nil {
+				return apperrors.WrapDatabaseError(err)
+			}
+			if inv == nil {
+				return inventory.ErrInventoryNotFound
+			}
+
+			before := inventoryStateOf(inv)
+			if err := inv.Remove(); err != nil {
+				return err
+			}
+
+			if err := repos.InventoryCommands.Delete(ctx, inv); err != nil {
+				return apperrors.WrapDatabaseError(err)
+			}
+
+			// The stock no longer exists, so neither does its value
+			if err := c.valuator.InTx(repos).Close(ctx, productID); err != nil {
+				return err
+			}
+
+			return c.recorder.InTx(repos.Audit).Record(ctx, audit.Change{
+				Command:       "DeleteInventory",
+				Input:         deleteInventoryInput{ProductID: productID},
+				AggregateType: AuditInventory,
+				AggregateID:   productID,
+				Before:        before,
+			})
+		})
+	})
+}
+
+// deleteInventoryInput is the input of DeleteInventoryCommand as the audit trail records it
+type deleteInventoryInput struct {
+	ProductID string `json:"product_id"`
+}
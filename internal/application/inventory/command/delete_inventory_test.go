@@ -0,0 +1,84 @@
+package command
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDeleteInventoryRepository serves one inventory and deletes it with a version check
+type fakeDeleteInventoryRepository struct {
+	inventory.InventoryRepository
+	reserved int
+	version  int
+	deleted  bool
+	deletes  int
+	// beforeDelete runs before every delete, to simulate a concurrent change
+	beforeDelete func()
+}
+
+func (r *fakeDeleteInventoryRepository) GetByProductID(ctx context.Context, productID string) (*inventory.Inventory, error) {
+	if r.deleted {
+		return nil, nil
+	}
+	now := time.Now()
+	return inventory.ReconstructInventory("inv-"+productID, productID, 10, r.reserved, 0,
+		inventory.StrictStockPolicy(), "Warehouse A", nil, now, now, r.version), nil
+}
+
+func (r *fakeDeleteInventoryRepository) Delete(ctx context.Context, inv *inventory.Inventory) error {
+	r.deletes++
+	if r.beforeDelete != nil {
+		r.beforeDelete()
+		r.beforeDelete = nil
+	}
+	if inv.Version() != r.version || r.reserved != 0 {
+		return inventory.ErrConcurrentModification
+	}
+	r.deleted = true
+	return nil
+}
+
+func newDeleteFixture(repo *fakeDeleteInventoryRepository) *DeleteInventoryCommand {
+	uow := &fakeUnitOfWork{repos: inventory.TxRepositories{InventoryCommands: repo, InventoryQueries: repo}}
+	return NewDeleteInventoryCommand(uow, nil, nil)
+}
+
+func TestDeleteInventoryCommand_RefusesReservedStock(t *testing.T) {
+	repo := &fakeDeleteInventoryRepository{reserved: 2, version: 1}
+
+	err := newDeleteFixture(repo).Execute(context.Background(), "p1")
+	assert.True(t, apperrors.Is(err, apperrors.CodeInventoryReserved))
+	assert.Zero(t, repo.deletes)
+	assert.False(t, repo.deleted)
+}
+
+func TestDeleteInventoryCommand_RefusesStockReservedAfterTheCheck(t *testing.T) {
+	repo := &fakeDeleteInventoryRepository{version: 1}
+	// A reservation commits between the check and the delete
+	repo.beforeDelete = func() {
+		repo.reserved = 2
+		repo.version++
+	}
+
+	err := newDeleteFixture(repo).Execute(context.Background(), "p1")
+	assert.True(t, apperrors.Is(err, apperrors.CodeInventoryReserved))
+	assert.Equal(t, 1, repo.deletes)
+	assert.False(t, repo.deleted)
+}
+
+func TestDeleteInventoryCommand_RetriesAStaleVersion(t *testing.T) {
+	repo := &fakeDeleteInventoryRepository{version: 1}
+	// An adjustment commits between the load and the delete
+	repo.beforeDelete = func() { repo.version++ }
+
+	err := newDeleteFixture(repo).Execute(context.Background(), "p1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.deletes)
+	assert.True(t, repo.deleted)
+}
@@ -0,0 +1,97 @@
+package command
+
+import (
+	"context"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/inventory/query"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// OpenStocktakeInput represents the input for opening a stocktake session
+// At least one product ID or location must be given; both may be combined
+type OpenStocktakeInput struct {
+	ProductIDs []string `json:"product_ids"`
+	Locations  []string `json:"locations"`
+}
+
+// OpenStocktakeCommand handles the business logic for opening a stocktake session
+type OpenStocktakeCommand struct {
+	uow      inventory.UnitOfWork
+	recorder *audit.Recorder
+}
+
+// NewOpenStocktakeCommand creates a new instance of OpenStocktakeCommand
+func NewOpenStocktakeCommand(
+	uow inventory.UnitOfWork,
+	recorder *audit.Recorder,
+) *OpenStocktakeCommand {
+	return &OpenStocktakeCommand{
+		uow:      uow,
+		recorder: recorder,
+	}
+}
+
+// Execute opens a stocktake, freezing the expected quantity of every selected inventory record
+func (c *OpenStocktakeCommand) Execute(ctx context.Context, input OpenStocktakeInput) (*query.StocktakeOutput, error) {
+	// Validate input
+	if len(input.ProductIDs) == 0 && len(input.Locations) == 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidInput, "at least one product ID or location is required")
+	}
+
+	var output *query.StocktakeOutput
+	err := c.uow.Do(ctx, func(ctx context.Context, repos inventory.TxRepositories) error {
+		// Collect the inventory records to snapshot
+		var snapshot []*inventory.Inventory
+		if len(input.ProductIDs) > 0 {
+			inventories, err := repos.InventoryQueries.GetByProductIDs(ctx, input.ProductIDs)
+			if err != nil {
+				return apperrors.WrapDatabaseError(err)
+			}
+			byProductID := make(map[string]*inventory.Inventory, len(inventories))
+			for _, inv := range inventories {
+				byProductID[inv.ProductID()] = inv
+			}
+			for _, productID := range input.ProductIDs {
+				inv, ok := byProductID[productID]
+				if !ok {
+					return apperrors.Newf(apperrors.CodeInventoryNotFound, "inventory not found for product %s", productID)
+				}
+				snapshot = append(snapshot, inv)
+			}
+		}
+		for _, location := range input.Locations {
+			inventories, err := repos.InventoryQueries.ListByLocation(ctx, location)
+			if err != nil {
+				return apperrors.WrapDatabaseError(err)
+			}
+			snapshot = append(snapshot, inventories...)
+		}
+
+		// Create stocktake entity with the frozen snapshot
+		st, err := inventory.NewStocktake(uuid.New().String(), snapshot)
+		if err != nil {
+			return err
+		}
+
+		// Save to repository
+		if err := repos.StocktakeCommands.Create(ctx, st); err != nil {
+			return apperrors.WrapDatabaseError(err)
+		}
+
+		output = query.NewStocktakeOutput(st)
+		return c.recorder.InTx(repos.Audit).Record(ctx, audit.Change{
+			Command:       "OpenStocktake",
+			Input:         input,
+			AggregateType: AuditStocktake,
+			AggregateID:   st.ID(),
+			After:         output,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
+}
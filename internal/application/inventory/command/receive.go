@@ -0,0 +1,44 @@
+package command
+
+import (
+	"context"
+
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// AdjustmentReasonReceipt marks adjustments posted by stock receipts
+const AdjustmentReasonReceipt = "receipt"
+
+// ReceiveStockInput represents the input for receiving stock at a known unit cost
+type ReceiveStockInput struct {
+	ProductID string  `json:"product_id" validate:"required"`
+	Quantity  int     `json:"quantity" validate:"required,min=1"`
+	UnitCost  float64 `json:"unit_cost" validate:"min=0"`
+}
+
+// ReceiveStockCommand posts an inbound receipt as a costed positive adjustment
+type ReceiveStockCommand struct {
+	adjustCommand *AdjustInventoryCommand
+}
+
+// NewReceiveStockCommand creates a new instance of ReceiveStockCommand
+func NewReceiveStockCommand(adjustCommand *AdjustInventoryCommand) *ReceiveStockCommand {
+	return &ReceiveStockCommand{
+		adjustCommand: adjustCommand,
+	}
+}
+
+// Execute performs the receive stock operation
+func (c *ReceiveStockCommand) Execute(ctx context.Context, input ReceiveStockInput) (*AdjustInventoryOutput, error) {
+	if input.Quantity <= 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidQuantity, "receipt quantity must be positive")
+	}
+
+	unitCost := input.UnitCost
+	return c.adjustCommand.execute(ctx, AdjustInventoryInput{
+		ProductID:  input.ProductID,
+		Adjustment: input.Quantity,
+		Reason:     AdjustmentReasonReceipt,
+		UnitCost:   &unitCost,
+	}, "ReceiveStock", input)
+}
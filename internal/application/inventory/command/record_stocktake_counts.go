@@ -0,0 +1,89 @@
+package command
+
+import (
+	"context"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/inventory/query"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// StocktakeCountInput represents a single counted quantity
+type StocktakeCountInput struct {
+	ProductID       string `json:"product_id" validate:"required"`
+	CountedQuantity int    `json:"counted_quantity" validate:"min=0"`
+}
+
+// RecordStocktakeCountsInput represents the input for submitting counts to a stocktake
+// Counts may cover only part of the session and may be resubmitted as recounts
+type RecordStocktakeCountsInput struct {
+	StocktakeID string                `json:"-"`
+	Counts      []StocktakeCountInput `json:"counts" validate:"required,min=1,dive"`
+}
+
+// RecordStocktakeCountsCommand handles the business logic for submitting stocktake counts
+type RecordStocktakeCountsCommand struct {
+	uow      inventory.UnitOfWork
+	recorder *audit.Recorder
+}
+
+// NewRecordStocktakeCountsCommand creates a new instance of RecordStocktakeCountsCommand
+func NewRecordStocktakeCountsCommand(
+	uow inventory.UnitOfWork,
+	recorder *audit.Recorder,
+) *RecordStocktakeCountsCommand {
+	return &RecordStocktakeCountsCommand{
+		uow:      uow,
+		recorder: recorder,
+	}
+}
+
+// Execute records the submitted counts and returns the recomputed variances
+func (c *RecordStocktakeCountsCommand) Execute(ctx context.Context, input RecordStocktakeCountsInput) (*query.StocktakeOutput, error) {
+	// Validate input
+	if input.StocktakeID == "" {
+		return nil, apperrors.New(apperrors.CodeInvalidInput, "stocktake ID is required")
+	}
+	if len(input.Counts) == 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidInput, "at least one count is required")
+	}
+
+	var output *query.StocktakeOutput
+	err := c.uow.Do(ctx, func(ctx context.Context, repos inventory.TxRepositories) error {
+		st, err := repos.StocktakeQueries.GetByID(ctx, input.StocktakeID)
+		if err != nil {
+			return apperrors.WrapDatabaseError(err)
+		}
+		if st == nil {
+			return inventory.ErrStocktakeNotFound
+		}
+		before := query.NewStocktakeOutput(st)
+
+		// Apply counts to the stocktake entity (business logic)
+		for _, count := range input.Counts {
+			if err := st.RecordCount(count.ProductID, count.CountedQuantity); err != nil {
+				return err
+			}
+		}
+
+		// Save updated stocktake
+		if err := repos.StocktakeCommands.Update(ctx, st); err != nil {
+			return apperrors.WrapDatabaseError(err)
+		}
+
+		output = query.NewStocktakeOutput(st)
+		return c.recorder.InTx(repos.Audit).Record(ctx, audit.Change{
+			Command:       "RecordStocktakeCounts",
+			Input:         input,
+			AggregateType: AuditStocktake,
+			AggregateID:   input.StocktakeID,
+			Before:        before,
+			After:         output,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
+}
@@ -0,0 +1,153 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/eventbus"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// ReservationInput represents the input for reserving or releasing inventory
+type ReservationInput struct {
+	ProductID string `json:"product_id" validate:"required"`
+	Quantity  int    `json:"quantity" validate:"required,min=1"`
+}
+
+// ReservationOutput represents the stock levels after a reservation change
+type ReservationOutput struct {
+	ProductID           string    `json:"product_id"`
+	Quantity            int       `json:"quantity"`
+	ReservedQuantity    int       `json:"reserved_quantity"`
+	AvailableQuantity   int       `json:"available_quantity"`
+	BackorderedQuantity int       `json:"backordered_quantity"`
+	StockPolicy         string    `json:"stock_policy"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// ReserveInventoryCommand handles the business logic for reserving stock
+// Shortfalls are backordered when the product's stock policy allows it
+type ReserveInventoryCommand struct {
+	uow        inventory.UnitOfWork
+	recorder   *audit.Recorder
+	dispatcher *eventbus.Dispatcher
+}
+
+// NewReserveInventoryCommand creates a new instance of ReserveInventoryCommand
+func NewReserveInventoryCommand(
+	uow inventory.UnitOfWork,
+	recorder *audit.Recorder,
+	dispatcher *eventbus.Dispatcher,
+) *ReserveInventoryCommand {
+	return &ReserveInventoryCommand{
+		uow:        uow,
+		recorder:   recorder,
+		dispatcher: dispatcher,
+	}
+}
+
+// Execute performs the reserve operation
+func (c *ReserveInventoryCommand) Execute(ctx context.Context, input ReservationInput) (*ReservationOutput, error) {
+	output, inv, err := changeReservation(ctx, c.uow, c.recorder, "ReserveInventory", input, (*inventory.Inventory).Reserve)
+	if err != nil {
+		return nil, err
+	}
+	c.dispatcher.Dispatch(ctx, inv.PullEvents()...)
+	return output, nil
+}
+
+// ReleaseInventoryCommand handles the business logic for releasing reserved stock
+type ReleaseInventoryCommand struct {
+	uow        inventory.UnitOfWork
+	recorder   *audit.Recorder
+	dispatcher *eventbus.Dispatcher
+}
+
+// NewReleaseInventoryCommand creates a new instance of ReleaseInventoryCommand
+func NewReleaseInventoryCommand(
+	uow inventory.UnitOfWork,
+	recorder *audit.Recorder,
+	dispatcher *eventbus.Dispatcher,
+) *ReleaseInventoryCommand {
+	return &ReleaseInventoryCommand{
+		uow:        uow,
+		recorder:   recorder,
+		dispatcher: dispatcher,
+	}
+}
+
+// Execute performs the release operation
+func (c *ReleaseInventoryCommand) Execute(ctx context.Context, input ReservationInput) (*ReservationOutput, error) {
+	output, inv, err := changeReservation(ctx, c.uow, c.recorder, "ReleaseInventory", input, (*inventory.Inventory).Release)
+	if err != nil {
+		return nil, err
+	}
+	c.dispatcher.Dispatch(ctx, inv.PullEvents()...)
+	return output, nil
+}
+
+// changeReservation loads the inventory, applies the domain operation and saves the result
+// with its audit entry; it also returns the changed inventory
+func changeReservation(
+	ctx context.Context,
+	uow inventory.UnitOfWork,
+	recorder *audit.Recorder,
+	command string,
+	input ReservationInput,
+	apply func(inv *inventory.Inventory, quantity int) error,
+) (*ReservationOutput, *inventory.Inventory, error) {
+	// Validate input
+	if input.ProductID == "" {
+		return nil, nil, apperrors.New(apperrors.CodeInvalidInput, "product ID is required")
+	}
+
+	// Retried with a fresh copy if the inventory changed concurrently
+	var inv *inventory.Inventory
+	err := retryOnConflict(ctx, func() error {
+		return uow.Do(ctx, func(ctx context.Context, repos inventory.TxRepositories) error {
+			var err error
+			inv, err = repos.InventoryQueries.GetByProductID(ctx, input.ProductID)
+			if err != nil {
+				return apperrors.WrapDatabaseError(err)
+			}
+			if inv == nil {
+				return inventory.ErrInventoryNotFound
+			}
+			before := inventoryStateOf(inv)
+
+			// Apply reservation change to inventory entity (business logic)
+			if err := apply(inv, input.Quantity); err != nil {
+				return err
+			}
+
+			// Save updated inventory (fails on a stale version)
+			if err := repos.InventoryCommands.Update(ctx, inv); err != nil {
+				return apperrors.WrapDatabaseError(err)
+			}
+
+			return recorder.InTx(repos.Audit).Record(ctx, audit.Change{
+				Command:       command,
+				Input:         input,
+				AggregateType: AuditInventory,
+				AggregateID:   inv.ProductID(),
+				Before:        before,
+				After:         inventoryStateOf(inv),
+			})
+		})
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &ReservationOutput{
+		ProductID:           inv.ProductID(),
+		Quantity:            inv.Quantity(),
+		ReservedQuantity:    inv.ReservedQuantity(),
+		AvailableQuantity:   inv.AvailableQuantity(),
+		BackorderedQuantity: inv.BackorderedQuantity(),
+		StockPolicy:         string(inv.StockPolicy().Type()),
+		UpdatedAt:           inv.UpdatedAt(),
+	}, inv, nil
+}
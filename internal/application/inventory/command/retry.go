@@ -0,0 +1,26 @@
+package command
+
+import (
+	"context"
+	"errors"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+)
+
+// maxConflictAttempts bounds how often a command reloads and retries after a concurrency conflict
+const maxConflictAttempts = 3
+
+// retryOnConflict runs fn again while it fails because an inventory changed after it was loaded
+// fn must reload the aggregate on every attempt so the retry sees the latest version.
+// Transactions the database aborts (serialization failures, deadlocks) share the conflict code
+// but are already rerun by the unit of work, so they are not retried a second time here.
+func retryOnConflict(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 0; attempt < maxConflictAttempts; attempt++ {
+		err = fn()
+		if !errors.Is(err, inventory.ErrConcurrentModification) || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
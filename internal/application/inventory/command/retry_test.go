@@ -0,0 +1,51 @@
+package command
+
+import (
+	"context"
+	"testing"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+func TestRetryOnConflict(t *testing.T) {
+	conflict := inventory.ErrConcurrentModification
+	aborted := apperrors.New(apperrors.CodeConcurrencyConflict, "Transaction conflicted with a concurrent update")
+	other := apperrors.New(apperrors.CodeInsufficientStock, "insufficient")
+
+	tests := []struct {
+		name        string
+		results     []error
+		wantCalls   int
+		wantErrCode apperrors.ErrorCode
+	}{
+		{name: "succeeds first time", results: []error{nil}, wantCalls: 1},
+		{name: "succeeds after conflict", results: []error{conflict, nil}, wantCalls: 2},
+		{name: "other errors are not retried", results: []error{other}, wantCalls: 1, wantErrCode: apperrors.CodeInsufficientStock},
+		{name: "aborted transactions are left to the unit of work", results: []error{aborted, nil}, wantCalls: 1, wantErrCode: apperrors.CodeConcurrencyConflict},
+		{name: "gives up after bounded attempts", results: []error{conflict, conflict, conflict, nil}, wantCalls: maxConflictAttempts, wantErrCode: apperrors.CodeConcurrencyConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := retryOnConflict(context.Background(), func() error {
+				result := tt.results[calls]
+				calls++
+				return result
+			})
+			if calls != tt.wantCalls {
+				t.Errorf("retryOnConflict() calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if tt.wantErrCode == "" {
+				if err != nil {
+					t.Errorf("retryOnConflict() unexpected error = %v", err)
+				}
+				return
+			}
+			if !apperrors.Is(err, tt.wantErrCode) {
+				t.Errorf("retryOnConflict() error = %v, want code %s", err, tt.wantErrCode)
+			}
+		})
+	}
+}
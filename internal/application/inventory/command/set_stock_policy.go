@@ -0,0 +1,99 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// SetStockPolicyInput represents the input for changing a product's stock policy
+type SetStockPolicyInput struct {
+	ProductID      string `json:"-"`
+	StockPolicy    string `json:"stock_policy" validate:"required,oneof=strict backorder unlimited"`
+	BackorderLimit int    `json:"backorder_limit" validate:"min=0"`
+}
+
+// SetStockPolicyOutput represents the output after changing a stock policy
+type SetStockPolicyOutput struct {
+	ProductID           string    `json:"product_id"`
+	StockPolicy         string    `json:"stock_policy"`
+	BackorderLimit      int       `json:"backorder_limit"`
+	BackorderedQuantity int       `json:"backordered_quantity"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// SetStockPolicyCommand handles the business logic for changing a product's stock policy
+type SetStockPolicyCommand struct {
+	uow      inventory.UnitOfWork
+	recorder *audit.Recorder
+}
+
+// NewSetStockPolicyCommand creates a new instance of SetStockPolicyCommand
+func NewSetStockPolicyCommand(
+	uow inventory.UnitOfWork,
+	recorder *audit.Recorder,
+) *SetStockPolicyCommand {
+	return &SetStockPolicyCommand{
+		uow:      uow,
+		recorder: recorder,
+	}
+}
+
+// Execute performs the set stock policy operation
+func (c *SetStockPolicyCommand) Execute(ctx context.Context, input SetStockPolicyInput) (*SetStockPolicyOutput, error) {
+	// Validate input
+	if input.ProductID == "" {
+		return nil, apperrors.New(apperrors.CodeInvalidInput, "product ID is required")
+	}
+	policy, err := inventory.NewStockPolicy(inventory.StockPolicyType(input.StockPolicy), input.BackorderLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retried with a fresh copy if the inventory changed concurrently
+	var inv *inventory.Inventory
+	err = retryOnConflict(ctx, func() error {
+		return c.uow.Do(ctx, func(ctx context.Context, repos inventory.TxRepositories) error {
+			var err error
+			inv, err = repos.InventoryQueries.GetByProductID(ctx, input.ProductID)
+			if err != nil {
+				return apperrors.WrapDatabaseError(err)
+			}
+			if inv == nil {
+				return inventory.ErrInventoryNotFound
+			}
+			before := inventoryStateOf(inv)
+
+			if err := inv.SetStockPolicy(policy); err != nil {
+				return err
+			}
+
+			if err := repos.InventoryCommands.Update(ctx, inv); err != nil {
+				return apperrors.WrapDatabaseError(err)
+			}
+
+			return c.recorder.InTx(repos.Audit).Record(ctx, audit.Change{
+				Command:       "SetStockPolicy",
+				Input:         input,
+				AggregateType: AuditInventory,
+				AggregateID:   inv.ProductID(),
+				Before:        before,
+				After:         inventoryStateOf(inv),
+			})
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SetStockPolicyOutput{
+		ProductID:           inv.ProductID(),
+		StockPolicy:         string(inv.StockPolicy().Type()),
+		BackorderLimit:      inv.StockPolicy().BackorderLimit(),
+		BackorderedQuantity: inv.BackorderedQuantity(),
+		UpdatedAt:           inv.UpdatedAt(),
+	}, nil
+}
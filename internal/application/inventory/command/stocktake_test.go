@@ -0,0 +1,196 @@
+package command
+
+import (
+	"context"
+	"testing"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStocktakeRepository stores copies of stocktake sessions and, like the real
+// repositories, only writes a session that is still open
+type fakeStocktakeRepository struct {
+	sessions map[string]*inventory.Stocktake
+	// beforeWrite runs before every update, to simulate a concurrent change
+	beforeWrite func()
+}
+
+func (r *fakeStocktakeRepository) Create(ctx context.Context, st *inventory.Stocktake) error {
+	r.sessions[st.ID()] = copyStocktake(st)
+	return nil
+}
+
+func (r *fakeStocktakeRepository) Update(ctx context.Context, st *inventory.Stocktake) error {
+	if r.beforeWrite != nil {
+		r.beforeWrite()
+	}
+	stored, ok := r.sessions[st.ID()]
+	if !ok || stored.Status() != inventory.StocktakeStatusOpen {
+		return inventory.ErrStocktakeNotOpen
+	}
+	r.sessions[st.ID()] = copyStocktake(st)
+	return nil
+}
+
+func (r *fakeStocktakeRepository) Apply(ctx context.Context, st *inventory.Stocktake) error {
+	return r.Update(ctx, st)
+}
+
+func (r *fakeStocktakeRepository) GetByID(ctx context.Context, id string) (*inventory.Stocktake, error) {
+	st, ok := r.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	return copyStocktake(st), nil
+}
+
+// copyStocktake copies a session so stored and loaded sessions never share lines
+func copyStocktake(st *inventory.Stocktake) *inventory.Stocktake {
+	lines := make([]*inventory.StocktakeLine, 0, len(st.Lines()))
+	for _, l := range st.Lines() {
+		lines = append(lines, inventory.ReconstructStocktakeLine(l.ProductID(), l.Location(),
+			l.ExpectedQuantity(), l.CountedQuantity(), l.IsCounted(), l.CountAttempts(), l.IsApproved(), l.CountedAt()))
+	}
+	return inventory.ReconstructStocktake(st.ID(), st.Status(), lines, st.CreatedAt(), st.UpdatedAt(), st.AppliedAt())
+}
+
+type stocktakeFixture struct {
+	inventories *fakeBatchInventoryRepository
+	stocktakes  *fakeStocktakeRepository
+	audit       *fakeAuditStore
+	open        *OpenStocktakeCommand
+	count       *RecordStocktakeCountsCommand
+	approve     *ApproveStocktakeCommand
+	apply       *ApplyStocktakeCommand
+}
+
+func newStocktakeFixture() *stocktakeFixture {
+	f := &stocktakeFixture{
+		inventories: &fakeBatchInventoryRepository{quantities: map[string]int{"p1": 10, "p2": 5}},
+		stocktakes:  &fakeStocktakeRepository{sessions: map[string]*inventory.Stocktake{}},
+		audit:       &fakeAuditStore{},
+	}
+	uow := &fakeUnitOfWork{repos: inventory.TxRepositories{
+		InventoryCommands: f.inventories,
+		InventoryQueries:  f.inventories,
+		StocktakeCommands: f.stocktakes,
+		StocktakeQueries:  f.stocktakes,
+		Audit:             f.audit,
+	}}
+	recorder := audit.NewRecorder(f.audit)
+	f.open = NewOpenStocktakeCommand(uow, recorder)
+	f.count = NewRecordStocktakeCountsCommand(uow, recorder)
+	f.approve = NewApproveStocktakeCommand(uow, recorder)
+	f.apply = NewApplyStocktakeCommand(uow, nil, recorder, nil)
+	return f
+}
+
+// openAndCount opens a session over p1 and p2 and records the given counts
+func (f *stocktakeFixture) openAndCount(t *testing.T, counts ...StocktakeCountInput) string {
+	t.Helper()
+	ctx := context.Background()
+
+	opened, err := f.open.Execute(ctx, OpenStocktakeInput{ProductIDs: []string{"p1", "p2"}})
+	require.NoError(t, err)
+	_, err = f.count.Execute(ctx, RecordStocktakeCountsInput{StocktakeID: opened.ID, Counts: counts})
+	require.NoError(t, err)
+	return opened.ID
+}
+
+func TestStocktakeCommands_OpenCountApproveApply(t *testing.T) {
+	f := newStocktakeFixture()
+	ctx := context.Background()
+	id := f.openAndCount(t,
+		StocktakeCountInput{ProductID: "p1", CountedQuantity: 7},
+		StocktakeCountInput{ProductID: "p2", CountedQuantity: 5},
+	)
+
+	_, err := f.approve.Execute(ctx, ApproveStocktakeInput{StocktakeID: id})
+	require.NoError(t, err)
+	output, err := f.apply.Execute(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, string(inventory.StocktakeStatusApplied), output.Status)
+
+	// Only the line with a variance is posted
+	require.Len(t, f.inventories.batches, 1)
+	require.Len(t, f.inventories.batches[0], 1)
+	assert.Equal(t, "p1", f.inventories.batches[0][0].ProductID())
+	assert.Equal(t, 7, f.inventories.batches[0][0].Quantity())
+
+	// The adjusted inventory is audited with the cycle count reason
+	var adjusted *audit.Entry
+	for _, entry := range f.audit.entries {
+		if entry.Command == "ApplyStocktake" && entry.AggregateType == AuditInventory {
+			adjusted = entry
+		}
+	}
+	require.NotNil(t, adjusted)
+	assert.Equal(t, "p1", adjusted.AggregateID)
+	assert.JSONEq(t, `{"stocktake_id":"`+id+`","adjustment":-3,"reason":"cycle count"}`, string(adjusted.Input))
+
+	// An applied session cannot be applied again
+	_, err = f.apply.Execute(ctx, id)
+	assert.True(t, apperrors.Is(err, apperrors.CodeStocktakeNotOpen))
+	assert.Len(t, f.inventories.batches, 1)
+}
+
+func TestApplyStocktakeCommand_PostsStaleCountsOnTopOfLaterMovements(t *testing.T) {
+	f := newStocktakeFixture()
+	ctx := context.Background()
+	id := f.openAndCount(t, StocktakeCountInput{ProductID: "p1", CountedQuantity: 7})
+	_, err := f.approve.Execute(ctx, ApproveStocktakeInput{StocktakeID: id, ProductIDs: []string{"p1"}})
+	require.NoError(t, err)
+
+	// Stock received after the snapshot makes the count stale
+	f.inventories.quantities["p1"] = 15
+
+	_, err = f.apply.Execute(ctx, id)
+	require.NoError(t, err)
+
+	// The variance against the snapshot is applied, keeping the later receipt
+	require.Len(t, f.inventories.batches, 1)
+	assert.Equal(t, 12, f.inventories.batches[0][0].Quantity())
+}
+
+func TestApplyStocktakeCommand_SkipsLinesRecountedAfterApproval(t *testing.T) {
+	f := newStocktakeFixture()
+	ctx := context.Background()
+	id := f.openAndCount(t, StocktakeCountInput{ProductID: "p1", CountedQuantity: 7})
+	_, err := f.approve.Execute(ctx, ApproveStocktakeInput{StocktakeID: id})
+	require.NoError(t, err)
+
+	// A recount replaces the approved count and needs approving again
+	_, err = f.count.Execute(ctx, RecordStocktakeCountsInput{
+		StocktakeID: id,
+		Counts:      []StocktakeCountInput{{ProductID: "p1", CountedQuantity: 8}},
+	})
+	require.NoError(t, err)
+
+	_, err = f.apply.Execute(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, f.inventories.batches, 1)
+	assert.Empty(t, f.inventories.batches[0])
+}
+
+func TestApplyStocktakeCommand_FailsWhenTheSessionClosedConcurrently(t *testing.T) {
+	f := newStocktakeFixture()
+	ctx := context.Background()
+	id := f.openAndCount(t, StocktakeCountInput{ProductID: "p1", CountedQuantity: 7})
+	_, err := f.approve.Execute(ctx, ApproveStocktakeInput{StocktakeID: id})
+	require.NoError(t, err)
+
+	// Another request cancels the session after this one loaded it
+	f.stocktakes.beforeWrite = func() {
+		cancelled := copyStocktake(f.stocktakes.sessions[id])
+		require.NoError(t, cancelled.Cancel())
+		f.stocktakes.sessions[id] = cancelled
+	}
+
+	_, err = f.apply.Execute(ctx, id)
+	assert.True(t, apperrors.Is(err, apperrors.CodeStocktakeNotOpen))
+	assert.Equal(t, inventory.StocktakeStatusCancelled, f.stocktakes.sessions[id].Status())
+}
@@ -0,0 +1,104 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// UpdateInventoryInput represents the input for changing an inventory record's location and metadata
+// Omitted fields are left unchanged; metadata entries with an empty value are removed
+type UpdateInventoryInput struct {
+	ProductID string            `json:"-"`
+	Location  *string           `json:"location" validate:"omitempty,max=255"`
+	Metadata  map[string]string `json:"metadata"`
+}
+
+// UpdateInventoryOutput represents the output after updating an inventory record
+type UpdateInventoryOutput struct {
+	ID        string            `json:"id"`
+	ProductID string            `json:"product_id"`
+	Location  string            `json:"location"`
+	Metadata  map[string]string `json:"metadata"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// UpdateInventoryCommand handles the business logic for updating an inventory record
+type UpdateInventoryCommand struct {
+	uow      inventory.UnitOfWork
+	recorder *audit.Recorder
+}
+
+// NewUpdateInventoryCommand creates a new instance of UpdateInventoryCommand
+func NewUpdateInventoryCommand(
+	uow inventory.UnitOfWork,
+	recorder *audit.Recorder,
+) *UpdateInventoryCommand {
+	return &UpdateInventoryCommand{
+		uow:      uow,
+		recorder: recorder,
+	}
+}
+
+// Execute performs the update inventory operation
+func (c *UpdateInventoryCommand) Execute(ctx context.Context, input UpdateInventoryInput) (*UpdateInventoryOutput, error) {
+	// Validate input
+	if input.ProductID == "" {
+		return nil, apperrors.New(apperrors.CodeInvalidInput, "product ID is required")
+	}
+	if input.Location == nil && len(input.Metadata) == 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidInput, "location or metadata is required")
+	}
+
+	// Retried with a fresh copy if the inventory changed concurrently
+	var inv *inventory.Inventory
+	err := retryOnConflict(ctx, func() error {
+		return c.uow.Do(ctx, func(ctx context.Context, repos inventory.TxRepositories) error {
+			var err error
+			inv, err = repos.InventoryQueries.GetByProductID(ctx, input.ProductID)
+			if err != nil {
+				return apperrors.WrapDatabaseError(err)
+			}
+			if inv == nil {
+				return inventory.ErrInventoryNotFound
+			}
+			before := inventoryStateOf(inv)
+
+			if input.Location != nil {
+				inv.UpdateLocation(*input.Location)
+			}
+			if len(input.Metadata) > 0 {
+				if err := inv.UpdateMetadata(input.Metadata); err != nil {
+					return err
+				}
+			}
+
+			if err := repos.InventoryCommands.Update(ctx, inv); err != nil {
+				return apperrors.WrapDatabaseError(err)
+			}
+
+			return c.recorder.InTx(repos.Audit).Record(ctx, audit.Change{
+				Command:       "UpdateInventory",
+				Input:         input,
+				AggregateType: AuditInventory,
+				AggregateID:   inv.ProductID(),
+				Before:        before,
+				After:         inventoryStateOf(inv),
+			})
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &UpdateInventoryOutput{
+		ID:        inv.ID(),
+		ProductID: inv.ProductID(),
+		Location:  inv.Location(),
+		Metadata:  inv.Metadata(),
+		UpdatedAt: inv.UpdatedAt(),
+	}, nil
+}
@@ -0,0 +1,123 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// StockValuator records the cost side of stock movements
+// Commands constructed without a valuator leave valuation untouched
+type StockValuator struct {
+	valuationCmdRepo   inventory.ValuationCommandRepository
+	valuationQueryRepo inventory.ValuationQueryRepository
+	method             inventory.CostingMethod
+}
+
+// NewStockValuator creates a new StockValuator
+// The costing method applies to products whose valuation does not exist yet
+func NewStockValuator(
+	valuationCmdRepo inventory.ValuationCommandRepository,
+	valuationQueryRepo inventory.ValuationQueryRepository,
+	method inventory.CostingMethod,
+) *StockValuator {
+	return &StockValuator{
+		valuationCmdRepo:   valuationCmdRepo,
+		valuationQueryRepo: valuationQueryRepo,
+		method:             method,
+	}
+}
+
+// InTx returns a valuator that reads and writes through a unit of work's repositories
+func (v *StockValuator) InTx(repos inventory.TxRepositories) *StockValuator {
+	if v == nil {
+		return nil
+	}
+	return &StockValuator{
+		valuationCmdRepo:   repos.ValuationCommands,
+		valuationQueryRepo: repos.ValuationQueries,
+		method:             v.method,
+	}
+}
+
+// Open creates the valuation for a product and values its opening stock
+func (v *StockValuator) Open(ctx context.Context, productID, currency string, quantity int, unitCost *float64) error {
+	if v == nil {
+		return nil
+	}
+
+	valuation, err := inventory.NewStockValuation(productID, v.method, currency)
+	if err != nil {
+		return err
+	}
+	if quantity > 0 {
+		if err := valuation.Receive(uuid.New().String(), quantity, costOrZero(unitCost), time.Now()); err != nil {
+			return err
+		}
+	}
+
+	if err := v.valuationCmdRepo.Save(ctx, valuation); err != nil {
+		return apperrors.WrapDatabaseError(err)
+	}
+	return nil
+}
+
+// Record values a stock movement and returns the cost of goods consumed
+// Inbound movements without a unit cost are valued at the current average cost
+// Products without a valuation are skipped
+func (v *StockValuator) Record(ctx context.Context, productID string, adjustment int, unitCost *float64) (float64, error) {
+	if v == nil || adjustment == 0 {
+		return 0, nil
+	}
+
+	valuation, err := v.valuationQueryRepo.GetByProductID(ctx, productID)
+	if err != nil {
+		return 0, apperrors.WrapDatabaseError(err)
+	}
+	if valuation == nil {
+		return 0, nil
+	}
+
+	var consumed float64
+	if adjustment > 0 {
+		cost := valuation.AverageUnitCost()
+		if unitCost != nil {
+			cost = *unitCost
+		}
+		if err := valuation.Receive(uuid.New().String(), adjustment, cost, time.Now()); err != nil {
+			return 0, err
+		}
+	} else {
+		consumed, err = valuation.Issue(-adjustment)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := v.valuationCmdRepo.Save(ctx, valuation); err != nil {
+		return 0, apperrors.WrapDatabaseError(err)
+	}
+	return consumed, nil
+}
+
+// Close removes the valuation of a product whose inventory record is deleted
+func (v *StockValuator) Close(ctx context.Context, productID string) error {
+	if v == nil {
+		return nil
+	}
+	if err := v.valuationCmdRepo.Delete(ctx, productID); err != nil {
+		return apperrors.WrapDatabaseError(err)
+	}
+	return nil
+}
+
+// costOrZero treats a missing unit cost as zero
+func costOrZero(unitCost *float64) float64 {
+	if unitCost == nil {
+		return 0
+	}
+	return *unitCost
+}
@@ -0,0 +1,29 @@
+package contract
+
+// Messages the Inventory module answers on the module bus
+// Other modules depend on these types only, never on the Inventory module's queries.
+
+// GetStockLevel asks for the stock level of one product
+// It fails with CodeInventoryNotFound when the product has no inventory.
+type GetStockLevel struct {
+	ProductID string
+}
+
+// MessageName returns the name of the message on the bus
+func (GetStockLevel) MessageName() string { return "inventory.get_stock_level" }
+
+// GetStockLevels asks for the stock levels of several products at once
+// The result is keyed by product ID; products without inventory are left out.
+type GetStockLevels struct {
+	ProductIDs []string
+}
+
+// MessageName returns the name of the message on the bus
+func (GetStockLevels) MessageName() string { return "inventory.get_stock_levels" }
+
+// StockLevel is the stock data other modules need
+type StockLevel struct {
+	ProductID         string
+	Quantity          int
+	AvailableQuantity int
+}
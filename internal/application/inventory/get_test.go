@@ -30,9 +30,13 @@ func TestGetInventoryUseCase_Execute(t *testing.T) {
 					"product-123",
 					100,
 					20,
+					0,
+					inventory.StrictStockPolicy(),
 					"Warehouse A",
+					nil,
 					time.Now(),
 					time.Now(),
+					1,
 				)
 				mockRepo.On("GetByProductID", mock.Anything, "product-123").Return(inv, nil)
 				mockProduct.On("Execute", mock.Anything, "product-123").
@@ -74,9 +78,13 @@ func TestGetInventoryUseCase_Execute(t *testing.T) {
 					"product-123",
 					100,
 					20,
+					0,
+					inventory.StrictStockPolicy(),
 					"Warehouse A",
+					nil,
 					time.Now(),
 					time.Now(),
+					1,
 				)
 				mockRepo.On("GetByProductID", mock.Anything, "product-123").Return(inv, nil)
 				// Mock product deleted - should gracefully degrade
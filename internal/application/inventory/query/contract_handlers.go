@@ -0,0 +1,49 @@
+package query
+
+import (
+	"context"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/inventory/contract"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/bus"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// RegisterContractHandlers answers the Inventory module's contract messages on the module bus
+func RegisterContractHandlers(b *bus.Bus, inventoryRepo inventory.InventoryQueryRepository) {
+	getInventories := NewGetInventoriesByProductIDsQuery(inventoryRepo)
+
+	bus.HandleQuery(b, func(ctx context.Context, msg contract.GetStockLevel) (*contract.StockLevel, error) {
+		if msg.ProductID == "" {
+			return nil, apperrors.New(apperrors.CodeInvalidInput, "product ID is required")
+		}
+		inv, err := inventoryRepo.GetByProductID(ctx, msg.ProductID)
+		if err != nil {
+			return nil, apperrors.WrapDatabaseError(err)
+		}
+		if inv == nil {
+			return nil, inventory.ErrInventoryNotFound
+		}
+		return &contract.StockLevel{
+			ProductID:         inv.ProductID(),
+			Quantity:          inv.Quantity(),
+			AvailableQuantity: inv.AvailableQuantity(),
+		}, nil
+	})
+
+	bus.HandleQuery(b, func(ctx context.Context, msg contract.GetStockLevels) (map[string]*contract.StockLevel, error) {
+		outputs, err := getInventories.Execute(ctx, msg.ProductIDs)
+		if err != nil {
+			return nil, err
+		}
+		levels := make(map[string]*contract.StockLevel, len(outputs))
+		for _, output := range outputs {
+			levels[output.ProductID] = &contract.StockLevel{
+				ProductID:         output.ProductID,
+				Quantity:          output.Quantity,
+				AvailableQuantity: output.AvailableQuantity,
+			}
+		}
+		return levels, nil
+	})
+}
@@ -28,17 +28,21 @@ func NewGetInventoryQuery(
 
 // GetInventoryOutput represents the output for getting inventory
 type GetInventoryOutput struct {
-	ID                string    `json:"id"`
-	ProductID         string    `json:"product_id"`
-	ProductName       string    `json:"product_name"`
-	ProductPrice      float64   `json:"product_price"`
-	ProductCurrency   string    `json:"product_currency"`
-	Quantity          int       `json:"quantity"`
-	ReservedQuantity  int       `json:"reserved_quantity"`
-	AvailableQuantity int       `json:"available_quantity"`
-	Location          string    `json:"location"`
-	CreatedAt         time.Time `json:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at"`
+	ID                  string            `json:"id"`
+	ProductID           string            `json:"product_id"`
+	ProductName         string            `json:"product_name"`
+	ProductPrice        float64           `json:"product_price"`
+	ProductCurrency     string            `json:"product_currency"`
+	Quantity            int               `json:"quantity"`
+	ReservedQuantity    int               `json:"reserved_quantity"`
+	AvailableQuantity   int               `json:"available_quantity"`
+	BackorderedQuantity int               `json:"backordered_quantity"`
+	StockPolicy         string            `json:"stock_policy"`
+	BackorderLimit      int               `json:"backorder_limit"`
+	Location            string            `json:"location"`
+	Metadata            map[string]string `json:"metadata,omitempty"`
+	CreatedAt           time.Time         `json:"created_at"`
+	UpdatedAt           time.Time         `json:"updated_at"`
 }
 
 // Execute performs the get inventory operation
@@ -65,17 +69,21 @@ func (q *GetInventoryQuery) Execute(ctx context.Context, productID string) (*Get
 		// If product is deleted but inventory still exists, return partial data
 		if apperrors.Is(err, apperrors.CodeProductNotFound) {
 			return &GetInventoryOutput{
-				ID:                inv.ID(),
-				ProductID:         inv.ProductID(),
-				ProductName:       "Unknown (Product Deleted)",
-				ProductPrice:      0,
-				ProductCurrency:   "",
-				Quantity:          inv.Quantity(),
-				ReservedQuantity:  inv.ReservedQuantity(),
-				AvailableQuantity: inv.AvailableQuantity(),
-				Location:          inv.Location(),
-				CreatedAt:         inv.CreatedAt(),
-				UpdatedAt:         inv.UpdatedAt(),
+				ID:                  inv.ID(),
+				ProductID:           inv.ProductID(),
+				ProductName:         "Unknown (Product Deleted)",
+				ProductPrice:        0,
+				ProductCurrency:     "",
+				Quantity:            inv.Quantity(),
+				ReservedQuantity:    inv.ReservedQuantity(),
+				AvailableQuantity:   inv.AvailableQuantity(),
+				BackorderedQuantity: inv.BackorderedQuantity(),
+				StockPolicy:         string(inv.StockPolicy().Type()),
+				BackorderLimit:      inv.StockPolicy().BackorderLimit(),
+				Location:            inv.Location(),
+				Metadata:            inv.Metadata(),
+				CreatedAt:           inv.CreatedAt(),
+				UpdatedAt:           inv.UpdatedAt(),
 			}, nil
 		}
 		return nil, err
@@ -83,17 +91,20 @@ func (q *GetInventoryQuery) Execute(ctx context.Context, productID string) (*Get
 
 	// Return output DTO enriched with product information
 	return &GetInventoryOutput{
-		ID:                inv.ID(),
-		ProductID:         inv.ProductID(),
-		ProductName:       productOutput.Name,
-		ProductPrice:      productOutput.PriceAmount,
-		ProductCurrency:   productOutput.PriceCurrency,
-		Quantity:          inv.Quantity(),
-		ReservedQuantity:  inv.ReservedQuantity(),
-		AvailableQuantity: inv.AvailableQuantity(),
-		Location:          inv.Location(),
-		CreatedAt:         inv.CreatedAt(),
-		UpdatedAt:         inv.UpdatedAt(),
+		ID:                  inv.ID(),
+		ProductID:           inv.ProductID(),
+		ProductName:         productOutput.Name,
+		ProductPrice:        productOutput.PriceAmount,
+		ProductCurrency:     productOutput.PriceCurrency,
+		Quantity:            inv.Quantity(),
+		ReservedQuantity:    inv.ReservedQuantity(),
+		AvailableQuantity:   inv.AvailableQuantity(),
+		BackorderedQuantity: inv.BackorderedQuantity(),
+		StockPolicy:         string(inv.StockPolicy().Type()),
+		BackorderLimit:      inv.StockPolicy().BackorderLimit(),
+		Location:            inv.Location(),
+		Metadata:            inv.Metadata(),
+		CreatedAt:           inv.CreatedAt(),
+		UpdatedAt:           inv.UpdatedAt(),
 	}, nil
 }
-
@@ -0,0 +1,49 @@
+package query
+
+import (
+	"context"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// GetInventoriesByProductIDsQuery handles the business logic for retrieving the inventory of several products at once
+// It is meant for list enrichment, so product data is not included
+type GetInventoriesByProductIDsQuery struct {
+	inventoryRepo inventory.InventoryQueryRepository
+}
+
+// NewGetInventoriesByProductIDsQuery creates a new instance of GetInventoriesByProductIDsQuery
+func NewGetInventoriesByProductIDsQuery(inventoryRepo inventory.InventoryQueryRepository) *GetInventoriesByProductIDsQuery {
+	return &GetInventoriesByProductIDsQuery{
+		inventoryRepo: inventoryRepo,
+	}
+}
+
+// Execute retrieves the inventory records in a single repository call
+// Products without inventory are omitted from the result
+func (q *GetInventoriesByProductIDsQuery) Execute(ctx context.Context, productIDs []string) ([]*GetInventoryOutput, error) {
+	inventories, err := q.inventoryRepo.GetByProductIDs(ctx, productIDs)
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	outputs := make([]*GetInventoryOutput, 0, len(inventories))
+	for _, inv := range inventories {
+		outputs = append(outputs, &GetInventoryOutput{
+			ID:                  inv.ID(),
+			ProductID:           inv.ProductID(),
+			Quantity:            inv.Quantity(),
+			ReservedQuantity:    inv.ReservedQuantity(),
+			AvailableQuantity:   inv.AvailableQuantity(),
+			BackorderedQuantity: inv.BackorderedQuantity(),
+			StockPolicy:         string(inv.StockPolicy().Type()),
+			BackorderLimit:      inv.StockPolicy().BackorderLimit(),
+			Location:            inv.Location(),
+			Metadata:            inv.Metadata(),
+			CreatedAt:           inv.CreatedAt(),
+			UpdatedAt:           inv.UpdatedAt(),
+		})
+	}
+	return outputs, nil
+}
@@ -0,0 +1,108 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// StocktakeLineOutput represents a single counted line of a stocktake
+type StocktakeLineOutput struct {
+	ProductID        string     `json:"product_id"`
+	Location         string     `json:"location"`
+	ExpectedQuantity int        `json:"expected_quantity"`
+	CountedQuantity  *int       `json:"counted_quantity"`
+	Variance         int        `json:"variance"`
+	CountAttempts    int        `json:"count_attempts"`
+	Approved         bool       `json:"approved"`
+	CountedAt        *time.Time `json:"counted_at,omitempty"`
+}
+
+// StocktakeOutput represents a stocktake session with its variances
+type StocktakeOutput struct {
+	ID                string                `json:"id"`
+	Status            string                `json:"status"`
+	Lines             []StocktakeLineOutput `json:"lines"`
+	TotalLines        int                   `json:"total_lines"`
+	CountedLines      int                   `json:"counted_lines"`
+	LinesWithVariance int                   `json:"lines_with_variance"`
+	NetVariance       int                   `json:"net_variance"`
+	CreatedAt         time.Time             `json:"created_at"`
+	UpdatedAt         time.Time             `json:"updated_at"`
+	AppliedAt         *time.Time            `json:"applied_at,omitempty"`
+}
+
+// NewStocktakeOutput maps a stocktake entity to its output DTO
+func NewStocktakeOutput(st *inventory.Stocktake) *StocktakeOutput {
+	output := &StocktakeOutput{
+		ID:        st.ID(),
+		Status:    string(st.Status()),
+		Lines:     make([]StocktakeLineOutput, 0, len(st.Lines())),
+		CreatedAt: st.CreatedAt(),
+		UpdatedAt: st.UpdatedAt(),
+	}
+	if appliedAt := st.AppliedAt(); !appliedAt.IsZero() {
+		output.AppliedAt = &appliedAt
+	}
+
+	for _, line := range st.Lines() {
+		lineOutput := StocktakeLineOutput{
+			ProductID:        line.ProductID(),
+			Location:         line.Location(),
+			ExpectedQuantity: line.ExpectedQuantity(),
+			Variance:         line.Variance(),
+			CountAttempts:    line.CountAttempts(),
+			Approved:         line.IsApproved(),
+		}
+		if line.IsCounted() {
+			counted := line.CountedQuantity()
+			countedAt := line.CountedAt()
+			lineOutput.CountedQuantity = &counted
+			lineOutput.CountedAt = &countedAt
+			output.CountedLines++
+		}
+		if line.Variance() != 0 {
+			output.LinesWithVariance++
+			output.NetVariance += line.Variance()
+		}
+		output.Lines = append(output.Lines, lineOutput)
+	}
+	output.TotalLines = len(output.Lines)
+
+	return output
+}
+
+// GetStocktakeQuery handles the business logic for retrieving a stocktake session
+type GetStocktakeQuery struct {
+	stocktakeRepo inventory.StocktakeQueryRepository
+}
+
+// NewGetStocktakeQuery creates a new instance of GetStocktakeQuery
+func NewGetStocktakeQuery(stocktakeRepo inventory.StocktakeQueryRepository) *GetStocktakeQuery {
+	return &GetStocktakeQuery{
+		stocktakeRepo: stocktakeRepo,
+	}
+}
+
+// Execute performs the get stocktake operation
+func (q *GetStocktakeQuery) Execute(ctx context.Context, stocktakeID string) (*StocktakeOutput, error) {
+	// Validate input
+	if stocktakeID == "" {
+		return nil, apperrors.New(apperrors.CodeInvalidInput, "stocktake ID is required")
+	}
+
+	// Retrieve stocktake from repository
+	st, err := q.stocktakeRepo.GetByID(ctx, stocktakeID)
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	// Check if stocktake exists
+	if st == nil {
+		return nil, inventory.ErrStocktakeNotFound
+	}
+
+	return NewStocktakeOutput(st), nil
+}
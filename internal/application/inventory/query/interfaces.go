@@ -3,30 +3,53 @@ package query
 import (
 	"context"
 
-	productquery "github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/product/query"
+	productcontract "github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/product/contract"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/bus"
 )
 
 // ProductQueryInterface defines the interface for product query operations
 // This allows Inventory module to communicate with Product module
 type ProductQueryInterface interface {
-	Execute(ctx context.Context, productID string) (*productquery.GetProductOutput, error)
+	Execute(ctx context.Context, productID string) (*productcontract.ProductSummary, error)
 }
 
-// ProductQueryAdapter adapts GetProductQuery to implement ProductQueryInterface
-// This enables Inventory module to call Product module
+// ProductQueryAdapter implements ProductQueryInterface by asking the Product module over the module bus
+// This enables Inventory module to call Product module without knowing how it is built
 type ProductQueryAdapter struct {
-	productQuery *productquery.GetProductQuery
+	bus *bus.Bus
 }
 
 // NewProductQueryAdapter creates a new adapter
-func NewProductQueryAdapter(productQuery *productquery.GetProductQuery) *ProductQueryAdapter {
+func NewProductQueryAdapter(b *bus.Bus) *ProductQueryAdapter {
 	return &ProductQueryAdapter{
-		productQuery: productQuery,
+		bus: b,
 	}
 }
 
-// Execute calls the product query and returns the result
-func (a *ProductQueryAdapter) Execute(ctx context.Context, productID string) (*productquery.GetProductOutput, error) {
-	return a.productQuery.Execute(ctx, productID)
+// Execute asks the Product module for the product's summary
+func (a *ProductQueryAdapter) Execute(ctx context.Context, productID string) (*productcontract.ProductSummary, error) {
+	return bus.Ask[*productcontract.ProductSummary](ctx, a.bus, productcontract.GetProductSummary{ProductID: productID})
 }
 
+// ProductBatchQueryInterface defines the interface for retrieving several products at once
+// This lets list queries enrich many rows with a single call to the Product module
+type ProductBatchQueryInterface interface {
+	Execute(ctx context.Context, productIDs []string) ([]*productcontract.ProductSummary, error)
+}
+
+// ProductBatchQueryAdapter implements ProductBatchQueryInterface over the module bus
+type ProductBatchQueryAdapter struct {
+	bus *bus.Bus
+}
+
+// NewProductBatchQueryAdapter creates a new adapter
+func NewProductBatchQueryAdapter(b *bus.Bus) *ProductBatchQueryAdapter {
+	return &ProductBatchQueryAdapter{
+		bus: b,
+	}
+}
+
+// Execute asks the Product module for the summaries of the products
+func (a *ProductBatchQueryAdapter) Execute(ctx context.Context, productIDs []string) ([]*productcontract.ProductSummary, error) {
+	return bus.Ask[[]*productcontract.ProductSummary](ctx, a.bus, productcontract.GetProductSummaries{ProductIDs: productIDs})
+}
@@ -0,0 +1,146 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	productcontract "github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/product/contract"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+const (
+	// DefaultInventoryPageSize is used when no page size is requested
+	DefaultInventoryPageSize = 20
+	// MaxInventoryPageSize bounds how many records a single page may contain
+	MaxInventoryPageSize = 100
+)
+
+// ListInventoryInput represents the filters, sorting and paging for listing inventory
+type ListInventoryInput struct {
+	Location       string    `form:"location"`
+	ZeroStock      bool      `form:"zero_stock"`
+	AvailableBelow *int      `form:"available_below" validate:"omitempty,min=0"`
+	UpdatedSince   time.Time `form:"updated_since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Sort           string    `form:"sort" validate:"omitempty,oneof=product_id quantity updated_at"`
+	Order          string    `form:"order" validate:"omitempty,oneof=asc desc"`
+	Page           int       `form:"page" validate:"min=0"`
+	PageSize       int       `form:"page_size" validate:"min=0,max=100"`
+	IncludeProduct bool      `form:"include_product"`
+}
+
+// ListInventoryOutput represents a page of inventory records
+type ListInventoryOutput struct {
+	Items    []*GetInventoryOutput `json:"items"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
+	Total    int                   `json:"total"`
+}
+
+// ListInventoryQuery handles the business logic for listing inventory
+type ListInventoryQuery struct {
+	inventoryRepo inventory.InventoryQueryRepository
+	productsQuery ProductBatchQueryInterface
+}
+
+// NewListInventoryQuery creates a new instance of ListInventoryQuery
+// Product details are fetched for the whole page in one call to the Product module
+func NewListInventoryQuery(
+	inventoryRepo inventory.InventoryQueryRepository,
+	productsQuery ProductBatchQueryInterface,
+) *ListInventoryQuery {
+	return &ListInventoryQuery{
+		inventoryRepo: inventoryRepo,
+		productsQuery: productsQuery,
+	}
+}
+
+// Execute performs the list inventory operation
+func (q *ListInventoryQuery) Execute(ctx context.Context, input ListInventoryInput) (*ListInventoryOutput, error) {
+	// Apply paging defaults
+	page := input.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := input.PageSize
+	if pageSize < 1 {
+		pageSize = DefaultInventoryPageSize
+	}
+	if pageSize > MaxInventoryPageSize {
+		return nil, apperrors.Newf(apperrors.CodeInvalidInput, "page size cannot exceed %d", MaxInventoryPageSize)
+	}
+	if input.AvailableBelow != nil && *input.AvailableBelow < 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidInput, "available_below cannot be negative")
+	}
+
+	filter := inventory.InventoryFilter{
+		Location:       input.Location,
+		ZeroStockOnly:  input.ZeroStock,
+		AvailableBelow: input.AvailableBelow,
+		UpdatedSince:   input.UpdatedSince,
+		SortBy:         inventory.InventorySortField(input.Sort),
+		SortDesc:       input.Order == "desc",
+		Limit:          pageSize,
+		Offset:         (page - 1) * pageSize,
+	}
+
+	inventories, err := q.inventoryRepo.List(ctx, filter)
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	total, err := q.inventoryRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	// MODULE COMMUNICATION: Enrich the whole page with one Product module call
+	products := make(map[string]*productcontract.ProductSummary)
+	if input.IncludeProduct && len(inventories) > 0 {
+		productIDs := make([]string, 0, len(inventories))
+		for _, inv := range inventories {
+			productIDs = append(productIDs, inv.ProductID())
+		}
+		productOutputs, err := q.productsQuery.Execute(ctx, productIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, productOutput := range productOutputs {
+			products[productOutput.ID] = productOutput
+		}
+	}
+
+	items := make([]*GetInventoryOutput, 0, len(inventories))
+	for _, inv := range inventories {
+		item := &GetInventoryOutput{
+			ID:                  inv.ID(),
+			ProductID:           inv.ProductID(),
+			Quantity:            inv.Quantity(),
+			ReservedQuantity:    inv.ReservedQuantity(),
+			AvailableQuantity:   inv.AvailableQuantity(),
+			BackorderedQuantity: inv.BackorderedQuantity(),
+			StockPolicy:         string(inv.StockPolicy().Type()),
+			BackorderLimit:      inv.StockPolicy().BackorderLimit(),
+			Location:            inv.Location(),
+			Metadata:            inv.Metadata(),
+			CreatedAt:           inv.CreatedAt(),
+			UpdatedAt:           inv.UpdatedAt(),
+		}
+		if input.IncludeProduct {
+			if productOutput, ok := products[inv.ProductID()]; ok {
+				item.ProductName = productOutput.Name
+				item.ProductPrice = productOutput.PriceAmount
+				item.ProductCurrency = productOutput.PriceCurrency
+			} else {
+				item.ProductName = "Unknown (Product Deleted)"
+			}
+		}
+		items = append(items, item)
+	}
+
+	return &ListInventoryOutput{
+		Items:    items,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}, nil
+}
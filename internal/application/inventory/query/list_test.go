@@ -0,0 +1,114 @@
+package query_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/inventory/query"
+	productcontract "github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/product/contract"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeInventoryQueryRepository returns a fixed page and records the filter it received
+type fakeInventoryQueryRepository struct {
+	inventory.InventoryQueryRepository
+	items  []*inventory.Inventory
+	total  int
+	filter inventory.InventoryFilter
+}
+
+func (r *fakeInventoryQueryRepository) List(ctx context.Context, filter inventory.InventoryFilter) ([]*inventory.Inventory, error) {
+	r.filter = filter
+	return r.items, nil
+}
+
+func (r *fakeInventoryQueryRepository) Count(ctx context.Context, filter inventory.InventoryFilter) (int, error) {
+	return r.total, nil
+}
+
+// fakeProductBatchQuery counts how often the Product module is called
+type fakeProductBatchQuery struct {
+	calls    int
+	products []*productcontract.ProductSummary
+}
+
+func (q *fakeProductBatchQuery) Execute(ctx context.Context, productIDs []string) ([]*productcontract.ProductSummary, error) {
+	q.calls++
+	return q.products, nil
+}
+
+func newListFixtures() (*fakeInventoryQueryRepository, *fakeProductBatchQuery) {
+	now := time.Now()
+	repo := &fakeInventoryQueryRepository{
+		items: []*inventory.Inventory{
+			inventory.ReconstructInventory("inv-1", "product-1", 0, 0, 0, inventory.StrictStockPolicy(), "Warehouse A", nil, now, now, 1),
+			inventory.ReconstructInventory("inv-2", "product-2", 5, 1, 0, inventory.StrictStockPolicy(), "Warehouse A", nil, now, now, 1),
+		},
+		total: 12,
+	}
+	products := &fakeProductBatchQuery{
+		products: []*productcontract.ProductSummary{
+			{ID: "product-1", Name: "Laptop", PriceAmount: 999.99, PriceCurrency: "USD"},
+		},
+	}
+	return repo, products
+}
+
+func TestListInventoryQuery_Execute(t *testing.T) {
+	repo, products := newListFixtures()
+	below := 3
+	q := query.NewListInventoryQuery(repo, products)
+
+	output, err := q.Execute(context.Background(), query.ListInventoryInput{
+		Location:       "Warehouse A",
+		AvailableBelow: &below,
+		Sort:           "quantity",
+		Order:          "desc",
+		Page:           2,
+		PageSize:       5,
+		IncludeProduct: true,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, inventory.InventoryFilter{
+		Location:       "Warehouse A",
+		AvailableBelow: &below,
+		SortBy:         inventory.SortByQuantity,
+		SortDesc:       true,
+		Limit:          5,
+		Offset:         5,
+	}, repo.filter)
+	assert.Equal(t, 12, output.Total)
+	assert.Equal(t, 2, output.Page)
+	assert.Len(t, output.Items, 2)
+
+	// Product names for the whole page come from a single batched call
+	assert.Equal(t, 1, products.calls)
+	assert.Equal(t, "Laptop", output.Items[0].ProductName)
+	assert.Equal(t, "Unknown (Product Deleted)", output.Items[1].ProductName)
+}
+
+func TestListInventoryQuery_Execute_WithoutEnrichment(t *testing.T) {
+	repo, products := newListFixtures()
+	q := query.NewListInventoryQuery(repo, products)
+
+	output, err := q.Execute(context.Background(), query.ListInventoryInput{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, products.calls)
+	assert.Equal(t, 1, output.Page)
+	assert.Equal(t, query.DefaultInventoryPageSize, output.PageSize)
+	assert.Empty(t, output.Items[0].ProductName)
+}
+
+func TestListInventoryQuery_Execute_PageSizeBound(t *testing.T) {
+	repo, products := newListFixtures()
+	q := query.NewListInventoryQuery(repo, products)
+
+	_, err := q.Execute(context.Background(), query.ListInventoryInput{PageSize: query.MaxInventoryPageSize + 1})
+	assert.True(t, apperrors.Is(err, apperrors.CodeInvalidInput))
+}
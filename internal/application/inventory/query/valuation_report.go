@@ -0,0 +1,184 @@
+package query
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// CostLayerOutput represents an open FIFO cost layer
+type CostLayerOutput struct {
+	ReceivedQuantity  int       `json:"received_quantity"`
+	RemainingQuantity int       `json:"remaining_quantity"`
+	UnitCost          float64   `json:"unit_cost"`
+	ReceivedAt        time.Time `json:"received_at"`
+}
+
+// ProductValuationOutput represents the valuation of a single product
+type ProductValuationOutput struct {
+	ProductID           string            `json:"product_id"`
+	ProductName         string            `json:"product_name"`
+	CostingMethod       string            `json:"costing_method"`
+	Currency            string            `json:"currency"`
+	Quantity            int               `json:"quantity"`
+	UnitCost            float64           `json:"unit_cost"`
+	TotalValue          float64           `json:"total_value"`
+	CostOfGoodsConsumed float64           `json:"cost_of_goods_consumed"`
+	CostLayers          []CostLayerOutput `json:"cost_layers,omitempty"`
+	UpdatedAt           time.Time         `json:"updated_at"`
+}
+
+// ValuationTotalOutput represents the totals for one currency
+type ValuationTotalOutput struct {
+	Currency            string  `json:"currency"`
+	TotalValue          float64 `json:"total_value"`
+	CostOfGoodsConsumed float64 `json:"cost_of_goods_consumed"`
+}
+
+// ValuationReportOutput represents the inventory valuation report
+// Totals are kept per currency because prices in different currencies cannot be added
+type ValuationReportOutput struct {
+	Products    []ProductValuationOutput `json:"products"`
+	Totals      []ValuationTotalOutput   `json:"totals"`
+	GeneratedAt time.Time                `json:"generated_at"`
+}
+
+// GetValuationReportQuery handles the business logic for reporting inventory value
+type GetValuationReportQuery struct {
+	valuationQueryRepo inventory.ValuationQueryRepository
+	productsQuery      ProductBatchQueryInterface
+}
+
+// NewGetValuationReportQuery creates a new instance of GetValuationReportQuery
+// Product names for the whole report are fetched in one call to the Product module
+func NewGetValuationReportQuery(
+	valuationQueryRepo inventory.ValuationQueryRepository,
+	productsQuery ProductBatchQueryInterface,
+) *GetValuationReportQuery {
+	return &GetValuationReportQuery{
+		valuationQueryRepo: valuationQueryRepo,
+		productsQuery:      productsQuery,
+	}
+}
+
+// Execute builds the valuation report, optionally restricted to a single product
+func (q *GetValuationReportQuery) Execute(ctx context.Context, productID string) (*ValuationReportOutput, error) {
+	var valuations []*inventory.StockValuation
+	if productID != "" {
+		valuation, err := q.valuationQueryRepo.GetByProductID(ctx, productID)
+		if err != nil {
+			return nil, apperrors.WrapDatabaseError(err)
+		}
+		if valuation == nil {
+			return nil, apperrors.New(apperrors.CodeInventoryNotFound, "no valuation found for product")
+		}
+		valuations = append(valuations, valuation)
+	} else {
+		var err error
+		valuations, err = q.valuationQueryRepo.List(ctx)
+		if err != nil {
+			return nil, apperrors.WrapDatabaseError(err)
+		}
+	}
+
+	output := &ValuationReportOutput{
+		Products:    make([]ProductValuationOutput, 0, len(valuations)),
+		Totals:      []ValuationTotalOutput{},
+		GeneratedAt: time.Now(),
+	}
+	// MODULE COMMUNICATION: Get product names for every valuation with one Product module call
+	productNames, err := q.productNames(ctx, valuations)
+	if err != nil {
+		return nil, err
+	}
+
+	totalValues := make(map[string]product.Price)
+	totalConsumed := make(map[string]product.Price)
+
+	for _, v := range valuations {
+		layers := make([]CostLayerOutput, 0, len(v.Layers()))
+		for _, layer := range v.Layers() {
+			layers = append(layers, CostLayerOutput{
+				ReceivedQuantity:  layer.ReceivedQuantity(),
+				RemainingQuantity: layer.RemainingQuantity(),
+				UnitCost:          layer.UnitCost(),
+				ReceivedAt:        layer.ReceivedAt(),
+			})
+		}
+
+		output.Products = append(output.Products, ProductValuationOutput{
+			ProductID:           v.ProductID(),
+			ProductName:         productNames[v.ProductID()],
+			CostingMethod:       string(v.Method()),
+			Currency:            v.Currency(),
+			Quantity:            v.Quantity(),
+			UnitCost:            v.AverageUnitCost(),
+			TotalValue:          v.TotalCost(),
+			CostOfGoodsConsumed: v.ConsumedCost(),
+			CostLayers:          layers,
+			UpdatedAt:           v.UpdatedAt(),
+		})
+
+		if err := addToTotal(totalValues, v.TotalCost(), v.Currency()); err != nil {
+			return nil, err
+		}
+		if err := addToTotal(totalConsumed, v.ConsumedCost(), v.Currency()); err != nil {
+			return nil, err
+		}
+	}
+
+	for currency, value := range totalValues {
+		output.Totals = append(output.Totals, ValuationTotalOutput{
+			Currency:            currency,
+			TotalValue:          value.Amount(),
+			CostOfGoodsConsumed: totalConsumed[currency].Amount(),
+		})
+	}
+	sort.Slice(output.Totals, func(i, j int) bool {
+		return output.Totals[i].Currency < output.Totals[j].Currency
+	})
+
+	return output, nil
+}
+
+// productNames returns the names of the valued products keyed by product ID
+// Products unknown to the Product module are left out
+func (q *GetValuationReportQuery) productNames(ctx context.Context, valuations []*inventory.StockValuation) (map[string]string, error) {
+	names := make(map[string]string, len(valuations))
+	if len(valuations) == 0 {
+		return names, nil
+	}
+
+	productIDs := make([]string, 0, len(valuations))
+	for _, v := range valuations {
+		productIDs = append(productIDs, v.ProductID())
+	}
+	productOutputs, err := q.productsQuery.Execute(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, productOutput := range productOutputs {
+		names[productOutput.ID] = productOutput.Name
+	}
+	return names, nil
+}
+
+// addToTotal adds an amount to the running total of its currency using Price semantics
+func addToTotal(totals map[string]product.Price, amount float64, currency string) error {
+	price, err := product.NewPrice(amount, currency)
+	if err != nil {
+		return err
+	}
+	if total, ok := totals[currency]; ok {
+		price, err = total.Add(price)
+		if err != nil {
+			return err
+		}
+	}
+	totals[currency] = price
+	return nil
+}
@@ -0,0 +1,42 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/outbox"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// ReplayDeadLetterOutput represents a dead message queued for publishing again
+type ReplayDeadLetterOutput struct {
+	ID         int64     `json:"id"`
+	ReplayedAt time.Time `json:"replayed_at"`
+}
+
+// ReplayDeadLetterCommand handles the business logic for replaying a dead outbox message
+// The message is published again with a fresh set of attempts, followed by the messages
+// of its aggregate it held back.
+type ReplayDeadLetterCommand struct {
+	store outbox.Store
+}
+
+// NewReplayDeadLetterCommand creates a new instance of ReplayDeadLetterCommand
+func NewReplayDeadLetterCommand(store outbox.Store) *ReplayDeadLetterCommand {
+	return &ReplayDeadLetterCommand{
+		store: store,
+	}
+}
+
+// Execute performs the replay operation for a dead message of the tenant of the context
+func (c *ReplayDeadLetterCommand) Execute(ctx context.Context, id int64) (*ReplayDeadLetterOutput, error) {
+	now := time.Now().UTC()
+	replayed, err := c.store.Replay(ctx, id, now)
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	if !replayed {
+		return nil, apperrors.Newf(apperrors.CodeOutboxMessageNotFound, "no dead outbox message with ID %d", id)
+	}
+	return &ReplayDeadLetterOutput{ID: id, ReplayedAt: now}, nil
+}
@@ -0,0 +1,109 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/outbox"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+const (
+	// DefaultDeadLetterPageSize is used when no page size is requested
+	DefaultDeadLetterPageSize = 20
+	// MaxDeadLetterPageSize bounds how many messages a single page may contain
+	MaxDeadLetterPageSize = 100
+)
+
+// ListDeadLettersInput represents the paging for listing dead outbox messages
+type ListDeadLettersInput struct {
+	Page     int `form:"page" validate:"min=0"`
+	PageSize int `form:"page_size" validate:"min=0,max=100"`
+}
+
+// DeadLetterOutput represents an event the relay gave up publishing
+type DeadLetterOutput struct {
+	ID            int64           `json:"id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	EventName     string          `json:"event_name"`
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Attempts      int             `json:"attempts"`
+	LastError     string          `json:"last_error"`
+	StoredAt      time.Time       `json:"stored_at"`
+}
+
+// ListDeadLettersOutput represents a page of dead outbox messages, oldest first
+type ListDeadLettersOutput struct {
+	Items    []*DeadLetterOutput `json:"items"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+	Total    int                 `json:"total"`
+}
+
+// ListDeadLettersQuery handles the business logic for listing dead outbox messages
+type ListDeadLettersQuery struct {
+	store outbox.Store
+}
+
+// NewListDeadLettersQuery creates a new instance of ListDeadLettersQuery
+func NewListDeadLettersQuery(store outbox.Store) *ListDeadLettersQuery {
+	return &ListDeadLettersQuery{
+		store: store,
+	}
+}
+
+// Execute performs the list dead letters operation for the tenant of the context
+func (q *ListDeadLettersQuery) Execute(ctx context.Context, input ListDeadLettersInput) (*ListDeadLettersOutput, error) {
+	// Apply paging defaults
+	page := input.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := input.PageSize
+	if pageSize < 1 {
+		pageSize = DefaultDeadLetterPageSize
+	}
+	if pageSize > MaxDeadLetterPageSize {
+		return nil, apperrors.Newf(apperrors.CodeInvalidInput, "page size cannot exceed %d", MaxDeadLetterPageSize)
+	}
+
+	filter := outbox.Filter{
+		Status: outbox.StatusDead,
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	}
+
+	messages, err := q.store.List(ctx, filter)
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	total, err := q.store.Count(ctx, filter)
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	items := make([]*DeadLetterOutput, 0, len(messages))
+	for _, message := range messages {
+		items = append(items, &DeadLetterOutput{
+			ID:            message.ID,
+			AggregateType: message.AggregateType,
+			AggregateID:   message.AggregateID,
+			EventName:     message.EventName,
+			Payload:       message.Payload,
+			OccurredAt:    message.OccurredAt,
+			Attempts:      message.Attempts,
+			LastError:     message.LastError,
+			StoredAt:      message.CreatedAt,
+		})
+	}
+
+	return &ListDeadLettersOutput{
+		Items:    items,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}, nil
+}
@@ -0,0 +1,30 @@
+package command
+
+import (
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+)
+
+// AuditProduct is the aggregate type of products in the audit trail
+const AuditProduct = "product"
+
+// productState is the state of a product as the audit trail records it
+type productState struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	PriceAmount   float64   `json:"price_amount"`
+	PriceCurrency string    `json:"price_currency"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// productStateOf captures the current state of a product
+func productStateOf(p *product.Product) *productState {
+	return &productState{
+		ID:            p.ID(),
+		Name:          p.Name(),
+		PriceAmount:   p.Price().Amount(),
+		PriceCurrency: p.Price().Currency(),
+		UpdatedAt:     p.UpdatedAt(),
+	}
+}
@@ -5,15 +5,19 @@ import (
 	"time"
 
 	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/eventbus"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
 	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
 	"github.com/google/uuid"
 )
 
 // CreateProductInput represents the input data for creating a product
+// A missing PriceCurrency defaults to the default currency of the tenant
 type CreateProductInput struct {
 	Name          string  `json:"name" validate:"required,min=1,max=255"`
 	PriceAmount   float64 `json:"price_amount" validate:"required,gte=0"`
-	PriceCurrency string  `json:"price_currency" validate:"required,len=3"`
+	PriceCurrency string  `json:"price_currency" validate:"omitempty,len=3"`
 }
 
 // CreateProductOutput represents the output data after creating a product
@@ -27,13 +31,17 @@ type CreateProductOutput struct {
 
 // CreateProductCommand handles the business logic for creating a product
 type CreateProductCommand struct {
-	productRepo product.ProductCommandRepository
+	uow        product.UnitOfWork
+	recorder   *audit.Recorder
+	dispatcher *eventbus.Dispatcher
 }
 
 // NewCreateProductCommand creates a new instance of CreateProductCommand
-func NewCreateProductCommand(productRepo product.ProductCommandRepository) *CreateProductCommand {
+func NewCreateProductCommand(uow product.UnitOfWork, recorder *audit.Recorder, dispatcher *eventbus.Dispatcher) *CreateProductCommand {
 	return &CreateProductCommand{
-		productRepo: productRepo,
+		uow:        uow,
+		recorder:   recorder,
+		dispatcher: dispatcher,
 	}
 }
 
@@ -44,8 +52,13 @@ func (c *CreateProductCommand) Execute(ctx context.Context, input CreateProductI
 		return nil, apperrors.New(apperrors.CodeInvalidProductName, "product name is required")
 	}
 
+	currency := input.PriceCurrency
+	if currency == "" {
+		currency = tenant.SettingsFromContext(ctx).DefaultCurrency
+	}
+
 	// Create price value object with validation
-	price, err := product.NewPrice(input.PriceAmount, input.PriceCurrency)
+	price, err := product.NewPrice(input.PriceAmount, currency)
 	if err != nil {
 		return nil, err
 	}
@@ -59,10 +72,24 @@ func (c *CreateProductCommand) Execute(ctx context.Context, input CreateProductI
 		return nil, err
 	}
 
-	// Persist the product
-	if err := c.productRepo.Create(ctx, prod); err != nil {
-		return nil, apperrors.WrapDatabaseError(err)
+	// Persist the product along with its audit entry
+	err = c.uow.Do(ctx, func(ctx context.Context, repos product.TxRepositories) error {
+		if err := repos.ProductCommands.Create(ctx, prod); err != nil {
+			return apperrors.WrapDatabaseError(err)
+		}
+
+		return c.recorder.InTx(repos.Audit).Record(ctx, audit.Change{
+			Command:       "CreateProduct",
+			Input:         input,
+			AggregateType: AuditProduct,
+			AggregateID:   prod.ID(),
+			After:         productStateOf(prod),
+		})
+	})
+	if err != nil {
+		return nil, err
 	}
+	c.dispatcher.Dispatch(ctx, prod.PullEvents()...)
 
 	// Return output DTO
 	return &CreateProductOutput{
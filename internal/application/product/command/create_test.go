@@ -0,0 +1,123 @@
+package command_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/product/command"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/event"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/eventbus"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProductCommandRepository keeps the last product it was asked to create
+type fakeProductCommandRepository struct {
+	product.ProductCommandRepository
+	created *product.Product
+}
+
+func (r *fakeProductCommandRepository) Create(ctx context.Context, prod *product.Product) error {
+	r.created = prod
+	return nil
+}
+
+// fakeUnitOfWork runs fn directly against its repositories
+type fakeUnitOfWork struct {
+	repos product.TxRepositories
+}
+
+func (u *fakeUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos product.TxRepositories) error) error {
+	return fn(ctx, u.repos)
+}
+
+// fakeAuditStore keeps the entries appended to it
+type fakeAuditStore struct {
+	audit.Store
+	entries []*audit.Entry
+	err     error
+}
+
+func (s *fakeAuditStore) Append(ctx context.Context, entry *audit.Entry) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func TestCreateProductCommand_Execute_TenantDefaultCurrency(t *testing.T) {
+	repo := &fakeProductCommandRepository{}
+	cmd := command.NewCreateProductCommand(&fakeUnitOfWork{repos: product.TxRepositories{ProductCommands: repo}}, nil, nil)
+	ctx := tenant.NewContext(context.Background(), tenant.Tenant{
+		ID:       "acme",
+		Settings: tenant.Settings{DefaultCurrency: "EUR"},
+	})
+
+	output, err := cmd.Execute(ctx, command.CreateProductInput{Name: "Widget", PriceAmount: 5})
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", output.PriceCurrency)
+	assert.Equal(t, "EUR", repo.created.Price().Currency())
+
+	// An explicit currency wins over the tenant's default
+	output, err = cmd.Execute(ctx, command.CreateProductInput{Name: "Widget", PriceAmount: 5, PriceCurrency: "USD"})
+	require.NoError(t, err)
+	assert.Equal(t, "USD", output.PriceCurrency)
+}
+
+func TestCreateProductCommand_Execute_NoCurrency(t *testing.T) {
+	cmd := command.NewCreateProductCommand(&fakeUnitOfWork{repos: product.TxRepositories{ProductCommands: &fakeProductCommandRepository{}}}, nil, nil)
+
+	_, err := cmd.Execute(context.Background(), command.CreateProductInput{Name: "Widget", PriceAmount: 5})
+	assert.True(t, apperrors.Is(err, apperrors.CodeInvalidPrice))
+}
+
+func TestCreateProductCommand_Execute_RecordsAudit(t *testing.T) {
+	store := &fakeAuditStore{}
+	uow := &fakeUnitOfWork{repos: product.TxRepositories{ProductCommands: &fakeProductCommandRepository{}, Audit: store}}
+	cmd := command.NewCreateProductCommand(uow, audit.NewRecorder(store), nil)
+	ctx := audit.NewContext(context.Background(), audit.Origin{Actor: "alice", RequestID: "req-1"})
+
+	output, err := cmd.Execute(ctx, command.CreateProductInput{Name: "Widget", PriceAmount: 5, PriceCurrency: "USD"})
+	require.NoError(t, err)
+
+	require.Len(t, store.entries, 1)
+	entry := store.entries[0]
+	assert.Equal(t, "CreateProduct", entry.Command)
+	assert.Equal(t, command.AuditProduct, entry.AggregateType)
+	assert.Equal(t, output.ID, entry.AggregateID)
+	assert.Equal(t, "alice", entry.Actor)
+	assert.Equal(t, "req-1", entry.RequestID)
+	assert.JSONEq(t, "null", string(entry.Before))
+	assert.Contains(t, string(entry.After), `"name":"Widget"`)
+}
+
+func TestCreateProductCommand_Execute_AuditFailureFailsCommand(t *testing.T) {
+	store := &fakeAuditStore{err: apperrors.New(apperrors.CodeConcurrencyConflict, "audit chain moved")}
+	uow := &fakeUnitOfWork{repos: product.TxRepositories{ProductCommands: &fakeProductCommandRepository{}, Audit: store}}
+	cmd := command.NewCreateProductCommand(uow, audit.NewRecorder(store), nil)
+
+	_, err := cmd.Execute(context.Background(), command.CreateProductInput{Name: "Widget", PriceAmount: 5, PriceCurrency: "USD"})
+	assert.True(t, apperrors.Is(err, apperrors.CodeConcurrencyConflict))
+}
+
+func TestCreateProductCommand_Execute_DispatchesEvents(t *testing.T) {
+	dispatcher := eventbus.NewDispatcher()
+	var dispatched []event.Event
+	dispatcher.Subscribe(eventbus.AllEvents, func(ctx context.Context, e event.Event) error {
+		dispatched = append(dispatched, e)
+		return nil
+	})
+	cmd := command.NewCreateProductCommand(&fakeUnitOfWork{repos: product.TxRepositories{ProductCommands: &fakeProductCommandRepository{}}}, nil, dispatcher)
+
+	output, err := cmd.Execute(context.Background(), command.CreateProductInput{Name: "Widget", PriceAmount: 5, PriceCurrency: "USD"})
+	require.NoError(t, err)
+
+	require.Len(t, dispatched, 1)
+	assert.Equal(t, product.EventProductCreated, dispatched[0].EventName())
+	assert.Equal(t, output.ID, dispatched[0].AggregateID())
+}
@@ -0,0 +1,35 @@
+package command
+
+import (
+	"context"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// RebuildCatalogOutput represents the output data after rebuilding the product catalog
+type RebuildCatalogOutput struct {
+	Entries int `json:"entries"`
+}
+
+// RebuildCatalogCommand regenerates the product catalog read model from the product and inventory records
+// Used after the read model was added, restored from a backup or suspected to have drifted
+type RebuildCatalogCommand struct {
+	projection product.CatalogProjection
+}
+
+// NewRebuildCatalogCommand creates a new instance of RebuildCatalogCommand
+func NewRebuildCatalogCommand(projection product.CatalogProjection) *RebuildCatalogCommand {
+	return &RebuildCatalogCommand{
+		projection: projection,
+	}
+}
+
+// Execute performs the rebuild
+func (c *RebuildCatalogCommand) Execute(ctx context.Context) (*RebuildCatalogOutput, error) {
+	entries, err := c.projection.Rebuild(ctx)
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	return &RebuildCatalogOutput{Entries: entries}, nil
+}
@@ -0,0 +1,30 @@
+package contract
+
+// Messages the Product module answers on the module bus
+// Other modules depend on these types only, never on the Product module's queries.
+
+// GetProductSummary asks for the summary of one product
+// It fails with CodeProductNotFound when the product does not exist.
+type GetProductSummary struct {
+	ProductID string
+}
+
+// MessageName returns the name of the message on the bus
+func (GetProductSummary) MessageName() string { return "product.get_product_summary" }
+
+// GetProductSummaries asks for the summaries of several products at once
+// Products that do not exist are left out of the result.
+type GetProductSummaries struct {
+	ProductIDs []string
+}
+
+// MessageName returns the name of the message on the bus
+func (GetProductSummaries) MessageName() string { return "product.get_product_summaries" }
+
+// ProductSummary is the product data other modules need
+type ProductSummary struct {
+	ID            string
+	Name          string
+	PriceAmount   float64
+	PriceCurrency string
+}
@@ -2,7 +2,7 @@ package product_test
 
 import (
 	"context"
-	"errors"
+	"database/sql/driver"
 	"testing"
 
 	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/product"
@@ -100,7 +100,7 @@ func (s *CreateProductUseCaseTestSuite) TestExecute() {
 			},
 			setupMock: func() {
 				s.mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*product.Product")).
-					Return(errors.New("database connection error")).
+					Return(driver.ErrBadConn).
 					Once()
 			},
 			expectedError: "connection", // Check for keyword instead of exact match (case-insensitive)
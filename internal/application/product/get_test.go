@@ -2,6 +2,7 @@ package product_test
 
 import (
 	"context"
+	"database/sql/driver"
 	"errors"
 	"testing"
 	"time"
@@ -43,6 +44,7 @@ func (s *GetProductUseCaseTestSuite) TestExecute() {
 					price,
 					time.Now(),
 					time.Now(),
+					1,
 				)
 				s.mockRepo.On("GetByID", mock.Anything, "test-product-id").
 					Return(expectedProduct, nil).
@@ -86,7 +88,7 @@ func (s *GetProductUseCaseTestSuite) TestExecute() {
 			productID: "test-product-id",
 			setupMock: func() {
 				s.mockRepo.On("GetByID", mock.Anything, "test-product-id").
-					Return(nil, errors.New("database connection error")).
+					Return(nil, driver.ErrBadConn).
 					Once()
 			},
 			expectedError: "connection", // Check for keyword instead of exact match (case-insensitive)
@@ -172,6 +174,7 @@ func (s *GetProductWithInventoryUseCaseTestSuite) TestExecute_WithInventory() {
 		price,
 		time.Now(),
 		time.Now(),
+		1,
 	)
 
 	// Setup inventory data
@@ -215,6 +218,7 @@ func (s *GetProductWithInventoryUseCaseTestSuite) TestExecute_InventoryNotFound(
 		price,
 		time.Now(),
 		time.Now(),
+		1,
 	)
 
 	s.mockRepo.On("GetByID", mock.Anything, "test-product-id").
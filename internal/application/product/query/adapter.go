@@ -2,6 +2,9 @@ package query
 
 import (
 	"context"
+
+	inventorycontract "github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/inventory/contract"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/bus"
 )
 
 // InventoryData represents minimal inventory data needed by Product module
@@ -11,7 +14,6 @@ type InventoryData interface {
 }
 
 // InventoryOutput represents inventory output data
-// This type is defined here to avoid circular imports
 type InventoryOutput struct {
 	Quantity          int
 	AvailableQuantity int
@@ -43,27 +45,60 @@ func (a *InventoryAdapter) GetAvailableQuantity() int {
 	return a.output.AvailableQuantity
 }
 
-// InventoryQueryFunc is a function type that executes an inventory query
-type InventoryQueryFunc func(ctx context.Context, productID string) (*InventoryOutput, error)
-
-// ProductInventoryAdapter adapts an inventory query function to implement InventoryQueryInterface
+// ProductInventoryAdapter implements InventoryQueryInterface by asking the Inventory module over the module bus
 // This enables Product module to call Inventory module without circular imports
 type ProductInventoryAdapter struct {
-	inventoryQuery InventoryQueryFunc
+	bus *bus.Bus
 }
 
-// NewProductInventoryAdapter creates a new adapter from a function
-func NewProductInventoryAdapter(inventoryQuery InventoryQueryFunc) *ProductInventoryAdapter {
+// NewProductInventoryAdapter creates a new adapter
+func NewProductInventoryAdapter(b *bus.Bus) *ProductInventoryAdapter {
 	return &ProductInventoryAdapter{
-		inventoryQuery: inventoryQuery,
+		bus: b,
 	}
 }
 
-// Execute calls the inventory query function and adapts the result
+// Execute asks the Inventory module for the product's stock level and adapts the result
 func (a *ProductInventoryAdapter) Execute(ctx context.Context, productID string) (InventoryData, error) {
-	output, err := a.inventoryQuery(ctx, productID)
+	level, err := bus.Ask[*inventorycontract.StockLevel](ctx, a.bus, inventorycontract.GetStockLevel{ProductID: productID})
 	if err != nil {
 		return nil, err
 	}
-	return NewInventoryAdapter(output), nil
+	return NewInventoryAdapter(toInventoryOutput(level)), nil
+}
+
+// ProductInventoryBatchAdapter implements InventoryBatchQueryInterface over the module bus
+type ProductInventoryBatchAdapter struct {
+	bus *bus.Bus
+}
+
+// NewProductInventoryBatchAdapter creates a new adapter
+func NewProductInventoryBatchAdapter(b *bus.Bus) *ProductInventoryBatchAdapter {
+	return &ProductInventoryBatchAdapter{
+		bus: b,
+	}
+}
+
+// Execute asks the Inventory module for the stock levels of the products and adapts the results
+func (a *ProductInventoryBatchAdapter) Execute(ctx context.Context, productIDs []string) (map[string]InventoryData, error) {
+	levels, err := bus.Ask[map[string]*inventorycontract.StockLevel](ctx, a.bus, inventorycontract.GetStockLevels{ProductIDs: productIDs})
+	if err != nil {
+		return nil, err
+	}
+	data := make(map[string]InventoryData, len(levels))
+	for productID, level := range levels {
+		data[productID] = NewInventoryAdapter(toInventoryOutput(level))
+	}
+	return data, nil
+}
+
+// toInventoryOutput converts a stock level from the Inventory module's contract
+func toInventoryOutput(level *inventorycontract.StockLevel) *InventoryOutput {
+	if level == nil {
+		return nil
+	}
+	return &InventoryOutput{
+		Quantity:          level.Quantity,
+		AvailableQuantity: level.AvailableQuantity,
+	}
 }
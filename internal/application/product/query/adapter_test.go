@@ -0,0 +1,38 @@
+package query_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	inventorycontract "github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/inventory/contract"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/product/query"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/bus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductInventoryBatchAdapter_AsksOverTheBus(t *testing.T) {
+	b := bus.New()
+	var asked []string
+	bus.HandleQuery(b, func(ctx context.Context, msg inventorycontract.GetStockLevels) (map[string]*inventorycontract.StockLevel, error) {
+		asked = msg.ProductIDs
+		return map[string]*inventorycontract.StockLevel{
+			"p1": {ProductID: "p1", Quantity: 8, AvailableQuantity: 5},
+		}, nil
+	})
+
+	data, err := query.NewProductInventoryBatchAdapter(b).Execute(context.Background(), []string{"p1", "p2"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"p1", "p2"}, asked)
+	require.Len(t, data, 1)
+	assert.Equal(t, 8, data["p1"].GetQuantity())
+	assert.Equal(t, 5, data["p1"].GetAvailableQuantity())
+}
+
+func TestProductInventoryAdapter_MissingHandler(t *testing.T) {
+	// Without the Inventory module wired in, the call fails instead of silently returning no stock
+	_, err := query.NewProductInventoryAdapter(bus.New()).Execute(context.Background(), "p1")
+	assert.True(t, errors.Is(err, bus.ErrNoHandler))
+}
@@ -0,0 +1,47 @@
+package query
+
+import (
+	"context"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/product/contract"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/bus"
+)
+
+// RegisterContractHandlers answers the Product module's contract messages on the module bus
+// Summaries are read from the product tables rather than the catalog read model, so a product
+// is visible to other modules as soon as it is stored.
+func RegisterContractHandlers(b *bus.Bus, productRepo product.ProductQueryRepository) {
+	getProduct := NewGetProductQuery(productRepo)
+	getProducts := NewGetProductsByIDsQuery(productRepo)
+
+	bus.HandleQuery(b, func(ctx context.Context, msg contract.GetProductSummary) (*contract.ProductSummary, error) {
+		output, err := getProduct.Execute(ctx, msg.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		return toProductSummary(output), nil
+	})
+
+	bus.HandleQuery(b, func(ctx context.Context, msg contract.GetProductSummaries) ([]*contract.ProductSummary, error) {
+		outputs, err := getProducts.Execute(ctx, msg.ProductIDs)
+		if err != nil {
+			return nil, err
+		}
+		summaries := make([]*contract.ProductSummary, 0, len(outputs))
+		for _, output := range outputs {
+			summaries = append(summaries, toProductSummary(output))
+		}
+		return summaries, nil
+	})
+}
+
+// toProductSummary converts a product output to the contract other modules see
+func toProductSummary(output *GetProductOutput) *contract.ProductSummary {
+	return &contract.ProductSummary{
+		ID:            output.ID,
+		Name:          output.Name,
+		PriceAmount:   output.PriceAmount,
+		PriceCurrency: output.PriceCurrency,
+	}
+}
@@ -12,6 +12,7 @@ import (
 type GetProductQuery struct {
 	productRepo    product.ProductQueryRepository
 	inventoryQuery InventoryQueryInterface
+	catalogRepo    product.CatalogQueryRepository
 }
 
 // InventoryQueryInterface defines the interface for inventory query operations
@@ -40,6 +41,14 @@ func NewGetProductQueryWithInventory(
 	}
 }
 
+// NewGetProductQueryWithCatalog creates a new instance that reads the product catalog read model
+// Product and stock data come from one denormalized entry instead of a round trip through the Inventory module
+func NewGetProductQueryWithCatalog(catalogRepo product.CatalogQueryRepository) *GetProductQuery {
+	return &GetProductQuery{
+		catalogRepo: catalogRepo,
+	}
+}
+
 // GetProductOutput represents the output data when retrieving a product
 type GetProductOutput struct {
 	ID            string    `json:"id"`
@@ -61,6 +70,10 @@ func (q *GetProductQuery) Execute(ctx context.Context, productID string) (*GetPr
 		return nil, apperrors.New(apperrors.CodeInvalidProductID, "product ID is required")
 	}
 
+	if q.catalogRepo != nil {
+		return q.executeFromCatalog(ctx, productID)
+	}
+
 	// Retrieve product from repository
 	prod, err := q.productRepo.GetByID(ctx, productID)
 	if err != nil {
@@ -98,3 +111,25 @@ func (q *GetProductQuery) Execute(ctx context.Context, productID string) (*GetPr
 	return output, nil
 }
 
+// executeFromCatalog reads the product with its stock levels in a single lookup
+func (q *GetProductQuery) executeFromCatalog(ctx context.Context, productID string) (*GetProductOutput, error) {
+	entry, err := q.catalogRepo.GetByProductID(ctx, productID)
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	if entry == nil {
+		return nil, product.ErrProductNotFound
+	}
+
+	return &GetProductOutput{
+		ID:                entry.ProductID,
+		Name:              entry.Name,
+		PriceAmount:       entry.PriceAmount,
+		PriceCurrency:     entry.PriceCurrency,
+		CreatedAt:         entry.CreatedAt,
+		UpdatedAt:         entry.UpdatedAt,
+		HasInventory:      entry.HasInventory,
+		StockQuantity:     entry.Quantity,
+		AvailableQuantity: entry.AvailableQuantity,
+	}, nil
+}
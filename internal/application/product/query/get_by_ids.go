@@ -0,0 +1,43 @@
+package query
+
+import (
+	"context"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// GetProductsByIDsQuery handles the business logic for retrieving several products at once
+// It is meant for list enrichment, so inventory data is not included
+type GetProductsByIDsQuery struct {
+	productRepo product.ProductQueryRepository
+}
+
+// NewGetProductsByIDsQuery creates a new instance of GetProductsByIDsQuery
+func NewGetProductsByIDsQuery(productRepo product.ProductQueryRepository) *GetProductsByIDsQuery {
+	return &GetProductsByIDsQuery{
+		productRepo: productRepo,
+	}
+}
+
+// Execute retrieves the products in a single repository call
+// Unknown product IDs are omitted from the result
+func (q *GetProductsByIDsQuery) Execute(ctx context.Context, productIDs []string) ([]*GetProductOutput, error) {
+	products, err := q.productRepo.GetByIDs(ctx, productIDs)
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	outputs := make([]*GetProductOutput, 0, len(products))
+	for _, prod := range products {
+		outputs = append(outputs, &GetProductOutput{
+			ID:            prod.ID(),
+			Name:          prod.Name(),
+			PriceAmount:   prod.Price().Amount(),
+			PriceCurrency: prod.Price().Currency(),
+			CreatedAt:     prod.CreatedAt(),
+			UpdatedAt:     prod.UpdatedAt(),
+		})
+	}
+	return outputs, nil
+}
@@ -0,0 +1,111 @@
+package query
+
+import (
+	"context"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+const (
+	// DefaultProductPageSize is used when no page size is requested
+	DefaultProductPageSize = 20
+	// MaxProductPageSize bounds how many products a single page may contain
+	MaxProductPageSize = 100
+)
+
+// InventoryBatchQueryInterface defines the interface for retrieving the inventory of several products at once
+// This lets list queries enrich many products with a single call to the Inventory module
+type InventoryBatchQueryInterface interface {
+	Execute(ctx context.Context, productIDs []string) (map[string]InventoryData, error)
+}
+
+// ListProductsInput represents the paging for listing products
+type ListProductsInput struct {
+	Page             int  `form:"page" validate:"min=0"`
+	PageSize         int  `form:"page_size" validate:"min=0,max=100"`
+	IncludeInventory bool `form:"include_inventory"`
+}
+
+// ListProductsOutput represents a page of products
+type ListProductsOutput struct {
+	Items    []*GetProductOutput `json:"items"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+}
+
+// ListProductsQuery handles the business logic for listing products
+type ListProductsQuery struct {
+	productRepo      product.ProductQueryRepository
+	inventoriesQuery InventoryBatchQueryInterface
+}
+
+// NewListProductsQuery creates a new instance of ListProductsQuery
+// Stock levels are fetched for the whole page in one call to the Inventory module
+func NewListProductsQuery(
+	productRepo product.ProductQueryRepository,
+	inventoriesQuery InventoryBatchQueryInterface,
+) *ListProductsQuery {
+	return &ListProductsQuery{
+		productRepo:      productRepo,
+		inventoriesQuery: inventoriesQuery,
+	}
+}
+
+// Execute performs the list products operation
+func (q *ListProductsQuery) Execute(ctx context.Context, input ListProductsInput) (*ListProductsOutput, error) {
+	// Apply paging defaults
+	page := input.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := input.PageSize
+	if pageSize < 1 {
+		pageSize = DefaultProductPageSize
+	}
+	if pageSize > MaxProductPageSize {
+		return nil, apperrors.Newf(apperrors.CodeInvalidInput, "page size cannot exceed %d", MaxProductPageSize)
+	}
+
+	products, err := q.productRepo.List(ctx, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	// MODULE COMMUNICATION: Enrich the whole page with one Inventory module call
+	inventories := make(map[string]InventoryData)
+	if input.IncludeInventory && q.inventoriesQuery != nil && len(products) > 0 {
+		productIDs := make([]string, 0, len(products))
+		for _, prod := range products {
+			productIDs = append(productIDs, prod.ID())
+		}
+		inventories, err = q.inventoriesQuery.Execute(ctx, productIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	items := make([]*GetProductOutput, 0, len(products))
+	for _, prod := range products {
+		item := &GetProductOutput{
+			ID:            prod.ID(),
+			Name:          prod.Name(),
+			PriceAmount:   prod.Price().Amount(),
+			PriceCurrency: prod.Price().Currency(),
+			CreatedAt:     prod.CreatedAt(),
+			UpdatedAt:     prod.UpdatedAt(),
+		}
+		if inventoryData, ok := inventories[prod.ID()]; ok {
+			item.HasInventory = true
+			item.StockQuantity = inventoryData.GetQuantity()
+			item.AvailableQuantity = inventoryData.GetAvailableQuantity()
+		}
+		items = append(items, item)
+	}
+
+	return &ListProductsOutput{
+		Items:    items,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
@@ -0,0 +1,98 @@
+package query_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/product/query"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProductQueryRepository returns a fixed page and records the paging it received
+type fakeProductQueryRepository struct {
+	product.ProductQueryRepository
+	items         []*product.Product
+	limit, offset int
+}
+
+func (r *fakeProductQueryRepository) List(ctx context.Context, limit, offset int) ([]*product.Product, error) {
+	r.limit, r.offset = limit, offset
+	return r.items, nil
+}
+
+// fakeInventoryBatchQuery counts how often the Inventory module is called
+type fakeInventoryBatchQuery struct {
+	calls       int
+	inventories map[string]query.InventoryData
+}
+
+func (q *fakeInventoryBatchQuery) Execute(ctx context.Context, productIDs []string) (map[string]query.InventoryData, error) {
+	q.calls++
+	return q.inventories, nil
+}
+
+func newListFixtures(t *testing.T) (*fakeProductQueryRepository, *fakeInventoryBatchQuery) {
+	t.Helper()
+	repo := &fakeProductQueryRepository{}
+	for _, id := range []string{"product-1", "product-2"} {
+		price, err := product.NewPrice(10, "USD")
+		require.NoError(t, err)
+		prod, err := product.NewProduct(id, "Product "+id, price)
+		require.NoError(t, err)
+		repo.items = append(repo.items, prod)
+	}
+	inventories := &fakeInventoryBatchQuery{
+		inventories: map[string]query.InventoryData{
+			"product-1": query.NewInventoryAdapter(&query.InventoryOutput{Quantity: 8, AvailableQuantity: 5}),
+		},
+	}
+	return repo, inventories
+}
+
+func TestListProductsQuery_Execute(t *testing.T) {
+	repo, inventories := newListFixtures(t)
+	q := query.NewListProductsQuery(repo, inventories)
+
+	output, err := q.Execute(context.Background(), query.ListProductsInput{
+		Page:             3,
+		PageSize:         10,
+		IncludeInventory: true,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 10, repo.limit)
+	assert.Equal(t, 20, repo.offset)
+	assert.Equal(t, 3, output.Page)
+	require.Len(t, output.Items, 2)
+
+	// Stock levels for the whole page come from a single batched call
+	assert.Equal(t, 1, inventories.calls)
+	assert.True(t, output.Items[0].HasInventory)
+	assert.Equal(t, 8, output.Items[0].StockQuantity)
+	assert.Equal(t, 5, output.Items[0].AvailableQuantity)
+	assert.False(t, output.Items[1].HasInventory)
+}
+
+func TestListProductsQuery_Execute_WithoutEnrichment(t *testing.T) {
+	repo, inventories := newListFixtures(t)
+	q := query.NewListProductsQuery(repo, inventories)
+
+	output, err := q.Execute(context.Background(), query.ListProductsInput{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, inventories.calls)
+	assert.Equal(t, 1, output.Page)
+	assert.Equal(t, query.DefaultProductPageSize, output.PageSize)
+	assert.False(t, output.Items[0].HasInventory)
+}
+
+func TestListProductsQuery_Execute_PageSizeBound(t *testing.T) {
+	repo, inventories := newListFixtures(t)
+	q := query.NewListProductsQuery(repo, inventories)
+
+	_, err := q.Execute(context.Background(), query.ListProductsInput{PageSize: query.MaxProductPageSize + 1})
+	assert.True(t, apperrors.Is(err, apperrors.CodeInvalidInput))
+}
@@ -0,0 +1,74 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/webhook"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// CreateSubscriptionInput represents the input data for subscribing an endpoint to events
+// A missing Secret is generated.
+type CreateSubscriptionInput struct {
+	URL        string   `json:"url" validate:"required,max=2048"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,required"`
+	Secret     string   `json:"secret" validate:"omitempty,min=16,max=255"`
+}
+
+// CreateSubscriptionOutput represents the created subscription with the secret that signs its deliveries
+// This is the only time the secret is returned.
+type CreateSubscriptionOutput struct {
+	SubscriptionOutput
+	Secret string `json:"secret"`
+}
+
+// CreateSubscriptionCommand handles the business logic for creating a webhook subscription
+type CreateSubscriptionCommand struct {
+	store webhook.Store
+}
+
+// NewCreateSubscriptionCommand creates a new instance of CreateSubscriptionCommand
+func NewCreateSubscriptionCommand(store webhook.Store) *CreateSubscriptionCommand {
+	return &CreateSubscriptionCommand{
+		store: store,
+	}
+}
+
+// Execute performs the create subscription operation for the tenant of the context
+func (c *CreateSubscriptionCommand) Execute(ctx context.Context, input CreateSubscriptionInput) (*CreateSubscriptionOutput, error) {
+	if err := validateURL(input.URL); err != nil {
+		return nil, err
+	}
+	eventTypes, err := validateEventTypes(input.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := input.Secret
+	if secret == "" {
+		if secret, err = webhook.NewSecret(); err != nil {
+			return nil, apperrors.Wrap(err, apperrors.CodeInternalError, "failed to generate a webhook secret")
+		}
+	}
+
+	now := time.Now().UTC()
+	sub := &webhook.Subscription{
+		ID:         uuid.New().String(),
+		URL:        input.URL,
+		EventTypes: eventTypes,
+		Secret:     secret,
+		Active:     true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := c.store.CreateSubscription(ctx, sub); err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	return &CreateSubscriptionOutput{
+		SubscriptionOutput: newSubscriptionOutput(sub),
+		Secret:             secret,
+	}, nil
+}
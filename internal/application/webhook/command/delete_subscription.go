@@ -0,0 +1,33 @@
+package command
+
+import (
+	"context"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/webhook"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// DeleteSubscriptionCommand handles the business logic for deleting a webhook subscription
+// Its delivery log, including deliveries not yet sent, goes with it.
+type DeleteSubscriptionCommand struct {
+	store webhook.Store
+}
+
+// NewDeleteSubscriptionCommand creates a new instance of DeleteSubscriptionCommand
+func NewDeleteSubscriptionCommand(store webhook.Store) *DeleteSubscriptionCommand {
+	return &DeleteSubscriptionCommand{
+		store: store,
+	}
+}
+
+// Execute performs the delete subscription operation for the tenant of the context
+func (c *DeleteSubscriptionCommand) Execute(ctx context.Context, id string) error {
+	deleted, err := c.store.DeleteSubscription(ctx, id)
+	if err != nil {
+		return apperrors.WrapDatabaseError(err)
+	}
+	if !deleted {
+		return apperrors.Newf(apperrors.CodeWebhookSubscriptionNotFound, "no webhook subscription with ID %s", id)
+	}
+	return nil
+}
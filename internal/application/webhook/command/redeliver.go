@@ -0,0 +1,51 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/webhook"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// RedeliverOutput represents a delivery queued for sending again
+type RedeliverOutput struct {
+	ID             int64     `json:"id"`
+	SubscriptionID string    `json:"subscription_id"`
+	RedeliveredAt  time.Time `json:"redelivered_at"`
+}
+
+// RedeliverCommand handles the business logic for sending a webhook delivery again
+// Any delivery can be redelivered, whatever its status, with a fresh set of attempts.
+type RedeliverCommand struct {
+	store webhook.Store
+}
+
+// NewRedeliverCommand creates a new instance of RedeliverCommand
+func NewRedeliverCommand(store webhook.Store) *RedeliverCommand {
+	return &RedeliverCommand{
+		store: store,
+	}
+}
+
+// Execute performs the redeliver operation for a delivery of a subscription of the tenant of the context
+func (c *RedeliverCommand) Execute(ctx context.Context, subscriptionID string, id int64) (*RedeliverOutput, error) {
+	sub, err := getSubscription(ctx, c.store, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	// A disabled subscription gets nothing, so the redelivery would only wait
+	if !sub.Active {
+		return nil, apperrors.Newf(apperrors.CodeWebhookSubscriptionDisabled, "webhook subscription %s is disabled; enable it before redelivering", sub.ID)
+	}
+
+	now := time.Now().UTC()
+	redelivered, err := c.store.Redeliver(ctx, sub.ID, id, now)
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	if !redelivered {
+		return nil, apperrors.Newf(apperrors.CodeWebhookDeliveryNotFound, "webhook subscription %s has no delivery with ID %d", sub.ID, id)
+	}
+	return &RedeliverOutput{ID: id, SubscriptionID: sub.ID, RedeliveredAt: now}, nil
+}
@@ -0,0 +1,131 @@
+package command
+
+import (
+	"context"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/webhook"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// EventTypes are the events a subscription can name, besides webhook.AllEvents
+var EventTypes = []string{
+	product.EventProductCreated,
+	product.EventProductPriceChanged,
+	inventory.EventInventoryCreated,
+	inventory.EventInventoryRelocated,
+	inventory.EventStockAdjusted,
+	inventory.EventStockReserved,
+	inventory.EventStockReleased,
+	inventory.EventStockDepleted,
+	inventory.EventStockPolicyChanged,
+	inventory.EventMetadataUpdated,
+	inventory.EventInventoryDeleted,
+}
+
+// SubscriptionOutput represents a webhook subscription
+// Only the end of the secret is shown; the full secret is returned once, on creation.
+type SubscriptionOutput struct {
+	ID                  string     `json:"id"`
+	URL                 string     `json:"url"`
+	EventTypes          []string   `json:"event_types"`
+	SecretHint          string     `json:"secret_hint"`
+	Active              bool       `json:"active"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// newSubscriptionOutput converts a subscription to its output
+func newSubscriptionOutput(sub *webhook.Subscription) SubscriptionOutput {
+	output := SubscriptionOutput{
+		ID:                  sub.ID,
+		URL:                 sub.URL,
+		EventTypes:          sub.EventTypes,
+		SecretHint:          secretHint(sub.Secret),
+		Active:              sub.Active,
+		ConsecutiveFailures: sub.ConsecutiveFailures,
+		CreatedAt:           sub.CreatedAt,
+		UpdatedAt:           sub.UpdatedAt,
+	}
+	if !sub.DisabledAt.IsZero() {
+		output.DisabledAt = &sub.DisabledAt
+	}
+	return output
+}
+
+// getSubscription returns a subscription of the tenant of the context, or CodeWebhookSubscriptionNotFound
+func getSubscription(ctx context.Context, store webhook.Store, id string) (*webhook.Subscription, error) {
+	sub, err := store.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	if sub == nil {
+		return nil, apperrors.Newf(apperrors.CodeWebhookSubscriptionNotFound, "no webhook subscription with ID %s", id)
+	}
+	return sub, nil
+}
+
+// validateURL checks that deliveries can be POSTed to rawURL
+// Hosts that are plainly not public are refused here; host names that resolve to
+// such an address are refused by the dispatcher when it connects.
+func validateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return apperrors.Newf(apperrors.CodeInvalidInput, "webhook URL %q must be an absolute http or https URL", rawURL)
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return apperrors.Newf(apperrors.CodeInvalidInput, "webhook URL %q must not point to localhost", rawURL)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !webhook.IsPublicAddress(addr) {
+		return apperrors.Newf(apperrors.CodeInvalidInput, "webhook URL %q must point to a public address", rawURL)
+	}
+	return nil
+}
+
+// validateEventTypes checks that every event type is known, and drops duplicates
+func validateEventTypes(eventTypes []string) ([]string, error) {
+	if len(eventTypes) == 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidInput, "at least one event type is required")
+	}
+	seen := make(map[string]bool, len(eventTypes))
+	unique := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		if !isKnownEventType(eventType) {
+			return nil, apperrors.Newf(apperrors.CodeInvalidInput, "unknown event type %q; use one of %v or %q", eventType, EventTypes, webhook.AllEvents)
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			unique = append(unique, eventType)
+		}
+	}
+	return unique, nil
+}
+
+// isKnownEventType reports whether a subscription can name eventType
+func isKnownEventType(eventType string) bool {
+	if eventType == webhook.AllEvents {
+		return true
+	}
+	for _, known := range EventTypes {
+		if eventType == known {
+			return true
+		}
+	}
+	return false
+}
+
+// secretHint returns the last four characters of a secret, the rest masked
+func secretHint(secret string) string {
+	if len(secret) <= 4 {
+		return "****"
+	}
+	return "****" + secret[len(secret)-4:]
+}
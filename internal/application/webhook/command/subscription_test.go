@@ -0,0 +1,149 @@
+package command_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/webhook/command"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/webhook"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var acme = tenant.NewContext(context.Background(), tenant.Tenant{ID: "acme"})
+
+// fakeWebhookStore keeps subscriptions and the IDs of the deliveries it has
+type fakeWebhookStore struct {
+	webhook.Store
+	subscriptions map[string]webhook.Subscription
+	deliveries    map[int64]string
+	redelivered   []int64
+}
+
+func newFakeWebhookStore() *fakeWebhookStore {
+	return &fakeWebhookStore{subscriptions: make(map[string]webhook.Subscription), deliveries: make(map[int64]string)}
+}
+
+func (s *fakeWebhookStore) CreateSubscription(ctx context.Context, sub *webhook.Subscription) error {
+	s.subscriptions[sub.ID] = *sub
+	return nil
+}
+
+func (s *fakeWebhookStore) GetSubscription(ctx context.Context, id string) (*webhook.Subscription, error) {
+	sub, ok := s.subscriptions[id]
+	if !ok {
+		return nil, nil
+	}
+	return &sub, nil
+}
+
+func (s *fakeWebhookStore) UpdateSubscription(ctx context.Context, sub *webhook.Subscription) error {
+	s.subscriptions[sub.ID] = *sub
+	return nil
+}
+
+func (s *fakeWebhookStore) Redeliver(ctx context.Context, subscriptionID string, id int64, now time.Time) (bool, error) {
+	if s.deliveries[id] != subscriptionID {
+		return false, nil
+	}
+	s.redelivered = append(s.redelivered, id)
+	return true, nil
+}
+
+func createSubscription(t *testing.T, store *fakeWebhookStore) *command.CreateSubscriptionOutput {
+	t.Helper()
+	output, err := command.NewCreateSubscriptionCommand(store).Execute(acme, command.CreateSubscriptionInput{
+		URL:        "https://example.com/hooks",
+		EventTypes: []string{product.EventProductCreated, product.EventProductCreated},
+	})
+	require.NoError(t, err)
+	return output
+}
+
+func TestCreateSubscriptionCommand_GeneratesASecret(t *testing.T) {
+	store := newFakeWebhookStore()
+
+	output := createSubscription(t, store)
+
+	assert.True(t, strings.HasPrefix(output.Secret, "whsec_"))
+	assert.Equal(t, "****"+output.Secret[len(output.Secret)-4:], output.SecretHint)
+	assert.Equal(t, []string{product.EventProductCreated}, output.EventTypes, "duplicate event types are dropped")
+	assert.True(t, output.Active)
+
+	stored, err := store.GetSubscription(acme, output.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, output.Secret, stored.Secret)
+}
+
+func TestCreateSubscriptionCommand_RejectsInvalidInput(t *testing.T) {
+	create := command.NewCreateSubscriptionCommand(newFakeWebhookStore())
+	tests := map[string]command.CreateSubscriptionInput{
+		"relative URL":       {URL: "/hooks", EventTypes: []string{webhook.AllEvents}},
+		"other scheme":       {URL: "ftp://example.com/hooks", EventTypes: []string{webhook.AllEvents}},
+		"unknown event type": {URL: "https://example.com/hooks", EventTypes: []string{"product.deleted"}},
+		"no event types":     {URL: "https://example.com/hooks"},
+		"localhost":          {URL: "http://localhost:8080/hooks", EventTypes: []string{webhook.AllEvents}},
+		"loopback":           {URL: "http://127.0.0.1/hooks", EventTypes: []string{webhook.AllEvents}},
+		"IPv6 loopback":      {URL: "http://[::1]/hooks", EventTypes: []string{webhook.AllEvents}},
+		"instance metadata":  {URL: "http://169.254.169.254/latest/meta-data", EventTypes: []string{webhook.AllEvents}},
+		"private network":    {URL: "https://10.0.0.5/hooks", EventTypes: []string{webhook.AllEvents}},
+		"IPv4-mapped":        {URL: "http://[::ffff:192.168.1.1]/hooks", EventTypes: []string{webhook.AllEvents}},
+	}
+	for name, input := range tests {
+		_, err := create.Execute(acme, input)
+		assert.True(t, apperrors.Is(err, apperrors.CodeInvalidInput), "%s: err = %v", name, err)
+	}
+}
+
+func TestUpdateSubscriptionCommand_EnablingResetsTheFailures(t *testing.T) {
+	store := newFakeWebhookStore()
+	created := createSubscription(t, store)
+	disabled := store.subscriptions[created.ID]
+	disabled.Active = false
+	disabled.ConsecutiveFailures = 3
+	disabled.DisabledAt = time.Now().UTC()
+	store.subscriptions[created.ID] = disabled
+
+	active := true
+	output, err := command.NewUpdateSubscriptionCommand(store).Execute(acme, created.ID, command.UpdateSubscriptionInput{Active: &active})
+	require.NoError(t, err)
+
+	assert.True(t, output.Active)
+	assert.Zero(t, output.ConsecutiveFailures)
+	assert.Nil(t, output.DisabledAt)
+	assert.Equal(t, []string{product.EventProductCreated}, output.EventTypes, "fields left out are kept")
+}
+
+func TestUpdateSubscriptionCommand_UnknownSubscription(t *testing.T) {
+	update := command.NewUpdateSubscriptionCommand(newFakeWebhookStore())
+
+	_, err := update.Execute(acme, "missing", command.UpdateSubscriptionInput{})
+	assert.True(t, apperrors.Is(err, apperrors.CodeWebhookSubscriptionNotFound))
+}
+
+func TestRedeliverCommand_RequiresAnActiveSubscription(t *testing.T) {
+	store := newFakeWebhookStore()
+	created := createSubscription(t, store)
+	store.deliveries[7] = created.ID
+	redeliver := command.NewRedeliverCommand(store)
+
+	_, err := redeliver.Execute(acme, created.ID, 8)
+	assert.True(t, apperrors.Is(err, apperrors.CodeWebhookDeliveryNotFound))
+
+	output, err := redeliver.Execute(acme, created.ID, 7)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), output.ID)
+	assert.Equal(t, []int64{7}, store.redelivered)
+
+	inactive := false
+	_, err = command.NewUpdateSubscriptionCommand(store).Execute(acme, created.ID, command.UpdateSubscriptionInput{Active: &inactive})
+	require.NoError(t, err)
+	_, err = redeliver.Execute(acme, created.ID, 7)
+	assert.True(t, apperrors.Is(err, apperrors.CodeWebhookSubscriptionDisabled))
+}
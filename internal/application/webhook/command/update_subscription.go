@@ -0,0 +1,70 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/webhook"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// UpdateSubscriptionInput represents the changes to a webhook subscription; missing fields are kept
+// Setting Active enables a subscription that was disabled after repeated failures, and its
+// pending deliveries are sent again.
+type UpdateSubscriptionInput struct {
+	URL        *string  `json:"url" validate:"omitempty,max=2048"`
+	EventTypes []string `json:"event_types" validate:"omitempty,min=1,dive,required"`
+	Secret     *string  `json:"secret" validate:"omitempty,min=16,max=255"`
+	Active     *bool    `json:"active"`
+}
+
+// UpdateSubscriptionCommand handles the business logic for updating a webhook subscription
+type UpdateSubscriptionCommand struct {
+	store webhook.Store
+}
+
+// NewUpdateSubscriptionCommand creates a new instance of UpdateSubscriptionCommand
+func NewUpdateSubscriptionCommand(store webhook.Store) *UpdateSubscriptionCommand {
+	return &UpdateSubscriptionCommand{
+		store: store,
+	}
+}
+
+// Execute performs the update subscription operation for the tenant of the context
+func (c *UpdateSubscriptionCommand) Execute(ctx context.Context, id string, input UpdateSubscriptionInput) (*SubscriptionOutput, error) {
+	sub, err := getSubscription(ctx, c.store, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.URL != nil {
+		if err := validateURL(*input.URL); err != nil {
+			return nil, err
+		}
+		sub.URL = *input.URL
+	}
+	if input.EventTypes != nil {
+		if sub.EventTypes, err = validateEventTypes(input.EventTypes); err != nil {
+			return nil, err
+		}
+	}
+	if input.Secret != nil {
+		sub.Secret = *input.Secret
+	}
+	if input.Active != nil {
+		if *input.Active && !sub.Active {
+			// Enabled again: the failures that disabled it no longer count
+			sub.ConsecutiveFailures = 0
+			sub.DisabledAt = time.Time{}
+		}
+		sub.Active = *input.Active
+	}
+	sub.UpdatedAt = time.Now().UTC()
+
+	if err := c.store.UpdateSubscription(ctx, sub); err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	output := newSubscriptionOutput(sub)
+	return &output, nil
+}
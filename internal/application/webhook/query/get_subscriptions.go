@@ -0,0 +1,108 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/webhook"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// SubscriptionOutput represents a webhook subscription
+// Only the end of the secret is shown, enough to tell which secret a receiver should hold.
+type SubscriptionOutput struct {
+	ID                  string     `json:"id"`
+	URL                 string     `json:"url"`
+	EventTypes          []string   `json:"event_types"`
+	SecretHint          string     `json:"secret_hint"`
+	Active              bool       `json:"active"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// GetSubscriptionQuery handles the business logic for retrieving a webhook subscription
+type GetSubscriptionQuery struct {
+	store webhook.Store
+}
+
+// NewGetSubscriptionQuery creates a new instance of GetSubscriptionQuery
+func NewGetSubscriptionQuery(store webhook.Store) *GetSubscriptionQuery {
+	return &GetSubscriptionQuery{
+		store: store,
+	}
+}
+
+// Execute performs the get subscription operation for the tenant of the context
+func (q *GetSubscriptionQuery) Execute(ctx context.Context, id string) (*SubscriptionOutput, error) {
+	sub, err := getSubscription(ctx, q.store, id)
+	if err != nil {
+		return nil, err
+	}
+	return newSubscriptionOutput(sub), nil
+}
+
+// ListSubscriptionsQuery handles the business logic for listing webhook subscriptions
+type ListSubscriptionsQuery struct {
+	store webhook.Store
+}
+
+// NewListSubscriptionsQuery creates a new instance of ListSubscriptionsQuery
+func NewListSubscriptionsQuery(store webhook.Store) *ListSubscriptionsQuery {
+	return &ListSubscriptionsQuery{
+		store: store,
+	}
+}
+
+// Execute performs the list subscriptions operation for the tenant of the context, oldest first
+func (q *ListSubscriptionsQuery) Execute(ctx context.Context) ([]*SubscriptionOutput, error) {
+	subscriptions, err := q.store.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	items := make([]*SubscriptionOutput, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		items = append(items, newSubscriptionOutput(sub))
+	}
+	return items, nil
+}
+
+// getSubscription returns a subscription of the tenant of the context, or CodeWebhookSubscriptionNotFound
+func getSubscription(ctx context.Context, store webhook.Store, id string) (*webhook.Subscription, error) {
+	sub, err := store.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	if sub == nil {
+		return nil, apperrors.Newf(apperrors.CodeWebhookSubscriptionNotFound, "no webhook subscription with ID %s", id)
+	}
+	return sub, nil
+}
+
+// newSubscriptionOutput converts a subscription to its output, masking the secret
+func newSubscriptionOutput(sub *webhook.Subscription) *SubscriptionOutput {
+	output := &SubscriptionOutput{
+		ID:                  sub.ID,
+		URL:                 sub.URL,
+		EventTypes:          sub.EventTypes,
+		SecretHint:          secretHint(sub.Secret),
+		Active:              sub.Active,
+		ConsecutiveFailures: sub.ConsecutiveFailures,
+		CreatedAt:           sub.CreatedAt,
+		UpdatedAt:           sub.UpdatedAt,
+	}
+	if !sub.DisabledAt.IsZero() {
+		output.DisabledAt = &sub.DisabledAt
+	}
+	return output
+}
+
+// secretHint returns the last four characters of a secret, the rest masked
+func secretHint(secret string) string {
+	if len(secret) <= 4 {
+		return "****"
+	}
+	return "****" + secret[len(secret)-4:]
+}
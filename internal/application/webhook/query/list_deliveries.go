@@ -0,0 +1,132 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/webhook"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+const (
+	// DefaultDeliveryPageSize is used when no page size is requested
+	DefaultDeliveryPageSize = 20
+	// MaxDeliveryPageSize bounds how many deliveries a single page may contain
+	MaxDeliveryPageSize = 100
+)
+
+// ListDeliveriesInput represents the filter and paging for listing the deliveries of a subscription
+type ListDeliveriesInput struct {
+	Status   string `form:"status" validate:"omitempty,oneof=pending delivered failed"`
+	Page     int    `form:"page" validate:"min=0"`
+	PageSize int    `form:"page_size" validate:"min=0,max=100"`
+}
+
+// DeliveryOutput represents an event sent, or to be sent, to a subscription
+type DeliveryOutput struct {
+	ID             int64           `json:"id"`
+	MessageID      int64           `json:"message_id"`
+	EventName      string          `json:"event_name"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// ListDeliveriesOutput represents a page of the delivery log of a subscription, newest first
+type ListDeliveriesOutput struct {
+	Items    []*DeliveryOutput `json:"items"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+	Total    int               `json:"total"`
+}
+
+// ListDeliveriesQuery handles the business logic for listing the delivery log of a webhook subscription
+type ListDeliveriesQuery struct {
+	store webhook.Store
+}
+
+// NewListDeliveriesQuery creates a new instance of ListDeliveriesQuery
+func NewListDeliveriesQuery(store webhook.Store) *ListDeliveriesQuery {
+	return &ListDeliveriesQuery{
+		store: store,
+	}
+}
+
+// Execute performs the list deliveries operation for a subscription of the tenant of the context
+func (q *ListDeliveriesQuery) Execute(ctx context.Context, subscriptionID string, input ListDeliveriesInput) (*ListDeliveriesOutput, error) {
+	// Apply paging defaults
+	page := input.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := input.PageSize
+	if pageSize < 1 {
+		pageSize = DefaultDeliveryPageSize
+	}
+	if pageSize > MaxDeliveryPageSize {
+		return nil, apperrors.Newf(apperrors.CodeInvalidInput, "page size cannot exceed %d", MaxDeliveryPageSize)
+	}
+
+	if _, err := getSubscription(ctx, q.store, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	filter := webhook.DeliveryFilter{
+		Status: webhook.DeliveryStatus(input.Status),
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	}
+
+	deliveries, err := q.store.ListDeliveries(ctx, subscriptionID, filter)
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	total, err := q.store.CountDeliveries(ctx, subscriptionID, filter)
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	items := make([]*DeliveryOutput, 0, len(deliveries))
+	for _, d := range deliveries {
+		item := &DeliveryOutput{
+			ID:             d.ID,
+			MessageID:      d.MessageID,
+			EventName:      d.EventName,
+			Payload:        d.Payload,
+			Status:         string(d.Status),
+			Attempts:       d.Attempts,
+			LastStatusCode: d.LastStatusCode,
+			LastError:      d.LastError,
+			LastAttemptAt:  timeOrNil(d.LastAttemptAt),
+			DeliveredAt:    timeOrNil(d.DeliveredAt),
+			CreatedAt:      d.CreatedAt,
+		}
+		// Only pending deliveries have another attempt coming
+		if d.Status == webhook.DeliveryPending {
+			item.NextAttemptAt = timeOrNil(d.NextAttemptAt)
+		}
+		items = append(items, item)
+	}
+
+	return &ListDeliveriesOutput{
+		Items:    items,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}, nil
+}
+
+// timeOrNil returns nil for the zero time, so it is left out of the output
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,39 @@
+package event
+
+import "time"
+
+// Event is something that happened to an aggregate
+// Events are named in the past tense, e.g. "product.created", and are immutable once raised.
+type Event interface {
+	// EventName identifies the kind of event; handlers subscribe by name
+	EventName() string
+
+	// AggregateID identifies the aggregate the event happened to
+	AggregateID() string
+
+	// OccurredAt returns when the event happened
+	OccurredAt() time.Time
+}
+
+// Events collects the events an aggregate raises until they are pulled for dispatch
+// The zero value is ready to use.
+type Events struct {
+	pending []Event
+}
+
+// Record adds an event to the pending events
+func (e *Events) Record(event Event) {
+	e.pending = append(e.pending, event)
+}
+
+// Pending returns the pending events in the order they were raised, leaving them pending
+func (e *Events) Pending() []Event {
+	return append([]Event(nil), e.pending...)
+}
+
+// Pull returns the pending events in the order they were raised and clears them
+func (e *Events) Pull() []Event {
+	pending := e.pending
+	e.pending = nil
+	return pending
+}
@@ -11,8 +11,14 @@ type InventoryCommandRepository interface {
 	// Update updates an existing inventory record
 	Update(ctx context.Context, inventory *Inventory) error
 
-	// Delete removes an inventory record by product ID
-	Delete(ctx context.Context, productID string) error
+	// UpdateBatch updates several inventory records within a single transaction
+	// Either every record is written or none is
+	UpdateBatch(ctx context.Context, inventories []*Inventory) error
+
+	// Delete removes an inventory record as it was loaded
+	// It fails with ErrConcurrentModification if the record changed since, so stock
+	// reserved or backordered in between is never removed with it.
+	Delete(ctx context.Context, inventory *Inventory) error
 
 	// AdjustStock adjusts the stock quantity for a product
 	AdjustStock(ctx context.Context, productID string, adjustment int) error
@@ -3,18 +3,31 @@ package inventory
 import (
 	"time"
 
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/event"
 	"github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
 )
 
+// Metadata limits
+const (
+	MaxMetadataEntries     = 50
+	MaxMetadataKeyLength   = 64
+	MaxMetadataValueLength = 255
+)
+
 // Inventory represents an inventory entity in the domain
 type Inventory struct {
-	id               string
-	productID        string
-	quantity         int
-	reservedQuantity int
-	location         string
-	createdAt        time.Time
-	updatedAt        time.Time
+	id                  string
+	productID           string
+	quantity            int
+	reservedQuantity    int
+	backorderedQuantity int
+	stockPolicy         StockPolicy
+	location            string
+	metadata            map[string]string
+	createdAt           time.Time
+	updatedAt           time.Time
+	version             int
+	events              event.Events
 }
 
 // NewInventory creates a new Inventory entity with validation
@@ -29,29 +42,30 @@ func NewInventory(id, productID string, quantity int, location string) (*Invento
 		return nil, ErrInvalidQuantity
 	}
 
-	now := time.Now()
-	return &Inventory{
-		id:               id,
-		productID:        productID,
-		quantity:         quantity,
-		reservedQuantity: 0,
-		location:         location,
-		createdAt:        now,
-		updatedAt:        now,
-	}, nil
+	inv := &Inventory{version: 1}
+	inv.raise(InventoryCreated{ID: id, ProductID: productID, Quantity: quantity, Location: location, At: time.Now()})
+	return inv, nil
 }
 
 // ReconstructInventory reconstructs an Inventory entity from persistence
-// This is used when loading from database
-func ReconstructInventory(id, productID string, quantity, reservedQuantity int, location string, createdAt, updatedAt time.Time) *Inventory {
+// This is used when loading from database; version is the persisted optimistic lock version
+func ReconstructInventory(id, productID string, quantity, reservedQuantity, backorderedQuantity int, stockPolicy StockPolicy, location string, metadata map[string]string, createdAt, updatedAt time.Time, version int) *Inventory {
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
 	return &Inventory{
-		id:               id,
-		productID:        productID,
-		quantity:         quantity,
-		reservedQuantity: reservedQuantity,
-		location:         location,
-		createdAt:        createdAt,
-		updatedAt:        updatedAt,
+		id:                  id,
+		productID:           productID,
+		quantity:            quantity,
+		reservedQuantity:    reservedQuantity,
+		backorderedQuantity: backorderedQuantity,
+		stockPolicy:         stockPolicy,
+		location:            location,
+		metadata:            metadata,
+		createdAt:           createdAt,
+		updatedAt:           updatedAt,
+		version:             version,
 	}
 }
 
@@ -75,11 +89,30 @@ func (i *Inventory) ReservedQuantity() int {
 	return i.reservedQuantity
 }
 
+// BackorderedQuantity returns the quantity promised beyond stock on hand
+func (i *Inventory) BackorderedQuantity() int {
+	return i.backorderedQuantity
+}
+
+// StockPolicy returns the oversell policy of this product
+func (i *Inventory) StockPolicy() StockPolicy {
+	return i.stockPolicy
+}
+
 // Location returns the storage location
 func (i *Inventory) Location() string {
 	return i.location
 }
 
+// Metadata returns a copy of the free-form attributes (e.g. aisle, bin, supplier SKU)
+func (i *Inventory) Metadata() map[string]string {
+	metadata := make(map[string]string, len(i.metadata))
+	for key, value := range i.metadata {
+		metadata[key] = value
+	}
+	return metadata
+}
+
 // CreatedAt returns when the inventory was created
 func (i *Inventory) CreatedAt() time.Time {
 	return i.createdAt
@@ -90,34 +123,55 @@ func (i *Inventory) UpdatedAt() time.Time {
 	return i.updatedAt
 }
 
+// Version returns the version the entity was loaded with, used for optimistic concurrency
+func (i *Inventory) Version() int {
+	return i.version
+}
+
 // AvailableQuantity returns the quantity available for reservation/sale
 func (i *Inventory) AvailableQuantity() int {
 	return i.quantity - i.reservedQuantity
 }
 
 // Reserve reserves a quantity of inventory
+// Any shortfall beyond the available quantity is backordered if the stock policy allows it
 func (i *Inventory) Reserve(quantity int) error {
 	if quantity <= 0 {
 		return ErrInvalidQuantity
 	}
-	if i.AvailableQuantity() < quantity {
-		return ErrInsufficientStock
+
+	fromStock := quantity
+	if available := i.AvailableQuantity(); available < quantity {
+		fromStock = available
+	}
+	shortfall := quantity - fromStock
+
+	if shortfall > 0 {
+		if i.stockPolicy.Type() == StockPolicyStrict {
+			return ErrInsufficientStock
+		}
+		if !i.stockPolicy.AllowsBackorder(i.backorderedQuantity + shortfall) {
+			return ErrBackorderLimitExceeded
+		}
 	}
-	i.reservedQuantity += quantity
-	i.updatedAt = time.Now()
+
+	wasAvailable := i.AvailableQuantity()
+	i.raise(StockReserved{ProductID: i.productID, Quantity: quantity, Backordered: shortfall, At: time.Now()})
+	i.recordDepletion(wasAvailable)
 	return nil
 }
 
 // Release releases a reserved quantity back to available stock
+// Backordered quantities are cancelled first since they were never taken from stock
 func (i *Inventory) Release(quantity int) error {
 	if quantity <= 0 {
 		return ErrInvalidQuantity
 	}
-	if i.reservedQuantity < quantity {
+	if i.reservedQuantity+i.backorderedQuantity < quantity {
 		return errors.New(errors.CodeInvalidQuantity, "cannot release more than reserved quantity")
 	}
-	i.reservedQuantity -= quantity
-	i.updatedAt = time.Now()
+
+	i.raise(StockReleased{ProductID: i.productID, Quantity: quantity, At: time.Now()})
 	return nil
 }
 
@@ -131,14 +185,117 @@ func (i *Inventory) AdjustQuantity(adjustment int) error {
 	if newQuantity < i.reservedQuantity {
 		return errors.New(errors.CodeInvalidAdjustment, "cannot adjust quantity below reserved amount")
 	}
-	i.quantity = newQuantity
-	i.updatedAt = time.Now()
+	if adjustment == 0 {
+		i.allocateBackorders()
+		i.updatedAt = time.Now()
+		return nil
+	}
+	wasAvailable := i.AvailableQuantity()
+	i.raise(StockAdjusted{ProductID: i.productID, Adjustment: adjustment, Quantity: newQuantity, At: time.Now()})
+	i.recordDepletion(wasAvailable)
 	return nil
 }
 
+// SetStockPolicy changes the oversell policy
+// The new policy must still permit the quantity currently on backorder
+func (i *Inventory) SetStockPolicy(policy StockPolicy) error {
+	if !policy.AllowsBackorder(i.backorderedQuantity) {
+		return errors.Newf(errors.CodeInvalidStockPolicy, "policy does not allow the %d units currently on backorder", i.backorderedQuantity)
+	}
+	i.raise(StockPolicyChanged{ProductID: i.productID, Policy: policy.Type(), BackorderLimit: policy.BackorderLimit(), At: time.Now()})
+	return nil
+}
+
+// recordDepletion raises StockDepleted when a change took the last available stock
+func (i *Inventory) recordDepletion(wasAvailable int) {
+	if wasAvailable > 0 && i.AvailableQuantity() <= 0 {
+		i.raise(StockDepleted{ProductID: i.productID, At: i.updatedAt})
+	}
+}
+
+// PendingEvents returns the events raised since the inventory was created or loaded, leaving them pending
+// Repositories store them in the outbox together with the changes that raised them
+func (i *Inventory) PendingEvents() []event.Event {
+	return i.events.Pending()
+}
+
+// PullEvents returns the events raised since the inventory was created or loaded, and clears them
+// Callers dispatch them once the changes that raised them are stored
+func (i *Inventory) PullEvents() []event.Event {
+	return i.events.Pull()
+}
+
+// allocateBackorders converts backorders into reservations as stock becomes available
+func (i *Inventory) allocateBackorders() {
+	allocation := i.AvailableQuantity()
+	if i.backorderedQuantity < allocation {
+		allocation = i.backorderedQuantity
+	}
+	if allocation <= 0 {
+		return
+	}
+	i.reservedQuantity += allocation
+	i.backorderedQuantity -= allocation
+}
+
 // UpdateLocation updates the storage location
 func (i *Inventory) UpdateLocation(location string) {
-	i.location = location
-	i.updatedAt = time.Now()
+	i.raise(InventoryRelocated{ProductID: i.productID, From: i.location, Location: location, At: time.Now()})
 }
 
+// UpdateMetadata merges changes into the metadata; an empty value removes the key
+func (i *Inventory) UpdateMetadata(changes map[string]string) error {
+	for key, value := range changes {
+		if key == "" || len(key) > MaxMetadataKeyLength {
+			return errors.Newf(errors.CodeInvalidInput, "metadata keys must be 1-%d characters", MaxMetadataKeyLength)
+		}
+		if len(value) > MaxMetadataValueLength {
+			return errors.Newf(errors.CodeInvalidInput, "metadata value for %q exceeds %d characters", key, MaxMetadataValueLength)
+		}
+	}
+
+	if len(mergeMetadata(i.metadata, changes)) > MaxMetadataEntries {
+		return errors.Newf(errors.CodeInvalidInput, "inventory cannot have more than %d metadata entries", MaxMetadataEntries)
+	}
+
+	recorded := make(map[string]string, len(changes))
+	for key, value := range changes {
+		recorded[key] = value
+	}
+	i.raise(MetadataUpdated{ProductID: i.productID, Changes: recorded, At: time.Now()})
+	return nil
+}
+
+// mergeMetadata returns a copy of metadata with changes applied; an empty value removes the key
+func mergeMetadata(metadata, changes map[string]string) map[string]string {
+	merged := make(map[string]string, len(metadata)+len(changes))
+	for key, value := range metadata {
+		merged[key] = value
+	}
+	for key, value := range changes {
+		if value == "" {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+// EnsureRemovable checks that no stock is promised to anyone before the record is deleted
+func (i *Inventory) EnsureRemovable() error {
+	if i.reservedQuantity != 0 || i.backorderedQuantity != 0 {
+		return ErrInventoryReserved
+	}
+	return nil
+}
+
+// Remove marks the inventory as deleted, ending its event stream
+// The repository's Delete removes the record; it is refused while stock is reserved or backordered.
+func (i *Inventory) Remove() error {
+	if err := i.EnsureRemovable(); err != nil {
+		return err
+	}
+	i.raise(InventoryDeleted{ProductID: i.productID, At: time.Now()})
+	return nil
+}
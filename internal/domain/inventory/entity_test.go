@@ -0,0 +1,228 @@
+package inventory_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+func TestInventory_UpdateMetadata(t *testing.T) {
+	now := time.Now()
+	inv := inventory.ReconstructInventory("inv-1", "product-1", 10, 0, 0, inventory.StrictStockPolicy(), "Warehouse A",
+		map[string]string{"bin": "A-01", "supplier": "acme"}, now, now, 1)
+
+	if err := inv.UpdateMetadata(map[string]string{"bin": "B-07", "supplier": "", "shelf": "3"}); err != nil {
+		t.Fatalf("UpdateMetadata() unexpected error: %v", err)
+	}
+
+	got := inv.Metadata()
+	if len(got) != 2 || got["bin"] != "B-07" || got["shelf"] != "3" {
+		t.Errorf("Metadata() = %v, want bin=B-07 and shelf=3", got)
+	}
+
+	// The returned map is a copy
+	got["bin"] = "changed"
+	if inv.Metadata()["bin"] != "B-07" {
+		t.Error("Metadata() exposed internal state")
+	}
+}
+
+func TestInventory_UpdateMetadata_Invalid(t *testing.T) {
+	tooMany := make(map[string]string)
+	for i := 0; i <= inventory.MaxMetadataEntries; i++ {
+		tooMany[fmt.Sprintf("key-%d", i)] = "v"
+	}
+
+	tests := []struct {
+		name    string
+		changes map[string]string
+	}{
+		{name: "empty key", changes: map[string]string{"": "v"}},
+		{name: "key too long", changes: map[string]string{strings.Repeat("k", inventory.MaxMetadataKeyLength+1): "v"}},
+		{name: "value too long", changes: map[string]string{"k": strings.Repeat("v", inventory.MaxMetadataValueLength+1)}},
+		{name: "too many entries", changes: tooMany},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv, _ := inventory.NewInventory("inv-1", "product-1", 10, "Warehouse A")
+			err := inv.UpdateMetadata(tt.changes)
+			if !errors.Is(err, errors.CodeInvalidInput) {
+				t.Errorf("UpdateMetadata() error code = %s, want %s", errors.GetCode(err), errors.CodeInvalidInput)
+			}
+			if len(inv.Metadata()) != 0 {
+				t.Errorf("Metadata() = %v, want unchanged", inv.Metadata())
+			}
+		})
+	}
+}
+
+func TestInventory_EnsureRemovable(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name        string
+		reserved    int
+		backordered int
+		wantErr     bool
+	}{
+		{name: "no reservations", wantErr: false},
+		{name: "reserved stock", reserved: 2, wantErr: true},
+		{name: "backordered stock", backordered: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, _ := inventory.NewStockPolicy(inventory.StockPolicyUnlimited, 0)
+			inv := inventory.ReconstructInventory("inv-1", "product-1", 10, tt.reserved, tt.backordered,
+				policy, "Warehouse A", nil, now, now, 1)
+			err := inv.EnsureRemovable()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EnsureRemovable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, errors.CodeInventoryReserved) {
+				t.Errorf("EnsureRemovable() error code = %s, want %s", errors.GetCode(err), errors.CodeInventoryReserved)
+			}
+		})
+	}
+}
+
+func TestInventory_PullEvents(t *testing.T) {
+	inv, _ := inventory.NewInventory("inv-1", "product-1", 10, "Warehouse A")
+	if events := inv.PullEvents(); len(events) != 1 || events[0].EventName() != inventory.EventInventoryCreated {
+		t.Fatalf("PullEvents() of a new inventory = %v, want one %s", events, inventory.EventInventoryCreated)
+	}
+
+	if err := inv.Reserve(4); err != nil {
+		t.Fatalf("Reserve() unexpected error: %v", err)
+	}
+	if err := inv.AdjustQuantity(-6); err != nil {
+		t.Fatalf("AdjustQuantity() unexpected error: %v", err)
+	}
+	if err := inv.Release(1); err != nil {
+		t.Fatalf("Release() unexpected error: %v", err)
+	}
+
+	want := []string{
+		inventory.EventStockReserved,
+		inventory.EventStockAdjusted,
+		inventory.EventStockDepleted,
+		inventory.EventStockReleased,
+	}
+	events := inv.PullEvents()
+	if len(events) != len(want) {
+		t.Fatalf("PullEvents() = %v, want %v", events, want)
+	}
+	for i, e := range events {
+		if e.EventName() != want[i] || e.AggregateID() != "product-1" {
+			t.Errorf("PullEvents()[%d] = %s of %q, want %s of product-1", i, e.EventName(), e.AggregateID(), want[i])
+		}
+	}
+	if adjusted := events[1].(inventory.StockAdjusted); adjusted.Adjustment != -6 || adjusted.Quantity != 4 {
+		t.Errorf("StockAdjusted = %+v, want adjustment -6 to quantity 4", adjusted)
+	}
+	if len(inv.PullEvents()) != 0 {
+		t.Error("PullEvents() should clear the pulled events")
+	}
+
+	// Failed operations raise nothing
+	if err := inv.AdjustQuantity(-10); err == nil {
+		t.Fatal("AdjustQuantity() below the reserved quantity should fail")
+	}
+	if events := inv.PullEvents(); len(events) != 0 {
+		t.Errorf("PullEvents() after a failed adjustment = %v, want none", events)
+	}
+}
+
+func TestRehydrateInventory(t *testing.T) {
+	inv, _ := inventory.NewInventory("inv-1", "product-1", 10, "Warehouse A")
+	policy, _ := inventory.NewStockPolicy(inventory.StockPolicyBackorder, 5)
+	if err := inv.SetStockPolicy(policy); err != nil {
+		t.Fatalf("SetStockPolicy() unexpected error: %v", err)
+	}
+	if err := inv.Reserve(12); err != nil {
+		t.Fatalf("Reserve() unexpected error: %v", err)
+	}
+	if err := inv.AdjustQuantity(1); err != nil {
+		t.Fatalf("AdjustQuantity() unexpected error: %v", err)
+	}
+	if err := inv.Release(2); err != nil {
+		t.Fatalf("Release() unexpected error: %v", err)
+	}
+	inv.UpdateLocation("Warehouse B")
+	if err := inv.UpdateMetadata(map[string]string{"bin": "B-07"}); err != nil {
+		t.Fatalf("UpdateMetadata() unexpected error: %v", err)
+	}
+	history := inv.PullEvents()
+
+	replayed, err := inventory.RehydrateInventory(nil, history)
+	if err != nil {
+		t.Fatalf("RehydrateInventory() unexpected error: %v", err)
+	}
+	want := inv.Snapshot()
+	want.Version = len(history)
+	if got := replayed.Snapshot(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("RehydrateInventory() = %+v, want %+v", got, want)
+	}
+
+	// Replaying the rest of the stream on a snapshot gives the same inventory
+	fromSnapshot, _ := inventory.RehydrateInventory(nil, history[:3])
+	snapshot := fromSnapshot.Snapshot()
+	replayed, err = inventory.RehydrateInventory(&snapshot, history[3:])
+	if err != nil {
+		t.Fatalf("RehydrateInventory() from a snapshot unexpected error: %v", err)
+	}
+	if got := replayed.Snapshot(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("RehydrateInventory() from a snapshot = %+v, want %+v", got, want)
+	}
+	if len(replayed.PendingEvents()) != 0 {
+		t.Error("RehydrateInventory() should not raise the replayed events again")
+	}
+
+	if _, err := inventory.RehydrateInventory(nil, history[1:]); err == nil {
+		t.Error("RehydrateInventory() of a stream without its creation should fail")
+	}
+}
+
+func TestInventory_Remove(t *testing.T) {
+	inv, _ := inventory.NewInventory("inv-1", "product-1", 10, "Warehouse A")
+	if err := inv.Reserve(2); err != nil {
+		t.Fatalf("Reserve() unexpected error: %v", err)
+	}
+	if err := inv.Remove(); !errors.Is(err, errors.CodeInventoryReserved) {
+		t.Fatalf("Remove() with reserved stock error = %v, want %s", err, errors.CodeInventoryReserved)
+	}
+
+	if err := inv.Release(2); err != nil {
+		t.Fatalf("Release() unexpected error: %v", err)
+	}
+	if err := inv.Remove(); err != nil {
+		t.Fatalf("Remove() unexpected error: %v", err)
+	}
+	events := inv.PullEvents()
+	if last := events[len(events)-1]; last.EventName() != inventory.EventInventoryDeleted {
+		t.Errorf("Remove() raised %s, want %s", last.EventName(), inventory.EventInventoryDeleted)
+	}
+}
+
+func TestRehydrateInventory_AfterDeletion(t *testing.T) {
+	deleted, _ := inventory.NewInventory("inv-1", "product-1", 10, "Warehouse A")
+	_ = deleted.UpdateMetadata(map[string]string{"bin": "B-07"})
+	_ = deleted.Remove()
+	recreated, _ := inventory.NewInventory("inv-2", "product-1", 3, "Warehouse B")
+	history := append(deleted.PullEvents(), recreated.PullEvents()...)
+
+	// The new inventory's creation replaces all of the deleted one's state, and the version continues
+	replayed, err := inventory.RehydrateInventory(nil, history)
+	if err != nil {
+		t.Fatalf("RehydrateInventory() unexpected error: %v", err)
+	}
+	want := recreated.Snapshot()
+	want.Version = len(history)
+	if got := replayed.Snapshot(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("RehydrateInventory() = %+v, want %+v", got, want)
+	}
+}
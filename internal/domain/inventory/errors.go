@@ -4,9 +4,14 @@ import "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
 
 // Domain errors - using pkg/errors for consistency
 var (
-	ErrInventoryNotFound = errors.New(errors.CodeInventoryNotFound, "inventory not found")
-	ErrInventoryExists   = errors.New(errors.CodeInventoryExists, "inventory already exists for this product")
-	ErrInsufficientStock = errors.New(errors.CodeInsufficientStock, "insufficient stock available")
-	ErrInvalidQuantity   = errors.New(errors.CodeInvalidQuantity, "quantity must be non-negative")
-	ErrInvalidAdjustment = errors.New(errors.CodeInvalidAdjustment, "invalid adjustment amount")
+	ErrInventoryNotFound      = errors.New(errors.CodeInventoryNotFound, "inventory not found")
+	ErrInventoryExists        = errors.New(errors.CodeInventoryExists, "inventory already exists for this product")
+	ErrInsufficientStock      = errors.New(errors.CodeInsufficientStock, "insufficient stock available")
+	ErrInvalidQuantity        = errors.New(errors.CodeInvalidQuantity, "quantity must be non-negative")
+	ErrInvalidAdjustment      = errors.New(errors.CodeInvalidAdjustment, "invalid adjustment amount")
+	ErrBackorderLimitExceeded = errors.New(errors.CodeInsufficientStock, "insufficient stock and backorder limit exceeded")
+	ErrStocktakeNotFound      = errors.New(errors.CodeStocktakeNotFound, "stocktake not found")
+	ErrStocktakeNotOpen       = errors.New(errors.CodeStocktakeNotOpen, "stocktake is no longer open")
+	ErrInventoryReserved      = errors.New(errors.CodeInventoryReserved, "inventory has reserved or backordered stock and cannot be removed")
+	ErrConcurrentModification = errors.New(errors.CodeConcurrencyConflict, "inventory was modified concurrently, reload and retry")
 )
@@ -0,0 +1,140 @@
+package inventory
+
+import (
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/event"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// Snapshot is the state of an inventory at a version of its event stream
+// Replaying the events after Version on top of it rebuilds the current inventory.
+type Snapshot struct {
+	ID                  string            `json:"id"`
+	ProductID           string            `json:"product_id"`
+	Quantity            int               `json:"quantity"`
+	ReservedQuantity    int               `json:"reserved_quantity"`
+	BackorderedQuantity int               `json:"backordered_quantity"`
+	StockPolicy         StockPolicyType   `json:"stock_policy"`
+	BackorderLimit      int               `json:"backorder_limit"`
+	Location            string            `json:"location"`
+	Metadata            map[string]string `json:"metadata"`
+	CreatedAt           time.Time         `json:"created_at"`
+	UpdatedAt           time.Time         `json:"updated_at"`
+	Version             int               `json:"version"`
+}
+
+// Snapshot captures the current state of the inventory, including changes not yet stored
+// Version is the version the entity was loaded with; the caller sets the stream version it belongs to.
+func (i *Inventory) Snapshot() Snapshot {
+	return Snapshot{
+		ID:                  i.id,
+		ProductID:           i.productID,
+		Quantity:            i.quantity,
+		ReservedQuantity:    i.reservedQuantity,
+		BackorderedQuantity: i.backorderedQuantity,
+		StockPolicy:         i.stockPolicy.Type(),
+		BackorderLimit:      i.stockPolicy.BackorderLimit(),
+		Location:            i.location,
+		Metadata:            i.Metadata(),
+		CreatedAt:           i.createdAt,
+		UpdatedAt:           i.updatedAt,
+		Version:             i.version,
+	}
+}
+
+// RehydrateInventory rebuilds an inventory by replaying the events of its stream
+// Without a snapshot the history must start with InventoryCreated; with one, history holds
+// the events recorded after the snapshot. Every replayed event advances the version by one.
+// A stream goes on after InventoryDeleted when the product gets a new inventory; its
+// InventoryCreated replaces the whole state, so the deleted inventory leaves nothing behind.
+func RehydrateInventory(snapshot *Snapshot, history []event.Event) (*Inventory, error) {
+	inv := &Inventory{}
+	if snapshot != nil {
+		inv = ReconstructInventory(
+			snapshot.ID,
+			snapshot.ProductID,
+			snapshot.Quantity,
+			snapshot.ReservedQuantity,
+			snapshot.BackorderedQuantity,
+			stockPolicyOf(snapshot.StockPolicy, snapshot.BackorderLimit),
+			snapshot.Location,
+			snapshot.Metadata,
+			snapshot.CreatedAt,
+			snapshot.UpdatedAt,
+			snapshot.Version,
+		)
+	} else if len(history) == 0 || history[0].EventName() != EventInventoryCreated {
+		return nil, errors.New(errors.CodeInternalError, "inventory event stream does not start with "+EventInventoryCreated)
+	}
+
+	for _, e := range history {
+		if err := inv.apply(e); err != nil {
+			return nil, err
+		}
+		inv.version++
+	}
+	return inv, nil
+}
+
+// raise applies a new event to the inventory and records it for the repository and dispatchers
+func (i *Inventory) raise(e event.Event) {
+	// Events raised by the inventory itself are always known
+	_ = i.apply(e)
+	i.events.Record(e)
+}
+
+// apply changes the state as the event describes, without checking business rules
+// It is the only place state changes, so replaying a stream gives the state the live changes gave.
+func (i *Inventory) apply(e event.Event) error {
+	switch e := e.(type) {
+	case InventoryCreated:
+		i.id = e.ID
+		i.productID = e.ProductID
+		i.quantity = e.Quantity
+		i.reservedQuantity = 0
+		i.backorderedQuantity = 0
+		i.stockPolicy = StrictStockPolicy()
+		i.location = e.Location
+		i.metadata = map[string]string{}
+		i.createdAt = e.At
+	case InventoryRelocated:
+		i.location = e.Location
+	case StockAdjusted:
+		i.quantity = e.Quantity
+		i.allocateBackorders()
+	case StockReserved:
+		i.reservedQuantity += e.Quantity - e.Backordered
+		i.backorderedQuantity += e.Backordered
+	case StockReleased:
+		// Backordered quantities are cancelled first since they were never taken from stock
+		fromBackorder := e.Quantity
+		if i.backorderedQuantity < fromBackorder {
+			fromBackorder = i.backorderedQuantity
+		}
+		i.backorderedQuantity -= fromBackorder
+		i.reservedQuantity -= e.Quantity - fromBackorder
+	case StockDepleted:
+		// Depletion follows from the change before it
+	case StockPolicyChanged:
+		i.stockPolicy = stockPolicyOf(e.Policy, e.BackorderLimit)
+	case MetadataUpdated:
+		i.metadata = mergeMetadata(i.metadata, e.Changes)
+	case InventoryDeleted:
+		// The record is gone; nothing follows until the product gets a new inventory
+	default:
+		return errors.Newf(errors.CodeInternalError, "cannot apply event %q to inventory", e.EventName())
+	}
+	i.updatedAt = e.OccurredAt()
+	return nil
+}
+
+// stockPolicyOf rebuilds a stored stock policy, falling back to the strict policy
+// when it fails validation so stock is never oversold
+func stockPolicyOf(policyType StockPolicyType, backorderLimit int) StockPolicy {
+	policy, err := NewStockPolicy(policyType, backorderLimit)
+	if err != nil {
+		return StrictStockPolicy()
+	}
+	return policy
+}
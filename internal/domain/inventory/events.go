@@ -0,0 +1,214 @@
+package inventory
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/event"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// AggregateType names inventory in the outbox and in published events
+// Inventory events are identified by the ID of the product the inventory is for.
+const AggregateType = "inventory"
+
+// Event names of the inventory aggregate
+const (
+	EventInventoryCreated   = "inventory.created"
+	EventInventoryRelocated = "inventory.relocated"
+	EventStockAdjusted      = "inventory.stock_adjusted"
+	EventStockReserved      = "inventory.stock_reserved"
+	EventStockReleased      = "inventory.stock_released"
+	EventStockDepleted      = "inventory.stock_depleted"
+	EventStockPolicyChanged = "inventory.stock_policy_changed"
+	EventMetadataUpdated    = "inventory.metadata_updated"
+	EventInventoryDeleted   = "inventory.deleted"
+)
+
+// InventoryCreated is raised when an inventory record is created
+// It starts the event stream of the inventory, with the strict stock policy and no metadata.
+type InventoryCreated struct {
+	ID        string    `json:"id"`
+	ProductID string    `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+	Location  string    `json:"location"`
+	At        time.Time `json:"occurred_at"`
+}
+
+// EventName identifies the kind of event
+func (e InventoryCreated) EventName() string { return EventInventoryCreated }
+
+// AggregateID returns the ID of the product the inventory is for
+func (e InventoryCreated) AggregateID() string { return e.ProductID }
+
+// OccurredAt returns when the inventory was created
+func (e InventoryCreated) OccurredAt() time.Time { return e.At }
+
+// InventoryRelocated is raised when the stock moves to another storage location
+type InventoryRelocated struct {
+	ProductID string    `json:"product_id"`
+	From      string    `json:"from"`
+	Location  string    `json:"location"`
+	At        time.Time `json:"occurred_at"`
+}
+
+// EventName identifies the kind of event
+func (e InventoryRelocated) EventName() string { return EventInventoryRelocated }
+
+// AggregateID returns the ID of the product the inventory is for
+func (e InventoryRelocated) AggregateID() string { return e.ProductID }
+
+// OccurredAt returns when the stock was relocated
+func (e InventoryRelocated) OccurredAt() time.Time { return e.At }
+
+// StockAdjusted is raised when the quantity on hand changes
+// Quantity is the quantity on hand after the adjustment.
+type StockAdjusted struct {
+	ProductID  string    `json:"product_id"`
+	Adjustment int       `json:"adjustment"`
+	Quantity   int       `json:"quantity"`
+	At         time.Time `json:"occurred_at"`
+}
+
+// EventName identifies the kind of event
+func (e StockAdjusted) EventName() string { return EventStockAdjusted }
+
+// AggregateID returns the ID of the product the inventory is for
+func (e StockAdjusted) AggregateID() string { return e.ProductID }
+
+// OccurredAt returns when the stock was adjusted
+func (e StockAdjusted) OccurredAt() time.Time { return e.At }
+
+// StockReserved is raised when stock is reserved
+// Backordered is the part of Quantity that could not be taken from stock.
+type StockReserved struct {
+	ProductID   string    `json:"product_id"`
+	Quantity    int       `json:"quantity"`
+	Backordered int       `json:"backordered"`
+	At          time.Time `json:"occurred_at"`
+}
+
+// EventName identifies the kind of event
+func (e StockReserved) EventName() string { return EventStockReserved }
+
+// AggregateID returns the ID of the product the inventory is for
+func (e StockReserved) AggregateID() string { return e.ProductID }
+
+// OccurredAt returns when the stock was reserved
+func (e StockReserved) OccurredAt() time.Time { return e.At }
+
+// StockReleased is raised when reserved or backordered stock is released
+type StockReleased struct {
+	ProductID string    `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+	At        time.Time `json:"occurred_at"`
+}
+
+// EventName identifies the kind of event
+func (e StockReleased) EventName() string { return EventStockReleased }
+
+// AggregateID returns the ID of the product the inventory is for
+func (e StockReleased) AggregateID() string { return e.ProductID }
+
+// OccurredAt returns when the stock was released
+func (e StockReleased) OccurredAt() time.Time { return e.At }
+
+// StockDepleted is raised when a change leaves no stock available to reserve
+// It follows the StockAdjusted or StockReserved event of that change.
+type StockDepleted struct {
+	ProductID string    `json:"product_id"`
+	At        time.Time `json:"occurred_at"`
+}
+
+// EventName identifies the kind of event
+func (e StockDepleted) EventName() string { return EventStockDepleted }
+
+// AggregateID returns the ID of the product the inventory is for
+func (e StockDepleted) AggregateID() string { return e.ProductID }
+
+// OccurredAt returns when the stock ran out
+func (e StockDepleted) OccurredAt() time.Time { return e.At }
+
+// StockPolicyChanged is raised when the oversell policy changes
+type StockPolicyChanged struct {
+	ProductID      string          `json:"product_id"`
+	Policy         StockPolicyType `json:"policy"`
+	BackorderLimit int             `json:"backorder_limit"`
+	At             time.Time       `json:"occurred_at"`
+}
+
+// EventName identifies the kind of event
+func (e StockPolicyChanged) EventName() string { return EventStockPolicyChanged }
+
+// AggregateID returns the ID of the product the inventory is for
+func (e StockPolicyChanged) AggregateID() string { return e.ProductID }
+
+// OccurredAt returns when the policy changed
+func (e StockPolicyChanged) OccurredAt() time.Time { return e.At }
+
+// MetadataUpdated is raised when metadata changes
+// Changes holds the merged entries; an empty value removed the key.
+type MetadataUpdated struct {
+	ProductID string            `json:"product_id"`
+	Changes   map[string]string `json:"changes"`
+	At        time.Time         `json:"occurred_at"`
+}
+
+// EventName identifies the kind of event
+func (e MetadataUpdated) EventName() string { return EventMetadataUpdated }
+
+// AggregateID returns the ID of the product the inventory is for
+func (e MetadataUpdated) AggregateID() string { return e.ProductID }
+
+// OccurredAt returns when the metadata changed
+func (e MetadataUpdated) OccurredAt() time.Time { return e.At }
+
+// InventoryDeleted is raised when an inventory record is deleted
+// It ends the inventory's life in its stream; a later InventoryCreated for the product continues the stream.
+type InventoryDeleted struct {
+	ProductID string    `json:"product_id"`
+	At        time.Time `json:"occurred_at"`
+}
+
+// EventName identifies the kind of event
+func (e InventoryDeleted) EventName() string { return EventInventoryDeleted }
+
+// AggregateID returns the ID of the product the inventory was for
+func (e InventoryDeleted) AggregateID() string { return e.ProductID }
+
+// OccurredAt returns when the inventory was deleted
+func (e InventoryDeleted) OccurredAt() time.Time { return e.At }
+
+// DecodeEvent rebuilds an inventory event from its name and JSON payload, as stored in an event stream
+func DecodeEvent(name string, payload []byte) (event.Event, error) {
+	switch name {
+	case EventInventoryCreated:
+		return decodeEvent[InventoryCreated](name, payload)
+	case EventInventoryRelocated:
+		return decodeEvent[InventoryRelocated](name, payload)
+	case EventStockAdjusted:
+		return decodeEvent[StockAdjusted](name, payload)
+	case EventStockReserved:
+		return decodeEvent[StockReserved](name, payload)
+	case EventStockReleased:
+		return decodeEvent[StockReleased](name, payload)
+	case EventStockDepleted:
+		return decodeEvent[StockDepleted](name, payload)
+	case EventStockPolicyChanged:
+		return decodeEvent[StockPolicyChanged](name, payload)
+	case EventMetadataUpdated:
+		return decodeEvent[MetadataUpdated](name, payload)
+	case EventInventoryDeleted:
+		return decodeEvent[InventoryDeleted](name, payload)
+	}
+	return nil, errors.Newf(errors.CodeInternalError, "unknown inventory event %q", name)
+}
+
+// decodeEvent unmarshals the payload of an event of type E
+func decodeEvent[E event.Event](name string, payload []byte) (event.Event, error) {
+	var e E
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternalError, "failed to decode inventory event "+name)
+	}
+	return e, nil
+}
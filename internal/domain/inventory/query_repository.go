@@ -1,6 +1,34 @@
 package inventory
 
-import "context"
+import (
+	"context"
+	"time"
+)
+
+// InventorySortField is a field inventory lists can be ordered by
+type InventorySortField string
+
+const (
+	// SortByProductID orders by product ID (the default)
+	SortByProductID InventorySortField = "product_id"
+	// SortByQuantity orders by quantity on hand
+	SortByQuantity InventorySortField = "quantity"
+	// SortByUpdatedAt orders by last update time
+	SortByUpdatedAt InventorySortField = "updated_at"
+)
+
+// InventoryFilter describes which inventory records to list and in which order
+// Zero values disable a filter
+type InventoryFilter struct {
+	Location       string
+	ZeroStockOnly  bool
+	AvailableBelow *int
+	UpdatedSince   time.Time
+	SortBy         InventorySortField
+	SortDesc       bool
+	Limit          int
+	Offset         int
+}
 
 // InventoryQueryRepository defines the interface for inventory read operations
 // This interface belongs to the domain layer and has no infrastructure dependencies
@@ -8,5 +36,17 @@ type InventoryQueryRepository interface {
 	// GetByProductID retrieves inventory by product ID
 	// Returns nil if inventory is not found
 	GetByProductID(ctx context.Context, productID string) (*Inventory, error)
-}
 
+	// GetByProductIDs retrieves the inventory records for several products at once
+	// Products without inventory are omitted from the result
+	GetByProductIDs(ctx context.Context, productIDs []string) ([]*Inventory, error)
+
+	// ListByLocation retrieves all inventory records stored at a location
+	ListByLocation(ctx context.Context, location string) ([]*Inventory, error)
+
+	// List retrieves a page of inventory records matching the filter
+	List(ctx context.Context, filter InventoryFilter) ([]*Inventory, error)
+
+	// Count returns how many inventory records match the filter, ignoring paging and sorting
+	Count(ctx context.Context, filter InventoryFilter) (int, error)
+}
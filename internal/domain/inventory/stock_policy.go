@@ -0,0 +1,79 @@
+package inventory
+
+import (
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// StockPolicyType determines how a product behaves when demand exceeds stock on hand
+type StockPolicyType string
+
+const (
+	// StockPolicyStrict rejects reservations beyond the available quantity
+	StockPolicyStrict StockPolicyType = "strict"
+	// StockPolicyBackorder accepts backorders up to a fixed limit
+	StockPolicyBackorder StockPolicyType = "backorder"
+	// StockPolicyUnlimited accepts any amount of backorders
+	StockPolicyUnlimited StockPolicyType = "unlimited"
+)
+
+// StockPolicy is a value object describing the oversell rules of a product
+type StockPolicy struct {
+	policyType     StockPolicyType
+	backorderLimit int
+}
+
+// NewStockPolicy creates a new StockPolicy value object with validation
+// An empty policy type defaults to strict
+func NewStockPolicy(policyType StockPolicyType, backorderLimit int) (StockPolicy, error) {
+	if policyType == "" {
+		policyType = StockPolicyStrict
+	}
+
+	switch policyType {
+	case StockPolicyStrict, StockPolicyUnlimited:
+		if backorderLimit != 0 {
+			return StockPolicy{}, errors.Newf(errors.CodeInvalidStockPolicy, "backorder limit only applies to the %s policy", StockPolicyBackorder)
+		}
+	case StockPolicyBackorder:
+		if backorderLimit <= 0 {
+			return StockPolicy{}, errors.New(errors.CodeInvalidStockPolicy, "backorder limit must be positive")
+		}
+	default:
+		return StockPolicy{}, errors.Newf(errors.CodeInvalidStockPolicy, "unknown stock policy %q", policyType)
+	}
+
+	return StockPolicy{
+		policyType:     policyType,
+		backorderLimit: backorderLimit,
+	}, nil
+}
+
+// StrictStockPolicy returns the default policy that never oversells
+func StrictStockPolicy() StockPolicy {
+	return StockPolicy{policyType: StockPolicyStrict}
+}
+
+// Type returns the policy type
+func (p StockPolicy) Type() StockPolicyType {
+	if p.policyType == "" {
+		return StockPolicyStrict
+	}
+	return p.policyType
+}
+
+// BackorderLimit returns the maximum backordered quantity (only meaningful for the backorder policy)
+func (p StockPolicy) BackorderLimit() int {
+	return p.backorderLimit
+}
+
+// AllowsBackorder checks whether the given total backordered quantity is permitted
+func (p StockPolicy) AllowsBackorder(backorderedQuantity int) bool {
+	switch p.Type() {
+	case StockPolicyUnlimited:
+		return true
+	case StockPolicyBackorder:
+		return backorderedQuantity <= p.backorderLimit
+	default:
+		return backorderedQuantity == 0
+	}
+}
@@ -0,0 +1,159 @@
+package inventory_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+func TestNewStockPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		policyType inventory.StockPolicyType
+		limit      int
+		wantType   inventory.StockPolicyType
+		wantErr    bool
+	}{
+		{name: "empty defaults to strict", policyType: "", wantType: inventory.StockPolicyStrict},
+		{name: "strict", policyType: inventory.StockPolicyStrict, wantType: inventory.StockPolicyStrict},
+		{name: "backorder with limit", policyType: inventory.StockPolicyBackorder, limit: 20, wantType: inventory.StockPolicyBackorder},
+		{name: "unlimited", policyType: inventory.StockPolicyUnlimited, wantType: inventory.StockPolicyUnlimited},
+		{name: "backorder without limit", policyType: inventory.StockPolicyBackorder, limit: 0, wantErr: true},
+		{name: "strict with limit", policyType: inventory.StockPolicyStrict, limit: 5, wantErr: true},
+		{name: "unknown policy", policyType: "preorder", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := inventory.NewStockPolicy(tt.policyType, tt.limit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewStockPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, errors.CodeInvalidStockPolicy) {
+					t.Errorf("NewStockPolicy() error code = %s, want %s", errors.GetCode(err), errors.CodeInvalidStockPolicy)
+				}
+				return
+			}
+			if got.Type() != tt.wantType {
+				t.Errorf("NewStockPolicy() Type() = %v, want %v", got.Type(), tt.wantType)
+			}
+		})
+	}
+}
+
+func newInventoryWithPolicy(t *testing.T, quantity, reserved, backordered int, policyType inventory.StockPolicyType, limit int) *inventory.Inventory {
+	t.Helper()
+	policy, err := inventory.NewStockPolicy(policyType, limit)
+	if err != nil {
+		t.Fatalf("NewStockPolicy() unexpected error = %v", err)
+	}
+	now := time.Now()
+	return inventory.ReconstructInventory("inv-1", "product-1", quantity, reserved, backordered, policy, "Warehouse A", nil, now, now, 1)
+}
+
+func TestInventory_Reserve_StockPolicy(t *testing.T) {
+	tests := []struct {
+		name            string
+		inv             *inventory.Inventory
+		quantity        int
+		wantErrCode     errors.ErrorCode
+		wantReserved    int
+		wantBackordered int
+	}{
+		{
+			name:         "strict within stock",
+			inv:          newInventoryWithPolicy(t, 10, 0, 0, inventory.StockPolicyStrict, 0),
+			quantity:     10,
+			wantReserved: 10,
+		},
+		{
+			name:        "strict beyond stock",
+			inv:         newInventoryWithPolicy(t, 10, 0, 0, inventory.StockPolicyStrict, 0),
+			quantity:    11,
+			wantErrCode: errors.CodeInsufficientStock,
+		},
+		{
+			name:            "backorder within limit",
+			inv:             newInventoryWithPolicy(t, 10, 4, 0, inventory.StockPolicyBackorder, 5),
+			quantity:        11,
+			wantReserved:    10,
+			wantBackordered: 5,
+		},
+		{
+			name:        "backorder beyond limit",
+			inv:         newInventoryWithPolicy(t, 10, 4, 2, inventory.StockPolicyBackorder, 5),
+			quantity:    10,
+			wantErrCode: errors.CodeInsufficientStock,
+		},
+		{
+			name:            "unlimited",
+			inv:             newInventoryWithPolicy(t, 0, 0, 0, inventory.StockPolicyUnlimited, 0),
+			quantity:        1000,
+			wantBackordered: 1000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.inv.Reserve(tt.quantity)
+			if tt.wantErrCode != "" {
+				if !errors.Is(err, tt.wantErrCode) {
+					t.Fatalf("Reserve() error = %v, want code %s", err, tt.wantErrCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Reserve() unexpected error = %v", err)
+			}
+			if tt.inv.ReservedQuantity() != tt.wantReserved {
+				t.Errorf("ReservedQuantity() = %d, want %d", tt.inv.ReservedQuantity(), tt.wantReserved)
+			}
+			if tt.inv.BackorderedQuantity() != tt.wantBackordered {
+				t.Errorf("BackorderedQuantity() = %d, want %d", tt.inv.BackorderedQuantity(), tt.wantBackordered)
+			}
+		})
+	}
+}
+
+func TestInventory_Release_CancelsBackordersFirst(t *testing.T) {
+	inv := newInventoryWithPolicy(t, 10, 10, 5, inventory.StockPolicyBackorder, 10)
+
+	if err := inv.Release(7); err != nil {
+		t.Fatalf("Release() unexpected error = %v", err)
+	}
+	if inv.BackorderedQuantity() != 0 || inv.ReservedQuantity() != 8 {
+		t.Errorf("after Release(7) reserved=%d backordered=%d, want reserved=8 backordered=0", inv.ReservedQuantity(), inv.BackorderedQuantity())
+	}
+
+	if err := inv.Release(9); !errors.Is(err, errors.CodeInvalidQuantity) {
+		t.Errorf("Release() beyond reserved error = %v, want %s", err, errors.CodeInvalidQuantity)
+	}
+}
+
+func TestInventory_AdjustQuantity_AllocatesBackorders(t *testing.T) {
+	inv := newInventoryWithPolicy(t, 10, 10, 8, inventory.StockPolicyBackorder, 10)
+
+	if err := inv.AdjustQuantity(5); err != nil {
+		t.Fatalf("AdjustQuantity() unexpected error = %v", err)
+	}
+	if inv.ReservedQuantity() != 15 || inv.BackorderedQuantity() != 3 || inv.AvailableQuantity() != 0 {
+		t.Errorf("after AdjustQuantity(5) reserved=%d backordered=%d available=%d, want 15/3/0",
+			inv.ReservedQuantity(), inv.BackorderedQuantity(), inv.AvailableQuantity())
+	}
+}
+
+func TestInventory_SetStockPolicy_RejectsOpenBackorders(t *testing.T) {
+	inv := newInventoryWithPolicy(t, 0, 0, 4, inventory.StockPolicyUnlimited, 0)
+
+	if err := inv.SetStockPolicy(inventory.StrictStockPolicy()); !errors.Is(err, errors.CodeInvalidStockPolicy) {
+		t.Errorf("SetStockPolicy(strict) error = %v, want %s", err, errors.CodeInvalidStockPolicy)
+	}
+
+	limited, _ := inventory.NewStockPolicy(inventory.StockPolicyBackorder, 4)
+	if err := inv.SetStockPolicy(limited); err != nil {
+		t.Errorf("SetStockPolicy(backorder 4) unexpected error = %v", err)
+	}
+}
@@ -0,0 +1,290 @@
+package inventory
+
+import (
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// AdjustmentReasonCycleCount is the reason recorded for adjustments produced by a stocktake
+const AdjustmentReasonCycleCount = "cycle count"
+
+// StocktakeStatus represents the lifecycle state of a stocktake session
+type StocktakeStatus string
+
+const (
+	// StocktakeStatusOpen accepts counts and approvals
+	StocktakeStatusOpen StocktakeStatus = "open"
+	// StocktakeStatusApplied means approved variances were posted to inventory
+	StocktakeStatusApplied StocktakeStatus = "applied"
+	// StocktakeStatusCancelled means the session was abandoned without posting
+	StocktakeStatusCancelled StocktakeStatus = "cancelled"
+)
+
+// StocktakeLine is a single product counted within a stocktake session
+type StocktakeLine struct {
+	productID        string
+	location         string
+	expectedQuantity int
+	countedQuantity  int
+	counted          bool
+	countAttempts    int
+	approved         bool
+	countedAt        time.Time
+}
+
+// ReconstructStocktakeLine reconstructs a StocktakeLine from persistence
+func ReconstructStocktakeLine(productID, location string, expectedQuantity, countedQuantity int, counted bool, countAttempts int, approved bool, countedAt time.Time) *StocktakeLine {
+	return &StocktakeLine{
+		productID:        productID,
+		location:         location,
+		expectedQuantity: expectedQuantity,
+		countedQuantity:  countedQuantity,
+		counted:          counted,
+		countAttempts:    countAttempts,
+		approved:         approved,
+		countedAt:        countedAt,
+	}
+}
+
+// ProductID returns the product being counted
+func (l *StocktakeLine) ProductID() string {
+	return l.productID
+}
+
+// Location returns the location frozen when the session was opened
+func (l *StocktakeLine) Location() string {
+	return l.location
+}
+
+// ExpectedQuantity returns the quantity frozen when the session was opened
+func (l *StocktakeLine) ExpectedQuantity() int {
+	return l.expectedQuantity
+}
+
+// CountedQuantity returns the latest counted quantity (zero if not yet counted)
+func (l *StocktakeLine) CountedQuantity() int {
+	return l.countedQuantity
+}
+
+// IsCounted reports whether a count has been submitted for this line
+func (l *StocktakeLine) IsCounted() bool {
+	return l.counted
+}
+
+// CountAttempts returns how many counts were submitted (more than one means recounts)
+func (l *StocktakeLine) CountAttempts() int {
+	return l.countAttempts
+}
+
+// IsApproved reports whether the variance has been approved for posting
+func (l *StocktakeLine) IsApproved() bool {
+	return l.approved
+}
+
+// CountedAt returns when the latest count was submitted
+func (l *StocktakeLine) CountedAt() time.Time {
+	return l.countedAt
+}
+
+// Variance returns counted minus expected quantity (zero if not yet counted)
+func (l *StocktakeLine) Variance() int {
+	if !l.counted {
+		return 0
+	}
+	return l.countedQuantity - l.expectedQuantity
+}
+
+// Stocktake represents a physical count session over a set of inventory records
+type Stocktake struct {
+	id        string
+	status    StocktakeStatus
+	lines     []*StocktakeLine
+	createdAt time.Time
+	updatedAt time.Time
+	appliedAt time.Time
+}
+
+// NewStocktake opens a stocktake session, freezing the expected quantity of each inventory record
+func NewStocktake(id string, snapshot []*Inventory) (*Stocktake, error) {
+	if id == "" {
+		return nil, errors.New(errors.CodeInvalidInput, "stocktake id cannot be empty")
+	}
+	if len(snapshot) == 0 {
+		return nil, errors.New(errors.CodeInvalidInput, "stocktake requires at least one inventory record")
+	}
+
+	seen := make(map[string]bool, len(snapshot))
+	lines := make([]*StocktakeLine, 0, len(snapshot))
+	for _, inv := range snapshot {
+		if inv == nil || seen[inv.ProductID()] {
+			continue
+		}
+		seen[inv.ProductID()] = true
+		lines = append(lines, &StocktakeLine{
+			productID:        inv.ProductID(),
+			location:         inv.Location(),
+			expectedQuantity: inv.Quantity(),
+		})
+	}
+
+	now := time.Now()
+	return &Stocktake{
+		id:        id,
+		status:    StocktakeStatusOpen,
+		lines:     lines,
+		createdAt: now,
+		updatedAt: now,
+	}, nil
+}
+
+// ReconstructStocktake reconstructs a Stocktake entity from persistence
+func ReconstructStocktake(id string, status StocktakeStatus, lines []*StocktakeLine, createdAt, updatedAt, appliedAt time.Time) *Stocktake {
+	return &Stocktake{
+		id:        id,
+		status:    status,
+		lines:     lines,
+		createdAt: createdAt,
+		updatedAt: updatedAt,
+		appliedAt: appliedAt,
+	}
+}
+
+// ID returns the stocktake's unique identifier
+func (s *Stocktake) ID() string {
+	return s.id
+}
+
+// Status returns the current lifecycle state
+func (s *Stocktake) Status() StocktakeStatus {
+	return s.status
+}
+
+// Lines returns the counted lines of the session
+func (s *Stocktake) Lines() []*StocktakeLine {
+	lines := make([]*StocktakeLine, len(s.lines))
+	copy(lines, s.lines)
+	return lines
+}
+
+// CreatedAt returns when the session was opened
+func (s *Stocktake) CreatedAt() time.Time {
+	return s.createdAt
+}
+
+// UpdatedAt returns when the session was last updated
+func (s *Stocktake) UpdatedAt() time.Time {
+	return s.updatedAt
+}
+
+// AppliedAt returns when approved variances were posted (zero if not applied)
+func (s *Stocktake) AppliedAt() time.Time {
+	return s.appliedAt
+}
+
+// RecordCount records a counted quantity for a product; counting again is a recount
+// and clears any previous approval of that line
+func (s *Stocktake) RecordCount(productID string, countedQuantity int) error {
+	if err := s.ensureOpen(); err != nil {
+		return err
+	}
+	if countedQuantity < 0 {
+		return ErrInvalidQuantity
+	}
+	line := s.line(productID)
+	if line == nil {
+		return errors.Newf(errors.CodeInvalidInput, "product %s is not part of this stocktake", productID)
+	}
+
+	now := time.Now()
+	line.countedQuantity = countedQuantity
+	line.counted = true
+	line.countAttempts++
+	line.approved = false
+	line.countedAt = now
+	s.updatedAt = now
+	return nil
+}
+
+// Approve approves the variances of the given products, or of every counted line when none are given
+func (s *Stocktake) Approve(productIDs ...string) error {
+	if err := s.ensureOpen(); err != nil {
+		return err
+	}
+
+	if len(productIDs) == 0 {
+		for _, line := range s.lines {
+			if line.counted {
+				line.approved = true
+			}
+		}
+		s.updatedAt = time.Now()
+		return nil
+	}
+
+	for _, productID := range productIDs {
+		line := s.line(productID)
+		if line == nil {
+			return errors.Newf(errors.CodeInvalidInput, "product %s is not part of this stocktake", productID)
+		}
+		if !line.counted {
+			return errors.Newf(errors.CodeInvalidInput, "product %s has not been counted", productID)
+		}
+	}
+	for _, productID := range productIDs {
+		s.line(productID).approved = true
+	}
+	s.updatedAt = time.Now()
+	return nil
+}
+
+// ApprovedVariances returns approved lines whose counted quantity differs from the snapshot
+func (s *Stocktake) ApprovedVariances() []*StocktakeLine {
+	var lines []*StocktakeLine
+	for _, line := range s.lines {
+		if line.approved && line.Variance() != 0 {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// MarkApplied closes the session after its approved variances have been posted
+func (s *Stocktake) MarkApplied() error {
+	if err := s.ensureOpen(); err != nil {
+		return err
+	}
+	now := time.Now()
+	s.status = StocktakeStatusApplied
+	s.appliedAt = now
+	s.updatedAt = now
+	return nil
+}
+
+// Cancel closes the session without posting any variance
+func (s *Stocktake) Cancel() error {
+	if err := s.ensureOpen(); err != nil {
+		return err
+	}
+	s.status = StocktakeStatusCancelled
+	s.updatedAt = time.Now()
+	return nil
+}
+
+// ensureOpen guards mutations of a closed session
+func (s *Stocktake) ensureOpen() error {
+	if s.status != StocktakeStatusOpen {
+		return ErrStocktakeNotOpen
+	}
+	return nil
+}
+
+// line finds the line for a product
+func (s *Stocktake) line(productID string) *StocktakeLine {
+	for _, line := range s.lines {
+		if line.productID == productID {
+			return line
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package inventory
+
+import "context"
+
+// StocktakeCommandRepository defines the interface for stocktake write operations
+// This interface belongs to the domain layer and has no infrastructure dependencies
+type StocktakeCommandRepository interface {
+	// Create stores a new stocktake session with its snapshot lines
+	Create(ctx context.Context, stocktake *Stocktake) error
+
+	// Update persists counts, approvals and status changes of a stocktake session
+	// Only a session that is still open is written; otherwise it fails with ErrStocktakeNotOpen,
+	// so of two concurrent closes only the first succeeds.
+	Update(ctx context.Context, stocktake *Stocktake) error
+
+	// Apply stores the applied session, failing like Update if it is no longer open
+	// The inventories its variances adjust are saved through the inventory repository,
+	// in the same unit of work.
+	Apply(ctx context.Context, stocktake *Stocktake) error
+}
+
+// StocktakeQueryRepository defines the interface for stocktake read operations
+// This interface belongs to the domain layer and has no infrastructure dependencies
+type StocktakeQueryRepository interface {
+	// GetByID retrieves a stocktake session with its lines
+	// Returns nil if the stocktake is not found
+	GetByID(ctx context.Context, id string) (*Stocktake, error)
+}
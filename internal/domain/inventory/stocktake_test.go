@@ -0,0 +1,160 @@
+package inventory_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+func newSnapshot() []*inventory.Inventory {
+	now := time.Now()
+	return []*inventory.Inventory{
+		inventory.ReconstructInventory("inv-1", "product-1", 100, 10, 0, inventory.StrictStockPolicy(), "Warehouse A", nil, now, now, 1),
+		inventory.ReconstructInventory("inv-2", "product-2", 50, 0, 0, inventory.StrictStockPolicy(), "Warehouse A", nil, now, now, 1),
+	}
+}
+
+func TestNewStocktake(t *testing.T) {
+	tests := []struct {
+		name      string
+		id        string
+		snapshot  []*inventory.Inventory
+		wantErr   bool
+		wantLines int
+	}{
+		{
+			name:      "valid snapshot",
+			id:        "st-1",
+			snapshot:  newSnapshot(),
+			wantLines: 2,
+		},
+		{
+			name:      "duplicate products are frozen once",
+			id:        "st-1",
+			snapshot:  append(newSnapshot(), newSnapshot()...),
+			wantLines: 2,
+		},
+		{
+			name:     "empty ID",
+			id:       "",
+			snapshot: newSnapshot(),
+			wantErr:  true,
+		},
+		{
+			name:     "empty snapshot",
+			id:       "st-1",
+			snapshot: nil,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := inventory.NewStocktake(tt.id, tt.snapshot)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewStocktake() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Status() != inventory.StocktakeStatusOpen {
+				t.Errorf("NewStocktake() Status() = %v, want %v", got.Status(), inventory.StocktakeStatusOpen)
+			}
+			if len(got.Lines()) != tt.wantLines {
+				t.Errorf("NewStocktake() len(Lines()) = %d, want %d", len(got.Lines()), tt.wantLines)
+			}
+			if got.Lines()[0].ExpectedQuantity() != 100 {
+				t.Errorf("NewStocktake() ExpectedQuantity() = %d, want 100", got.Lines()[0].ExpectedQuantity())
+			}
+		})
+	}
+}
+
+func TestStocktake_RecordCount(t *testing.T) {
+	st, _ := inventory.NewStocktake("st-1", newSnapshot())
+
+	if err := st.RecordCount("product-1", 95); err != nil {
+		t.Fatalf("RecordCount() unexpected error = %v", err)
+	}
+	line := st.Lines()[0]
+	if line.Variance() != -5 {
+		t.Errorf("Variance() = %d, want -5", line.Variance())
+	}
+	if st.Lines()[1].IsCounted() {
+		t.Error("uncounted line should not be marked as counted")
+	}
+
+	// Recount replaces the count and clears approval
+	_ = st.Approve("product-1")
+	if err := st.RecordCount("product-1", 98); err != nil {
+		t.Fatalf("RecordCount() recount unexpected error = %v", err)
+	}
+	line = st.Lines()[0]
+	if line.CountAttempts() != 2 {
+		t.Errorf("CountAttempts() = %d, want 2", line.CountAttempts())
+	}
+	if line.IsApproved() {
+		t.Error("recount should clear approval")
+	}
+	if line.Variance() != -2 {
+		t.Errorf("Variance() after recount = %d, want -2", line.Variance())
+	}
+
+	if err := st.RecordCount("product-unknown", 1); !errors.Is(err, errors.CodeInvalidInput) {
+		t.Errorf("RecordCount() unknown product error = %v, want %s", err, errors.CodeInvalidInput)
+	}
+	if err := st.RecordCount("product-1", -1); !errors.Is(err, errors.CodeInvalidQuantity) {
+		t.Errorf("RecordCount() negative error = %v, want %s", err, errors.CodeInvalidQuantity)
+	}
+}
+
+func TestStocktake_Approve(t *testing.T) {
+	st, _ := inventory.NewStocktake("st-1", newSnapshot())
+	_ = st.RecordCount("product-1", 90)
+
+	if err := st.Approve("product-2"); !errors.Is(err, errors.CodeInvalidInput) {
+		t.Errorf("Approve() uncounted line error = %v, want %s", err, errors.CodeInvalidInput)
+	}
+
+	if err := st.Approve(); err != nil {
+		t.Fatalf("Approve() unexpected error = %v", err)
+	}
+	variances := st.ApprovedVariances()
+	if len(variances) != 1 || variances[0].ProductID() != "product-1" || variances[0].Variance() != -10 {
+		t.Errorf("ApprovedVariances() = %v, want single product-1 line with variance -10", variances)
+	}
+}
+
+func TestStocktake_ApprovedVariances_SkipsZeroVariance(t *testing.T) {
+	st, _ := inventory.NewStocktake("st-1", newSnapshot())
+	_ = st.RecordCount("product-1", 100)
+	_ = st.RecordCount("product-2", 55)
+	_ = st.Approve()
+
+	variances := st.ApprovedVariances()
+	if len(variances) != 1 || variances[0].ProductID() != "product-2" {
+		t.Errorf("ApprovedVariances() = %v, want only product-2", variances)
+	}
+}
+
+func TestStocktake_ClosedSessionRejectsChanges(t *testing.T) {
+	st, _ := inventory.NewStocktake("st-1", newSnapshot())
+	if err := st.MarkApplied(); err != nil {
+		t.Fatalf("MarkApplied() unexpected error = %v", err)
+	}
+	if st.AppliedAt().IsZero() {
+		t.Error("AppliedAt() should be set after MarkApplied()")
+	}
+
+	if err := st.RecordCount("product-1", 1); !errors.Is(err, errors.CodeStocktakeNotOpen) {
+		t.Errorf("RecordCount() on applied session error = %v, want %s", err, errors.CodeStocktakeNotOpen)
+	}
+	if err := st.Approve(); !errors.Is(err, errors.CodeStocktakeNotOpen) {
+		t.Errorf("Approve() on applied session error = %v, want %s", err, errors.CodeStocktakeNotOpen)
+	}
+	if err := st.Cancel(); !errors.Is(err, errors.CodeStocktakeNotOpen) {
+		t.Errorf("Cancel() on applied session error = %v, want %s", err, errors.CodeStocktakeNotOpen)
+	}
+}
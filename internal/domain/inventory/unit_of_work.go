@@ -0,0 +1,56 @@
+package inventory
+
+import (
+	"context"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// IsolationLevel is the transaction isolation a unit of work runs with
+type IsolationLevel string
+
+const (
+	// IsolationDefault uses the database's default isolation level
+	IsolationDefault IsolationLevel = ""
+	// IsolationReadCommitted sees only data committed before each statement
+	IsolationReadCommitted IsolationLevel = "read_committed"
+	// IsolationRepeatableRead sees a snapshot taken at the first statement
+	IsolationRepeatableRead IsolationLevel = "repeatable_read"
+	// IsolationSerializable behaves as if transactions ran one after another
+	IsolationSerializable IsolationLevel = "serializable"
+)
+
+// ParseIsolationLevel converts a configuration value to an IsolationLevel
+func ParseIsolationLevel(value string) (IsolationLevel, error) {
+	switch level := IsolationLevel(value); level {
+	case IsolationDefault, IsolationReadCommitted, IsolationRepeatableRead, IsolationSerializable:
+		return level, nil
+	}
+	return "", errors.Newf(errors.CodeInvalidInput,
+		"isolation level must be %q, %q or %q", IsolationReadCommitted, IsolationRepeatableRead, IsolationSerializable)
+}
+
+// TxRepositories are the repositories bound to a single transaction
+// They must not be used after the unit of work returns
+type TxRepositories struct {
+	InventoryCommands InventoryCommandRepository
+	InventoryQueries  InventoryQueryRepository
+	StocktakeCommands StocktakeCommandRepository
+	StocktakeQueries  StocktakeQueryRepository
+	ValuationCommands ValuationCommandRepository
+	ValuationQueries  ValuationQueryRepository
+	// Audit appends to the audit trail in the transaction, so entries commit with the changes they record
+	Audit audit.Store
+}
+
+// UnitOfWork runs a function inside a transaction
+// The transaction commits when the function returns nil and rolls back otherwise.
+// Serialization failures are retried, so the function must be safe to run again.
+type UnitOfWork interface {
+	// Do runs fn with the configured default isolation level
+	Do(ctx context.Context, fn func(ctx context.Context, repos TxRepositories) error) error
+
+	// DoWithIsolation runs fn with the given isolation level
+	DoWithIsolation(ctx context.Context, level IsolationLevel, fn func(ctx context.Context, repos TxRepositories) error) error
+}
@@ -0,0 +1,40 @@
+package inventory_test
+
+import (
+	"testing"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+func TestParseIsolationLevel(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    inventory.IsolationLevel
+		wantErr bool
+	}{
+		{value: "", want: inventory.IsolationDefault},
+		{value: "read_committed", want: inventory.IsolationReadCommitted},
+		{value: "repeatable_read", want: inventory.IsolationRepeatableRead},
+		{value: "serializable", want: inventory.IsolationSerializable},
+		{value: "read_uncommitted", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := inventory.ParseIsolationLevel(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseIsolationLevel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, errors.CodeInvalidInput) {
+					t.Errorf("ParseIsolationLevel() error code = %s, want %s", errors.GetCode(err), errors.CodeInvalidInput)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseIsolationLevel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
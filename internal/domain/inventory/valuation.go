@@ -0,0 +1,246 @@
+package inventory
+
+import (
+	"math"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// CostingMethod determines how outbound stock is costed
+type CostingMethod string
+
+const (
+	// CostingMethodFIFO consumes the oldest cost layers first
+	CostingMethodFIFO CostingMethod = "fifo"
+	// CostingMethodWeightedAverage costs every unit at the moving weighted average
+	CostingMethodWeightedAverage CostingMethod = "weighted_average"
+)
+
+// ParseCostingMethod validates a costing method name
+func ParseCostingMethod(method string) (CostingMethod, error) {
+	switch CostingMethod(method) {
+	case CostingMethodFIFO, CostingMethodWeightedAverage:
+		return CostingMethod(method), nil
+	default:
+		return "", errors.Newf(errors.CodeInvalidCostingMethod, "unknown costing method %q", method)
+	}
+}
+
+// CostLayer is a batch of received stock with its unit cost (used by FIFO)
+type CostLayer struct {
+	id                string
+	receivedQuantity  int
+	remainingQuantity int
+	unitCost          float64
+	receivedAt        time.Time
+}
+
+// ReconstructCostLayer reconstructs a CostLayer from persistence
+func ReconstructCostLayer(id string, receivedQuantity, remainingQuantity int, unitCost float64, receivedAt time.Time) *CostLayer {
+	return &CostLayer{
+		id:                id,
+		receivedQuantity:  receivedQuantity,
+		remainingQuantity: remainingQuantity,
+		unitCost:          unitCost,
+		receivedAt:        receivedAt,
+	}
+}
+
+// ID returns the layer's unique identifier
+func (l *CostLayer) ID() string {
+	return l.id
+}
+
+// ReceivedQuantity returns the quantity originally received
+func (l *CostLayer) ReceivedQuantity() int {
+	return l.receivedQuantity
+}
+
+// RemainingQuantity returns the quantity not yet consumed
+func (l *CostLayer) RemainingQuantity() int {
+	return l.remainingQuantity
+}
+
+// UnitCost returns the cost per unit of this layer
+func (l *CostLayer) UnitCost() float64 {
+	return l.unitCost
+}
+
+// ReceivedAt returns when the layer was received
+func (l *CostLayer) ReceivedAt() time.Time {
+	return l.receivedAt
+}
+
+// StockValuation tracks the monetary value of a product's stock
+// Units that were on hand before valuation started carry no cost
+type StockValuation struct {
+	productID    string
+	method       CostingMethod
+	currency     string
+	quantity     int
+	totalCost    float64
+	consumedCost float64
+	layers       []*CostLayer
+	updatedAt    time.Time
+}
+
+// NewStockValuation creates an empty valuation for a product
+func NewStockValuation(productID string, method CostingMethod, currency string) (*StockValuation, error) {
+	if productID == "" {
+		return nil, errors.New(errors.CodeInvalidInput, "product id cannot be empty")
+	}
+	if _, err := ParseCostingMethod(string(method)); err != nil {
+		return nil, err
+	}
+	if len(currency) != 3 {
+		return nil, errors.New(errors.CodeInvalidPrice, "currency must be a 3-letter ISO code")
+	}
+
+	return &StockValuation{
+		productID: productID,
+		method:    method,
+		currency:  currency,
+		updatedAt: time.Now(),
+	}, nil
+}
+
+// ReconstructStockValuation reconstructs a StockValuation from persistence
+// Layers must be ordered oldest first
+func ReconstructStockValuation(productID string, method CostingMethod, currency string, quantity int, totalCost, consumedCost float64, layers []*CostLayer, updatedAt time.Time) *StockValuation {
+	return &StockValuation{
+		productID:    productID,
+		method:       method,
+		currency:     currency,
+		quantity:     quantity,
+		totalCost:    totalCost,
+		consumedCost: consumedCost,
+		layers:       layers,
+		updatedAt:    updatedAt,
+	}
+}
+
+// ProductID returns the valued product
+func (v *StockValuation) ProductID() string {
+	return v.productID
+}
+
+// Method returns the costing method
+func (v *StockValuation) Method() CostingMethod {
+	return v.method
+}
+
+// Currency returns the currency all costs are expressed in
+func (v *StockValuation) Currency() string {
+	return v.currency
+}
+
+// Quantity returns the quantity being valued
+func (v *StockValuation) Quantity() int {
+	return v.quantity
+}
+
+// TotalCost returns the value of the stock on hand
+func (v *StockValuation) TotalCost() float64 {
+	return v.totalCost
+}
+
+// ConsumedCost returns the cumulative cost of goods consumed by outbound movements
+func (v *StockValuation) ConsumedCost() float64 {
+	return v.consumedCost
+}
+
+// AverageUnitCost returns the value per unit on hand
+func (v *StockValuation) AverageUnitCost() float64 {
+	if v.quantity == 0 {
+		return 0
+	}
+	return roundCost(v.totalCost / float64(v.quantity))
+}
+
+// Layers returns the cost layers, oldest first (empty for weighted average)
+func (v *StockValuation) Layers() []*CostLayer {
+	layers := make([]*CostLayer, len(v.layers))
+	copy(layers, v.layers)
+	return layers
+}
+
+// UpdatedAt returns when the valuation last changed
+func (v *StockValuation) UpdatedAt() time.Time {
+	return v.updatedAt
+}
+
+// Receive records inbound stock at the given unit cost
+func (v *StockValuation) Receive(layerID string, quantity int, unitCost float64, receivedAt time.Time) error {
+	if quantity <= 0 {
+		return ErrInvalidQuantity
+	}
+	if unitCost < 0 {
+		return errors.New(errors.CodeInvalidPrice, "unit cost cannot be negative")
+	}
+
+	if v.method == CostingMethodFIFO {
+		if layerID == "" {
+			return errors.New(errors.CodeInvalidInput, "cost layer id cannot be empty")
+		}
+		v.layers = append(v.layers, &CostLayer{
+			id:                layerID,
+			receivedQuantity:  quantity,
+			remainingQuantity: quantity,
+			unitCost:          unitCost,
+			receivedAt:        receivedAt,
+		})
+	}
+
+	v.quantity += quantity
+	v.totalCost = roundCost(v.totalCost + float64(quantity)*unitCost)
+	v.updatedAt = time.Now()
+	return nil
+}
+
+// Issue records outbound stock and returns the cost of goods consumed
+func (v *StockValuation) Issue(quantity int) (float64, error) {
+	if quantity <= 0 {
+		return 0, ErrInvalidQuantity
+	}
+
+	var cost float64
+	switch v.method {
+	case CostingMethodFIFO:
+		remaining := quantity
+		for _, layer := range v.layers {
+			if remaining == 0 {
+				break
+			}
+			take := layer.remainingQuantity
+			if take > remaining {
+				take = remaining
+			}
+			layer.remainingQuantity -= take
+			remaining -= take
+			cost += float64(take) * layer.unitCost
+		}
+	default:
+		costed := quantity
+		if costed > v.quantity {
+			costed = v.quantity
+		}
+		cost = float64(costed) * v.AverageUnitCost()
+	}
+	cost = roundCost(cost)
+
+	v.quantity -= quantity
+	if v.quantity <= 0 {
+		v.quantity = 0
+		cost = v.totalCost
+	}
+	v.totalCost = roundCost(v.totalCost - cost)
+	v.consumedCost = roundCost(v.consumedCost + cost)
+	v.updatedAt = time.Now()
+	return cost, nil
+}
+
+// roundCost rounds to the 4 decimal places stored by persistence
+func roundCost(amount float64) float64 {
+	return math.Round(amount*10000) / 10000
+}
@@ -0,0 +1,24 @@
+package inventory
+
+import "context"
+
+// ValuationCommandRepository defines the interface for stock valuation write operations
+// This interface belongs to the domain layer and has no infrastructure dependencies
+type ValuationCommandRepository interface {
+	// Save creates or updates a valuation together with its cost layers
+	Save(ctx context.Context, valuation *StockValuation) error
+
+	// Delete removes the valuation of a product together with its cost layers
+	Delete(ctx context.Context, productID string) error
+}
+
+// ValuationQueryRepository defines the interface for stock valuation read operations
+// This interface belongs to the domain layer and has no infrastructure dependencies
+type ValuationQueryRepository interface {
+	// GetByProductID retrieves a valuation with its open cost layers
+	// Returns nil if the product has no valuation
+	GetByProductID(ctx context.Context, productID string) (*StockValuation, error)
+
+	// List retrieves every valuation with its open cost layers
+	List(ctx context.Context) ([]*StockValuation, error)
+}
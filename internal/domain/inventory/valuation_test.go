@@ -0,0 +1,130 @@
+package inventory_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+func newValuation(t *testing.T, method inventory.CostingMethod) *inventory.StockValuation {
+	t.Helper()
+	v, err := inventory.NewStockValuation("product-1", method, "USD")
+	if err != nil {
+		t.Fatalf("NewStockValuation() unexpected error = %v", err)
+	}
+	now := time.Now()
+	if err := v.Receive("layer-1", 10, 2.00, now); err != nil {
+		t.Fatalf("Receive() unexpected error = %v", err)
+	}
+	if err := v.Receive("layer-2", 10, 4.00, now.Add(time.Minute)); err != nil {
+		t.Fatalf("Receive() unexpected error = %v", err)
+	}
+	return v
+}
+
+func TestNewStockValuation(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   inventory.CostingMethod
+		currency string
+		wantCode errors.ErrorCode
+	}{
+		{name: "fifo", method: inventory.CostingMethodFIFO, currency: "USD"},
+		{name: "weighted average", method: inventory.CostingMethodWeightedAverage, currency: "EUR"},
+		{name: "unknown method", method: "lifo", currency: "USD", wantCode: errors.CodeInvalidCostingMethod},
+		{name: "invalid currency", method: inventory.CostingMethodFIFO, currency: "US", wantCode: errors.CodeInvalidPrice},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := inventory.NewStockValuation("product-1", tt.method, tt.currency)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("NewStockValuation() unexpected error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantCode) {
+				t.Errorf("NewStockValuation() error = %v, want code %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestStockValuation_Issue_FIFO(t *testing.T) {
+	v := newValuation(t, inventory.CostingMethodFIFO)
+
+	cost, err := v.Issue(15)
+	if err != nil {
+		t.Fatalf("Issue() unexpected error = %v", err)
+	}
+	// 10 units at 2.00 from the oldest layer, 5 units at 4.00 from the next
+	if cost != 40 {
+		t.Errorf("Issue(15) cost = %v, want 40", cost)
+	}
+	if v.Quantity() != 5 || v.TotalCost() != 20 || v.ConsumedCost() != 40 {
+		t.Errorf("after Issue(15) quantity=%d total=%v consumed=%v, want 5/20/40", v.Quantity(), v.TotalCost(), v.ConsumedCost())
+	}
+	if layers := v.Layers(); layers[0].RemainingQuantity() != 0 || layers[1].RemainingQuantity() != 5 {
+		t.Errorf("remaining per layer = %d/%d, want 0/5", layers[0].RemainingQuantity(), layers[1].RemainingQuantity())
+	}
+}
+
+func TestStockValuation_Issue_WeightedAverage(t *testing.T) {
+	v := newValuation(t, inventory.CostingMethodWeightedAverage)
+
+	if v.AverageUnitCost() != 3 {
+		t.Fatalf("AverageUnitCost() = %v, want 3", v.AverageUnitCost())
+	}
+	cost, err := v.Issue(15)
+	if err != nil {
+		t.Fatalf("Issue() unexpected error = %v", err)
+	}
+	if cost != 45 {
+		t.Errorf("Issue(15) cost = %v, want 45", cost)
+	}
+	if len(v.Layers()) != 0 {
+		t.Errorf("weighted average should not keep cost layers, got %d", len(v.Layers()))
+	}
+
+	// Receiving at a new cost moves the average
+	if err := v.Receive("", 5, 5.00, time.Now()); err != nil {
+		t.Fatalf("Receive() unexpected error = %v", err)
+	}
+	if v.AverageUnitCost() != 4 {
+		t.Errorf("AverageUnitCost() after receipt = %v, want 4", v.AverageUnitCost())
+	}
+}
+
+func TestStockValuation_Issue_BeyondValuedStock(t *testing.T) {
+	for _, method := range []inventory.CostingMethod{inventory.CostingMethodFIFO, inventory.CostingMethodWeightedAverage} {
+		t.Run(string(method), func(t *testing.T) {
+			v := newValuation(t, method)
+
+			// Units without cost information are consumed at zero cost
+			cost, err := v.Issue(25)
+			if err != nil {
+				t.Fatalf("Issue() unexpected error = %v", err)
+			}
+			if cost != 60 || v.Quantity() != 0 || v.TotalCost() != 0 {
+				t.Errorf("Issue(25) cost=%v quantity=%d total=%v, want 60/0/0", cost, v.Quantity(), v.TotalCost())
+			}
+		})
+	}
+}
+
+func TestStockValuation_Receive_Validation(t *testing.T) {
+	v, _ := inventory.NewStockValuation("product-1", inventory.CostingMethodFIFO, "USD")
+
+	if err := v.Receive("layer-1", 0, 1, time.Now()); !errors.Is(err, errors.CodeInvalidQuantity) {
+		t.Errorf("Receive() zero quantity error = %v, want %s", err, errors.CodeInvalidQuantity)
+	}
+	if err := v.Receive("layer-1", 1, -1, time.Now()); !errors.Is(err, errors.CodeInvalidPrice) {
+		t.Errorf("Receive() negative cost error = %v, want %s", err, errors.CodeInvalidPrice)
+	}
+	if _, err := v.Issue(0); !errors.Is(err, errors.CodeInvalidQuantity) {
+		t.Errorf("Issue() zero quantity error = %v, want %s", err, errors.CodeInvalidQuantity)
+	}
+}
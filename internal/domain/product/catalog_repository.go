@@ -0,0 +1,37 @@
+package product
+
+import (
+	"context"
+	"time"
+)
+
+// CatalogEntry is the denormalized product-with-stock read model
+// It is kept in step with product and inventory changes so a product read costs a single lookup
+type CatalogEntry struct {
+	ProductID         string
+	Name              string
+	PriceAmount       float64
+	PriceCurrency     string
+	HasInventory      bool
+	Quantity          int
+	ReservedQuantity  int
+	AvailableQuantity int
+	Location          string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// CatalogQueryRepository defines the interface for reading the product catalog read model
+// This interface belongs to the domain layer and has no infrastructure dependencies
+type CatalogQueryRepository interface {
+	// GetByProductID retrieves the catalog entry of a product
+	// Returns nil if the product is not found
+	GetByProductID(ctx context.Context, productID string) (*CatalogEntry, error)
+}
+
+// CatalogProjection maintains the product catalog read model
+type CatalogProjection interface {
+	// Rebuild regenerates every catalog entry from the product and inventory records
+	// Returns the number of entries written
+	Rebuild(ctx context.Context) (int, error)
+}
@@ -3,13 +3,15 @@ package product
 import (
 	"time"
 
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/event"
 	"github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
 )
 
 // Domain errors - using pkg/errors for consistency
 var (
-	ErrProductNotFound      = errors.New(errors.CodeProductNotFound, "product not found")
-	ErrProductAlreadyExists = errors.New(errors.CodeProductAlreadyExists, "product already exists")
+	ErrProductNotFound        = errors.New(errors.CodeProductNotFound, "product not found")
+	ErrProductAlreadyExists   = errors.New(errors.CodeProductAlreadyExists, "product already exists")
+	ErrConcurrentModification = errors.New(errors.CodeConcurrencyConflict, "product was modified concurrently, reload and retry")
 )
 
 // Product represents a product entity in the domain
@@ -19,6 +21,8 @@ type Product struct {
 	price     Price
 	createdAt time.Time
 	updatedAt time.Time
+	version   int
+	events    event.Events
 }
 
 // NewProduct creates a new Product entity with validation
@@ -31,24 +35,34 @@ func NewProduct(id, name string, price Price) (*Product, error) {
 	}
 
 	now := time.Now()
-	return &Product{
+	p := &Product{
 		id:        id,
 		name:      name,
 		price:     price,
 		createdAt: now,
 		updatedAt: now,
-	}, nil
+		version:   1,
+	}
+	p.events.Record(ProductCreated{
+		ProductID:     id,
+		Name:          name,
+		PriceAmount:   price.Amount(),
+		PriceCurrency: price.Currency(),
+		At:            now,
+	})
+	return p, nil
 }
 
 // ReconstructProduct reconstructs a Product entity from persistence
-// This is used when loading from database
-func ReconstructProduct(id, name string, price Price, createdAt, updatedAt time.Time) *Product {
+// This is used when loading from database; version is the persisted optimistic lock version
+func ReconstructProduct(id, name string, price Price, createdAt, updatedAt time.Time, version int) *Product {
 	return &Product{
 		id:        id,
 		name:      name,
 		price:     price,
 		createdAt: createdAt,
 		updatedAt: updatedAt,
+		version:   version,
 	}
 }
 
@@ -77,6 +91,11 @@ func (p *Product) UpdatedAt() time.Time {
 	return p.updatedAt
 }
 
+// Version returns the version the entity was loaded with, used for optimistic concurrency
+func (p *Product) Version() int {
+	return p.version
+}
+
 // UpdateName updates the product's name with validation
 func (p *Product) UpdateName(name string) error {
 	if name == "" {
@@ -88,8 +107,32 @@ func (p *Product) UpdateName(name string) error {
 }
 
 // UpdatePrice updates the product's price with validation
+// Changing to a different price raises ProductPriceChanged
 func (p *Product) UpdatePrice(price Price) error {
+	old := p.price
 	p.price = price
 	p.updatedAt = time.Now()
+	if !old.Equals(price) {
+		p.events.Record(ProductPriceChanged{
+			ProductID:        p.id,
+			OldPriceAmount:   old.Amount(),
+			OldPriceCurrency: old.Currency(),
+			NewPriceAmount:   price.Amount(),
+			NewPriceCurrency: price.Currency(),
+			At:               p.updatedAt,
+		})
+	}
 	return nil
 }
+
+// PendingEvents returns the events raised since the product was created or loaded, leaving them pending
+// Repositories store them in the outbox together with the changes that raised them
+func (p *Product) PendingEvents() []event.Event {
+	return p.events.Pending()
+}
+
+// PullEvents returns the events raised since the product was created or loaded, and clears them
+// Callers dispatch them once the changes that raised them are stored
+func (p *Product) PullEvents() []event.Event {
+	return p.events.Pull()
+}
@@ -0,0 +1,49 @@
+package product
+
+import "time"
+
+// AggregateType names products in the outbox and in published events
+const AggregateType = "product"
+
+// Event names of the product aggregate
+const (
+	EventProductCreated      = "product.created"
+	EventProductPriceChanged = "product.price_changed"
+)
+
+// ProductCreated is raised when a new product is created
+type ProductCreated struct {
+	ProductID     string    `json:"product_id"`
+	Name          string    `json:"name"`
+	PriceAmount   float64   `json:"price_amount"`
+	PriceCurrency string    `json:"price_currency"`
+	At            time.Time `json:"occurred_at"`
+}
+
+// EventName identifies the kind of event
+func (e ProductCreated) EventName() string { return EventProductCreated }
+
+// AggregateID returns the ID of the product
+func (e ProductCreated) AggregateID() string { return e.ProductID }
+
+// OccurredAt returns when the product was created
+func (e ProductCreated) OccurredAt() time.Time { return e.At }
+
+// ProductPriceChanged is raised when a product's price is changed to a different price
+type ProductPriceChanged struct {
+	ProductID        string    `json:"product_id"`
+	OldPriceAmount   float64   `json:"old_price_amount"`
+	OldPriceCurrency string    `json:"old_price_currency"`
+	NewPriceAmount   float64   `json:"new_price_amount"`
+	NewPriceCurrency string    `json:"new_price_currency"`
+	At               time.Time `json:"occurred_at"`
+}
+
+// EventName identifies the kind of event
+func (e ProductPriceChanged) EventName() string { return EventProductPriceChanged }
+
+// AggregateID returns the ID of the product
+func (e ProductPriceChanged) AggregateID() string { return e.ProductID }
+
+// OccurredAt returns when the price changed
+func (e ProductPriceChanged) OccurredAt() time.Time { return e.At }
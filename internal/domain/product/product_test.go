@@ -88,7 +88,7 @@ func TestReconstructProduct(t *testing.T) {
 	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 	updatedAt := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
 
-	prod := product.ReconstructProduct("product-123", "Test Product", price, createdAt, updatedAt)
+	prod := product.ReconstructProduct("product-123", "Test Product", price, createdAt, updatedAt, 1)
 
 	if prod.ID() != "product-123" {
 		t.Errorf("ReconstructProduct() ID() = %v, want %v", prod.ID(), "product-123")
@@ -199,3 +199,33 @@ func TestProduct_Getters(t *testing.T) {
 		t.Error("Product.UpdatedAt() should not be zero")
 	}
 }
+
+func TestProduct_PullEvents(t *testing.T) {
+	price, _ := product.NewPrice(99.99, "USD")
+	prod, _ := product.NewProduct("product-123", "Test Product", price)
+
+	events := prod.PullEvents()
+	if len(events) != 1 || events[0].EventName() != product.EventProductCreated {
+		t.Fatalf("Product.PullEvents() = %v, want one %s", events, product.EventProductCreated)
+	}
+	if len(prod.PullEvents()) != 0 {
+		t.Error("Product.PullEvents() should clear the pulled events")
+	}
+
+	// Setting the same price changes nothing
+	_ = prod.UpdatePrice(price)
+	if events := prod.PullEvents(); len(events) != 0 {
+		t.Errorf("Product.PullEvents() after an unchanged price = %v, want none", events)
+	}
+
+	newPrice, _ := product.NewPrice(149.99, "USD")
+	_ = prod.UpdatePrice(newPrice)
+	events = prod.PullEvents()
+	if len(events) != 1 {
+		t.Fatalf("Product.PullEvents() = %v, want one event", events)
+	}
+	changed, ok := events[0].(product.ProductPriceChanged)
+	if !ok || changed.OldPriceAmount != 99.99 || changed.NewPriceAmount != 149.99 || changed.AggregateID() != "product-123" {
+		t.Errorf("Product.PullEvents() = %+v, want a price change from 99.99 to 149.99", events[0])
+	}
+}
@@ -9,6 +9,10 @@ type ProductQueryRepository interface {
 	// Returns nil if product is not found
 	GetByID(ctx context.Context, id string) (*Product, error)
 
+	// GetByIDs retrieves the products with the given identifiers in a single call
+	// Unknown identifiers are omitted from the result
+	GetByIDs(ctx context.Context, ids []string) ([]*Product, error)
+
 	// List retrieves all products with pagination
 	List(ctx context.Context, limit, offset int) ([]*Product, error)
 }
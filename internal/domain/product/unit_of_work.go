@@ -0,0 +1,22 @@
+package product
+
+import (
+	"context"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+)
+
+// TxRepositories are the repositories bound to a single transaction
+// They must not be used after the unit of work returns
+type TxRepositories struct {
+	ProductCommands ProductCommandRepository
+	// Audit appends to the audit trail in the transaction, so entries commit with the changes they record
+	Audit audit.Store
+}
+
+// UnitOfWork runs a function inside a transaction
+// The transaction commits when the function returns nil and rolls back otherwise.
+type UnitOfWork interface {
+	// Do runs fn in a transaction
+	Do(ctx context.Context, fn func(ctx context.Context, repos TxRepositories) error) error
+}
@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/readconsistency"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	"golang.org/x/sync/singleflight"
+)
+
+// Cache stores encoded entities by key
+// Implementations must be safe for concurrent use
+type Cache interface {
+	// Get returns the value stored under key and whether it was found
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set stores value under key until ttl has passed
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete removes the given keys; missing keys are ignored
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Options controls how long the decorators keep what they read
+type Options struct {
+	// TTL is how long a found entity is served from the cache
+	TTL time.Duration
+	// NegativeTTL is how long a lookup that found nothing is served from the cache; zero disables it
+	NegativeTTL time.Duration
+}
+
+// productKey is the cache key of a product
+// Keys are prefixed with the tenant of the context, so tenants never share entries
+func productKey(ctx context.Context, id string) string {
+	return tenant.ID(ctx) + ":product:" + id
+}
+
+// inventoryKey is the cache key of a product's inventory
+func inventoryKey(ctx context.Context, productID string) string {
+	return tenant.ID(ctx) + ":inventory:" + productID
+}
+
+// loader reads entities through a cache
+// Concurrent misses of one key share a single repository call, and lookups that
+// found nothing are cached as an empty value for NegativeTTL.
+type loader struct {
+	cache   Cache
+	options Options
+	group   singleflight.Group
+}
+
+// get returns the encoded entity under key, calling load on a miss
+// A nil result means the entity does not exist. Contexts that must read from the
+// primary database bypass the cache, so writes and read-your-writes reads never see
+// a cached value.
+func (l *loader) get(ctx context.Context, key string, load func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	if readconsistency.RequiresPrimary(ctx) {
+		return load(ctx)
+	}
+
+	if value, found, err := l.cache.Get(ctx, key); err != nil {
+		log.Printf("Cache read of %s failed, reading from the repository: %v", key, err)
+	} else if found {
+		return nilIfEmpty(value), nil
+	}
+
+	// The shared load outlives a caller that gives up, so it is not cancelled with it
+	result := l.group.DoChan(key, func() (interface{}, error) {
+		value, err := load(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+		l.store(ctx, key, value)
+		return value, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return nilIfEmpty(res.Val.([]byte)), nil
+	}
+}
+
+// store caches a loaded value, or the absence of one
+func (l *loader) store(ctx context.Context, key string, value []byte) {
+	ttl := l.options.TTL
+	if len(value) == 0 {
+		ttl = l.options.NegativeTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+	if err := l.cache.Set(context.WithoutCancel(ctx), key, value, ttl); err != nil {
+		log.Printf("Cache write of %s failed: %v", key, err)
+	}
+}
+
+// invalidate drops cached entries after a write
+// A failed delete is logged; the entry then expires with its TTL
+func invalidate(ctx context.Context, c Cache, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	if err := c.Delete(context.WithoutCancel(ctx), keys...); err != nil {
+		log.Printf("Cache invalidation of %v failed: %v", keys, err)
+	}
+}
+
+// nilIfEmpty turns the cached marker for a missing entity into nil
+func nilIfEmpty(value []byte) []byte {
+	if len(value) == 0 {
+		return nil
+	}
+	return value
+}
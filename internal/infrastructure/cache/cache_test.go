@@ -0,0 +1,387 @@
+package cache_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/cache"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/memory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/readconsistency"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+var options = cache.Options{TTL: time.Minute, NegativeTTL: time.Minute}
+
+// countingProducts counts the lookups that reach the wrapped repository
+type countingProducts struct {
+	product.ProductQueryRepository
+	calls atomic.Int32
+	gate  chan struct{} // when set, lookups wait for it to close
+}
+
+func (r *countingProducts) GetByID(ctx context.Context, id string) (*product.Product, error) {
+	r.calls.Add(1)
+	if r.gate != nil {
+		<-r.gate
+	}
+	return r.ProductQueryRepository.GetByID(ctx, id)
+}
+
+func (r *countingProducts) GetByIDs(ctx context.Context, ids []string) ([]*product.Product, error) {
+	r.calls.Add(1)
+	return r.ProductQueryRepository.GetByIDs(ctx, ids)
+}
+
+// countingInventory counts the lookups that reach the wrapped repository
+type countingInventory struct {
+	inventory.InventoryQueryRepository
+	calls atomic.Int32
+}
+
+func (r *countingInventory) GetByProductID(ctx context.Context, productID string) (*inventory.Inventory, error) {
+	r.calls.Add(1)
+	return r.InventoryQueryRepository.GetByProductID(ctx, productID)
+}
+
+func (r *countingInventory) GetByProductIDs(ctx context.Context, productIDs []string) ([]*inventory.Inventory, error) {
+	r.calls.Add(1)
+	return r.InventoryQueryRepository.GetByProductIDs(ctx, productIDs)
+}
+
+func newProduct(t *testing.T, id string) *product.Product {
+	t.Helper()
+	price, err := product.NewPrice(10, "USD")
+	if err != nil {
+		t.Fatalf("NewPrice() error = %v", err)
+	}
+	p, err := product.NewProduct(id, "Product "+id, price)
+	if err != nil {
+		t.Fatalf("NewProduct() error = %v", err)
+	}
+	return p
+}
+
+// caches runs a test against every Cache implementation
+func caches(t *testing.T, test func(t *testing.T, c cache.Cache)) {
+	t.Run("LRU", func(t *testing.T) { test(t, cache.NewLRU(100)) })
+	t.Run("Redis", func(t *testing.T) {
+		server := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { client.Close() })
+		test(t, cache.NewRedis(client, "test:"))
+	})
+}
+
+func TestCache_SetGetDelete(t *testing.T) {
+	caches(t, func(t *testing.T, c cache.Cache) {
+		ctx := context.Background()
+		if _, found, err := c.Get(ctx, "a"); found || err != nil {
+			t.Fatalf("Get() of missing key = %v, %v", found, err)
+		}
+		if err := c.Set(ctx, "a", []byte("1"), time.Minute); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+		if err := c.Set(ctx, "b", []byte{}, time.Minute); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+		if value, found, err := c.Get(ctx, "a"); !found || err != nil || string(value) != "1" {
+			t.Fatalf("Get() = %q, %v, %v; want \"1\"", value, found, err)
+		}
+		if value, found, _ := c.Get(ctx, "b"); !found || len(value) != 0 {
+			t.Fatalf("Get() of empty value = %q, %v; want found and empty", value, found)
+		}
+		if err := c.Delete(ctx, "a", "b", "missing"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, found, _ := c.Get(ctx, "a"); found {
+			t.Fatal("Get() after Delete() found the key")
+		}
+	})
+}
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewLRU(2)
+	c.Set(ctx, "a", []byte("1"), time.Minute)
+	c.Set(ctx, "b", []byte("2"), time.Minute)
+	c.Get(ctx, "a") // b is now the least recently used
+	c.Set(ctx, "c", []byte("3"), time.Minute)
+
+	if _, found, _ := c.Get(ctx, "b"); found {
+		t.Error("least recently used entry was not evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, found, _ := c.Get(ctx, key); !found {
+			t.Errorf("entry %s was evicted", key)
+		}
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", c.Len())
+	}
+}
+
+func TestLRU_ExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewLRU(10)
+	c.Set(ctx, "a", []byte("1"), 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+
+	if _, found, _ := c.Get(ctx, "a"); found {
+		t.Fatal("expired entry was served")
+	}
+}
+
+func TestProductQueryRepository_CachesLookups(t *testing.T) {
+	caches(t, func(t *testing.T, c cache.Cache) {
+		ctx := context.Background()
+		db := memory.NewDatabase()
+		memory.NewProductCommandRepository(db).Create(ctx, newProduct(t, "p-1"))
+		inner := &countingProducts{ProductQueryRepository: memory.NewProductQueryRepository(db)}
+		repo := cache.NewProductQueryRepository(inner, c, options)
+
+		for i := 0; i < 3; i++ {
+			p, err := repo.GetByID(ctx, "p-1")
+			if err != nil || p == nil || p.Name() != "Product p-1" {
+				t.Fatalf("GetByID() = %v, %v", p, err)
+			}
+		}
+		if got := inner.calls.Load(); got != 1 {
+			t.Fatalf("repository called %d times, want 1", got)
+		}
+
+		// Each hit is a separate instance
+		a, _ := repo.GetByID(ctx, "p-1")
+		b, _ := repo.GetByID(ctx, "p-1")
+		if a == b {
+			t.Fatal("cache hits share one product instance")
+		}
+	})
+}
+
+func TestProductQueryRepository_TenantsDoNotShareEntries(t *testing.T) {
+	caches(t, func(t *testing.T, c cache.Cache) {
+		acme := tenant.NewContext(context.Background(), tenant.Tenant{ID: "acme"})
+		globex := tenant.NewContext(context.Background(), tenant.Tenant{ID: "globex"})
+		db := memory.NewDatabase()
+		memory.NewProductCommandRepository(db).Create(acme, newProduct(t, "p-1"))
+		repo := cache.NewProductQueryRepository(memory.NewProductQueryRepository(db), c, options)
+
+		if p, err := repo.GetByID(acme, "p-1"); err != nil || p == nil {
+			t.Fatalf("GetByID() for acme = %v, %v", p, err)
+		}
+		if p, err := repo.GetByID(globex, "p-1"); err != nil || p != nil {
+			t.Fatalf("GetByID() for globex = %v, %v, want nil", p, err)
+		}
+	})
+}
+
+func TestProductQueryRepository_CachesNotFound(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingProducts{ProductQueryRepository: memory.NewProductQueryRepository(memory.NewDatabase())}
+	repo := cache.NewProductQueryRepository(inner, cache.NewLRU(10), options)
+
+	for i := 0; i < 2; i++ {
+		if p, err := repo.GetByID(ctx, "missing"); p != nil || err != nil {
+			t.Fatalf("GetByID() = %v, %v; want nil, nil", p, err)
+		}
+	}
+	if got := inner.calls.Load(); got != 1 {
+		t.Fatalf("repository called %d times, want 1", got)
+	}
+
+	noNegative := cache.NewProductQueryRepository(inner, cache.NewLRU(10), cache.Options{TTL: time.Minute})
+	noNegative.GetByID(ctx, "missing")
+	noNegative.GetByID(ctx, "missing")
+	if got := inner.calls.Load(); got != 3 {
+		t.Fatalf("repository called %d times without negative caching, want 3", got)
+	}
+}
+
+func TestProductQueryRepository_SharesConcurrentMisses(t *testing.T) {
+	ctx := context.Background()
+	db := memory.NewDatabase()
+	memory.NewProductCommandRepository(db).Create(ctx, newProduct(t, "p-1"))
+	inner := &countingProducts{ProductQueryRepository: memory.NewProductQueryRepository(db), gate: make(chan struct{})}
+	repo := cache.NewProductQueryRepository(inner, cache.NewLRU(10), options)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if p, err := repo.GetByID(ctx, "p-1"); err != nil || p == nil {
+				errs <- err
+			}
+		}()
+	}
+	// Let the callers pile up on the first lookup before releasing it
+	time.Sleep(50 * time.Millisecond)
+	close(inner.gate)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if got := inner.calls.Load(); got != 1 {
+		t.Fatalf("repository called %d times for concurrent misses, want 1", got)
+	}
+}
+
+func TestProductQueryRepository_PrimaryReadsBypassCache(t *testing.T) {
+	ctx := readconsistency.WithPrimary(context.Background())
+	db := memory.NewDatabase()
+	memory.NewProductCommandRepository(db).Create(ctx, newProduct(t, "p-1"))
+	inner := &countingProducts{ProductQueryRepository: memory.NewProductQueryRepository(db)}
+	repo := cache.NewProductQueryRepository(inner, cache.NewLRU(10), options)
+
+	repo.GetByID(ctx, "p-1")
+	repo.GetByID(ctx, "p-1")
+	if got := inner.calls.Load(); got != 2 {
+		t.Fatalf("repository called %d times, want 2", got)
+	}
+}
+
+func TestProductQueryRepository_GetByIDsMixesCachedAndLoaded(t *testing.T) {
+	ctx := context.Background()
+	db := memory.NewDatabase()
+	commands := memory.NewProductCommandRepository(db)
+	for _, id := range []string{"p-1", "p-2", "p-3"} {
+		commands.Create(ctx, newProduct(t, id))
+	}
+	inner := &countingProducts{ProductQueryRepository: memory.NewProductQueryRepository(db)}
+	repo := cache.NewProductQueryRepository(inner, cache.NewLRU(10), options)
+
+	repo.GetByID(ctx, "p-2")
+	products, err := repo.GetByIDs(ctx, []string{"p-3", "p-2", "missing", "p-1", "p-3"})
+	if err != nil {
+		t.Fatalf("GetByIDs() error = %v", err)
+	}
+	var ids []string
+	for _, p := range products {
+		ids = append(ids, p.ID())
+	}
+	if len(ids) != 3 || ids[0] != "p-3" || ids[1] != "p-2" || ids[2] != "p-1" {
+		t.Fatalf("GetByIDs() = %v, want [p-3 p-2 p-1]", ids)
+	}
+
+	// Everything, including the unknown ID, is cached now
+	before := inner.calls.Load()
+	if _, err := repo.GetByIDs(ctx, []string{"p-1", "p-2", "p-3", "missing"}); err != nil {
+		t.Fatalf("GetByIDs() error = %v", err)
+	}
+	if got := inner.calls.Load(); got != before {
+		t.Fatalf("repository called %d more times for cached IDs", got-before)
+	}
+}
+
+func TestProductCommandRepository_InvalidatesOnWrite(t *testing.T) {
+	ctx := context.Background()
+	db := memory.NewDatabase()
+	c := cache.NewLRU(10)
+	commands := cache.NewProductCommandRepository(memory.NewProductCommandRepository(db), c)
+	repo := cache.NewProductQueryRepository(memory.NewProductQueryRepository(db), c, options)
+
+	if p, _ := repo.GetByID(ctx, "p-1"); p != nil {
+		t.Fatal("GetByID() found a product before it was created")
+	}
+	if err := commands.Create(ctx, newProduct(t, "p-1")); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	p, _ := repo.GetByID(ctx, "p-1")
+	if p == nil {
+		t.Fatal("cached not-found survived Create()")
+	}
+
+	if err := p.UpdateName("Renamed"); err != nil {
+		t.Fatalf("UpdateName() error = %v", err)
+	}
+	if err := commands.Update(ctx, p); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got, _ := repo.GetByID(ctx, "p-1"); got == nil || got.Name() != "Renamed" {
+		t.Fatalf("GetByID() after Update() = %v, want the renamed product", got)
+	}
+}
+
+func TestInventoryQueryRepository_GetByProductIDsMixesCachedAndLoaded(t *testing.T) {
+	ctx := context.Background()
+	db := memory.NewDatabase()
+	for _, id := range []string{"p-1", "p-2"} {
+		memory.NewProductCommandRepository(db).Create(ctx, newProduct(t, id))
+		inv, err := inventory.NewInventory("inv-"+id, id, 10, "A1")
+		if err != nil {
+			t.Fatalf("NewInventory() error = %v", err)
+		}
+		memory.NewInventoryCommandRepository(db).Create(ctx, inv)
+	}
+	inner := &countingInventory{InventoryQueryRepository: memory.NewInventoryQueryRepository(db)}
+	repo := cache.NewInventoryQueryRepository(inner, cache.NewLRU(10), options)
+
+	repo.GetByProductID(ctx, "p-1")
+	inventories, err := repo.GetByProductIDs(ctx, []string{"p-2", "missing", "p-1"})
+	if err != nil {
+		t.Fatalf("GetByProductIDs() error = %v", err)
+	}
+	if len(inventories) != 2 || inventories[0].ProductID() != "p-2" || inventories[1].ProductID() != "p-1" {
+		t.Fatalf("GetByProductIDs() returned %d inventories, want p-2 and p-1", len(inventories))
+	}
+
+	// Everything, including the product without inventory, is cached now
+	before := inner.calls.Load()
+	if _, err := repo.GetByProductIDs(ctx, []string{"p-1", "p-2", "missing"}); err != nil {
+		t.Fatalf("GetByProductIDs() error = %v", err)
+	}
+	if got := inner.calls.Load(); got != before {
+		t.Fatalf("repository called %d more times for cached product IDs", got-before)
+	}
+}
+
+func TestUnitOfWork_InvalidatesInventoryWrittenInTransaction(t *testing.T) {
+	ctx := context.Background()
+	db := memory.NewDatabase()
+	c := cache.NewLRU(10)
+	memory.NewProductCommandRepository(db).Create(ctx, newProduct(t, "p-1"))
+	inv, err := inventory.NewInventory("inv-1", "p-1", 10, "A1")
+	if err != nil {
+		t.Fatalf("NewInventory() error = %v", err)
+	}
+	memory.NewInventoryCommandRepository(db).Create(ctx, inv)
+
+	inner := &countingInventory{InventoryQueryRepository: memory.NewInventoryQueryRepository(db)}
+	queries := cache.NewInventoryQueryRepository(inner, c, options)
+	uow := cache.NewUnitOfWork(memory.NewUnitOfWork(db), c)
+
+	if got, _ := queries.GetByProductID(ctx, "p-1"); got == nil || got.Quantity() != 10 {
+		t.Fatalf("GetByProductID() = %v, want quantity 10", got)
+	}
+
+	err = uow.Do(ctx, func(ctx context.Context, repos inventory.TxRepositories) error {
+		if err := repos.InventoryCommands.AdjustStock(ctx, "p-1", 5); err != nil {
+			return err
+		}
+		// Still cached until the unit of work ends
+		if got, _ := queries.GetByProductID(context.Background(), "p-1"); got.Quantity() != 10 {
+			t.Errorf("cached inventory changed inside the transaction: %d", got.Quantity())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	if got, _ := queries.GetByProductID(ctx, "p-1"); got == nil || got.Quantity() != 15 {
+		t.Fatalf("GetByProductID() after the unit of work = %v, want quantity 15", got)
+	}
+	if calls := inner.calls.Load(); calls != 2 {
+		t.Fatalf("repository called %d times, want 2", calls)
+	}
+}
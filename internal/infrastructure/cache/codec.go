@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+)
+
+// Entities are cached as JSON snapshots and rebuilt on every hit, so callers
+// never share an instance. An empty value records that the entity does not exist.
+
+// productSnapshot is the cached form of a product
+type productSnapshot struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	PriceAmount   float64   `json:"price_amount"`
+	PriceCurrency string    `json:"price_currency"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Version       int       `json:"version"`
+}
+
+// encodeProduct encodes a product, or its absence when p is nil
+func encodeProduct(p *product.Product) ([]byte, error) {
+	if p == nil {
+		return []byte{}, nil
+	}
+	return json.Marshal(productSnapshot{
+		ID:            p.ID(),
+		Name:          p.Name(),
+		PriceAmount:   p.Price().Amount(),
+		PriceCurrency: p.Price().Currency(),
+		CreatedAt:     p.CreatedAt(),
+		UpdatedAt:     p.UpdatedAt(),
+		Version:       p.Version(),
+	})
+}
+
+// decodeProduct rebuilds a product; a nil value decodes to nil
+func decodeProduct(value []byte) (*product.Product, error) {
+	if value == nil {
+		return nil, nil
+	}
+	var s productSnapshot
+	if err := json.Unmarshal(value, &s); err != nil {
+		return nil, err
+	}
+	price, err := product.NewPrice(s.PriceAmount, s.PriceCurrency)
+	if err != nil {
+		return nil, err
+	}
+	return product.ReconstructProduct(s.ID, s.Name, price, s.CreatedAt, s.UpdatedAt, s.Version), nil
+}
+
+// inventorySnapshot is the cached form of an inventory
+type inventorySnapshot struct {
+	ID                  string            `json:"id"`
+	ProductID           string            `json:"product_id"`
+	Quantity            int               `json:"quantity"`
+	ReservedQuantity    int               `json:"reserved_quantity"`
+	BackorderedQuantity int               `json:"backordered_quantity"`
+	StockPolicy         string            `json:"stock_policy"`
+	BackorderLimit      int               `json:"backorder_limit"`
+	Location            string            `json:"location"`
+	Metadata            map[string]string `json:"metadata"`
+	CreatedAt           time.Time         `json:"created_at"`
+	UpdatedAt           time.Time         `json:"updated_at"`
+	Version             int               `json:"version"`
+}
+
+// encodeInventory encodes an inventory, or its absence when inv is nil
+func encodeInventory(inv *inventory.Inventory) ([]byte, error) {
+	if inv == nil {
+		return []byte{}, nil
+	}
+	return json.Marshal(inventorySnapshot{
+		ID:                  inv.ID(),
+		ProductID:           inv.ProductID(),
+		Quantity:            inv.Quantity(),
+		ReservedQuantity:    inv.ReservedQuantity(),
+		BackorderedQuantity: inv.BackorderedQuantity(),
+		StockPolicy:         string(inv.StockPolicy().Type()),
+		BackorderLimit:      inv.StockPolicy().BackorderLimit(),
+		Location:            inv.Location(),
+		Metadata:            inv.Metadata(),
+		CreatedAt:           inv.CreatedAt(),
+		UpdatedAt:           inv.UpdatedAt(),
+		Version:             inv.Version(),
+	})
+}
+
+// decodeInventory rebuilds an inventory; a nil value decodes to nil
+func decodeInventory(value []byte) (*inventory.Inventory, error) {
+	if value == nil {
+		return nil, nil
+	}
+	var s inventorySnapshot
+	if err := json.Unmarshal(value, &s); err != nil {
+		return nil, err
+	}
+	policy, err := inventory.NewStockPolicy(inventory.StockPolicyType(s.StockPolicy), s.BackorderLimit)
+	if err != nil {
+		return nil, err
+	}
+	return inventory.ReconstructInventory(
+		s.ID,
+		s.ProductID,
+		s.Quantity,
+		s.ReservedQuantity,
+		s.BackorderedQuantity,
+		policy,
+		s.Location,
+		s.Metadata,
+		s.CreatedAt,
+		s.UpdatedAt,
+		s.Version,
+	), nil
+}
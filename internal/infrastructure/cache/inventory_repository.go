@@ -0,0 +1,242 @@
+package cache
+
+import (
+	"context"
+	"sync"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/readconsistency"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// InventoryQueryRepository serves inventory lookups by product ID from a cache
+// Listing and counting are not cached and always read from the wrapped repository.
+type InventoryQueryRepository struct {
+	repo   inventory.InventoryQueryRepository
+	loader *loader
+}
+
+// NewInventoryQueryRepository wraps repo with cache
+func NewInventoryQueryRepository(repo inventory.InventoryQueryRepository, cache Cache, options Options) inventory.InventoryQueryRepository {
+	return &InventoryQueryRepository{repo: repo, loader: &loader{cache: cache, options: options}}
+}
+
+// GetByProductID retrieves the inventory of a product
+// Returns nil if the inventory is not found
+func (r *InventoryQueryRepository) GetByProductID(ctx context.Context, productID string) (*inventory.Inventory, error) {
+	value, err := r.loader.get(ctx, inventoryKey(ctx, productID), func(ctx context.Context) ([]byte, error) {
+		inv, err := r.repo.GetByProductID(ctx, productID)
+		if err != nil {
+			return nil, err
+		}
+		return encodeInventory(inv)
+	})
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	inv, err := decodeInventory(value)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.CodeInternalError, "failed to decode cached inventory")
+	}
+	return inv, nil
+}
+
+// GetByProductIDs retrieves the inventories of the given products, in the order asked for
+// Cached inventories are served from the cache and the rest are read in one call
+func (r *InventoryQueryRepository) GetByProductIDs(ctx context.Context, productIDs []string) ([]*inventory.Inventory, error) {
+	if readconsistency.RequiresPrimary(ctx) {
+		return r.repo.GetByProductIDs(ctx, productIDs)
+	}
+
+	found := make(map[string]*inventory.Inventory, len(productIDs))
+	missing := make(map[string]bool)
+	var misses []string
+	for _, productID := range productIDs {
+		if _, seen := found[productID]; seen || missing[productID] {
+			continue
+		}
+		value, ok, err := r.loader.cache.Get(ctx, inventoryKey(ctx, productID))
+		if err != nil || !ok {
+			missing[productID] = true
+			misses = append(misses, productID)
+			continue
+		}
+		inv, err := decodeInventory(nilIfEmpty(value))
+		if err != nil {
+			return nil, apperrors.Wrap(err, apperrors.CodeInternalError, "failed to decode cached inventory")
+		}
+		found[productID] = inv
+	}
+
+	if len(misses) > 0 {
+		loaded, err := r.repo.GetByProductIDs(ctx, misses)
+		if err != nil {
+			return nil, err
+		}
+		byProductID := make(map[string]*inventory.Inventory, len(loaded))
+		for _, inv := range loaded {
+			byProductID[inv.ProductID()] = inv
+		}
+		for _, productID := range misses {
+			inv := byProductID[productID]
+			value, err := encodeInventory(inv)
+			if err != nil {
+				return nil, apperrors.Wrap(err, apperrors.CodeInternalError, "failed to encode cached inventory")
+			}
+			r.loader.store(ctx, inventoryKey(ctx, productID), value)
+			found[productID] = inv
+		}
+	}
+
+	inventories := make([]*inventory.Inventory, 0, len(found))
+	for _, productID := range productIDs {
+		if inv := found[productID]; inv != nil {
+			inventories = append(inventories, inv)
+			found[productID] = nil // each inventory is returned once
+		}
+	}
+	return inventories, nil
+}
+
+// ListByLocation retrieves the inventories at a location from the wrapped repository
+func (r *InventoryQueryRepository) ListByLocation(ctx context.Context, location string) ([]*inventory.Inventory, error) {
+	return r.repo.ListByLocation(ctx, location)
+}
+
+// List retrieves the inventories matching a filter from the wrapped repository
+func (r *InventoryQueryRepository) List(ctx context.Context, filter inventory.InventoryFilter) ([]*inventory.Inventory, error) {
+	return r.repo.List(ctx, filter)
+}
+
+// Count counts the inventories matching a filter in the wrapped repository
+func (r *InventoryQueryRepository) Count(ctx context.Context, filter inventory.InventoryFilter) (int, error) {
+	return r.repo.Count(ctx, filter)
+}
+
+// InventoryCommandRepository drops cached inventories when they are written
+type InventoryCommandRepository struct {
+	repo  inventory.InventoryCommandRepository
+	cache Cache
+}
+
+// NewInventoryCommandRepository wraps repo so writes invalidate cache
+func NewInventoryCommandRepository(repo inventory.InventoryCommandRepository, cache Cache) inventory.InventoryCommandRepository {
+	return &InventoryCommandRepository{repo: repo, cache: cache}
+}
+
+// Create stores a new inventory, dropping a cached not-found entry for its product
+func (r *InventoryCommandRepository) Create(ctx context.Context, inv *inventory.Inventory) error {
+	defer invalidate(ctx, r.cache, inventoryKey(ctx, inv.ProductID()))
+	return r.repo.Create(ctx, inv)
+}
+
+// Update stores changes to an inventory
+func (r *InventoryCommandRepository) Update(ctx context.Context, inv *inventory.Inventory) error {
+	defer invalidate(ctx, r.cache, inventoryKey(ctx, inv.ProductID()))
+	return r.repo.Update(ctx, inv)
+}
+
+// UpdateBatch stores changes to several inventories
+func (r *InventoryCommandRepository) UpdateBatch(ctx context.Context, inventories []*inventory.Inventory) error {
+	defer invalidate(ctx, r.cache, inventoryKeys(ctx, inventories)...)
+	return r.repo.UpdateBatch(ctx, inventories)
+}
+
+// Delete removes the inventory of a product
+func (r *InventoryCommandRepository) Delete(ctx context.Context, inv *inventory.Inventory) error {
+	defer invalidate(ctx, r.cache, inventoryKey(ctx, inv.ProductID()))
+	return r.repo.Delete(ctx, inv)
+}
+
+// AdjustStock changes the quantity of a product's inventory
+func (r *InventoryCommandRepository) AdjustStock(ctx context.Context, productID string, adjustment int) error {
+	defer invalidate(ctx, r.cache, inventoryKey(ctx, productID))
+	return r.repo.AdjustStock(ctx, productID, adjustment)
+}
+
+// UnitOfWork drops the cached inventories written inside a unit of work once it finishes
+// Entries are dropped after the transaction ends, so a concurrent read cannot cache
+// uncommitted state in between.
+type UnitOfWork struct {
+	uow   inventory.UnitOfWork
+	cache Cache
+}
+
+// NewUnitOfWork wraps uow so its inventory writes invalidate cache
+func NewUnitOfWork(uow inventory.UnitOfWork, cache Cache) inventory.UnitOfWork {
+	return &UnitOfWork{uow: uow, cache: cache}
+}
+
+// Do runs fn with the configured default isolation level
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos inventory.TxRepositories) error) error {
+	written := &writtenKeys{}
+	defer func() { invalidate(ctx, u.cache, written.list()...) }()
+	return u.uow.Do(ctx, written.track(fn))
+}
+
+// DoWithIsolation runs fn with the given isolation level
+func (u *UnitOfWork) DoWithIsolation(ctx context.Context, level inventory.IsolationLevel, fn func(ctx context.Context, repos inventory.TxRepositories) error) error {
+	written := &writtenKeys{}
+	defer func() { invalidate(ctx, u.cache, written.list()...) }()
+	return u.uow.DoWithIsolation(ctx, level, written.track(fn))
+}
+
+// writtenKeys collects the cache keys written by a unit of work, across its retries
+type writtenKeys struct {
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+// track hands fn transaction-scoped repositories that record what they write
+func (w *writtenKeys) track(fn func(ctx context.Context, repos inventory.TxRepositories) error) func(ctx context.Context, repos inventory.TxRepositories) error {
+	return func(ctx context.Context, repos inventory.TxRepositories) error {
+		recorder := &recordingCache{keys: w}
+		repos.InventoryCommands = NewInventoryCommandRepository(repos.InventoryCommands, recorder)
+		return fn(ctx, repos)
+	}
+}
+
+// add records keys
+func (w *writtenKeys) add(keys ...string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.keys == nil {
+		w.keys = make(map[string]bool)
+	}
+	for _, key := range keys {
+		w.keys[key] = true
+	}
+}
+
+// list returns the recorded keys
+func (w *writtenKeys) list() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	keys := make([]string, 0, len(w.keys))
+	for key := range w.keys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// recordingCache defers invalidations issued inside a transaction until it ends
+type recordingCache struct {
+	Cache
+	keys *writtenKeys
+}
+
+// Delete records the keys instead of dropping them
+func (c *recordingCache) Delete(_ context.Context, keys ...string) error {
+	c.keys.add(keys...)
+	return nil
+}
+
+// inventoryKeys returns the cache keys of several inventories
+func inventoryKeys(ctx context.Context, inventories []*inventory.Inventory) []string {
+	keys := make([]string, 0, len(inventories))
+	for _, inv := range inventories {
+		keys = append(keys, inventoryKey(ctx, inv.ProductID()))
+	}
+	return keys
+}
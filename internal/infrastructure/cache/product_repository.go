@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/readconsistency"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// ProductQueryRepository serves product lookups by ID from a cache
+// List is not cached and always reads from the wrapped repository.
+type ProductQueryRepository struct {
+	repo   product.ProductQueryRepository
+	loader *loader
+}
+
+// NewProductQueryRepository wraps repo with cache
+func NewProductQueryRepository(repo product.ProductQueryRepository, cache Cache, options Options) product.ProductQueryRepository {
+	return &ProductQueryRepository{repo: repo, loader: &loader{cache: cache, options: options}}
+}
+
+// GetByID retrieves a product by its ID
+// Returns nil if the product is not found
+func (r *ProductQueryRepository) GetByID(ctx context.Context, id string) (*product.Product, error) {
+	value, err := r.loader.get(ctx, productKey(ctx, id), func(ctx context.Context) ([]byte, error) {
+		p, err := r.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return encodeProduct(p)
+	})
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	return decodeCachedProduct(value)
+}
+
+// GetByIDs retrieves the products with the given IDs, in the order asked for
+// Cached products are served from the cache and the rest are read in one call
+func (r *ProductQueryRepository) GetByIDs(ctx context.Context, ids []string) ([]*product.Product, error) {
+	if readconsistency.RequiresPrimary(ctx) {
+		return r.repo.GetByIDs(ctx, ids)
+	}
+
+	found := make(map[string]*product.Product, len(ids))
+	missing := make(map[string]bool)
+	var misses []string
+	for _, id := range ids {
+		if _, seen := found[id]; seen || missing[id] {
+			continue
+		}
+		value, ok, err := r.loader.cache.Get(ctx, productKey(ctx, id))
+		if err != nil || !ok {
+			missing[id] = true
+			misses = append(misses, id)
+			continue
+		}
+		p, err := decodeCachedProduct(nilIfEmpty(value))
+		if err != nil {
+			return nil, err
+		}
+		found[id] = p
+	}
+
+	if len(misses) > 0 {
+		loaded, err := r.repo.GetByIDs(ctx, misses)
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[string]*product.Product, len(loaded))
+		for _, p := range loaded {
+			byID[p.ID()] = p
+		}
+		for _, id := range misses {
+			p := byID[id]
+			value, err := encodeProduct(p)
+			if err != nil {
+				return nil, apperrors.Wrap(err, apperrors.CodeInternalError, "failed to encode cached product")
+			}
+			r.loader.store(ctx, productKey(ctx, id), value)
+			found[id] = p
+		}
+	}
+
+	products := make([]*product.Product, 0, len(found))
+	for _, id := range ids {
+		if p := found[id]; p != nil {
+			products = append(products, p)
+			found[id] = nil // each product is returned once
+		}
+	}
+	return products, nil
+}
+
+// List retrieves a page of products from the wrapped repository
+func (r *ProductQueryRepository) List(ctx context.Context, limit, offset int) ([]*product.Product, error) {
+	return r.repo.List(ctx, limit, offset)
+}
+
+// ProductCommandRepository drops cached products when they are written
+type ProductCommandRepository struct {
+	repo  product.ProductCommandRepository
+	cache Cache
+}
+
+// NewProductCommandRepository wraps repo so writes invalidate cache
+func NewProductCommandRepository(repo product.ProductCommandRepository, cache Cache) product.ProductCommandRepository {
+	return &ProductCommandRepository{repo: repo, cache: cache}
+}
+
+// Create stores a new product, dropping a cached not-found entry for its ID
+func (r *ProductCommandRepository) Create(ctx context.Context, p *product.Product) error {
+	defer invalidate(ctx, r.cache, productKey(ctx, p.ID()))
+	return r.repo.Create(ctx, p)
+}
+
+// Update stores changes to a product
+func (r *ProductCommandRepository) Update(ctx context.Context, p *product.Product) error {
+	defer invalidate(ctx, r.cache, productKey(ctx, p.ID()))
+	return r.repo.Update(ctx, p)
+}
+
+// Delete removes a product and, with it, its cached inventory
+func (r *ProductCommandRepository) Delete(ctx context.Context, id string) error {
+	defer invalidate(ctx, r.cache, productKey(ctx, id), inventoryKey(ctx, id))
+	return r.repo.Delete(ctx, id)
+}
+
+// ProductUnitOfWork drops the cached products written inside a unit of work once it finishes
+type ProductUnitOfWork struct {
+	uow   product.UnitOfWork
+	cache Cache
+}
+
+// NewProductUnitOfWork wraps uow so its product writes invalidate cache
+func NewProductUnitOfWork(uow product.UnitOfWork, cache Cache) product.UnitOfWork {
+	return &ProductUnitOfWork{uow: uow, cache: cache}
+}
+
+// Do runs fn with transaction-scoped repositories that record what they write
+func (u *ProductUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos product.TxRepositories) error) error {
+	written := &writtenKeys{}
+	defer func() { invalidate(ctx, u.cache, written.list()...) }()
+	return u.uow.Do(ctx, func(ctx context.Context, repos product.TxRepositories) error {
+		repos.ProductCommands = NewProductCommandRepository(repos.ProductCommands, &recordingCache{keys: written})
+		return fn(ctx, repos)
+	})
+}
+
+// decodeCachedProduct rebuilds a cached product, reporting corrupt entries as internal errors
+func decodeCachedProduct(value []byte) (*product.Product, error) {
+	p, err := decodeProduct(value)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.CodeInternalError, "failed to decode cached product")
+	}
+	return p, nil
+}
@@ -1,17 +1,55 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
 	"github.com/spf13/viper"
 )
 
+// Storage backends selectable through STORAGE
+const (
+	StorageSQL    = "sql"
+	StorageMemory = "memory"
+)
+
+// Database drivers selectable through DB_DRIVER
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Cache backends selectable through CACHE_BACKEND
+const (
+	CacheNone   = "none"
+	CacheMemory = "memory"
+	CacheRedis  = "redis"
+)
+
+// Outbox publishers selectable through OUTBOX_PUBLISHER
+const (
+	PublisherLog   = "log"
+	PublisherHTTP  = "http"
+	PublisherNATS  = "nats"
+	PublisherKafka = "kafka"
+)
+
 // Config holds all application configuration
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	App      AppConfig
+	Server      ServerConfig
+	Storage     StorageConfig
+	Database    DatabaseConfig
+	App         AppConfig
+	Inventory   InventoryConfig
+	Idempotency IdempotencyConfig
+	Cache       CacheConfig
+	Tenant      TenantConfig
+	Outbox      OutboxConfig
+	Webhook     WebhookConfig
 }
 
 // ServerConfig holds server-related configuration
@@ -20,14 +58,63 @@ type ServerConfig struct {
 	Port string
 }
 
+// StorageConfig selects where the application keeps its data
+type StorageConfig struct {
+	// Backend is "sql" to use the configured database or "memory" to keep everything in process
+	Backend string
+}
+
 // DatabaseConfig holds database-related configuration
 type DatabaseConfig struct {
+	// Driver is the SQL backend: "postgres" or "sqlite"
+	Driver string
+	// Path is the database file used by the sqlite driver
+	Path     string
 	Host     string
 	Port     string
 	User     string
 	Password string
 	Name     string
 	SSLMode  string
+	// TxIsolation is the default isolation level of units of work
+	TxIsolation string
+	// TxMaxAttempts bounds how often a transaction runs when it hits serialization failures
+	TxMaxAttempts int
+	// ReadReplicas are DSNs of PostgreSQL replicas that serve query repositories
+	ReadReplicas []string
+	// ReplicaMaxLag is how far behind the primary a replica may be and still serve reads
+	ReplicaMaxLag time.Duration
+	// ReplicaCheckInterval is how often replica health and lag are checked
+	ReplicaCheckInterval time.Duration
+	// ReadYourWritesWindow is how long a client's reads go to the primary after it writes
+	ReadYourWritesWindow time.Duration
+	// AutoMigrate applies pending embedded migrations on startup
+	AutoMigrate bool
+
+	// MaxOpenConns caps the connections open to the database, in use or idle
+	MaxOpenConns int
+	// MaxIdleConns caps the idle connections kept for reuse
+	MaxIdleConns int
+	// ConnMaxLifetime closes connections older than this; zero keeps them forever
+	ConnMaxLifetime time.Duration
+	// ConnMaxIdleTime closes connections idle for longer than this; zero keeps them forever
+	ConnMaxIdleTime time.Duration
+
+	// ConnectTimeout is how long startup keeps retrying to reach the database
+	ConnectTimeout time.Duration
+	// ConnectBackoff is the wait before the first connection retry; it doubles after each failure
+	ConnectBackoff time.Duration
+	// ConnectMaxBackoff caps the wait between connection retries
+	ConnectMaxBackoff time.Duration
+
+	// QueryTimeout bounds every repository call; zero disables it
+	QueryTimeout time.Duration
+	// StatementTimeout is the PostgreSQL statement_timeout of server connections; zero disables it
+	StatementTimeout time.Duration
+
+	// RowLevelSecurity sets the request's tenant on every PostgreSQL connection for the
+	// tenant_isolation policies; they only apply when DB_USER does not own the tables
+	RowLevelSecurity bool
 }
 
 // AppConfig holds application-related configuration
@@ -36,19 +123,175 @@ type AppConfig struct {
 	LogLevel string
 }
 
+// InventoryConfig holds inventory-related configuration
+type InventoryConfig struct {
+	CostingMethod string
+	// EventSourced rebuilds inventories from their event streams, keeping the inventory table as their projection
+	EventSourced bool
+	// SnapshotEvery is how many events of a stream are recorded between snapshots
+	SnapshotEvery int
+}
+
+// IdempotencyConfig holds configuration for Idempotency-Key handling
+type IdempotencyConfig struct {
+	// TTL is how long a stored response is replayed for repeats of its key
+	TTL time.Duration
+}
+
+// CacheConfig holds configuration for caching product and inventory reads
+type CacheConfig struct {
+	// Backend is "none", "memory" for an in-process LRU or "redis" for a cache shared by all instances
+	Backend string
+	// TTL is how long a product or inventory is served from the cache
+	TTL time.Duration
+	// NegativeTTL is how long a lookup that found nothing is served from the cache
+	NegativeTTL time.Duration
+	// Size is how many entries the in-process cache holds
+	Size int
+	// RedisAddr is the host:port of the Redis server
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+	// KeyPrefix namespaces the Redis keys of this application
+	KeyPrefix string
+}
+
+// TenantConfig holds configuration for serving several tenants
+type TenantConfig struct {
+	// Required rejects requests that name no tenant instead of serving them as the default tenant
+	Required bool
+	// Defaults are the settings of tenants that leave them empty
+	Defaults tenant.Settings
+	// Tenants maps the known tenant IDs to their settings; when empty any valid tenant ID is accepted
+	Tenants map[string]tenant.Settings
+}
+
+// OutboxConfig holds configuration for relaying stored domain events to a message broker
+type OutboxConfig struct {
+	// Publisher is "log", "http" to POST each event, "nats" or "kafka" through its REST Proxy
+	Publisher string
+	// HTTPURL receives every event as a JSON POST
+	HTTPURL string
+	// NATSURL is the nats://host:port of the NATS server
+	NATSURL string
+	// NATSSubjectPrefix is prepended to event names to form NATS subjects
+	NATSSubjectPrefix string
+	// KafkaRESTURL is the base URL of the Kafka REST Proxy
+	KafkaRESTURL string
+	// KafkaTopic receives every event, keyed by the aggregate that raised it
+	KafkaTopic string
+	// PollInterval is how long the relay waits when no event is due
+	PollInterval time.Duration
+	// BatchSize is how many events the relay claims at a time
+	BatchSize int
+	// MaxAttempts is how many times an event is published before it is dead-lettered
+	MaxAttempts int
+	// RetryBaseDelay is the delay after the first failure; it doubles with every failure up to RetryMaxDelay
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// Lease is how long a claimed event is reserved for the relay that claimed it
+	Lease time.Duration
+	// PublishTimeout bounds each attempt to publish an event
+	PublishTimeout time.Duration
+	// Retention is how long published events are kept; zero keeps them forever
+	Retention time.Duration
+}
+
+// WebhookConfig holds configuration for delivering domain events to webhook subscriptions
+type WebhookConfig struct {
+	// Enabled hands every event to the matching webhook subscriptions
+	Enabled bool
+	// PollInterval is how long the dispatcher waits when no delivery is due
+	PollInterval time.Duration
+	// BatchSize is how many deliveries the dispatcher claims at a time
+	BatchSize int
+	// MaxAttempts is how many times a delivery is sent before it fails for good
+	MaxAttempts int
+	// RetryBaseDelay is the delay after the first failure; it doubles with every failure up to RetryMaxDelay
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// Lease is how long a claimed delivery is reserved for the dispatcher that claimed it
+	Lease time.Duration
+	// Timeout bounds each attempt to deliver an event
+	Timeout time.Duration
+	// DisableAfter is how many failed attempts in a row disable a subscription; zero never disables one
+	DisableAfter int
+	// Retention is how long delivered deliveries are kept; zero keeps them forever
+	Retention time.Duration
+}
+
 // Load loads configuration from environment variables and config files
 func Load() (*Config, error) {
 	// Set default values
 	viper.SetDefault("SERVER_HOST", "0.0.0.0")
 	viper.SetDefault("SERVER_PORT", "8080")
+	viper.SetDefault("STORAGE", StorageSQL)
+	viper.SetDefault("DB_DRIVER", DriverPostgres)
+	viper.SetDefault("DB_PATH", "cleanarch.db")
 	viper.SetDefault("DB_HOST", "localhost")
 	viper.SetDefault("DB_PORT", "5432")
 	viper.SetDefault("DB_USER", "postgres")
 	viper.SetDefault("DB_PASSWORD", "postgres")
 	viper.SetDefault("DB_NAME", "cleanarch")
 	viper.SetDefault("DB_SSLMODE", "disable")
+	viper.SetDefault("DB_TX_ISOLATION", "read_committed")
+	viper.SetDefault("DB_TX_MAX_ATTEMPTS", 3)
+	viper.SetDefault("DB_READ_REPLICAS", "")
+	viper.SetDefault("DB_REPLICA_MAX_LAG", "5s")
+	viper.SetDefault("DB_REPLICA_CHECK_INTERVAL", "5s")
+	viper.SetDefault("DB_READ_YOUR_WRITES_WINDOW", "5s")
+	viper.SetDefault("DB_AUTO_MIGRATE", false)
+	viper.SetDefault("DB_MAX_OPEN_CONNS", 25)
+	viper.SetDefault("DB_MAX_IDLE_CONNS", 5)
+	viper.SetDefault("DB_CONN_MAX_LIFETIME", "30m")
+	viper.SetDefault("DB_CONN_MAX_IDLE_TIME", "5m")
+	viper.SetDefault("DB_CONNECT_TIMEOUT", "30s")
+	viper.SetDefault("DB_CONNECT_BACKOFF", "250ms")
+	viper.SetDefault("DB_CONNECT_MAX_BACKOFF", "5s")
+	viper.SetDefault("DB_QUERY_TIMEOUT", "5s")
+	viper.SetDefault("DB_STATEMENT_TIMEOUT", "10s")
+	viper.SetDefault("DB_ROW_LEVEL_SECURITY", false)
 	viper.SetDefault("APP_ENV", "development")
 	viper.SetDefault("LOG_LEVEL", "debug")
+	viper.SetDefault("INVENTORY_COSTING_METHOD", "fifo")
+	viper.SetDefault("INVENTORY_EVENT_SOURCED", false)
+	viper.SetDefault("INVENTORY_SNAPSHOT_EVERY", 50)
+	viper.SetDefault("IDEMPOTENCY_TTL", "24h")
+	viper.SetDefault("CACHE_BACKEND", CacheMemory)
+	viper.SetDefault("CACHE_TTL", "1m")
+	viper.SetDefault("CACHE_NEGATIVE_TTL", "5s")
+	viper.SetDefault("CACHE_SIZE", 10000)
+	viper.SetDefault("CACHE_REDIS_ADDR", "localhost:6379")
+	viper.SetDefault("CACHE_REDIS_PASSWORD", "")
+	viper.SetDefault("CACHE_REDIS_DB", 0)
+	viper.SetDefault("CACHE_KEY_PREFIX", "cleanarch:")
+	viper.SetDefault("TENANT_REQUIRED", false)
+	viper.SetDefault("TENANT_DEFAULT_CURRENCY", "")
+	viper.SetDefault("TENANTS", "")
+	viper.SetDefault("OUTBOX_PUBLISHER", PublisherLog)
+	viper.SetDefault("OUTBOX_HTTP_URL", "")
+	viper.SetDefault("OUTBOX_NATS_URL", "nats://localhost:4222")
+	viper.SetDefault("OUTBOX_NATS_SUBJECT_PREFIX", "cleanarch.")
+	viper.SetDefault("OUTBOX_KAFKA_REST_URL", "http://localhost:8082")
+	viper.SetDefault("OUTBOX_KAFKA_TOPIC", "cleanarch-events")
+	viper.SetDefault("OUTBOX_POLL_INTERVAL", "1s")
+	viper.SetDefault("OUTBOX_BATCH_SIZE", 100)
+	viper.SetDefault("OUTBOX_MAX_ATTEMPTS", 10)
+	viper.SetDefault("OUTBOX_RETRY_BASE_DELAY", "1s")
+	viper.SetDefault("OUTBOX_RETRY_MAX_DELAY", "5m")
+	viper.SetDefault("OUTBOX_LEASE", "30s")
+	viper.SetDefault("OUTBOX_PUBLISH_TIMEOUT", "10s")
+	viper.SetDefault("OUTBOX_RETENTION", "168h")
+	viper.SetDefault("WEBHOOK_ENABLED", true)
+	viper.SetDefault("WEBHOOK_POLL_INTERVAL", "1s")
+	viper.SetDefault("WEBHOOK_BATCH_SIZE", 50)
+	viper.SetDefault("WEBHOOK_MAX_ATTEMPTS", 8)
+	viper.SetDefault("WEBHOOK_RETRY_BASE_DELAY", "30s")
+	viper.SetDefault("WEBHOOK_RETRY_MAX_DELAY", "1h")
+	viper.SetDefault("WEBHOOK_LEASE", "5m")
+	viper.SetDefault("WEBHOOK_TIMEOUT", "10s")
+	viper.SetDefault("WEBHOOK_DISABLE_AFTER", 20)
+	viper.SetDefault("WEBHOOK_RETENTION", "720h")
 
 	// Enable reading from environment variables
 	viper.AutomaticEnv()
@@ -57,7 +300,7 @@ func Load() (*Config, error) {
 	viper.SetConfigName(".env")
 	viper.SetConfigType("env")
 	viper.AddConfigPath(".")
-	
+
 	// It's okay if .env file doesn't exist
 	_ = viper.ReadInConfig()
 
@@ -66,18 +309,161 @@ func Load() (*Config, error) {
 			Host: viper.GetString("SERVER_HOST"),
 			Port: viper.GetString("SERVER_PORT"),
 		},
+		Storage: StorageConfig{
+			Backend: viper.GetString("STORAGE"),
+		},
 		Database: DatabaseConfig{
-			Host:     viper.GetString("DB_HOST"),
-			Port:     viper.GetString("DB_PORT"),
-			User:     viper.GetString("DB_USER"),
-			Password: viper.GetString("DB_PASSWORD"),
-			Name:     viper.GetString("DB_NAME"),
-			SSLMode:  viper.GetString("DB_SSLMODE"),
+			Driver:        viper.GetString("DB_DRIVER"),
+			Path:          viper.GetString("DB_PATH"),
+			Host:          viper.GetString("DB_HOST"),
+			Port:          viper.GetString("DB_PORT"),
+			User:          viper.GetString("DB_USER"),
+			Password:      viper.GetString("DB_PASSWORD"),
+			Name:          viper.GetString("DB_NAME"),
+			SSLMode:       viper.GetString("DB_SSLMODE"),
+			TxIsolation:   viper.GetString("DB_TX_ISOLATION"),
+			TxMaxAttempts: viper.GetInt("DB_TX_MAX_ATTEMPTS"),
+
+			ReadReplicas:         splitList(viper.GetString("DB_READ_REPLICAS")),
+			ReplicaMaxLag:        viper.GetDuration("DB_REPLICA_MAX_LAG"),
+			ReplicaCheckInterval: viper.GetDuration("DB_REPLICA_CHECK_INTERVAL"),
+			ReadYourWritesWindow: viper.GetDuration("DB_READ_YOUR_WRITES_WINDOW"),
+			AutoMigrate:          viper.GetBool("DB_AUTO_MIGRATE"),
+
+			MaxOpenConns:    viper.GetInt("DB_MAX_OPEN_CONNS"),
+			MaxIdleConns:    viper.GetInt("DB_MAX_IDLE_CONNS"),
+			ConnMaxLifetime: viper.GetDuration("DB_CONN_MAX_LIFETIME"),
+			ConnMaxIdleTime: viper.GetDuration("DB_CONN_MAX_IDLE_TIME"),
+
+			ConnectTimeout:    viper.GetDuration("DB_CONNECT_TIMEOUT"),
+			ConnectBackoff:    viper.GetDuration("DB_CONNECT_BACKOFF"),
+			ConnectMaxBackoff: viper.GetDuration("DB_CONNECT_MAX_BACKOFF"),
+
+			QueryTimeout:     viper.GetDuration("DB_QUERY_TIMEOUT"),
+			StatementTimeout: viper.GetDuration("DB_STATEMENT_TIMEOUT"),
+
+			RowLevelSecurity: viper.GetBool("DB_ROW_LEVEL_SECURITY"),
 		},
 		App: AppConfig{
 			Env:      viper.GetString("APP_ENV"),
 			LogLevel: viper.GetString("LOG_LEVEL"),
 		},
+		Inventory: InventoryConfig{
+			CostingMethod: viper.GetString("INVENTORY_COSTING_METHOD"),
+			EventSourced:  viper.GetBool("INVENTORY_EVENT_SOURCED"),
+			SnapshotEvery: viper.GetInt("INVENTORY_SNAPSHOT_EVERY"),
+		},
+		Idempotency: IdempotencyConfig{
+			TTL: viper.GetDuration("IDEMPOTENCY_TTL"),
+		},
+		Cache: CacheConfig{
+			Backend:       viper.GetString("CACHE_BACKEND"),
+			TTL:           viper.GetDuration("CACHE_TTL"),
+			NegativeTTL:   viper.GetDuration("CACHE_NEGATIVE_TTL"),
+			Size:          viper.GetInt("CACHE_SIZE"),
+			RedisAddr:     viper.GetString("CACHE_REDIS_ADDR"),
+			RedisPassword: viper.GetString("CACHE_REDIS_PASSWORD"),
+			RedisDB:       viper.GetInt("CACHE_REDIS_DB"),
+			KeyPrefix:     viper.GetString("CACHE_KEY_PREFIX"),
+		},
+		Tenant: TenantConfig{
+			Required: viper.GetBool("TENANT_REQUIRED"),
+			Defaults: tenant.Settings{
+				DefaultCurrency: viper.GetString("TENANT_DEFAULT_CURRENCY"),
+			},
+		},
+		Outbox: OutboxConfig{
+			Publisher:         viper.GetString("OUTBOX_PUBLISHER"),
+			HTTPURL:           viper.GetString("OUTBOX_HTTP_URL"),
+			NATSURL:           viper.GetString("OUTBOX_NATS_URL"),
+			NATSSubjectPrefix: viper.GetString("OUTBOX_NATS_SUBJECT_PREFIX"),
+			KafkaRESTURL:      viper.GetString("OUTBOX_KAFKA_REST_URL"),
+			KafkaTopic:        viper.GetString("OUTBOX_KAFKA_TOPIC"),
+			PollInterval:      viper.GetDuration("OUTBOX_POLL_INTERVAL"),
+			BatchSize:         viper.GetInt("OUTBOX_BATCH_SIZE"),
+			MaxAttempts:       viper.GetInt("OUTBOX_MAX_ATTEMPTS"),
+			RetryBaseDelay:    viper.GetDuration("OUTBOX_RETRY_BASE_DELAY"),
+			RetryMaxDelay:     viper.GetDuration("OUTBOX_RETRY_MAX_DELAY"),
+			Lease:             viper.GetDuration("OUTBOX_LEASE"),
+			PublishTimeout:    viper.GetDuration("OUTBOX_PUBLISH_TIMEOUT"),
+			Retention:         viper.GetDuration("OUTBOX_RETENTION"),
+		},
+		Webhook: WebhookConfig{
+			Enabled:        viper.GetBool("WEBHOOK_ENABLED"),
+			PollInterval:   viper.GetDuration("WEBHOOK_POLL_INTERVAL"),
+			BatchSize:      viper.GetInt("WEBHOOK_BATCH_SIZE"),
+			MaxAttempts:    viper.GetInt("WEBHOOK_MAX_ATTEMPTS"),
+			RetryBaseDelay: viper.GetDuration("WEBHOOK_RETRY_BASE_DELAY"),
+			RetryMaxDelay:  viper.GetDuration("WEBHOOK_RETRY_MAX_DELAY"),
+			Lease:          viper.GetDuration("WEBHOOK_LEASE"),
+			Timeout:        viper.GetDuration("WEBHOOK_TIMEOUT"),
+			DisableAfter:   viper.GetInt("WEBHOOK_DISABLE_AFTER"),
+			Retention:      viper.GetDuration("WEBHOOK_RETENTION"),
+		},
+	}
+
+	tenants, err := parseTenants(viper.GetString("TENANTS"))
+	if err != nil {
+		return nil, err
+	}
+	config.Tenant.Tenants = tenants
+	if currency := config.Tenant.Defaults.DefaultCurrency; currency != "" && len(currency) != 3 {
+		return nil, fmt.Errorf("TENANT_DEFAULT_CURRENCY %q must be a 3-letter ISO code", currency)
+	}
+
+	switch config.Storage.Backend {
+	case StorageSQL, StorageMemory:
+	default:
+		return nil, fmt.Errorf("unsupported STORAGE %q (expected %q or %q)", config.Storage.Backend, StorageSQL, StorageMemory)
+	}
+
+	switch config.Database.Driver {
+	case DriverPostgres, DriverSQLite:
+	default:
+		return nil, fmt.Errorf("unsupported DB_DRIVER %q (expected %q or %q)", config.Database.Driver, DriverPostgres, DriverSQLite)
+	}
+
+	switch config.Cache.Backend {
+	case CacheNone, CacheMemory, CacheRedis:
+	default:
+		return nil, fmt.Errorf("unsupported CACHE_BACKEND %q (expected %q, %q or %q)", config.Cache.Backend, CacheNone, CacheMemory, CacheRedis)
+	}
+
+	switch config.Outbox.Publisher {
+	case PublisherLog, PublisherNATS, PublisherKafka:
+	case PublisherHTTP:
+		if config.Outbox.HTTPURL == "" {
+			return nil, fmt.Errorf("OUTBOX_PUBLISHER %q requires OUTBOX_HTTP_URL", PublisherHTTP)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported OUTBOX_PUBLISHER %q (expected %q, %q, %q or %q)",
+			config.Outbox.Publisher, PublisherLog, PublisherHTTP, PublisherNATS, PublisherKafka)
+	}
+	if config.Outbox.BatchSize < 1 || config.Outbox.MaxAttempts < 1 {
+		return nil, fmt.Errorf("OUTBOX_BATCH_SIZE (%d) and OUTBOX_MAX_ATTEMPTS (%d) must be at least 1",
+			config.Outbox.BatchSize, config.Outbox.MaxAttempts)
+	}
+	if config.Inventory.SnapshotEvery < 1 {
+		return nil, fmt.Errorf("INVENTORY_SNAPSHOT_EVERY (%d) must be at least 1", config.Inventory.SnapshotEvery)
+	}
+	if config.Webhook.BatchSize < 1 || config.Webhook.MaxAttempts < 1 {
+		return nil, fmt.Errorf("WEBHOOK_BATCH_SIZE (%d) and WEBHOOK_MAX_ATTEMPTS (%d) must be at least 1",
+			config.Webhook.BatchSize, config.Webhook.MaxAttempts)
+	}
+	if config.Webhook.DisableAfter < 0 {
+		return nil, fmt.Errorf("WEBHOOK_DISABLE_AFTER (%d) cannot be negative", config.Webhook.DisableAfter)
+	}
+
+	if config.Database.MaxOpenConns > 0 && config.Database.MaxIdleConns > config.Database.MaxOpenConns {
+		return nil, fmt.Errorf("DB_MAX_IDLE_CONNS (%d) cannot exceed DB_MAX_OPEN_CONNS (%d)", config.Database.MaxIdleConns, config.Database.MaxOpenConns)
+	}
+
+	if len(config.Database.ReadReplicas) > 0 && config.Database.Driver != DriverPostgres {
+		return nil, fmt.Errorf("DB_READ_REPLICAS requires DB_DRIVER %q", DriverPostgres)
+	}
+
+	if config.Database.RowLevelSecurity && config.Database.Driver != DriverPostgres {
+		return nil, fmt.Errorf("DB_ROW_LEVEL_SECURITY requires DB_DRIVER %q", DriverPostgres)
 	}
 
 	log.Printf("Configuration loaded successfully (env: %s)", config.App.Env)
@@ -85,8 +471,9 @@ func Load() (*Config, error) {
 }
 
 // GetDatabaseDSN returns the database connection string
+// Connections get the configured statement_timeout, which PostgreSQL enforces per statement
 func (c *Config) GetDatabaseDSN() string {
-	return fmt.Sprintf(
+	dsn := fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		c.Database.Host,
 		c.Database.Port,
@@ -95,10 +482,45 @@ func (c *Config) GetDatabaseDSN() string {
 		c.Database.Name,
 		c.Database.SSLMode,
 	)
+	if c.Database.StatementTimeout > 0 {
+		dsn += fmt.Sprintf(" statement_timeout=%d", c.Database.StatementTimeout.Milliseconds())
+	}
+	return dsn
+}
+
+// splitList splits a comma-separated setting, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseTenants decodes TENANTS, a JSON object of tenant IDs to their settings
+// such as {"acme": {"default_currency": "EUR"}}
+func parseTenants(value string) (map[string]tenant.Settings, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	var tenants map[string]tenant.Settings
+	if err := json.Unmarshal([]byte(value), &tenants); err != nil {
+		return nil, fmt.Errorf("invalid TENANTS: %w", err)
+	}
+	for id := range tenants {
+		if err := tenant.ValidateID(id); err != nil {
+			return nil, fmt.Errorf("invalid TENANTS: %q: %w", id, err)
+		}
+		if currency := tenants[id].DefaultCurrency; currency != "" && len(currency) != 3 {
+			return nil, fmt.Errorf("invalid TENANTS: %q: default_currency %q must be a 3-letter ISO code", id, currency)
+		}
+	}
+	return tenants, nil
 }
 
 // GetServerAddress returns the server address
 func (c *Config) GetServerAddress() string {
 	return fmt.Sprintf("%s:%s", c.Server.Host, c.Server.Port)
 }
-
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// defaultBackoff is the first wait when RetryOptions leaves Backoff unset, matching DB_CONNECT_BACKOFF
+const defaultBackoff = 250 * time.Millisecond
+
+// RetryOptions controls how long and how often Connect retries an unreachable database
+type RetryOptions struct {
+	// Timeout is how long Connect keeps trying; zero tries once
+	Timeout time.Duration
+	// Backoff is the wait before the first retry; it doubles after every failed attempt
+	// Zero or less waits 250ms, so retries never ping without a pause
+	Backoff time.Duration
+	// MaxBackoff caps the wait between attempts; zero leaves it uncapped
+	MaxBackoff time.Duration
+}
+
+// Connect pings the database until it answers, backing off exponentially between attempts
+// It gives up once opts.Timeout has passed or ctx is done, returning the last ping error,
+// so the server can start before its database and wait for it instead of exiting.
+func Connect(ctx context.Context, db *sql.DB, opts RetryOptions) error {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	backoff := opts.Backoff
+	if backoff <= 0 {
+		backoff = defaultBackoff
+	}
+	for attempt := 1; ; attempt++ {
+		err := db.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+
+		deadline, hasDeadline := ctx.Deadline()
+		if ctx.Err() != nil || !hasDeadline || time.Now().Add(backoff).After(deadline) {
+			return fmt.Errorf("database unreachable after %d attempt(s): %w", attempt, err)
+		}
+		log.Printf("Database not ready (attempt %d), retrying in %v: %v", attempt, backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("database unreachable after %d attempt(s): %w", attempt, err)
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if opts.MaxBackoff > 0 && backoff > opts.MaxBackoff {
+			backoff = opts.MaxBackoff
+		}
+	}
+}
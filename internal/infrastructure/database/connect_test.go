@@ -0,0 +1,81 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/database"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/sqlite"
+	_ "github.com/lib/pq"
+)
+
+func TestConnect_ReachableDatabase(t *testing.T) {
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	if err := database.Connect(context.Background(), db, database.RetryOptions{Timeout: time.Second}); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+}
+
+func TestConnect_RetriesUntilTimeout(t *testing.T) {
+	// Nothing listens on port 1, so every ping is refused
+	db, err := sql.Open("postgres", "host=127.0.0.1 port=1 user=x dbname=x sslmode=disable connect_timeout=1")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	defer db.Close()
+
+	start := time.Now()
+	err = database.Connect(context.Background(), db, database.RetryOptions{
+		Timeout:    300 * time.Millisecond,
+		Backoff:    20 * time.Millisecond,
+		MaxBackoff: 50 * time.Millisecond,
+	})
+	if err == nil {
+		t.Fatal("Connect() succeeded against an unreachable database")
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond || elapsed > 2*time.Second {
+		t.Fatalf("Connect() gave up after %v, want about the 300ms timeout", elapsed)
+	}
+}
+
+func TestConnect_NoTimeoutTriesOnce(t *testing.T) {
+	db, err := sql.Open("postgres", "host=127.0.0.1 port=1 user=x dbname=x sslmode=disable connect_timeout=1")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	defer db.Close()
+
+	start := time.Now()
+	if err := database.Connect(context.Background(), db, database.RetryOptions{Backoff: time.Second}); err == nil {
+		t.Fatal("Connect() succeeded against an unreachable database")
+	}
+	if elapsed := time.Since(start); elapsed > 900*time.Millisecond {
+		t.Fatalf("Connect() without timeout retried for %v", elapsed)
+	}
+}
+
+func TestConnect_ZeroBackoffStillPauses(t *testing.T) {
+	db, err := sql.Open("postgres", "host=127.0.0.1 port=1 user=x dbname=x sslmode=disable connect_timeout=1")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	defer db.Close()
+
+	// With the 250ms default wait only two pings fit in the timeout
+	err = database.Connect(context.Background(), db, database.RetryOptions{Timeout: 400 * time.Millisecond})
+	if err == nil {
+		t.Fatal("Connect() succeeded against an unreachable database")
+	}
+	if !strings.Contains(err.Error(), "after 2 attempt(s)") {
+		t.Fatalf("Connect() error = %v, want it to give up after 2 attempts", err)
+	}
+}
@@ -0,0 +1,218 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+)
+
+// TenantSetting is the PostgreSQL setting the row-level security policies compare tenant_id with
+const TenantSetting = "app.tenant_id"
+
+// setTenantQuery stores the tenant in the session, so it outlives the statement and any transaction
+const setTenantQuery = "SELECT set_config('" + TenantSetting + "', $1, false)"
+
+// NewTenantConnector wraps a connector so every connection acts for the tenant of its caller
+// Before a statement runs, the connection's TenantSetting is switched to the tenant of the
+// statement's context when it differs from the one set last, letting the row-level security
+// policies of the schema reject rows of other tenants even if a query forgets to filter them.
+// The base driver's connections must implement driver.ExecerContext.
+func NewTenantConnector(base driver.Connector) driver.Connector {
+	return &tenantConnector{base: base}
+}
+
+type tenantConnector struct {
+	base driver.Connector
+}
+
+// Connect opens a base connection with no tenant set yet
+func (c *tenantConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.base.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := conn.(driver.ExecerContext); !ok {
+		conn.Close()
+		return nil, errors.New("tenant connector: driver connection does not implement ExecerContext")
+	}
+	return &tenantConn{Conn: conn}, nil
+}
+
+// Driver returns the base driver
+func (c *tenantConnector) Driver() driver.Driver {
+	return c.base.Driver()
+}
+
+// tenantConn switches the tenant setting of a connection before each statement
+// database/sql never uses a connection from two goroutines at once, so it needs no locking.
+type tenantConn struct {
+	driver.Conn
+	// tenantID is the tenant set on the session; empty until the first statement
+	tenantID string
+}
+
+// useTenant sets the tenant of ctx on the session unless it is already set
+func (c *tenantConn) useTenant(ctx context.Context) error {
+	tenantID := tenant.ID(ctx)
+	if tenantID == c.tenantID {
+		return nil
+	}
+	if _, err := c.Conn.(driver.ExecerContext).ExecContext(ctx, setTenantQuery, []driver.NamedValue{
+		{Ordinal: 1, Value: tenantID},
+	}); err != nil {
+		c.tenantID = ""
+		return err
+	}
+	c.tenantID = tenantID
+	return nil
+}
+
+// ExecContext runs a statement for the tenant of ctx
+func (c *tenantConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := c.useTenant(ctx); err != nil {
+		return nil, err
+	}
+	return c.Conn.(driver.ExecerContext).ExecContext(ctx, query, args)
+}
+
+// QueryContext runs a query for the tenant of ctx
+func (c *tenantConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	if err := c.useTenant(ctx); err != nil {
+		return nil, err
+	}
+	return queryer.QueryContext(ctx, query, args)
+}
+
+// PrepareContext prepares a statement whose executions act for the tenant of their own context
+func (c *tenantConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tenantStmt{Stmt: stmt, conn: c}, nil
+}
+
+// Prepare prepares a statement without a context
+func (c *tenantConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// BeginTx sets the tenant of ctx and then starts a transaction
+func (c *tenantConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if err := c.useTenant(ctx); err != nil {
+		return nil, err
+	}
+
+	var (
+		tx  driver.Tx
+		err error
+	)
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		tx, err = beginner.BeginTx(ctx, opts)
+	} else {
+		tx, err = c.Conn.Begin()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tenantTx{Tx: tx, conn: c}, nil
+}
+
+// Ping checks the base connection
+func (c *tenantConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession prepares the base connection for reuse; the tenant setting is kept
+func (c *tenantConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid reports whether the base connection may be reused
+func (c *tenantConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// tenantTx forgets the session's tenant on rollback
+// A tenant switched inside the transaction is undone by the rollback, so it must be set again.
+type tenantTx struct {
+	driver.Tx
+	conn *tenantConn
+}
+
+// Rollback aborts the transaction
+func (t *tenantTx) Rollback() error {
+	t.conn.tenantID = ""
+	return t.Tx.Rollback()
+}
+
+// tenantStmt runs a prepared statement for the tenant of each execution's context
+type tenantStmt struct {
+	driver.Stmt
+	conn *tenantConn
+}
+
+// ExecContext executes the statement for the tenant of ctx
+func (s *tenantStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if err := s.conn.useTenant(ctx); err != nil {
+		return nil, err
+	}
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return execer.ExecContext(ctx, args)
+	}
+	values, err := namedValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Exec(values)
+}
+
+// QueryContext runs the statement for the tenant of ctx
+func (s *tenantStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if err := s.conn.useTenant(ctx); err != nil {
+		return nil, err
+	}
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		return queryer.QueryContext(ctx, args)
+	}
+	values, err := namedValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Query(values)
+}
+
+// namedValues converts positional named arguments for drivers without context support
+func namedValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("tenant connector: driver does not support named parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
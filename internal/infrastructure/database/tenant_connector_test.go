@@ -0,0 +1,147 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/database"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+)
+
+// recordingConnector hands out connections that log every statement they run
+type recordingConnector struct {
+	mu         sync.Mutex
+	statements []string
+}
+
+func (c *recordingConnector) Connect(context.Context) (driver.Conn, error) {
+	return &recordingConn{connector: c}, nil
+}
+
+func (c *recordingConnector) Driver() driver.Driver { return nil }
+
+func (c *recordingConnector) record(statement string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.statements = append(c.statements, statement)
+}
+
+func (c *recordingConnector) take() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	statements := c.statements
+	c.statements = nil
+	return statements
+}
+
+type recordingConn struct {
+	connector *recordingConnector
+}
+
+func (c *recordingConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *recordingConn) Close() error                        { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *recordingConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.connector.record("BEGIN")
+	return recordingTx{c}, nil
+}
+
+func (c *recordingConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if len(args) > 0 {
+		query += " [" + args[0].Value.(string) + "]"
+	}
+	c.connector.record(query)
+	return driver.RowsAffected(0), nil
+}
+
+func (c *recordingConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.connector.record(query)
+	return emptyRows{}, nil
+}
+
+type recordingTx struct{ conn *recordingConn }
+
+func (t recordingTx) Commit() error   { t.conn.connector.record("COMMIT"); return nil }
+func (t recordingTx) Rollback() error { t.conn.connector.record("ROLLBACK"); return nil }
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string         { return nil }
+func (emptyRows) Close() error              { return nil }
+func (emptyRows) Next([]driver.Value) error { return io.EOF }
+
+func openTenantDB(t *testing.T) (*sql.DB, *recordingConnector) {
+	t.Helper()
+	connector := &recordingConnector{}
+	db := sql.OpenDB(database.NewTenantConnector(connector))
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db, connector
+}
+
+func forTenant(id string) context.Context {
+	return tenant.NewContext(context.Background(), tenant.Tenant{ID: id})
+}
+
+const setTenant = "SELECT set_config('app.tenant_id', $1, false)"
+
+func TestTenantConnector_SetsTenantWhenItChanges(t *testing.T) {
+	db, connector := openTenantDB(t)
+
+	for _, ctx := range []context.Context{forTenant("acme"), forTenant("acme"), forTenant("globex"), context.Background()} {
+		if _, err := db.ExecContext(ctx, "DELETE FROM products"); err != nil {
+			t.Fatalf("ExecContext() error = %v", err)
+		}
+	}
+	rows, err := db.QueryContext(forTenant("default"), "SELECT 1")
+	if err != nil {
+		t.Fatalf("QueryContext() error = %v", err)
+	}
+	rows.Close()
+
+	want := []string{
+		setTenant + " [acme]", "DELETE FROM products",
+		"DELETE FROM products",
+		setTenant + " [globex]", "DELETE FROM products",
+		setTenant + " [default]", "DELETE FROM products",
+		"SELECT 1",
+	}
+	if got := connector.take(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("statements = %q, want %q", got, want)
+	}
+}
+
+func TestTenantConnector_SetsTenantAgainAfterRollback(t *testing.T) {
+	db, connector := openTenantDB(t)
+	ctx := forTenant("acme")
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("BeginTx() error = %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE inventory SET quantity = 1"); err != nil {
+		t.Fatalf("ExecContext() error = %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if _, err := db.ExecContext(ctx, "UPDATE inventory SET quantity = 2"); err != nil {
+		t.Fatalf("ExecContext() error = %v", err)
+	}
+
+	want := []string{
+		setTenant + " [acme]", "BEGIN", "UPDATE inventory SET quantity = 1", "ROLLBACK",
+		setTenant + " [acme]", "UPDATE inventory SET quantity = 2",
+	}
+	if got := connector.take(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("statements = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,55 @@
+package delivery
+
+import (
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// ActorHeader names the caller recorded in the audit trail when authentication does not
+	ActorHeader = "X-Actor-ID"
+	// ActorContextKey is the gin context key under which authentication stores the caller's identity
+	ActorContextKey = "actor"
+	// RequestIDHeader carries the ID that ties a request to its audit entries and logs
+	RequestIDHeader = "X-Request-ID"
+	// maxActorLength and maxRequestIDLength match the columns of the audit trail
+	maxActorLength     = 255
+	maxRequestIDLength = 255
+	// anonymousActor is recorded for requests that identify no caller
+	anonymousActor = "anonymous"
+)
+
+// AuditMiddleware stores who issued a request, and its request ID, in the request context
+// so the commands it runs are attributed in the audit trail. A caller set by authentication
+// under ActorContextKey takes precedence over the X-Actor-ID header. The request ID is taken
+// from X-Request-ID or generated, and echoed in the response.
+func AuditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor := c.GetString(ActorContextKey)
+		if actor == "" {
+			actor = c.GetHeader(ActorHeader)
+		}
+		if actor == "" {
+			actor = anonymousActor
+		}
+		if len(actor) > maxActorLength {
+			HandleError(c, apperrors.Newf(apperrors.CodeInvalidInput, "Actor cannot exceed %d characters", maxActorLength))
+			c.Abort()
+			return
+		}
+
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.New().String()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		c.Request = c.Request.WithContext(audit.NewContext(c.Request.Context(), audit.Origin{
+			Actor:     actor,
+			RequestID: requestID,
+		}))
+		c.Next()
+	}
+}
@@ -0,0 +1,56 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/audit/query"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/model"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// AuditHandler handles HTTP requests for the audit trail
+type AuditHandler struct {
+	listQuery *query.ListAuditEntriesQuery
+	validator *validator.Validate
+}
+
+// NewAuditHandler creates a new AuditHandler
+func NewAuditHandler(listQuery *query.ListAuditEntriesQuery) *AuditHandler {
+	return &AuditHandler{
+		listQuery: listQuery,
+		validator: validator.New(),
+	}
+}
+
+// List handles GET /audit - lists the commands recorded for the tenant, newest first
+func (h *AuditHandler) List(c *gin.Context) {
+	var input query.ListAuditEntriesInput
+
+	// Bind query string
+	if err := c.ShouldBindQuery(&input); err != nil {
+		appErr := apperrors.New(apperrors.CodeInvalidInput, "Invalid query parameters: "+err.Error())
+		HandleError(c, appErr)
+		return
+	}
+
+	// Validate input
+	if err := h.validator.Struct(input); err != nil {
+		HandleValidationError(c, err)
+		return
+	}
+
+	// Execute query
+	output, err := h.listQuery.Execute(c.Request.Context(), input)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	// Return success response
+	c.JSON(http.StatusOK, model.NewSuccessResponse(
+		"Audit trail listed successfully",
+		output,
+	))
+}
@@ -0,0 +1,72 @@
+package delivery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// newAuditRouter echoes the actor and request ID each handler saw
+// authActor, when set, plays the part of an authentication middleware
+func newAuditRouter(authActor string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	if authActor != "" {
+		router.Use(func(c *gin.Context) {
+			c.Set(ActorContextKey, authActor)
+			c.Next()
+		})
+	}
+	router.Use(AuditMiddleware())
+	router.GET("/items", func(c *gin.Context) {
+		origin := audit.FromContext(c.Request.Context())
+		c.String(http.StatusOK, origin.Actor+" "+origin.RequestID)
+	})
+	return router
+}
+
+func sendAudit(router *gin.Engine, actor, requestID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	if actor != "" {
+		req.Header.Set(ActorHeader, actor)
+	}
+	if requestID != "" {
+		req.Header.Set(RequestIDHeader, requestID)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuditMiddleware_UsesHeaders(t *testing.T) {
+	w := sendAudit(newAuditRouter(""), "alice", "req-1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "alice req-1", w.Body.String())
+	assert.Equal(t, "req-1", w.Header().Get(RequestIDHeader))
+}
+
+func TestAuditMiddleware_Defaults(t *testing.T) {
+	w := sendAudit(newAuditRouter(""), "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	requestID := w.Header().Get(RequestIDHeader)
+	assert.NotEmpty(t, requestID)
+	assert.Equal(t, anonymousActor+" "+requestID, w.Body.String())
+}
+
+func TestAuditMiddleware_AuthenticatedActorWins(t *testing.T) {
+	w := sendAudit(newAuditRouter("bob"), "alice", "req-1")
+	assert.Equal(t, "bob req-1", w.Body.String())
+}
+
+func TestAuditMiddleware_RejectsLongActor(t *testing.T) {
+	w := sendAudit(newAuditRouter(""), strings.Repeat("a", maxActorLength+1), "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_INPUT")
+}
@@ -22,7 +22,11 @@ func HandleError(c *gin.Context, err error) {
 	message := apperrors.GetMessage(err)
 
 	// Log error for debugging (in production, use proper logging)
-	log.Printf("Error [%s]: %s", code, message)
+	if constraint := apperrors.GetConstraint(err); constraint != "" {
+		log.Printf("Error [%s]: %s (constraint %s)", code, message, constraint)
+	} else {
+		log.Printf("Error [%s]: %s", code, message)
+	}
 
 	// Return error response
 	c.JSON(httpStatus, model.NewErrorResponse(message, string(code)))
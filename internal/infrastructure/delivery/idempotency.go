@@ -0,0 +1,204 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/idempotency"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header carrying the client's idempotency key
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks responses replayed from the idempotency store
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	// maxIdempotencyKeyLength matches the key column of the idempotency store
+	maxIdempotencyKeyLength = 255
+	// maxCompleteAttempts bounds how often storing a response is tried
+	maxCompleteAttempts = 3
+	// completeRetryDelay is the pause before storing a response is tried again
+	completeRetryDelay = 100 * time.Millisecond
+	// defaultReplayContentType is replayed for records stored without a content type
+	defaultReplayContentType = "application/json; charset=utf-8"
+)
+
+// IdempotencyMiddleware makes write requests carrying an Idempotency-Key header safe to retry
+// The first request with a key is processed and its response stored; repeats with the same
+// method, URL and body replay the stored response, while reuse with a different request is rejected.
+// Server errors release the key so the request can be retried. Any other response has taken
+// effect, so its key is never released: a response that cannot be stored leaves the key
+// reserved until it expires, and repeats are refused as in progress rather than processed again.
+func IdempotencyMiddleware(store idempotency.Store, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isWriteMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			HandleError(c, apperrors.Newf(apperrors.CodeInvalidIdempotencyKey, "Idempotency key cannot exceed %d characters", maxIdempotencyKeyLength))
+			c.Abort()
+			return
+		}
+
+		// Read the body for the fingerprint and restore it for the handler
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			HandleError(c, apperrors.Wrap(err, apperrors.CodeInvalidInput, "Failed to read request body"))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.Path, c.Request.URL.RawQuery, body)
+
+		ctx := c.Request.Context()
+		existing, err := claimIdempotencyKey(ctx, store, key, fingerprint, ttl)
+		if err != nil {
+			HandleError(c, err)
+			c.Abort()
+			return
+		}
+		if existing != nil {
+			replayIdempotentResponse(c, existing, fingerprint)
+			return
+		}
+
+		// The response is recorded even if the client goes away before it is written
+		storeCtx := context.WithoutCancel(ctx)
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		release := true
+		defer func() {
+			// A panicking handler leaves no response to store
+			if release {
+				if err := store.Release(storeCtx, key); err != nil {
+					log.Printf("Failed to release idempotency key %q: %v", key, err)
+				}
+			}
+		}()
+
+		c.Next()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+		release = false
+		if err := completeIdempotencyKey(storeCtx, store, key, status, recorder.Header().Get("Content-Type"), recorder.body.Bytes()); err != nil {
+			log.Printf("Failed to store response for idempotency key %q, keeping it reserved until it expires: %v", key, err)
+		}
+	}
+}
+
+// completeIdempotencyKey stores the response of a reserved request, retrying failed attempts
+func completeIdempotencyKey(ctx context.Context, store idempotency.Store, key string, status int, contentType string, body []byte) error {
+	var err error
+	for attempt := 0; attempt < maxCompleteAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(completeRetryDelay)
+		}
+		if err = store.Complete(ctx, key, status, contentType, body); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// claimIdempotencyKey reserves the key for this request or returns the record already holding it
+// A record that expires between the two lookups is reserved again
+func claimIdempotencyKey(ctx context.Context, store idempotency.Store, key, fingerprint string, ttl time.Duration) (*idempotency.Record, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		now := time.Now()
+		reserved, err := store.Reserve(ctx, &idempotency.Record{
+			Key:         key,
+			Fingerprint: fingerprint,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(ttl),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if reserved {
+			return nil, nil
+		}
+
+		existing, err := store.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return existing, nil
+		}
+	}
+	return nil, apperrors.New(apperrors.CodeIdempotencyInProgress, "Request with this idempotency key is still in progress")
+}
+
+// replayIdempotentResponse answers a repeated request from the stored record
+func replayIdempotentResponse(c *gin.Context, record *idempotency.Record, fingerprint string) {
+	defer c.Abort()
+
+	if record.Fingerprint != fingerprint {
+		HandleError(c, apperrors.New(apperrors.CodeIdempotencyKeyReused, "Idempotency key was already used with a different request"))
+		return
+	}
+	if !record.IsCompleted() {
+		HandleError(c, apperrors.New(apperrors.CodeIdempotencyInProgress, "Request with this idempotency key is still in progress"))
+		return
+	}
+
+	contentType := record.ContentType
+	if contentType == "" {
+		contentType = defaultReplayContentType
+	}
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Data(record.StatusCode, contentType, record.ResponseBody)
+}
+
+// requestFingerprint identifies a request by its method, path, query string and body
+func requestFingerprint(method, path, rawQuery string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{'\n'})
+	hash.Write([]byte(path))
+	hash.Write([]byte{'?'})
+	hash.Write([]byte(rawQuery))
+	hash.Write([]byte{'\n'})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// isWriteMethod reports whether requests with the method may change state
+func isWriteMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// responseRecorder copies the response body while it is written to the client
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write writes to the client and keeps a copy
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString writes to the client and keeps a copy
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
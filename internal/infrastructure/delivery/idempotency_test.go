@@ -0,0 +1,194 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/idempotency"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeIdempotencyStore keeps records in memory
+type fakeIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*idempotency.Record
+	// completeFailures is how many calls of Complete fail before one succeeds
+	completeFailures int
+}
+
+func (s *fakeIdempotencyStore) Reserve(ctx context.Context, record *idempotency.Record) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.records[record.Key]; ok && existing.ExpiresAt.After(record.CreatedAt) {
+		return false, nil
+	}
+	copied := *record
+	s.records[record.Key] = &copied
+	return true, nil
+}
+
+func (s *fakeIdempotencyStore) Get(ctx context.Context, key string) (*idempotency.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[key]
+	if !ok || !record.ExpiresAt.After(time.Now()) {
+		return nil, nil
+	}
+	copied := *record
+	return &copied, nil
+}
+
+func (s *fakeIdempotencyStore) Complete(ctx context.Context, key string, statusCode int, contentType string, responseBody []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.completeFailures > 0 {
+		s.completeFailures--
+		return errors.New("connection reset")
+	}
+	s.records[key].StatusCode = statusCode
+	s.records[key].ContentType = contentType
+	s.records[key].ResponseBody = responseBody
+	return nil
+}
+
+func (s *fakeIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+// newIdempotentRouter counts how often the handler actually runs
+func newIdempotentRouter(store idempotency.Store, status int) (*gin.Engine, *int) {
+	gin.SetMode(gin.TestMode)
+	calls := 0
+	router := gin.New()
+	router.Use(IdempotencyMiddleware(store, time.Hour))
+	router.PATCH("/adjust", func(c *gin.Context) {
+		calls++
+		c.JSON(status, gin.H{"calls": calls})
+	})
+	router.POST("/export", func(c *gin.Context) {
+		calls++
+		c.String(status, "calls=%d", calls)
+	})
+	return router, &calls
+}
+
+func sendIdempotent(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	return sendIdempotentTo(router, http.MethodPatch, "/adjust", key, body)
+}
+
+func sendIdempotentTo(router *gin.Engine, method, target, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(IdempotencyKeyHeader, key)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyMiddleware_ReplaysStoredResponse(t *testing.T) {
+	store := &fakeIdempotencyStore{records: map[string]*idempotency.Record{}}
+	router, calls := newIdempotentRouter(store, http.StatusOK)
+
+	first := sendIdempotent(router, "key-1", `{"adjustment":5}`)
+	second := sendIdempotent(router, "key-1", `{"adjustment":5}`)
+
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "true", second.Header().Get(IdempotentReplayedHeader))
+}
+
+func TestIdempotencyMiddleware_RejectsReuseWithDifferentBody(t *testing.T) {
+	store := &fakeIdempotencyStore{records: map[string]*idempotency.Record{}}
+	router, calls := newIdempotentRouter(store, http.StatusOK)
+
+	sendIdempotent(router, "key-1", `{"adjustment":5}`)
+	w := sendIdempotent(router, "key-1", `{"adjustment":6}`)
+
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), string(apperrors.CodeIdempotencyKeyReused))
+}
+
+func TestIdempotencyMiddleware_InProgress(t *testing.T) {
+	store := &fakeIdempotencyStore{records: map[string]*idempotency.Record{}}
+	router, calls := newIdempotentRouter(store, http.StatusOK)
+
+	fingerprint := requestFingerprint(http.MethodPatch, "/adjust", "", []byte(`{"adjustment":5}`))
+	store.records["key-1"] = &idempotency.Record{Key: "key-1", Fingerprint: fingerprint, ExpiresAt: time.Now().Add(time.Hour)}
+
+	w := sendIdempotent(router, "key-1", `{"adjustment":5}`)
+
+	assert.Equal(t, 0, *calls)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestIdempotencyMiddleware_ReleasesKeyOnServerError(t *testing.T) {
+	store := &fakeIdempotencyStore{records: map[string]*idempotency.Record{}}
+	router, calls := newIdempotentRouter(store, http.StatusInternalServerError)
+
+	sendIdempotent(router, "key-1", `{"adjustment":5}`)
+	sendIdempotent(router, "key-1", `{"adjustment":5}`)
+
+	assert.Equal(t, 2, *calls)
+	assert.Empty(t, store.records)
+}
+
+func TestIdempotencyMiddleware_RejectsReuseWithDifferentQuery(t *testing.T) {
+	store := &fakeIdempotencyStore{records: map[string]*idempotency.Record{}}
+	router, calls := newIdempotentRouter(store, http.StatusOK)
+
+	sendIdempotentTo(router, http.MethodPost, "/export?location=WH-1", "key-1", "")
+	w := sendIdempotentTo(router, http.MethodPost, "/export?location=WH-2", "key-1", "")
+
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func TestIdempotencyMiddleware_ReplaysStoredContentType(t *testing.T) {
+	store := &fakeIdempotencyStore{records: map[string]*idempotency.Record{}}
+	router, calls := newIdempotentRouter(store, http.StatusCreated)
+
+	first := sendIdempotentTo(router, http.MethodPost, "/export", "key-1", "")
+	second := sendIdempotentTo(router, http.MethodPost, "/export", "key-1", "")
+
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, "calls=1", second.Body.String())
+	assert.Equal(t, first.Header().Get("Content-Type"), second.Header().Get("Content-Type"))
+	assert.Equal(t, "text/plain; charset=utf-8", second.Header().Get("Content-Type"))
+}
+
+func TestIdempotencyMiddleware_RetriesStoringTheResponse(t *testing.T) {
+	store := &fakeIdempotencyStore{records: map[string]*idempotency.Record{}, completeFailures: 1}
+	router, calls := newIdempotentRouter(store, http.StatusOK)
+
+	sendIdempotent(router, "key-1", `{"adjustment":5}`)
+	second := sendIdempotent(router, "key-1", `{"adjustment":5}`)
+
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, "true", second.Header().Get(IdempotentReplayedHeader))
+}
+
+func TestIdempotencyMiddleware_KeepsKeyWhoseResponseCannotBeStored(t *testing.T) {
+	store := &fakeIdempotencyStore{records: map[string]*idempotency.Record{}, completeFailures: maxCompleteAttempts}
+	router, calls := newIdempotentRouter(store, http.StatusOK)
+
+	sendIdempotent(router, "key-1", `{"adjustment":5}`)
+	w := sendIdempotent(router, "key-1", `{"adjustment":5}`)
+
+	// The adjustment took effect, so a retry must not apply it again
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, store.records, "key-1")
+}
@@ -1,6 +1,7 @@
 package delivery
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/inventory/command"
@@ -13,23 +14,44 @@ import (
 
 // InventoryHandler handles HTTP requests for inventory operations
 type InventoryHandler struct {
-	createCommand *command.CreateInventoryCommand
-	getQuery      *query.GetInventoryQuery
-	adjustCommand *command.AdjustInventoryCommand
-	validator     *validator.Validate
+	createCommand      *command.CreateInventoryCommand
+	getQuery           *query.GetInventoryQuery
+	listQuery          *query.ListInventoryQuery
+	updateCommand      *command.UpdateInventoryCommand
+	deleteCommand      *command.DeleteInventoryCommand
+	adjustCommand      *command.AdjustInventoryCommand
+	batchAdjustCommand *command.BatchAdjustInventoryCommand
+	reserveCommand     *command.ReserveInventoryCommand
+	releaseCommand     *command.ReleaseInventoryCommand
+	stockPolicyCommand *command.SetStockPolicyCommand
+	validator          *validator.Validate
 }
 
 // NewInventoryHandler creates a new InventoryHandler
 func NewInventoryHandler(
 	createCommand *command.CreateInventoryCommand,
 	getQuery *query.GetInventoryQuery,
+	listQuery *query.ListInventoryQuery,
+	updateCommand *command.UpdateInventoryCommand,
+	deleteCommand *command.DeleteInventoryCommand,
 	adjustCommand *command.AdjustInventoryCommand,
+	batchAdjustCommand *command.BatchAdjustInventoryCommand,
+	reserveCommand *command.ReserveInventoryCommand,
+	releaseCommand *command.ReleaseInventoryCommand,
+	stockPolicyCommand *command.SetStockPolicyCommand,
 ) *InventoryHandler {
 	return &InventoryHandler{
-		createCommand: createCommand,
-		getQuery:      getQuery,
-		adjustCommand: adjustCommand,
-		validator:     validator.New(),
+		createCommand:      createCommand,
+		getQuery:           getQuery,
+		listQuery:          listQuery,
+		updateCommand:      updateCommand,
+		deleteCommand:      deleteCommand,
+		adjustCommand:      adjustCommand,
+		batchAdjustCommand: batchAdjustCommand,
+		reserveCommand:     reserveCommand,
+		releaseCommand:     releaseCommand,
+		stockPolicyCommand: stockPolicyCommand,
+		validator:          validator.New(),
 	}
 }
 
@@ -88,6 +110,90 @@ func (h *InventoryHandler) Get(c *gin.Context) {
 	))
 }
 
+// List handles GET /inventory - lists inventory with filters, sorting and pagination
+func (h *InventoryHandler) List(c *gin.Context) {
+	var input query.ListInventoryInput
+
+	// Bind query string
+	if err := c.ShouldBindQuery(&input); err != nil {
+		appErr := apperrors.New(apperrors.CodeInvalidInput, "Invalid query parameters: "+err.Error())
+		HandleError(c, appErr)
+		return
+	}
+
+	// Validate input
+	if err := h.validator.Struct(input); err != nil {
+		HandleValidationError(c, err)
+		return
+	}
+
+	// Execute query
+	output, err := h.listQuery.Execute(c.Request.Context(), input)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	// Return success response
+	c.JSON(http.StatusOK, model.NewSuccessResponse(
+		"Inventory listed successfully",
+		output,
+	))
+}
+
+// Update handles PATCH /inventory/:productId - changes the location and metadata of an inventory record
+func (h *InventoryHandler) Update(c *gin.Context) {
+	var input command.UpdateInventoryInput
+
+	// Bind JSON request body
+	if err := c.ShouldBindJSON(&input); err != nil {
+		appErr := apperrors.New(apperrors.CodeInvalidInput, "Invalid request body: "+err.Error())
+		HandleError(c, appErr)
+		return
+	}
+	input.ProductID = c.Param("productId")
+
+	// Validate input
+	if err := h.validator.Struct(input); err != nil {
+		HandleValidationError(c, err)
+		return
+	}
+
+	// Execute command
+	output, err := h.updateCommand.Execute(c.Request.Context(), input)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(
+		"Inventory updated successfully",
+		output,
+	))
+}
+
+// Delete handles DELETE /inventory/:productId - removes an inventory record without reservations
+func (h *InventoryHandler) Delete(c *gin.Context) {
+	productID := c.Param("productId")
+
+	if productID == "" {
+		appErr := apperrors.New(apperrors.CodeInvalidInput, "Product ID is required")
+		HandleError(c, appErr)
+		return
+	}
+
+	// Execute command
+	if err := h.deleteCommand.Execute(c.Request.Context(), productID); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(
+		"Inventory deleted successfully",
+		nil,
+	))
+}
+
 // Adjust handles PATCH /inventory/adjust - adjusts inventory quantity
 func (h *InventoryHandler) Adjust(c *gin.Context) {
 	var input command.AdjustInventoryInput
@@ -118,3 +224,120 @@ func (h *InventoryHandler) Adjust(c *gin.Context) {
 		output,
 	))
 }
+
+// AdjustBatch handles POST /inventory/adjustments:batch - applies many stock deltas in one transaction
+// Gin reads ":batch" as a path parameter, so anything other than the literal suffix is rejected
+func (h *InventoryHandler) AdjustBatch(c *gin.Context) {
+	if c.Param("batch") != ":batch" {
+		HandleError(c, apperrors.New(apperrors.CodeNotFound, "Route not found"))
+		return
+	}
+
+	var input command.BatchAdjustInventoryInput
+
+	// Bind JSON request body
+	if err := c.ShouldBindJSON(&input); err != nil {
+		appErr := apperrors.New(apperrors.CodeInvalidInput, "Invalid request body: "+err.Error())
+		HandleError(c, appErr)
+		return
+	}
+
+	// Validate input
+	if err := h.validator.Struct(input); err != nil {
+		HandleValidationError(c, err)
+		return
+	}
+
+	// Execute command
+	output, err := h.batchAdjustCommand.Execute(c.Request.Context(), input)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	// A rejected all-or-nothing batch still reports why each line failed
+	if !output.Committed {
+		c.JSON(http.StatusUnprocessableEntity, model.NewSuccessResponse(
+			"Batch rejected; no adjustments were applied",
+			output,
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(
+		"Batch adjustments processed",
+		output,
+	))
+}
+
+// Reserve handles POST /inventory/reserve - reserves stock, backordering shortfalls if allowed
+func (h *InventoryHandler) Reserve(c *gin.Context) {
+	h.changeReservation(c, h.reserveCommand.Execute, "Inventory reserved successfully")
+}
+
+// Release handles POST /inventory/release - releases reserved or backordered stock
+func (h *InventoryHandler) Release(c *gin.Context) {
+	h.changeReservation(c, h.releaseCommand.Execute, "Inventory released successfully")
+}
+
+// changeReservation binds a reservation request and executes the given command
+func (h *InventoryHandler) changeReservation(
+	c *gin.Context,
+	execute func(ctx context.Context, input command.ReservationInput) (*command.ReservationOutput, error),
+	message string,
+) {
+	var input command.ReservationInput
+
+	// Bind JSON request body
+	if err := c.ShouldBindJSON(&input); err != nil {
+		appErr := apperrors.New(apperrors.CodeInvalidInput, "Invalid request body: "+err.Error())
+		HandleError(c, appErr)
+		return
+	}
+
+	// Validate input
+	if err := h.validator.Struct(input); err != nil {
+		HandleValidationError(c, err)
+		return
+	}
+
+	// Execute command
+	output, err := execute(c.Request.Context(), input)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(message, output))
+}
+
+// SetStockPolicy handles PUT /inventory/:productId/stock-policy - changes the oversell policy
+func (h *InventoryHandler) SetStockPolicy(c *gin.Context) {
+	var input command.SetStockPolicyInput
+
+	// Bind JSON request body
+	if err := c.ShouldBindJSON(&input); err != nil {
+		appErr := apperrors.New(apperrors.CodeInvalidInput, "Invalid request body: "+err.Error())
+		HandleError(c, appErr)
+		return
+	}
+	input.ProductID = c.Param("productId")
+
+	// Validate input
+	if err := h.validator.Struct(input); err != nil {
+		HandleValidationError(c, err)
+		return
+	}
+
+	// Execute command
+	output, err := h.stockPolicyCommand.Execute(c.Request.Context(), input)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(
+		"Stock policy updated successfully",
+		output,
+	))
+}
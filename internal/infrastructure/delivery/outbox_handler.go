@@ -0,0 +1,82 @@
+package delivery
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/outbox/command"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/outbox/query"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/model"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// OutboxHandler handles HTTP requests for administering the outbox
+type OutboxHandler struct {
+	listQuery     *query.ListDeadLettersQuery
+	replayCommand *command.ReplayDeadLetterCommand
+	validator     *validator.Validate
+}
+
+// NewOutboxHandler creates a new OutboxHandler
+func NewOutboxHandler(listQuery *query.ListDeadLettersQuery, replayCommand *command.ReplayDeadLetterCommand) *OutboxHandler {
+	return &OutboxHandler{
+		listQuery:     listQuery,
+		replayCommand: replayCommand,
+		validator:     validator.New(),
+	}
+}
+
+// ListDeadLetters handles GET /outbox/dead-letters - lists the events that could not be published
+func (h *OutboxHandler) ListDeadLetters(c *gin.Context) {
+	var input query.ListDeadLettersInput
+
+	// Bind query string
+	if err := c.ShouldBindQuery(&input); err != nil {
+		appErr := apperrors.New(apperrors.CodeInvalidInput, "Invalid query parameters: "+err.Error())
+		HandleError(c, appErr)
+		return
+	}
+
+	// Validate input
+	if err := h.validator.Struct(input); err != nil {
+		HandleValidationError(c, err)
+		return
+	}
+
+	// Execute query
+	output, err := h.listQuery.Execute(c.Request.Context(), input)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	// Return success response
+	c.JSON(http.StatusOK, model.NewSuccessResponse(
+		"Dead letters listed successfully",
+		output,
+	))
+}
+
+// ReplayDeadLetter handles POST /outbox/dead-letters/:id/replay - queues a dead event for publishing again
+func (h *OutboxHandler) ReplayDeadLetter(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		HandleError(c, apperrors.New(apperrors.CodeInvalidInput, "Outbox message ID must be a positive integer"))
+		return
+	}
+
+	// Execute command
+	output, err := h.replayCommand.Execute(c.Request.Context(), id)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	// Return success response
+	c.JSON(http.StatusAccepted, model.NewSuccessResponse(
+		"Dead letter queued for replay",
+		output,
+	))
+}
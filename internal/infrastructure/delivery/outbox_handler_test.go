@@ -0,0 +1,83 @@
+package delivery
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/outbox/command"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/outbox/query"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/memory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/outbox"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newOutboxRouter serves the outbox endpoints over a store holding one dead product.created
+func newOutboxRouter(t *testing.T) (*gin.Engine, outbox.Store, int64) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	db := memory.NewDatabase()
+	price, _ := product.NewPrice(10, "USD")
+	prod, err := product.NewProduct("prod-1", "Widget", price)
+	require.NoError(t, err)
+	require.NoError(t, memory.NewProductCommandRepository(db).Create(ctx, prod))
+
+	store := memory.NewOutboxStore(db)
+	now := time.Now().Add(time.Second)
+	claimed, err := store.Claim(ctx, now, time.Minute, 1)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.NoError(t, store.MarkFailed(ctx, claimed[0].ID, 10, now, "broker down", true))
+
+	handler := NewOutboxHandler(query.NewListDeadLettersQuery(store), command.NewReplayDeadLetterCommand(store))
+	router := gin.New()
+	router.Use(ErrorHandlerMiddleware())
+	router.GET("/outbox/dead-letters", handler.ListDeadLetters)
+	router.POST("/outbox/dead-letters/:id/replay", handler.ReplayDeadLetter)
+	return router, store, claimed[0].ID
+}
+
+func sendOutbox(router *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestOutboxHandler_ListsDeadLetters(t *testing.T) {
+	router, _, _ := newOutboxRouter(t)
+
+	w := sendOutbox(router, http.MethodGet, "/outbox/dead-letters?page_size=5")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"event_name":"product.created"`)
+	assert.Contains(t, w.Body.String(), `"last_error":"broker down"`)
+	assert.Contains(t, w.Body.String(), `"total":1`)
+
+	w = sendOutbox(router, http.MethodGet, "/outbox/dead-letters?page_size=500")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestOutboxHandler_ReplaysDeadLetters(t *testing.T) {
+	router, store, id := newOutboxRouter(t)
+	path := "/outbox/dead-letters/" + strconv.FormatInt(id, 10) + "/replay"
+
+	w := sendOutbox(router, http.MethodPost, path)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	count, err := store.Count(context.Background(), outbox.Filter{Status: outbox.StatusPending})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	// Only dead messages can be replayed
+	w = sendOutbox(router, http.MethodPost, path)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "OUTBOX_MESSAGE_NOT_FOUND")
+
+	w = sendOutbox(router, http.MethodPost, "/outbox/dead-letters/abc/replay")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
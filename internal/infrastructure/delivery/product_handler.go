@@ -15,6 +15,7 @@ import (
 type ProductHandler struct {
 	createCommand *command.CreateProductCommand
 	getQuery      *query.GetProductQuery
+	listQuery     *query.ListProductsQuery
 	validator     *validator.Validate
 }
 
@@ -22,10 +23,12 @@ type ProductHandler struct {
 func NewProductHandler(
 	createCommand *command.CreateProductCommand,
 	getQuery *query.GetProductQuery,
+	listQuery *query.ListProductsQuery,
 ) *ProductHandler {
 	return &ProductHandler{
 		createCommand: createCommand,
 		getQuery:      getQuery,
+		listQuery:     listQuery,
 		validator:     validator.New(),
 	}
 }
@@ -85,6 +88,37 @@ func (h *ProductHandler) Get(c *gin.Context) {
 	))
 }
 
+// List handles GET /products - lists products with optional stock levels
+func (h *ProductHandler) List(c *gin.Context) {
+	var input query.ListProductsInput
+
+	// Bind query string
+	if err := c.ShouldBindQuery(&input); err != nil {
+		appErr := apperrors.New(apperrors.CodeInvalidInput, "Invalid query parameters: "+err.Error())
+		HandleError(c, appErr)
+		return
+	}
+
+	// Validate input
+	if err := h.validator.Struct(input); err != nil {
+		HandleValidationError(c, err)
+		return
+	}
+
+	// Execute query
+	output, err := h.listQuery.Execute(c.Request.Context(), input)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	// Return success response
+	c.JSON(http.StatusOK, model.NewSuccessResponse(
+		"Products listed successfully",
+		output,
+	))
+}
+
 // HealthCheck handles GET /health - simple health check endpoint
 func HealthCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
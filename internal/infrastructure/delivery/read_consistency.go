@@ -0,0 +1,63 @@
+package delivery
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/readconsistency"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ReadConsistencyHeader lets a client ask for primary reads on a single request
+	ReadConsistencyHeader = "X-Read-Consistency"
+	// ReadConsistencyPrimary is the ReadConsistencyHeader value that forces primary reads
+	ReadConsistencyPrimary = "primary"
+	// readPrimaryCookie holds the Unix millisecond time until which a client reads from the primary
+	readPrimaryCookie = "read_primary_until"
+)
+
+// ReadYourWritesMiddleware routes reads to the primary database when a client needs to see its own writes
+// Write requests always read from the primary, and a cookie keeps the writing client on the
+// primary for the following window so replica lag cannot hide its changes.
+// A zero window only pins the write request itself.
+func ReadYourWritesMiddleware(window time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if requiresPrimaryRead(c) {
+			c.Request = c.Request.WithContext(readconsistency.WithPrimary(c.Request.Context()))
+		}
+
+		if isWriteMethod(c.Request.Method) && window > 0 {
+			until := time.Now().Add(window)
+			http.SetCookie(c.Writer, &http.Cookie{
+				Name:     readPrimaryCookie,
+				Value:    strconv.FormatInt(until.UnixMilli(), 10),
+				Path:     "/",
+				MaxAge:   int(window.Round(time.Second) / time.Second),
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+
+		c.Next()
+	}
+}
+
+// requiresPrimaryRead reports whether the request must read from the primary database
+func requiresPrimaryRead(c *gin.Context) bool {
+	if isWriteMethod(c.Request.Method) {
+		return true
+	}
+	if strings.EqualFold(c.GetHeader(ReadConsistencyHeader), ReadConsistencyPrimary) {
+		return true
+	}
+
+	cookie, err := c.Cookie(readPrimaryCookie)
+	if err != nil {
+		return false
+	}
+	until, err := strconv.ParseInt(cookie, 10, 64)
+	return err == nil && time.Now().UnixMilli() < until
+}
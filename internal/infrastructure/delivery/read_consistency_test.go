@@ -0,0 +1,74 @@
+package delivery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/readconsistency"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// newReadConsistencyRouter reports whether each handler saw a primary-pinned context
+func newReadConsistencyRouter(window time.Duration) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ReadYourWritesMiddleware(window))
+	handler := func(c *gin.Context) {
+		c.String(http.StatusOK, strconv.FormatBool(readconsistency.RequiresPrimary(c.Request.Context())))
+	}
+	router.GET("/items", handler)
+	router.POST("/items", handler)
+	return router
+}
+
+func sendReadConsistency(router *gin.Engine, method string, prepare func(*http.Request)) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/items", nil)
+	if prepare != nil {
+		prepare(req)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestReadYourWritesMiddleware_ReadsAfterWriteUsePrimary(t *testing.T) {
+	router := newReadConsistencyRouter(5 * time.Second)
+
+	write := sendReadConsistency(router, http.MethodPost, nil)
+	assert.Equal(t, "true", write.Body.String())
+	cookies := write.Result().Cookies()
+	if assert.Len(t, cookies, 1) {
+		assert.Equal(t, readPrimaryCookie, cookies[0].Name)
+		assert.Equal(t, 5, cookies[0].MaxAge)
+	}
+
+	read := sendReadConsistency(router, http.MethodGet, func(req *http.Request) { req.AddCookie(cookies[0]) })
+	assert.Equal(t, "true", read.Body.String())
+}
+
+func TestReadYourWritesMiddleware_ExpiredWindowUsesReplicas(t *testing.T) {
+	router := newReadConsistencyRouter(5 * time.Second)
+	expired := strconv.FormatInt(time.Now().Add(-time.Second).UnixMilli(), 10)
+
+	read := sendReadConsistency(router, http.MethodGet, func(req *http.Request) {
+		req.AddCookie(&http.Cookie{Name: readPrimaryCookie, Value: expired})
+	})
+	assert.Equal(t, "false", read.Body.String())
+	assert.Empty(t, read.Result().Cookies())
+}
+
+func TestReadYourWritesMiddleware_HeaderForcesPrimary(t *testing.T) {
+	router := newReadConsistencyRouter(0)
+
+	read := sendReadConsistency(router, http.MethodGet, func(req *http.Request) {
+		req.Header.Set(ReadConsistencyHeader, ReadConsistencyPrimary)
+	})
+	assert.Equal(t, "true", read.Body.String())
+
+	write := sendReadConsistency(router, http.MethodPost, nil)
+	assert.Empty(t, write.Result().Cookies(), "a zero window should not pin later reads")
+}
@@ -0,0 +1,168 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/inventory/command"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/inventory/query"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/model"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// StocktakeHandler handles HTTP requests for stocktake (cycle count) operations
+type StocktakeHandler struct {
+	openCommand    *command.OpenStocktakeCommand
+	countsCommand  *command.RecordStocktakeCountsCommand
+	approveCommand *command.ApproveStocktakeCommand
+	applyCommand   *command.ApplyStocktakeCommand
+	cancelCommand  *command.CancelStocktakeCommand
+	getQuery       *query.GetStocktakeQuery
+	validator      *validator.Validate
+}
+
+// NewStocktakeHandler creates a new StocktakeHandler
+func NewStocktakeHandler(
+	openCommand *command.OpenStocktakeCommand,
+	countsCommand *command.RecordStocktakeCountsCommand,
+	approveCommand *command.ApproveStocktakeCommand,
+	applyCommand *command.ApplyStocktakeCommand,
+	cancelCommand *command.CancelStocktakeCommand,
+	getQuery *query.GetStocktakeQuery,
+) *StocktakeHandler {
+	return &StocktakeHandler{
+		openCommand:    openCommand,
+		countsCommand:  countsCommand,
+		approveCommand: approveCommand,
+		applyCommand:   applyCommand,
+		cancelCommand:  cancelCommand,
+		getQuery:       getQuery,
+		validator:      validator.New(),
+	}
+}
+
+// Open handles POST /stocktakes - opens a stocktake and freezes expected quantities
+func (h *StocktakeHandler) Open(c *gin.Context) {
+	var input command.OpenStocktakeInput
+
+	// Bind JSON request body
+	if err := c.ShouldBindJSON(&input); err != nil {
+		appErr := apperrors.New(apperrors.CodeInvalidInput, "Invalid request body: "+err.Error())
+		HandleError(c, appErr)
+		return
+	}
+
+	// Execute command
+	output, err := h.openCommand.Execute(c.Request.Context(), input)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	// Return success response
+	c.JSON(http.StatusCreated, model.NewSuccessResponse(
+		"Stocktake opened successfully",
+		output,
+	))
+}
+
+// Get handles GET /stocktakes/:id - retrieves a stocktake with its variances
+func (h *StocktakeHandler) Get(c *gin.Context) {
+	output, err := h.getQuery.Execute(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(
+		"Stocktake retrieved successfully",
+		output,
+	))
+}
+
+// RecordCounts handles POST /stocktakes/:id/counts - submits counted quantities
+func (h *StocktakeHandler) RecordCounts(c *gin.Context) {
+	var input command.RecordStocktakeCountsInput
+
+	// Bind JSON request body
+	if err := c.ShouldBindJSON(&input); err != nil {
+		appErr := apperrors.New(apperrors.CodeInvalidInput, "Invalid request body: "+err.Error())
+		HandleError(c, appErr)
+		return
+	}
+	input.StocktakeID = c.Param("id")
+
+	// Validate input
+	if err := h.validator.Struct(input); err != nil {
+		HandleValidationError(c, err)
+		return
+	}
+
+	// Execute command
+	output, err := h.countsCommand.Execute(c.Request.Context(), input)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(
+		"Stocktake counts recorded successfully",
+		output,
+	))
+}
+
+// Approve handles POST /stocktakes/:id/approve - approves variances for posting
+func (h *StocktakeHandler) Approve(c *gin.Context) {
+	var input command.ApproveStocktakeInput
+
+	// An empty body approves every counted line
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			appErr := apperrors.New(apperrors.CodeInvalidInput, "Invalid request body: "+err.Error())
+			HandleError(c, appErr)
+			return
+		}
+	}
+	input.StocktakeID = c.Param("id")
+
+	// Execute command
+	output, err := h.approveCommand.Execute(c.Request.Context(), input)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(
+		"Stocktake variances approved successfully",
+		output,
+	))
+}
+
+// Apply handles POST /stocktakes/:id/apply - posts approved variances as cycle count adjustments
+func (h *StocktakeHandler) Apply(c *gin.Context) {
+	output, err := h.applyCommand.Execute(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(
+		"Stocktake applied successfully",
+		output,
+	))
+}
+
+// Cancel handles POST /stocktakes/:id/cancel - closes a stocktake without posting
+func (h *StocktakeHandler) Cancel(c *gin.Context) {
+	output, err := h.cancelCommand.Execute(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(
+		"Stocktake cancelled successfully",
+		output,
+	))
+}
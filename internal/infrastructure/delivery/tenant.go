@@ -0,0 +1,45 @@
+package delivery
+
+import (
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// TenantHeader names the tenant a request acts for when authentication does not
+	TenantHeader = "X-Tenant-ID"
+	// TenantContextKey is the gin context key under which authentication stores the caller's tenant ID
+	TenantContextKey = "tenant_id"
+)
+
+// TenantMiddleware resolves the tenant a request acts for and stores it in the request context
+// A tenant set by authentication under TenantContextKey takes precedence over the
+// X-Tenant-ID header, so authenticated callers cannot reach another tenant's data.
+// Requests naming no tenant act for tenant.DefaultID unless a tenant is required.
+// It must run before any middleware that reads or writes tenant data, such as idempotency.
+func TenantMiddleware(registry *tenant.Registry, required bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetString(TenantContextKey)
+		if id == "" {
+			id = c.GetHeader(TenantHeader)
+		}
+		if id == "" {
+			if required {
+				HandleError(c, apperrors.Newf(apperrors.CodeTenantRequired, "The %s header is required", TenantHeader))
+				c.Abort()
+				return
+			}
+			id = tenant.DefaultID
+		}
+
+		t, err := registry.Resolve(id)
+		if err != nil {
+			HandleError(c, err)
+			c.Abort()
+			return
+		}
+		c.Request = c.Request.WithContext(tenant.NewContext(c.Request.Context(), t))
+		c.Next()
+	}
+}
@@ -0,0 +1,87 @@
+package delivery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTenantRouter echoes the tenant and default currency each handler saw
+// authTenant, when set, plays the part of an authentication middleware
+func newTenantRouter(required bool, authTenant string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	registry := tenant.NewRegistry(
+		tenant.Settings{DefaultCurrency: "USD"},
+		map[string]tenant.Settings{"acme": {DefaultCurrency: "EUR"}, "globex": {}},
+	)
+
+	router := gin.New()
+	if authTenant != "" {
+		router.Use(func(c *gin.Context) {
+			c.Set(TenantContextKey, authTenant)
+			c.Next()
+		})
+	}
+	router.Use(TenantMiddleware(registry, required))
+	router.GET("/items", func(c *gin.Context) {
+		t, _ := tenant.FromContext(c.Request.Context())
+		c.String(http.StatusOK, t.ID+" "+t.Settings.DefaultCurrency)
+	})
+	return router
+}
+
+func sendTenant(router *gin.Engine, header string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	if header != "" {
+		req.Header.Set(TenantHeader, header)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestTenantMiddleware_ResolvesHeader(t *testing.T) {
+	router := newTenantRouter(false, "")
+
+	acme := sendTenant(router, "acme")
+	assert.Equal(t, http.StatusOK, acme.Code)
+	assert.Equal(t, "acme EUR", acme.Body.String())
+
+	// Settings a tenant leaves empty fall back to the defaults
+	globex := sendTenant(router, "globex")
+	assert.Equal(t, "globex USD", globex.Body.String())
+}
+
+func TestTenantMiddleware_DefaultTenant(t *testing.T) {
+	w := sendTenant(newTenantRouter(false, ""), "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, tenant.DefaultID+" USD", w.Body.String())
+}
+
+func TestTenantMiddleware_RequiredTenant(t *testing.T) {
+	w := sendTenant(newTenantRouter(true, ""), "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "TENANT_REQUIRED")
+}
+
+func TestTenantMiddleware_RejectsUnknownAndInvalidTenants(t *testing.T) {
+	router := newTenantRouter(false, "")
+
+	unknown := sendTenant(router, "initech")
+	assert.Equal(t, http.StatusForbidden, unknown.Code)
+	assert.Contains(t, unknown.Body.String(), "UNKNOWN_TENANT")
+
+	invalid := sendTenant(router, "Acme Corp")
+	assert.Equal(t, http.StatusBadRequest, invalid.Code)
+	assert.Contains(t, invalid.Body.String(), "INVALID_TENANT_ID")
+}
+
+func TestTenantMiddleware_AuthenticatedTenantWins(t *testing.T) {
+	w := sendTenant(newTenantRouter(true, "acme"), "globex")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "acme EUR", w.Body.String())
+}
@@ -0,0 +1,79 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/inventory/command"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/inventory/query"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/model"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// ValuationHandler handles HTTP requests for costed receipts and inventory valuation
+type ValuationHandler struct {
+	receiveCommand *command.ReceiveStockCommand
+	reportQuery    *query.GetValuationReportQuery
+	validator      *validator.Validate
+}
+
+// NewValuationHandler creates a new ValuationHandler
+func NewValuationHandler(
+	receiveCommand *command.ReceiveStockCommand,
+	reportQuery *query.GetValuationReportQuery,
+) *ValuationHandler {
+	return &ValuationHandler{
+		receiveCommand: receiveCommand,
+		reportQuery:    reportQuery,
+		validator:      validator.New(),
+	}
+}
+
+// Receive handles POST /inventory/receipts - receives stock at a unit cost
+func (h *ValuationHandler) Receive(c *gin.Context) {
+	var input command.ReceiveStockInput
+
+	// Bind JSON request body
+	if err := c.ShouldBindJSON(&input); err != nil {
+		appErr := apperrors.New(apperrors.CodeInvalidInput, "Invalid request body: "+err.Error())
+		HandleError(c, appErr)
+		return
+	}
+
+	// Validate input
+	if err := h.validator.Struct(input); err != nil {
+		HandleValidationError(c, err)
+		return
+	}
+
+	// Execute command
+	output, err := h.receiveCommand.Execute(c.Request.Context(), input)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	// Return success response
+	c.JSON(http.StatusCreated, model.NewSuccessResponse(
+		"Stock received successfully",
+		output,
+	))
+}
+
+// Report handles GET /inventory/valuation - reports stock value per product and in total
+// An optional product_id query parameter restricts the report to one product
+func (h *ValuationHandler) Report(c *gin.Context) {
+	// Execute query
+	output, err := h.reportQuery.Execute(c.Request.Context(), c.Query("product_id"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	// Return success response
+	c.JSON(http.StatusOK, model.NewSuccessResponse(
+		"Inventory valuation retrieved successfully",
+		output,
+	))
+}
@@ -0,0 +1,208 @@
+package delivery
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/webhook/command"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/webhook/query"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/model"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// WebhookHandler handles HTTP requests for webhook subscriptions and their deliveries
+type WebhookHandler struct {
+	createCommand    *command.CreateSubscriptionCommand
+	updateCommand    *command.UpdateSubscriptionCommand
+	deleteCommand    *command.DeleteSubscriptionCommand
+	redeliverCommand *command.RedeliverCommand
+	getQuery         *query.GetSubscriptionQuery
+	listQuery        *query.ListSubscriptionsQuery
+	deliveriesQuery  *query.ListDeliveriesQuery
+	validator        *validator.Validate
+}
+
+// NewWebhookHandler creates a new WebhookHandler
+func NewWebhookHandler(
+	createCommand *command.CreateSubscriptionCommand,
+	updateCommand *command.UpdateSubscriptionCommand,
+	deleteCommand *command.DeleteSubscriptionCommand,
+	redeliverCommand *command.RedeliverCommand,
+	getQuery *query.GetSubscriptionQuery,
+	listQuery *query.ListSubscriptionsQuery,
+	deliveriesQuery *query.ListDeliveriesQuery,
+) *WebhookHandler {
+	return &WebhookHandler{
+		createCommand:    createCommand,
+		updateCommand:    updateCommand,
+		deleteCommand:    deleteCommand,
+		redeliverCommand: redeliverCommand,
+		getQuery:         getQuery,
+		listQuery:        listQuery,
+		deliveriesQuery:  deliveriesQuery,
+		validator:        validator.New(),
+	}
+}
+
+// Create handles POST /webhooks - subscribes an endpoint to events
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var input command.CreateSubscriptionInput
+
+	// Bind JSON request body
+	if err := c.ShouldBindJSON(&input); err != nil {
+		appErr := apperrors.New(apperrors.CodeInvalidInput, "Invalid request body: "+err.Error())
+		HandleError(c, appErr)
+		return
+	}
+
+	// Validate input
+	if err := h.validator.Struct(input); err != nil {
+		HandleValidationError(c, err)
+		return
+	}
+
+	// Execute command
+	output, err := h.createCommand.Execute(c.Request.Context(), input)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	// Return success response
+	c.JSON(http.StatusCreated, model.NewSuccessResponse(
+		"Webhook subscription created successfully",
+		output,
+	))
+}
+
+// List handles GET /webhooks - lists the webhook subscriptions
+func (h *WebhookHandler) List(c *gin.Context) {
+	// Execute query
+	output, err := h.listQuery.Execute(c.Request.Context())
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	// Return success response
+	c.JSON(http.StatusOK, model.NewSuccessResponse(
+		"Webhook subscriptions listed successfully",
+		output,
+	))
+}
+
+// Get handles GET /webhooks/:id - retrieves a webhook subscription by ID
+func (h *WebhookHandler) Get(c *gin.Context) {
+	// Execute query
+	output, err := h.getQuery.Execute(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	// Return success response
+	c.JSON(http.StatusOK, model.NewSuccessResponse(
+		"Webhook subscription retrieved successfully",
+		output,
+	))
+}
+
+// Update handles PATCH /webhooks/:id - changes a webhook subscription, or enables it again
+func (h *WebhookHandler) Update(c *gin.Context) {
+	var input command.UpdateSubscriptionInput
+
+	// Bind JSON request body
+	if err := c.ShouldBindJSON(&input); err != nil {
+		appErr := apperrors.New(apperrors.CodeInvalidInput, "Invalid request body: "+err.Error())
+		HandleError(c, appErr)
+		return
+	}
+
+	// Validate input
+	if err := h.validator.Struct(input); err != nil {
+		HandleValidationError(c, err)
+		return
+	}
+
+	// Execute command
+	output, err := h.updateCommand.Execute(c.Request.Context(), c.Param("id"), input)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	// Return success response
+	c.JSON(http.StatusOK, model.NewSuccessResponse(
+		"Webhook subscription updated successfully",
+		output,
+	))
+}
+
+// Delete handles DELETE /webhooks/:id - deletes a webhook subscription with its delivery log
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	// Execute command
+	if err := h.deleteCommand.Execute(c.Request.Context(), c.Param("id")); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(
+		"Webhook subscription deleted successfully",
+		nil,
+	))
+}
+
+// ListDeliveries handles GET /webhooks/:id/deliveries - lists the delivery log of a subscription
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	var input query.ListDeliveriesInput
+
+	// Bind query string
+	if err := c.ShouldBindQuery(&input); err != nil {
+		appErr := apperrors.New(apperrors.CodeInvalidInput, "Invalid query parameters: "+err.Error())
+		HandleError(c, appErr)
+		return
+	}
+
+	// Validate input
+	if err := h.validator.Struct(input); err != nil {
+		HandleValidationError(c, err)
+		return
+	}
+
+	// Execute query
+	output, err := h.deliveriesQuery.Execute(c.Request.Context(), c.Param("id"), input)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	// Return success response
+	c.JSON(http.StatusOK, model.NewSuccessResponse(
+		"Webhook deliveries listed successfully",
+		output,
+	))
+}
+
+// Redeliver handles POST /webhooks/:id/deliveries/:deliveryId/redeliver - queues a delivery for sending again
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	deliveryID, err := strconv.ParseInt(c.Param("deliveryId"), 10, 64)
+	if err != nil || deliveryID < 1 {
+		HandleError(c, apperrors.New(apperrors.CodeInvalidInput, "Delivery ID must be a positive integer"))
+		return
+	}
+
+	// Execute command
+	output, err := h.redeliverCommand.Execute(c.Request.Context(), c.Param("id"), deliveryID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	// Return success response
+	c.JSON(http.StatusAccepted, model.NewSuccessResponse(
+		"Webhook delivery queued for redelivery",
+		output,
+	))
+}
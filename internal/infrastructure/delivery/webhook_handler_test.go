@@ -0,0 +1,153 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/webhook/command"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/application/webhook/query"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/memory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/webhook"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newWebhookRouter serves the webhook endpoints over an empty store
+func newWebhookRouter() (*gin.Engine, webhook.Store) {
+	gin.SetMode(gin.TestMode)
+	store := memory.NewWebhookStore(memory.NewDatabase())
+
+	handler := NewWebhookHandler(
+		command.NewCreateSubscriptionCommand(store),
+		command.NewUpdateSubscriptionCommand(store),
+		command.NewDeleteSubscriptionCommand(store),
+		command.NewRedeliverCommand(store),
+		query.NewGetSubscriptionQuery(store),
+		query.NewListSubscriptionsQuery(store),
+		query.NewListDeliveriesQuery(store),
+	)
+	router := gin.New()
+	router.Use(ErrorHandlerMiddleware())
+	router.POST("/webhooks", handler.Create)
+	router.GET("/webhooks", handler.List)
+	router.GET("/webhooks/:id", handler.Get)
+	router.PATCH("/webhooks/:id", handler.Update)
+	router.DELETE("/webhooks/:id", handler.Delete)
+	router.GET("/webhooks/:id/deliveries", handler.ListDeliveries)
+	router.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", handler.Redeliver)
+	return router, store
+}
+
+func sendWebhook(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// createWebhook subscribes an endpoint to product.created and returns its ID and secret
+func createWebhook(t *testing.T, router *gin.Engine) (string, string) {
+	t.Helper()
+	w := sendWebhook(router, http.MethodPost, "/webhooks", `{"url":"https://example.com/hooks","event_types":["product.created"]}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var response struct {
+		Data struct {
+			ID     string `json:"id"`
+			Secret string `json:"secret"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response.Data.ID, response.Data.Secret
+}
+
+func TestWebhookHandler_ManagesSubscriptions(t *testing.T) {
+	router, _ := newWebhookRouter()
+	id, secret := createWebhook(t, router)
+	require.NotEmpty(t, secret)
+
+	// The secret is only returned on creation
+	w := sendWebhook(router, http.MethodGet, "/webhooks/"+id, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), secret)
+	assert.Contains(t, w.Body.String(), `"secret_hint":"****`+secret[len(secret)-4:]+`"`)
+
+	w = sendWebhook(router, http.MethodPatch, "/webhooks/"+id, `{"event_types":["*"],"active":false}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"event_types":["*"]`)
+	assert.Contains(t, w.Body.String(), `"active":false`)
+
+	w = sendWebhook(router, http.MethodGet, "/webhooks", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"`+id+`"`)
+
+	w = sendWebhook(router, http.MethodDelete, "/webhooks/"+id, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = sendWebhook(router, http.MethodGet, "/webhooks/"+id, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "WEBHOOK_SUBSCRIPTION_NOT_FOUND")
+}
+
+func TestWebhookHandler_RejectsInvalidSubscriptions(t *testing.T) {
+	router, _ := newWebhookRouter()
+
+	w := sendWebhook(router, http.MethodPost, "/webhooks", `{"url":"not a url","event_types":["product.created"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = sendWebhook(router, http.MethodPost, "/webhooks", `{"url":"https://example.com/hooks","event_types":["product.deleted"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = sendWebhook(router, http.MethodPost, "/webhooks", `{"url":"https://example.com/hooks"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestWebhookHandler_ListsAndRedeliversDeliveries(t *testing.T) {
+	router, store := newWebhookRouter()
+	id, _ := createWebhook(t, router)
+	now := time.Now().UTC()
+	require.NoError(t, store.Enqueue(context.Background(), []*webhook.Delivery{{
+		SubscriptionID: id,
+		MessageID:      1,
+		EventName:      product.EventProductCreated,
+		Payload:        []byte(`{"id":1}`),
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+	}}))
+	claimed, err := store.Claim(context.Background(), now, time.Minute, 1)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	deliveryID := claimed[0].ID
+	attempt := webhook.Attempt{At: now, StatusCode: http.StatusBadGateway, Error: "responded 502 Bad Gateway"}
+	require.NoError(t, store.MarkFailed(context.Background(), deliveryID, 5, attempt, now, true))
+
+	w := sendWebhook(router, http.MethodGet, "/webhooks/"+id+"/deliveries?status=failed", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"last_status_code":502`)
+	assert.Contains(t, w.Body.String(), `"total":1`)
+
+	w = sendWebhook(router, http.MethodGet, "/webhooks/"+id+"/deliveries?status=lost", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	redeliver := "/webhooks/" + id + "/deliveries/" + strconv.FormatInt(deliveryID, 10) + "/redeliver"
+	w = sendWebhook(router, http.MethodPost, redeliver, "")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	count, err := store.CountDeliveries(context.Background(), id, webhook.DeliveryFilter{Status: webhook.DeliveryPending})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	w = sendWebhook(router, http.MethodPost, "/webhooks/"+id+"/deliveries/999/redeliver", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "WEBHOOK_DELIVERY_NOT_FOUND")
+
+	w = sendWebhook(router, http.MethodPost, "/webhooks/"+id+"/deliveries/abc/redeliver", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
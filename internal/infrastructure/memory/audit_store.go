@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+)
+
+// AuditStore implements the audit.Store interface in memory
+type AuditStore struct {
+	session session
+}
+
+// NewAuditStore creates a new instance of AuditStore
+func NewAuditStore(db *Database) audit.Store {
+	return &AuditStore{session: session{db: db}}
+}
+
+// Append links the entry to the end of the tenant's chain and stores a copy of it
+func (s *AuditStore) Append(ctx context.Context, entry *audit.Entry) error {
+	return s.session.write(ctx, func(t *tables) error {
+		var last *audit.Entry
+		if n := len(t.auditLog); n > 0 {
+			last = &t.auditLog[n-1]
+		}
+		entry.Link(last)
+		t.auditLog = append(t.auditLog, copyAuditEntry(*entry))
+		return nil
+	})
+}
+
+// List returns a page of the entries matching the filter, newest first
+func (s *AuditStore) List(ctx context.Context, filter audit.Filter) ([]*audit.Entry, error) {
+	entries := make([]*audit.Entry, 0)
+	err := s.session.read(ctx, func(t *tables) error {
+		skipped := 0
+		for i := len(t.auditLog) - 1; i >= 0 && len(entries) < filter.Limit; i-- {
+			if !matchesAuditFilter(t.auditLog[i], filter) {
+				continue
+			}
+			if skipped < filter.Offset {
+				skipped++
+				continue
+			}
+			entry := copyAuditEntry(t.auditLog[i])
+			entries = append(entries, &entry)
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// Count returns how many entries match the filter
+func (s *AuditStore) Count(ctx context.Context, filter audit.Filter) (int, error) {
+	count := 0
+	err := s.session.read(ctx, func(t *tables) error {
+		for _, entry := range t.auditLog {
+			if matchesAuditFilter(entry, filter) {
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Chain returns up to limit entries following the given sequence, oldest first
+func (s *AuditStore) Chain(ctx context.Context, afterSequence int64, limit int) ([]*audit.Entry, error) {
+	entries := make([]*audit.Entry, 0)
+	err := s.session.read(ctx, func(t *tables) error {
+		for _, stored := range t.auditLog {
+			if len(entries) == limit {
+				break
+			}
+			if stored.Sequence > afterSequence {
+				entry := copyAuditEntry(stored)
+				entries = append(entries, &entry)
+			}
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// matchesAuditFilter reports whether an entry is selected by the filter's non-empty fields
+func matchesAuditFilter(entry audit.Entry, filter audit.Filter) bool {
+	return (filter.AggregateType == "" || entry.AggregateType == filter.AggregateType) &&
+		(filter.AggregateID == "" || entry.AggregateID == filter.AggregateID) &&
+		(filter.Actor == "" || entry.Actor == filter.Actor)
+}
+
+// copyAuditEntry copies an entry so stored entries never share state with callers
+func copyAuditEntry(entry audit.Entry) audit.Entry {
+	entry.Input = append([]byte(nil), entry.Input...)
+	entry.Before = append([]byte(nil), entry.Before...)
+	entry.After = append([]byte(nil), entry.After...)
+	return entry
+}
@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+)
+
+// CatalogRepository implements CatalogQueryRepository and CatalogProjection in memory
+// Entries are joined from the product and inventory tables under one read lock, so the
+// read model can never drift from its sources and rebuilding has nothing to regenerate.
+type CatalogRepository struct {
+	session session
+}
+
+// NewCatalogQueryRepository creates a new instance for query operations
+func NewCatalogQueryRepository(db *Database) product.CatalogQueryRepository {
+	return &CatalogRepository{session: session{db: db}}
+}
+
+// NewCatalogProjection creates a new instance that rebuilds the read model
+func NewCatalogProjection(db *Database) product.CatalogProjection {
+	return &CatalogRepository{session: session{db: db}}
+}
+
+// GetByProductID retrieves the catalog entry of a product
+func (r *CatalogRepository) GetByProductID(ctx context.Context, productID string) (*product.CatalogEntry, error) {
+	var entry *product.CatalogEntry
+	err := r.session.read(ctx, func(t *tables) error {
+		row, ok := t.products.rows[productID]
+		if !ok {
+			return nil // Product not found
+		}
+
+		entry = &product.CatalogEntry{
+			ProductID:     row.id,
+			Name:          row.name,
+			PriceAmount:   row.priceAmount,
+			PriceCurrency: row.priceCurrency,
+			CreatedAt:     row.createdAt,
+			UpdatedAt:     row.updatedAt,
+		}
+		if inv, ok := t.inventory.rows[productID]; ok {
+			entry.HasInventory = true
+			entry.Quantity = inv.quantity
+			entry.ReservedQuantity = inv.reservedQuantity
+			entry.AvailableQuantity = inv.quantity - inv.reservedQuantity
+			entry.Location = inv.location
+		}
+		return nil
+	})
+	return entry, err
+}
+
+// Rebuild reports how many entries the read model holds across every tenant
+func (r *CatalogRepository) Rebuild(ctx context.Context) (int, error) {
+	var entries int
+	err := r.session.readAll(func(t *tables) error {
+		entries += len(t.products.rows)
+		return nil
+	})
+	return entries, err
+}
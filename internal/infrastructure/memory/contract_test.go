@@ -0,0 +1,41 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/memory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/repotest"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		return newRepositories(memory.NewDatabase())
+	})
+}
+
+func TestEventSourcedRepositoryContract(t *testing.T) {
+	repotest.RunEventSourced(t, func(t *testing.T) repotest.Repositories {
+		db := memory.NewDatabase()
+		repos := newRepositories(db)
+		repos.InventoryCommands = memory.NewEventSourcedInventoryCommandRepository(db, repotest.SnapshotEvery)
+		repos.InventoryQueries = memory.NewEventSourcedInventoryQueryRepository(db)
+		repos.UnitOfWork = memory.NewEventSourcedUnitOfWork(db, repotest.SnapshotEvery)
+		return repos
+	})
+}
+
+func newRepositories(db *memory.Database) repotest.Repositories {
+	return repotest.Repositories{
+		ProductCommands:   memory.NewProductCommandRepository(db),
+		ProductQueries:    memory.NewProductQueryRepository(db),
+		InventoryCommands: memory.NewInventoryCommandRepository(db),
+		InventoryQueries:  memory.NewInventoryQueryRepository(db),
+		Catalog:           memory.NewCatalogQueryRepository(db),
+		CatalogProjection: memory.NewCatalogProjection(db),
+		Audit:             memory.NewAuditStore(db),
+		Outbox:            memory.NewOutboxStore(db),
+		Webhooks:          memory.NewWebhookStore(db),
+		ProductUnitOfWork: memory.NewProductUnitOfWork(db),
+		UnitOfWork:        memory.NewUnitOfWork(db),
+	}
+}
@@ -0,0 +1,345 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/event"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/idempotency"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/outbox"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/webhook"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// Database is a thread-safe in-memory stand-in for the SQL schema
+// Every write works on a copy of the tables that replaces the original only
+// when the write succeeds, so a failed write never leaves partial changes behind.
+// The copy shares its rows with the original until it changes a table, so only
+// the tables a write touches are copied.
+// Each tenant has its own set of tables, so a tenant can never see another's rows.
+type Database struct {
+	mu      sync.RWMutex
+	tenants map[string]*tables
+	// outboxSequence is the ID of the last outbox message, shared by every tenant
+	outboxSequence int64
+	// webhookSequence is the ID of the last webhook delivery, shared by every tenant
+	webhookSequence int64
+}
+
+// NewDatabase creates an empty in-memory database
+func NewDatabase() *Database {
+	return &Database{tenants: make(map[string]*tables)}
+}
+
+// tablesOf returns the tables of a tenant; a tenant without writes has empty tables
+// The caller must hold the lock
+func (db *Database) tablesOf(tenantID string) *tables {
+	if t, ok := db.tenants[tenantID]; ok {
+		return t
+	}
+	return newTables()
+}
+
+// tables mirrors the SQL tables, keyed by their primary keys
+type tables struct {
+	products  table[string, productRow]
+	inventory table[string, inventoryRow] // keyed by product_id, which is unique
+	// inventoryEvents holds the event stream of each product, ordered by version; events are never changed once appended
+	inventoryEvents    table[string, []inventoryEventRow]
+	inventorySnapshots table[string, inventory.Snapshot] // the latest snapshot of each stream
+	stocktakes         table[string, stocktakeRow]
+	valuations         table[string, valuationRow]
+	costLayers         table[string, costLayerRow]
+	idempotency        table[string, idempotency.Record]
+	auditLog           []audit.Entry      // ordered by sequence; entries are never changed once appended
+	outbox             rowList[outboxRow] // ordered by ID
+	webhooks           table[string, webhook.Subscription]
+	deliveries         rowList[webhookDeliveryRow] // ordered by ID
+}
+
+// table is a keyed table whose rows a copy of the tables shares with the original
+// until it first changes them
+type table[K comparable, V any] struct {
+	rows   map[K]V
+	shared bool
+}
+
+// newTable creates an empty table
+func newTable[K comparable, V any]() table[K, V] {
+	return table[K, V]{rows: make(map[K]V)}
+}
+
+// mutable returns the rows for changing, copying them first if they are shared
+func (t *table[K, V]) mutable() map[K]V {
+	if t.shared {
+		rows := make(map[K]V, len(t.rows))
+		for k, v := range t.rows {
+			rows[k] = v
+		}
+		t.rows, t.shared = rows, false
+	}
+	return t.rows
+}
+
+// set stores a row under its key
+func (t *table[K, V]) set(key K, row V) {
+	t.mutable()[key] = row
+}
+
+// delete removes the row stored under a key
+func (t *table[K, V]) delete(key K) {
+	if _, ok := t.rows[key]; ok {
+		delete(t.mutable(), key)
+	}
+}
+
+// rowList is an ordered table whose rows are shared like those of a table
+// Rows are changed in place, so they must be taken from mutable to be changed.
+type rowList[T any] struct {
+	rows   []T
+	shared bool
+}
+
+// mutable returns the rows for changing, copying them first if they are shared
+func (l *rowList[T]) mutable() []T {
+	if l.shared {
+		l.rows, l.shared = append([]T(nil), l.rows...), false
+	}
+	return l.rows
+}
+
+// append adds a row after the last one
+func (l *rowList[T]) append(row T) {
+	l.rows = append(l.mutable(), row)
+}
+
+// replace swaps every row for the given ones, which the list then owns
+func (l *rowList[T]) replace(rows []T) {
+	l.rows, l.shared = rows, false
+}
+
+type productRow struct {
+	id            string
+	name          string
+	priceAmount   float64
+	priceCurrency string
+	createdAt     time.Time
+	updatedAt     time.Time
+	version       int
+}
+
+type inventoryRow struct {
+	id                  string
+	productID           string
+	quantity            int
+	reservedQuantity    int
+	backorderedQuantity int
+	stockPolicy         string
+	backorderLimit      int
+	location            string
+	metadata            map[string]string
+	createdAt           time.Time
+	updatedAt           time.Time
+	version             int
+}
+
+type inventoryEventRow struct {
+	version    int
+	event      event.Event
+	recordedAt time.Time
+}
+
+type stocktakeRow struct {
+	id        string
+	status    string
+	lines     []stocktakeLineRow
+	createdAt time.Time
+	updatedAt time.Time
+	appliedAt time.Time
+}
+
+type stocktakeLineRow struct {
+	productID        string
+	location         string
+	expectedQuantity int
+	countedQuantity  int
+	counted          bool
+	countAttempts    int
+	approved         bool
+	countedAt        time.Time
+}
+
+type valuationRow struct {
+	productID     string
+	costingMethod string
+	currency      string
+	quantity      int
+	totalCost     float64
+	consumedCost  float64
+	updatedAt     time.Time
+}
+
+type outboxRow struct {
+	message     outbox.Message
+	lockedUntil time.Time
+}
+
+type webhookDeliveryRow struct {
+	delivery    webhook.Delivery
+	lockedUntil time.Time
+}
+
+type costLayerRow struct {
+	id                string
+	productID         string
+	receivedQuantity  int
+	remainingQuantity int
+	unitCost          float64
+	receivedAt        time.Time
+}
+
+func newTables() *tables {
+	return &tables{
+		products:           newTable[string, productRow](),
+		inventory:          newTable[string, inventoryRow](),
+		inventoryEvents:    newTable[string, []inventoryEventRow](),
+		inventorySnapshots: newTable[string, inventory.Snapshot](),
+		stocktakes:         newTable[string, stocktakeRow](),
+		valuations:         newTable[string, valuationRow](),
+		costLayers:         newTable[string, costLayerRow](),
+		idempotency:        newTable[string, idempotency.Record](),
+		webhooks:           newTable[string, webhook.Subscription](),
+	}
+}
+
+// clone returns a copy of the tables that can be changed without affecting readers
+// Each table is copied by the first change to it, so a write copies only the tables it touches.
+func (t *tables) clone() *tables {
+	c := *t
+	c.products.shared = true
+	c.inventory.shared = true
+	c.inventoryEvents.shared = true
+	c.inventorySnapshots.shared = true
+	c.stocktakes.shared = true
+	c.valuations.shared = true
+	c.costLayers.shared = true
+	c.idempotency.shared = true
+	// Appending to the capped slice copies it, leaving the original untouched
+	c.auditLog = t.auditLog[:len(t.auditLog):len(t.auditLog)]
+	c.outbox.shared = true
+	c.webhooks.shared = true
+	c.deliveries.shared = true
+	return &c
+}
+
+// deleteProduct removes a product and cascades like the ON DELETE CASCADE foreign keys
+func (t *tables) deleteProduct(id string) {
+	t.products.delete(id)
+	t.inventory.delete(id)
+	t.inventoryEvents.delete(id)
+	t.inventorySnapshots.delete(id)
+	t.deleteValuation(id)
+	for stocktakeID, st := range t.stocktakes.rows {
+		lines := make([]stocktakeLineRow, 0, len(st.lines))
+		for _, line := range st.lines {
+			if line.productID != id {
+				lines = append(lines, line)
+			}
+		}
+		st.lines = lines
+		t.stocktakes.set(stocktakeID, st)
+	}
+}
+
+// deleteValuation removes a valuation and cascades to its cost layers
+func (t *tables) deleteValuation(productID string) {
+	t.valuations.delete(productID)
+	for id, layer := range t.costLayers.rows {
+		if layer.productID == productID {
+			t.costLayers.delete(id)
+		}
+	}
+}
+
+// session gives repositories access to the tables of the tenant of the context
+// Outside a unit of work every call takes the database lock itself; inside one,
+// the unit of work already holds the lock and its working copy is used directly.
+type session struct {
+	db *Database
+	tx *tables
+}
+
+// read runs fn against a consistent view of the tables
+func (s session) read(ctx context.Context, fn func(t *tables) error) error {
+	if s.tx != nil {
+		return fn(s.tx)
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	return fn(s.db.tablesOf(tenant.ID(ctx)))
+}
+
+// readAll runs fn against the tables of every tenant
+func (s session) readAll(fn func(t *tables) error) error {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	for _, t := range s.db.tenants {
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeAll runs fn against a copy of the tables of every tenant and keeps the
+// copies only if fn succeeds for all of them
+func (s session) writeAll(fn func(t *tables) error) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	work := make(map[string]*tables, len(s.db.tenants))
+	for tenantID, t := range s.db.tenants {
+		work[tenantID] = t.clone()
+		if err := fn(work[tenantID]); err != nil {
+			return err
+		}
+	}
+	s.db.tenants = work
+	return nil
+}
+
+// write runs fn against a copy of the tables and keeps the copy only if fn succeeds
+func (s session) write(ctx context.Context, fn func(t *tables) error) error {
+	if s.tx != nil {
+		return fn(s.tx)
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	tenantID := tenant.ID(ctx)
+	work := s.db.tablesOf(tenantID).clone()
+	if err := fn(work); err != nil {
+		return err
+	}
+	s.db.tenants[tenantID] = work
+	return nil
+}
+
+// errUniqueViolation reports a duplicate key, as the SQL unique constraints would
+func errUniqueViolation(constraint string) error {
+	return apperrors.ConstraintError(nil, constraint, "", apperrors.CodeConflict, "Resource already exists")
+}
+
+// errForeignKeyViolation reports a missing referenced row, as the SQL foreign keys would
+func errForeignKeyViolation(constraint string) error {
+	return apperrors.ConstraintError(nil, constraint, "", apperrors.CodeInvalidInput, "Referenced resource does not exist")
+}
+
+// errCheckViolation reports a row rejected by a SQL check constraint
+func errCheckViolation(constraint string) error {
+	return apperrors.ConstraintError(nil, constraint, "", apperrors.CodeInvalidInput, "Value violates a data rule")
+}
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/idempotency"
+)
+
+// IdempotencyStore implements the idempotency.Store interface in memory
+type IdempotencyStore struct {
+	session session
+}
+
+// NewIdempotencyStore creates a new instance of IdempotencyStore
+func NewIdempotencyStore(db *Database) idempotency.Store {
+	return &IdempotencyStore{session: session{db: db}}
+}
+
+// Reserve claims the key unless an unexpired record already holds it
+func (s *IdempotencyStore) Reserve(ctx context.Context, record *idempotency.Record) (bool, error) {
+	reserved := false
+	err := s.session.write(ctx, func(t *tables) error {
+		if existing, ok := t.idempotency.rows[record.Key]; ok && existing.ExpiresAt.After(record.CreatedAt) {
+			return nil
+		}
+		t.idempotency.set(record.Key, idempotency.Record{
+			Key:         record.Key,
+			Fingerprint: record.Fingerprint,
+			CreatedAt:   record.CreatedAt,
+			ExpiresAt:   record.ExpiresAt,
+		})
+		reserved = true
+		return nil
+	})
+	return reserved, err
+}
+
+// Get retrieves the unexpired record for a key
+func (s *IdempotencyStore) Get(ctx context.Context, key string) (*idempotency.Record, error) {
+	var record *idempotency.Record
+	err := s.session.read(ctx, func(t *tables) error {
+		if existing, ok := t.idempotency.rows[key]; ok && existing.ExpiresAt.After(time.Now()) {
+			record = &existing
+		}
+		return nil
+	})
+	return record, err
+}
+
+// Complete stores the response of a reserved request
+func (s *IdempotencyStore) Complete(ctx context.Context, key string, statusCode int, contentType string, responseBody []byte) error {
+	return s.session.write(ctx, func(t *tables) error {
+		record, ok := t.idempotency.rows[key]
+		if !ok {
+			return nil
+		}
+		record.StatusCode = statusCode
+		record.ContentType = contentType
+		record.ResponseBody = append([]byte(nil), responseBody...)
+		t.idempotency.set(key, record)
+		return nil
+	})
+}
+
+// Release removes a reservation so the request can be retried
+func (s *IdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.session.write(ctx, func(t *tables) error {
+		t.idempotency.delete(key)
+		return nil
+	})
+}
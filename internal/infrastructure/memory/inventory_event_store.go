@@ -0,0 +1,280 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/event"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+)
+
+// EventSourcedInventoryRepository implements both InventoryCommandRepository and InventoryQueryRepository
+// on the inventory event streams, in memory
+// It behaves like the SQL repository: inventories are rehydrated from their snapshot and events,
+// and the inventory table is written as their projection by the same write.
+type EventSourcedInventoryRepository struct {
+	session       session
+	projection    *InventoryRepository
+	snapshotEvery int
+}
+
+// NewEventSourcedInventoryCommandRepository creates a new instance for command operations
+// A snapshot is taken whenever a change crosses a multiple of snapshotEvery events
+func NewEventSourcedInventoryCommandRepository(db *Database, snapshotEvery int) inventory.InventoryCommandRepository {
+	return newEventSourcedInventoryRepository(session{db: db}, snapshotEvery)
+}
+
+// NewEventSourcedInventoryQueryRepository creates a new instance for query operations
+func NewEventSourcedInventoryQueryRepository(db *Database) inventory.InventoryQueryRepository {
+	return newEventSourcedInventoryRepository(session{db: db}, 0)
+}
+
+// newEventSourcedInventoryRepository binds the repository and its projection to the same session
+func newEventSourcedInventoryRepository(s session, snapshotEvery int) *EventSourcedInventoryRepository {
+	return &EventSourcedInventoryRepository{
+		session:       s,
+		projection:    &InventoryRepository{session: s},
+		snapshotEvery: snapshotEvery,
+	}
+}
+
+// Create starts the event stream of a new inventory and stores its projection
+// The inventory of a product whose earlier inventory was deleted continues that stream.
+// An inventory not raised by NewInventory is adopted into a stream on its first change
+func (r *EventSourcedInventoryRepository) Create(ctx context.Context, inv *inventory.Inventory) error {
+	events := inv.PendingEvents()
+	startsStream := len(events) > 0 && events[0].EventName() == inventory.EventInventoryCreated
+
+	return r.session.write(ctx, func(t *tables) error {
+		if _, exists := t.products.rows[inv.ProductID()]; !exists {
+			return errForeignKeyViolation("fk_product")
+		}
+		if _, exists := t.inventory.rows[inv.ProductID()]; exists {
+			return errUniqueViolation("inventory_product_id_key")
+		}
+		for _, existing := range t.inventory.rows {
+			if existing.id == inv.ID() {
+				return errUniqueViolation("inventory_pkey")
+			}
+		}
+
+		row := toInventoryRow(inv)
+		if err := checkInventoryRow(row); err != nil {
+			return err
+		}
+		// Versions follow the stream, so a row that does not start one is ahead of it
+		from := inventoryStreamVersion(t, inv.ProductID())
+		row.version = from + inv.Version()
+		if startsStream {
+			row.version = from + len(events)
+			r.append(t, inv, from, events)
+		}
+		t.inventory.set(row.productID, row)
+		return r.session.appendOutbox(ctx, t, inventory.AggregateType, events)
+	})
+}
+
+// GetByProductID rehydrates the inventory of a product from its event stream
+func (r *EventSourcedInventoryRepository) GetByProductID(ctx context.Context, productID string) (*inventory.Inventory, error) {
+	var inv *inventory.Inventory
+	err := r.session.read(ctx, func(t *tables) error {
+		var err error
+		inv, err = loadInventory(t, productID)
+		return err
+	})
+	return inv, err
+}
+
+// GetByProductIDs rehydrates the inventories of the given products, skipping products without one
+func (r *EventSourcedInventoryRepository) GetByProductIDs(ctx context.Context, productIDs []string) ([]*inventory.Inventory, error) {
+	inventories := make([]*inventory.Inventory, 0, len(productIDs))
+	err := r.session.read(ctx, func(t *tables) error {
+		seen := make(map[string]bool, len(productIDs))
+		for _, productID := range productIDs {
+			if seen[productID] {
+				continue
+			}
+			seen[productID] = true
+
+			inv, err := loadInventory(t, productID)
+			if err != nil {
+				return err
+			}
+			if inv != nil {
+				inventories = append(inventories, inv)
+			}
+		}
+		return nil
+	})
+	return inventories, err
+}
+
+// ListByLocation retrieves all inventory records stored at a location from the projection
+func (r *EventSourcedInventoryRepository) ListByLocation(ctx context.Context, location string) ([]*inventory.Inventory, error) {
+	return r.projection.ListByLocation(ctx, location)
+}
+
+// List retrieves a page of inventory records matching the filter from the projection
+func (r *EventSourcedInventoryRepository) List(ctx context.Context, filter inventory.InventoryFilter) ([]*inventory.Inventory, error) {
+	return r.projection.List(ctx, filter)
+}
+
+// Count returns how many inventory records match the filter in the projection
+func (r *EventSourcedInventoryRepository) Count(ctx context.Context, filter inventory.InventoryFilter) (int, error) {
+	return r.projection.Count(ctx, filter)
+}
+
+// Update appends the inventory's pending events to its stream if the stream is still at the loaded version
+func (r *EventSourcedInventoryRepository) Update(ctx context.Context, inv *inventory.Inventory) error {
+	return r.session.write(ctx, func(t *tables) error {
+		return r.save(ctx, t, inv)
+	})
+}
+
+// UpdateBatch appends the pending events of several inventories; either every stream is written or none is
+func (r *EventSourcedInventoryRepository) UpdateBatch(ctx context.Context, inventories []*inventory.Inventory) error {
+	return r.session.write(ctx, func(t *tables) error {
+		for _, inv := range inventories {
+			if err := r.save(ctx, t, inv); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete ends the event stream of an inventory with its InventoryDeleted event and deletes the projection
+// The stream is kept, so its history can still be replayed and a new inventory for the product continues it.
+// The projection row carries the stream version, so it is deleted with the same check as Update.
+func (r *EventSourcedInventoryRepository) Delete(ctx context.Context, inv *inventory.Inventory) error {
+	return r.session.write(ctx, func(t *tables) error {
+		if err := follow(t, inv.ProductID(), inv.Version()); err != nil {
+			return err
+		}
+		if err := deleteInventory(t, inv); err != nil {
+			return err
+		}
+		r.append(t, inv, inv.Version(), inv.PendingEvents())
+		return r.session.appendOutbox(ctx, t, inventory.AggregateType, inv.PendingEvents())
+	})
+}
+
+// AdjustStock adjusts the stock quantity for a product through the aggregate, so the change is in its stream
+func (r *EventSourcedInventoryRepository) AdjustStock(ctx context.Context, productID string, adjustment int) error {
+	return r.session.write(ctx, func(t *tables) error {
+		inv, err := loadInventory(t, productID)
+		if err != nil || inv == nil {
+			return err
+		}
+		if err := inv.AdjustQuantity(adjustment); err != nil {
+			return err
+		}
+		return r.save(ctx, t, inv)
+	})
+}
+
+// save appends the pending events of an inventory to its stream and writes the projection
+func (r *EventSourcedInventoryRepository) save(ctx context.Context, t *tables, inv *inventory.Inventory) error {
+	expected := inv.Version()
+	events := inv.PendingEvents()
+
+	if err := follow(t, inv.ProductID(), expected); err != nil {
+		return err
+	}
+	stored := t.inventory.rows[inv.ProductID()]
+
+	row := toInventoryRow(inv)
+	if err := checkInventoryRow(row); err != nil {
+		return err
+	}
+	row.id = stored.id
+	row.createdAt = stored.createdAt
+	row.version = expected + len(events)
+	t.inventory.set(row.productID, row)
+
+	r.append(t, inv, expected, events)
+	return r.session.appendOutbox(ctx, t, inventory.AggregateType, events)
+}
+
+// follow checks that the projection row and the stream of a product are at the version the caller loaded
+// A projection row ahead of its stream is snapshotted first, so events can follow it
+func follow(t *tables, productID string, expected int) error {
+	stored, ok := t.inventory.rows[productID]
+	if !ok || stored.version != expected || inventoryStreamVersion(t, productID) > expected {
+		return inventory.ErrConcurrentModification
+	}
+	if inventoryStreamVersion(t, productID) < expected {
+		t.inventorySnapshots.set(productID, stored.toDomain().Snapshot())
+	}
+	return nil
+}
+
+// append stores events as the versions after from, snapshotting the inventory
+// when they cross a multiple of the snapshot interval
+func (r *EventSourcedInventoryRepository) append(t *tables, inv *inventory.Inventory, from int, events []event.Event) {
+	recordedAt := time.Now().UTC()
+	// Appending to the capped stream copies it, leaving the stream other copies share untouched
+	stream := t.inventoryEvents.rows[inv.ProductID()]
+	stream = stream[:len(stream):len(stream)]
+	for i, e := range events {
+		stream = append(stream, inventoryEventRow{version: from + i + 1, event: e, recordedAt: recordedAt})
+	}
+	t.inventoryEvents.set(inv.ProductID(), stream)
+
+	to := from + len(events)
+	if r.snapshotEvery < 1 || to/r.snapshotEvery == from/r.snapshotEvery {
+		return
+	}
+	snapshot := inv.Snapshot()
+	snapshot.Version = to
+	t.inventorySnapshots.set(inv.ProductID(), snapshot)
+}
+
+// loadInventory rebuilds an inventory from its snapshot and the events after it
+// The projection row decides whether the inventory exists; a row ahead of its stream is returned as stored
+func loadInventory(t *tables, productID string) (*inventory.Inventory, error) {
+	row, ok := t.inventory.rows[productID]
+	if !ok {
+		return nil, nil
+	}
+	version := inventoryStreamVersion(t, productID)
+	if version == 0 || version < row.version {
+		return row.toDomain(), nil
+	}
+
+	var snapshot *inventory.Snapshot
+	after := 0
+	if stored, ok := t.inventorySnapshots.rows[productID]; ok {
+		stored.Metadata = copyMetadata(stored.Metadata)
+		snapshot = &stored
+		after = stored.Version
+	}
+	var history []event.Event
+	for _, stored := range t.inventoryEvents.rows[productID] {
+		if stored.version > after {
+			history = append(history, stored.event)
+		}
+	}
+	return inventory.RehydrateInventory(snapshot, history)
+}
+
+// inventoryStreamVersion returns the version of the last event or snapshot of a stream, 0 for no stream
+func inventoryStreamVersion(t *tables, productID string) int {
+	version := 0
+	if stream := t.inventoryEvents.rows[productID]; len(stream) > 0 {
+		version = stream[len(stream)-1].version
+	}
+	if snapshot, ok := t.inventorySnapshots.rows[productID]; ok && snapshot.Version > version {
+		version = snapshot.Version
+	}
+	return version
+}
+
+// copyMetadata copies metadata so a rehydrated inventory never shares it with the stored snapshot
+func copyMetadata(metadata map[string]string) map[string]string {
+	copied := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		copied[key] = value
+	}
+	return copied
+}
@@ -0,0 +1,309 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+)
+
+// InventoryRepository implements both InventoryCommandRepository and InventoryQueryRepository in memory
+type InventoryRepository struct {
+	session session
+}
+
+// NewInventoryCommandRepository creates a new instance for command operations
+func NewInventoryCommandRepository(db *Database) inventory.InventoryCommandRepository {
+	return &InventoryRepository{session: session{db: db}}
+}
+
+// NewInventoryQueryRepository creates a new instance for query operations
+func NewInventoryQueryRepository(db *Database) inventory.InventoryQueryRepository {
+	return &InventoryRepository{session: session{db: db}}
+}
+
+// Create stores a new inventory record and its pending events in the outbox
+func (r *InventoryRepository) Create(ctx context.Context, inv *inventory.Inventory) error {
+	return r.session.write(ctx, func(t *tables) error {
+		if _, exists := t.products.rows[inv.ProductID()]; !exists {
+			return errForeignKeyViolation("fk_product")
+		}
+		if _, exists := t.inventory.rows[inv.ProductID()]; exists {
+			return errUniqueViolation("inventory_product_id_key")
+		}
+		for _, existing := range t.inventory.rows {
+			if existing.id == inv.ID() {
+				return errUniqueViolation("inventory_pkey")
+			}
+		}
+
+		row := toInventoryRow(inv)
+		if err := checkInventoryRow(row); err != nil {
+			return err
+		}
+		t.inventory.set(row.productID, row)
+		return r.session.appendOutbox(ctx, t, inventory.AggregateType, inv.PendingEvents())
+	})
+}
+
+// GetByProductID retrieves inventory by product ID
+func (r *InventoryRepository) GetByProductID(ctx context.Context, productID string) (*inventory.Inventory, error) {
+	var inv *inventory.Inventory
+	err := r.session.read(ctx, func(t *tables) error {
+		if row, ok := t.inventory.rows[productID]; ok {
+			inv = row.toDomain()
+		}
+		return nil
+	})
+	return inv, err
+}
+
+// GetByProductIDs retrieves the inventory records for the given products
+func (r *InventoryRepository) GetByProductIDs(ctx context.Context, productIDs []string) ([]*inventory.Inventory, error) {
+	inventories := make([]*inventory.Inventory, 0, len(productIDs))
+	err := r.session.read(ctx, func(t *tables) error {
+		seen := make(map[string]bool, len(productIDs))
+		for _, productID := range productIDs {
+			row, ok := t.inventory.rows[productID]
+			if !ok || seen[productID] {
+				continue
+			}
+			seen[productID] = true
+			inventories = append(inventories, row.toDomain())
+		}
+		return nil
+	})
+	return inventories, err
+}
+
+// ListByLocation retrieves all inventory records stored at a location
+func (r *InventoryRepository) ListByLocation(ctx context.Context, location string) ([]*inventory.Inventory, error) {
+	return r.List(ctx, inventory.InventoryFilter{Location: location})
+}
+
+// List retrieves a page of inventory records matching the filter
+func (r *InventoryRepository) List(ctx context.Context, filter inventory.InventoryFilter) ([]*inventory.Inventory, error) {
+	var inventories []*inventory.Inventory
+	err := r.session.read(ctx, func(t *tables) error {
+		rows := filterInventory(t, filter)
+		sortInventory(rows, filter.SortBy, filter.SortDesc)
+		rows = paginate(rows, filter.Limit, filter.Offset)
+
+		inventories = make([]*inventory.Inventory, 0, len(rows))
+		for _, row := range rows {
+			inventories = append(inventories, row.toDomain())
+		}
+		return nil
+	})
+	return inventories, err
+}
+
+// Count returns how many inventory records match the filter
+func (r *InventoryRepository) Count(ctx context.Context, filter inventory.InventoryFilter) (int, error) {
+	var count int
+	err := r.session.read(ctx, func(t *tables) error {
+		count = len(filterInventory(t, filter))
+		return nil
+	})
+	return count, err
+}
+
+// Update updates an existing inventory record if the stored version still matches the entity's version
+func (r *InventoryRepository) Update(ctx context.Context, inv *inventory.Inventory) error {
+	return r.session.write(ctx, func(t *tables) error {
+		if err := updateInventory(t, inv); err != nil {
+			return err
+		}
+		return r.session.appendOutbox(ctx, t, inventory.AggregateType, inv.PendingEvents())
+	})
+}
+
+// UpdateBatch updates several inventory records; either every record is written or none is
+func (r *InventoryRepository) UpdateBatch(ctx context.Context, inventories []*inventory.Inventory) error {
+	return r.session.write(ctx, func(t *tables) error {
+		for _, inv := range inventories {
+			if err := updateInventory(t, inv); err != nil {
+				return err
+			}
+			if err := r.session.appendOutbox(ctx, t, inventory.AggregateType, inv.PendingEvents()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete removes an inventory record with a version check and stores its pending events in the outbox
+func (r *InventoryRepository) Delete(ctx context.Context, inv *inventory.Inventory) error {
+	return r.session.write(ctx, func(t *tables) error {
+		if err := deleteInventory(t, inv); err != nil {
+			return err
+		}
+		return r.session.appendOutbox(ctx, t, inventory.AggregateType, inv.PendingEvents())
+	})
+}
+
+// AdjustStock adjusts the stock quantity for a product
+func (r *InventoryRepository) AdjustStock(ctx context.Context, productID string, adjustment int) error {
+	return r.session.write(ctx, func(t *tables) error {
+		return adjustInventory(t, productID, adjustment, time.Now())
+	})
+}
+
+// deleteInventory removes the stored row of an entity if it still has the entity's version
+// and nothing is reserved or backordered
+func deleteInventory(t *tables, inv *inventory.Inventory) error {
+	stored, ok := t.inventory.rows[inv.ProductID()]
+	if !ok || stored.version != inv.Version() || stored.reservedQuantity != 0 || stored.backorderedQuantity != 0 {
+		return inventory.ErrConcurrentModification
+	}
+	t.inventory.delete(inv.ProductID())
+	return nil
+}
+
+// updateInventory writes an entity over its stored row with a version check
+func updateInventory(t *tables, inv *inventory.Inventory) error {
+	stored, ok := t.inventory.rows[inv.ProductID()]
+	if !ok || stored.version != inv.Version() {
+		return inventory.ErrConcurrentModification
+	}
+
+	row := toInventoryRow(inv)
+	if err := checkInventoryRow(row); err != nil {
+		return err
+	}
+	row.id = stored.id
+	row.createdAt = stored.createdAt
+	row.version = stored.version + 1
+	t.inventory.set(row.productID, row)
+	return nil
+}
+
+// adjustInventory changes the quantity in place and allocates incoming stock to
+// open backorders first, mirroring the AdjustInventoryQuantity query
+func adjustInventory(t *tables, productID string, adjustment int, at time.Time) error {
+	row, ok := t.inventory.rows[productID]
+	if !ok {
+		return nil
+	}
+
+	row.quantity += adjustment
+	allocation := row.quantity - row.reservedQuantity
+	if allocation < 0 {
+		allocation = 0
+	}
+	if allocation > row.backorderedQuantity {
+		allocation = row.backorderedQuantity
+	}
+	row.reservedQuantity += allocation
+	row.backorderedQuantity -= allocation
+	row.updatedAt = at
+	row.version++
+
+	if err := checkInventoryRow(row); err != nil {
+		return err
+	}
+	t.inventory.set(productID, row)
+	return nil
+}
+
+// filterInventory returns the rows matching the filter conditions
+func filterInventory(t *tables, filter inventory.InventoryFilter) []inventoryRow {
+	rows := make([]inventoryRow, 0, len(t.inventory.rows))
+	for _, row := range t.inventory.rows {
+		if filter.Location != "" && row.location != filter.Location {
+			continue
+		}
+		if filter.ZeroStockOnly && row.quantity != 0 {
+			continue
+		}
+		if filter.AvailableBelow != nil && row.quantity-row.reservedQuantity >= *filter.AvailableBelow {
+			continue
+		}
+		if !filter.UpdatedSince.IsZero() && row.updatedAt.Before(filter.UpdatedSince) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// sortInventory orders rows like the ListInventory query, breaking ties by product ID
+func sortInventory(rows []inventoryRow, sortBy inventory.InventorySortField, desc bool) {
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		switch {
+		case sortBy == inventory.SortByQuantity && a.quantity != b.quantity:
+			return (a.quantity < b.quantity) != desc
+		case sortBy == inventory.SortByUpdatedAt && !a.updatedAt.Equal(b.updatedAt):
+			return a.updatedAt.Before(b.updatedAt) != desc
+		}
+		return (a.productID < b.productID) != desc
+	})
+}
+
+// toInventoryRow converts an inventory entity to its stored form
+func toInventoryRow(inv *inventory.Inventory) inventoryRow {
+	return inventoryRow{
+		id:                  inv.ID(),
+		productID:           inv.ProductID(),
+		quantity:            inv.Quantity(),
+		reservedQuantity:    inv.ReservedQuantity(),
+		backorderedQuantity: inv.BackorderedQuantity(),
+		stockPolicy:         string(inv.StockPolicy().Type()),
+		backorderLimit:      inv.StockPolicy().BackorderLimit(),
+		location:            inv.Location(),
+		metadata:            inv.Metadata(),
+		createdAt:           inv.CreatedAt(),
+		updatedAt:           inv.UpdatedAt(),
+		version:             inv.Version(),
+	}
+}
+
+// checkInventoryRow enforces the inventory table's check constraints
+func checkInventoryRow(row inventoryRow) error {
+	switch {
+	case row.quantity < 0:
+		return errCheckViolation("check_quantity_positive")
+	case row.reservedQuantity < 0:
+		return errCheckViolation("check_reserved_positive")
+	case row.reservedQuantity > row.quantity:
+		return errCheckViolation("check_reserved_lte_quantity")
+	case row.backorderedQuantity < 0:
+		return errCheckViolation("check_backordered_positive")
+	case row.backorderLimit < 0:
+		return errCheckViolation("check_backorder_limit_positive")
+	}
+	switch inventory.StockPolicyType(row.stockPolicy) {
+	case inventory.StockPolicyStrict, inventory.StockPolicyBackorder, inventory.StockPolicyUnlimited:
+		return nil
+	}
+	return errCheckViolation("check_stock_policy")
+}
+
+// toDomain rebuilds the inventory entity from the stored row
+// Rows whose policy fails validation fall back to the strict policy, like the SQL repository
+func (row inventoryRow) toDomain() *inventory.Inventory {
+	policy, err := inventory.NewStockPolicy(inventory.StockPolicyType(row.stockPolicy), row.backorderLimit)
+	if err != nil {
+		policy = inventory.StrictStockPolicy()
+	}
+	metadata := make(map[string]string, len(row.metadata))
+	for key, value := range row.metadata {
+		metadata[key] = value
+	}
+	return inventory.ReconstructInventory(
+		row.id,
+		row.productID,
+		row.quantity,
+		row.reservedQuantity,
+		row.backorderedQuantity,
+		policy,
+		row.location,
+		metadata,
+		row.createdAt,
+		row.updatedAt,
+		row.version,
+	)
+}
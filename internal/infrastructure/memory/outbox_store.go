@@ -0,0 +1,192 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/event"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/outbox"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// OutboxStore implements the outbox.Store interface in memory
+type OutboxStore struct {
+	session session
+}
+
+// NewOutboxStore creates a new instance of OutboxStore
+func NewOutboxStore(db *Database) outbox.Store {
+	return &OutboxStore{session: session{db: db}}
+}
+
+// Claim leases the oldest due message of each aggregate, oldest first
+// Leasing cannot fail, so the rows are leased in place rather than on a copy.
+func (s *OutboxStore) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*outbox.Message, error) {
+	s.session.db.mu.Lock()
+	defer s.session.db.mu.Unlock()
+
+	var due []*outboxRow
+	for _, t := range s.session.db.tenants {
+		blocked := make(map[[2]string]bool)
+		rows := t.outbox.mutable()
+		for i := range rows {
+			row := &rows[i]
+			key := [2]string{row.message.AggregateType, row.message.AggregateID}
+			if row.message.Status == outbox.StatusPublished || blocked[key] {
+				continue
+			}
+			blocked[key] = true
+			if row.message.Status == outbox.StatusPending &&
+				!row.message.NextAttemptAt.After(now) &&
+				!row.lockedUntil.After(now) {
+				due = append(due, row)
+			}
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].message.ID < due[j].message.ID })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	messages := make([]*outbox.Message, 0, len(due))
+	for _, row := range due {
+		row.lockedUntil = now.Add(lease)
+		messages = append(messages, copyOutboxMessage(row.message))
+	}
+	return messages, nil
+}
+
+// MarkPublished records that a claimed message was published
+func (s *OutboxStore) MarkPublished(ctx context.Context, id int64, at time.Time) error {
+	return s.updateByID(id, func(row *outboxRow) {
+		row.message.Status = outbox.StatusPublished
+		row.message.PublishedAt = at
+		row.lockedUntil = time.Time{}
+	})
+}
+
+// MarkFailed records a failed attempt to publish a claimed message
+func (s *OutboxStore) MarkFailed(ctx context.Context, id int64, attempts int, nextAttemptAt time.Time, lastError string, dead bool) error {
+	return s.updateByID(id, func(row *outboxRow) {
+		row.message.Attempts = attempts
+		row.message.NextAttemptAt = nextAttemptAt
+		row.message.LastError = lastError
+		if dead {
+			row.message.Status = outbox.StatusDead
+		}
+		row.lockedUntil = time.Time{}
+	})
+}
+
+// List returns the messages of the tenant of the context, oldest first
+func (s *OutboxStore) List(ctx context.Context, filter outbox.Filter) ([]*outbox.Message, error) {
+	messages := make([]*outbox.Message, 0)
+	err := s.session.read(ctx, func(t *tables) error {
+		skipped := 0
+		for _, row := range t.outbox.rows {
+			if len(messages) == filter.Limit {
+				break
+			}
+			if filter.Status != "" && row.message.Status != filter.Status {
+				continue
+			}
+			if skipped < filter.Offset {
+				skipped++
+				continue
+			}
+			messages = append(messages, copyOutboxMessage(row.message))
+		}
+		return nil
+	})
+	return messages, err
+}
+
+// Count returns how many messages of the tenant of the context match the filter
+func (s *OutboxStore) Count(ctx context.Context, filter outbox.Filter) (int, error) {
+	count := 0
+	err := s.session.read(ctx, func(t *tables) error {
+		for _, row := range t.outbox.rows {
+			if filter.Status == "" || row.message.Status == filter.Status {
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Replay makes a dead message of the tenant of the context pending again
+func (s *OutboxStore) Replay(ctx context.Context, id int64, now time.Time) (bool, error) {
+	replayed := false
+	err := s.session.write(ctx, func(t *tables) error {
+		rows := t.outbox.mutable()
+		for i := range rows {
+			row := &rows[i]
+			if row.message.ID != id || row.message.Status != outbox.StatusDead {
+				continue
+			}
+			row.message.Status = outbox.StatusPending
+			row.message.Attempts = 0
+			row.message.NextAttemptAt = now
+			row.message.LastError = ""
+			row.lockedUntil = time.Time{}
+			replayed = true
+		}
+		return nil
+	})
+	return replayed, err
+}
+
+// DeletePublished removes messages published before the given time
+func (s *OutboxStore) DeletePublished(ctx context.Context, before time.Time) (int, error) {
+	deleted := 0
+	err := s.session.writeAll(func(t *tables) error {
+		kept := make([]outboxRow, 0, len(t.outbox.rows))
+		for _, row := range t.outbox.rows {
+			if row.message.Status == outbox.StatusPublished && row.message.PublishedAt.Before(before) {
+				deleted++
+				continue
+			}
+			kept = append(kept, row)
+		}
+		t.outbox.replace(kept)
+		return nil
+	})
+	return deleted, err
+}
+
+// updateByID applies fn to the message with the given ID, whatever its tenant
+func (s *OutboxStore) updateByID(id int64, fn func(row *outboxRow)) error {
+	return s.session.writeAll(func(t *tables) error {
+		for i := range t.outbox.rows {
+			if t.outbox.rows[i].message.ID == id {
+				fn(&t.outbox.mutable()[i])
+			}
+		}
+		return nil
+	})
+}
+
+// appendOutbox stores events as pending messages of the tenant of the context in t
+// Repositories call it from their writes, which hold the database lock the
+// outbox sequence is guarded by.
+func (s session) appendOutbox(ctx context.Context, t *tables, aggregateType string, events []event.Event) error {
+	messages, err := outbox.NewMessages(tenant.ID(ctx), aggregateType, events, time.Now().UTC())
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.CodeInternalError, "failed to encode event")
+	}
+	for _, message := range messages {
+		s.db.outboxSequence++
+		message.ID = s.db.outboxSequence
+		t.outbox.append(outboxRow{message: *message})
+	}
+	return nil
+}
+
+// copyOutboxMessage copies a message so stored messages never share state with callers
+func copyOutboxMessage(message outbox.Message) *outbox.Message {
+	message.Payload = append([]byte(nil), message.Payload...)
+	return &message
+}
@@ -0,0 +1,174 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+)
+
+// ProductRepository implements both ProductCommandRepository and ProductQueryRepository in memory
+type ProductRepository struct {
+	session session
+}
+
+// NewProductCommandRepository creates a new instance for command operations
+func NewProductCommandRepository(db *Database) product.ProductCommandRepository {
+	return &ProductRepository{session: session{db: db}}
+}
+
+// NewProductQueryRepository creates a new instance for query operations
+func NewProductQueryRepository(db *Database) product.ProductQueryRepository {
+	return &ProductRepository{session: session{db: db}}
+}
+
+// Create stores a new product and its pending events in the outbox
+func (r *ProductRepository) Create(ctx context.Context, prod *product.Product) error {
+	return r.session.write(ctx, func(t *tables) error {
+		if _, exists := t.products.rows[prod.ID()]; exists {
+			return errUniqueViolation("products_pkey")
+		}
+		row := toProductRow(prod)
+		if err := checkProductRow(row); err != nil {
+			return err
+		}
+		t.products.set(row.id, row)
+		return r.session.appendOutbox(ctx, t, product.AggregateType, prod.PendingEvents())
+	})
+}
+
+// GetByID retrieves a product by its ID
+func (r *ProductRepository) GetByID(ctx context.Context, id string) (*product.Product, error) {
+	var prod *product.Product
+	err := r.session.read(ctx, func(t *tables) error {
+		row, ok := t.products.rows[id]
+		if !ok {
+			return nil // Product not found
+		}
+		var err error
+		prod, err = row.toDomain()
+		return err
+	})
+	return prod, err
+}
+
+// GetByIDs retrieves the products with the given identifiers
+func (r *ProductRepository) GetByIDs(ctx context.Context, ids []string) ([]*product.Product, error) {
+	products := make([]*product.Product, 0, len(ids))
+	err := r.session.read(ctx, func(t *tables) error {
+		seen := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			row, ok := t.products.rows[id]
+			if !ok || seen[id] {
+				continue
+			}
+			seen[id] = true
+			prod, err := row.toDomain()
+			if err != nil {
+				return err
+			}
+			products = append(products, prod)
+		}
+		return nil
+	})
+	return products, err
+}
+
+// Update updates an existing product if the stored version still matches the entity's version
+func (r *ProductRepository) Update(ctx context.Context, prod *product.Product) error {
+	return r.session.write(ctx, func(t *tables) error {
+		stored, ok := t.products.rows[prod.ID()]
+		if !ok || stored.version != prod.Version() {
+			return product.ErrConcurrentModification
+		}
+		row := toProductRow(prod)
+		if err := checkProductRow(row); err != nil {
+			return err
+		}
+		row.createdAt = stored.createdAt
+		row.version = stored.version + 1
+		t.products.set(row.id, row)
+		return r.session.appendOutbox(ctx, t, product.AggregateType, prod.PendingEvents())
+	})
+}
+
+// Delete removes a product together with the rows referencing it
+func (r *ProductRepository) Delete(ctx context.Context, id string) error {
+	return r.session.write(ctx, func(t *tables) error {
+		t.deleteProduct(id)
+		return nil
+	})
+}
+
+// List retrieves products ordered from newest to oldest
+func (r *ProductRepository) List(ctx context.Context, limit, offset int) ([]*product.Product, error) {
+	var products []*product.Product
+	err := r.session.read(ctx, func(t *tables) error {
+		rows := make([]productRow, 0, len(t.products.rows))
+		for _, row := range t.products.rows {
+			rows = append(rows, row)
+		}
+		sort.Slice(rows, func(i, j int) bool {
+			if !rows[i].createdAt.Equal(rows[j].createdAt) {
+				return rows[i].createdAt.After(rows[j].createdAt)
+			}
+			return rows[i].id < rows[j].id
+		})
+
+		rows = paginate(rows, limit, offset)
+		products = make([]*product.Product, 0, len(rows))
+		for _, row := range rows {
+			prod, err := row.toDomain()
+			if err != nil {
+				return err
+			}
+			products = append(products, prod)
+		}
+		return nil
+	})
+	return products, err
+}
+
+// toProductRow converts a product entity to its stored form
+func toProductRow(prod *product.Product) productRow {
+	return productRow{
+		id:            prod.ID(),
+		name:          prod.Name(),
+		priceAmount:   prod.Price().Amount(),
+		priceCurrency: prod.Price().Currency(),
+		createdAt:     prod.CreatedAt(),
+		updatedAt:     prod.UpdatedAt(),
+		version:       prod.Version(),
+	}
+}
+
+// checkProductRow enforces the products table's check constraints
+func checkProductRow(row productRow) error {
+	if row.priceAmount < 0 {
+		return errCheckViolation("products_price_amount_check")
+	}
+	return nil
+}
+
+// toDomain rebuilds the product entity from the stored row
+func (row productRow) toDomain() (*product.Product, error) {
+	price, err := product.NewPrice(row.priceAmount, row.priceCurrency)
+	if err != nil {
+		return nil, err
+	}
+	return product.ReconstructProduct(row.id, row.name, price, row.createdAt, row.updatedAt, row.version), nil
+}
+
+// paginate applies LIMIT and OFFSET semantics to sorted rows
+func paginate[T any](rows []T, limit, offset int) []T {
+	if offset >= len(rows) {
+		return nil
+	}
+	if offset > 0 {
+		rows = rows[offset:]
+	}
+	if limit > 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
@@ -0,0 +1,258 @@
+package memory_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/memory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+func newProduct(t *testing.T, id string) *product.Product {
+	t.Helper()
+	price, err := product.NewPrice(10, "USD")
+	if err != nil {
+		t.Fatalf("NewPrice() error = %v", err)
+	}
+	prod, err := product.NewProduct(id, "Product "+id, price)
+	if err != nil {
+		t.Fatalf("NewProduct() error = %v", err)
+	}
+	return prod
+}
+
+func newInventory(t *testing.T, id, productID string, quantity int) *inventory.Inventory {
+	t.Helper()
+	inv, err := inventory.NewInventory(id, productID, quantity, "WH-1")
+	if err != nil {
+		t.Fatalf("NewInventory() error = %v", err)
+	}
+	return inv
+}
+
+func seedProduct(t *testing.T, db *memory.Database, id string) {
+	t.Helper()
+	if err := memory.NewProductCommandRepository(db).Create(context.Background(), newProduct(t, id)); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+}
+
+func TestProductRepository_CreateRejectsDuplicateID(t *testing.T) {
+	db := memory.NewDatabase()
+	seedProduct(t, db, "prod-1")
+
+	err := memory.NewProductCommandRepository(db).Create(context.Background(), newProduct(t, "prod-1"))
+	if !errors.Is(err, errors.CodeProductAlreadyExists) {
+		t.Fatalf("Create() error code = %s, want %s", errors.GetCode(err), errors.CodeProductAlreadyExists)
+	}
+}
+
+func TestProductRepository_UpdateDetectsStaleVersion(t *testing.T) {
+	ctx := context.Background()
+	db := memory.NewDatabase()
+	seedProduct(t, db, "prod-1")
+	repo := memory.NewProductQueryRepository(db)
+	commands := memory.NewProductCommandRepository(db)
+
+	first, _ := repo.GetByID(ctx, "prod-1")
+	second, _ := repo.GetByID(ctx, "prod-1")
+
+	if err := commands.Update(ctx, first); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := commands.Update(ctx, second); err != product.ErrConcurrentModification {
+		t.Fatalf("Update() error = %v, want %v", err, product.ErrConcurrentModification)
+	}
+
+	stored, _ := repo.GetByID(ctx, "prod-1")
+	if stored.Version() != first.Version()+1 {
+		t.Errorf("Version() = %d, want %d", stored.Version(), first.Version()+1)
+	}
+}
+
+func TestInventoryRepository_CreateEnforcesForeignKeyAndUniqueProduct(t *testing.T) {
+	ctx := context.Background()
+	db := memory.NewDatabase()
+	repo := memory.NewInventoryCommandRepository(db)
+
+	err := repo.Create(ctx, newInventory(t, "inv-1", "missing", 5))
+	if !errors.Is(err, errors.CodeProductNotFound) {
+		t.Fatalf("Create() without product error code = %s, want %s", errors.GetCode(err), errors.CodeProductNotFound)
+	}
+
+	seedProduct(t, db, "prod-1")
+	if err := repo.Create(ctx, newInventory(t, "inv-1", "prod-1", 5)); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	err = repo.Create(ctx, newInventory(t, "inv-2", "prod-1", 5))
+	if !errors.Is(err, errors.CodeInventoryExists) {
+		t.Fatalf("Create() duplicate product error code = %s, want %s", errors.GetCode(err), errors.CodeInventoryExists)
+	}
+}
+
+func TestInventoryRepository_AdjustStockEnforcesCheckConstraints(t *testing.T) {
+	ctx := context.Background()
+	db := memory.NewDatabase()
+	seedProduct(t, db, "prod-1")
+	commands := memory.NewInventoryCommandRepository(db)
+	queries := memory.NewInventoryQueryRepository(db)
+
+	inv := newInventory(t, "inv-1", "prod-1", 5)
+	if err := inv.Reserve(4); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if err := commands.Create(ctx, inv); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	err := commands.AdjustStock(ctx, "prod-1", -2)
+	if !errors.Is(err, errors.CodeInsufficientStock) {
+		t.Fatalf("AdjustStock() error code = %s, want %s", errors.GetCode(err), errors.CodeInsufficientStock)
+	}
+	if got := errors.GetConstraint(err); got != "check_reserved_lte_quantity" {
+		t.Errorf("GetConstraint() = %q, want check_reserved_lte_quantity", got)
+	}
+
+	stored, _ := queries.GetByProductID(ctx, "prod-1")
+	if stored.Quantity() != 5 || stored.Version() != inv.Version() {
+		t.Errorf("failed adjustment changed the row: quantity = %d, version = %d", stored.Quantity(), stored.Version())
+	}
+}
+
+func TestInventoryRepository_UpdateBatchIsAllOrNothing(t *testing.T) {
+	ctx := context.Background()
+	db := memory.NewDatabase()
+	commands := memory.NewInventoryCommandRepository(db)
+	queries := memory.NewInventoryQueryRepository(db)
+
+	for _, id := range []string{"prod-1", "prod-2"} {
+		seedProduct(t, db, id)
+		if err := commands.Create(ctx, newInventory(t, "inv-"+id, id, 5)); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	first, _ := queries.GetByProductID(ctx, "prod-1")
+	second, _ := queries.GetByProductID(ctx, "prod-2")
+	_ = first.AdjustQuantity(3)
+	_ = second.AdjustQuantity(3)
+	if err := commands.AdjustStock(ctx, "prod-2", 1); err != nil {
+		t.Fatalf("AdjustStock() error = %v", err)
+	}
+
+	if err := commands.UpdateBatch(ctx, []*inventory.Inventory{first, second}); err != inventory.ErrConcurrentModification {
+		t.Fatalf("UpdateBatch() error = %v, want %v", err, inventory.ErrConcurrentModification)
+	}
+	stored, _ := queries.GetByProductID(ctx, "prod-1")
+	if stored.Quantity() != 5 {
+		t.Errorf("Quantity() = %d, want 5 after rolled back batch", stored.Quantity())
+	}
+}
+
+func TestInventoryRepository_ListFiltersSortsAndPaginates(t *testing.T) {
+	ctx := context.Background()
+	db := memory.NewDatabase()
+	commands := memory.NewInventoryCommandRepository(db)
+	queries := memory.NewInventoryQueryRepository(db)
+
+	for i, quantity := range []int{7, 0, 3, 9} {
+		id := fmt.Sprintf("prod-%d", i)
+		seedProduct(t, db, id)
+		if err := commands.Create(ctx, newInventory(t, "inv-"+id, id, quantity)); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	below := 8
+	filter := inventory.InventoryFilter{AvailableBelow: &below, SortBy: inventory.SortByQuantity, SortDesc: true, Limit: 2}
+	page, err := queries.List(ctx, filter)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(page) != 2 || page[0].Quantity() != 7 || page[1].Quantity() != 3 {
+		t.Fatalf("List() returned unexpected page")
+	}
+
+	count, err := queries.Count(ctx, filter)
+	if err != nil {
+		t.Fatalf("Count() error = %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Count() = %d, want 3", count)
+	}
+}
+
+func TestProductRepository_DeleteCascades(t *testing.T) {
+	ctx := context.Background()
+	db := memory.NewDatabase()
+	seedProduct(t, db, "prod-1")
+	if err := memory.NewInventoryCommandRepository(db).Create(ctx, newInventory(t, "inv-1", "prod-1", 5)); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := memory.NewProductCommandRepository(db).Delete(ctx, "prod-1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	inv, err := memory.NewInventoryQueryRepository(db).GetByProductID(ctx, "prod-1")
+	if err != nil || inv != nil {
+		t.Errorf("GetByProductID() = %v, %v; want nil, nil", inv, err)
+	}
+}
+
+func TestUnitOfWork_RollsBackOnError(t *testing.T) {
+	ctx := context.Background()
+	db := memory.NewDatabase()
+	seedProduct(t, db, "prod-1")
+	uow := memory.NewUnitOfWork(db)
+
+	failure := errors.New(errors.CodeInternalError, "boom")
+	err := uow.Do(ctx, func(ctx context.Context, repos inventory.TxRepositories) error {
+		if err := repos.InventoryCommands.Create(ctx, newInventory(t, "inv-1", "prod-1", 5)); err != nil {
+			return err
+		}
+		return failure
+	})
+	if err != failure {
+		t.Fatalf("Do() error = %v, want %v", err, failure)
+	}
+
+	inv, _ := memory.NewInventoryQueryRepository(db).GetByProductID(ctx, "prod-1")
+	if inv != nil {
+		t.Errorf("GetByProductID() = %v, want nil after rollback", inv)
+	}
+}
+
+func TestInventoryRepository_ConcurrentAdjustments(t *testing.T) {
+	ctx := context.Background()
+	db := memory.NewDatabase()
+	seedProduct(t, db, "prod-1")
+	commands := memory.NewInventoryCommandRepository(db)
+	queries := memory.NewInventoryQueryRepository(db)
+	if err := commands.Create(ctx, newInventory(t, "inv-1", "prod-1", 0)); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = commands.AdjustStock(ctx, "prod-1", 1)
+		}()
+		go func() {
+			defer wg.Done()
+			_, _ = queries.GetByProductID(ctx, "prod-1")
+		}()
+	}
+	wg.Wait()
+
+	inv, _ := queries.GetByProductID(ctx, "prod-1")
+	if inv.Quantity() != 50 {
+		t.Errorf("Quantity() = %d, want 50", inv.Quantity())
+	}
+}
@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+)
+
+// StocktakeRepository implements both StocktakeCommandRepository and StocktakeQueryRepository in memory
+type StocktakeRepository struct {
+	session session
+}
+
+// NewStocktakeCommandRepository creates a new instance for command operations
+func NewStocktakeCommandRepository(db *Database) inventory.StocktakeCommandRepository {
+	return &StocktakeRepository{session: session{db: db}}
+}
+
+// NewStocktakeQueryRepository creates a new instance for query operations
+func NewStocktakeQueryRepository(db *Database) inventory.StocktakeQueryRepository {
+	return &StocktakeRepository{session: session{db: db}}
+}
+
+// Create stores a new stocktake session and its snapshot lines
+func (r *StocktakeRepository) Create(ctx context.Context, st *inventory.Stocktake) error {
+	return r.session.write(ctx, func(t *tables) error {
+		if _, exists := t.stocktakes.rows[st.ID()]; exists {
+			return errUniqueViolation("stocktakes_pkey")
+		}
+		return saveStocktake(t, st, st.CreatedAt())
+	})
+}
+
+// Update persists counts, approvals and the status of a stocktake session
+func (r *StocktakeRepository) Update(ctx context.Context, st *inventory.Stocktake) error {
+	return r.session.write(ctx, func(t *tables) error {
+		stored, ok := t.stocktakes.rows[st.ID()]
+		if !ok || stored.status != string(inventory.StocktakeStatusOpen) {
+			return inventory.ErrStocktakeNotOpen
+		}
+		return saveStocktake(t, st, stored.createdAt)
+	})
+}
+
+// Apply stores the applied session; its adjusted inventories are saved by the caller
+func (r *StocktakeRepository) Apply(ctx context.Context, st *inventory.Stocktake) error {
+	return r.session.write(ctx, func(t *tables) error {
+		stored, ok := t.stocktakes.rows[st.ID()]
+		if !ok || stored.status != string(inventory.StocktakeStatusOpen) {
+			return inventory.ErrStocktakeNotOpen
+		}
+		return saveStocktake(t, st, stored.createdAt)
+	})
+}
+
+// GetByID retrieves a stocktake session with its lines
+func (r *StocktakeRepository) GetByID(ctx context.Context, id string) (*inventory.Stocktake, error) {
+	var st *inventory.Stocktake
+	err := r.session.read(ctx, func(t *tables) error {
+		row, ok := t.stocktakes.rows[id]
+		if !ok {
+			return nil // Stocktake not found
+		}
+
+		lines := make([]*inventory.StocktakeLine, 0, len(row.lines))
+		for _, line := range row.lines {
+			lines = append(lines, inventory.ReconstructStocktakeLine(
+				line.productID,
+				line.location,
+				line.expectedQuantity,
+				line.countedQuantity,
+				line.counted,
+				line.countAttempts,
+				line.approved,
+				line.countedAt,
+			))
+		}
+		st = inventory.ReconstructStocktake(
+			row.id,
+			inventory.StocktakeStatus(row.status),
+			lines,
+			row.createdAt,
+			row.updatedAt,
+			row.appliedAt,
+		)
+		return nil
+	})
+	return st, err
+}
+
+// saveStocktake validates and stores the session header with all of its lines
+func saveStocktake(t *tables, st *inventory.Stocktake, createdAt time.Time) error {
+	switch st.Status() {
+	case inventory.StocktakeStatusOpen, inventory.StocktakeStatusApplied, inventory.StocktakeStatusCancelled:
+	default:
+		return errCheckViolation("check_stocktake_status")
+	}
+
+	lines := make([]stocktakeLineRow, 0, len(st.Lines()))
+	for _, line := range st.Lines() {
+		if _, exists := t.products.rows[line.ProductID()]; !exists {
+			return errForeignKeyViolation("fk_stocktake_product")
+		}
+		if line.IsCounted() && line.CountedQuantity() < 0 {
+			return errCheckViolation("check_counted_quantity_positive")
+		}
+		lines = append(lines, stocktakeLineRow{
+			productID:        line.ProductID(),
+			location:         line.Location(),
+			expectedQuantity: line.ExpectedQuantity(),
+			countedQuantity:  line.CountedQuantity(),
+			counted:          line.IsCounted(),
+			countAttempts:    line.CountAttempts(),
+			approved:         line.IsApproved(),
+			countedAt:        line.CountedAt(),
+		})
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i].productID < lines[j].productID })
+
+	t.stocktakes.set(st.ID(), stocktakeRow{
+		id:        st.ID(),
+		status:    string(st.Status()),
+		lines:     lines,
+		createdAt: createdAt,
+		updatedAt: st.UpdatedAt(),
+		appliedAt: st.AppliedAt(),
+	})
+	return nil
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+)
+
+// UnitOfWork implements inventory.UnitOfWork in memory
+// Units of work hold the database lock for their whole duration, so every
+// isolation level behaves as serializable and no retries are ever needed.
+type UnitOfWork struct {
+	db *Database
+	// eventSourced binds the inventory repositories to the inventory event streams
+	eventSourced  bool
+	snapshotEvery int
+}
+
+// NewUnitOfWork creates a new instance of UnitOfWork
+func NewUnitOfWork(db *Database) inventory.UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// NewEventSourcedUnitOfWork creates a UnitOfWork whose inventory repositories work on the
+// inventory event streams, snapshotting every snapshotEvery events
+func NewEventSourcedUnitOfWork(db *Database, snapshotEvery int) inventory.UnitOfWork {
+	return &UnitOfWork{db: db, eventSourced: true, snapshotEvery: snapshotEvery}
+}
+
+// Do runs fn against a working copy of the tenant's tables that is kept only if fn succeeds
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos inventory.TxRepositories) error) error {
+	return u.DoWithIsolation(ctx, inventory.IsolationSerializable, fn)
+}
+
+// DoWithIsolation runs fn like Do; the isolation level is always serializable
+func (u *UnitOfWork) DoWithIsolation(
+	ctx context.Context,
+	level inventory.IsolationLevel,
+	fn func(ctx context.Context, repos inventory.TxRepositories) error,
+) error {
+	return transact(ctx, u.db, func(tx session) error {
+		repos := inventory.TxRepositories{
+			InventoryCommands: &InventoryRepository{session: tx},
+			InventoryQueries:  &InventoryRepository{session: tx},
+			StocktakeCommands: &StocktakeRepository{session: tx},
+			StocktakeQueries:  &StocktakeRepository{session: tx},
+			ValuationCommands: &ValuationRepository{session: tx},
+			ValuationQueries:  &ValuationRepository{session: tx},
+			Audit:             &AuditStore{session: tx},
+		}
+		if u.eventSourced {
+			inventoryRepo := newEventSourcedInventoryRepository(tx, u.snapshotEvery)
+			repos.InventoryCommands = inventoryRepo
+			repos.InventoryQueries = inventoryRepo
+		}
+		return fn(ctx, repos)
+	})
+}
+
+// ProductUnitOfWork implements product.UnitOfWork in memory
+type ProductUnitOfWork struct {
+	db *Database
+}
+
+// NewProductUnitOfWork creates a new instance of ProductUnitOfWork
+func NewProductUnitOfWork(db *Database) product.UnitOfWork {
+	return &ProductUnitOfWork{db: db}
+}
+
+// Do runs fn against a working copy of the tenant's tables that is kept only if fn succeeds
+func (u *ProductUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos product.TxRepositories) error) error {
+	return transact(ctx, u.db, func(tx session) error {
+		return fn(ctx, product.TxRepositories{
+			ProductCommands: &ProductRepository{session: tx},
+			Audit:           &AuditStore{session: tx},
+		})
+	})
+}
+
+// transact runs fn with a session on a working copy of the tenant's tables,
+// and keeps the copy only if fn succeeds
+func transact(ctx context.Context, db *Database, fn func(tx session) error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tenantID := tenant.ID(ctx)
+	work := db.tablesOf(tenantID).clone()
+	if err := fn(session{db: db, tx: work}); err != nil {
+		return err
+	}
+
+	db.tenants[tenantID] = work
+	return nil
+}
@@ -0,0 +1,178 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+)
+
+// ValuationRepository implements both ValuationCommandRepository and ValuationQueryRepository in memory
+type ValuationRepository struct {
+	session session
+}
+
+// NewValuationCommandRepository creates a new instance for command operations
+func NewValuationCommandRepository(db *Database) inventory.ValuationCommandRepository {
+	return &ValuationRepository{session: session{db: db}}
+}
+
+// NewValuationQueryRepository creates a new instance for query operations
+func NewValuationQueryRepository(db *Database) inventory.ValuationQueryRepository {
+	return &ValuationRepository{session: session{db: db}}
+}
+
+// Save upserts the valuation totals and every loaded cost layer
+// Like the SQL upsert, the costing method and currency of an existing valuation are kept
+func (r *ValuationRepository) Save(ctx context.Context, v *inventory.StockValuation) error {
+	return r.session.write(ctx, func(t *tables) error {
+		if _, exists := t.products.rows[v.ProductID()]; !exists {
+			return errForeignKeyViolation("fk_inventory_valuations_product")
+		}
+
+		row := valuationRow{
+			productID:     v.ProductID(),
+			costingMethod: string(v.Method()),
+			currency:      v.Currency(),
+			quantity:      v.Quantity(),
+			totalCost:     v.TotalCost(),
+			consumedCost:  v.ConsumedCost(),
+			updatedAt:     v.UpdatedAt(),
+		}
+		if stored, ok := t.valuations.rows[row.productID]; ok {
+			row.costingMethod = stored.costingMethod
+			row.currency = stored.currency
+		}
+		if err := checkValuationRow(row); err != nil {
+			return err
+		}
+		t.valuations.set(row.productID, row)
+
+		for _, layer := range v.Layers() {
+			layerRow := costLayerRow{
+				id:                layer.ID(),
+				productID:         v.ProductID(),
+				receivedQuantity:  layer.ReceivedQuantity(),
+				remainingQuantity: layer.RemainingQuantity(),
+				unitCost:          layer.UnitCost(),
+				receivedAt:        layer.ReceivedAt(),
+			}
+			if stored, ok := t.costLayers.rows[layerRow.id]; ok && stored.productID != layerRow.productID {
+				return errUniqueViolation("inventory_cost_layers_pkey")
+			}
+			if err := checkCostLayerRow(layerRow); err != nil {
+				return err
+			}
+			t.costLayers.set(layerRow.id, layerRow)
+		}
+		return nil
+	})
+}
+
+// Delete removes the valuation of a product together with its cost layers
+func (r *ValuationRepository) Delete(ctx context.Context, productID string) error {
+	return r.session.write(ctx, func(t *tables) error {
+		t.deleteValuation(productID)
+		return nil
+	})
+}
+
+// GetByProductID retrieves a valuation with its open cost layers
+func (r *ValuationRepository) GetByProductID(ctx context.Context, productID string) (*inventory.StockValuation, error) {
+	var valuation *inventory.StockValuation
+	err := r.session.read(ctx, func(t *tables) error {
+		if row, ok := t.valuations.rows[productID]; ok {
+			valuation = row.toDomain(openCostLayers(t)[productID])
+		}
+		return nil
+	})
+	return valuation, err
+}
+
+// List retrieves every valuation with its open cost layers, ordered by product ID
+func (r *ValuationRepository) List(ctx context.Context) ([]*inventory.StockValuation, error) {
+	var valuations []*inventory.StockValuation
+	err := r.session.read(ctx, func(t *tables) error {
+		rows := make([]valuationRow, 0, len(t.valuations.rows))
+		for _, row := range t.valuations.rows {
+			rows = append(rows, row)
+		}
+		sort.Slice(rows, func(i, j int) bool { return rows[i].productID < rows[j].productID })
+
+		layers := openCostLayers(t)
+		valuations = make([]*inventory.StockValuation, 0, len(rows))
+		for _, row := range rows {
+			valuations = append(valuations, row.toDomain(layers[row.productID]))
+		}
+		return nil
+	})
+	return valuations, err
+}
+
+// openCostLayers groups the layers with remaining stock by product, oldest first
+func openCostLayers(t *tables) map[string][]costLayerRow {
+	layers := make(map[string][]costLayerRow)
+	for _, layer := range t.costLayers.rows {
+		if layer.remainingQuantity > 0 {
+			layers[layer.productID] = append(layers[layer.productID], layer)
+		}
+	}
+	for _, productLayers := range layers {
+		sort.Slice(productLayers, func(i, j int) bool {
+			if !productLayers[i].receivedAt.Equal(productLayers[j].receivedAt) {
+				return productLayers[i].receivedAt.Before(productLayers[j].receivedAt)
+			}
+			return productLayers[i].id < productLayers[j].id
+		})
+	}
+	return layers
+}
+
+// checkValuationRow enforces the inventory_valuations table's check constraints
+func checkValuationRow(row valuationRow) error {
+	if _, err := inventory.ParseCostingMethod(row.costingMethod); err != nil {
+		return errCheckViolation("check_costing_method")
+	}
+	if row.quantity < 0 {
+		return errCheckViolation("check_valuation_quantity_positive")
+	}
+	if row.totalCost < 0 {
+		return errCheckViolation("check_valuation_total_cost_positive")
+	}
+	return nil
+}
+
+// checkCostLayerRow enforces the inventory_cost_layers table's check constraints
+func checkCostLayerRow(row costLayerRow) error {
+	if row.remainingQuantity < 0 || row.remainingQuantity > row.receivedQuantity {
+		return errCheckViolation("check_layer_remaining")
+	}
+	if row.unitCost < 0 {
+		return errCheckViolation("check_layer_unit_cost_positive")
+	}
+	return nil
+}
+
+// toDomain rebuilds the valuation from the stored rows
+func (row valuationRow) toDomain(layerRows []costLayerRow) *inventory.StockValuation {
+	layers := make([]*inventory.CostLayer, 0, len(layerRows))
+	for _, layer := range layerRows {
+		layers = append(layers, inventory.ReconstructCostLayer(
+			layer.id,
+			layer.receivedQuantity,
+			layer.remainingQuantity,
+			layer.unitCost,
+			layer.receivedAt,
+		))
+	}
+	return inventory.ReconstructStockValuation(
+		row.productID,
+		inventory.CostingMethod(row.costingMethod),
+		row.currency,
+		row.quantity,
+		row.totalCost,
+		row.consumedCost,
+		layers,
+		row.updatedAt,
+	)
+}
@@ -0,0 +1,333 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/webhook"
+)
+
+// WebhookStore implements the webhook.Store interface in memory
+type WebhookStore struct {
+	session session
+}
+
+// NewWebhookStore creates a new instance of WebhookStore
+func NewWebhookStore(db *Database) webhook.Store {
+	return &WebhookStore{session: session{db: db}}
+}
+
+// CreateSubscription stores a new subscription for the tenant of the context
+func (s *WebhookStore) CreateSubscription(ctx context.Context, sub *webhook.Subscription) error {
+	return s.session.write(ctx, func(t *tables) error {
+		if _, exists := t.webhooks.rows[sub.ID]; exists {
+			return errUniqueViolation("webhook_subscriptions_pkey")
+		}
+		stored := copySubscription(*sub)
+		stored.TenantID = tenant.ID(ctx)
+		t.webhooks.set(sub.ID, *stored)
+		return nil
+	})
+}
+
+// GetSubscription returns a subscription of the tenant of the context, or nil if there is none
+func (s *WebhookStore) GetSubscription(ctx context.Context, id string) (*webhook.Subscription, error) {
+	var found *webhook.Subscription
+	err := s.session.read(ctx, func(t *tables) error {
+		if sub, ok := t.webhooks.rows[id]; ok {
+			found = copySubscription(sub)
+		}
+		return nil
+	})
+	return found, err
+}
+
+// ListSubscriptions returns the subscriptions of the tenant of the context, oldest first
+func (s *WebhookStore) ListSubscriptions(ctx context.Context) ([]*webhook.Subscription, error) {
+	subscriptions := make([]*webhook.Subscription, 0)
+	err := s.session.read(ctx, func(t *tables) error {
+		for _, sub := range t.webhooks.rows {
+			subscriptions = append(subscriptions, copySubscription(sub))
+		}
+		return nil
+	})
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if !subscriptions[i].CreatedAt.Equal(subscriptions[j].CreatedAt) {
+			return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+		}
+		return subscriptions[i].ID < subscriptions[j].ID
+	})
+	return subscriptions, err
+}
+
+// UpdateSubscription stores the changeable fields of a subscription
+func (s *WebhookStore) UpdateSubscription(ctx context.Context, sub *webhook.Subscription) error {
+	return s.session.write(ctx, func(t *tables) error {
+		stored, ok := t.webhooks.rows[sub.ID]
+		if !ok {
+			return nil
+		}
+		updated := copySubscription(*sub)
+		updated.TenantID = stored.TenantID
+		updated.CreatedAt = stored.CreatedAt
+		t.webhooks.set(sub.ID, *updated)
+		return nil
+	})
+}
+
+// DeleteSubscription removes a subscription and cascades to its deliveries
+func (s *WebhookStore) DeleteSubscription(ctx context.Context, id string) (bool, error) {
+	deleted := false
+	err := s.session.write(ctx, func(t *tables) error {
+		if _, ok := t.webhooks.rows[id]; !ok {
+			return nil
+		}
+		t.webhooks.delete(id)
+		kept := make([]webhookDeliveryRow, 0, len(t.deliveries.rows))
+		for _, row := range t.deliveries.rows {
+			if row.delivery.SubscriptionID != id {
+				kept = append(kept, row)
+			}
+		}
+		t.deliveries.replace(kept)
+		deleted = true
+		return nil
+	})
+	return deleted, err
+}
+
+// Enqueue stores pending deliveries, skipping messages their subscription already has
+func (s *WebhookStore) Enqueue(ctx context.Context, deliveries []*webhook.Delivery) error {
+	return s.session.write(ctx, func(t *tables) error {
+		for _, d := range deliveries {
+			sub, ok := t.webhooks.rows[d.SubscriptionID]
+			if !ok {
+				return errForeignKeyViolation("webhook_deliveries_subscription_id_fkey")
+			}
+			if t.hasDelivery(d.SubscriptionID, d.MessageID) {
+				continue
+			}
+			s.session.db.webhookSequence++
+			t.deliveries.append(webhookDeliveryRow{delivery: webhook.Delivery{
+				ID:             s.session.db.webhookSequence,
+				TenantID:       sub.TenantID,
+				SubscriptionID: d.SubscriptionID,
+				MessageID:      d.MessageID,
+				EventName:      d.EventName,
+				Payload:        append([]byte(nil), d.Payload...),
+				Status:         webhook.DeliveryPending,
+				NextAttemptAt:  d.NextAttemptAt,
+				CreatedAt:      d.CreatedAt,
+			}})
+		}
+		return nil
+	})
+}
+
+// Claim leases due deliveries of active subscriptions, oldest first
+// Leasing cannot fail, so the rows are leased in place rather than on a copy.
+func (s *WebhookStore) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*webhook.Delivery, error) {
+	s.session.db.mu.Lock()
+	defer s.session.db.mu.Unlock()
+
+	var due []*webhookDeliveryRow
+	for _, t := range s.session.db.tenants {
+		rows := t.deliveries.mutable()
+		for i := range rows {
+			row := &rows[i]
+			if row.delivery.Status == webhook.DeliveryPending &&
+				t.webhooks.rows[row.delivery.SubscriptionID].Active &&
+				!row.delivery.NextAttemptAt.After(now) &&
+				!row.lockedUntil.After(now) {
+				due = append(due, row)
+			}
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].delivery.ID < due[j].delivery.ID })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	deliveries := make([]*webhook.Delivery, 0, len(due))
+	for _, row := range due {
+		row.lockedUntil = now.Add(lease)
+		deliveries = append(deliveries, copyDelivery(row.delivery))
+	}
+	return deliveries, nil
+}
+
+// MarkDelivered records the successful attempt of a claimed delivery
+func (s *WebhookStore) MarkDelivered(ctx context.Context, id int64, attempts int, attempt webhook.Attempt) error {
+	return s.updateDeliveryByID(id, func(row *webhookDeliveryRow) {
+		row.delivery.Status = webhook.DeliveryDelivered
+		row.delivery.Attempts = attempts
+		row.delivery.LastStatusCode = attempt.StatusCode
+		row.delivery.LastError = ""
+		row.delivery.LastAttemptAt = attempt.At
+		row.delivery.DeliveredAt = attempt.At
+		row.lockedUntil = time.Time{}
+	})
+}
+
+// MarkFailed records a failed attempt of a claimed delivery
+func (s *WebhookStore) MarkFailed(ctx context.Context, id int64, attempts int, attempt webhook.Attempt, nextAttemptAt time.Time, failed bool) error {
+	return s.updateDeliveryByID(id, func(row *webhookDeliveryRow) {
+		if failed {
+			row.delivery.Status = webhook.DeliveryFailed
+		}
+		row.delivery.Attempts = attempts
+		row.delivery.LastStatusCode = attempt.StatusCode
+		row.delivery.LastError = attempt.Error
+		row.delivery.LastAttemptAt = attempt.At
+		row.delivery.NextAttemptAt = nextAttemptAt
+		row.lockedUntil = time.Time{}
+	})
+}
+
+// RecordSuccess resets the consecutive failures of a subscription
+func (s *WebhookStore) RecordSuccess(ctx context.Context, subscriptionID string) error {
+	return s.session.write(ctx, func(t *tables) error {
+		if sub, ok := t.webhooks.rows[subscriptionID]; ok && sub.ConsecutiveFailures != 0 {
+			sub.ConsecutiveFailures = 0
+			t.webhooks.set(subscriptionID, sub)
+		}
+		return nil
+	})
+}
+
+// RecordFailure counts a failed attempt and disables the subscription after disableAfter in a row
+func (s *WebhookStore) RecordFailure(ctx context.Context, subscriptionID string, disableAfter int, now time.Time) (bool, error) {
+	disabled := false
+	err := s.session.write(ctx, func(t *tables) error {
+		sub, ok := t.webhooks.rows[subscriptionID]
+		if !ok {
+			return nil
+		}
+		sub.ConsecutiveFailures++
+		if sub.Active && disableAfter > 0 && sub.ConsecutiveFailures >= disableAfter {
+			sub.Active = false
+			sub.DisabledAt = now
+			sub.UpdatedAt = now
+			disabled = true
+		}
+		t.webhooks.set(subscriptionID, sub)
+		return nil
+	})
+	return disabled, err
+}
+
+// ListDeliveries returns the delivery log of a subscription, newest first
+func (s *WebhookStore) ListDeliveries(ctx context.Context, subscriptionID string, filter webhook.DeliveryFilter) ([]*webhook.Delivery, error) {
+	deliveries := make([]*webhook.Delivery, 0)
+	err := s.session.read(ctx, func(t *tables) error {
+		skipped := 0
+		for i := len(t.deliveries.rows) - 1; i >= 0 && len(deliveries) < filter.Limit; i-- {
+			d := t.deliveries.rows[i].delivery
+			if !matchesDeliveryFilter(d, subscriptionID, filter) {
+				continue
+			}
+			if skipped < filter.Offset {
+				skipped++
+				continue
+			}
+			deliveries = append(deliveries, copyDelivery(d))
+		}
+		return nil
+	})
+	return deliveries, err
+}
+
+// CountDeliveries returns how many deliveries of a subscription match the filter
+func (s *WebhookStore) CountDeliveries(ctx context.Context, subscriptionID string, filter webhook.DeliveryFilter) (int, error) {
+	count := 0
+	err := s.session.read(ctx, func(t *tables) error {
+		for _, row := range t.deliveries.rows {
+			if matchesDeliveryFilter(row.delivery, subscriptionID, filter) {
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Redeliver makes a delivery of a subscription pending again
+func (s *WebhookStore) Redeliver(ctx context.Context, subscriptionID string, id int64, now time.Time) (bool, error) {
+	redelivered := false
+	err := s.session.write(ctx, func(t *tables) error {
+		rows := t.deliveries.mutable()
+		for i := range rows {
+			row := &rows[i]
+			if row.delivery.ID != id || row.delivery.SubscriptionID != subscriptionID {
+				continue
+			}
+			row.delivery.Status = webhook.DeliveryPending
+			row.delivery.Attempts = 0
+			row.delivery.NextAttemptAt = now
+			row.delivery.DeliveredAt = time.Time{}
+			row.lockedUntil = time.Time{}
+			redelivered = true
+		}
+		return nil
+	})
+	return redelivered, err
+}
+
+// DeleteDelivered removes deliveries delivered before the given time
+func (s *WebhookStore) DeleteDelivered(ctx context.Context, before time.Time) (int, error) {
+	deleted := 0
+	err := s.session.writeAll(func(t *tables) error {
+		kept := make([]webhookDeliveryRow, 0, len(t.deliveries.rows))
+		for _, row := range t.deliveries.rows {
+			if row.delivery.Status == webhook.DeliveryDelivered && row.delivery.DeliveredAt.Before(before) {
+				deleted++
+				continue
+			}
+			kept = append(kept, row)
+		}
+		t.deliveries.replace(kept)
+		return nil
+	})
+	return deleted, err
+}
+
+// updateDeliveryByID applies fn to the delivery with the given ID, whatever its tenant
+func (s *WebhookStore) updateDeliveryByID(id int64, fn func(row *webhookDeliveryRow)) error {
+	return s.session.writeAll(func(t *tables) error {
+		for i := range t.deliveries.rows {
+			if t.deliveries.rows[i].delivery.ID == id {
+				fn(&t.deliveries.mutable()[i])
+			}
+		}
+		return nil
+	})
+}
+
+// hasDelivery reports whether a subscription already has a delivery of a message
+func (t *tables) hasDelivery(subscriptionID string, messageID int64) bool {
+	for _, row := range t.deliveries.rows {
+		if row.delivery.SubscriptionID == subscriptionID && row.delivery.MessageID == messageID {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesDeliveryFilter reports whether a delivery of a subscription passes the filter
+func matchesDeliveryFilter(d webhook.Delivery, subscriptionID string, filter webhook.DeliveryFilter) bool {
+	return d.SubscriptionID == subscriptionID && (filter.Status == "" || d.Status == filter.Status)
+}
+
+// copySubscription copies a subscription so stored subscriptions never share state with callers
+func copySubscription(sub webhook.Subscription) *webhook.Subscription {
+	sub.EventTypes = append([]string(nil), sub.EventTypes...)
+	return &sub
+}
+
+// copyDelivery copies a delivery so stored deliveries never share state with callers
+func copyDelivery(d webhook.Delivery) *webhook.Delivery {
+	d.Payload = append([]byte(nil), d.Payload...)
+	return &d
+}
@@ -0,0 +1,85 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/outbox"
+)
+
+// Kafka REST Proxy v2 media types for records with JSON values and for its responses
+const (
+	kafkaContentType = "application/vnd.kafka.json.v2+json"
+	kafkaAccept      = "application/vnd.kafka.v2+json"
+)
+
+// KafkaPublisher produces every message to a Kafka topic through a Kafka REST Proxy
+// Records are keyed by their aggregate, so each aggregate's events land on one
+// partition and are consumed in the order they were published.
+type KafkaPublisher struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewKafkaPublisher creates a new instance of KafkaPublisher for the REST Proxy at baseURL
+// A nil client uses http.DefaultClient.
+func NewKafkaPublisher(baseURL, topic string, client *http.Client) *KafkaPublisher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &KafkaPublisher{
+		endpoint: strings.TrimRight(baseURL, "/") + "/topics/" + url.PathEscape(topic),
+		client:   client,
+	}
+}
+
+type kafkaRecord struct {
+	Key   string   `json:"key"`
+	Value Envelope `json:"value"`
+}
+
+type kafkaProduceRequest struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		Partition int    `json:"partition"`
+		Offset    int64  `json:"offset"`
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// Publish produces the message's envelope as a single record
+// The proxy answers 200 even when the broker rejects a record, so the offsets are checked too.
+func (p *KafkaPublisher) Publish(ctx context.Context, message *outbox.Message) error {
+	body, err := json.Marshal(kafkaProduceRequest{Records: []kafkaRecord{{
+		Key:   KafkaKey(message),
+		Value: NewEnvelope(message),
+	}}})
+	if err != nil {
+		return err
+	}
+
+	var produced kafkaProduceResponse
+	headers := map[string]string{"Content-Type": kafkaContentType, "Accept": kafkaAccept}
+	if err := post(ctx, p.client, p.endpoint, headers, body, &produced); err != nil {
+		return err
+	}
+	for _, offset := range produced.Offsets {
+		if offset.ErrorCode != nil {
+			return fmt.Errorf("kafka rejected the record (error %d): %s", *offset.ErrorCode, offset.Error)
+		}
+	}
+	return nil
+}
+
+// KafkaKey is the record key of a message: its tenant, aggregate type and aggregate ID
+func KafkaKey(message *outbox.Message) string {
+	return message.TenantID + "/" + message.AggregateType + "/" + message.AggregateID
+}
@@ -0,0 +1,167 @@
+package messaging
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/outbox"
+)
+
+// natsDialTimeout bounds connecting when the context sets no deadline
+const natsDialTimeout = 10 * time.Second
+
+// NATSPublisher publishes every message to a NATS subject made of a prefix and the event name
+// It speaks the core NATS text protocol over a single connection, opened on first use
+// and reopened after any error. Each publish is followed by a PING, so it only returns
+// once the server has processed the message.
+type NATSPublisher struct {
+	address       string
+	subjectPrefix string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewNATSPublisher creates a new instance of NATSPublisher for a nats://host:port URL
+func NewNATSPublisher(serverURL, subjectPrefix string) (*NATSPublisher, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NATS URL %q: %w", serverURL, err)
+	}
+	if u.Scheme != "nats" || u.Host == "" {
+		return nil, fmt.Errorf("invalid NATS URL %q: expected nats://host:port", serverURL)
+	}
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	return &NATSPublisher{address: address, subjectPrefix: subjectPrefix}, nil
+}
+
+// Subject returns the subject a message is published to
+func (p *NATSPublisher) Subject(message *outbox.Message) string {
+	return p.subjectPrefix + message.EventName
+}
+
+// Publish sends the message's envelope and waits for the server to acknowledge the following PING
+func (p *NATSPublisher) Publish(ctx context.Context, message *outbox.Message) error {
+	body, err := json.Marshal(NewEnvelope(message))
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return err
+		}
+	}
+	if err := p.publish(ctx, p.Subject(message), body); err != nil {
+		p.closeConn()
+		return err
+	}
+	return nil
+}
+
+// Close closes the connection to the server
+func (p *NATSPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closeConn()
+}
+
+// connect opens the connection and completes the INFO / CONNECT handshake
+// The caller must hold the lock
+func (p *NATSPublisher) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: natsDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", p.address)
+	if err != nil {
+		return fmt.Errorf("connecting to NATS at %s: %w", p.address, err)
+	}
+	p.conn = conn
+	p.reader = bufio.NewReader(conn)
+	setDeadline(ctx, conn)
+
+	line, err := p.reader.ReadString('\n')
+	if err != nil {
+		p.closeConn()
+		return fmt.Errorf("reading NATS server INFO: %w", err)
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		p.closeConn()
+		return fmt.Errorf("unexpected NATS greeting %q", strings.TrimSpace(line))
+	}
+
+	if _, err := fmt.Fprint(conn, "CONNECT {\"verbose\":false,\"pedantic\":false,\"name\":\"clean-arch-ddd outbox relay\"}\r\nPING\r\n"); err != nil {
+		p.closeConn()
+		return err
+	}
+	if err := p.awaitPong(); err != nil {
+		p.closeConn()
+		return fmt.Errorf("connecting to NATS at %s: %w", p.address, err)
+	}
+	return nil
+}
+
+// publish writes a PUB and a PING and waits for the PONG
+// The caller must hold the lock
+func (p *NATSPublisher) publish(ctx context.Context, subject string, body []byte) error {
+	setDeadline(ctx, p.conn)
+	if _, err := fmt.Fprintf(p.conn, "PUB %s %d\r\n%s\r\nPING\r\n", subject, len(body), body); err != nil {
+		return err
+	}
+	return p.awaitPong()
+}
+
+// awaitPong reads server operations until the PONG answering the client's PING
+// The server's own PINGs are answered on the way; a -ERR fails the wait.
+func (p *NATSPublisher) awaitPong() error {
+	for {
+		line, err := p.reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := fmt.Fprint(p.conn, "PONG\r\n"); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New("NATS server error: " + strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+		// +OK and INFO updates need no answer
+	}
+}
+
+// closeConn drops the connection; the caller must hold the lock
+func (p *NATSPublisher) closeConn() error {
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn, p.reader = nil, nil
+	return err
+}
+
+// setDeadline makes conn's reads and writes give up when ctx does
+func setDeadline(ctx context.Context, conn net.Conn) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Time{}
+	}
+	_ = conn.SetDeadline(deadline)
+}
@@ -0,0 +1,122 @@
+// Package messaging relays the domain events stored in the outbox to the
+// message brokers and services outside the process
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/outbox"
+)
+
+// Publisher delivers outbox messages outside the process
+// Publish returns once the message was accepted; the relay retries it when it returns an error,
+// so subscribers may receive a message more than once and can use its ID to tell.
+type Publisher interface {
+	Publish(ctx context.Context, message *outbox.Message) error
+}
+
+// Envelope is the published form of a message
+type Envelope struct {
+	ID            int64           `json:"id"`
+	TenantID      string          `json:"tenant_id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	EventName     string          `json:"event_name"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// NewEnvelope wraps a message for publication
+func NewEnvelope(message *outbox.Message) Envelope {
+	return Envelope{
+		ID:            message.ID,
+		TenantID:      message.TenantID,
+		AggregateType: message.AggregateType,
+		AggregateID:   message.AggregateID,
+		EventName:     message.EventName,
+		OccurredAt:    message.OccurredAt,
+		Payload:       message.Payload,
+	}
+}
+
+// LogPublisher logs every message instead of sending it anywhere
+type LogPublisher struct{}
+
+// NewLogPublisher creates a new instance of LogPublisher
+func NewLogPublisher() *LogPublisher {
+	return &LogPublisher{}
+}
+
+// Publish logs the message
+func (p *LogPublisher) Publish(ctx context.Context, message *outbox.Message) error {
+	log.Printf("Published %s of %s %q (tenant: %s, message: %d): %s",
+		message.EventName, message.AggregateType, message.AggregateID, message.TenantID, message.ID, message.Payload)
+	return nil
+}
+
+// HTTPPublisher POSTs every message as a JSON envelope to a URL
+// Any 2xx response accepts the message.
+type HTTPPublisher struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPPublisher creates a new instance of HTTPPublisher
+// A nil client uses http.DefaultClient.
+func NewHTTPPublisher(url string, client *http.Client) *HTTPPublisher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPPublisher{url: url, client: client}
+}
+
+// Publish POSTs the message's envelope, identified by the X-Outbox-Message-ID header
+func (p *HTTPPublisher) Publish(ctx context.Context, message *outbox.Message) error {
+	body, err := json.Marshal(NewEnvelope(message))
+	if err != nil {
+		return err
+	}
+	headers := map[string]string{
+		"Content-Type":        "application/json",
+		"X-Outbox-Message-ID": fmt.Sprint(message.ID),
+		"X-Event-Name":        message.EventName,
+	}
+	return post(ctx, p.client, p.url, headers, body, nil)
+}
+
+// post sends body to url and fails unless the response is a 2xx
+// The response body is decoded into response unless it is nil.
+func post(ctx context.Context, client *http.Client, url string, headers map[string]string, body []byte, response any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s responded %s: %s", url, resp.Status, bytes.TrimSpace(detail))
+	}
+	if response == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return fmt.Errorf("decoding the response of %s: %w", url, err)
+	}
+	return nil
+}
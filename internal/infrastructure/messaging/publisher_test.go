@@ -0,0 +1,228 @@
+package messaging_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/messaging"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/outbox"
+)
+
+var message = &outbox.Message{
+	ID:            42,
+	TenantID:      "acme",
+	AggregateType: "product",
+	AggregateID:   "prod-1",
+	EventName:     "product.created",
+	Payload:       json.RawMessage(`{"product_id":"prod-1"}`),
+	OccurredAt:    time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+}
+
+func TestHTTPPublisher_PostsTheEnvelope(t *testing.T) {
+	var got messaging.Envelope
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	if err := messaging.NewHTTPPublisher(server.URL, nil).Publish(context.Background(), message); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if header.Get("X-Outbox-Message-ID") != "42" || header.Get("Content-Type") != "application/json" {
+		t.Errorf("headers = %v, want the message ID and a JSON content type", header)
+	}
+	if got.ID != 42 || got.TenantID != "acme" || got.EventName != "product.created" || string(got.Payload) != `{"product_id":"prod-1"}` {
+		t.Errorf("envelope = %+v", got)
+	}
+}
+
+func TestHTTPPublisher_FailsOnErrorResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "try later", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	err := messaging.NewHTTPPublisher(server.URL, nil).Publish(context.Background(), message)
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Publish() error = %v, want the 503 response", err)
+	}
+}
+
+func TestKafkaPublisher_ProducesAKeyedRecord(t *testing.T) {
+	var path, contentType string
+	var request struct {
+		Records []struct {
+			Key   string             `json:"key"`
+			Value messaging.Envelope `json:"value"`
+		} `json:"records"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, contentType = r.URL.Path, r.Header.Get("Content-Type")
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		w.Header().Set("Content-Type", "application/vnd.kafka.v2+json")
+		fmt.Fprint(w, `{"offsets":[{"partition":1,"offset":7,"error_code":null,"error":null}]}`)
+	}))
+	defer server.Close()
+
+	if err := messaging.NewKafkaPublisher(server.URL+"/", "events", nil).Publish(context.Background(), message); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if path != "/topics/events" || contentType != "application/vnd.kafka.json.v2+json" {
+		t.Errorf("request = %s with %s, want /topics/events with the embedded JSON format", path, contentType)
+	}
+	if len(request.Records) != 1 || request.Records[0].Key != "acme/product/prod-1" || request.Records[0].Value.ID != 42 {
+		t.Errorf("records = %+v, want one record keyed by aggregate", request.Records)
+	}
+}
+
+func TestKafkaPublisher_FailsOnRejectedRecords(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"offsets":[{"partition":null,"offset":null,"error_code":50002,"error":"leader not available"}]}`)
+	}))
+	defer server.Close()
+
+	err := messaging.NewKafkaPublisher(server.URL, "events", nil).Publish(context.Background(), message)
+	if err == nil || !strings.Contains(err.Error(), "leader not available") {
+		t.Errorf("Publish() error = %v, want the rejection", err)
+	}
+}
+
+// natsServer is a stand-in NATS server that accepts one connection at a time
+// It records the subject and payload of every PUB and answers -ERR to publishes while reject is set.
+type natsServer struct {
+	listener net.Listener
+	received chan string
+	reject   atomic.Bool
+}
+
+func newNATSServer(t *testing.T, reject bool) *natsServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	s := &natsServer{listener: listener, received: make(chan string, 10)}
+	s.reject.Store(reject)
+	t.Cleanup(func() { listener.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *natsServer) url() string {
+	return "nats://" + s.listener.Addr().String()
+}
+
+func (s *natsServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.handle(conn)
+	}
+}
+
+func (s *natsServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	fmt.Fprint(conn, "INFO {\"server_id\":\"test\",\"max_payload\":1048576}\r\n")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "PING":
+			// Servers ping their clients too; a client must answer before its own PONG arrives
+			fmt.Fprint(conn, "PING\r\nPONG\r\n")
+		case "PUB":
+			var size int
+			fmt.Sscan(fields[2], &size)
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			if s.reject.Load() {
+				fmt.Fprint(conn, "-ERR 'Permissions Violation for Publish'\r\n")
+				return
+			}
+			s.received <- fields[1] + " " + string(payload[:size])
+		}
+	}
+}
+
+func TestNATSPublisher_PublishesToTheEventSubject(t *testing.T) {
+	server := newNATSServer(t, false)
+	publisher, err := messaging.NewNATSPublisher(server.url(), "cleanarch.")
+	if err != nil {
+		t.Fatalf("NewNATSPublisher() error = %v", err)
+	}
+	defer publisher.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for i := 0; i < 2; i++ {
+		if err := publisher.Publish(ctx, message); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		got := <-server.received
+		subject, payload, _ := strings.Cut(got, " ")
+		if subject != "cleanarch.product.created" {
+			t.Errorf("subject = %s, want cleanarch.product.created", subject)
+		}
+		var envelope messaging.Envelope
+		if err := json.Unmarshal([]byte(payload), &envelope); err != nil || envelope.ID != 42 {
+			t.Errorf("payload = %s, %v; want the envelope of message 42", payload, err)
+		}
+	}
+}
+
+func TestNATSPublisher_ReportsServerErrorsAndReconnects(t *testing.T) {
+	server := newNATSServer(t, true)
+	publisher, err := messaging.NewNATSPublisher(server.url(), "cleanarch.")
+	if err != nil {
+		t.Fatalf("NewNATSPublisher() error = %v", err)
+	}
+	defer publisher.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = publisher.Publish(ctx, message)
+	if err == nil || !strings.Contains(err.Error(), "Permissions Violation") {
+		t.Fatalf("Publish() error = %v, want the server error", err)
+	}
+
+	// The failed connection was dropped; the next publish opens a new one
+	server.reject.Store(false)
+	if err := publisher.Publish(ctx, message); err != nil {
+		t.Errorf("Publish() after reconnecting error = %v", err)
+	}
+}
+
+func TestNewNATSPublisher_RejectsOtherURLs(t *testing.T) {
+	if _, err := messaging.NewNATSPublisher("http://localhost:4222", ""); err == nil {
+		t.Error("NewNATSPublisher() accepted an http URL")
+	}
+}
@@ -0,0 +1,160 @@
+package messaging
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/outbox"
+)
+
+// pruneInterval is how often the relay removes published messages past their retention
+const pruneInterval = time.Hour
+
+// RelayOptions tunes how the relay publishes and retries messages
+type RelayOptions struct {
+	// PollInterval is how long the relay waits when no message is due
+	PollInterval time.Duration
+	// BatchSize is how many messages are claimed at a time
+	BatchSize int
+	// MaxAttempts is how many times a message is published before it becomes dead
+	MaxAttempts int
+	// RetryBaseDelay is the delay after the first failure; it doubles with every
+	// further failure, up to RetryMaxDelay
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// Lease is how long claimed messages are reserved; it must outlast a batch of publishes
+	Lease time.Duration
+	// PublishTimeout bounds each attempt to publish a message; zero leaves it unbounded
+	PublishTimeout time.Duration
+	// Retention is how long published messages are kept; zero keeps them forever
+	Retention time.Duration
+}
+
+// Relay publishes the messages stored in the outbox
+// An aggregate's messages are published one at a time and in the order they were stored;
+// other aggregates are not held up by one whose messages fail. Several relays can share
+// a store, as claimed messages are leased to the relay that claimed them.
+type Relay struct {
+	store     outbox.Store
+	publisher Publisher
+	options   RelayOptions
+}
+
+// NewRelay creates a new instance of Relay
+func NewRelay(store outbox.Store, publisher Publisher, options RelayOptions) *Relay {
+	return &Relay{store: store, publisher: publisher, options: options}
+}
+
+// Run publishes due messages until ctx is done
+func (r *Relay) Run(ctx context.Context) {
+	var lastPrune time.Time
+	for {
+		now := time.Now().UTC()
+		if r.options.Retention > 0 && now.Sub(lastPrune) >= pruneInterval {
+			r.prune(ctx, now)
+			lastPrune = now
+		}
+
+		claimed, err := r.Process(ctx, now)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Outbox relay failed: %v", err)
+		}
+		// A full batch, or published messages that released their aggregates' next ones,
+		// means more messages may already be due
+		if err == nil && claimed > 0 {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.options.PollInterval):
+		}
+	}
+}
+
+// Process publishes one batch of the messages due at now and returns how many were claimed
+// A message that fails is retried after a backoff, or becomes dead after MaxAttempts.
+func (r *Relay) Process(ctx context.Context, now time.Time) (int, error) {
+	messages, err := r.store.Claim(ctx, now, r.options.Lease, r.options.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, message := range messages {
+		publishErr := r.publish(ctx, message)
+		if ctx.Err() != nil {
+			// Shutting down: the lease expires and the message is claimed again
+			return len(messages), ctx.Err()
+		}
+		// Results are recorded even if ctx ends meanwhile, so published messages are not published again
+		recordCtx := context.WithoutCancel(ctx)
+		if publishErr == nil {
+			if err := r.store.MarkPublished(recordCtx, message.ID, time.Now().UTC()); err != nil {
+				return len(messages), err
+			}
+			continue
+		}
+
+		attempts := message.Attempts + 1
+		dead := attempts >= r.options.MaxAttempts
+		if dead {
+			log.Printf("Outbox message %d (%s of %q, tenant: %s) is dead after %d attempts: %v",
+				message.ID, message.EventName, message.AggregateID, message.TenantID, attempts, publishErr)
+		} else {
+			log.Printf("Publishing outbox message %d failed (attempt %d of %d): %v",
+				message.ID, attempts, r.options.MaxAttempts, publishErr)
+		}
+		nextAttemptAt := now.Add(r.Backoff(attempts))
+		if err := r.store.MarkFailed(recordCtx, message.ID, attempts, nextAttemptAt, publishErr.Error(), dead); err != nil {
+			return len(messages), err
+		}
+	}
+	return len(messages), nil
+}
+
+// Backoff returns how long to wait after the given number of failed attempts
+func (r *Relay) Backoff(attempts int) time.Duration {
+	return backoff(r.options.RetryBaseDelay, r.options.RetryMaxDelay, attempts)
+}
+
+// backoff returns baseDelay doubled for every failed attempt after the first, up to maxDelay
+// A zero maxDelay leaves the delay unbounded.
+func backoff(baseDelay, maxDelay time.Duration, attempts int) time.Duration {
+	delay := baseDelay
+	for i := 1; i < attempts && (maxDelay <= 0 || delay < maxDelay); i++ {
+		delay *= 2
+	}
+	if maxDelay > 0 && delay > maxDelay {
+		return maxDelay
+	}
+	return delay
+}
+
+// publish hands a message to the publisher within the publish timeout
+func (r *Relay) publish(ctx context.Context, message *outbox.Message) error {
+	if r.options.PublishTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.options.PublishTimeout)
+		defer cancel()
+	}
+	return r.publisher.Publish(ctx, message)
+}
+
+// prune removes published messages older than the retention
+func (r *Relay) prune(ctx context.Context, now time.Time) {
+	deleted, err := r.store.DeletePublished(ctx, now.Add(-r.options.Retention))
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Pruning published outbox messages failed: %v", err)
+		}
+		return
+	}
+	if deleted > 0 {
+		log.Printf("Pruned %d published outbox messages", deleted)
+	}
+}
@@ -0,0 +1,162 @@
+package messaging_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/memory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/messaging"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/outbox"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+)
+
+// recordingPublisher records what it publishes and fails while err is set
+type recordingPublisher struct {
+	mu        sync.Mutex
+	err       error
+	published []string
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, message *outbox.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.published = append(p.published, message.AggregateID+":"+message.EventName)
+	return nil
+}
+
+func (p *recordingPublisher) fail(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
+
+func (p *recordingPublisher) list() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.published...)
+}
+
+var relayOptions = messaging.RelayOptions{
+	BatchSize:      10,
+	MaxAttempts:    3,
+	RetryBaseDelay: time.Second,
+	RetryMaxDelay:  time.Minute,
+	Lease:          time.Minute,
+}
+
+// newStoreWithProducts stores products through the memory repository, which writes their events to the outbox
+// The first product also has its price changed, so it has two messages.
+func newStoreWithProducts(t *testing.T, ctx context.Context, ids ...string) outbox.Store {
+	t.Helper()
+	db := memory.NewDatabase()
+	repo := memory.NewProductCommandRepository(db)
+	for i, id := range ids {
+		price, _ := product.NewPrice(10, "USD")
+		prod, err := product.NewProduct(id, "Product "+id, price)
+		if err != nil {
+			t.Fatalf("NewProduct() error = %v", err)
+		}
+		if err := repo.Create(ctx, prod); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		prod.PullEvents()
+		if i == 0 {
+			newPrice, _ := product.NewPrice(12, "USD")
+			if err := prod.UpdatePrice(newPrice); err != nil {
+				t.Fatalf("UpdatePrice() error = %v", err)
+			}
+			if err := repo.Update(ctx, prod); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+		}
+	}
+	return memory.NewOutboxStore(db)
+}
+
+func TestRelay_PublishesEachAggregateInOrder(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), tenant.Tenant{ID: "acme"})
+	store := newStoreWithProducts(t, ctx, "prod-1", "prod-2")
+	publisher := &recordingPublisher{}
+	relay := messaging.NewRelay(store, publisher, relayOptions)
+	now := time.Now().Add(time.Second)
+
+	// The price change of prod-1 waits for its creation to be published
+	if claimed, err := relay.Process(ctx, now); err != nil || claimed != 2 {
+		t.Fatalf("Process() = %d, %v; want 2 messages", claimed, err)
+	}
+	if claimed, err := relay.Process(ctx, now); err != nil || claimed != 1 {
+		t.Fatalf("second Process() = %d, %v; want 1 message", claimed, err)
+	}
+
+	want := []string{"prod-1:product.created", "prod-2:product.created", "prod-1:product.price_changed"}
+	got := publisher.list()
+	if len(got) != len(want) {
+		t.Fatalf("published %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("published[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+	if count, err := store.Count(ctx, outbox.Filter{Status: outbox.StatusPublished}); err != nil || count != 3 {
+		t.Errorf("Count(published) = %d, %v; want 3", count, err)
+	}
+}
+
+func TestRelay_RetriesWithBackoffThenDeadLetters(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), tenant.Tenant{ID: "acme"})
+	store := newStoreWithProducts(t, ctx, "prod-1")
+	publisher := &recordingPublisher{}
+	publisher.fail(errors.New("broker unavailable"))
+	relay := messaging.NewRelay(store, publisher, relayOptions)
+	now := time.Now().Add(time.Second)
+
+	// Attempts at now, now+1s and now+3s: the delay doubles after each failure
+	for i, at := range []time.Time{now, now.Add(time.Second), now.Add(3 * time.Second)} {
+		if claimed, err := relay.Process(ctx, at); err != nil || claimed != 1 {
+			t.Fatalf("attempt %d: Process() = %d, %v; want 1 message", i+1, claimed, err)
+		}
+		if claimed, _ := relay.Process(ctx, at); claimed != 0 {
+			t.Fatalf("attempt %d: Process() during the backoff claimed %d messages", i+1, claimed)
+		}
+	}
+
+	dead, err := store.List(ctx, outbox.Filter{Status: outbox.StatusDead, Limit: 10})
+	if err != nil || len(dead) != 1 {
+		t.Fatalf("List(dead) = %d messages, %v; want 1", len(dead), err)
+	}
+	if dead[0].Attempts != 3 || dead[0].LastError != "broker unavailable" || dead[0].EventName != product.EventProductCreated {
+		t.Errorf("dead message = %+v, want product.created after 3 attempts", dead[0])
+	}
+	// The dead message holds back the later price change of the same product
+	if claimed, _ := relay.Process(ctx, now.Add(time.Hour)); claimed != 0 {
+		t.Errorf("Process() with a dead message claimed %d messages, want 0", claimed)
+	}
+
+	publisher.fail(nil)
+	if replayed, err := store.Replay(ctx, dead[0].ID, now.Add(time.Hour)); err != nil || !replayed {
+		t.Fatalf("Replay() = %v, %v", replayed, err)
+	}
+	relay.Process(ctx, now.Add(time.Hour))
+	relay.Process(ctx, now.Add(time.Hour))
+	if got := publisher.list(); len(got) != 2 || got[1] != "prod-1:product.price_changed" {
+		t.Errorf("published after replay %v, want creation then price change", got)
+	}
+}
+
+func TestRelay_Backoff(t *testing.T) {
+	relay := messaging.NewRelay(nil, nil, messaging.RelayOptions{RetryBaseDelay: time.Second, RetryMaxDelay: 5 * time.Second})
+	want := map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 50: 5 * time.Second}
+	for attempts, delay := range want {
+		if got := relay.Backoff(attempts); got != delay {
+			t.Errorf("Backoff(%d) = %v, want %v", attempts, got, delay)
+		}
+	}
+}
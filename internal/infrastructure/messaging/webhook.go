@@ -0,0 +1,315 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/outbox"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/webhook"
+)
+
+// Publishers hands every message to each of its publishers in turn
+// A message is accepted only if every publisher accepts it, so when one fails the relay
+// retries the message with all of them.
+type Publishers []Publisher
+
+// Publish hands the message to every publisher and joins their errors
+func (p Publishers) Publish(ctx context.Context, message *outbox.Message) error {
+	var errs []error
+	for _, publisher := range p {
+		if err := publisher.Publish(ctx, message); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WebhookFanout turns every outbox message into a delivery for each matching subscription
+// It only stores the deliveries; the WebhookDispatcher sends them. A message the relay
+// hands over again is skipped by the store, so each subscription gets it once.
+type WebhookFanout struct {
+	store webhook.Store
+}
+
+// NewWebhookFanout creates a new instance of WebhookFanout
+func NewWebhookFanout(store webhook.Store) *WebhookFanout {
+	return &WebhookFanout{store: store}
+}
+
+// Publish enqueues the message's envelope for the active subscriptions of its tenant that want it
+func (f *WebhookFanout) Publish(ctx context.Context, message *outbox.Message) error {
+	ctx = tenant.NewContext(ctx, tenant.Tenant{ID: message.TenantID})
+	subscriptions, err := f.store.ListSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []*webhook.Delivery
+	var payload []byte
+	now := time.Now().UTC()
+	for _, sub := range subscriptions {
+		if !sub.Active || !sub.Matches(message.EventName) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(NewEnvelope(message)); err != nil {
+				return err
+			}
+		}
+		deliveries = append(deliveries, &webhook.Delivery{
+			SubscriptionID: sub.ID,
+			MessageID:      message.ID,
+			EventName:      message.EventName,
+			Payload:        payload,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return f.store.Enqueue(ctx, deliveries)
+}
+
+// WebhookOptions tunes how the dispatcher sends and retries deliveries
+type WebhookOptions struct {
+	// PollInterval is how long the dispatcher waits when no delivery is due
+	PollInterval time.Duration
+	// BatchSize is how many deliveries are claimed at a time
+	BatchSize int
+	// MaxAttempts is how many times a delivery is sent before it fails for good
+	MaxAttempts int
+	// RetryBaseDelay is the delay after the first failure; it doubles with every
+	// further failure, up to RetryMaxDelay
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// Lease is how long claimed deliveries are reserved; it must outlast a batch of attempts
+	Lease time.Duration
+	// Timeout bounds each attempt; zero leaves it unbounded
+	Timeout time.Duration
+	// DisableAfter is how many attempts in a row may fail before a subscription is
+	// disabled; zero never disables one
+	DisableAfter int
+	// Retention is how long delivered deliveries are kept; zero keeps them forever
+	Retention time.Duration
+}
+
+// WebhookDispatcher sends the pending webhook deliveries to their subscriptions
+// Every attempt is signed with the subscription's secret. A delivery that fails is retried
+// after a backoff, and a subscription whose attempts keep failing is disabled. Several
+// dispatchers can share a store, as claimed deliveries are leased to the one that claimed them.
+type WebhookDispatcher struct {
+	store   webhook.Store
+	client  *http.Client
+	options WebhookOptions
+}
+
+// NewWebhookDispatcher creates a new instance of WebhookDispatcher
+// A nil client uses NewWebhookClient. Redirects are never followed, whatever the client,
+// so a subscription cannot bounce a delivery to another destination.
+func NewWebhookDispatcher(store webhook.Store, client *http.Client, options WebhookOptions) *WebhookDispatcher {
+	if client == nil {
+		client = NewWebhookClient()
+	}
+	noRedirects := *client
+	noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &WebhookDispatcher{store: store, client: &noRedirects, options: options}
+}
+
+// NewWebhookClient returns the HTTP client deliveries are sent with by default
+// It connects only to public addresses, checked when dialing after the host name is resolved,
+// so a name that resolves to loopback, a private network or instance metadata is refused too.
+// Proxy settings are ignored, as a proxy would hide the destination from that check.
+func NewWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   dialPublicOnly,
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		},
+	}
+}
+
+// dialPublicOnly refuses connections to addresses deliveries must not reach
+func dialPublicOnly(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil || !webhook.IsPublicAddress(addrPort.Addr()) {
+		return webhook.ErrNonPublicDestination
+	}
+	return nil
+}
+
+// Run sends due deliveries until ctx is done
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	var lastPrune time.Time
+	for {
+		now := time.Now().UTC()
+		if d.options.Retention > 0 && now.Sub(lastPrune) >= pruneInterval {
+			d.prune(ctx, now)
+			lastPrune = now
+		}
+
+		claimed, err := d.Process(ctx, now)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Webhook dispatcher failed: %v", err)
+		}
+		// A full batch means more deliveries may already be due
+		if err == nil && claimed >= d.options.BatchSize {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.options.PollInterval):
+		}
+	}
+}
+
+// Process sends one batch of the deliveries due at now and returns how many were claimed
+// A delivery that fails is retried after a backoff, or fails for good after MaxAttempts.
+func (d *WebhookDispatcher) Process(ctx context.Context, now time.Time) (int, error) {
+	deliveries, err := d.store.Claim(ctx, now, d.options.Lease, d.options.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		tenantCtx := tenant.NewContext(ctx, tenant.Tenant{ID: delivery.TenantID})
+		sub, err := d.store.GetSubscription(tenantCtx, delivery.SubscriptionID)
+		if err != nil {
+			return len(deliveries), err
+		}
+		if sub == nil || !sub.Active {
+			// Disabled since it was claimed: the delivery waits until the subscription is enabled again
+			continue
+		}
+
+		attempt := d.send(tenantCtx, sub, delivery)
+		if ctx.Err() != nil {
+			// Shutting down: the lease expires and the delivery is claimed again
+			return len(deliveries), ctx.Err()
+		}
+		// Results are recorded even if ctx ends meanwhile, so delivered events are not sent again
+		recordCtx := context.WithoutCancel(tenantCtx)
+		attempts := delivery.Attempts + 1
+		if attempt.Error == "" {
+			if err := d.store.MarkDelivered(recordCtx, delivery.ID, attempts, attempt); err != nil {
+				return len(deliveries), err
+			}
+			if err := d.store.RecordSuccess(recordCtx, sub.ID); err != nil {
+				return len(deliveries), err
+			}
+			continue
+		}
+
+		failed := attempts >= d.options.MaxAttempts
+		if failed {
+			log.Printf("Webhook delivery %d (%s to subscription %s, tenant: %s) failed after %d attempts: %s",
+				delivery.ID, delivery.EventName, sub.ID, delivery.TenantID, attempts, attempt.Error)
+		} else {
+			log.Printf("Webhook delivery %d failed (attempt %d of %d): %s",
+				delivery.ID, attempts, d.options.MaxAttempts, attempt.Error)
+		}
+		nextAttemptAt := now.Add(d.Backoff(attempts))
+		if err := d.store.MarkFailed(recordCtx, delivery.ID, attempts, attempt, nextAttemptAt, failed); err != nil {
+			return len(deliveries), err
+		}
+		disabled, err := d.store.RecordFailure(recordCtx, sub.ID, d.options.DisableAfter, attempt.At)
+		if err != nil {
+			return len(deliveries), err
+		}
+		if disabled {
+			log.Printf("Webhook subscription %s (tenant: %s) was disabled after %d failed attempts in a row",
+				sub.ID, delivery.TenantID, d.options.DisableAfter)
+		}
+	}
+	return len(deliveries), nil
+}
+
+// Backoff returns how long to wait after the given number of failed attempts
+func (d *WebhookDispatcher) Backoff(attempts int) time.Duration {
+	return backoff(d.options.RetryBaseDelay, d.options.RetryMaxDelay, attempts)
+}
+
+// send POSTs a delivery to its subscription once, signed at the time of the attempt
+// Any 2xx response accepts the delivery. Response bodies are not kept, so a delivery log
+// cannot be used to read what an endpoint answered.
+func (d *WebhookDispatcher) send(ctx context.Context, sub *webhook.Subscription, delivery *webhook.Delivery) webhook.Attempt {
+	if d.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.options.Timeout)
+		defer cancel()
+	}
+
+	attempt := webhook.Attempt{At: time.Now().UTC()}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.HeaderDeliveryID, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(webhook.HeaderEvent, delivery.EventName)
+	req.Header.Set(webhook.HeaderTimestamp, strconv.FormatInt(attempt.At.Unix(), 10))
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign(sub.Secret, attempt.At, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if errors.Is(err, webhook.ErrNonPublicDestination) {
+		// The dial error names the resolved address, which is not the subscriber's to see
+		attempt.Error = webhook.ErrNonPublicDestination.Error()
+		return attempt
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+	// Drained up to a bound so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	attempt.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		attempt.Error = fmt.Sprintf("responded %s", resp.Status)
+	}
+	return attempt
+}
+
+// prune removes delivered deliveries older than the retention
+func (d *WebhookDispatcher) prune(ctx context.Context, now time.Time) {
+	deleted, err := d.store.DeleteDelivered(ctx, now.Add(-d.options.Retention))
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Pruning delivered webhook deliveries failed: %v", err)
+		}
+		return
+	}
+	if deleted > 0 {
+		log.Printf("Pruned %d delivered webhook deliveries", deleted)
+	}
+}
@@ -0,0 +1,313 @@
+package messaging_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/memory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/messaging"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/outbox"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/webhook"
+)
+
+var webhookOptions = messaging.WebhookOptions{
+	BatchSize:      10,
+	MaxAttempts:    3,
+	RetryBaseDelay: time.Second,
+	RetryMaxDelay:  time.Minute,
+	Lease:          time.Minute,
+	Timeout:        5 * time.Second,
+	DisableAfter:   5,
+}
+
+// receiver is a webhook endpoint that checks signatures and answers with status
+type receiver struct {
+	mu       sync.Mutex
+	secret   string
+	status   int
+	received []*http.Request
+	bodies   [][]byte
+	err      error
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	body, _ := io.ReadAll(req.Body)
+	err := webhook.Verify(r.secret, req.Header.Get(webhook.HeaderTimestamp), req.Header.Get(webhook.HeaderSignature), body, time.Now(), time.Minute)
+	if err != nil && r.err == nil {
+		r.err = err
+	}
+	r.received = append(r.received, req)
+	r.bodies = append(r.bodies, body)
+	w.WriteHeader(r.status)
+}
+
+func (r *receiver) respond(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.received)
+}
+
+// subscribe stores an active subscription of acme to url
+func subscribe(t *testing.T, store webhook.Store, id, url string, eventTypes ...string) {
+	t.Helper()
+	ctx := tenant.NewContext(context.Background(), tenant.Tenant{ID: "acme"})
+	now := time.Now().UTC()
+	sub := &webhook.Subscription{ID: id, URL: url, EventTypes: eventTypes, Secret: "whsec_" + id, Active: true, CreatedAt: now, UpdatedAt: now}
+	if err := store.CreateSubscription(ctx, sub); err != nil {
+		t.Fatalf("CreateSubscription() error = %v", err)
+	}
+}
+
+func newMessage(id int64, eventName string) *outbox.Message {
+	return &outbox.Message{
+		ID:            id,
+		TenantID:      "acme",
+		AggregateType: product.AggregateType,
+		AggregateID:   "prod-1",
+		EventName:     eventName,
+		Payload:       []byte(`{"product_id":"prod-1"}`),
+	}
+}
+
+func TestWebhookFanout_EnqueuesEachMessageForMatchingSubscriptions(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), tenant.Tenant{ID: "acme"})
+	store := memory.NewWebhookStore(memory.NewDatabase())
+	subscribe(t, store, "all", "https://example.com/all", webhook.AllEvents)
+	subscribe(t, store, "stock", "https://example.com/stock", inventory.EventStockAdjusted)
+	fanout := messaging.NewWebhookFanout(store)
+
+	message := newMessage(1, product.EventProductCreated)
+	// The relay may hand a message over again; it is still delivered once
+	for i := 0; i < 2; i++ {
+		if err := fanout.Publish(context.Background(), message); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+
+	all, err := store.ListDeliveries(ctx, "all", webhook.DeliveryFilter{Limit: 10})
+	if err != nil || len(all) != 1 {
+		t.Fatalf("ListDeliveries(all) = %d deliveries, %v; want 1", len(all), err)
+	}
+	var envelope messaging.Envelope
+	if err := json.Unmarshal(all[0].Payload, &envelope); err != nil {
+		t.Fatalf("decoding the payload: %v", err)
+	}
+	if envelope.ID != 1 || envelope.EventName != product.EventProductCreated || envelope.TenantID != "acme" {
+		t.Errorf("payload = %+v, want the envelope of message 1", envelope)
+	}
+	if count, err := store.CountDeliveries(ctx, "stock", webhook.DeliveryFilter{}); err != nil || count != 0 {
+		t.Errorf("CountDeliveries(stock) = %d, %v; want 0", count, err)
+	}
+}
+
+func TestWebhookDispatcher_SendsSignedDeliveries(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), tenant.Tenant{ID: "acme"})
+	store := memory.NewWebhookStore(memory.NewDatabase())
+	hook := &receiver{secret: "whsec_sub-1", status: http.StatusNoContent}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+	subscribe(t, store, "sub-1", server.URL, webhook.AllEvents)
+	if err := messaging.NewWebhookFanout(store).Publish(context.Background(), newMessage(1, product.EventProductCreated)); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	dispatcher := messaging.NewWebhookDispatcher(store, server.Client(), webhookOptions)
+	if claimed, err := dispatcher.Process(context.Background(), time.Now().Add(time.Second)); err != nil || claimed != 1 {
+		t.Fatalf("Process() = %d, %v; want 1 delivery", claimed, err)
+	}
+
+	if hook.count() != 1 {
+		t.Fatalf("received %d requests, want 1", hook.count())
+	}
+	if hook.err != nil {
+		t.Errorf("signature check: %v", hook.err)
+	}
+	req := hook.received[0]
+	if req.Header.Get(webhook.HeaderEvent) != product.EventProductCreated || req.Header.Get(webhook.HeaderDeliveryID) == "" {
+		t.Errorf("headers = %v, want the event name and delivery ID", req.Header)
+	}
+	delivered, err := store.ListDeliveries(ctx, "sub-1", webhook.DeliveryFilter{Status: webhook.DeliveryDelivered, Limit: 10})
+	if err != nil || len(delivered) != 1 || delivered[0].LastStatusCode != http.StatusNoContent {
+		t.Errorf("ListDeliveries(delivered) = %+v, %v; want the delivery answered with 204", delivered, err)
+	}
+}
+
+func TestWebhookDispatcher_RetriesWithBackoffThenFails(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), tenant.Tenant{ID: "acme"})
+	store := memory.NewWebhookStore(memory.NewDatabase())
+	hook := &receiver{secret: "whsec_sub-1", status: http.StatusInternalServerError}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+	subscribe(t, store, "sub-1", server.URL, webhook.AllEvents)
+	if err := messaging.NewWebhookFanout(store).Publish(context.Background(), newMessage(1, product.EventProductCreated)); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	dispatcher := messaging.NewWebhookDispatcher(store, server.Client(), webhookOptions)
+	now := time.Now().Add(time.Second)
+
+	// Attempts at now, now+1s and now+3s: the delay doubles after each failure
+	for i, at := range []time.Time{now, now.Add(time.Second), now.Add(3 * time.Second)} {
+		if claimed, err := dispatcher.Process(context.Background(), at); err != nil || claimed != 1 {
+			t.Fatalf("attempt %d: Process() = %d, %v; want 1 delivery", i+1, claimed, err)
+		}
+		if claimed, _ := dispatcher.Process(context.Background(), at); claimed != 0 {
+			t.Fatalf("attempt %d: Process() during the backoff claimed %d deliveries", i+1, claimed)
+		}
+	}
+
+	failed, err := store.ListDeliveries(ctx, "sub-1", webhook.DeliveryFilter{Status: webhook.DeliveryFailed, Limit: 10})
+	if err != nil || len(failed) != 1 {
+		t.Fatalf("ListDeliveries(failed) = %d deliveries, %v; want 1", len(failed), err)
+	}
+	if failed[0].Attempts != 3 || failed[0].LastStatusCode != http.StatusInternalServerError || failed[0].LastError == "" {
+		t.Errorf("failed delivery = %+v, want 3 attempts answered with 500", failed[0])
+	}
+
+	// A manual redelivery is sent again and a success resets the failure count
+	hook.respond(http.StatusOK)
+	if redelivered, err := store.Redeliver(ctx, "sub-1", failed[0].ID, now); err != nil || !redelivered {
+		t.Fatalf("Redeliver() = %v, %v", redelivered, err)
+	}
+	if claimed, err := dispatcher.Process(context.Background(), now.Add(time.Hour)); err != nil || claimed != 1 {
+		t.Fatalf("Process() after redelivery = %d, %v; want 1 delivery", claimed, err)
+	}
+	sub, err := store.GetSubscription(ctx, "sub-1")
+	if err != nil || sub.ConsecutiveFailures != 0 || !sub.Active {
+		t.Errorf("subscription after success = %+v, %v; want active without failures", sub, err)
+	}
+}
+
+// dispatchOnce publishes one message to a subscription of url and sends it with client
+// It returns the delivery after the attempt.
+func dispatchOnce(t *testing.T, client *http.Client, url string) *webhook.Delivery {
+	t.Helper()
+	ctx := tenant.NewContext(context.Background(), tenant.Tenant{ID: "acme"})
+	store := memory.NewWebhookStore(memory.NewDatabase())
+	subscribe(t, store, "sub-1", url, webhook.AllEvents)
+	if err := messaging.NewWebhookFanout(store).Publish(context.Background(), newMessage(1, product.EventProductCreated)); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	dispatcher := messaging.NewWebhookDispatcher(store, client, webhookOptions)
+	if claimed, err := dispatcher.Process(context.Background(), time.Now().Add(time.Second)); err != nil || claimed != 1 {
+		t.Fatalf("Process() = %d, %v; want 1 delivery", claimed, err)
+	}
+	deliveries, err := store.ListDeliveries(ctx, "sub-1", webhook.DeliveryFilter{Limit: 10})
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("ListDeliveries() = %d deliveries, %v; want 1", len(deliveries), err)
+	}
+	return deliveries[0]
+}
+
+func TestWebhookDispatcher_RefusesNonPublicDestinations(t *testing.T) {
+	hook := &receiver{secret: "whsec_sub-1", status: http.StatusNoContent}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	// The default client checks the address it connects to, so loopback is refused
+	delivery := dispatchOnce(t, nil, server.URL)
+
+	if hook.count() != 0 {
+		t.Errorf("received %d requests, want none", hook.count())
+	}
+	if delivery.Status != webhook.DeliveryPending || delivery.LastError != webhook.ErrNonPublicDestination.Error() {
+		t.Errorf("delivery = %+v, want a failed attempt refused as non-public", delivery)
+	}
+}
+
+func TestWebhookDispatcher_DoesNotFollowRedirects(t *testing.T) {
+	target := &receiver{secret: "whsec_sub-1", status: http.StatusNoContent}
+	targetServer := httptest.NewServer(target)
+	defer targetServer.Close()
+	redirect := httptest.NewServer(http.RedirectHandler(targetServer.URL, http.StatusFound))
+	defer redirect.Close()
+
+	delivery := dispatchOnce(t, redirect.Client(), redirect.URL)
+
+	if target.count() != 0 {
+		t.Errorf("redirect target received %d requests, want none", target.count())
+	}
+	if delivery.LastStatusCode != http.StatusFound || delivery.LastError != "responded 302 Found" {
+		t.Errorf("delivery = %+v, want a failed attempt answered with 302", delivery)
+	}
+}
+
+func TestWebhookDispatcher_DoesNotKeepResponseBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, "internal-token-123")
+	}))
+	defer server.Close()
+
+	delivery := dispatchOnce(t, server.Client(), server.URL)
+
+	if delivery.LastError != "responded 403 Forbidden" {
+		t.Errorf("LastError = %q, want only the status", delivery.LastError)
+	}
+}
+
+func TestWebhookDispatcher_DisablesSubscriptionsThatKeepFailing(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), tenant.Tenant{ID: "acme"})
+	store := memory.NewWebhookStore(memory.NewDatabase())
+	subscribe(t, store, "sub-1", "http://127.0.0.1:1/unreachable", webhook.AllEvents)
+	fanout := messaging.NewWebhookFanout(store)
+	for id := int64(1); id <= 3; id++ {
+		if err := fanout.Publish(context.Background(), newMessage(id, product.EventProductCreated)); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+	options := webhookOptions
+	options.DisableAfter = 2
+	dispatcher := messaging.NewWebhookDispatcher(store, nil, options)
+
+	if claimed, err := dispatcher.Process(context.Background(), time.Now().Add(time.Second)); err != nil || claimed != 3 {
+		t.Fatalf("Process() = %d, %v; want 3 deliveries", claimed, err)
+	}
+
+	sub, err := store.GetSubscription(ctx, "sub-1")
+	if err != nil || sub == nil {
+		t.Fatalf("GetSubscription() = %v, %v", sub, err)
+	}
+	if sub.Active || sub.DisabledAt.IsZero() || sub.ConsecutiveFailures != 2 {
+		t.Errorf("subscription = %+v, want disabled after 2 failures", sub)
+	}
+	// The third delivery was claimed before the subscription was disabled; it is not attempted
+	pending, err := store.ListDeliveries(ctx, "sub-1", webhook.DeliveryFilter{Status: webhook.DeliveryPending, Limit: 10})
+	if err != nil || len(pending) != 3 || pending[0].Attempts != 0 {
+		t.Errorf("pending deliveries = %+v, %v; want all 3, the last never attempted", pending, err)
+	}
+	if claimed, _ := dispatcher.Process(context.Background(), time.Now().Add(time.Hour)); claimed != 0 {
+		t.Errorf("Process() for a disabled subscription claimed %d deliveries, want 0", claimed)
+	}
+}
+
+func TestPublishers_JoinsTheErrorsOfEveryPublisher(t *testing.T) {
+	ok := &recordingPublisher{}
+	broken := &recordingPublisher{}
+	broken.fail(errors.New("broker unavailable"))
+
+	err := messaging.Publishers{broken, ok}.Publish(context.Background(), newMessage(1, product.EventProductCreated))
+	if err == nil || err.Error() != "broker unavailable" {
+		t.Errorf("Publish() error = %v, want the broken publisher's error", err)
+	}
+	if got := ok.list(); len(got) != 1 {
+		t.Errorf("the other publisher got %v, want the message anyway", got)
+	}
+}
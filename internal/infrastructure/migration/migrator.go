@@ -0,0 +1,160 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/db"
+	"github.com/pressly/goose/v3"
+)
+
+// Database drivers with embedded migrations, named as in config.DatabaseConfig.Driver
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// ErrSchemaBehind is returned by EnsureCurrent when migrations are pending
+var ErrSchemaBehind = errors.New("database schema is behind the application")
+
+// Result describes one migration applied or rolled back
+type Result struct {
+	Version  int64
+	Name     string
+	Duration time.Duration
+}
+
+// Status describes one embedded migration and whether the database has it
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator applies the migrations embedded in the binary to a database
+// It uses the same goose version table as the goose CLI, so both can manage one database
+type Migrator struct {
+	provider *goose.Provider
+}
+
+// New creates a migrator for the embedded migrations of driver
+func New(conn *sql.DB, driver string) (*Migrator, error) {
+	var (
+		dialect goose.Dialect
+		fsys    fs.FS
+		err     error
+	)
+	switch driver {
+	case DriverPostgres:
+		dialect = goose.DialectPostgres
+		fsys, err = fs.Sub(db.PostgresMigrations, "migrations")
+	case DriverSQLite:
+		dialect = goose.DialectSQLite3
+		fsys, err = fs.Sub(db.SQLiteMigrations, "sqlite/migrations")
+	default:
+		return nil, fmt.Errorf("no migrations for database driver %q", driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := goose.NewProvider(dialect, conn, fsys, goose.WithDisableGlobalRegistry(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return &Migrator{provider: provider}, nil
+}
+
+// Up applies every pending migration
+// Returns the applied migrations, in order; none if the schema is current
+func (m *Migrator) Up(ctx context.Context) ([]Result, error) {
+	results, err := m.provider.Up(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return toResults(results), nil
+}
+
+// Down rolls back the most recently applied migration
+func (m *Migrator) Down(ctx context.Context) (Result, error) {
+	result, err := m.provider.Down(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+	return toResults([]*goose.MigrationResult{result})[0], nil
+}
+
+// Status lists every embedded migration in version order
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	statuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Status, 0, len(statuses))
+	for _, s := range statuses {
+		out = append(out, Status{
+			Version:   s.Source.Version,
+			Name:      sourceName(s.Source),
+			Applied:   s.State == goose.StateApplied,
+			AppliedAt: s.AppliedAt,
+		})
+	}
+	return out, nil
+}
+
+// Version returns the schema version of the database and the latest embedded version
+func (m *Migrator) Version(ctx context.Context) (current, latest int64, err error) {
+	current, err = m.provider.GetDBVersion(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, source := range m.provider.ListSources() {
+		if source.Version > latest {
+			latest = source.Version
+		}
+	}
+	return current, latest, nil
+}
+
+// EnsureCurrent fails with ErrSchemaBehind if any embedded migration is not applied
+// A database ahead of the binary passes, so an older release can still serve during a rollout
+func (m *Migrator) EnsureCurrent(ctx context.Context) error {
+	pending, err := m.provider.HasPending(ctx)
+	if err != nil {
+		return err
+	}
+	if !pending {
+		return nil
+	}
+
+	current, latest, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: database is at version %d, the application needs %d (run `api migrate up` or set DB_AUTO_MIGRATE=true)",
+		ErrSchemaBehind, current, latest)
+}
+
+// toResults converts goose migration results
+func toResults(results []*goose.MigrationResult) []Result {
+	out := make([]Result, 0, len(results))
+	for _, r := range results {
+		out = append(out, Result{
+			Version:  r.Source.Version,
+			Name:     sourceName(r.Source),
+			Duration: r.Duration,
+		})
+	}
+	return out
+}
+
+// sourceName returns the file name of a migration
+func sourceName(source *goose.Source) string {
+	return path.Base(source.Path)
+}
@@ -0,0 +1,101 @@
+package migration_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/migration"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/sqlite"
+)
+
+func newMigrator(t *testing.T) (*migration.Migrator, *sql.DB) {
+	t.Helper()
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	m, err := migration.New(db, migration.DriverSQLite)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return m, db
+}
+
+func TestMigrator_UpAppliesEveryEmbeddedMigration(t *testing.T) {
+	ctx := context.Background()
+	m, db := newMigrator(t)
+
+	if err := m.EnsureCurrent(ctx); !errors.Is(err, migration.ErrSchemaBehind) {
+		t.Fatalf("EnsureCurrent() on empty database = %v, want ErrSchemaBehind", err)
+	}
+
+	results, err := m.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	current, latest, err := m.Version(ctx)
+	if err != nil {
+		t.Fatalf("Version() error = %v", err)
+	}
+	if latest == 0 || current != latest {
+		t.Fatalf("Version() = %d, %d; want current == latest", current, latest)
+	}
+	if len(results) == 0 || results[len(results)-1].Version != latest {
+		t.Fatalf("Up() results = %+v, want migrations up to %d", results, latest)
+	}
+	if err := m.EnsureCurrent(ctx); err != nil {
+		t.Fatalf("EnsureCurrent() after Up = %v", err)
+	}
+	if _, err := db.Exec("SELECT COUNT(*) FROM products"); err != nil {
+		t.Fatalf("schema not created: %v", err)
+	}
+
+	again, err := m.Up(ctx)
+	if err != nil || len(again) != 0 {
+		t.Fatalf("second Up() = %+v, %v; want nothing to apply", again, err)
+	}
+}
+
+func TestMigrator_DownLeavesSchemaBehind(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newMigrator(t)
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	_, latest, _ := m.Version(ctx)
+
+	result, err := m.Down(ctx)
+	if err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+	if result.Version != latest {
+		t.Fatalf("Down() rolled back %d, want %d", result.Version, latest)
+	}
+
+	if err := m.EnsureCurrent(ctx); !errors.Is(err, migration.ErrSchemaBehind) {
+		t.Fatalf("EnsureCurrent() after Down = %v, want ErrSchemaBehind", err)
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	last := statuses[len(statuses)-1]
+	if last.Version != latest || last.Applied || last.Name == "" {
+		t.Fatalf("Status() last = %+v, want pending version %d", last, latest)
+	}
+	if !statuses[0].Applied {
+		t.Fatalf("Status() first = %+v, want applied", statuses[0])
+	}
+}
+
+func TestNew_RejectsUnknownDriver(t *testing.T) {
+	if _, err := migration.New(nil, "mysql"); err == nil {
+		t.Fatal("New() with unknown driver succeeded")
+	}
+}
@@ -0,0 +1,137 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/persistence/sqlcgen"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// AuditRepositoryImpl implements the audit.Store interface
+type AuditRepositoryImpl struct {
+	db      *sql.DB
+	queries *sqlcgen.Queries
+}
+
+// NewAuditStore creates a new instance of AuditRepositoryImpl
+func NewAuditStore(db *sql.DB) audit.Store {
+	return &AuditRepositoryImpl{
+		db:      db,
+		queries: sqlcgen.New(db),
+	}
+}
+
+// Append links the entry to the end of the tenant's chain and stores it
+// Locking the tenant's chain head makes concurrent appends queue behind one another;
+// under repeatable read or serializable isolation the one that waited fails with a
+// serialization error, which the unit of work retries.
+func (r *AuditRepositoryImpl) Append(ctx context.Context, entry *audit.Entry) error {
+	tenantID := tenant.ID(ctx)
+	return runInTx(ctx, r.db, r.queries, func(q *sqlcgen.Queries) error {
+		head, err := q.LockAuditChainHead(ctx, tenantID)
+		if err != nil {
+			return err
+		}
+		var last *audit.Entry
+		if head.Sequence > 0 {
+			last = &audit.Entry{Sequence: head.Sequence, Hash: head.Hash}
+		}
+		entry.Link(last)
+
+		if err := q.InsertAuditEntry(ctx, sqlcgen.InsertAuditEntryParams{
+			TenantID:      tenantID,
+			Sequence:      entry.Sequence,
+			Actor:         entry.Actor,
+			Command:       entry.Command,
+			AggregateType: entry.AggregateType,
+			AggregateID:   entry.AggregateID,
+			Input:         entry.Input,
+			BeforeState:   entry.Before,
+			AfterState:    entry.After,
+			RequestID:     entry.RequestID,
+			OccurredAt:    entry.OccurredAt.UTC(),
+			PreviousHash:  entry.PreviousHash,
+			Hash:          entry.Hash,
+		}); err != nil {
+			return err
+		}
+		return q.AdvanceAuditChainHead(ctx, sqlcgen.AdvanceAuditChainHeadParams{
+			TenantID: tenantID,
+			Sequence: entry.Sequence,
+			Hash:     entry.Hash,
+		})
+	})
+}
+
+// List returns a page of the entries matching the filter, newest first
+func (r *AuditRepositoryImpl) List(ctx context.Context, filter audit.Filter) ([]*audit.Entry, error) {
+	dbEntries, err := r.queries.ListAuditEntries(ctx, sqlcgen.ListAuditEntriesParams{
+		TenantID:      tenant.ID(ctx),
+		AggregateType: toNullString(filter.AggregateType),
+		AggregateID:   toNullString(filter.AggregateID),
+		Actor:         toNullString(filter.Actor),
+		RowOffset:     int32(filter.Offset),
+		RowLimit:      int32(filter.Limit),
+	})
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	return toAuditEntries(dbEntries), nil
+}
+
+// Count returns how many entries match the filter
+func (r *AuditRepositoryImpl) Count(ctx context.Context, filter audit.Filter) (int, error) {
+	count, err := r.queries.CountAuditEntries(ctx, sqlcgen.CountAuditEntriesParams{
+		TenantID:      tenant.ID(ctx),
+		AggregateType: toNullString(filter.AggregateType),
+		AggregateID:   toNullString(filter.AggregateID),
+		Actor:         toNullString(filter.Actor),
+	})
+	if err != nil {
+		return 0, apperrors.WrapDatabaseError(err)
+	}
+	return int(count), nil
+}
+
+// Chain returns up to limit entries following the given sequence, oldest first
+func (r *AuditRepositoryImpl) Chain(ctx context.Context, afterSequence int64, limit int) ([]*audit.Entry, error) {
+	dbEntries, err := r.queries.ListAuditChain(ctx, sqlcgen.ListAuditChainParams{
+		TenantID:      tenant.ID(ctx),
+		AfterSequence: afterSequence,
+		RowLimit:      int32(limit),
+	})
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	return toAuditEntries(dbEntries), nil
+}
+
+// toAuditEntries converts database rows to audit entries
+func toAuditEntries(dbEntries []sqlcgen.AuditLog) []*audit.Entry {
+	entries := make([]*audit.Entry, 0, len(dbEntries))
+	for _, dbEntry := range dbEntries {
+		entries = append(entries, toAuditEntry(dbEntry))
+	}
+	return entries
+}
+
+// toAuditEntry converts a database row to an audit entry
+func toAuditEntry(dbEntry sqlcgen.AuditLog) *audit.Entry {
+	return &audit.Entry{
+		Sequence:      dbEntry.Sequence,
+		Actor:         dbEntry.Actor,
+		Command:       dbEntry.Command,
+		AggregateType: dbEntry.AggregateType,
+		AggregateID:   dbEntry.AggregateID,
+		Input:         dbEntry.Input,
+		Before:        dbEntry.BeforeState,
+		After:         dbEntry.AfterState,
+		RequestID:     dbEntry.RequestID,
+		OccurredAt:    dbEntry.OccurredAt.UTC(),
+		PreviousHash:  dbEntry.PreviousHash,
+		Hash:          dbEntry.Hash,
+	}
+}
@@ -0,0 +1,83 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/persistence/sqlcgen"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// CatalogRepositoryImpl serves and rebuilds the product_catalog_view read model
+// Database triggers keep the view in step with products and inventory, so it has no write methods
+type CatalogRepositoryImpl struct {
+	db      *sql.DB
+	queries *sqlcgen.Queries
+}
+
+// NewCatalogQueryRepository creates a new instance for query operations
+// db may be a *sql.DB or a ReadRouter that sends reads to replicas
+func NewCatalogQueryRepository(db sqlcgen.DBTX) product.CatalogQueryRepository {
+	return &CatalogRepositoryImpl{
+		queries: sqlcgen.New(db),
+	}
+}
+
+// NewCatalogProjection creates a new instance that rebuilds the read model
+func NewCatalogProjection(db *sql.DB) product.CatalogProjection {
+	return &CatalogRepositoryImpl{
+		db:      db,
+		queries: sqlcgen.New(db),
+	}
+}
+
+// GetByProductID retrieves the catalog entry of a product
+func (r *CatalogRepositoryImpl) GetByProductID(ctx context.Context, productID string) (*product.CatalogEntry, error) {
+	row, err := r.queries.GetProductCatalogEntry(ctx, sqlcgen.GetProductCatalogEntryParams{
+		TenantID:  tenant.ID(ctx),
+		ProductID: productID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Product not found
+		}
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	priceAmount, err := strconv.ParseFloat(row.PriceAmount, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &product.CatalogEntry{
+		ProductID:         row.ProductID,
+		Name:              row.Name,
+		PriceAmount:       priceAmount,
+		PriceCurrency:     row.PriceCurrency,
+		HasInventory:      row.HasInventory,
+		Quantity:          int(row.Quantity),
+		ReservedQuantity:  int(row.ReservedQuantity),
+		AvailableQuantity: int(row.AvailableQuantity),
+		Location:          row.Location.String,
+		CreatedAt:         row.CreatedAt,
+		UpdatedAt:         row.UpdatedAt,
+	}, nil
+}
+
+// Rebuild clears the read model and regenerates it from products and inventory in one transaction
+// It covers every tenant, so it is meant for administrative commands rather than requests
+func (r *CatalogRepositoryImpl) Rebuild(ctx context.Context) (int, error) {
+	var entries int64
+	err := runInTx(ctx, r.db, r.queries, func(q *sqlcgen.Queries) error {
+		if err := q.ClearProductCatalog(ctx); err != nil {
+			return err
+		}
+		var err error
+		entries, err = q.RebuildProductCatalog(ctx)
+		return err
+	})
+	return int(entries), err
+}
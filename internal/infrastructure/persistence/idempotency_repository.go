@@ -0,0 +1,93 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/persistence/sqlcgen"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/idempotency"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// IdempotencyRepositoryImpl implements the idempotency.Store interface
+type IdempotencyRepositoryImpl struct {
+	queries *sqlcgen.Queries
+}
+
+// NewIdempotencyStore creates a new instance of IdempotencyRepositoryImpl
+func NewIdempotencyStore(db *sql.DB) idempotency.Store {
+	return &IdempotencyRepositoryImpl{
+		queries: sqlcgen.New(db),
+	}
+}
+
+// Reserve claims the key unless an unexpired record already holds it
+// Keys are scoped by the tenant of the context, so tenants cannot replay each other's responses
+func (r *IdempotencyRepositoryImpl) Reserve(ctx context.Context, record *idempotency.Record) (bool, error) {
+	rows, err := r.queries.ReserveIdempotencyKey(ctx, sqlcgen.ReserveIdempotencyKeyParams{
+		TenantID:    tenant.ID(ctx),
+		Key:         record.Key,
+		Fingerprint: record.Fingerprint,
+		CreatedAt:   record.CreatedAt,
+		ExpiresAt:   record.ExpiresAt,
+	})
+	if err != nil {
+		return false, apperrors.WrapDatabaseError(err)
+	}
+	return rows > 0, nil
+}
+
+// Get retrieves the unexpired record for a key
+func (r *IdempotencyRepositoryImpl) Get(ctx context.Context, key string) (*idempotency.Record, error) {
+	dbRecord, err := r.queries.GetIdempotencyKey(ctx, sqlcgen.GetIdempotencyKeyParams{
+		TenantID: tenant.ID(ctx),
+		Key:      key,
+		Now:      time.Now(),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Key not found or expired
+		}
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	return &idempotency.Record{
+		Key:          dbRecord.Key,
+		Fingerprint:  dbRecord.Fingerprint,
+		StatusCode:   int(dbRecord.StatusCode.Int32),
+		ContentType:  dbRecord.ContentType,
+		ResponseBody: dbRecord.ResponseBody,
+		CreatedAt:    dbRecord.CreatedAt,
+		ExpiresAt:    dbRecord.ExpiresAt,
+	}, nil
+}
+
+// Complete stores the response of a reserved request
+func (r *IdempotencyRepositoryImpl) Complete(ctx context.Context, key string, statusCode int, contentType string, responseBody []byte) error {
+	err := r.queries.CompleteIdempotencyKey(ctx, sqlcgen.CompleteIdempotencyKeyParams{
+		TenantID:     tenant.ID(ctx),
+		Key:          key,
+		StatusCode:   sql.NullInt32{Int32: int32(statusCode), Valid: true},
+		ContentType:  contentType,
+		ResponseBody: responseBody,
+		CompletedAt:  toNullTime(time.Now()),
+	})
+	if err != nil {
+		return apperrors.WrapDatabaseError(err)
+	}
+	return nil
+}
+
+// Release removes a reservation so the request can be retried
+func (r *IdempotencyRepositoryImpl) Release(ctx context.Context, key string) error {
+	if err := r.queries.DeleteIdempotencyKey(ctx, sqlcgen.DeleteIdempotencyKeyParams{
+		TenantID: tenant.ID(ctx),
+		Key:      key,
+	}); err != nil {
+		return apperrors.WrapDatabaseError(err)
+	}
+	return nil
+}
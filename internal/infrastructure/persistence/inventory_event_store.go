@@ -0,0 +1,464 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/event"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/persistence/sqlcgen"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// EventSourcedInventoryRepository implements both InventoryCommandRepository and InventoryQueryRepository
+// on the inventory event streams
+// Inventories are rehydrated from their latest snapshot and the events after it. Changes append
+// their events to the stream of the product and write the inventory table as a projection in the
+// same transaction, so lists, counts and the catalog keep reading it as before.
+// Rows written before event sourcing was enabled are adopted by snapshotting them on their next change.
+type EventSourcedInventoryRepository struct {
+	db            *sql.DB
+	queries       *sqlcgen.Queries
+	projection    *InventoryRepositoryImpl
+	snapshotEvery int
+}
+
+// NewEventSourcedInventoryCommandRepository creates a new instance for command operations
+// A snapshot is taken whenever a change crosses a multiple of snapshotEvery events
+func NewEventSourcedInventoryCommandRepository(db *sql.DB, snapshotEvery int) inventory.InventoryCommandRepository {
+	return newEventSourcedInventoryRepository(db, sqlcgen.New(db), snapshotEvery)
+}
+
+// NewEventSourcedInventoryQueryRepository creates a new instance for query operations
+// db may be a *sql.DB or a ReadRouter that sends reads to replicas
+func NewEventSourcedInventoryQueryRepository(db sqlcgen.DBTX) inventory.InventoryQueryRepository {
+	return newEventSourcedInventoryRepository(nil, sqlcgen.New(db), 0)
+}
+
+// newEventSourcedInventoryRepository binds the repository and its projection to the same queries
+// A repository without a database handle runs in the transaction of its queries
+func newEventSourcedInventoryRepository(db *sql.DB, queries *sqlcgen.Queries, snapshotEvery int) *EventSourcedInventoryRepository {
+	return &EventSourcedInventoryRepository{
+		db:            db,
+		queries:       queries,
+		projection:    &InventoryRepositoryImpl{db: db, queries: queries},
+		snapshotEvery: snapshotEvery,
+	}
+}
+
+// Create starts the event stream of a new inventory and stores its projection
+// The inventory of a product whose earlier inventory was deleted continues that stream.
+// An inventory not raised by NewInventory has no creation event to start from; its row is
+// adopted into a stream on its first change. Pending events are stored in the outbox as well.
+func (r *EventSourcedInventoryRepository) Create(ctx context.Context, inv *inventory.Inventory) error {
+	events := inv.PendingEvents()
+	startsStream := len(events) > 0 && events[0].EventName() == inventory.EventInventoryCreated
+
+	metadata, err := json.Marshal(inv.Metadata())
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.CodeInternalError, "failed to encode inventory metadata")
+	}
+
+	return runInTx(ctx, r.db, r.queries, func(q *sqlcgen.Queries) error {
+		// Versions follow the stream, so a row that does not start one is ahead of it
+		from, err := r.streamVersion(ctx, q, inv.ProductID())
+		if err != nil {
+			return err
+		}
+		version := from + inv.Version()
+		if startsStream {
+			version = from + len(events)
+		}
+
+		params := sqlcgen.CreateInventoryParams{
+			ID:                  inv.ID(),
+			TenantID:            tenant.ID(ctx),
+			ProductID:           inv.ProductID(),
+			Quantity:            int32(inv.Quantity()),
+			ReservedQuantity:    int32(inv.ReservedQuantity()),
+			Location:            toNullString(inv.Location()),
+			CreatedAt:           inv.CreatedAt(),
+			UpdatedAt:           inv.UpdatedAt(),
+			StockPolicy:         string(inv.StockPolicy().Type()),
+			BackorderLimit:      int32(inv.StockPolicy().BackorderLimit()),
+			BackorderedQuantity: int32(inv.BackorderedQuantity()),
+			Version:             int32(version),
+			Metadata:            metadata,
+		}
+		if err := q.CreateInventory(ctx, params); err != nil {
+			return apperrors.WrapDatabaseError(err)
+		}
+		if startsStream {
+			if err := r.append(ctx, q, inv, from, events); err != nil {
+				return err
+			}
+		}
+		return appendOutbox(ctx, q, inventory.AggregateType, events)
+	})
+}
+
+// GetByProductID rehydrates the inventory of a product from its event stream
+func (r *EventSourcedInventoryRepository) GetByProductID(ctx context.Context, productID string) (*inventory.Inventory, error) {
+	return r.load(ctx, r.queries, productID)
+}
+
+// GetByProductIDs rehydrates the inventories of the given products, skipping products without one
+// The projection rows, snapshots and events of all the products are read in one query each.
+func (r *EventSourcedInventoryRepository) GetByProductIDs(ctx context.Context, productIDs []string) ([]*inventory.Inventory, error) {
+	if len(productIDs) == 0 {
+		return []*inventory.Inventory{}, nil
+	}
+
+	tenantID := tenant.ID(ctx)
+	rows, err := r.queries.ListInventoryByProductIDs(ctx, sqlcgen.ListInventoryByProductIDsParams{
+		TenantID:   tenantID,
+		ProductIds: productIDs,
+	})
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	if len(rows) == 0 {
+		return []*inventory.Inventory{}, nil
+	}
+
+	// Events are read before snapshots: a snapshot taken in between only covers events
+	// that were already read, and rehydrate drops those
+	dbEvents, err := r.queries.ListInventoryEventsByProductIDs(ctx, sqlcgen.ListInventoryEventsByProductIDsParams{
+		TenantID:   tenantID,
+		ProductIds: productIDs,
+	})
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	eventsByProduct := make(map[string][]sqlcgen.InventoryEvent, len(rows))
+	for _, dbEvent := range dbEvents {
+		eventsByProduct[dbEvent.ProductID] = append(eventsByProduct[dbEvent.ProductID], dbEvent)
+	}
+
+	dbSnapshots, err := r.queries.ListInventorySnapshotsByProductIDs(ctx, sqlcgen.ListInventorySnapshotsByProductIDsParams{
+		TenantID:   tenantID,
+		ProductIds: productIDs,
+	})
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	snapshots := make(map[string]*inventory.Snapshot, len(dbSnapshots))
+	for _, dbSnapshot := range dbSnapshots {
+		snapshot, err := decodeSnapshot(dbSnapshot)
+		if err != nil {
+			return nil, err
+		}
+		snapshots[dbSnapshot.ProductID] = snapshot
+	}
+
+	inventories := make([]*inventory.Inventory, 0, len(rows))
+	for _, row := range rows {
+		inv, err := r.rehydrate(row, snapshots[row.ProductID], eventsByProduct[row.ProductID])
+		if err != nil {
+			return nil, err
+		}
+		inventories = append(inventories, inv)
+	}
+	return inventories, nil
+}
+
+// ListByLocation retrieves all inventory records stored at a location from the projection
+func (r *EventSourcedInventoryRepository) ListByLocation(ctx context.Context, location string) ([]*inventory.Inventory, error) {
+	return r.projection.ListByLocation(ctx, location)
+}
+
+// List retrieves a page of inventory records matching the filter from the projection
+func (r *EventSourcedInventoryRepository) List(ctx context.Context, filter inventory.InventoryFilter) ([]*inventory.Inventory, error) {
+	return r.projection.List(ctx, filter)
+}
+
+// Count returns how many inventory records match the filter in the projection
+func (r *EventSourcedInventoryRepository) Count(ctx context.Context, filter inventory.InventoryFilter) (int, error) {
+	return r.projection.Count(ctx, filter)
+}
+
+// Update appends the inventory's pending events to its stream if the stream is still at the loaded version
+func (r *EventSourcedInventoryRepository) Update(ctx context.Context, inv *inventory.Inventory) error {
+	return runInTx(ctx, r.db, r.queries, func(q *sqlcgen.Queries) error {
+		return r.save(ctx, q, inv)
+	})
+}
+
+// UpdateBatch appends the pending events of several inventories within a single transaction
+// A version mismatch on any stream rolls back the whole batch
+func (r *EventSourcedInventoryRepository) UpdateBatch(ctx context.Context, inventories []*inventory.Inventory) error {
+	return runInTx(ctx, r.db, r.queries, func(q *sqlcgen.Queries) error {
+		for _, inv := range inventories {
+			if err := r.save(ctx, q, inv); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete ends the event stream of an inventory with its InventoryDeleted event and deletes the projection
+// The stream is kept, so its history can still be replayed and a new inventory for the product continues it.
+// The projection row carries the stream version, so it is deleted with the same check as Update.
+func (r *EventSourcedInventoryRepository) Delete(ctx context.Context, inv *inventory.Inventory) error {
+	return runInTx(ctx, r.db, r.queries, func(q *sqlcgen.Queries) error {
+		expected := inv.Version()
+		events := inv.PendingEvents()
+		if err := r.follow(ctx, q, inv.ProductID(), expected); err != nil {
+			return err
+		}
+
+		rows, err := q.DeleteInventory(ctx, sqlcgen.DeleteInventoryParams{
+			TenantID:        tenant.ID(ctx),
+			ProductID:       inv.ProductID(),
+			ExpectedVersion: int32(expected),
+		})
+		if err != nil {
+			return apperrors.WrapDatabaseError(err)
+		}
+		if rows == 0 {
+			return inventory.ErrConcurrentModification
+		}
+
+		if err := r.append(ctx, q, inv, expected, events); err != nil {
+			return err
+		}
+		return appendOutbox(ctx, q, inventory.AggregateType, events)
+	})
+}
+
+// AdjustStock adjusts the stock quantity for a product through the aggregate, so the change is in its stream
+func (r *EventSourcedInventoryRepository) AdjustStock(ctx context.Context, productID string, adjustment int) error {
+	return runInTx(ctx, r.db, r.queries, func(q *sqlcgen.Queries) error {
+		inv, err := r.load(ctx, q, productID)
+		if err != nil || inv == nil {
+			return err
+		}
+		if err := inv.AdjustQuantity(adjustment); err != nil {
+			return err
+		}
+		return r.save(ctx, q, inv)
+	})
+}
+
+// load rebuilds an inventory from its snapshot and the events after it
+// The projection row decides whether the inventory exists; a row ahead of its stream
+// (written before event sourcing was enabled) is returned as stored.
+func (r *EventSourcedInventoryRepository) load(ctx context.Context, q *sqlcgen.Queries, productID string) (*inventory.Inventory, error) {
+	tenantID := tenant.ID(ctx)
+	row, err := q.GetInventoryByProductID(ctx, sqlcgen.GetInventoryByProductIDParams{TenantID: tenantID, ProductID: productID})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Inventory not found
+		}
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	snapshot, err := r.snapshot(ctx, q, productID)
+	if err != nil {
+		return nil, err
+	}
+	after := 0
+	if snapshot != nil {
+		after = snapshot.Version
+	}
+	dbEvents, err := q.ListInventoryEvents(ctx, sqlcgen.ListInventoryEventsParams{
+		TenantID:     tenantID,
+		ProductID:    productID,
+		AfterVersion: int32(after),
+	})
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	return r.rehydrate(row, snapshot, dbEvents)
+}
+
+// rehydrate rebuilds an inventory from its projection row, snapshot and the events of its stream
+// Events the snapshot already covers are skipped.
+func (r *EventSourcedInventoryRepository) rehydrate(row sqlcgen.Inventory, snapshot *inventory.Snapshot, dbEvents []sqlcgen.InventoryEvent) (*inventory.Inventory, error) {
+	after := 0
+	if snapshot != nil {
+		after = snapshot.Version
+	}
+	version := after
+	history := make([]event.Event, 0, len(dbEvents))
+	for _, dbEvent := range dbEvents {
+		if int(dbEvent.Version) <= after {
+			continue
+		}
+		e, err := inventory.DecodeEvent(dbEvent.EventName, dbEvent.Payload)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, e)
+		version = int(dbEvent.Version)
+	}
+	if version == 0 || version < int(row.Version) {
+		return r.projection.toDomainInventory(row), nil
+	}
+	return inventory.RehydrateInventory(snapshot, history)
+}
+
+// save appends the pending events of an inventory to its stream and writes the projection
+// The projection update holds the row lock and checks the version, so of two writers
+// loaded at the same version only the first appends.
+func (r *EventSourcedInventoryRepository) save(ctx context.Context, q *sqlcgen.Queries, inv *inventory.Inventory) error {
+	tenantID := tenant.ID(ctx)
+	expected := inv.Version()
+	events := inv.PendingEvents()
+
+	if err := r.follow(ctx, q, inv.ProductID(), expected); err != nil {
+		return err
+	}
+
+	metadata, err := json.Marshal(inv.Metadata())
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.CodeInternalError, "failed to encode inventory metadata")
+	}
+	rows, err := q.ProjectInventory(ctx, sqlcgen.ProjectInventoryParams{
+		TenantID:            tenantID,
+		ProductID:           inv.ProductID(),
+		Quantity:            int32(inv.Quantity()),
+		ReservedQuantity:    int32(inv.ReservedQuantity()),
+		Location:            toNullString(inv.Location()),
+		UpdatedAt:           inv.UpdatedAt(),
+		StockPolicy:         string(inv.StockPolicy().Type()),
+		BackorderLimit:      int32(inv.StockPolicy().BackorderLimit()),
+		BackorderedQuantity: int32(inv.BackorderedQuantity()),
+		Metadata:            metadata,
+		Version:             int32(expected + len(events)),
+		ExpectedVersion:     int32(expected),
+	})
+	if err != nil {
+		return apperrors.WrapDatabaseError(err)
+	}
+	if rows == 0 {
+		return inventory.ErrConcurrentModification
+	}
+
+	if err := r.append(ctx, q, inv, expected, events); err != nil {
+		return err
+	}
+	return appendOutbox(ctx, q, inventory.AggregateType, events)
+}
+
+// append stores events as the versions after from, snapshotting the inventory
+// when they cross a multiple of the snapshot interval
+func (r *EventSourcedInventoryRepository) append(ctx context.Context, q *sqlcgen.Queries, inv *inventory.Inventory, from int, events []event.Event) error {
+	recordedAt := time.Now().UTC()
+	for i, e := range events {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "failed to encode event")
+		}
+		err = q.AppendInventoryEvent(ctx, sqlcgen.AppendInventoryEventParams{
+			TenantID:   tenant.ID(ctx),
+			ProductID:  inv.ProductID(),
+			Version:    int32(from + i + 1),
+			EventName:  e.EventName(),
+			Payload:    payload,
+			OccurredAt: e.OccurredAt().UTC(),
+			RecordedAt: recordedAt,
+		})
+		if err != nil {
+			return apperrors.WrapDatabaseError(err)
+		}
+	}
+
+	to := from + len(events)
+	if r.snapshotEvery < 1 || to/r.snapshotEvery == from/r.snapshotEvery {
+		return nil
+	}
+	snapshot := inv.Snapshot()
+	snapshot.Version = to
+	return r.saveSnapshot(ctx, q, snapshot)
+}
+
+// follow checks that the stream of a product is at the version the caller loaded
+// A projection row ahead of its stream is adopted, so events can follow it.
+func (r *EventSourcedInventoryRepository) follow(ctx context.Context, q *sqlcgen.Queries, productID string, expected int) error {
+	current, err := r.streamVersion(ctx, q, productID)
+	if err != nil {
+		return err
+	}
+	if current > expected {
+		return inventory.ErrConcurrentModification
+	}
+	if current < expected {
+		return r.adopt(ctx, q, productID, expected)
+	}
+	return nil
+}
+
+// adopt snapshots a projection row that is ahead of its stream, so events can follow it
+func (r *EventSourcedInventoryRepository) adopt(ctx context.Context, q *sqlcgen.Queries, productID string, version int) error {
+	row, err := q.GetInventoryByProductID(ctx, sqlcgen.GetInventoryByProductIDParams{TenantID: tenant.ID(ctx), ProductID: productID})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return inventory.ErrConcurrentModification
+		}
+		return apperrors.WrapDatabaseError(err)
+	}
+	if int(row.Version) != version {
+		return inventory.ErrConcurrentModification
+	}
+	return r.saveSnapshot(ctx, q, r.projection.toDomainInventory(row).Snapshot())
+}
+
+// streamVersion returns the version of the last event or snapshot of a stream, 0 for no stream
+func (r *EventSourcedInventoryRepository) streamVersion(ctx context.Context, q *sqlcgen.Queries, productID string) (int, error) {
+	version, err := q.GetInventoryEventsVersion(ctx, sqlcgen.GetInventoryEventsVersionParams{TenantID: tenant.ID(ctx), ProductID: productID})
+	if err != nil {
+		return 0, apperrors.WrapDatabaseError(err)
+	}
+	snapshot, err := r.snapshot(ctx, q, productID)
+	if err != nil {
+		return 0, err
+	}
+	if snapshot != nil && snapshot.Version > int(version) {
+		return snapshot.Version, nil
+	}
+	return int(version), nil
+}
+
+// snapshot returns the latest snapshot of a stream, or nil if it has none
+func (r *EventSourcedInventoryRepository) snapshot(ctx context.Context, q *sqlcgen.Queries, productID string) (*inventory.Snapshot, error) {
+	dbSnapshot, err := q.GetInventorySnapshot(ctx, sqlcgen.GetInventorySnapshotParams{TenantID: tenant.ID(ctx), ProductID: productID})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	return decodeSnapshot(dbSnapshot)
+}
+
+// decodeSnapshot converts a stored snapshot into its domain form
+func decodeSnapshot(dbSnapshot sqlcgen.InventorySnapshot) (*inventory.Snapshot, error) {
+	var snapshot inventory.Snapshot
+	if err := json.Unmarshal(dbSnapshot.State, &snapshot); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.CodeInternalError, "failed to decode inventory snapshot")
+	}
+	snapshot.Version = int(dbSnapshot.Version)
+	return &snapshot, nil
+}
+
+// saveSnapshot stores a snapshot unless the stream already has a later one
+func (r *EventSourcedInventoryRepository) saveSnapshot(ctx context.Context, q *sqlcgen.Queries, snapshot inventory.Snapshot) error {
+	state, err := json.Marshal(snapshot)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.CodeInternalError, "failed to encode inventory snapshot")
+	}
+	err = q.SaveInventorySnapshot(ctx, sqlcgen.SaveInventorySnapshotParams{
+		TenantID:  tenant.ID(ctx),
+		ProductID: snapshot.ProductID,
+		Version:   int32(snapshot.Version),
+		State:     state,
+		CreatedAt: time.Now().UTC(),
+	})
+	return apperrors.WrapDatabaseError(err)
+}
@@ -3,17 +3,20 @@ package persistence
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"time"
 
 	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
 	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/persistence/sqlcgen"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
 	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
 )
 
 // InventoryRepositoryImpl implements the inventory.InventoryRepository interface
 // It also satisfies both InventoryCommandRepository and InventoryQueryRepository
 type InventoryRepositoryImpl struct {
+	db      *sql.DB
 	queries *sqlcgen.Queries
 }
 
@@ -21,6 +24,7 @@ type InventoryRepositoryImpl struct {
 // Deprecated: Use NewInventoryCommandRepository and NewInventoryQueryRepository instead
 func NewInventoryRepository(db *sql.DB) inventory.InventoryRepository {
 	return &InventoryRepositoryImpl{
+		db:      db,
 		queries: sqlcgen.New(db),
 	}
 }
@@ -28,39 +32,57 @@ func NewInventoryRepository(db *sql.DB) inventory.InventoryRepository {
 // NewInventoryCommandRepository creates a new instance for command operations
 func NewInventoryCommandRepository(db *sql.DB) inventory.InventoryCommandRepository {
 	return &InventoryRepositoryImpl{
+		db:      db,
 		queries: sqlcgen.New(db),
 	}
 }
 
 // NewInventoryQueryRepository creates a new instance for query operations
-func NewInventoryQueryRepository(db *sql.DB) inventory.InventoryQueryRepository {
+// db may be a *sql.DB or a ReadRouter that sends reads to replicas
+func NewInventoryQueryRepository(db sqlcgen.DBTX) inventory.InventoryQueryRepository {
 	return &InventoryRepositoryImpl{
 		queries: sqlcgen.New(db),
 	}
 }
 
 // Create stores a new inventory record in the database
+// The inventory's pending events are stored in the outbox in the same transaction
 func (r *InventoryRepositoryImpl) Create(ctx context.Context, inv *inventory.Inventory) error {
-	params := sqlcgen.CreateInventoryParams{
-		ID:               inv.ID(),
-		ProductID:        inv.ProductID(),
-		Quantity:         int32(inv.Quantity()),
-		ReservedQuantity: int32(inv.ReservedQuantity()),
-		Location:         toNullString(inv.Location()),
-		CreatedAt:        inv.CreatedAt(),
-		UpdatedAt:        inv.UpdatedAt(),
+	metadata, err := json.Marshal(inv.Metadata())
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.CodeInternalError, "failed to encode inventory metadata")
 	}
 
-	err := r.queries.CreateInventory(ctx, params)
-	if err != nil {
-		return apperrors.WrapDatabaseError(err)
+	params := sqlcgen.CreateInventoryParams{
+		ID:                  inv.ID(),
+		TenantID:            tenant.ID(ctx),
+		ProductID:           inv.ProductID(),
+		Quantity:            int32(inv.Quantity()),
+		ReservedQuantity:    int32(inv.ReservedQuantity()),
+		Location:            toNullString(inv.Location()),
+		CreatedAt:           inv.CreatedAt(),
+		UpdatedAt:           inv.UpdatedAt(),
+		StockPolicy:         string(inv.StockPolicy().Type()),
+		BackorderLimit:      int32(inv.StockPolicy().BackorderLimit()),
+		BackorderedQuantity: int32(inv.BackorderedQuantity()),
+		Version:             int32(inv.Version()),
+		Metadata:            metadata,
 	}
-	return nil
+
+	return runInTx(ctx, r.db, r.queries, func(q *sqlcgen.Queries) error {
+		if err := q.CreateInventory(ctx, params); err != nil {
+			return apperrors.WrapDatabaseError(err)
+		}
+		return appendOutbox(ctx, q, inventory.AggregateType, inv.PendingEvents())
+	})
 }
 
 // GetByProductID retrieves inventory by product ID from the database
 func (r *InventoryRepositoryImpl) GetByProductID(ctx context.Context, productID string) (*inventory.Inventory, error) {
-	dbInventory, err := r.queries.GetInventoryByProductID(ctx, productID)
+	dbInventory, err := r.queries.GetInventoryByProductID(ctx, sqlcgen.GetInventoryByProductIDParams{
+		TenantID:  tenant.ID(ctx),
+		ProductID: productID,
+	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil // Inventory not found
@@ -71,38 +93,173 @@ func (r *InventoryRepositoryImpl) GetByProductID(ctx context.Context, productID
 	return r.toDomainInventory(dbInventory), nil
 }
 
+// GetByProductIDs retrieves the inventory records for the given products in a single query
+func (r *InventoryRepositoryImpl) GetByProductIDs(ctx context.Context, productIDs []string) ([]*inventory.Inventory, error) {
+	if len(productIDs) == 0 {
+		return []*inventory.Inventory{}, nil
+	}
+
+	dbInventories, err := r.queries.ListInventoryByProductIDs(ctx, sqlcgen.ListInventoryByProductIDsParams{
+		TenantID:   tenant.ID(ctx),
+		ProductIds: productIDs,
+	})
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	inventories := make([]*inventory.Inventory, 0, len(dbInventories))
+	for _, dbInventory := range dbInventories {
+		inventories = append(inventories, r.toDomainInventory(dbInventory))
+	}
+
+	return inventories, nil
+}
+
+// ListByLocation retrieves all inventory records stored at a location
+func (r *InventoryRepositoryImpl) ListByLocation(ctx context.Context, location string) ([]*inventory.Inventory, error) {
+	dbInventories, err := r.queries.ListInventoryByLocation(ctx, sqlcgen.ListInventoryByLocationParams{
+		TenantID: tenant.ID(ctx),
+		Location: toNullString(location),
+	})
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	inventories := make([]*inventory.Inventory, 0, len(dbInventories))
+	for _, dbInventory := range dbInventories {
+		inventories = append(inventories, r.toDomainInventory(dbInventory))
+	}
+
+	return inventories, nil
+}
+
+// List retrieves a page of inventory records matching the filter
+func (r *InventoryRepositoryImpl) List(ctx context.Context, filter inventory.InventoryFilter) ([]*inventory.Inventory, error) {
+	location, zeroStockOnly, availableBelow, updatedSince := toInventoryFilterParams(filter)
+	sortBy := filter.SortBy
+	if sortBy == "" {
+		sortBy = inventory.SortByProductID
+	}
+
+	dbInventories, err := r.queries.ListInventory(ctx, sqlcgen.ListInventoryParams{
+		TenantID:       tenant.ID(ctx),
+		Location:       location,
+		ZeroStockOnly:  zeroStockOnly,
+		AvailableBelow: availableBelow,
+		UpdatedSince:   updatedSince,
+		SortBy:         string(sortBy),
+		SortDesc:       filter.SortDesc,
+		RowLimit:       int32(filter.Limit),
+		RowOffset:      int32(filter.Offset),
+	})
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	inventories := make([]*inventory.Inventory, 0, len(dbInventories))
+	for _, dbInventory := range dbInventories {
+		inventories = append(inventories, r.toDomainInventory(dbInventory))
+	}
+	return inventories, nil
+}
+
+// Count returns how many inventory records match the filter
+func (r *InventoryRepositoryImpl) Count(ctx context.Context, filter inventory.InventoryFilter) (int, error) {
+	location, zeroStockOnly, availableBelow, updatedSince := toInventoryFilterParams(filter)
+
+	count, err := r.queries.CountInventory(ctx, sqlcgen.CountInventoryParams{
+		TenantID:       tenant.ID(ctx),
+		Location:       location,
+		ZeroStockOnly:  zeroStockOnly,
+		AvailableBelow: availableBelow,
+		UpdatedSince:   updatedSince,
+	})
+	if err != nil {
+		return 0, apperrors.WrapDatabaseError(err)
+	}
+	return int(count), nil
+}
+
 // Update updates an existing inventory record in the database
+// The update only applies if the stored version still matches the entity's version
 func (r *InventoryRepositoryImpl) Update(ctx context.Context, inv *inventory.Inventory) error {
+	return runInTx(ctx, r.db, r.queries, func(q *sqlcgen.Queries) error {
+		return r.update(ctx, q, inv)
+	})
+}
+
+// UpdateBatch updates several inventory records within a single transaction
+// A version mismatch on any record rolls back the whole batch
+func (r *InventoryRepositoryImpl) UpdateBatch(ctx context.Context, inventories []*inventory.Inventory) error {
+	return runInTx(ctx, r.db, r.queries, func(q *sqlcgen.Queries) error {
+		for _, inv := range inventories {
+			if err := r.update(ctx, q, inv); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// update writes an inventory record with a version check using the given queries,
+// followed by its pending events in the outbox
+func (r *InventoryRepositoryImpl) update(ctx context.Context, q *sqlcgen.Queries, inv *inventory.Inventory) error {
+	metadata, err := json.Marshal(inv.Metadata())
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.CodeInternalError, "failed to encode inventory metadata")
+	}
+
 	params := sqlcgen.UpdateInventoryParams{
-		ProductID:        inv.ProductID(),
-		Quantity:         int32(inv.Quantity()),
-		ReservedQuantity: int32(inv.ReservedQuantity()),
-		Location:         toNullString(inv.Location()),
-		UpdatedAt:        inv.UpdatedAt(),
+		TenantID:            tenant.ID(ctx),
+		ProductID:           inv.ProductID(),
+		Quantity:            int32(inv.Quantity()),
+		ReservedQuantity:    int32(inv.ReservedQuantity()),
+		Location:            toNullString(inv.Location()),
+		UpdatedAt:           inv.UpdatedAt(),
+		StockPolicy:         string(inv.StockPolicy().Type()),
+		BackorderLimit:      int32(inv.StockPolicy().BackorderLimit()),
+		BackorderedQuantity: int32(inv.BackorderedQuantity()),
+		Metadata:            metadata,
+		ExpectedVersion:     int32(inv.Version()),
 	}
 
-	err := r.queries.UpdateInventory(ctx, params)
+	rows, err := q.UpdateInventory(ctx, params)
 	if err != nil {
 		return apperrors.WrapDatabaseError(err)
 	}
-	return nil
+	if rows == 0 {
+		return inventory.ErrConcurrentModification
+	}
+	return appendOutbox(ctx, q, inventory.AggregateType, inv.PendingEvents())
 }
 
-// Delete removes an inventory record from the database
-func (r *InventoryRepositoryImpl) Delete(ctx context.Context, productID string) error {
-	err := r.queries.DeleteInventory(ctx, productID)
-	if err != nil {
-		return apperrors.WrapDatabaseError(err)
-	}
-	return nil
+// Delete removes an inventory record from the database and stores its pending events in the outbox
+// The delete only applies if the stored version still matches the entity's version
+// and nothing is reserved or backordered
+func (r *InventoryRepositoryImpl) Delete(ctx context.Context, inv *inventory.Inventory) error {
+	return runInTx(ctx, r.db, r.queries, func(q *sqlcgen.Queries) error {
+		rows, err := q.DeleteInventory(ctx, sqlcgen.DeleteInventoryParams{
+			TenantID:        tenant.ID(ctx),
+			ProductID:       inv.ProductID(),
+			ExpectedVersion: int32(inv.Version()),
+		})
+		if err != nil {
+			return apperrors.WrapDatabaseError(err)
+		}
+		if rows == 0 {
+			return inventory.ErrConcurrentModification
+		}
+		return appendOutbox(ctx, q, inventory.AggregateType, inv.PendingEvents())
+	})
 }
 
 // AdjustStock adjusts the stock quantity for a product
 func (r *InventoryRepositoryImpl) AdjustStock(ctx context.Context, productID string, adjustment int) error {
 	params := sqlcgen.AdjustInventoryQuantityParams{
-		ProductID: productID,
-		Quantity:  int32(adjustment),
-		UpdatedAt: time.Now(),
+		Adjustment: int32(adjustment),
+		UpdatedAt:  time.Now(),
+		TenantID:   tenant.ID(ctx),
+		ProductID:  productID,
 	}
 
 	err := r.queries.AdjustInventoryQuantity(ctx, params)
@@ -119,12 +276,50 @@ func (r *InventoryRepositoryImpl) toDomainInventory(dbInventory sqlcgen.Inventor
 		dbInventory.ProductID,
 		int(dbInventory.Quantity),
 		int(dbInventory.ReservedQuantity),
+		int(dbInventory.BackorderedQuantity),
+		r.toDomainStockPolicy(dbInventory),
 		fromNullString(dbInventory.Location),
+		r.toDomainMetadata(dbInventory.Metadata),
 		dbInventory.CreatedAt,
 		dbInventory.UpdatedAt,
+		int(dbInventory.Version),
 	)
 }
 
+// toDomainMetadata decodes the metadata column, treating malformed JSON as empty
+func (r *InventoryRepositoryImpl) toDomainMetadata(raw []byte) map[string]string {
+	metadata := map[string]string{}
+	if len(raw) == 0 {
+		return metadata
+	}
+	if err := json.Unmarshal(raw, &metadata); err != nil {
+		return map[string]string{}
+	}
+	return metadata
+}
+
+// toDomainStockPolicy rebuilds the stock policy value object from its persisted columns
+// Rows that fail validation fall back to the strict policy so stock is never oversold
+func (r *InventoryRepositoryImpl) toDomainStockPolicy(dbInventory sqlcgen.Inventory) inventory.StockPolicy {
+	policy, err := inventory.NewStockPolicy(
+		inventory.StockPolicyType(dbInventory.StockPolicy),
+		int(dbInventory.BackorderLimit),
+	)
+	if err != nil {
+		return inventory.StrictStockPolicy()
+	}
+	return policy
+}
+
+// toInventoryFilterParams converts the filter conditions to nullable query parameters
+func toInventoryFilterParams(filter inventory.InventoryFilter) (sql.NullString, bool, sql.NullInt32, sql.NullTime) {
+	availableBelow := sql.NullInt32{}
+	if filter.AvailableBelow != nil {
+		availableBelow = sql.NullInt32{Int32: int32(*filter.AvailableBelow), Valid: true}
+	}
+	return toNullString(filter.Location), filter.ZeroStockOnly, availableBelow, toNullTime(filter.UpdatedSince)
+}
+
 // toNullString converts a string to sql.NullString
 func toNullString(s string) sql.NullString {
 	return sql.NullString{
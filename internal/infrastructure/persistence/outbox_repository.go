@@ -0,0 +1,169 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/event"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/persistence/sqlcgen"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/outbox"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// OutboxRepositoryImpl implements the outbox.Store interface
+type OutboxRepositoryImpl struct {
+	queries *sqlcgen.Queries
+}
+
+// NewOutboxStore creates a new instance of OutboxRepositoryImpl
+func NewOutboxStore(db *sql.DB) outbox.Store {
+	return &OutboxRepositoryImpl{
+		queries: sqlcgen.New(db),
+	}
+}
+
+// Claim leases the oldest due message of each aggregate, oldest first
+func (r *OutboxRepositoryImpl) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*outbox.Message, error) {
+	dbMessages, err := r.queries.ClaimOutboxMessages(ctx, sqlcgen.ClaimOutboxMessagesParams{
+		LockedUntil: sql.NullTime{Time: now.Add(lease).UTC(), Valid: true},
+		Now:         now.UTC(),
+		BatchSize:   int32(limit),
+	})
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	messages := toOutboxMessages(dbMessages)
+	sort.Slice(messages, func(i, j int) bool { return messages[i].ID < messages[j].ID })
+	return messages, nil
+}
+
+// MarkPublished records that a claimed message was published
+func (r *OutboxRepositoryImpl) MarkPublished(ctx context.Context, id int64, at time.Time) error {
+	err := r.queries.MarkOutboxMessagePublished(ctx, sqlcgen.MarkOutboxMessagePublishedParams{
+		ID:          id,
+		PublishedAt: sql.NullTime{Time: at.UTC(), Valid: true},
+	})
+	if err != nil {
+		return apperrors.WrapDatabaseError(err)
+	}
+	return nil
+}
+
+// MarkFailed records a failed attempt to publish a claimed message
+func (r *OutboxRepositoryImpl) MarkFailed(ctx context.Context, id int64, attempts int, nextAttemptAt time.Time, lastError string, dead bool) error {
+	status := outbox.StatusPending
+	if dead {
+		status = outbox.StatusDead
+	}
+	err := r.queries.MarkOutboxMessageFailed(ctx, sqlcgen.MarkOutboxMessageFailedParams{
+		ID:            id,
+		Attempts:      int32(attempts),
+		NextAttemptAt: nextAttemptAt.UTC(),
+		LastError:     lastError,
+		Status:        string(status),
+	})
+	if err != nil {
+		return apperrors.WrapDatabaseError(err)
+	}
+	return nil
+}
+
+// List returns the messages of the tenant of the context, oldest first
+func (r *OutboxRepositoryImpl) List(ctx context.Context, filter outbox.Filter) ([]*outbox.Message, error) {
+	dbMessages, err := r.queries.ListOutboxMessages(ctx, sqlcgen.ListOutboxMessagesParams{
+		TenantID:  tenant.ID(ctx),
+		Status:    toNullString(string(filter.Status)),
+		RowOffset: int32(filter.Offset),
+		RowLimit:  int32(filter.Limit),
+	})
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	return toOutboxMessages(dbMessages), nil
+}
+
+// Count returns how many messages of the tenant of the context match the filter
+func (r *OutboxRepositoryImpl) Count(ctx context.Context, filter outbox.Filter) (int, error) {
+	count, err := r.queries.CountOutboxMessages(ctx, sqlcgen.CountOutboxMessagesParams{
+		TenantID: tenant.ID(ctx),
+		Status:   toNullString(string(filter.Status)),
+	})
+	if err != nil {
+		return 0, apperrors.WrapDatabaseError(err)
+	}
+	return int(count), nil
+}
+
+// Replay makes a dead message of the tenant of the context pending again
+func (r *OutboxRepositoryImpl) Replay(ctx context.Context, id int64, now time.Time) (bool, error) {
+	rows, err := r.queries.ReplayOutboxMessage(ctx, sqlcgen.ReplayOutboxMessageParams{
+		TenantID:      tenant.ID(ctx),
+		ID:            id,
+		NextAttemptAt: now.UTC(),
+	})
+	if err != nil {
+		return false, apperrors.WrapDatabaseError(err)
+	}
+	return rows > 0, nil
+}
+
+// DeletePublished removes messages published before the given time
+func (r *OutboxRepositoryImpl) DeletePublished(ctx context.Context, before time.Time) (int, error) {
+	rows, err := r.queries.DeletePublishedOutboxMessages(ctx, sql.NullTime{Time: before.UTC(), Valid: true})
+	if err != nil {
+		return 0, apperrors.WrapDatabaseError(err)
+	}
+	return int(rows), nil
+}
+
+// appendOutbox stores events as pending messages of the tenant of the context using q
+// Repositories call it with the queries of the transaction that stores the aggregate
+func appendOutbox(ctx context.Context, q *sqlcgen.Queries, aggregateType string, events []event.Event) error {
+	messages, err := outbox.NewMessages(tenant.ID(ctx), aggregateType, events, time.Now().UTC())
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.CodeInternalError, "failed to encode event")
+	}
+	for _, message := range messages {
+		err := q.InsertOutboxMessage(ctx, sqlcgen.InsertOutboxMessageParams{
+			TenantID:      message.TenantID,
+			AggregateType: message.AggregateType,
+			AggregateID:   message.AggregateID,
+			EventName:     message.EventName,
+			Payload:       message.Payload,
+			OccurredAt:    message.OccurredAt,
+			Status:        string(message.Status),
+			NextAttemptAt: message.NextAttemptAt,
+			CreatedAt:     message.CreatedAt,
+		})
+		if err != nil {
+			return apperrors.WrapDatabaseError(err)
+		}
+	}
+	return nil
+}
+
+// toOutboxMessages converts database outbox rows to messages
+func toOutboxMessages(dbMessages []sqlcgen.Outbox) []*outbox.Message {
+	messages := make([]*outbox.Message, 0, len(dbMessages))
+	for _, dbMessage := range dbMessages {
+		messages = append(messages, &outbox.Message{
+			ID:            dbMessage.ID,
+			TenantID:      dbMessage.TenantID,
+			AggregateType: dbMessage.AggregateType,
+			AggregateID:   dbMessage.AggregateID,
+			EventName:     dbMessage.EventName,
+			Payload:       dbMessage.Payload,
+			OccurredAt:    dbMessage.OccurredAt,
+			Status:        outbox.Status(dbMessage.Status),
+			Attempts:      int(dbMessage.Attempts),
+			NextAttemptAt: dbMessage.NextAttemptAt,
+			LastError:     dbMessage.LastError,
+			CreatedAt:     dbMessage.CreatedAt,
+			PublishedAt:   dbMessage.PublishedAt.Time,
+		})
+	}
+	return messages
+}
@@ -8,12 +8,14 @@ import (
 
 	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
 	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/persistence/sqlcgen"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
 	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
 )
 
 // ProductRepositoryImpl implements the product.ProductRepository interface
 // It also satisfies both ProductCommandRepository and ProductQueryRepository
 type ProductRepositoryImpl struct {
+	db      *sql.DB
 	queries *sqlcgen.Queries
 }
 
@@ -21,6 +23,7 @@ type ProductRepositoryImpl struct {
 // Deprecated: Use NewProductCommandRepository and NewProductQueryRepository instead
 func NewProductRepository(db *sql.DB) product.ProductRepository {
 	return &ProductRepositoryImpl{
+		db:      db,
 		queries: sqlcgen.New(db),
 	}
 }
@@ -28,38 +31,47 @@ func NewProductRepository(db *sql.DB) product.ProductRepository {
 // NewProductCommandRepository creates a new instance for command operations
 func NewProductCommandRepository(db *sql.DB) product.ProductCommandRepository {
 	return &ProductRepositoryImpl{
+		db:      db,
 		queries: sqlcgen.New(db),
 	}
 }
 
 // NewProductQueryRepository creates a new instance for query operations
-func NewProductQueryRepository(db *sql.DB) product.ProductQueryRepository {
+// db may be a *sql.DB or a ReadRouter that sends reads to replicas
+func NewProductQueryRepository(db sqlcgen.DBTX) product.ProductQueryRepository {
 	return &ProductRepositoryImpl{
 		queries: sqlcgen.New(db),
 	}
 }
 
 // Create stores a new product in the database
+// The product's pending events are stored in the outbox in the same transaction
 func (r *ProductRepositoryImpl) Create(ctx context.Context, prod *product.Product) error {
 	params := sqlcgen.CreateProductParams{
 		ID:            prod.ID(),
+		TenantID:      tenant.ID(ctx),
 		Name:          prod.Name(),
 		PriceAmount:   strconv.FormatFloat(prod.Price().Amount(), 'f', -1, 64),
 		PriceCurrency: prod.Price().Currency(),
 		CreatedAt:     prod.CreatedAt(),
 		UpdatedAt:     prod.UpdatedAt(),
+		Version:       int32(prod.Version()),
 	}
 
-	err := r.queries.CreateProduct(ctx, params)
-	if err != nil {
-		return apperrors.WrapDatabaseError(err)
-	}
-	return nil
+	return runInTx(ctx, r.db, r.queries, func(q *sqlcgen.Queries) error {
+		if err := q.CreateProduct(ctx, params); err != nil {
+			return apperrors.WrapDatabaseError(err)
+		}
+		return appendOutbox(ctx, q, product.AggregateType, prod.PendingEvents())
+	})
 }
 
 // GetByID retrieves a product by its ID from the database
 func (r *ProductRepositoryImpl) GetByID(ctx context.Context, id string) (*product.Product, error) {
-	dbProduct, err := r.queries.GetProductByID(ctx, id)
+	dbProduct, err := r.queries.GetProductByID(ctx, sqlcgen.GetProductByIDParams{
+		TenantID: tenant.ID(ctx),
+		ID:       id,
+	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil // Product not found
@@ -70,26 +82,63 @@ func (r *ProductRepositoryImpl) GetByID(ctx context.Context, id string) (*produc
 	return r.toDomainProduct(dbProduct)
 }
 
+// GetByIDs retrieves the products with the given identifiers in a single query
+func (r *ProductRepositoryImpl) GetByIDs(ctx context.Context, ids []string) ([]*product.Product, error) {
+	if len(ids) == 0 {
+		return []*product.Product{}, nil
+	}
+
+	dbProducts, err := r.queries.ListProductsByIDs(ctx, sqlcgen.ListProductsByIDsParams{
+		TenantID: tenant.ID(ctx),
+		Ids:      ids,
+	})
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	products := make([]*product.Product, 0, len(dbProducts))
+	for _, dbProduct := range dbProducts {
+		prod, err := r.toDomainProduct(dbProduct)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, prod)
+	}
+	return products, nil
+}
+
 // Update updates an existing product in the database
+// The update only applies if the stored version still matches the entity's version,
+// and stores the product's pending events in the outbox in the same transaction
 func (r *ProductRepositoryImpl) Update(ctx context.Context, prod *product.Product) error {
 	params := sqlcgen.UpdateProductParams{
-		ID:            prod.ID(),
-		Name:          prod.Name(),
-		PriceAmount:   strconv.FormatFloat(prod.Price().Amount(), 'f', -1, 64),
-		PriceCurrency: prod.Price().Currency(),
-		UpdatedAt:     prod.UpdatedAt(),
+		TenantID:        tenant.ID(ctx),
+		ID:              prod.ID(),
+		Name:            prod.Name(),
+		PriceAmount:     strconv.FormatFloat(prod.Price().Amount(), 'f', -1, 64),
+		PriceCurrency:   prod.Price().Currency(),
+		UpdatedAt:       prod.UpdatedAt(),
+		ExpectedVersion: int32(prod.Version()),
 	}
 
-	err := r.queries.UpdateProduct(ctx, params)
-	if err != nil {
-		return apperrors.WrapDatabaseError(err)
-	}
-	return nil
+	return runInTx(ctx, r.db, r.queries, func(q *sqlcgen.Queries) error {
+		rows, err := q.UpdateProduct(ctx, params)
+		if err != nil {
+			return apperrors.WrapDatabaseError(err)
+		}
+		if rows == 0 {
+			return product.ErrConcurrentModification
+		}
+		return appendOutbox(ctx, q, product.AggregateType, prod.PendingEvents())
+	})
 }
 
 // Delete removes a product from the database
 func (r *ProductRepositoryImpl) Delete(ctx context.Context, id string) error {
-	err := r.queries.DeleteProduct(ctx, id)
+	err := r.queries.DeleteProduct(ctx, sqlcgen.DeleteProductParams{
+		TenantID: tenant.ID(ctx),
+		ID:       id,
+	})
 	if err != nil {
 		return apperrors.WrapDatabaseError(err)
 	}
@@ -99,8 +148,9 @@ func (r *ProductRepositoryImpl) Delete(ctx context.Context, id string) error {
 // List retrieves all products with pagination
 func (r *ProductRepositoryImpl) List(ctx context.Context, limit, offset int) ([]*product.Product, error) {
 	params := sqlcgen.ListProductsParams{
-		Limit:  int32(limit),
-		Offset: int32(offset),
+		TenantID: tenant.ID(ctx),
+		Limit:    int32(limit),
+		Offset:   int32(offset),
 	}
 
 	dbProducts, err := r.queries.ListProducts(ctx, params)
@@ -138,5 +188,6 @@ func (r *ProductRepositoryImpl) toDomainProduct(dbProduct sqlcgen.Product) (*pro
 		price,
 		dbProduct.CreatedAt,
 		dbProduct.UpdatedAt,
+		int(dbProduct.Version),
 	), nil
 }
@@ -0,0 +1,185 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/readconsistency"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// ReplicaLagQuery reports how many seconds a PostgreSQL standby is behind its primary
+// A standby that has replayed everything it received is treated as current even if
+// the primary has been idle, and a primary reports no lag at all
+const ReplicaLagQuery = `
+SELECT CASE
+    WHEN NOT pg_is_in_recovery() THEN 0
+    WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+    ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+END`
+
+// ReplicaOptions controls when a read replica is used
+type ReplicaOptions struct {
+	// MaxLag is how far behind the primary a replica may be and still serve reads
+	MaxLag time.Duration
+	// CheckInterval is how often replica health and lag are checked by Start
+	CheckInterval time.Duration
+	// LagQuery returns the replica lag in seconds; defaults to ReplicaLagQuery
+	LagQuery string
+}
+
+// replica is a read-only connection pool with its last known health
+type replica struct {
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// ReadRouter sends reads to healthy replicas and everything else to the primary
+// It satisfies sqlcgen.DBTX, so query repositories can be built on top of it.
+// Reads fall back to the primary when no replica is healthy, when the context
+// requires the primary (see readconsistency.WithPrimary) or when a replica
+// connection fails mid-request.
+type ReadRouter struct {
+	primary  *sql.DB
+	replicas []*replica
+	options  ReplicaOptions
+	next     atomic.Uint64
+}
+
+// NewReadRouter creates a router over the primary and its read replicas
+// Replicas start out healthy and are checked by CheckReplicas or Start
+func NewReadRouter(primary *sql.DB, replicas []*sql.DB, options ReplicaOptions) *ReadRouter {
+	if options.LagQuery == "" {
+		options.LagQuery = ReplicaLagQuery
+	}
+	router := &ReadRouter{primary: primary, options: options}
+	for _, db := range replicas {
+		r := &replica{db: db}
+		r.healthy.Store(true)
+		router.replicas = append(router.replicas, r)
+	}
+	return router
+}
+
+// ExecContext always runs on the primary
+func (r *ReadRouter) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return r.primary.ExecContext(ctx, query, args...)
+}
+
+// PrepareContext always prepares on the primary
+func (r *ReadRouter) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return r.primary.PrepareContext(ctx, query)
+}
+
+// QueryContext runs a read on a replica, falling back to the primary
+func (r *ReadRouter) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if rep := r.pick(ctx); rep != nil {
+		rows, err := rep.db.QueryContext(ctx, query, args...)
+		if err == nil || !r.failover(rep, err) {
+			return rows, err
+		}
+	}
+	return r.primary.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext runs a single-row read on a replica, falling back to the primary
+func (r *ReadRouter) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if rep := r.pick(ctx); rep != nil {
+		row := rep.db.QueryRowContext(ctx, query, args...)
+		if err := row.Err(); err == nil || !r.failover(rep, err) {
+			return row
+		}
+	}
+	return r.primary.QueryRowContext(ctx, query, args...)
+}
+
+// Start checks the replicas every CheckInterval until the context is cancelled
+func (r *ReadRouter) Start(ctx context.Context) {
+	if len(r.replicas) == 0 || r.options.CheckInterval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(r.options.CheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.CheckReplicas(ctx)
+			}
+		}
+	}()
+}
+
+// CheckReplicas marks each replica healthy if it answers and is within the allowed lag
+func (r *ReadRouter) CheckReplicas(ctx context.Context) {
+	for i, rep := range r.replicas {
+		var lagSeconds float64
+		err := rep.db.QueryRowContext(ctx, r.options.LagQuery).Scan(&lagSeconds)
+		lag := time.Duration(lagSeconds * float64(time.Second))
+
+		healthy := err == nil && (r.options.MaxLag <= 0 || lag <= r.options.MaxLag)
+		if wasHealthy := rep.healthy.Swap(healthy); wasHealthy != healthy {
+			switch {
+			case err != nil:
+				log.Printf("Read replica %d unavailable, reading from primary: %v", i, err)
+			case !healthy:
+				log.Printf("Read replica %d is %v behind (max %v), reading from primary", i, lag, r.options.MaxLag)
+			default:
+				log.Printf("Read replica %d is back in rotation", i)
+			}
+		}
+	}
+}
+
+// HealthyReplicas returns how many replicas currently serve reads
+func (r *ReadRouter) HealthyReplicas() int {
+	healthy := 0
+	for _, rep := range r.replicas {
+		if rep.healthy.Load() {
+			healthy++
+		}
+	}
+	return healthy
+}
+
+// Close closes the replica pools; the primary is owned by the caller
+func (r *ReadRouter) Close() error {
+	var errs []error
+	for _, rep := range r.replicas {
+		errs = append(errs, rep.db.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// pick returns the next healthy replica in round-robin order, or nil to use the primary
+func (r *ReadRouter) pick(ctx context.Context) *replica {
+	if len(r.replicas) == 0 || readconsistency.RequiresPrimary(ctx) {
+		return nil
+	}
+	start := r.next.Add(1)
+	for i := range r.replicas {
+		rep := r.replicas[(start+uint64(i))%uint64(len(r.replicas))]
+		if rep.healthy.Load() {
+			return rep
+		}
+	}
+	return nil
+}
+
+// failover takes a replica out of rotation if err means it cannot be reached
+// Returns true if the read should be retried on the primary
+func (r *ReadRouter) failover(rep *replica, err error) bool {
+	if !apperrors.IsConnectionError(err) {
+		return false
+	}
+	if rep.healthy.Swap(false) {
+		log.Printf("Read replica connection failed, reading from primary: %v", err)
+	}
+	return true
+}
@@ -0,0 +1,130 @@
+package persistence_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/persistence"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/sqlite"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/readconsistency"
+)
+
+// openNamedDatabase opens a throwaway database whose "whoami" query returns name
+func openNamedDatabase(t *testing.T, name string) *sql.DB {
+	t.Helper()
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), name+".db"))
+	if err != nil {
+		t.Fatalf("sqlite.Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec("CREATE TABLE whoami (name TEXT); INSERT INTO whoami VALUES (?)", name); err != nil {
+		t.Fatalf("creating whoami table: %v", err)
+	}
+	return db
+}
+
+// readFrom returns the name of the database that served the read
+func readFrom(t *testing.T, ctx context.Context, router *persistence.ReadRouter) string {
+	t.Helper()
+	var name string
+	if err := router.QueryRowContext(ctx, "SELECT name FROM whoami").Scan(&name); err != nil {
+		t.Fatalf("QueryRowContext() error = %v", err)
+	}
+	return name
+}
+
+func TestReadRouter_RoutesReadsToReplicas(t *testing.T) {
+	primary := openNamedDatabase(t, "primary")
+	router := persistence.NewReadRouter(primary, []*sql.DB{openNamedDatabase(t, "replica")}, persistence.ReplicaOptions{})
+
+	if got := readFrom(t, context.Background(), router); got != "replica" {
+		t.Errorf("read served by %q, want replica", got)
+	}
+
+	rows, err := router.QueryContext(context.Background(), "SELECT name FROM whoami")
+	if err != nil {
+		t.Fatalf("QueryContext() error = %v", err)
+	}
+	defer rows.Close()
+	var name string
+	if !rows.Next() || rows.Scan(&name) != nil || name != "replica" {
+		t.Errorf("QueryContext read served by %q, want replica", name)
+	}
+}
+
+func TestReadRouter_WritesAndPinnedReadsUsePrimary(t *testing.T) {
+	primary := openNamedDatabase(t, "primary")
+	router := persistence.NewReadRouter(primary, []*sql.DB{openNamedDatabase(t, "replica")}, persistence.ReplicaOptions{})
+
+	if _, err := router.ExecContext(context.Background(), "UPDATE whoami SET name = 'primary-written'"); err != nil {
+		t.Fatalf("ExecContext() error = %v", err)
+	}
+	if got := readFrom(t, readconsistency.WithPrimary(context.Background()), router); got != "primary-written" {
+		t.Errorf("pinned read served by %q, want primary-written", got)
+	}
+	if got := readFrom(t, context.Background(), router); got != "replica" {
+		t.Errorf("unpinned read served by %q, want replica", got)
+	}
+}
+
+func TestReadRouter_RoundRobinAcrossReplicas(t *testing.T) {
+	primary := openNamedDatabase(t, "primary")
+	router := persistence.NewReadRouter(primary, []*sql.DB{
+		openNamedDatabase(t, "replica-a"),
+		openNamedDatabase(t, "replica-b"),
+	}, persistence.ReplicaOptions{})
+
+	served := map[string]int{}
+	for i := 0; i < 4; i++ {
+		served[readFrom(t, context.Background(), router)]++
+	}
+	if served["replica-a"] != 2 || served["replica-b"] != 2 {
+		t.Errorf("reads served = %v, want two per replica", served)
+	}
+}
+
+func TestReadRouter_LaggingReplicaFallsBackToPrimary(t *testing.T) {
+	primary := openNamedDatabase(t, "primary")
+	replica := openNamedDatabase(t, "replica")
+	if _, err := replica.Exec("CREATE TABLE lag (seconds REAL); INSERT INTO lag VALUES (30)"); err != nil {
+		t.Fatalf("creating lag table: %v", err)
+	}
+	router := persistence.NewReadRouter(primary, []*sql.DB{replica}, persistence.ReplicaOptions{
+		MaxLag:   5 * time.Second,
+		LagQuery: "SELECT seconds FROM lag",
+	})
+
+	router.CheckReplicas(context.Background())
+	if got := router.HealthyReplicas(); got != 0 {
+		t.Fatalf("HealthyReplicas() = %d, want 0 while lagging", got)
+	}
+	if got := readFrom(t, context.Background(), router); got != "primary" {
+		t.Errorf("read served by %q, want primary", got)
+	}
+
+	if _, err := replica.Exec("UPDATE lag SET seconds = 0.5"); err != nil {
+		t.Fatalf("updating lag: %v", err)
+	}
+	router.CheckReplicas(context.Background())
+	if got := readFrom(t, context.Background(), router); got != "replica" {
+		t.Errorf("read served by %q after catching up, want replica", got)
+	}
+}
+
+func TestReadRouter_StatementErrorsAreNotRetriedOnPrimary(t *testing.T) {
+	primary := openNamedDatabase(t, "primary")
+	if _, err := primary.Exec("CREATE TABLE primary_only (id INTEGER)"); err != nil {
+		t.Fatalf("creating table: %v", err)
+	}
+	router := persistence.NewReadRouter(primary, []*sql.DB{openNamedDatabase(t, "replica")}, persistence.ReplicaOptions{})
+
+	if _, err := router.QueryContext(context.Background(), "SELECT id FROM primary_only"); err == nil {
+		t.Fatal("QueryContext() error = nil, want the replica's error")
+	}
+	if got := router.HealthyReplicas(); got != 1 {
+		t.Errorf("HealthyReplicas() = %d, want 1", got)
+	}
+}
@@ -0,0 +1,175 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/persistence/sqlcgen"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// StocktakeRepositoryImpl implements both StocktakeCommandRepository and StocktakeQueryRepository
+type StocktakeRepositoryImpl struct {
+	db      *sql.DB
+	queries *sqlcgen.Queries
+}
+
+// NewStocktakeCommandRepository creates a new instance for command operations
+func NewStocktakeCommandRepository(db *sql.DB) inventory.StocktakeCommandRepository {
+	return &StocktakeRepositoryImpl{
+		db:      db,
+		queries: sqlcgen.New(db),
+	}
+}
+
+// NewStocktakeQueryRepository creates a new instance for query operations
+// db may be a *sql.DB or a ReadRouter that sends reads to replicas
+func NewStocktakeQueryRepository(db sqlcgen.DBTX) inventory.StocktakeQueryRepository {
+	return &StocktakeRepositoryImpl{
+		queries: sqlcgen.New(db),
+	}
+}
+
+// Create stores a new stocktake session and its snapshot lines in one transaction
+func (r *StocktakeRepositoryImpl) Create(ctx context.Context, st *inventory.Stocktake) error {
+	return r.inTx(ctx, func(q *sqlcgen.Queries) error {
+		err := q.CreateStocktake(ctx, sqlcgen.CreateStocktakeParams{
+			ID:        st.ID(),
+			TenantID:  tenant.ID(ctx),
+			Status:    string(st.Status()),
+			CreatedAt: st.CreatedAt(),
+			UpdatedAt: st.UpdatedAt(),
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, line := range st.Lines() {
+			err := q.CreateStocktakeLine(ctx, sqlcgen.CreateStocktakeLineParams{
+				TenantID:         tenant.ID(ctx),
+				StocktakeID:      st.ID(),
+				ProductID:        line.ProductID(),
+				Location:         toNullString(line.Location()),
+				ExpectedQuantity: int32(line.ExpectedQuantity()),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Update persists counts, approvals and the status of a stocktake session
+func (r *StocktakeRepositoryImpl) Update(ctx context.Context, st *inventory.Stocktake) error {
+	return r.inTx(ctx, func(q *sqlcgen.Queries) error {
+		return r.save(ctx, q, st)
+	})
+}
+
+// Apply stores the applied session; its adjusted inventories are saved by the caller
+func (r *StocktakeRepositoryImpl) Apply(ctx context.Context, st *inventory.Stocktake) error {
+	return r.inTx(ctx, func(q *sqlcgen.Queries) error {
+		return r.save(ctx, q, st)
+	})
+}
+
+// GetByID retrieves a stocktake session with its lines
+func (r *StocktakeRepositoryImpl) GetByID(ctx context.Context, id string) (*inventory.Stocktake, error) {
+	dbStocktake, err := r.queries.GetStocktakeByID(ctx, sqlcgen.GetStocktakeByIDParams{
+		TenantID: tenant.ID(ctx),
+		ID:       id,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Stocktake not found
+		}
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	dbLines, err := r.queries.ListStocktakeLines(ctx, sqlcgen.ListStocktakeLinesParams{
+		TenantID:    tenant.ID(ctx),
+		StocktakeID: id,
+	})
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	lines := make([]*inventory.StocktakeLine, 0, len(dbLines))
+	for _, dbLine := range dbLines {
+		lines = append(lines, inventory.ReconstructStocktakeLine(
+			dbLine.ProductID,
+			fromNullString(dbLine.Location),
+			int(dbLine.ExpectedQuantity),
+			int(dbLine.CountedQuantity.Int32),
+			dbLine.CountedQuantity.Valid,
+			int(dbLine.CountAttempts),
+			dbLine.Approved,
+			dbLine.CountedAt.Time,
+		))
+	}
+
+	return inventory.ReconstructStocktake(
+		dbStocktake.ID,
+		inventory.StocktakeStatus(dbStocktake.Status),
+		lines,
+		dbStocktake.CreatedAt,
+		dbStocktake.UpdatedAt,
+		dbStocktake.AppliedAt.Time,
+	), nil
+}
+
+// save writes the session header and every line using the given queries
+// Only an open session is written, so one applied or cancelled concurrently fails with ErrStocktakeNotOpen.
+func (r *StocktakeRepositoryImpl) save(ctx context.Context, q *sqlcgen.Queries, st *inventory.Stocktake) error {
+	rows, err := q.UpdateStocktake(ctx, sqlcgen.UpdateStocktakeParams{
+		TenantID:       tenant.ID(ctx),
+		ID:             st.ID(),
+		Status:         string(st.Status()),
+		UpdatedAt:      st.UpdatedAt(),
+		AppliedAt:      toNullTime(st.AppliedAt()),
+		ExpectedStatus: string(inventory.StocktakeStatusOpen),
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return inventory.ErrStocktakeNotOpen
+	}
+
+	for _, line := range st.Lines() {
+		err := q.UpdateStocktakeLine(ctx, sqlcgen.UpdateStocktakeLineParams{
+			TenantID:    tenant.ID(ctx),
+			StocktakeID: st.ID(),
+			ProductID:   line.ProductID(),
+			CountedQuantity: sql.NullInt32{
+				Int32: int32(line.CountedQuantity()),
+				Valid: line.IsCounted(),
+			},
+			CountAttempts: int32(line.CountAttempts()),
+			Approved:      line.IsApproved(),
+			CountedAt:     toNullTime(line.CountedAt()),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inTx runs fn inside a database transaction, rolling back on error
+func (r *StocktakeRepositoryImpl) inTx(ctx context.Context, fn func(q *sqlcgen.Queries) error) error {
+	return runInTx(ctx, r.db, r.queries, fn)
+}
+
+// toNullTime converts a time to sql.NullTime, treating the zero time as NULL
+func toNullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{
+		Time:  t,
+		Valid: !t.IsZero(),
+	}
+}
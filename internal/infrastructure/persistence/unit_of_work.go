@@ -0,0 +1,208 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/persistence/sqlcgen"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+	"github.com/lib/pq"
+)
+
+// serializationRetryDelay is the base pause before retrying a failed transaction
+const serializationRetryDelay = 20 * time.Millisecond
+
+// UnitOfWorkImpl implements inventory.UnitOfWork on top of database/sql transactions
+type UnitOfWorkImpl struct {
+	db          *sql.DB
+	isolation   inventory.IsolationLevel
+	maxAttempts int
+	// eventSourced binds the inventory repositories to the inventory event streams
+	eventSourced  bool
+	snapshotEvery int
+}
+
+// NewUnitOfWork creates a new instance of UnitOfWorkImpl
+// maxAttempts bounds how often a transaction runs when it hits serialization failures
+func NewUnitOfWork(db *sql.DB, isolation inventory.IsolationLevel, maxAttempts int) inventory.UnitOfWork {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &UnitOfWorkImpl{
+		db:          db,
+		isolation:   isolation,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// NewEventSourcedUnitOfWork creates a UnitOfWorkImpl whose inventory repositories work on the
+// inventory event streams, snapshotting every snapshotEvery events
+func NewEventSourcedUnitOfWork(db *sql.DB, isolation inventory.IsolationLevel, maxAttempts, snapshotEvery int) inventory.UnitOfWork {
+	u := NewUnitOfWork(db, isolation, maxAttempts).(*UnitOfWorkImpl)
+	u.eventSourced = true
+	u.snapshotEvery = snapshotEvery
+	return u
+}
+
+// Do runs fn in a transaction with the default isolation level
+func (u *UnitOfWorkImpl) Do(ctx context.Context, fn func(ctx context.Context, repos inventory.TxRepositories) error) error {
+	return u.DoWithIsolation(ctx, u.isolation, fn)
+}
+
+// DoWithIsolation runs fn in a transaction with the given isolation level
+// Serialization failures and deadlocks roll back and run fn again with a growing pause
+func (u *UnitOfWorkImpl) DoWithIsolation(
+	ctx context.Context,
+	level inventory.IsolationLevel,
+	fn func(ctx context.Context, repos inventory.TxRepositories) error,
+) error {
+	return retrySerialization(ctx, u.maxAttempts, func() error { return u.run(ctx, level, fn) })
+}
+
+// retrySerialization runs fn up to maxAttempts times while it fails with a serialization failure or deadlock
+func retrySerialization(ctx context.Context, maxAttempts int, fn func() error) error {
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err = fn()
+		if err == nil || !isSerializationFailure(err) || attempt == maxAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt) * serializationRetryDelay):
+		}
+	}
+	return err
+}
+
+// run executes fn in a single transaction
+func (u *UnitOfWorkImpl) run(
+	ctx context.Context,
+	level inventory.IsolationLevel,
+	fn func(ctx context.Context, repos inventory.TxRepositories) error,
+) error {
+	tx, err := u.db.BeginTx(ctx, &sql.TxOptions{Isolation: toSQLIsolation(level)})
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.CodeTransactionFailed, "Failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	if err := fn(ctx, u.txRepositories(sqlcgen.New(tx))); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return apperrors.Wrap(err, apperrors.CodeTransactionFailed, "Failed to commit transaction")
+	}
+	return nil
+}
+
+// txRepositories binds every repository to the transaction's queries, with the
+// event-sourced inventory repository when the unit of work uses it
+func (u *UnitOfWorkImpl) txRepositories(q *sqlcgen.Queries) inventory.TxRepositories {
+	repos := newTxRepositories(q)
+	if u.eventSourced {
+		inventoryRepo := newEventSourcedInventoryRepository(nil, q, u.snapshotEvery)
+		repos.InventoryCommands = inventoryRepo
+		repos.InventoryQueries = inventoryRepo
+	}
+	return repos
+}
+
+// newTxRepositories binds every repository to the transaction's queries
+// The repositories have no database handle, so they never open nested transactions
+func newTxRepositories(q *sqlcgen.Queries) inventory.TxRepositories {
+	inventoryRepo := &InventoryRepositoryImpl{queries: q}
+	stocktakeRepo := &StocktakeRepositoryImpl{queries: q}
+	valuationRepo := &ValuationRepositoryImpl{queries: q}
+	return inventory.TxRepositories{
+		InventoryCommands: inventoryRepo,
+		InventoryQueries:  inventoryRepo,
+		StocktakeCommands: stocktakeRepo,
+		StocktakeQueries:  stocktakeRepo,
+		ValuationCommands: valuationRepo,
+		ValuationQueries:  valuationRepo,
+		Audit:             &AuditRepositoryImpl{queries: q},
+	}
+}
+
+// ProductUnitOfWorkImpl implements product.UnitOfWork on top of database/sql transactions
+type ProductUnitOfWorkImpl struct {
+	db          *sql.DB
+	maxAttempts int
+}
+
+// NewProductUnitOfWork creates a new instance of ProductUnitOfWorkImpl
+// maxAttempts bounds how often a transaction runs when it hits serialization failures
+func NewProductUnitOfWork(db *sql.DB, maxAttempts int) product.UnitOfWork {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &ProductUnitOfWorkImpl{db: db, maxAttempts: maxAttempts}
+}
+
+// Do runs fn in a transaction with the database's default isolation level
+// Serialization failures and deadlocks roll back and run fn again with a growing pause
+func (u *ProductUnitOfWorkImpl) Do(ctx context.Context, fn func(ctx context.Context, repos product.TxRepositories) error) error {
+	return retrySerialization(ctx, u.maxAttempts, func() error {
+		return runInTx(ctx, u.db, sqlcgen.New(u.db), func(q *sqlcgen.Queries) error {
+			return fn(ctx, product.TxRepositories{
+				ProductCommands: &ProductRepositoryImpl{queries: q},
+				Audit:           &AuditRepositoryImpl{queries: q},
+			})
+		})
+	})
+}
+
+// runInTx runs fn in its own transaction, or directly when the repository
+// is already bound to a unit of work's transaction
+func runInTx(ctx context.Context, db *sql.DB, queries *sqlcgen.Queries, fn func(q *sqlcgen.Queries) error) error {
+	if db == nil {
+		return apperrors.WrapDatabaseError(fn(queries))
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.CodeTransactionFailed, "Failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	if err := fn(queries.WithTx(tx)); err != nil {
+		return apperrors.WrapDatabaseError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return apperrors.Wrap(err, apperrors.CodeTransactionFailed, "Failed to commit transaction")
+	}
+	return nil
+}
+
+// toSQLIsolation maps the domain isolation level to database/sql
+func toSQLIsolation(level inventory.IsolationLevel) sql.IsolationLevel {
+	switch level {
+	case inventory.IsolationReadCommitted:
+		return sql.LevelReadCommitted
+	case inventory.IsolationRepeatableRead:
+		return sql.LevelRepeatableRead
+	case inventory.IsolationSerializable:
+		return sql.LevelSerializable
+	default:
+		return sql.LevelDefault
+	}
+}
+
+// isSerializationFailure reports whether PostgreSQL aborted the transaction
+// because of a serialization failure (40001) or a deadlock (40P01)
+func isSerializationFailure(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == "40001" || pqErr.Code == "40P01"
+}
@@ -0,0 +1,184 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/persistence/sqlcgen"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// ValuationRepositoryImpl implements both ValuationCommandRepository and ValuationQueryRepository
+type ValuationRepositoryImpl struct {
+	db      *sql.DB
+	queries *sqlcgen.Queries
+}
+
+// NewValuationCommandRepository creates a new instance for command operations
+func NewValuationCommandRepository(db *sql.DB) inventory.ValuationCommandRepository {
+	return &ValuationRepositoryImpl{
+		db:      db,
+		queries: sqlcgen.New(db),
+	}
+}
+
+// NewValuationQueryRepository creates a new instance for query operations
+// db may be a *sql.DB or a ReadRouter that sends reads to replicas
+func NewValuationQueryRepository(db sqlcgen.DBTX) inventory.ValuationQueryRepository {
+	return &ValuationRepositoryImpl{
+		queries: sqlcgen.New(db),
+	}
+}
+
+// Save upserts the valuation totals and every loaded cost layer in one transaction
+func (r *ValuationRepositoryImpl) Save(ctx context.Context, v *inventory.StockValuation) error {
+	return runInTx(ctx, r.db, r.queries, func(q *sqlcgen.Queries) error {
+		err := q.UpsertInventoryValuation(ctx, sqlcgen.UpsertInventoryValuationParams{
+			ProductID:     v.ProductID(),
+			TenantID:      tenant.ID(ctx),
+			CostingMethod: string(v.Method()),
+			Currency:      v.Currency(),
+			Quantity:      int32(v.Quantity()),
+			TotalCost:     formatCost(v.TotalCost()),
+			ConsumedCost:  formatCost(v.ConsumedCost()),
+			UpdatedAt:     v.UpdatedAt(),
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, layer := range v.Layers() {
+			err := q.UpsertCostLayer(ctx, sqlcgen.UpsertCostLayerParams{
+				ID:                layer.ID(),
+				TenantID:          tenant.ID(ctx),
+				ProductID:         v.ProductID(),
+				ReceivedQuantity:  int32(layer.ReceivedQuantity()),
+				RemainingQuantity: int32(layer.RemainingQuantity()),
+				UnitCost:          formatCost(layer.UnitCost()),
+				ReceivedAt:        layer.ReceivedAt(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete removes the valuation of a product; its cost layers cascade
+func (r *ValuationRepositoryImpl) Delete(ctx context.Context, productID string) error {
+	if err := r.queries.DeleteInventoryValuation(ctx, sqlcgen.DeleteInventoryValuationParams{
+		TenantID:  tenant.ID(ctx),
+		ProductID: productID,
+	}); err != nil {
+		return apperrors.WrapDatabaseError(err)
+	}
+	return nil
+}
+
+// GetByProductID retrieves a valuation with its open cost layers
+func (r *ValuationRepositoryImpl) GetByProductID(ctx context.Context, productID string) (*inventory.StockValuation, error) {
+	dbValuation, err := r.queries.GetInventoryValuation(ctx, sqlcgen.GetInventoryValuationParams{
+		TenantID:  tenant.ID(ctx),
+		ProductID: productID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Valuation not found
+		}
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	dbLayers, err := r.queries.ListOpenCostLayers(ctx, sqlcgen.ListOpenCostLayersParams{
+		TenantID:  tenant.ID(ctx),
+		ProductID: productID,
+	})
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	return r.toDomainValuation(dbValuation, dbLayers)
+}
+
+// List retrieves every valuation with its open cost layers
+func (r *ValuationRepositoryImpl) List(ctx context.Context) ([]*inventory.StockValuation, error) {
+	dbValuations, err := r.queries.ListInventoryValuations(ctx, tenant.ID(ctx))
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+
+	dbLayers, err := r.queries.ListAllOpenCostLayers(ctx, tenant.ID(ctx))
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	layersByProduct := make(map[string][]sqlcgen.InventoryCostLayer)
+	for _, dbLayer := range dbLayers {
+		layersByProduct[dbLayer.ProductID] = append(layersByProduct[dbLayer.ProductID], dbLayer)
+	}
+
+	valuations := make([]*inventory.StockValuation, 0, len(dbValuations))
+	for _, dbValuation := range dbValuations {
+		v, err := r.toDomainValuation(dbValuation, layersByProduct[dbValuation.ProductID])
+		if err != nil {
+			return nil, err
+		}
+		valuations = append(valuations, v)
+	}
+	return valuations, nil
+}
+
+// toDomainValuation converts database rows to a domain valuation
+func (r *ValuationRepositoryImpl) toDomainValuation(dbValuation sqlcgen.InventoryValuation, dbLayers []sqlcgen.InventoryCostLayer) (*inventory.StockValuation, error) {
+	totalCost, err := parseCost(dbValuation.TotalCost)
+	if err != nil {
+		return nil, err
+	}
+	consumedCost, err := parseCost(dbValuation.ConsumedCost)
+	if err != nil {
+		return nil, err
+	}
+
+	layers := make([]*inventory.CostLayer, 0, len(dbLayers))
+	for _, dbLayer := range dbLayers {
+		unitCost, err := parseCost(dbLayer.UnitCost)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, inventory.ReconstructCostLayer(
+			dbLayer.ID,
+			int(dbLayer.ReceivedQuantity),
+			int(dbLayer.RemainingQuantity),
+			unitCost,
+			dbLayer.ReceivedAt,
+		))
+	}
+
+	return inventory.ReconstructStockValuation(
+		dbValuation.ProductID,
+		inventory.CostingMethod(dbValuation.CostingMethod),
+		dbValuation.Currency,
+		int(dbValuation.Quantity),
+		totalCost,
+		consumedCost,
+		layers,
+		dbValuation.UpdatedAt,
+	), nil
+}
+
+// formatCost converts a cost to its DECIMAL representation
+func formatCost(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 4, 64)
+}
+
+// parseCost converts a DECIMAL column to a cost
+func parseCost(value string) (float64, error) {
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, apperrors.Wrap(err, apperrors.CodeDatabaseError, "failed to parse cost")
+	}
+	return amount, nil
+}
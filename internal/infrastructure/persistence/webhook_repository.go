@@ -0,0 +1,307 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/persistence/sqlcgen"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/webhook"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// WebhookRepositoryImpl implements the webhook.Store interface
+type WebhookRepositoryImpl struct {
+	queries *sqlcgen.Queries
+}
+
+// NewWebhookStore creates a new instance of WebhookRepositoryImpl
+func NewWebhookStore(db *sql.DB) webhook.Store {
+	return &WebhookRepositoryImpl{
+		queries: sqlcgen.New(db),
+	}
+}
+
+// CreateSubscription stores a new subscription for the tenant of the context
+func (r *WebhookRepositoryImpl) CreateSubscription(ctx context.Context, s *webhook.Subscription) error {
+	eventTypes, err := json.Marshal(s.EventTypes)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.CodeInternalError, "failed to encode event types")
+	}
+	err = r.queries.CreateWebhookSubscription(ctx, sqlcgen.CreateWebhookSubscriptionParams{
+		ID:                  s.ID,
+		TenantID:            tenant.ID(ctx),
+		Url:                 s.URL,
+		EventTypes:          eventTypes,
+		Secret:              s.Secret,
+		Active:              s.Active,
+		ConsecutiveFailures: int32(s.ConsecutiveFailures),
+		DisabledAt:          toNullTime(s.DisabledAt),
+		CreatedAt:           s.CreatedAt,
+		UpdatedAt:           s.UpdatedAt,
+	})
+	return apperrors.WrapDatabaseError(err)
+}
+
+// GetSubscription returns a subscription of the tenant of the context, or nil if there is none
+func (r *WebhookRepositoryImpl) GetSubscription(ctx context.Context, id string) (*webhook.Subscription, error) {
+	dbSubscription, err := r.queries.GetWebhookSubscription(ctx, sqlcgen.GetWebhookSubscriptionParams{
+		TenantID: tenant.ID(ctx),
+		ID:       id,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Subscription not found
+		}
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	return toWebhookSubscription(dbSubscription)
+}
+
+// ListSubscriptions returns the subscriptions of the tenant of the context, oldest first
+func (r *WebhookRepositoryImpl) ListSubscriptions(ctx context.Context) ([]*webhook.Subscription, error) {
+	dbSubscriptions, err := r.queries.ListWebhookSubscriptions(ctx, tenant.ID(ctx))
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	subscriptions := make([]*webhook.Subscription, 0, len(dbSubscriptions))
+	for _, dbSubscription := range dbSubscriptions {
+		s, err := toWebhookSubscription(dbSubscription)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, s)
+	}
+	return subscriptions, nil
+}
+
+// UpdateSubscription stores the changeable fields of a subscription
+func (r *WebhookRepositoryImpl) UpdateSubscription(ctx context.Context, s *webhook.Subscription) error {
+	eventTypes, err := json.Marshal(s.EventTypes)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.CodeInternalError, "failed to encode event types")
+	}
+	err = r.queries.UpdateWebhookSubscription(ctx, sqlcgen.UpdateWebhookSubscriptionParams{
+		TenantID:            tenant.ID(ctx),
+		ID:                  s.ID,
+		Url:                 s.URL,
+		EventTypes:          eventTypes,
+		Secret:              s.Secret,
+		Active:              s.Active,
+		ConsecutiveFailures: int32(s.ConsecutiveFailures),
+		DisabledAt:          toNullTime(s.DisabledAt),
+		UpdatedAt:           s.UpdatedAt,
+	})
+	return apperrors.WrapDatabaseError(err)
+}
+
+// DeleteSubscription removes a subscription with its deliveries
+func (r *WebhookRepositoryImpl) DeleteSubscription(ctx context.Context, id string) (bool, error) {
+	rows, err := r.queries.DeleteWebhookSubscription(ctx, sqlcgen.DeleteWebhookSubscriptionParams{
+		TenantID: tenant.ID(ctx),
+		ID:       id,
+	})
+	if err != nil {
+		return false, apperrors.WrapDatabaseError(err)
+	}
+	return rows > 0, nil
+}
+
+// Enqueue stores pending deliveries, skipping messages their subscription already has
+func (r *WebhookRepositoryImpl) Enqueue(ctx context.Context, deliveries []*webhook.Delivery) error {
+	for _, d := range deliveries {
+		err := r.queries.InsertWebhookDelivery(ctx, sqlcgen.InsertWebhookDeliveryParams{
+			TenantID:       tenant.ID(ctx),
+			SubscriptionID: d.SubscriptionID,
+			MessageID:      d.MessageID,
+			EventName:      d.EventName,
+			Payload:        d.Payload,
+			Status:         string(webhook.DeliveryPending),
+			NextAttemptAt:  d.NextAttemptAt.UTC(),
+			CreatedAt:      d.CreatedAt.UTC(),
+		})
+		if err != nil {
+			return apperrors.WrapDatabaseError(err)
+		}
+	}
+	return nil
+}
+
+// Claim leases due deliveries of active subscriptions, oldest first
+func (r *WebhookRepositoryImpl) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*webhook.Delivery, error) {
+	dbDeliveries, err := r.queries.ClaimWebhookDeliveries(ctx, sqlcgen.ClaimWebhookDeliveriesParams{
+		LockedUntil: sql.NullTime{Time: now.Add(lease).UTC(), Valid: true},
+		Now:         now.UTC(),
+		BatchSize:   int32(limit),
+	})
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	deliveries := toWebhookDeliveries(dbDeliveries)
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	return deliveries, nil
+}
+
+// MarkDelivered records the successful attempt of a claimed delivery
+func (r *WebhookRepositoryImpl) MarkDelivered(ctx context.Context, id int64, attempts int, attempt webhook.Attempt) error {
+	err := r.queries.MarkWebhookDeliveryDelivered(ctx, sqlcgen.MarkWebhookDeliveryDeliveredParams{
+		ID:             id,
+		Attempts:       int32(attempts),
+		LastStatusCode: int32(attempt.StatusCode),
+		LastAttemptAt:  sql.NullTime{Time: attempt.At.UTC(), Valid: true},
+	})
+	return apperrors.WrapDatabaseError(err)
+}
+
+// MarkFailed records a failed attempt of a claimed delivery
+func (r *WebhookRepositoryImpl) MarkFailed(ctx context.Context, id int64, attempts int, attempt webhook.Attempt, nextAttemptAt time.Time, failed bool) error {
+	status := webhook.DeliveryPending
+	if failed {
+		status = webhook.DeliveryFailed
+	}
+	err := r.queries.MarkWebhookDeliveryFailed(ctx, sqlcgen.MarkWebhookDeliveryFailedParams{
+		ID:             id,
+		Status:         string(status),
+		Attempts:       int32(attempts),
+		LastStatusCode: int32(attempt.StatusCode),
+		LastError:      attempt.Error,
+		LastAttemptAt:  sql.NullTime{Time: attempt.At.UTC(), Valid: true},
+		NextAttemptAt:  nextAttemptAt.UTC(),
+	})
+	return apperrors.WrapDatabaseError(err)
+}
+
+// RecordSuccess resets the consecutive failures of a subscription
+func (r *WebhookRepositoryImpl) RecordSuccess(ctx context.Context, subscriptionID string) error {
+	err := r.queries.ResetWebhookSubscriptionFailures(ctx, sqlcgen.ResetWebhookSubscriptionFailuresParams{
+		TenantID: tenant.ID(ctx),
+		ID:       subscriptionID,
+	})
+	return apperrors.WrapDatabaseError(err)
+}
+
+// RecordFailure counts a failed attempt and disables the subscription after disableAfter in a row
+func (r *WebhookRepositoryImpl) RecordFailure(ctx context.Context, subscriptionID string, disableAfter int, now time.Time) (bool, error) {
+	row, err := r.queries.IncrementWebhookSubscriptionFailures(ctx, sqlcgen.IncrementWebhookSubscriptionFailuresParams{
+		TenantID: tenant.ID(ctx),
+		ID:       subscriptionID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil // Subscription deleted meanwhile
+		}
+		return false, apperrors.WrapDatabaseError(err)
+	}
+	if !row.Active || disableAfter <= 0 || int(row.ConsecutiveFailures) < disableAfter {
+		return false, nil
+	}
+	// Only the failure that flips the subscription reports disabling it
+	rows, err := r.queries.DisableWebhookSubscription(ctx, sqlcgen.DisableWebhookSubscriptionParams{
+		TenantID:   tenant.ID(ctx),
+		ID:         subscriptionID,
+		DisabledAt: sql.NullTime{Time: now.UTC(), Valid: true},
+	})
+	if err != nil {
+		return false, apperrors.WrapDatabaseError(err)
+	}
+	return rows > 0, nil
+}
+
+// ListDeliveries returns the delivery log of a subscription, newest first
+func (r *WebhookRepositoryImpl) ListDeliveries(ctx context.Context, subscriptionID string, filter webhook.DeliveryFilter) ([]*webhook.Delivery, error) {
+	dbDeliveries, err := r.queries.ListWebhookDeliveries(ctx, sqlcgen.ListWebhookDeliveriesParams{
+		TenantID:       tenant.ID(ctx),
+		SubscriptionID: subscriptionID,
+		Status:         toNullString(string(filter.Status)),
+		RowOffset:      int32(filter.Offset),
+		RowLimit:       int32(filter.Limit),
+	})
+	if err != nil {
+		return nil, apperrors.WrapDatabaseError(err)
+	}
+	return toWebhookDeliveries(dbDeliveries), nil
+}
+
+// CountDeliveries returns how many deliveries of a subscription match the filter
+func (r *WebhookRepositoryImpl) CountDeliveries(ctx context.Context, subscriptionID string, filter webhook.DeliveryFilter) (int, error) {
+	count, err := r.queries.CountWebhookDeliveries(ctx, sqlcgen.CountWebhookDeliveriesParams{
+		TenantID:       tenant.ID(ctx),
+		SubscriptionID: subscriptionID,
+		Status:         toNullString(string(filter.Status)),
+	})
+	if err != nil {
+		return 0, apperrors.WrapDatabaseError(err)
+	}
+	return int(count), nil
+}
+
+// Redeliver makes a delivery of a subscription pending again
+func (r *WebhookRepositoryImpl) Redeliver(ctx context.Context, subscriptionID string, id int64, now time.Time) (bool, error) {
+	rows, err := r.queries.RedeliverWebhookDelivery(ctx, sqlcgen.RedeliverWebhookDeliveryParams{
+		TenantID:       tenant.ID(ctx),
+		SubscriptionID: subscriptionID,
+		ID:             id,
+		NextAttemptAt:  now.UTC(),
+	})
+	if err != nil {
+		return false, apperrors.WrapDatabaseError(err)
+	}
+	return rows > 0, nil
+}
+
+// DeleteDelivered removes deliveries delivered before the given time
+func (r *WebhookRepositoryImpl) DeleteDelivered(ctx context.Context, before time.Time) (int, error) {
+	rows, err := r.queries.DeleteDeliveredWebhookDeliveries(ctx, sql.NullTime{Time: before.UTC(), Valid: true})
+	if err != nil {
+		return 0, apperrors.WrapDatabaseError(err)
+	}
+	return int(rows), nil
+}
+
+// toWebhookSubscription converts a database subscription row to a subscription
+func toWebhookSubscription(dbSubscription sqlcgen.WebhookSubscription) (*webhook.Subscription, error) {
+	var eventTypes []string
+	if err := json.Unmarshal(dbSubscription.EventTypes, &eventTypes); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.CodeInternalError, "failed to decode event types")
+	}
+	return &webhook.Subscription{
+		ID:                  dbSubscription.ID,
+		TenantID:            dbSubscription.TenantID,
+		URL:                 dbSubscription.Url,
+		EventTypes:          eventTypes,
+		Secret:              dbSubscription.Secret,
+		Active:              dbSubscription.Active,
+		ConsecutiveFailures: int(dbSubscription.ConsecutiveFailures),
+		DisabledAt:          dbSubscription.DisabledAt.Time,
+		CreatedAt:           dbSubscription.CreatedAt,
+		UpdatedAt:           dbSubscription.UpdatedAt,
+	}, nil
+}
+
+// toWebhookDeliveries converts database delivery rows to deliveries
+func toWebhookDeliveries(dbDeliveries []sqlcgen.WebhookDelivery) []*webhook.Delivery {
+	deliveries := make([]*webhook.Delivery, 0, len(dbDeliveries))
+	for _, d := range dbDeliveries {
+		deliveries = append(deliveries, &webhook.Delivery{
+			ID:             d.ID,
+			TenantID:       d.TenantID,
+			SubscriptionID: d.SubscriptionID,
+			MessageID:      d.MessageID,
+			EventName:      d.EventName,
+			Payload:        d.Payload,
+			Status:         webhook.DeliveryStatus(d.Status),
+			Attempts:       int(d.Attempts),
+			NextAttemptAt:  d.NextAttemptAt,
+			LastStatusCode: int(d.LastStatusCode),
+			LastError:      d.LastError,
+			LastAttemptAt:  d.LastAttemptAt.Time,
+			DeliveredAt:    d.DeliveredAt.Time,
+			CreatedAt:      d.CreatedAt,
+		})
+	}
+	return deliveries
+}
@@ -0,0 +1,1654 @@
+// Package repotest holds the contract every product, inventory, catalog, audit, outbox and
+// webhook repository implementation must satisfy, so the SQL backends and the in-memory store are
+// exercised by the same behavioral tests.
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/outbox"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/webhook"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// Repositories is the set of repositories a contract run exercises
+type Repositories struct {
+	ProductCommands   product.ProductCommandRepository
+	ProductQueries    product.ProductQueryRepository
+	InventoryCommands inventory.InventoryCommandRepository
+	InventoryQueries  inventory.InventoryQueryRepository
+	Catalog           product.CatalogQueryRepository
+	CatalogProjection product.CatalogProjection
+	Audit             audit.Store
+	Outbox            outbox.Store
+	Webhooks          webhook.Store
+	ProductUnitOfWork product.UnitOfWork
+	UnitOfWork        inventory.UnitOfWork
+}
+
+// Factory returns repositories backed by an empty store
+type Factory func(t *testing.T) Repositories
+
+// Run checks the product, inventory, catalog, audit, outbox and webhook repository contract against fresh stores from newRepos
+func Run(t *testing.T, newRepos Factory) {
+	t.Run("Product", func(t *testing.T) {
+		for name, test := range productContract {
+			t.Run(name, func(t *testing.T) { test(t, newRepos(t)) })
+		}
+	})
+	t.Run("Inventory", func(t *testing.T) {
+		for name, test := range inventoryContract {
+			t.Run(name, func(t *testing.T) { test(t, newRepos(t)) })
+		}
+	})
+	t.Run("Catalog", func(t *testing.T) {
+		for name, test := range catalogContract {
+			t.Run(name, func(t *testing.T) { test(t, newRepos(t)) })
+		}
+	})
+	t.Run("Tenant", func(t *testing.T) {
+		for name, test := range tenantContract {
+			t.Run(name, func(t *testing.T) { test(t, newRepos(t)) })
+		}
+	})
+	t.Run("Audit", func(t *testing.T) {
+		for name, test := range auditContract {
+			t.Run(name, func(t *testing.T) { test(t, newRepos(t)) })
+		}
+	})
+	t.Run("Outbox", func(t *testing.T) {
+		for name, test := range outboxContract {
+			t.Run(name, func(t *testing.T) { test(t, newRepos(t)) })
+		}
+	})
+	t.Run("Webhook", func(t *testing.T) {
+		for name, test := range webhookContract {
+			t.Run(name, func(t *testing.T) { test(t, newRepos(t)) })
+		}
+	})
+}
+
+// SnapshotEvery is the snapshot interval RunEventSourced expects the inventory repositories to use
+const SnapshotEvery = 3
+
+// RunEventSourced checks the inventory, catalog and tenant contract, and the event-sourcing cases,
+// against fresh stores whose inventory repositories work on inventory event streams
+func RunEventSourced(t *testing.T, newRepos Factory) {
+	t.Run("Inventory", func(t *testing.T) {
+		for name, test := range inventoryContract {
+			t.Run(name, func(t *testing.T) { test(t, newRepos(t)) })
+		}
+	})
+	t.Run("Catalog", func(t *testing.T) {
+		for name, test := range catalogContract {
+			t.Run(name, func(t *testing.T) { test(t, newRepos(t)) })
+		}
+	})
+	t.Run("Tenant", func(t *testing.T) {
+		for name, test := range tenantContract {
+			t.Run(name, func(t *testing.T) { test(t, newRepos(t)) })
+		}
+	})
+	t.Run("EventSourcing", func(t *testing.T) {
+		for name, test := range eventSourcingContract {
+			t.Run(name, func(t *testing.T) { test(t, newRepos(t)) })
+		}
+	})
+}
+
+// baseTime keeps timestamps deterministic and free of sub-microsecond precision
+var baseTime = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+var productContract = map[string]func(t *testing.T, r Repositories){
+	"CreateAndGetByID": func(t *testing.T, r Repositories) {
+		ctx := context.Background()
+		createProduct(t, r, "prod-1", 0)
+
+		got, err := r.ProductQueries.GetByID(ctx, "prod-1")
+		if err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+		if got == nil {
+			t.Fatal("GetByID() = nil, want product")
+		}
+		if got.Name() != "Product prod-1" || got.Price().Amount() != 10.5 || got.Price().Currency() != "USD" || got.Version() != 1 {
+			t.Errorf("GetByID() = %s %v %s v%d, want Product prod-1 10.5 USD v1",
+				got.Name(), got.Price().Amount(), got.Price().Currency(), got.Version())
+		}
+	},
+	"GetByIDMissingReturnsNil": func(t *testing.T, r Repositories) {
+		got, err := r.ProductQueries.GetByID(context.Background(), "missing")
+		if err != nil || got != nil {
+			t.Errorf("GetByID() = %v, %v; want nil, nil", got, err)
+		}
+	},
+	"CreateRejectsDuplicateID": func(t *testing.T, r Repositories) {
+		createProduct(t, r, "prod-1", 0)
+
+		err := r.ProductCommands.Create(context.Background(), newProduct(t, "prod-1", 0))
+		if !errors.Is(err, errors.CodeProductAlreadyExists) {
+			t.Errorf("Create() error code = %s, want %s", errors.GetCode(err), errors.CodeProductAlreadyExists)
+		}
+	},
+	"GetByIDsSkipsMissing": func(t *testing.T, r Repositories) {
+		createProduct(t, r, "prod-1", 0)
+		createProduct(t, r, "prod-2", 1)
+
+		got, err := r.ProductQueries.GetByIDs(context.Background(), []string{"prod-2", "missing", "prod-1"})
+		if err != nil {
+			t.Fatalf("GetByIDs() error = %v", err)
+		}
+		if len(got) != 2 {
+			t.Errorf("GetByIDs() returned %d products, want 2", len(got))
+		}
+	},
+	"UpdateChecksVersion": func(t *testing.T, r Repositories) {
+		ctx := context.Background()
+		createProduct(t, r, "prod-1", 0)
+		first, _ := r.ProductQueries.GetByID(ctx, "prod-1")
+		stale, _ := r.ProductQueries.GetByID(ctx, "prod-1")
+
+		if err := first.UpdateName("Renamed"); err != nil {
+			t.Fatalf("UpdateName() error = %v", err)
+		}
+		if err := r.ProductCommands.Update(ctx, first); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if err := r.ProductCommands.Update(ctx, stale); err != product.ErrConcurrentModification {
+			t.Errorf("Update() with stale version error = %v, want %v", err, product.ErrConcurrentModification)
+		}
+
+		got, _ := r.ProductQueries.GetByID(ctx, "prod-1")
+		if got.Name() != "Renamed" || got.Version() != 2 {
+			t.Errorf("GetByID() = %s v%d, want Renamed v2", got.Name(), got.Version())
+		}
+	},
+	"Delete": func(t *testing.T, r Repositories) {
+		ctx := context.Background()
+		createProduct(t, r, "prod-1", 0)
+
+		if err := r.ProductCommands.Delete(ctx, "prod-1"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if got, _ := r.ProductQueries.GetByID(ctx, "prod-1"); got != nil {
+			t.Errorf("GetByID() after Delete = %v, want nil", got)
+		}
+	},
+	"ListNewestFirstWithPagination": func(t *testing.T, r Repositories) {
+		for i := 0; i < 3; i++ {
+			createProduct(t, r, fmt.Sprintf("prod-%d", i), i)
+		}
+
+		got, err := r.ProductQueries.List(context.Background(), 2, 1)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if ids := productIDs(got); fmt.Sprint(ids) != "[prod-1 prod-0]" {
+			t.Errorf("List() = %v, want [prod-1 prod-0]", ids)
+		}
+	},
+}
+
+var inventoryContract = map[string]func(t *testing.T, r Repositories){
+	"CreateRequiresProduct": func(t *testing.T, r Repositories) {
+		err := r.InventoryCommands.Create(context.Background(), newInventory(t, "prod-1", 5))
+		if !errors.Is(err, errors.CodeProductNotFound) {
+			t.Errorf("Create() error code = %s, want %s", errors.GetCode(err), errors.CodeProductNotFound)
+		}
+	},
+	"CreateRejectsSecondRecordForProduct": func(t *testing.T, r Repositories) {
+		createProduct(t, r, "prod-1", 0)
+		createInventory(t, r, newInventory(t, "prod-1", 5))
+
+		dup, err := inventory.NewInventory("inv-other", "prod-1", 5, "WH-1")
+		if err != nil {
+			t.Fatalf("NewInventory() error = %v", err)
+		}
+		err = r.InventoryCommands.Create(context.Background(), dup)
+		if !errors.Is(err, errors.CodeInventoryExists) {
+			t.Errorf("Create() error code = %s, want %s", errors.GetCode(err), errors.CodeInventoryExists)
+		}
+	},
+	"CreateAndGetByProductID": func(t *testing.T, r Repositories) {
+		ctx := context.Background()
+		createProduct(t, r, "prod-1", 0)
+		policy, err := inventory.NewStockPolicy(inventory.StockPolicyBackorder, 10)
+		if err != nil {
+			t.Fatalf("NewStockPolicy() error = %v", err)
+		}
+		createInventory(t, r, inventory.ReconstructInventory(
+			"inv-prod-1", "prod-1", 8, 3, 2, policy, "WH-2",
+			map[string]string{"aisle": "7"}, baseTime, baseTime, 1,
+		))
+
+		got, err := r.InventoryQueries.GetByProductID(ctx, "prod-1")
+		if err != nil {
+			t.Fatalf("GetByProductID() error = %v", err)
+		}
+		if got == nil {
+			t.Fatal("GetByProductID() = nil, want inventory")
+		}
+		if got.ID() != "inv-prod-1" || got.Quantity() != 8 || got.ReservedQuantity() != 3 || got.BackorderedQuantity() != 2 {
+			t.Errorf("GetByProductID() quantities = %d/%d/%d, want 8/3/2", got.Quantity(), got.ReservedQuantity(), got.BackorderedQuantity())
+		}
+		if got.StockPolicy() != policy || got.Location() != "WH-2" || got.Metadata()["aisle"] != "7" {
+			t.Errorf("GetByProductID() = %v %q %v, want backorder policy, WH-2 and aisle 7", got.StockPolicy(), got.Location(), got.Metadata())
+		}
+
+		if missing, err := r.InventoryQueries.GetByProductID(ctx, "missing"); err != nil || missing != nil {
+			t.Errorf("GetByProductID() for missing product = %v, %v; want nil, nil", missing, err)
+		}
+	},
+	"GetByProductIDsSkipsMissing": func(t *testing.T, r Repositories) {
+		ctx := context.Background()
+		createProduct(t, r, "prod-1", 0)
+		createProduct(t, r, "prod-2", 1)
+		createProduct(t, r, "prod-3", 2)
+		createInventory(t, r, newInventory(t, "prod-1", 5))
+		createInventory(t, r, newInventory(t, "prod-2", 7))
+
+		got, err := r.InventoryQueries.GetByProductIDs(ctx, []string{"prod-2", "prod-3", "missing", "prod-1"})
+		if err != nil {
+			t.Fatalf("GetByProductIDs() error = %v", err)
+		}
+		if len(got) != 2 {
+			t.Errorf("GetByProductIDs() returned %d inventories, want 2", len(got))
+		}
+
+		if empty, err := r.InventoryQueries.GetByProductIDs(ctx, nil); err != nil || len(empty) != 0 {
+			t.Errorf("GetByProductIDs(nil) = %v, %v; want no inventories", empty, err)
+		}
+	},
+	"UpdateChecksVersion": func(t *testing.T, r Repositories) {
+		ctx := context.Background()
+		createProduct(t, r, "prod-1", 0)
+		createInventory(t, r, newInventory(t, "prod-1", 5))
+		first, _ := r.InventoryQueries.GetByProductID(ctx, "prod-1")
+		stale, _ := r.InventoryQueries.GetByProductID(ctx, "prod-1")
+
+		if err := first.AdjustQuantity(2); err != nil {
+			t.Fatalf("AdjustQuantity() error = %v", err)
+		}
+		if err := r.InventoryCommands.Update(ctx, first); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if err := r.InventoryCommands.Update(ctx, stale); err != inventory.ErrConcurrentModification {
+			t.Errorf("Update() with stale version error = %v, want %v", err, inventory.ErrConcurrentModification)
+		}
+
+		got, _ := r.InventoryQueries.GetByProductID(ctx, "prod-1")
+		if got.Quantity() != 7 || got.Version() != first.Version()+1 {
+			t.Errorf("GetByProductID() = %d v%d, want 7 v%d", got.Quantity(), got.Version(), first.Version()+1)
+		}
+	},
+	"DeleteChecksVersionAndReservedStock": func(t *testing.T, r Repositories) {
+		ctx := context.Background()
+		createProduct(t, r, "prod-1", 0)
+		createInventory(t, r, newInventory(t, "prod-1", 5))
+		stale, _ := r.InventoryQueries.GetByProductID(ctx, "prod-1")
+
+		// A reservation made after the inventory was loaded fails its delete
+		reserved, _ := r.InventoryQueries.GetByProductID(ctx, "prod-1")
+		if err := reserved.Reserve(2); err != nil {
+			t.Fatalf("Reserve() error = %v", err)
+		}
+		if err := r.InventoryCommands.Update(ctx, reserved); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if err := r.InventoryCommands.Delete(ctx, stale); err != inventory.ErrConcurrentModification {
+			t.Errorf("Delete() with stale version error = %v, want %v", err, inventory.ErrConcurrentModification)
+		}
+
+		// So does reserved stock at the loaded version
+		current, _ := r.InventoryQueries.GetByProductID(ctx, "prod-1")
+		if err := r.InventoryCommands.Delete(ctx, current); err != inventory.ErrConcurrentModification {
+			t.Errorf("Delete() with reserved stock error = %v, want %v", err, inventory.ErrConcurrentModification)
+		}
+		if got, _ := r.InventoryQueries.GetByProductID(ctx, "prod-1"); got == nil || got.ReservedQuantity() != 2 {
+			t.Fatalf("GetByProductID() after refused deletes = %v, want 2 reserved", got)
+		}
+	},
+	"UpdateBatchIsAllOrNothing": func(t *testing.T, r Repositories) {
+		ctx := context.Background()
+		for i, id := range []string{"prod-1", "prod-2"} {
+			createProduct(t, r, id, i)
+			createInventory(t, r, newInventory(t, id, 5))
+		}
+		first, _ := r.InventoryQueries.GetByProductID(ctx, "prod-1")
+		second, _ := r.InventoryQueries.GetByProductID(ctx, "prod-2")
+		_ = first.AdjustQuantity(1)
+		_ = second.AdjustQuantity(1)
+		if err := r.InventoryCommands.AdjustStock(ctx, "prod-2", 1); err != nil {
+			t.Fatalf("AdjustStock() error = %v", err)
+		}
+
+		err := r.InventoryCommands.UpdateBatch(ctx, []*inventory.Inventory{first, second})
+		if err != inventory.ErrConcurrentModification {
+			t.Fatalf("UpdateBatch() error = %v, want %v", err, inventory.ErrConcurrentModification)
+		}
+		if got, _ := r.InventoryQueries.GetByProductID(ctx, "prod-1"); got.Quantity() != 5 {
+			t.Errorf("Quantity() after rolled back batch = %d, want 5", got.Quantity())
+		}
+	},
+	"AdjustStockAllocatesBackorders": func(t *testing.T, r Repositories) {
+		ctx := context.Background()
+		createProduct(t, r, "prod-1", 0)
+		policy, _ := inventory.NewStockPolicy(inventory.StockPolicyBackorder, 10)
+		createInventory(t, r, inventory.ReconstructInventory(
+			"inv-prod-1", "prod-1", 0, 0, 3, policy, "WH-1", nil, baseTime, baseTime, 1,
+		))
+
+		if err := r.InventoryCommands.AdjustStock(ctx, "prod-1", 5); err != nil {
+			t.Fatalf("AdjustStock() error = %v", err)
+		}
+
+		got, _ := r.InventoryQueries.GetByProductID(ctx, "prod-1")
+		if got.Quantity() != 5 || got.ReservedQuantity() != 3 || got.BackorderedQuantity() != 0 || got.Version() != 2 {
+			t.Errorf("after AdjustStock() quantities = %d/%d/%d v%d, want 5/3/0 v2",
+				got.Quantity(), got.ReservedQuantity(), got.BackorderedQuantity(), got.Version())
+		}
+	},
+	"AdjustStockEnforcesReservedLimit": func(t *testing.T, r Repositories) {
+		ctx := context.Background()
+		createProduct(t, r, "prod-1", 0)
+		inv := newInventory(t, "prod-1", 5)
+		if err := inv.Reserve(4); err != nil {
+			t.Fatalf("Reserve() error = %v", err)
+		}
+		createInventory(t, r, inv)
+
+		if err := r.InventoryCommands.AdjustStock(ctx, "prod-1", -2); err == nil {
+			t.Fatal("AdjustStock() below the reserved quantity succeeded, want error")
+		}
+		if got, _ := r.InventoryQueries.GetByProductID(ctx, "prod-1"); got.Quantity() != 5 {
+			t.Errorf("Quantity() after rejected adjustment = %d, want 5", got.Quantity())
+		}
+	},
+	"ListFiltersSortsAndPaginates": func(t *testing.T, r Repositories) {
+		ctx := context.Background()
+		for i, quantity := range []int{7, 0, 3, 9} {
+			id := fmt.Sprintf("prod-%d", i)
+			createProduct(t, r, id, i)
+			createInventory(t, r, newInventory(t, id, quantity))
+		}
+
+		below := 8
+		filter := inventory.InventoryFilter{
+			AvailableBelow: &below,
+			SortBy:         inventory.SortByQuantity,
+			SortDesc:       true,
+			Limit:          2,
+		}
+		got, err := r.InventoryQueries.List(ctx, filter)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if ids := inventoryProductIDs(got); fmt.Sprint(ids) != "[prod-0 prod-2]" {
+			t.Errorf("List() = %v, want [prod-0 prod-2]", ids)
+		}
+
+		count, err := r.InventoryQueries.Count(ctx, filter)
+		if err != nil {
+			t.Fatalf("Count() error = %v", err)
+		}
+		if count != 3 {
+			t.Errorf("Count() = %d, want 3", count)
+		}
+
+		zero, err := r.InventoryQueries.List(ctx, inventory.InventoryFilter{ZeroStockOnly: true, Limit: 10})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if ids := inventoryProductIDs(zero); fmt.Sprint(ids) != "[prod-1]" {
+			t.Errorf("List(zero stock) = %v, want [prod-1]", ids)
+		}
+	},
+	"ListByLocation": func(t *testing.T, r Repositories) {
+		ctx := context.Background()
+		createProduct(t, r, "prod-1", 0)
+		createProduct(t, r, "prod-2", 1)
+		createInventory(t, r, newInventory(t, "prod-1", 1))
+		other, _ := inventory.NewInventory("inv-prod-2", "prod-2", 1, "WH-9")
+		createInventory(t, r, other)
+
+		got, err := r.InventoryQueries.ListByLocation(ctx, "WH-9")
+		if err != nil {
+			t.Fatalf("ListByLocation() error = %v", err)
+		}
+		if ids := inventoryProductIDs(got); fmt.Sprint(ids) != "[prod-2]" {
+			t.Errorf("ListByLocation() = %v, want [prod-2]", ids)
+		}
+	},
+	"DeletingProductCascades": func(t *testing.T, r Repositories) {
+		ctx := context.Background()
+		createProduct(t, r, "prod-1", 0)
+		createInventory(t, r, newInventory(t, "prod-1", 5))
+
+		if err := r.ProductCommands.Delete(ctx, "prod-1"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if got, err := r.InventoryQueries.GetByProductID(ctx, "prod-1"); err != nil || got != nil {
+			t.Errorf("GetByProductID() after product delete = %v, %v; want nil, nil", got, err)
+		}
+	},
+}
+
+// newProduct builds a product created offset hours after baseTime
+var catalogContract = map[string]func(t *testing.T, r Repositories){
+	"TracksProductAndInventoryChanges": func(t *testing.T, r Repositories) {
+		ctx := context.Background()
+		createProduct(t, r, "prod-1", 0)
+		if entry := assertCatalogEntry(t, r, "prod-1", false, 0, 0); entry.Name != "Product prod-1" || entry.PriceAmount != 10.5 {
+			t.Errorf("entry = %s %v, want Product prod-1 10.5", entry.Name, entry.PriceAmount)
+		}
+
+		inv := newInventory(t, "prod-1", 10)
+		createInventory(t, r, inv)
+		if err := r.InventoryCommands.AdjustStock(ctx, "prod-1", 5); err != nil {
+			t.Fatalf("AdjustStock() error = %v", err)
+		}
+		assertCatalogEntry(t, r, "prod-1", true, 15, 15)
+
+		stored, err := r.InventoryQueries.GetByProductID(ctx, "prod-1")
+		if err != nil || stored == nil {
+			t.Fatalf("GetByProductID() = %v, %v", stored, err)
+		}
+		if err := stored.Reserve(4); err != nil {
+			t.Fatalf("Reserve() error = %v", err)
+		}
+		if err := r.InventoryCommands.Update(ctx, stored); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		assertCatalogEntry(t, r, "prod-1", true, 15, 11)
+
+		prod, err := r.ProductQueries.GetByID(ctx, "prod-1")
+		if err != nil || prod == nil {
+			t.Fatalf("GetByID() = %v, %v", prod, err)
+		}
+		if err := prod.UpdateName("Renamed"); err != nil {
+			t.Fatalf("UpdateName() error = %v", err)
+		}
+		if err := r.ProductCommands.Update(ctx, prod); err != nil {
+			t.Fatalf("Update(product) error = %v", err)
+		}
+		entry := assertCatalogEntry(t, r, "prod-1", true, 15, 11)
+		if entry.Name != "Renamed" || entry.Location != "WH-1" {
+			t.Errorf("entry = %s at %s, want Renamed at WH-1", entry.Name, entry.Location)
+		}
+
+		// Only an inventory without reserved stock can be deleted
+		stored, _ = r.InventoryQueries.GetByProductID(ctx, "prod-1")
+		if err := stored.Release(4); err != nil {
+			t.Fatalf("Release() error = %v", err)
+		}
+		if err := r.InventoryCommands.Update(ctx, stored); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		stored, _ = r.InventoryQueries.GetByProductID(ctx, "prod-1")
+		if err := r.InventoryCommands.Delete(ctx, stored); err != nil {
+			t.Fatalf("Delete(inventory) error = %v", err)
+		}
+		assertCatalogEntry(t, r, "prod-1", false, 0, 0)
+	},
+	"RemovedWithProduct": func(t *testing.T, r Repositories) {
+		ctx := context.Background()
+		createProduct(t, r, "prod-1", 0)
+		createInventory(t, r, newInventory(t, "prod-1", 10))
+
+		if err := r.ProductCommands.Delete(ctx, "prod-1"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		got, err := r.Catalog.GetByProductID(ctx, "prod-1")
+		if err != nil || got != nil {
+			t.Errorf("GetByProductID() = %v, %v; want nil, nil", got, err)
+		}
+	},
+	"RebuildRegeneratesEveryEntry": func(t *testing.T, r Repositories) {
+		createProduct(t, r, "prod-1", 0)
+		createProduct(t, r, "prod-2", 1)
+		createInventory(t, r, newInventory(t, "prod-2", 7))
+
+		entries, err := r.CatalogProjection.Rebuild(context.Background())
+		if err != nil {
+			t.Fatalf("Rebuild() error = %v", err)
+		}
+		if entries != 2 {
+			t.Errorf("Rebuild() = %d entries, want 2", entries)
+		}
+		assertCatalogEntry(t, r, "prod-1", false, 0, 0)
+		assertCatalogEntry(t, r, "prod-2", true, 7, 7)
+	},
+}
+
+var tenantContract = map[string]func(t *testing.T, r Repositories){
+	"ProductsAreInvisibleToOtherTenants": func(t *testing.T, r Repositories) {
+		acme, globex := forTenant("acme"), forTenant("globex")
+		if err := r.ProductCommands.Create(acme, newProduct(t, "prod-1", 0)); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+
+		for name, ctx := range map[string]context.Context{"globex": globex, "default": context.Background()} {
+			if got, err := r.ProductQueries.GetByID(ctx, "prod-1"); err != nil || got != nil {
+				t.Errorf("GetByID() for %s = %v, %v; want nil, nil", name, got, err)
+			}
+			if got, err := r.ProductQueries.GetByIDs(ctx, []string{"prod-1"}); err != nil || len(got) != 0 {
+				t.Errorf("GetByIDs() for %s = %v, %v; want none", name, productIDs(got), err)
+			}
+			if got, err := r.ProductQueries.List(ctx, 10, 0); err != nil || len(got) != 0 {
+				t.Errorf("List() for %s = %v, %v; want none", name, productIDs(got), err)
+			}
+		}
+
+		// Deletes from another tenant leave the product alone
+		if err := r.ProductCommands.Delete(globex, "prod-1"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if got, err := r.ProductQueries.GetByID(acme, "prod-1"); err != nil || got == nil {
+			t.Errorf("GetByID() for acme = %v, %v; want product", got, err)
+		}
+	},
+	"InventoryIsInvisibleToOtherTenants": func(t *testing.T, r Repositories) {
+		acme, globex := forTenant("acme"), forTenant("globex")
+		if err := r.ProductCommands.Create(acme, newProduct(t, "prod-1", 0)); err != nil {
+			t.Fatalf("Create(product) error = %v", err)
+		}
+		if err := r.InventoryCommands.Create(acme, newInventory(t, "prod-1", 5)); err != nil {
+			t.Fatalf("Create(inventory) error = %v", err)
+		}
+
+		if got, err := r.InventoryQueries.GetByProductID(globex, "prod-1"); err != nil || got != nil {
+			t.Errorf("GetByProductID() for globex = %v, %v; want nil, nil", got, err)
+		}
+		if got, err := r.InventoryQueries.GetByProductIDs(globex, []string{"prod-1"}); err != nil || len(got) != 0 {
+			t.Errorf("GetByProductIDs() for globex = %v, %v; want none", inventoryProductIDs(got), err)
+		}
+		if count, err := r.InventoryQueries.Count(globex, inventory.InventoryFilter{}); err != nil || count != 0 {
+			t.Errorf("Count() for globex = %d, %v; want 0", count, err)
+		}
+		if err := r.InventoryCommands.AdjustStock(globex, "prod-1", 10); err != nil {
+			t.Fatalf("AdjustStock() error = %v", err)
+		}
+
+		got, err := r.InventoryQueries.GetByProductID(acme, "prod-1")
+		if err != nil || got == nil {
+			t.Fatalf("GetByProductID() for acme = %v, %v; want inventory", got, err)
+		}
+		if got.Quantity() != 5 {
+			t.Errorf("Quantity() = %d, want 5 untouched by another tenant", got.Quantity())
+		}
+	},
+	"InventoryRejectsProductOfOtherTenant": func(t *testing.T, r Repositories) {
+		if err := r.ProductCommands.Create(forTenant("acme"), newProduct(t, "prod-1", 0)); err != nil {
+			t.Fatalf("Create(product) error = %v", err)
+		}
+
+		err := r.InventoryCommands.Create(forTenant("globex"), newInventory(t, "prod-1", 5))
+		if !errors.Is(err, errors.CodeProductNotFound) {
+			t.Errorf("Create() error code = %s, want %s", errors.GetCode(err), errors.CodeProductNotFound)
+		}
+	},
+	"CatalogIsScopedByTenant": func(t *testing.T, r Repositories) {
+		acme, globex := forTenant("acme"), forTenant("globex")
+		if err := r.ProductCommands.Create(acme, newProduct(t, "prod-1", 0)); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if err := r.ProductCommands.Create(globex, newProduct(t, "prod-2", 0)); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+
+		if entry, err := r.Catalog.GetByProductID(globex, "prod-1"); err != nil || entry != nil {
+			t.Errorf("GetByProductID() for globex = %v, %v; want nil, nil", entry, err)
+		}
+		if entry, err := r.Catalog.GetByProductID(acme, "prod-1"); err != nil || entry == nil {
+			t.Errorf("GetByProductID() for acme = %v, %v; want entry", entry, err)
+		}
+
+		// Rebuilds are administrative and cover every tenant
+		entries, err := r.CatalogProjection.Rebuild(context.Background())
+		if err != nil {
+			t.Fatalf("Rebuild() error = %v", err)
+		}
+		if entries != 2 {
+			t.Errorf("Rebuild() = %d entries, want 2", entries)
+		}
+	},
+}
+
+// The audit trail is append-only and cannot be emptied between cases,
+// so every case appends to the chain of a tenant of its own
+var auditContract = map[string]func(t *testing.T, r Repositories){
+	"AppendLinksEntriesIntoAVerifiableChain": func(t *testing.T, r Repositories) {
+		ctx := forTenant(newAuditTenant())
+		for i := 0; i < 3; i++ {
+			appendAuditEntry(t, r, ctx, "alice", fmt.Sprintf("prod-%d", i))
+		}
+
+		chain, err := r.Audit.Chain(ctx, 0, 10)
+		if err != nil {
+			t.Fatalf("Chain() error = %v", err)
+		}
+		if len(chain) != 3 || chain[0].Sequence != 1 || chain[2].Sequence != 3 {
+			t.Fatalf("Chain() = %d entries, want sequences 1 to 3", len(chain))
+		}
+		if chain[1].PreviousHash != chain[0].Hash {
+			t.Errorf("entry 2 links to %q, want %q", chain[1].PreviousHash, chain[0].Hash)
+		}
+		// Stored entries must hash as they did when appended
+		if err := audit.Verify(nil, chain); err != nil {
+			t.Errorf("Verify() error = %v", err)
+		}
+
+		rest, err := r.Audit.Chain(ctx, 2, 10)
+		if err != nil || len(rest) != 1 || rest[0].Sequence != 3 {
+			t.Errorf("Chain(after 2) = %d entries, %v; want entry 3", len(rest), err)
+		}
+	},
+	"EntriesCommitWithTheirUnitOfWork": func(t *testing.T, r Repositories) {
+		ctx := forTenant(newAuditTenant())
+		appendAuditEntry(t, r, ctx, "alice", "prod-1")
+
+		// A unit of work that fails keeps neither its change nor its entry
+		failure := errors.New(errors.CodeInvalidInput, "command failed")
+		err := r.ProductUnitOfWork.Do(ctx, func(ctx context.Context, repos product.TxRepositories) error {
+			if err := repos.ProductCommands.Create(ctx, newProduct(t, "audit-rolled-back", 0)); err != nil {
+				return err
+			}
+			if err := repos.Audit.Append(ctx, newAuditEntry("alice", "audit-rolled-back")); err != nil {
+				return err
+			}
+			return failure
+		})
+		if err != failure {
+			t.Fatalf("Do() error = %v, want %v", err, failure)
+		}
+		if got, err := r.ProductQueries.GetByID(ctx, "audit-rolled-back"); err != nil || got != nil {
+			t.Errorf("GetByID() after rollback = %v, %v; want nil", got, err)
+		}
+
+		err = r.ProductUnitOfWork.Do(ctx, func(ctx context.Context, repos product.TxRepositories) error {
+			if err := repos.ProductCommands.Create(ctx, newProduct(t, "audit-committed", 0)); err != nil {
+				return err
+			}
+			return repos.Audit.Append(ctx, newAuditEntry("alice", "audit-committed"))
+		})
+		if err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+		if got, err := r.ProductQueries.GetByID(ctx, "audit-committed"); err != nil || got == nil {
+			t.Errorf("GetByID() after commit = %v, %v; want the product", got, err)
+		}
+
+		// The committed entry continues the chain where the rolled back one left no gap
+		chain, err := r.Audit.Chain(ctx, 0, 10)
+		if err != nil {
+			t.Fatalf("Chain() error = %v", err)
+		}
+		if len(chain) != 2 || chain[1].AggregateID != "audit-committed" {
+			t.Fatalf("Chain() = %d entries, want the first entry and the committed one", len(chain))
+		}
+		if err := audit.Verify(nil, chain); err != nil {
+			t.Errorf("Verify() error = %v", err)
+		}
+	},
+	"ListFiltersNewestFirstWithPagination": func(t *testing.T, r Repositories) {
+		ctx := forTenant(newAuditTenant())
+		appendAuditEntry(t, r, ctx, "alice", "prod-1")
+		appendAuditEntry(t, r, ctx, "bob", "prod-1")
+		appendAuditEntry(t, r, ctx, "alice", "prod-2")
+		appendAuditEntry(t, r, ctx, "alice", "prod-1")
+
+		filter := audit.Filter{AggregateType: "product", AggregateID: "prod-1", Actor: "alice", Limit: 1, Offset: 1}
+		got, err := r.Audit.List(ctx, filter)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if len(got) != 1 || got[0].Sequence != 1 {
+			t.Errorf("List() = %d entries, want entry 1 only", len(got))
+		}
+		count, err := r.Audit.Count(ctx, filter)
+		if err != nil || count != 2 {
+			t.Errorf("Count() = %d, %v; want 2", count, err)
+		}
+
+		all, err := r.Audit.List(ctx, audit.Filter{Limit: 10})
+		if err != nil || len(all) != 4 || all[0].Sequence != 4 {
+			t.Errorf("List() without filters = %d entries, %v; want 4 newest first", len(all), err)
+		}
+	},
+	"ChainsAreKeptPerTenant": func(t *testing.T, r Repositories) {
+		acme := forTenant(newAuditTenant())
+		globex := forTenant(newAuditTenant())
+		appendAuditEntry(t, r, acme, "alice", "prod-1")
+		appendAuditEntry(t, r, acme, "alice", "prod-1")
+
+		entry := appendAuditEntry(t, r, globex, "bob", "prod-2")
+		if entry.Sequence != 1 || entry.PreviousHash != "" {
+			t.Errorf("first entry of globex = sequence %d after %q, want sequence 1 starting the chain", entry.Sequence, entry.PreviousHash)
+		}
+		if count, err := r.Audit.Count(globex, audit.Filter{}); err != nil || count != 1 {
+			t.Errorf("Count() for globex = %d, %v; want 1", count, err)
+		}
+	},
+}
+
+// Messages are due as soon as they are stored, so cases claim a little after the wall clock
+var outboxContract = map[string]func(t *testing.T, r Repositories){
+	"WritesStoreTheirPendingEvents": func(t *testing.T, r Repositories) {
+		ctx := forTenant("acme")
+		prod := createNewProduct(t, r, ctx, "prod-1")
+		inv := newInventory(t, "prod-1", 10)
+		if err := r.InventoryCommands.Create(ctx, inv); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		inv.PullEvents() // dispatched once stored, like the commands do
+		if err := inv.AdjustQuantity(-10); err != nil {
+			t.Fatalf("AdjustQuantity() error = %v", err)
+		}
+		if err := r.InventoryCommands.Update(ctx, inv); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		messages, err := r.Outbox.List(ctx, outbox.Filter{Limit: 10})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		want := []string{product.EventProductCreated, inventory.EventInventoryCreated, inventory.EventStockAdjusted, inventory.EventStockDepleted}
+		if got := outboxEventNames(messages); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("List() = %v, want %v", got, want)
+		}
+		created := messages[0]
+		if created.TenantID != "acme" || created.AggregateType != product.AggregateType || created.AggregateID != prod.ID() ||
+			created.Status != outbox.StatusPending || created.Attempts != 0 {
+			t.Errorf("stored message = %+v, want pending product.created of prod-1 for acme", created)
+		}
+		if !strings.Contains(string(created.Payload), `"product_id":"prod-1"`) {
+			t.Errorf("payload = %s, want the encoded event", created.Payload)
+		}
+		if messages[1].AggregateType != inventory.AggregateType || messages[1].AggregateID != "prod-1" {
+			t.Errorf("inventory message aggregate = %s %s, want inventory prod-1", messages[1].AggregateType, messages[1].AggregateID)
+		}
+	},
+	"FailedWritesStoreNoEvents": func(t *testing.T, r Repositories) {
+		ctx := forTenant("acme")
+		prod := createNewProduct(t, r, ctx, "prod-1")
+		if err := r.ProductCommands.Create(ctx, prod); err == nil {
+			t.Fatal("Create() of a duplicate product succeeded")
+		}
+
+		if count, err := r.Outbox.Count(ctx, outbox.Filter{}); err != nil || count != 1 {
+			t.Errorf("Count() = %d, %v; want only the first product.created", count, err)
+		}
+	},
+	"ClaimTakesTheOldestMessageOfEachAggregate": func(t *testing.T, r Repositories) {
+		ctx := forTenant("acme")
+		prod := createNewProduct(t, r, ctx, "prod-1")
+		price, _ := product.NewPrice(20, "USD")
+		if err := prod.UpdatePrice(price); err != nil {
+			t.Fatalf("UpdatePrice() error = %v", err)
+		}
+		if err := r.ProductCommands.Update(ctx, prod); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		createNewProduct(t, r, forTenant("globex"), "prod-2")
+
+		now := time.Now().Add(time.Second)
+		claimed := claimOutbox(t, r, now, 10)
+		if got := outboxAggregates(claimed); fmt.Sprint(got) != "[prod-1 prod-2]" {
+			t.Fatalf("Claim() = %v, want the first message of prod-1 and of prod-2", got)
+		}
+		if claimed[0].EventName != product.EventProductCreated || claimed[1].TenantID != "globex" {
+			t.Errorf("Claim() = %s of %s, %s of %s; want product.created of acme first",
+				claimed[0].EventName, claimed[0].TenantID, claimed[1].EventName, claimed[1].TenantID)
+		}
+		if again := claimOutbox(t, r, now, 10); len(again) != 0 {
+			t.Errorf("Claim() during the lease = %v, want nothing", outboxAggregates(again))
+		}
+
+		// Publishing an aggregate's message releases its next one
+		if err := r.Outbox.MarkPublished(context.Background(), claimed[0].ID, now); err != nil {
+			t.Fatalf("MarkPublished() error = %v", err)
+		}
+		next := claimOutbox(t, r, now, 10)
+		if len(next) != 1 || next[0].EventName != product.EventProductPriceChanged {
+			t.Fatalf("Claim() after publishing = %v, want product.price_changed of prod-1", outboxEventNames(next))
+		}
+
+		// Leases expire, so a message whose publisher died is claimed again
+		expired := claimOutbox(t, r, now.Add(time.Hour), 10)
+		if len(expired) != 2 || expired[0].ID != next[0].ID || expired[1].ID != claimed[1].ID {
+			t.Errorf("Claim() after the lease = %v, want prod-1 and prod-2 again", outboxEventNames(expired))
+		}
+	},
+	"FailedMessagesAreRetriedThenDieUntilReplayed": func(t *testing.T, r Repositories) {
+		ctx := forTenant("acme")
+		createNewProduct(t, r, ctx, "prod-1")
+		now := time.Now().Add(time.Second)
+		claimed := claimOutbox(t, r, now, 10)
+		if len(claimed) != 1 {
+			t.Fatalf("Claim() = %d messages, want 1", len(claimed))
+		}
+		id := claimed[0].ID
+
+		if err := r.Outbox.MarkFailed(context.Background(), id, 1, now.Add(time.Minute), "broker down", false); err != nil {
+			t.Fatalf("MarkFailed() error = %v", err)
+		}
+		if early := claimOutbox(t, r, now, 10); len(early) != 0 {
+			t.Errorf("Claim() before the retry = %d messages, want none", len(early))
+		}
+		retried := claimOutbox(t, r, now.Add(time.Minute), 10)
+		if len(retried) != 1 || retried[0].Attempts != 1 || retried[0].LastError != "broker down" {
+			t.Fatalf("Claim() at the retry = %+v, want the message after 1 attempt", retried)
+		}
+
+		if err := r.Outbox.MarkFailed(context.Background(), id, 2, now.Add(time.Hour), "broker down", true); err != nil {
+			t.Fatalf("MarkFailed() error = %v", err)
+		}
+		if dead := claimOutbox(t, r, now.Add(24*time.Hour), 10); len(dead) != 0 {
+			t.Errorf("Claim() of a dead message = %d messages, want none", len(dead))
+		}
+		if count, err := r.Outbox.Count(ctx, outbox.Filter{Status: outbox.StatusDead}); err != nil || count != 1 {
+			t.Errorf("Count(dead) = %d, %v; want 1", count, err)
+		}
+
+		if replayed, err := r.Outbox.Replay(forTenant("globex"), id, now); err != nil || replayed {
+			t.Errorf("Replay() by another tenant = %v, %v; want false", replayed, err)
+		}
+		if replayed, err := r.Outbox.Replay(ctx, id, now); err != nil || !replayed {
+			t.Fatalf("Replay() = %v, %v; want true", replayed, err)
+		}
+		if replayed, err := r.Outbox.Replay(ctx, id, now); err != nil || replayed {
+			t.Errorf("Replay() of a pending message = %v, %v; want false", replayed, err)
+		}
+		again := claimOutbox(t, r, now, 10)
+		if len(again) != 1 || again[0].Attempts != 0 || again[0].Status != outbox.StatusPending {
+			t.Errorf("Claim() after replay = %+v, want the message with no attempts", again)
+		}
+	},
+	"DeletePublishedRemovesOnlyOldPublishedMessages": func(t *testing.T, r Repositories) {
+		ctx := forTenant("acme")
+		createNewProduct(t, r, ctx, "prod-1")
+		createNewProduct(t, r, ctx, "prod-2")
+		now := time.Now().Add(time.Second)
+		claimed := claimOutbox(t, r, now, 10)
+		if len(claimed) != 2 {
+			t.Fatalf("Claim() = %d messages, want 2", len(claimed))
+		}
+		if err := r.Outbox.MarkPublished(context.Background(), claimed[0].ID, now); err != nil {
+			t.Fatalf("MarkPublished() error = %v", err)
+		}
+
+		deleted, err := r.Outbox.DeletePublished(context.Background(), now.Add(time.Minute))
+		if err != nil || deleted != 1 {
+			t.Fatalf("DeletePublished() = %d, %v; want 1", deleted, err)
+		}
+		remaining, err := r.Outbox.List(ctx, outbox.Filter{Limit: 10})
+		if err != nil || len(remaining) != 1 || remaining[0].ID != claimed[1].ID {
+			t.Errorf("List() after delete = %v, %v; want the unpublished message", outboxAggregates(remaining), err)
+		}
+	},
+}
+
+// Webhook cases work at baseTime, so deliveries are due exactly when they say they are
+var webhookContract = map[string]func(t *testing.T, r Repositories){
+	"SubscriptionsBelongToTheirTenant": func(t *testing.T, r Repositories) {
+		acme := forTenant("acme")
+		createSubscription(t, r, acme, "sub-2", baseTime.Add(time.Minute), product.EventProductCreated)
+		first := createSubscription(t, r, acme, "sub-1", baseTime, webhook.AllEvents)
+
+		got, err := r.Webhooks.GetSubscription(acme, "sub-1")
+		if err != nil || got == nil {
+			t.Fatalf("GetSubscription() = %v, %v; want sub-1", got, err)
+		}
+		if got.TenantID != "acme" || got.URL != first.URL || fmt.Sprint(got.EventTypes) != "[*]" ||
+			got.Secret != first.Secret || !got.Active || !got.CreatedAt.Equal(baseTime) {
+			t.Errorf("GetSubscription() = %+v, want the stored subscription", got)
+		}
+		if other, err := r.Webhooks.GetSubscription(forTenant("globex"), "sub-1"); err != nil || other != nil {
+			t.Errorf("GetSubscription() by another tenant = %v, %v; want nil", other, err)
+		}
+
+		subscriptions, err := r.Webhooks.ListSubscriptions(acme)
+		if err != nil {
+			t.Fatalf("ListSubscriptions() error = %v", err)
+		}
+		if got := subscriptionIDs(subscriptions); fmt.Sprint(got) != "[sub-1 sub-2]" {
+			t.Errorf("ListSubscriptions() = %v, want oldest first", got)
+		}
+		if others, err := r.Webhooks.ListSubscriptions(forTenant("globex")); err != nil || len(others) != 0 {
+			t.Errorf("ListSubscriptions() by another tenant = %v, %v; want none", subscriptionIDs(others), err)
+		}
+
+		got.URL = "https://example.com/renamed"
+		got.EventTypes = []string{product.EventProductCreated, inventory.EventStockAdjusted}
+		got.Active = false
+		got.DisabledAt = baseTime.Add(time.Hour)
+		got.UpdatedAt = baseTime.Add(time.Hour)
+		if err := r.Webhooks.UpdateSubscription(acme, got); err != nil {
+			t.Fatalf("UpdateSubscription() error = %v", err)
+		}
+		updated, err := r.Webhooks.GetSubscription(acme, "sub-1")
+		if err != nil || updated == nil {
+			t.Fatalf("GetSubscription() after update = %v, %v", updated, err)
+		}
+		if updated.URL != got.URL || len(updated.EventTypes) != 2 || updated.Active || !updated.DisabledAt.Equal(got.DisabledAt) {
+			t.Errorf("GetSubscription() after update = %+v, want the new URL, events and state", updated)
+		}
+
+		if deleted, err := r.Webhooks.DeleteSubscription(forTenant("globex"), "sub-1"); err != nil || deleted {
+			t.Errorf("DeleteSubscription() by another tenant = %v, %v; want false", deleted, err)
+		}
+		if deleted, err := r.Webhooks.DeleteSubscription(acme, "sub-1"); err != nil || !deleted {
+			t.Fatalf("DeleteSubscription() = %v, %v; want true", deleted, err)
+		}
+		if gone, err := r.Webhooks.GetSubscription(acme, "sub-1"); err != nil || gone != nil {
+			t.Errorf("GetSubscription() after delete = %v, %v; want nil", gone, err)
+		}
+	},
+	"EnqueueDeliversEachMessageOnce": func(t *testing.T, r Repositories) {
+		ctx := forTenant("acme")
+		createSubscription(t, r, ctx, "sub-1", baseTime, webhook.AllEvents)
+		enqueueDeliveries(t, r, ctx, "sub-1", 1, 2)
+		enqueueDeliveries(t, r, ctx, "sub-1", 2, 3)
+
+		deliveries, err := r.Webhooks.ListDeliveries(ctx, "sub-1", webhook.DeliveryFilter{Limit: 10})
+		if err != nil {
+			t.Fatalf("ListDeliveries() error = %v", err)
+		}
+		if got := deliveryMessageIDs(deliveries); fmt.Sprint(got) != "[3 2 1]" {
+			t.Fatalf("ListDeliveries() = %v, want messages 3, 2 and 1 once each, newest first", got)
+		}
+		d := deliveries[2]
+		if d.TenantID != "acme" || d.EventName != product.EventProductCreated || d.Status != webhook.DeliveryPending ||
+			d.Attempts != 0 || !strings.Contains(string(d.Payload), `"id":1`) {
+			t.Errorf("stored delivery = %+v, want a pending product.created of message 1 for acme", d)
+		}
+	},
+	"ClaimTakesDueDeliveriesOfActiveSubscriptions": func(t *testing.T, r Repositories) {
+		acme := forTenant("acme")
+		createSubscription(t, r, acme, "sub-1", baseTime, webhook.AllEvents)
+		paused := createSubscription(t, r, acme, "sub-2", baseTime, webhook.AllEvents)
+		createSubscription(t, r, forTenant("globex"), "sub-3", baseTime, webhook.AllEvents)
+		enqueueDeliveries(t, r, acme, "sub-1", 1)
+		enqueueDeliveries(t, r, acme, "sub-2", 1)
+		enqueueDeliveries(t, r, forTenant("globex"), "sub-3", 2)
+		paused.Active = false
+		if err := r.Webhooks.UpdateSubscription(acme, paused); err != nil {
+			t.Fatalf("UpdateSubscription() error = %v", err)
+		}
+
+		claimed := claimDeliveries(t, r, baseTime)
+		if got := deliverySubscriptions(claimed); fmt.Sprint(got) != "[sub-1 sub-3]" {
+			t.Fatalf("Claim() = %v, want the deliveries of the active subscriptions of both tenants", got)
+		}
+		if claimed[1].TenantID != "globex" {
+			t.Errorf("Claim() tenant = %s, want globex", claimed[1].TenantID)
+		}
+		if again := claimDeliveries(t, r, baseTime); len(again) != 0 {
+			t.Errorf("Claim() during the lease = %v, want nothing", deliverySubscriptions(again))
+		}
+		if expired := claimDeliveries(t, r, baseTime.Add(time.Hour)); len(expired) != 2 {
+			t.Errorf("Claim() after the lease = %v, want both deliveries again", deliverySubscriptions(expired))
+		}
+	},
+	"FailedDeliveriesAreRetriedThenFailUntilRedelivered": func(t *testing.T, r Repositories) {
+		ctx := forTenant("acme")
+		createSubscription(t, r, ctx, "sub-1", baseTime, webhook.AllEvents)
+		enqueueDeliveries(t, r, ctx, "sub-1", 1)
+		claimed := claimDeliveries(t, r, baseTime)
+		if len(claimed) != 1 {
+			t.Fatalf("Claim() = %d deliveries, want 1", len(claimed))
+		}
+		id := claimed[0].ID
+
+		attempt := webhook.Attempt{At: baseTime, StatusCode: 500, Error: "unexpected status 500"}
+		if err := r.Webhooks.MarkFailed(context.Background(), id, 1, attempt, baseTime.Add(time.Minute), false); err != nil {
+			t.Fatalf("MarkFailed() error = %v", err)
+		}
+		if early := claimDeliveries(t, r, baseTime); len(early) != 0 {
+			t.Errorf("Claim() before the retry = %d deliveries, want none", len(early))
+		}
+		retried := claimDeliveries(t, r, baseTime.Add(time.Minute))
+		if len(retried) != 1 || retried[0].Attempts != 1 || retried[0].LastStatusCode != 500 ||
+			retried[0].LastError != attempt.Error || !retried[0].LastAttemptAt.Equal(baseTime) {
+			t.Fatalf("Claim() at the retry = %+v, want the delivery after 1 attempt", retried)
+		}
+
+		if err := r.Webhooks.MarkFailed(context.Background(), id, 2, attempt, baseTime.Add(time.Hour), true); err != nil {
+			t.Fatalf("MarkFailed() error = %v", err)
+		}
+		if failed := claimDeliveries(t, r, baseTime.Add(24*time.Hour)); len(failed) != 0 {
+			t.Errorf("Claim() of a failed delivery = %d deliveries, want none", len(failed))
+		}
+		if count, err := r.Webhooks.CountDeliveries(ctx, "sub-1", webhook.DeliveryFilter{Status: webhook.DeliveryFailed}); err != nil || count != 1 {
+			t.Errorf("CountDeliveries(failed) = %d, %v; want 1", count, err)
+		}
+
+		if redelivered, err := r.Webhooks.Redeliver(forTenant("globex"), "sub-1", id, baseTime); err != nil || redelivered {
+			t.Errorf("Redeliver() by another tenant = %v, %v; want false", redelivered, err)
+		}
+		if redelivered, err := r.Webhooks.Redeliver(ctx, "sub-2", id, baseTime); err != nil || redelivered {
+			t.Errorf("Redeliver() through another subscription = %v, %v; want false", redelivered, err)
+		}
+		if redelivered, err := r.Webhooks.Redeliver(ctx, "sub-1", id, baseTime); err != nil || !redelivered {
+			t.Fatalf("Redeliver() = %v, %v; want true", redelivered, err)
+		}
+		again := claimDeliveries(t, r, baseTime)
+		if len(again) != 1 || again[0].Attempts != 0 || again[0].Status != webhook.DeliveryPending {
+			t.Errorf("Claim() after redelivery = %+v, want the delivery with no attempts", again)
+		}
+	},
+	"DeliveredDeliveriesAreLoggedThenPruned": func(t *testing.T, r Repositories) {
+		ctx := forTenant("acme")
+		createSubscription(t, r, ctx, "sub-1", baseTime, webhook.AllEvents)
+		enqueueDeliveries(t, r, ctx, "sub-1", 1, 2)
+		claimed := claimDeliveries(t, r, baseTime)
+		if len(claimed) != 2 {
+			t.Fatalf("Claim() = %d deliveries, want 2", len(claimed))
+		}
+		if err := r.Webhooks.MarkDelivered(context.Background(), claimed[0].ID, 1, webhook.Attempt{At: baseTime, StatusCode: 204}); err != nil {
+			t.Fatalf("MarkDelivered() error = %v", err)
+		}
+
+		delivered, err := r.Webhooks.ListDeliveries(ctx, "sub-1", webhook.DeliveryFilter{Status: webhook.DeliveryDelivered, Limit: 10})
+		if err != nil || len(delivered) != 1 {
+			t.Fatalf("ListDeliveries(delivered) = %d, %v; want 1", len(delivered), err)
+		}
+		if d := delivered[0]; d.ID != claimed[0].ID || d.Attempts != 1 || d.LastStatusCode != 204 || !d.DeliveredAt.Equal(baseTime) {
+			t.Errorf("delivered delivery = %+v, want 1 attempt answered with 204", d)
+		}
+		if pending := claimDeliveries(t, r, baseTime.Add(time.Hour)); len(pending) != 1 || pending[0].ID != claimed[1].ID {
+			t.Errorf("Claim() after delivery = %v, want only the undelivered message", deliveryMessageIDs(pending))
+		}
+
+		deleted, err := r.Webhooks.DeleteDelivered(context.Background(), baseTime.Add(time.Minute))
+		if err != nil || deleted != 1 {
+			t.Fatalf("DeleteDelivered() = %d, %v; want 1", deleted, err)
+		}
+		if count, err := r.Webhooks.CountDeliveries(ctx, "sub-1", webhook.DeliveryFilter{}); err != nil || count != 1 {
+			t.Errorf("CountDeliveries() after delete = %d, %v; want 1", count, err)
+		}
+	},
+	"RecordFailureDisablesAfterRepeatedFailures": func(t *testing.T, r Repositories) {
+		ctx := forTenant("acme")
+		createSubscription(t, r, ctx, "sub-1", baseTime, webhook.AllEvents)
+		disabledAt := baseTime.Add(time.Hour)
+
+		for i := 1; i <= 2; i++ {
+			if disabled, err := r.Webhooks.RecordFailure(ctx, "sub-1", 3, disabledAt); err != nil || disabled {
+				t.Fatalf("RecordFailure() #%d = %v, %v; want false", i, disabled, err)
+			}
+		}
+		// A success in between starts the count again
+		if err := r.Webhooks.RecordSuccess(ctx, "sub-1"); err != nil {
+			t.Fatalf("RecordSuccess() error = %v", err)
+		}
+		for i := 1; i <= 2; i++ {
+			if disabled, err := r.Webhooks.RecordFailure(ctx, "sub-1", 3, disabledAt); err != nil || disabled {
+				t.Fatalf("RecordFailure() #%d after a success = %v, %v; want false", i, disabled, err)
+			}
+		}
+		if disabled, err := r.Webhooks.RecordFailure(ctx, "sub-1", 3, disabledAt); err != nil || !disabled {
+			t.Fatalf("RecordFailure() #3 = %v, %v; want true", disabled, err)
+		}
+		if disabled, err := r.Webhooks.RecordFailure(ctx, "sub-1", 3, disabledAt); err != nil || disabled {
+			t.Errorf("RecordFailure() of a disabled subscription = %v, %v; want false", disabled, err)
+		}
+
+		sub, err := r.Webhooks.GetSubscription(ctx, "sub-1")
+		if err != nil || sub == nil {
+			t.Fatalf("GetSubscription() = %v, %v", sub, err)
+		}
+		if sub.Active || sub.ConsecutiveFailures != 4 || !sub.DisabledAt.Equal(disabledAt) {
+			t.Errorf("GetSubscription() = %+v, want disabled after 4 failures", sub)
+		}
+		if disabled, err := r.Webhooks.RecordFailure(forTenant("globex"), "sub-1", 3, disabledAt); err != nil || disabled {
+			t.Errorf("RecordFailure() by another tenant = %v, %v; want false", disabled, err)
+		}
+	},
+	"ListDeliveriesPagesNewestFirst": func(t *testing.T, r Repositories) {
+		ctx := forTenant("acme")
+		createSubscription(t, r, ctx, "sub-1", baseTime, webhook.AllEvents)
+		createSubscription(t, r, ctx, "sub-2", baseTime, webhook.AllEvents)
+		enqueueDeliveries(t, r, ctx, "sub-1", 1, 2, 3, 4)
+		enqueueDeliveries(t, r, ctx, "sub-2", 5)
+
+		page, err := r.Webhooks.ListDeliveries(ctx, "sub-1", webhook.DeliveryFilter{Offset: 1, Limit: 2})
+		if err != nil {
+			t.Fatalf("ListDeliveries() error = %v", err)
+		}
+		if got := deliveryMessageIDs(page); fmt.Sprint(got) != "[3 2]" {
+			t.Errorf("ListDeliveries(offset 1, limit 2) = %v, want [3 2]", got)
+		}
+		if count, err := r.Webhooks.CountDeliveries(ctx, "sub-1", webhook.DeliveryFilter{}); err != nil || count != 4 {
+			t.Errorf("CountDeliveries() = %d, %v; want 4", count, err)
+		}
+		if others, err := r.Webhooks.ListDeliveries(forTenant("globex"), "sub-1", webhook.DeliveryFilter{Limit: 10}); err != nil || len(others) != 0 {
+			t.Errorf("ListDeliveries() by another tenant = %v, %v; want none", deliveryMessageIDs(others), err)
+		}
+	},
+	"DeleteSubscriptionRemovesItsDeliveries": func(t *testing.T, r Repositories) {
+		ctx := forTenant("acme")
+		createSubscription(t, r, ctx, "sub-1", baseTime, webhook.AllEvents)
+		createSubscription(t, r, ctx, "sub-2", baseTime, webhook.AllEvents)
+		enqueueDeliveries(t, r, ctx, "sub-1", 1, 2)
+		enqueueDeliveries(t, r, ctx, "sub-2", 1)
+
+		if deleted, err := r.Webhooks.DeleteSubscription(ctx, "sub-1"); err != nil || !deleted {
+			t.Fatalf("DeleteSubscription() = %v, %v; want true", deleted, err)
+		}
+		if count, err := r.Webhooks.CountDeliveries(ctx, "sub-1", webhook.DeliveryFilter{}); err != nil || count != 0 {
+			t.Errorf("CountDeliveries() of the deleted subscription = %d, %v; want 0", count, err)
+		}
+		if claimed := claimDeliveries(t, r, baseTime); fmt.Sprint(deliverySubscriptions(claimed)) != "[sub-2]" {
+			t.Errorf("Claim() = %v, want only the delivery of sub-2", deliverySubscriptions(claimed))
+		}
+	},
+}
+
+var eventSourcingContract = map[string]func(t *testing.T, r Repositories){
+	"RehydratesAcrossSnapshots": func(t *testing.T, r Repositories) {
+		ctx := context.Background()
+		createProduct(t, r, "prod-1", 0)
+		createInventory(t, r, newInventory(t, "prod-1", 10))
+		backorder, _ := inventory.NewStockPolicy(inventory.StockPolicyBackorder, 5)
+
+		// Every change is stored from a fresh load, so each one replays the stream written before it
+		changes := []func(inv *inventory.Inventory) error{
+			func(inv *inventory.Inventory) error { return inv.AdjustQuantity(5) },
+			func(inv *inventory.Inventory) error { return inv.Reserve(4) },
+			func(inv *inventory.Inventory) error { inv.UpdateLocation("WH-2"); return nil },
+			func(inv *inventory.Inventory) error { return inv.UpdateMetadata(map[string]string{"bin": "A1"}) },
+			func(inv *inventory.Inventory) error { return inv.Release(1) },
+			func(inv *inventory.Inventory) error { return inv.SetStockPolicy(backorder) },
+			func(inv *inventory.Inventory) error { return inv.Reserve(14) }, // backorders 2 and depletes the stock
+			func(inv *inventory.Inventory) error { return inv.AdjustQuantity(4) },
+		}
+		for i, change := range changes {
+			inv, err := r.InventoryQueries.GetByProductID(ctx, "prod-1")
+			if err != nil || inv == nil {
+				t.Fatalf("GetByProductID() before change %d = %v, %v", i, inv, err)
+			}
+			if err := change(inv); err != nil {
+				t.Fatalf("change %d error = %v", i, err)
+			}
+			if err := r.InventoryCommands.Update(ctx, inv); err != nil {
+				t.Fatalf("Update() of change %d error = %v", i, err)
+			}
+		}
+
+		got, err := r.InventoryQueries.GetByProductID(ctx, "prod-1")
+		if err != nil {
+			t.Fatalf("GetByProductID() error = %v", err)
+		}
+		if got.Quantity() != 19 || got.ReservedQuantity() != 17 || got.BackorderedQuantity() != 0 || got.Version() != 10 {
+			t.Errorf("GetByProductID() = %d/%d reserved/%d backordered v%d, want 19/17/0 v10",
+				got.Quantity(), got.ReservedQuantity(), got.BackorderedQuantity(), got.Version())
+		}
+		if got.Location() != "WH-2" || got.Metadata()["bin"] != "A1" || got.StockPolicy() != backorder {
+			t.Errorf("GetByProductID() = %s %v %v, want WH-2 with bin A1 and the backorder policy",
+				got.Location(), got.Metadata(), got.StockPolicy())
+		}
+
+		// The inventory table is kept as the projection of the stream
+		listed, err := r.InventoryQueries.List(ctx, inventory.InventoryFilter{Location: "WH-2", Limit: 10})
+		if err != nil || len(listed) != 1 {
+			t.Fatalf("List() = %d inventories, %v; want 1", len(listed), err)
+		}
+		if listed[0].Quantity() != 19 || listed[0].ReservedQuantity() != 17 || listed[0].Version() != 10 {
+			t.Errorf("List() = %d/%d reserved v%d, want 19/17 v10", listed[0].Quantity(), listed[0].ReservedQuantity(), listed[0].Version())
+		}
+	},
+	"ConcurrentAppendsConflict": func(t *testing.T, r Repositories) {
+		ctx := context.Background()
+		createProduct(t, r, "prod-1", 0)
+		createInventory(t, r, newInventory(t, "prod-1", 10))
+		first, _ := r.InventoryQueries.GetByProductID(ctx, "prod-1")
+		second, _ := r.InventoryQueries.GetByProductID(ctx, "prod-1")
+
+		_ = first.Reserve(3)
+		if err := r.InventoryCommands.Update(ctx, first); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		_ = second.Reserve(4)
+		if err := r.InventoryCommands.Update(ctx, second); err != inventory.ErrConcurrentModification {
+			t.Errorf("Update() of a stale stream error = %v, want %v", err, inventory.ErrConcurrentModification)
+		}
+
+		got, _ := r.InventoryQueries.GetByProductID(ctx, "prod-1")
+		if got.ReservedQuantity() != 3 || got.Version() != 2 {
+			t.Errorf("GetByProductID() = %d reserved v%d, want 3 v2", got.ReservedQuantity(), got.Version())
+		}
+	},
+	"AdoptsInventoriesWithoutAStream": func(t *testing.T, r Repositories) {
+		ctx := context.Background()
+		createProduct(t, r, "prod-1", 0)
+		// Inventories stored before event sourcing was enabled have a row but no events
+		createInventory(t, r, inventory.ReconstructInventory("inv-prod-1", "prod-1", 8, 2, 0,
+			inventory.StrictStockPolicy(), "WH-1", nil, baseTime, baseTime, 4))
+
+		inv, _ := r.InventoryQueries.GetByProductID(ctx, "prod-1")
+		if inv.Quantity() != 8 || inv.ReservedQuantity() != 2 || inv.Version() != 4 {
+			t.Fatalf("GetByProductID() = %d/%d reserved v%d, want 8/2 v4", inv.Quantity(), inv.ReservedQuantity(), inv.Version())
+		}
+		for i := 0; i < SnapshotEvery+1; i++ {
+			inv, _ = r.InventoryQueries.GetByProductID(ctx, "prod-1")
+			_ = inv.AdjustQuantity(1)
+			if err := r.InventoryCommands.Update(ctx, inv); err != nil {
+				t.Fatalf("Update() %d error = %v", i, err)
+			}
+		}
+
+		got, _ := r.InventoryQueries.GetByProductID(ctx, "prod-1")
+		want := 4 + SnapshotEvery + 1
+		if got.Quantity() != 8+SnapshotEvery+1 || got.ReservedQuantity() != 2 || got.Version() != want {
+			t.Errorf("GetByProductID() = %d/%d reserved v%d, want %d/2 v%d",
+				got.Quantity(), got.ReservedQuantity(), got.Version(), 8+SnapshotEvery+1, want)
+		}
+	},
+	"GetByProductIDsRehydratesEachStream": func(t *testing.T, r Repositories) {
+		ctx := context.Background()
+		for i, productID := range []string{"prod-1", "prod-2", "prod-3"} {
+			createProduct(t, r, productID, i)
+		}
+		// prod-1 continues past a snapshot, prod-2 has only its first event and prod-3 has no stream
+		createInventory(t, r, newInventory(t, "prod-1", 10))
+		for i := 0; i < SnapshotEvery+1; i++ {
+			if err := r.InventoryCommands.AdjustStock(ctx, "prod-1", 1); err != nil {
+				t.Fatalf("AdjustStock() error = %v", err)
+			}
+		}
+		createInventory(t, r, newInventory(t, "prod-2", 5))
+		createInventory(t, r, inventory.ReconstructInventory("inv-prod-3", "prod-3", 8, 2, 0,
+			inventory.StrictStockPolicy(), "WH-1", nil, baseTime, baseTime, 4))
+
+		got, err := r.InventoryQueries.GetByProductIDs(ctx, []string{"prod-3", "missing", "prod-1", "prod-2", "prod-1"})
+		if err != nil {
+			t.Fatalf("GetByProductIDs() error = %v", err)
+		}
+		if len(got) != 3 {
+			t.Fatalf("GetByProductIDs() = %v, want each of the 3 inventories once", inventoryProductIDs(got))
+		}
+		for _, inv := range got {
+			want, err := r.InventoryQueries.GetByProductID(ctx, inv.ProductID())
+			if err != nil || want == nil {
+				t.Fatalf("GetByProductID(%s) = %v, %v", inv.ProductID(), want, err)
+			}
+			if inv.Quantity() != want.Quantity() || inv.ReservedQuantity() != want.ReservedQuantity() || inv.Version() != want.Version() {
+				t.Errorf("GetByProductIDs() %s = %d/%d reserved v%d, want %d/%d reserved v%d", inv.ProductID(),
+					inv.Quantity(), inv.ReservedQuantity(), inv.Version(), want.Quantity(), want.ReservedQuantity(), want.Version())
+			}
+		}
+		for _, inv := range got {
+			if inv.ProductID() == "prod-1" && (inv.Quantity() != 10+SnapshotEvery+1 || inv.Version() != SnapshotEvery+2) {
+				t.Errorf("GetByProductIDs() prod-1 = %d v%d, want %d v%d", inv.Quantity(), inv.Version(), 10+SnapshotEvery+1, SnapshotEvery+2)
+			}
+		}
+	},
+	"ApplyingAStocktakeAppendsToTheStream": func(t *testing.T, r Repositories) {
+		ctx := context.Background()
+		createProduct(t, r, "prod-1", 0)
+		createProduct(t, r, "prod-2", 1)
+		createInventory(t, r, newInventory(t, "prod-1", 10))
+		createInventory(t, r, newInventory(t, "prod-2", 5))
+		productIDs := []string{"prod-1", "prod-2"}
+
+		snapshot, err := r.InventoryQueries.GetByProductIDs(ctx, productIDs)
+		if err != nil {
+			t.Fatalf("GetByProductIDs() error = %v", err)
+		}
+		st, err := inventory.NewStocktake("st-1", snapshot)
+		if err != nil {
+			t.Fatalf("NewStocktake() error = %v", err)
+		}
+		err = r.UnitOfWork.Do(ctx, func(ctx context.Context, repos inventory.TxRepositories) error {
+			if err := repos.StocktakeCommands.Create(ctx, st); err != nil {
+				return err
+			}
+			_ = st.RecordCount("prod-1", 7)
+			_ = st.RecordCount("prod-2", 6)
+			_ = st.Approve()
+			return repos.StocktakeCommands.Update(ctx, st)
+		})
+		if err != nil {
+			t.Fatalf("counting the stocktake: %v", err)
+		}
+
+		// Applied as ApplyStocktake does: the variances are saved through the inventory repository
+		err = r.UnitOfWork.Do(ctx, func(ctx context.Context, repos inventory.TxRepositories) error {
+			st, err := repos.StocktakeQueries.GetByID(ctx, "st-1")
+			if err != nil {
+				return err
+			}
+			inventories, err := repos.InventoryQueries.GetByProductIDs(ctx, productIDs)
+			if err != nil {
+				return err
+			}
+			variances := make(map[string]int)
+			for _, line := range st.ApprovedVariances() {
+				variances[line.ProductID()] = line.Variance()
+			}
+			if len(variances) != 2 {
+				t.Fatalf("ApprovedVariances() = %v, want both products", variances)
+			}
+			for _, inv := range inventories {
+				if err := inv.AdjustQuantity(variances[inv.ProductID()]); err != nil {
+					return err
+				}
+			}
+			if err := st.MarkApplied(); err != nil {
+				return err
+			}
+			if err := repos.InventoryCommands.UpdateBatch(ctx, inventories); err != nil {
+				return err
+			}
+			return repos.StocktakeCommands.Apply(ctx, st)
+		})
+		if err != nil {
+			t.Fatalf("applying the stocktake: %v", err)
+		}
+
+		// Loads replay the stream, so the variances must have been appended to it
+		for productID, want := range map[string]int{"prod-1": 7, "prod-2": 6} {
+			got, err := r.InventoryQueries.GetByProductID(ctx, productID)
+			if err != nil || got == nil {
+				t.Fatalf("GetByProductID(%s) = %v, %v", productID, got, err)
+			}
+			if got.Quantity() != want || got.Version() != 2 {
+				t.Errorf("GetByProductID(%s) = %d v%d, want %d v2", productID, got.Quantity(), got.Version(), want)
+			}
+		}
+
+		// The projection stays at the stream's version, so the next change appends after the variance
+		inv, _ := r.InventoryQueries.GetByProductID(ctx, "prod-1")
+		_ = inv.Reserve(2)
+		if err := r.InventoryCommands.Update(ctx, inv); err != nil {
+			t.Fatalf("Update() after the stocktake error = %v", err)
+		}
+		listed, err := r.InventoryQueries.List(ctx, inventory.InventoryFilter{Limit: 10})
+		if err != nil || len(listed) != 2 {
+			t.Fatalf("List() = %d inventories, %v; want 2", len(listed), err)
+		}
+		for _, got := range listed {
+			if got.ProductID() == "prod-1" && (got.Quantity() != 7 || got.ReservedQuantity() != 2 || got.Version() != 3) {
+				t.Errorf("List() prod-1 = %d/%d reserved v%d, want 7/2 v3", got.Quantity(), got.ReservedQuantity(), got.Version())
+			}
+		}
+	},
+	"DeleteEndsTheStream": func(t *testing.T, r Repositories) {
+		ctx := context.Background()
+		createProduct(t, r, "prod-1", 0)
+		createInventory(t, r, newInventory(t, "prod-1", 10))
+		for i := 0; i < SnapshotEvery; i++ {
+			if err := r.InventoryCommands.AdjustStock(ctx, "prod-1", 1); err != nil {
+				t.Fatalf("AdjustStock() error = %v", err)
+			}
+		}
+
+		stored, _ := r.InventoryQueries.GetByProductID(ctx, "prod-1")
+		if err := stored.Remove(); err != nil {
+			t.Fatalf("Remove() error = %v", err)
+		}
+		if err := r.InventoryCommands.Delete(ctx, stored); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if got, _ := r.InventoryQueries.GetByProductID(ctx, "prod-1"); got != nil {
+			t.Fatalf("GetByProductID() after Delete = %v, want nil", got)
+		}
+		messages, err := r.Outbox.List(ctx, outbox.Filter{Limit: 100})
+		if err != nil || len(messages) == 0 || messages[len(messages)-1].EventName != inventory.EventInventoryDeleted {
+			t.Fatalf("Outbox.List() = %v, %v; want %s last", outboxEventNames(messages), err, inventory.EventInventoryDeleted)
+		}
+
+		// A new inventory of the product continues the stream after the deletion,
+		// and its creation replaces everything the deleted inventory left
+		deleted := SnapshotEvery + 2
+		createInventory(t, r, newInventory(t, "prod-1", 3))
+		got, err := r.InventoryQueries.GetByProductID(ctx, "prod-1")
+		if err != nil || got == nil {
+			t.Fatalf("GetByProductID() = %v, %v", got, err)
+		}
+		if got.Quantity() != 3 || got.Version() != deleted+1 {
+			t.Errorf("GetByProductID() = %d v%d, want 3 v%d", got.Quantity(), got.Version(), deleted+1)
+		}
+
+		if err := got.Reserve(1); err != nil {
+			t.Fatalf("Reserve() error = %v", err)
+		}
+		if err := r.InventoryCommands.Update(ctx, got); err != nil {
+			t.Fatalf("Update() after recreating error = %v", err)
+		}
+		got, _ = r.InventoryQueries.GetByProductID(ctx, "prod-1")
+		if got.Quantity() != 3 || got.ReservedQuantity() != 1 || got.Version() != deleted+2 {
+			t.Errorf("GetByProductID() = %d/%d reserved v%d, want 3/1 v%d", got.Quantity(), got.ReservedQuantity(), got.Version(), deleted+2)
+		}
+	},
+}
+
+// createSubscription stores an active subscription created at createdAt
+func createSubscription(t *testing.T, r Repositories, ctx context.Context, id string, createdAt time.Time, eventTypes ...string) *webhook.Subscription {
+	t.Helper()
+	sub := &webhook.Subscription{
+		ID:         id,
+		URL:        "https://example.com/hooks/" + id,
+		EventTypes: eventTypes,
+		Secret:     "whsec_" + id,
+		Active:     true,
+		CreatedAt:  createdAt,
+		UpdatedAt:  createdAt,
+	}
+	if err := r.Webhooks.CreateSubscription(ctx, sub); err != nil {
+		t.Fatalf("CreateSubscription(%s) error = %v", id, err)
+	}
+	return sub
+}
+
+// enqueueDeliveries enqueues a product.created delivery, due at baseTime, of each outbox message
+func enqueueDeliveries(t *testing.T, r Repositories, ctx context.Context, subscriptionID string, messageIDs ...int64) {
+	t.Helper()
+	deliveries := make([]*webhook.Delivery, 0, len(messageIDs))
+	for _, messageID := range messageIDs {
+		deliveries = append(deliveries, &webhook.Delivery{
+			SubscriptionID: subscriptionID,
+			MessageID:      messageID,
+			EventName:      product.EventProductCreated,
+			Payload:        []byte(fmt.Sprintf(`{"id":%d}`, messageID)),
+			NextAttemptAt:  baseTime,
+			CreatedAt:      baseTime,
+		})
+	}
+	if err := r.Webhooks.Enqueue(ctx, deliveries); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+}
+
+// claimDeliveries claims due deliveries with a one minute lease
+func claimDeliveries(t *testing.T, r Repositories, now time.Time) []*webhook.Delivery {
+	t.Helper()
+	deliveries, err := r.Webhooks.Claim(context.Background(), now, time.Minute, 10)
+	if err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	return deliveries
+}
+
+func subscriptionIDs(subscriptions []*webhook.Subscription) []string {
+	ids := make([]string, 0, len(subscriptions))
+	for _, s := range subscriptions {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+func deliveryMessageIDs(deliveries []*webhook.Delivery) []int64 {
+	ids := make([]int64, 0, len(deliveries))
+	for _, d := range deliveries {
+		ids = append(ids, d.MessageID)
+	}
+	return ids
+}
+
+func deliverySubscriptions(deliveries []*webhook.Delivery) []string {
+	ids := make([]string, 0, len(deliveries))
+	for _, d := range deliveries {
+		ids = append(ids, d.SubscriptionID)
+	}
+	return ids
+}
+
+// createNewProduct creates a product through its constructor, so it stores product.created
+func createNewProduct(t *testing.T, r Repositories, ctx context.Context, id string) *product.Product {
+	t.Helper()
+	price, err := product.NewPrice(10.5, "USD")
+	if err != nil {
+		t.Fatalf("NewPrice() error = %v", err)
+	}
+	prod, err := product.NewProduct(id, "Product "+id, price)
+	if err != nil {
+		t.Fatalf("NewProduct() error = %v", err)
+	}
+	if err := r.ProductCommands.Create(ctx, prod); err != nil {
+		t.Fatalf("Create(product %s) error = %v", id, err)
+	}
+	// Commands pull the events of what they wrote once it is stored
+	prod.PullEvents()
+	return prod
+}
+
+// claimOutbox claims due messages with a one minute lease
+func claimOutbox(t *testing.T, r Repositories, now time.Time, limit int) []*outbox.Message {
+	t.Helper()
+	messages, err := r.Outbox.Claim(context.Background(), now, time.Minute, limit)
+	if err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	return messages
+}
+
+func outboxEventNames(messages []*outbox.Message) []string {
+	names := make([]string, 0, len(messages))
+	for _, m := range messages {
+		names = append(names, m.EventName)
+	}
+	return names
+}
+
+func outboxAggregates(messages []*outbox.Message) []string {
+	ids := make([]string, 0, len(messages))
+	for _, m := range messages {
+		ids = append(ids, m.AggregateID)
+	}
+	return ids
+}
+
+// auditTenants numbers the tenants of audit cases within a run
+var auditTenants int
+
+// newAuditTenant returns a tenant ID no earlier run has appended audit entries for
+func newAuditTenant() string {
+	auditTenants++
+	return fmt.Sprintf("audit-%d-%d", time.Now().UnixNano(), auditTenants)
+}
+
+// appendAuditEntry appends an update of a product by actor
+func appendAuditEntry(t *testing.T, r Repositories, ctx context.Context, actor, productID string) *audit.Entry {
+	t.Helper()
+	entry := newAuditEntry(actor, productID)
+	if err := r.Audit.Append(ctx, entry); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	return entry
+}
+
+// newAuditEntry returns an unlinked update of a product by actor
+func newAuditEntry(actor, productID string) *audit.Entry {
+	return &audit.Entry{
+		Actor:         actor,
+		Command:       "UpdateProduct",
+		AggregateType: "product",
+		AggregateID:   productID,
+		Input:         []byte(`{"name":"Renamed"}`),
+		Before:        []byte(`{"name":"Original"}`),
+		After:         []byte(`{"name":"Renamed"}`),
+		RequestID:     "req-1",
+		OccurredAt:    baseTime,
+	}
+}
+
+// forTenant returns a context acting for the tenant
+func forTenant(id string) context.Context {
+	return tenant.NewContext(context.Background(), tenant.Tenant{ID: id})
+}
+
+// assertCatalogEntry checks the stock levels of a product's catalog entry
+func assertCatalogEntry(t *testing.T, r Repositories, productID string, hasInventory bool, quantity, available int) *product.CatalogEntry {
+	t.Helper()
+	entry, err := r.Catalog.GetByProductID(context.Background(), productID)
+	if err != nil {
+		t.Fatalf("GetByProductID() error = %v", err)
+	}
+	if entry == nil {
+		t.Fatalf("GetByProductID(%s) = nil, want entry", productID)
+	}
+	if entry.HasInventory != hasInventory || entry.Quantity != quantity || entry.AvailableQuantity != available {
+		t.Errorf("entry %s = inventory %v, quantity %d, available %d; want %v, %d, %d",
+			productID, entry.HasInventory, entry.Quantity, entry.AvailableQuantity, hasInventory, quantity, available)
+	}
+	return entry
+}
+
+func newProduct(t *testing.T, id string, offset int) *product.Product {
+	t.Helper()
+	price, err := product.NewPrice(10.5, "USD")
+	if err != nil {
+		t.Fatalf("NewPrice() error = %v", err)
+	}
+	createdAt := baseTime.Add(time.Duration(offset) * time.Hour)
+	return product.ReconstructProduct(id, "Product "+id, price, createdAt, createdAt, 1)
+}
+
+func createProduct(t *testing.T, r Repositories, id string, offset int) {
+	t.Helper()
+	if err := r.ProductCommands.Create(context.Background(), newProduct(t, id, offset)); err != nil {
+		t.Fatalf("Create(product %s) error = %v", id, err)
+	}
+}
+
+func newInventory(t *testing.T, productID string, quantity int) *inventory.Inventory {
+	t.Helper()
+	inv, err := inventory.NewInventory("inv-"+productID, productID, quantity, "WH-1")
+	if err != nil {
+		t.Fatalf("NewInventory() error = %v", err)
+	}
+	return inv
+}
+
+func createInventory(t *testing.T, r Repositories, inv *inventory.Inventory) {
+	t.Helper()
+	if err := r.InventoryCommands.Create(context.Background(), inv); err != nil {
+		t.Fatalf("Create(inventory %s) error = %v", inv.ProductID(), err)
+	}
+}
+
+func productIDs(products []*product.Product) []string {
+	ids := make([]string, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID())
+	}
+	return ids
+}
+
+func inventoryProductIDs(inventories []*inventory.Inventory) []string {
+	ids := make([]string, 0, len(inventories))
+	for _, inv := range inventories {
+		ids = append(ids, inv.ProductID())
+	}
+	return ids
+}
@@ -0,0 +1,136 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/sqlite/sqlitegen"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+)
+
+// AuditRepositoryImpl implements the audit.Store interface on SQLite
+type AuditRepositoryImpl struct {
+	db      *sql.DB
+	queries *sqlitegen.Queries
+}
+
+// NewAuditStore creates a new instance of AuditRepositoryImpl
+func NewAuditStore(db *sql.DB) audit.Store {
+	return &AuditRepositoryImpl{
+		db:      db,
+		queries: sqlitegen.New(db),
+	}
+}
+
+// Append links the entry to the end of the tenant's chain and stores it
+// Writing the tenant's chain head takes the write lock, so concurrent appends queue
+// behind one another.
+func (r *AuditRepositoryImpl) Append(ctx context.Context, entry *audit.Entry) error {
+	tenantID := tenant.ID(ctx)
+	return runInTx(ctx, r.db, r.queries, func(q *sqlitegen.Queries) error {
+		head, err := q.LockAuditChainHead(ctx, tenantID)
+		if err != nil {
+			return err
+		}
+		var last *audit.Entry
+		if head.Sequence > 0 {
+			last = &audit.Entry{Sequence: head.Sequence, Hash: head.Hash}
+		}
+		entry.Link(last)
+
+		if err := q.InsertAuditEntry(ctx, sqlitegen.InsertAuditEntryParams{
+			TenantID:      tenantID,
+			Sequence:      entry.Sequence,
+			Actor:         entry.Actor,
+			Command:       entry.Command,
+			AggregateType: entry.AggregateType,
+			AggregateID:   entry.AggregateID,
+			Input:         string(entry.Input),
+			BeforeState:   string(entry.Before),
+			AfterState:    string(entry.After),
+			RequestID:     entry.RequestID,
+			OccurredAt:    entry.OccurredAt.UTC(),
+			PreviousHash:  entry.PreviousHash,
+			Hash:          entry.Hash,
+		}); err != nil {
+			return err
+		}
+		return q.AdvanceAuditChainHead(ctx, sqlitegen.AdvanceAuditChainHeadParams{
+			TenantID: tenantID,
+			Sequence: entry.Sequence,
+			Hash:     entry.Hash,
+		})
+	})
+}
+
+// List returns a page of the entries matching the filter, newest first
+func (r *AuditRepositoryImpl) List(ctx context.Context, filter audit.Filter) ([]*audit.Entry, error) {
+	dbEntries, err := r.queries.ListAuditEntries(ctx, sqlitegen.ListAuditEntriesParams{
+		TenantID:      tenant.ID(ctx),
+		AggregateType: toNullString(filter.AggregateType),
+		AggregateID:   toNullString(filter.AggregateID),
+		Actor:         toNullString(filter.Actor),
+		RowOffset:     int64(filter.Offset),
+		RowLimit:      int64(filter.Limit),
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return toAuditEntries(dbEntries), nil
+}
+
+// Count returns how many entries match the filter
+func (r *AuditRepositoryImpl) Count(ctx context.Context, filter audit.Filter) (int, error) {
+	count, err := r.queries.CountAuditEntries(ctx, sqlitegen.CountAuditEntriesParams{
+		TenantID:      tenant.ID(ctx),
+		AggregateType: toNullString(filter.AggregateType),
+		AggregateID:   toNullString(filter.AggregateID),
+		Actor:         toNullString(filter.Actor),
+	})
+	if err != nil {
+		return 0, wrapError(err)
+	}
+	return int(count), nil
+}
+
+// Chain returns up to limit entries following the given sequence, oldest first
+func (r *AuditRepositoryImpl) Chain(ctx context.Context, afterSequence int64, limit int) ([]*audit.Entry, error) {
+	dbEntries, err := r.queries.ListAuditChain(ctx, sqlitegen.ListAuditChainParams{
+		TenantID:      tenant.ID(ctx),
+		AfterSequence: afterSequence,
+		RowLimit:      int64(limit),
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return toAuditEntries(dbEntries), nil
+}
+
+// toAuditEntries converts database rows to audit entries
+func toAuditEntries(dbEntries []sqlitegen.AuditLog) []*audit.Entry {
+	entries := make([]*audit.Entry, 0, len(dbEntries))
+	for _, dbEntry := range dbEntries {
+		entries = append(entries, toAuditEntry(dbEntry))
+	}
+	return entries
+}
+
+// toAuditEntry converts a database row to an audit entry
+func toAuditEntry(dbEntry sqlitegen.AuditLog) *audit.Entry {
+	return &audit.Entry{
+		Sequence:      dbEntry.Sequence,
+		Actor:         dbEntry.Actor,
+		Command:       dbEntry.Command,
+		AggregateType: dbEntry.AggregateType,
+		AggregateID:   dbEntry.AggregateID,
+		Input:         json.RawMessage(dbEntry.Input),
+		Before:        json.RawMessage(dbEntry.BeforeState),
+		After:         json.RawMessage(dbEntry.AfterState),
+		RequestID:     dbEntry.RequestID,
+		OccurredAt:    dbEntry.OccurredAt.UTC(),
+		PreviousHash:  dbEntry.PreviousHash,
+		Hash:          dbEntry.Hash,
+	}
+}
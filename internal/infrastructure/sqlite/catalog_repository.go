@@ -0,0 +1,75 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/sqlite/sqlitegen"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+)
+
+// CatalogRepositoryImpl serves and rebuilds the product_catalog_view read model on SQLite
+// Database triggers keep the view in step with products and inventory, so it has no write methods
+type CatalogRepositoryImpl struct {
+	db      *sql.DB
+	queries *sqlitegen.Queries
+}
+
+// NewCatalogQueryRepository creates a new instance for query operations
+func NewCatalogQueryRepository(db *sql.DB) product.CatalogQueryRepository {
+	return &CatalogRepositoryImpl{
+		queries: sqlitegen.New(db),
+	}
+}
+
+// NewCatalogProjection creates a new instance that rebuilds the read model
+func NewCatalogProjection(db *sql.DB) product.CatalogProjection {
+	return &CatalogRepositoryImpl{
+		db:      db,
+		queries: sqlitegen.New(db),
+	}
+}
+
+// GetByProductID retrieves the catalog entry of a product
+func (r *CatalogRepositoryImpl) GetByProductID(ctx context.Context, productID string) (*product.CatalogEntry, error) {
+	row, err := r.queries.GetProductCatalogEntry(ctx, sqlitegen.GetProductCatalogEntryParams{
+		TenantID:  tenant.ID(ctx),
+		ProductID: productID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Product not found
+		}
+		return nil, wrapError(err)
+	}
+
+	return &product.CatalogEntry{
+		ProductID:         row.ProductID,
+		Name:              row.Name,
+		PriceAmount:       row.PriceAmount,
+		PriceCurrency:     row.PriceCurrency,
+		HasInventory:      row.HasInventory,
+		Quantity:          int(row.Quantity),
+		ReservedQuantity:  int(row.ReservedQuantity),
+		AvailableQuantity: int(row.AvailableQuantity),
+		Location:          fromNullString(row.Location),
+		CreatedAt:         row.CreatedAt,
+		UpdatedAt:         row.UpdatedAt,
+	}, nil
+}
+
+// Rebuild clears the read model and regenerates it from products and inventory in one transaction
+func (r *CatalogRepositoryImpl) Rebuild(ctx context.Context) (int, error) {
+	var entries int64
+	err := runInTx(ctx, r.db, r.queries, func(q *sqlitegen.Queries) error {
+		if err := q.ClearProductCatalog(ctx); err != nil {
+			return err
+		}
+		var err error
+		entries, err = q.RebuildProductCatalog(ctx)
+		return err
+	})
+	return int(entries), err
+}
@@ -0,0 +1,64 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/migration"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/repotest"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/sqlite"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		return newRepositories(openTestDatabase(t))
+	})
+}
+
+func TestEventSourcedRepositoryContract(t *testing.T) {
+	repotest.RunEventSourced(t, func(t *testing.T) repotest.Repositories {
+		db := openTestDatabase(t)
+		repos := newRepositories(db)
+		repos.InventoryCommands = sqlite.NewEventSourcedInventoryCommandRepository(db, repotest.SnapshotEvery)
+		repos.InventoryQueries = sqlite.NewEventSourcedInventoryQueryRepository(db)
+		repos.UnitOfWork = sqlite.NewEventSourcedUnitOfWork(db, 3, repotest.SnapshotEvery)
+		return repos
+	})
+}
+
+func newRepositories(db *sql.DB) repotest.Repositories {
+	return repotest.Repositories{
+		ProductCommands:   sqlite.NewProductCommandRepository(db),
+		ProductQueries:    sqlite.NewProductQueryRepository(db),
+		InventoryCommands: sqlite.NewInventoryCommandRepository(db),
+		InventoryQueries:  sqlite.NewInventoryQueryRepository(db),
+		Catalog:           sqlite.NewCatalogQueryRepository(db),
+		CatalogProjection: sqlite.NewCatalogProjection(db),
+		Audit:             sqlite.NewAuditStore(db),
+		Outbox:            sqlite.NewOutboxStore(db),
+		Webhooks:          sqlite.NewWebhookStore(db),
+		ProductUnitOfWork: sqlite.NewProductUnitOfWork(db, 3),
+		UnitOfWork:        sqlite.NewUnitOfWork(db, 3),
+	}
+}
+
+// openTestDatabase creates a migrated database file that is removed with the test
+func openTestDatabase(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	m, err := migration.New(db, migration.DriverSQLite)
+	if err != nil {
+		t.Fatalf("migration.New() error = %v", err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatalf("applying migrations: %v", err)
+	}
+	return db
+}
@@ -0,0 +1,30 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+// DriverName is the database/sql driver name of the pure-Go SQLite driver
+const DriverName = "sqlite"
+
+// DSN builds the connection string for a database file
+// Every connection enforces foreign keys, waits for locks instead of failing at once,
+// writes times in SQLite's own format and takes the write lock when a transaction begins.
+func DSN(path string) string {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Set("_time_format", "sqlite")
+	params.Set("_txlock", "immediate")
+	return fmt.Sprintf("file:%s?%s", path, params.Encode())
+}
+
+// Open opens the SQLite database file at path
+func Open(path string) (*sql.DB, error) {
+	return sql.Open(DriverName, DSN(path))
+}
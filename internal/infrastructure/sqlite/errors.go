@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"errors"
+	"strings"
+
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// wrapError classifies SQLite errors by their extended result code
+// Constraint violations are reported under the PostgreSQL constraint names so the
+// mappings registered with apperrors.RegisterConstraint apply to both backends.
+// Errors that are not SQLite errors fall back to apperrors.WrapDatabaseError
+func wrapError(err error) error {
+	return wrapErrorWithForeignKey(err, "")
+}
+
+// wrapErrorWithForeignKey is wrapError for writes whose only foreign key is foreignKey
+// SQLite does not name the foreign key that failed, so the caller supplies it
+func wrapErrorWithForeignKey(err error, foreignKey string) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*apperrors.AppError); ok {
+		return err
+	}
+
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return apperrors.WrapDatabaseError(err)
+	}
+
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		constraint, column := uniqueConstraint(sqliteErr.Error())
+		return apperrors.ConstraintError(err, constraint, column, apperrors.CodeConflict, "Resource already exists")
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY, sqlite3.SQLITE_CONSTRAINT_TRIGGER:
+		// The tenant triggers raise "FOREIGN KEY constraint failed" for cross-tenant references
+		return apperrors.ConstraintError(err, foreignKey, "", apperrors.CodeInvalidInput, "Referenced resource does not exist")
+	case sqlite3.SQLITE_CONSTRAINT_CHECK:
+		constraint := constraintDetail(sqliteErr.Error(), "CHECK constraint failed: ")
+		return apperrors.ConstraintError(err, constraint, "", apperrors.CodeInvalidInput, "Value violates a data rule")
+	case sqlite3.SQLITE_CONSTRAINT_NOTNULL:
+		_, column := splitColumn(constraintDetail(sqliteErr.Error(), "NOT NULL constraint failed: "))
+		return apperrors.ConstraintError(err, "", column, apperrors.CodeInvalidInput, "Required value is missing")
+	case sqlite3.SQLITE_CANTOPEN, sqlite3.SQLITE_IOERR:
+		return apperrors.Wrap(err, apperrors.CodeDatabaseConnection, "Database connection error")
+	}
+	return apperrors.Wrap(err, apperrors.CodeDatabaseError, "Database operation failed")
+}
+
+// uniqueConstraint derives the PostgreSQL default constraint name from a UNIQUE failure
+// "UNIQUE constraint failed: products.id" becomes products_pkey and
+// "UNIQUE constraint failed: inventory.product_id" becomes inventory_product_id_key
+func uniqueConstraint(message string) (constraint, column string) {
+	detail := constraintDetail(message, "UNIQUE constraint failed: ")
+	if detail == "" {
+		return "", ""
+	}
+
+	var table string
+	var columns []string
+	for _, qualified := range strings.Split(detail, ", ") {
+		t, c := splitColumn(qualified)
+		table = t
+		columns = append(columns, c)
+	}
+	column = strings.Join(columns, ",")
+
+	if len(columns) == 1 && columns[0] == "id" {
+		return table + "_pkey", column
+	}
+	return table + "_" + strings.Join(columns, "_") + "_key", column
+}
+
+// constraintDetail returns what SQLite reports after prefix, without the result code suffix
+func constraintDetail(message, prefix string) string {
+	i := strings.Index(message, prefix)
+	if i < 0 {
+		return ""
+	}
+	detail := message[i+len(prefix):]
+	if j := strings.LastIndex(detail, " ("); j >= 0 {
+		detail = detail[:j]
+	}
+	return strings.TrimSpace(detail)
+}
+
+// splitColumn splits a "table.column" reference
+func splitColumn(qualified string) (table, column string) {
+	if i := strings.LastIndex(qualified, "."); i >= 0 {
+		return qualified[:i], qualified[i+1:]
+	}
+	return "", qualified
+}
+
+// isBusy reports whether SQLite gave up waiting for another connection's lock
+func isBusy(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code() & 0xff // primary result code
+	return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+}
@@ -0,0 +1,85 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/sqlite/sqlitegen"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/idempotency"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+)
+
+// IdempotencyRepositoryImpl implements the idempotency.Store interface on SQLite
+type IdempotencyRepositoryImpl struct {
+	queries *sqlitegen.Queries
+}
+
+// NewIdempotencyStore creates a new instance of IdempotencyRepositoryImpl
+func NewIdempotencyStore(db *sql.DB) idempotency.Store {
+	return &IdempotencyRepositoryImpl{
+		queries: sqlitegen.New(db),
+	}
+}
+
+// Reserve claims the key unless an unexpired record already holds it
+func (r *IdempotencyRepositoryImpl) Reserve(ctx context.Context, record *idempotency.Record) (bool, error) {
+	rows, err := r.queries.ReserveIdempotencyKey(ctx, sqlitegen.ReserveIdempotencyKeyParams{
+		TenantID:    tenant.ID(ctx),
+		Key:         record.Key,
+		Fingerprint: record.Fingerprint,
+		CreatedAt:   record.CreatedAt.UTC(),
+		ExpiresAt:   record.ExpiresAt.UTC(),
+	})
+	if err != nil {
+		return false, wrapError(err)
+	}
+	return rows > 0, nil
+}
+
+// Get retrieves the unexpired record for a key
+func (r *IdempotencyRepositoryImpl) Get(ctx context.Context, key string) (*idempotency.Record, error) {
+	dbRecord, err := r.queries.GetIdempotencyKey(ctx, sqlitegen.GetIdempotencyKeyParams{
+		TenantID: tenant.ID(ctx),
+		Key:      key,
+		Now:      time.Now().UTC(),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Key not found or expired
+		}
+		return nil, wrapError(err)
+	}
+
+	return &idempotency.Record{
+		Key:          dbRecord.Key,
+		Fingerprint:  dbRecord.Fingerprint,
+		StatusCode:   int(dbRecord.StatusCode.Int64),
+		ContentType:  dbRecord.ContentType,
+		ResponseBody: dbRecord.ResponseBody,
+		CreatedAt:    dbRecord.CreatedAt,
+		ExpiresAt:    dbRecord.ExpiresAt,
+	}, nil
+}
+
+// Complete stores the response of a reserved request
+func (r *IdempotencyRepositoryImpl) Complete(ctx context.Context, key string, statusCode int, contentType string, responseBody []byte) error {
+	err := r.queries.CompleteIdempotencyKey(ctx, sqlitegen.CompleteIdempotencyKeyParams{
+		TenantID:     tenant.ID(ctx),
+		Key:          key,
+		StatusCode:   sql.NullInt64{Int64: int64(statusCode), Valid: true},
+		ContentType:  contentType,
+		ResponseBody: responseBody,
+		CompletedAt:  toNullTime(time.Now()),
+	})
+	return wrapError(err)
+}
+
+// Release removes a reservation so the request can be retried
+func (r *IdempotencyRepositoryImpl) Release(ctx context.Context, key string) error {
+	return wrapError(r.queries.DeleteIdempotencyKey(ctx, sqlitegen.DeleteIdempotencyKeyParams{
+		TenantID: tenant.ID(ctx),
+		Key:      key,
+	}))
+}
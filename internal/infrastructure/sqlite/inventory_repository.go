@@ -0,0 +1,320 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/sqlite/sqlitegen"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// InventoryRepositoryImpl implements both InventoryCommandRepository and InventoryQueryRepository on SQLite
+type InventoryRepositoryImpl struct {
+	db      *sql.DB
+	queries *sqlitegen.Queries
+}
+
+// NewInventoryCommandRepository creates a new instance for command operations
+func NewInventoryCommandRepository(db *sql.DB) inventory.InventoryCommandRepository {
+	return &InventoryRepositoryImpl{
+		db:      db,
+		queries: sqlitegen.New(db),
+	}
+}
+
+// NewInventoryQueryRepository creates a new instance for query operations
+func NewInventoryQueryRepository(db *sql.DB) inventory.InventoryQueryRepository {
+	return &InventoryRepositoryImpl{
+		db:      db,
+		queries: sqlitegen.New(db),
+	}
+}
+
+// Create stores a new inventory record in the database
+// The inventory's pending events are stored in the outbox in the same transaction
+func (r *InventoryRepositoryImpl) Create(ctx context.Context, inv *inventory.Inventory) error {
+	metadata, err := json.Marshal(inv.Metadata())
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.CodeInternalError, "failed to encode inventory metadata")
+	}
+
+	params := sqlitegen.CreateInventoryParams{
+		TenantID:            tenant.ID(ctx),
+		ID:                  inv.ID(),
+		ProductID:           inv.ProductID(),
+		Quantity:            int64(inv.Quantity()),
+		ReservedQuantity:    int64(inv.ReservedQuantity()),
+		Location:            toNullString(inv.Location()),
+		CreatedAt:           inv.CreatedAt().UTC(),
+		UpdatedAt:           inv.UpdatedAt().UTC(),
+		StockPolicy:         string(inv.StockPolicy().Type()),
+		BackorderLimit:      int64(inv.StockPolicy().BackorderLimit()),
+		BackorderedQuantity: int64(inv.BackorderedQuantity()),
+		Version:             int64(inv.Version()),
+		Metadata:            string(metadata),
+	}
+	return runInTx(ctx, r.db, r.queries, func(q *sqlitegen.Queries) error {
+		if err := q.CreateInventory(ctx, params); err != nil {
+			return wrapErrorWithForeignKey(err, "fk_product")
+		}
+		return appendOutbox(ctx, q, inventory.AggregateType, inv.PendingEvents())
+	})
+}
+
+// GetByProductID retrieves inventory by product ID from the database
+func (r *InventoryRepositoryImpl) GetByProductID(ctx context.Context, productID string) (*inventory.Inventory, error) {
+	dbInventory, err := r.queries.GetInventoryByProductID(ctx, sqlitegen.GetInventoryByProductIDParams{
+		TenantID:  tenant.ID(ctx),
+		ProductID: productID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Inventory not found
+		}
+		return nil, wrapError(err)
+	}
+
+	return r.toDomainInventory(dbInventory), nil
+}
+
+// GetByProductIDs retrieves the inventory records for the given products in a single query
+func (r *InventoryRepositoryImpl) GetByProductIDs(ctx context.Context, productIDs []string) ([]*inventory.Inventory, error) {
+	if len(productIDs) == 0 {
+		return []*inventory.Inventory{}, nil
+	}
+
+	dbInventories, err := r.queries.ListInventoryByProductIDs(ctx, sqlitegen.ListInventoryByProductIDsParams{
+		TenantID:   tenant.ID(ctx),
+		ProductIds: productIDs,
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return r.toDomainInventories(dbInventories), nil
+}
+
+// ListByLocation retrieves all inventory records stored at a location
+func (r *InventoryRepositoryImpl) ListByLocation(ctx context.Context, location string) ([]*inventory.Inventory, error) {
+	dbInventories, err := r.queries.ListInventoryByLocation(ctx, sqlitegen.ListInventoryByLocationParams{
+		TenantID: tenant.ID(ctx),
+		Location: toNullString(location),
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return r.toDomainInventories(dbInventories), nil
+}
+
+// List retrieves a page of inventory records matching the filter
+func (r *InventoryRepositoryImpl) List(ctx context.Context, filter inventory.InventoryFilter) ([]*inventory.Inventory, error) {
+	location, availableBelow, updatedSince := toInventoryFilterParams(filter)
+	sortBy := filter.SortBy
+	if sortBy == "" {
+		sortBy = inventory.SortByProductID
+	}
+
+	dbInventories, err := r.queries.ListInventory(ctx, sqlitegen.ListInventoryParams{
+		TenantID:       tenant.ID(ctx),
+		Location:       location,
+		ZeroStockOnly:  filter.ZeroStockOnly,
+		AvailableBelow: availableBelow,
+		UpdatedSince:   updatedSince,
+		SortBy:         string(sortBy),
+		SortDesc:       filter.SortDesc,
+		RowLimit:       int64(filter.Limit),
+		RowOffset:      int64(filter.Offset),
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return r.toDomainInventories(dbInventories), nil
+}
+
+// Count returns how many inventory records match the filter
+func (r *InventoryRepositoryImpl) Count(ctx context.Context, filter inventory.InventoryFilter) (int, error) {
+	location, availableBelow, updatedSince := toInventoryFilterParams(filter)
+
+	count, err := r.queries.CountInventory(ctx, sqlitegen.CountInventoryParams{
+		TenantID:       tenant.ID(ctx),
+		Location:       location,
+		ZeroStockOnly:  filter.ZeroStockOnly,
+		AvailableBelow: availableBelow,
+		UpdatedSince:   updatedSince,
+	})
+	if err != nil {
+		return 0, wrapError(err)
+	}
+	return int(count), nil
+}
+
+// Update updates an existing inventory record in the database
+// The update only applies if the stored version still matches the entity's version
+func (r *InventoryRepositoryImpl) Update(ctx context.Context, inv *inventory.Inventory) error {
+	return runInTx(ctx, r.db, r.queries, func(q *sqlitegen.Queries) error {
+		return r.update(ctx, q, inv)
+	})
+}
+
+// UpdateBatch updates several inventory records within a single transaction
+// A version mismatch on any record rolls back the whole batch
+func (r *InventoryRepositoryImpl) UpdateBatch(ctx context.Context, inventories []*inventory.Inventory) error {
+	return runInTx(ctx, r.db, r.queries, func(q *sqlitegen.Queries) error {
+		for _, inv := range inventories {
+			if err := r.update(ctx, q, inv); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// update writes an inventory record with a version check using the given queries,
+// followed by its pending events in the outbox
+func (r *InventoryRepositoryImpl) update(ctx context.Context, q *sqlitegen.Queries, inv *inventory.Inventory) error {
+	metadata, err := json.Marshal(inv.Metadata())
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.CodeInternalError, "failed to encode inventory metadata")
+	}
+
+	rows, err := q.UpdateInventory(ctx, sqlitegen.UpdateInventoryParams{
+		TenantID:            tenant.ID(ctx),
+		ProductID:           inv.ProductID(),
+		Quantity:            int64(inv.Quantity()),
+		ReservedQuantity:    int64(inv.ReservedQuantity()),
+		Location:            toNullString(inv.Location()),
+		UpdatedAt:           inv.UpdatedAt().UTC(),
+		StockPolicy:         string(inv.StockPolicy().Type()),
+		BackorderLimit:      int64(inv.StockPolicy().BackorderLimit()),
+		BackorderedQuantity: int64(inv.BackorderedQuantity()),
+		Metadata:            string(metadata),
+		ExpectedVersion:     int64(inv.Version()),
+	})
+	if err != nil {
+		return wrapError(err)
+	}
+	if rows == 0 {
+		return inventory.ErrConcurrentModification
+	}
+	return appendOutbox(ctx, q, inventory.AggregateType, inv.PendingEvents())
+}
+
+// Delete removes an inventory record from the database and stores its pending events in the outbox
+// The delete only applies if the stored version still matches the entity's version
+// and nothing is reserved or backordered
+func (r *InventoryRepositoryImpl) Delete(ctx context.Context, inv *inventory.Inventory) error {
+	return runInTx(ctx, r.db, r.queries, func(q *sqlitegen.Queries) error {
+		rows, err := q.DeleteInventory(ctx, sqlitegen.DeleteInventoryParams{
+			TenantID:        tenant.ID(ctx),
+			ProductID:       inv.ProductID(),
+			ExpectedVersion: int64(inv.Version()),
+		})
+		if err != nil {
+			return wrapError(err)
+		}
+		if rows == 0 {
+			return inventory.ErrConcurrentModification
+		}
+		return appendOutbox(ctx, q, inventory.AggregateType, inv.PendingEvents())
+	})
+}
+
+// AdjustStock adjusts the stock quantity for a product
+func (r *InventoryRepositoryImpl) AdjustStock(ctx context.Context, productID string, adjustment int) error {
+	err := r.queries.AdjustInventoryQuantity(ctx, sqlitegen.AdjustInventoryQuantityParams{
+		TenantID:   tenant.ID(ctx),
+		Adjustment: int64(adjustment),
+		UpdatedAt:  time.Now().UTC(),
+		ProductID:  productID,
+	})
+	return wrapError(err)
+}
+
+// toDomainInventories converts database inventory models to domain inventory entities
+func (r *InventoryRepositoryImpl) toDomainInventories(dbInventories []sqlitegen.Inventory) []*inventory.Inventory {
+	inventories := make([]*inventory.Inventory, 0, len(dbInventories))
+	for _, dbInventory := range dbInventories {
+		inventories = append(inventories, r.toDomainInventory(dbInventory))
+	}
+	return inventories
+}
+
+// toDomainInventory converts a database inventory model to a domain inventory entity
+func (r *InventoryRepositoryImpl) toDomainInventory(dbInventory sqlitegen.Inventory) *inventory.Inventory {
+	return inventory.ReconstructInventory(
+		dbInventory.ID,
+		dbInventory.ProductID,
+		int(dbInventory.Quantity),
+		int(dbInventory.ReservedQuantity),
+		int(dbInventory.BackorderedQuantity),
+		r.toDomainStockPolicy(dbInventory),
+		fromNullString(dbInventory.Location),
+		r.toDomainMetadata(dbInventory.Metadata),
+		dbInventory.CreatedAt,
+		dbInventory.UpdatedAt,
+		int(dbInventory.Version),
+	)
+}
+
+// toDomainMetadata decodes the metadata column, treating malformed JSON as empty
+func (r *InventoryRepositoryImpl) toDomainMetadata(raw string) map[string]string {
+	metadata := map[string]string{}
+	if raw == "" {
+		return metadata
+	}
+	if err := json.Unmarshal([]byte(raw), &metadata); err != nil {
+		return map[string]string{}
+	}
+	return metadata
+}
+
+// toDomainStockPolicy rebuilds the stock policy value object from its persisted columns
+// Rows that fail validation fall back to the strict policy so stock is never oversold
+func (r *InventoryRepositoryImpl) toDomainStockPolicy(dbInventory sqlitegen.Inventory) inventory.StockPolicy {
+	policy, err := inventory.NewStockPolicy(
+		inventory.StockPolicyType(dbInventory.StockPolicy),
+		int(dbInventory.BackorderLimit),
+	)
+	if err != nil {
+		return inventory.StrictStockPolicy()
+	}
+	return policy
+}
+
+// toInventoryFilterParams converts the filter conditions to nullable query parameters
+func toInventoryFilterParams(filter inventory.InventoryFilter) (sql.NullString, sql.NullInt64, sql.NullTime) {
+	availableBelow := sql.NullInt64{}
+	if filter.AvailableBelow != nil {
+		availableBelow = sql.NullInt64{Int64: int64(*filter.AvailableBelow), Valid: true}
+	}
+	return toNullString(filter.Location), availableBelow, toNullTime(filter.UpdatedSince)
+}
+
+// toNullString converts a string to sql.NullString
+func toNullString(s string) sql.NullString {
+	return sql.NullString{
+		String: s,
+		Valid:  s != "",
+	}
+}
+
+// fromNullString converts sql.NullString to string
+func fromNullString(ns sql.NullString) string {
+	if ns.Valid {
+		return ns.String
+	}
+	return ""
+}
+
+// toNullTime converts a time to a UTC sql.NullTime, treating the zero time as NULL
+// Times are always written in UTC so their text form sorts chronologically
+func toNullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{
+		Time:  t.UTC(),
+		Valid: !t.IsZero(),
+	}
+}
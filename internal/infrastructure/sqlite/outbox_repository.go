@@ -0,0 +1,162 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/event"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/sqlite/sqlitegen"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/outbox"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// OutboxRepositoryImpl implements the outbox.Store interface on SQLite
+type OutboxRepositoryImpl struct {
+	queries *sqlitegen.Queries
+}
+
+// NewOutboxStore creates a new instance of OutboxRepositoryImpl
+func NewOutboxStore(db *sql.DB) outbox.Store {
+	return &OutboxRepositoryImpl{
+		queries: sqlitegen.New(db),
+	}
+}
+
+// Claim leases the oldest due message of each aggregate, oldest first
+func (r *OutboxRepositoryImpl) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*outbox.Message, error) {
+	dbMessages, err := r.queries.ClaimOutboxMessages(ctx, sqlitegen.ClaimOutboxMessagesParams{
+		LockedUntil: sql.NullTime{Time: now.Add(lease).UTC(), Valid: true},
+		Now:         now.UTC(),
+		BatchSize:   int64(limit),
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	messages := toOutboxMessages(dbMessages)
+	sort.Slice(messages, func(i, j int) bool { return messages[i].ID < messages[j].ID })
+	return messages, nil
+}
+
+// MarkPublished records that a claimed message was published
+func (r *OutboxRepositoryImpl) MarkPublished(ctx context.Context, id int64, at time.Time) error {
+	return wrapError(r.queries.MarkOutboxMessagePublished(ctx, sqlitegen.MarkOutboxMessagePublishedParams{
+		ID:          id,
+		PublishedAt: sql.NullTime{Time: at.UTC(), Valid: true},
+	}))
+}
+
+// MarkFailed records a failed attempt to publish a claimed message
+func (r *OutboxRepositoryImpl) MarkFailed(ctx context.Context, id int64, attempts int, nextAttemptAt time.Time, lastError string, dead bool) error {
+	status := outbox.StatusPending
+	if dead {
+		status = outbox.StatusDead
+	}
+	return wrapError(r.queries.MarkOutboxMessageFailed(ctx, sqlitegen.MarkOutboxMessageFailedParams{
+		ID:            id,
+		Attempts:      int64(attempts),
+		NextAttemptAt: nextAttemptAt.UTC(),
+		LastError:     lastError,
+		Status:        string(status),
+	}))
+}
+
+// List returns the messages of the tenant of the context, oldest first
+func (r *OutboxRepositoryImpl) List(ctx context.Context, filter outbox.Filter) ([]*outbox.Message, error) {
+	dbMessages, err := r.queries.ListOutboxMessages(ctx, sqlitegen.ListOutboxMessagesParams{
+		TenantID:  tenant.ID(ctx),
+		Status:    toNullString(string(filter.Status)),
+		RowOffset: int64(filter.Offset),
+		RowLimit:  int64(filter.Limit),
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return toOutboxMessages(dbMessages), nil
+}
+
+// Count returns how many messages of the tenant of the context match the filter
+func (r *OutboxRepositoryImpl) Count(ctx context.Context, filter outbox.Filter) (int, error) {
+	count, err := r.queries.CountOutboxMessages(ctx, sqlitegen.CountOutboxMessagesParams{
+		TenantID: tenant.ID(ctx),
+		Status:   toNullString(string(filter.Status)),
+	})
+	if err != nil {
+		return 0, wrapError(err)
+	}
+	return int(count), nil
+}
+
+// Replay makes a dead message of the tenant of the context pending again
+func (r *OutboxRepositoryImpl) Replay(ctx context.Context, id int64, now time.Time) (bool, error) {
+	rows, err := r.queries.ReplayOutboxMessage(ctx, sqlitegen.ReplayOutboxMessageParams{
+		TenantID:      tenant.ID(ctx),
+		ID:            id,
+		NextAttemptAt: now.UTC(),
+	})
+	if err != nil {
+		return false, wrapError(err)
+	}
+	return rows > 0, nil
+}
+
+// DeletePublished removes messages published before the given time
+func (r *OutboxRepositoryImpl) DeletePublished(ctx context.Context, before time.Time) (int, error) {
+	rows, err := r.queries.DeletePublishedOutboxMessages(ctx, sql.NullTime{Time: before.UTC(), Valid: true})
+	if err != nil {
+		return 0, wrapError(err)
+	}
+	return int(rows), nil
+}
+
+// appendOutbox stores events as pending messages of the tenant of the context using q
+// Repositories call it with the queries of the transaction that stores the aggregate
+func appendOutbox(ctx context.Context, q *sqlitegen.Queries, aggregateType string, events []event.Event) error {
+	messages, err := outbox.NewMessages(tenant.ID(ctx), aggregateType, events, time.Now().UTC())
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.CodeInternalError, "failed to encode event")
+	}
+	for _, message := range messages {
+		err := q.InsertOutboxMessage(ctx, sqlitegen.InsertOutboxMessageParams{
+			TenantID:      message.TenantID,
+			AggregateType: message.AggregateType,
+			AggregateID:   message.AggregateID,
+			EventName:     message.EventName,
+			Payload:       string(message.Payload),
+			OccurredAt:    message.OccurredAt,
+			Status:        string(message.Status),
+			NextAttemptAt: message.NextAttemptAt,
+			CreatedAt:     message.CreatedAt,
+		})
+		if err != nil {
+			return wrapError(err)
+		}
+	}
+	return nil
+}
+
+// toOutboxMessages converts database outbox rows to messages
+func toOutboxMessages(dbMessages []sqlitegen.Outbox) []*outbox.Message {
+	messages := make([]*outbox.Message, 0, len(dbMessages))
+	for _, dbMessage := range dbMessages {
+		messages = append(messages, &outbox.Message{
+			ID:            dbMessage.ID,
+			TenantID:      dbMessage.TenantID,
+			AggregateType: dbMessage.AggregateType,
+			AggregateID:   dbMessage.AggregateID,
+			EventName:     dbMessage.EventName,
+			Payload:       json.RawMessage(dbMessage.Payload),
+			OccurredAt:    dbMessage.OccurredAt,
+			Status:        outbox.Status(dbMessage.Status),
+			Attempts:      int(dbMessage.Attempts),
+			NextAttemptAt: dbMessage.NextAttemptAt,
+			LastError:     dbMessage.LastError,
+			CreatedAt:     dbMessage.CreatedAt,
+			PublishedAt:   dbMessage.PublishedAt.Time,
+		})
+	}
+	return messages
+}
@@ -0,0 +1,161 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/sqlite/sqlitegen"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+)
+
+// ProductRepositoryImpl implements both ProductCommandRepository and ProductQueryRepository on SQLite
+type ProductRepositoryImpl struct {
+	db      *sql.DB
+	queries *sqlitegen.Queries
+}
+
+// NewProductCommandRepository creates a new instance for command operations
+func NewProductCommandRepository(db *sql.DB) product.ProductCommandRepository {
+	return &ProductRepositoryImpl{
+		db:      db,
+		queries: sqlitegen.New(db),
+	}
+}
+
+// NewProductQueryRepository creates a new instance for query operations
+func NewProductQueryRepository(db *sql.DB) product.ProductQueryRepository {
+	return &ProductRepositoryImpl{
+		queries: sqlitegen.New(db),
+	}
+}
+
+// Create stores a new product in the database
+// The product's pending events are stored in the outbox in the same transaction
+func (r *ProductRepositoryImpl) Create(ctx context.Context, prod *product.Product) error {
+	params := sqlitegen.CreateProductParams{
+		TenantID:      tenant.ID(ctx),
+		ID:            prod.ID(),
+		Name:          prod.Name(),
+		PriceAmount:   prod.Price().Amount(),
+		PriceCurrency: prod.Price().Currency(),
+		CreatedAt:     prod.CreatedAt().UTC(),
+		UpdatedAt:     prod.UpdatedAt().UTC(),
+		Version:       int64(prod.Version()),
+	}
+	return runInTx(ctx, r.db, r.queries, func(q *sqlitegen.Queries) error {
+		if err := q.CreateProduct(ctx, params); err != nil {
+			return err
+		}
+		return appendOutbox(ctx, q, product.AggregateType, prod.PendingEvents())
+	})
+}
+
+// GetByID retrieves a product by its ID from the database
+func (r *ProductRepositoryImpl) GetByID(ctx context.Context, id string) (*product.Product, error) {
+	dbProduct, err := r.queries.GetProductByID(ctx, sqlitegen.GetProductByIDParams{
+		TenantID: tenant.ID(ctx),
+		ID:       id,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Product not found
+		}
+		return nil, wrapError(err)
+	}
+
+	return r.toDomainProduct(dbProduct)
+}
+
+// GetByIDs retrieves the products with the given identifiers in a single query
+func (r *ProductRepositoryImpl) GetByIDs(ctx context.Context, ids []string) ([]*product.Product, error) {
+	if len(ids) == 0 {
+		return []*product.Product{}, nil
+	}
+
+	dbProducts, err := r.queries.ListProductsByIDs(ctx, sqlitegen.ListProductsByIDsParams{
+		TenantID: tenant.ID(ctx),
+		Ids:      ids,
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return r.toDomainProducts(dbProducts)
+}
+
+// Update updates an existing product in the database
+// The update only applies if the stored version still matches the entity's version,
+// and stores the product's pending events in the outbox in the same transaction
+func (r *ProductRepositoryImpl) Update(ctx context.Context, prod *product.Product) error {
+	params := sqlitegen.UpdateProductParams{
+		TenantID:        tenant.ID(ctx),
+		ID:              prod.ID(),
+		Name:            prod.Name(),
+		PriceAmount:     prod.Price().Amount(),
+		PriceCurrency:   prod.Price().Currency(),
+		UpdatedAt:       prod.UpdatedAt().UTC(),
+		ExpectedVersion: int64(prod.Version()),
+	}
+	return runInTx(ctx, r.db, r.queries, func(q *sqlitegen.Queries) error {
+		rows, err := q.UpdateProduct(ctx, params)
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return product.ErrConcurrentModification
+		}
+		return appendOutbox(ctx, q, product.AggregateType, prod.PendingEvents())
+	})
+}
+
+// Delete removes a product from the database
+func (r *ProductRepositoryImpl) Delete(ctx context.Context, id string) error {
+	return wrapError(r.queries.DeleteProduct(ctx, sqlitegen.DeleteProductParams{
+		TenantID: tenant.ID(ctx),
+		ID:       id,
+	}))
+}
+
+// List retrieves all products with pagination
+func (r *ProductRepositoryImpl) List(ctx context.Context, limit, offset int) ([]*product.Product, error) {
+	dbProducts, err := r.queries.ListProducts(ctx, sqlitegen.ListProductsParams{
+		TenantID: tenant.ID(ctx),
+		Limit:    int64(limit),
+		Offset:   int64(offset),
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return r.toDomainProducts(dbProducts)
+}
+
+// toDomainProducts converts database product models to domain product entities
+func (r *ProductRepositoryImpl) toDomainProducts(dbProducts []sqlitegen.Product) ([]*product.Product, error) {
+	products := make([]*product.Product, 0, len(dbProducts))
+	for _, dbProduct := range dbProducts {
+		prod, err := r.toDomainProduct(dbProduct)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, prod)
+	}
+	return products, nil
+}
+
+// toDomainProduct converts a database product model to a domain product entity
+func (r *ProductRepositoryImpl) toDomainProduct(dbProduct sqlitegen.Product) (*product.Product, error) {
+	price, err := product.NewPrice(dbProduct.PriceAmount, dbProduct.PriceCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	return product.ReconstructProduct(
+		dbProduct.ID,
+		dbProduct.Name,
+		price,
+		dbProduct.CreatedAt,
+		dbProduct.UpdatedAt,
+		int(dbProduct.Version),
+	), nil
+}
@@ -0,0 +1,160 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/sqlite/sqlitegen"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+)
+
+// StocktakeRepositoryImpl implements both StocktakeCommandRepository and StocktakeQueryRepository on SQLite
+type StocktakeRepositoryImpl struct {
+	db      *sql.DB
+	queries *sqlitegen.Queries
+}
+
+// NewStocktakeCommandRepository creates a new instance for command operations
+func NewStocktakeCommandRepository(db *sql.DB) inventory.StocktakeCommandRepository {
+	return &StocktakeRepositoryImpl{
+		db:      db,
+		queries: sqlitegen.New(db),
+	}
+}
+
+// NewStocktakeQueryRepository creates a new instance for query operations
+func NewStocktakeQueryRepository(db *sql.DB) inventory.StocktakeQueryRepository {
+	return &StocktakeRepositoryImpl{
+		db:      db,
+		queries: sqlitegen.New(db),
+	}
+}
+
+// Create stores a new stocktake session and its snapshot lines in one transaction
+func (r *StocktakeRepositoryImpl) Create(ctx context.Context, st *inventory.Stocktake) error {
+	return runInTx(ctx, r.db, r.queries, func(q *sqlitegen.Queries) error {
+		err := q.CreateStocktake(ctx, sqlitegen.CreateStocktakeParams{
+			TenantID:  tenant.ID(ctx),
+			ID:        st.ID(),
+			Status:    string(st.Status()),
+			CreatedAt: st.CreatedAt().UTC(),
+			UpdatedAt: st.UpdatedAt().UTC(),
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, line := range st.Lines() {
+			err := q.CreateStocktakeLine(ctx, sqlitegen.CreateStocktakeLineParams{
+				TenantID:         tenant.ID(ctx),
+				StocktakeID:      st.ID(),
+				ProductID:        line.ProductID(),
+				Location:         toNullString(line.Location()),
+				ExpectedQuantity: int64(line.ExpectedQuantity()),
+			})
+			if err != nil {
+				return wrapErrorWithForeignKey(err, "fk_stocktake_product")
+			}
+		}
+		return nil
+	})
+}
+
+// Update persists counts, approvals and the status of a stocktake session
+func (r *StocktakeRepositoryImpl) Update(ctx context.Context, st *inventory.Stocktake) error {
+	return runInTx(ctx, r.db, r.queries, func(q *sqlitegen.Queries) error {
+		return r.save(ctx, q, st)
+	})
+}
+
+// Apply stores the applied session; its adjusted inventories are saved by the caller
+func (r *StocktakeRepositoryImpl) Apply(ctx context.Context, st *inventory.Stocktake) error {
+	return runInTx(ctx, r.db, r.queries, func(q *sqlitegen.Queries) error {
+		return r.save(ctx, q, st)
+	})
+}
+
+// GetByID retrieves a stocktake session with its lines
+func (r *StocktakeRepositoryImpl) GetByID(ctx context.Context, id string) (*inventory.Stocktake, error) {
+	dbStocktake, err := r.queries.GetStocktakeByID(ctx, sqlitegen.GetStocktakeByIDParams{
+		TenantID: tenant.ID(ctx),
+		ID:       id,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Stocktake not found
+		}
+		return nil, wrapError(err)
+	}
+
+	dbLines, err := r.queries.ListStocktakeLines(ctx, sqlitegen.ListStocktakeLinesParams{
+		TenantID:    tenant.ID(ctx),
+		StocktakeID: id,
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	lines := make([]*inventory.StocktakeLine, 0, len(dbLines))
+	for _, dbLine := range dbLines {
+		lines = append(lines, inventory.ReconstructStocktakeLine(
+			dbLine.ProductID,
+			fromNullString(dbLine.Location),
+			int(dbLine.ExpectedQuantity),
+			int(dbLine.CountedQuantity.Int64),
+			dbLine.CountedQuantity.Valid,
+			int(dbLine.CountAttempts),
+			dbLine.Approved,
+			dbLine.CountedAt.Time,
+		))
+	}
+
+	return inventory.ReconstructStocktake(
+		dbStocktake.ID,
+		inventory.StocktakeStatus(dbStocktake.Status),
+		lines,
+		dbStocktake.CreatedAt,
+		dbStocktake.UpdatedAt,
+		dbStocktake.AppliedAt.Time,
+	), nil
+}
+
+// save writes the session header and every line using the given queries
+// Only an open session is written, so one applied or cancelled concurrently fails with ErrStocktakeNotOpen.
+func (r *StocktakeRepositoryImpl) save(ctx context.Context, q *sqlitegen.Queries, st *inventory.Stocktake) error {
+	rows, err := q.UpdateStocktake(ctx, sqlitegen.UpdateStocktakeParams{
+		TenantID:       tenant.ID(ctx),
+		ID:             st.ID(),
+		Status:         string(st.Status()),
+		UpdatedAt:      st.UpdatedAt().UTC(),
+		AppliedAt:      toNullTime(st.AppliedAt()),
+		ExpectedStatus: string(inventory.StocktakeStatusOpen),
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return inventory.ErrStocktakeNotOpen
+	}
+
+	for _, line := range st.Lines() {
+		err := q.UpdateStocktakeLine(ctx, sqlitegen.UpdateStocktakeLineParams{
+			TenantID:    tenant.ID(ctx),
+			StocktakeID: st.ID(),
+			ProductID:   line.ProductID(),
+			CountedQuantity: sql.NullInt64{
+				Int64: int64(line.CountedQuantity()),
+				Valid: line.IsCounted(),
+			},
+			CountAttempts: int64(line.CountAttempts()),
+			Approved:      line.IsApproved(),
+			CountedAt:     toNullTime(line.CountedAt()),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/sqlite/sqlitegen"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// busyRetryDelay is the base pause before retrying a transaction that found the database locked
+const busyRetryDelay = 20 * time.Millisecond
+
+// UnitOfWorkImpl implements inventory.UnitOfWork on top of SQLite transactions
+// SQLite transactions are always serializable, so the requested isolation level is not used
+type UnitOfWorkImpl struct {
+	db          *sql.DB
+	maxAttempts int
+	// eventSourced binds the inventory repositories to the inventory event streams
+	eventSourced  bool
+	snapshotEvery int
+}
+
+// NewUnitOfWork creates a new instance of UnitOfWorkImpl
+// maxAttempts bounds how often a transaction runs when the database stays locked
+func NewUnitOfWork(db *sql.DB, maxAttempts int) inventory.UnitOfWork {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &UnitOfWorkImpl{
+		db:          db,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// NewEventSourcedUnitOfWork creates a UnitOfWorkImpl whose inventory repositories work on the
+// inventory event streams, snapshotting every snapshotEvery events
+func NewEventSourcedUnitOfWork(db *sql.DB, maxAttempts, snapshotEvery int) inventory.UnitOfWork {
+	u := NewUnitOfWork(db, maxAttempts).(*UnitOfWorkImpl)
+	u.eventSourced = true
+	u.snapshotEvery = snapshotEvery
+	return u
+}
+
+// Do runs fn in a transaction
+func (u *UnitOfWorkImpl) Do(ctx context.Context, fn func(ctx context.Context, repos inventory.TxRepositories) error) error {
+	return u.DoWithIsolation(ctx, inventory.IsolationDefault, fn)
+}
+
+// DoWithIsolation runs fn in a transaction
+// Busy and locked errors roll back and run fn again with a growing pause
+func (u *UnitOfWorkImpl) DoWithIsolation(
+	ctx context.Context,
+	level inventory.IsolationLevel,
+	fn func(ctx context.Context, repos inventory.TxRepositories) error,
+) error {
+	return retryBusy(ctx, u.maxAttempts, func() error { return u.run(ctx, fn) })
+}
+
+// retryBusy runs fn up to maxAttempts times while it fails because the database is busy or locked
+func retryBusy(ctx context.Context, maxAttempts int, fn func() error) error {
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err = fn()
+		if err == nil || !isBusy(err) || attempt == maxAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt) * busyRetryDelay):
+		}
+	}
+	return err
+}
+
+// run executes fn in a single transaction
+func (u *UnitOfWorkImpl) run(ctx context.Context, fn func(ctx context.Context, repos inventory.TxRepositories) error) error {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.CodeTransactionFailed, "Failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	if err := fn(ctx, u.txRepositories(sqlitegen.New(tx))); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return apperrors.Wrap(err, apperrors.CodeTransactionFailed, "Failed to commit transaction")
+	}
+	return nil
+}
+
+// txRepositories binds every repository to the transaction's queries, with the
+// event-sourced inventory repository when the unit of work uses it
+func (u *UnitOfWorkImpl) txRepositories(q *sqlitegen.Queries) inventory.TxRepositories {
+	repos := newTxRepositories(q)
+	if u.eventSourced {
+		inventoryRepo := newEventSourcedInventoryRepository(nil, q, u.snapshotEvery)
+		repos.InventoryCommands = inventoryRepo
+		repos.InventoryQueries = inventoryRepo
+	}
+	return repos
+}
+
+// newTxRepositories binds every repository to the transaction's queries
+// The repositories have no database handle, so they never open nested transactions
+func newTxRepositories(q *sqlitegen.Queries) inventory.TxRepositories {
+	inventoryRepo := &InventoryRepositoryImpl{queries: q}
+	stocktakeRepo := &StocktakeRepositoryImpl{queries: q}
+	valuationRepo := &ValuationRepositoryImpl{queries: q}
+	return inventory.TxRepositories{
+		InventoryCommands: inventoryRepo,
+		InventoryQueries:  inventoryRepo,
+		StocktakeCommands: stocktakeRepo,
+		StocktakeQueries:  stocktakeRepo,
+		ValuationCommands: valuationRepo,
+		ValuationQueries:  valuationRepo,
+		Audit:             &AuditRepositoryImpl{queries: q},
+	}
+}
+
+// ProductUnitOfWorkImpl implements product.UnitOfWork on top of SQLite transactions
+type ProductUnitOfWorkImpl struct {
+	db          *sql.DB
+	maxAttempts int
+}
+
+// NewProductUnitOfWork creates a new instance of ProductUnitOfWorkImpl
+// maxAttempts bounds how often a transaction runs when the database stays locked
+func NewProductUnitOfWork(db *sql.DB, maxAttempts int) product.UnitOfWork {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &ProductUnitOfWorkImpl{db: db, maxAttempts: maxAttempts}
+}
+
+// Do runs fn in a transaction
+// Busy and locked errors roll back and run fn again with a growing pause
+func (u *ProductUnitOfWorkImpl) Do(ctx context.Context, fn func(ctx context.Context, repos product.TxRepositories) error) error {
+	return retryBusy(ctx, u.maxAttempts, func() error {
+		return runInTx(ctx, u.db, sqlitegen.New(u.db), func(q *sqlitegen.Queries) error {
+			return fn(ctx, product.TxRepositories{
+				ProductCommands: &ProductRepositoryImpl{queries: q},
+				Audit:           &AuditRepositoryImpl{queries: q},
+			})
+		})
+	})
+}
+
+// runInTx runs fn in its own transaction, or directly when the repository
+// is already bound to a unit of work's transaction
+func runInTx(ctx context.Context, db *sql.DB, queries *sqlitegen.Queries, fn func(q *sqlitegen.Queries) error) error {
+	if db == nil {
+		return wrapError(fn(queries))
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.CodeTransactionFailed, "Failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	if err := fn(queries.WithTx(tx)); err != nil {
+		return wrapError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return apperrors.Wrap(err, apperrors.CodeTransactionFailed, "Failed to commit transaction")
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/sqlite/sqlitegen"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+)
+
+// ValuationRepositoryImpl implements both ValuationCommandRepository and ValuationQueryRepository on SQLite
+type ValuationRepositoryImpl struct {
+	db      *sql.DB
+	queries *sqlitegen.Queries
+}
+
+// NewValuationCommandRepository creates a new instance for command operations
+func NewValuationCommandRepository(db *sql.DB) inventory.ValuationCommandRepository {
+	return &ValuationRepositoryImpl{
+		db:      db,
+		queries: sqlitegen.New(db),
+	}
+}
+
+// NewValuationQueryRepository creates a new instance for query operations
+func NewValuationQueryRepository(db *sql.DB) inventory.ValuationQueryRepository {
+	return &ValuationRepositoryImpl{
+		db:      db,
+		queries: sqlitegen.New(db),
+	}
+}
+
+// Save upserts the valuation totals and every loaded cost layer in one transaction
+func (r *ValuationRepositoryImpl) Save(ctx context.Context, v *inventory.StockValuation) error {
+	return runInTx(ctx, r.db, r.queries, func(q *sqlitegen.Queries) error {
+		err := q.UpsertInventoryValuation(ctx, sqlitegen.UpsertInventoryValuationParams{
+			TenantID:      tenant.ID(ctx),
+			ProductID:     v.ProductID(),
+			CostingMethod: string(v.Method()),
+			Currency:      v.Currency(),
+			Quantity:      int64(v.Quantity()),
+			TotalCost:     v.TotalCost(),
+			ConsumedCost:  v.ConsumedCost(),
+			UpdatedAt:     v.UpdatedAt().UTC(),
+		})
+		if err != nil {
+			return wrapErrorWithForeignKey(err, "fk_inventory_valuations_product")
+		}
+
+		for _, layer := range v.Layers() {
+			err := q.UpsertCostLayer(ctx, sqlitegen.UpsertCostLayerParams{
+				TenantID:          tenant.ID(ctx),
+				ID:                layer.ID(),
+				ProductID:         v.ProductID(),
+				ReceivedQuantity:  int64(layer.ReceivedQuantity()),
+				RemainingQuantity: int64(layer.RemainingQuantity()),
+				UnitCost:          layer.UnitCost(),
+				ReceivedAt:        layer.ReceivedAt().UTC(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete removes the valuation of a product; its cost layers cascade
+func (r *ValuationRepositoryImpl) Delete(ctx context.Context, productID string) error {
+	return wrapError(r.queries.DeleteInventoryValuation(ctx, sqlitegen.DeleteInventoryValuationParams{
+		TenantID:  tenant.ID(ctx),
+		ProductID: productID,
+	}))
+}
+
+// GetByProductID retrieves a valuation with its open cost layers
+func (r *ValuationRepositoryImpl) GetByProductID(ctx context.Context, productID string) (*inventory.StockValuation, error) {
+	dbValuation, err := r.queries.GetInventoryValuation(ctx, sqlitegen.GetInventoryValuationParams{
+		TenantID:  tenant.ID(ctx),
+		ProductID: productID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Valuation not found
+		}
+		return nil, wrapError(err)
+	}
+
+	dbLayers, err := r.queries.ListOpenCostLayers(ctx, sqlitegen.ListOpenCostLayersParams{
+		TenantID:  tenant.ID(ctx),
+		ProductID: productID,
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return r.toDomainValuation(dbValuation, dbLayers), nil
+}
+
+// List retrieves every valuation with its open cost layers
+func (r *ValuationRepositoryImpl) List(ctx context.Context) ([]*inventory.StockValuation, error) {
+	dbValuations, err := r.queries.ListInventoryValuations(ctx, tenant.ID(ctx))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	dbLayers, err := r.queries.ListAllOpenCostLayers(ctx, tenant.ID(ctx))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	layersByProduct := make(map[string][]sqlitegen.InventoryCostLayer)
+	for _, dbLayer := range dbLayers {
+		layersByProduct[dbLayer.ProductID] = append(layersByProduct[dbLayer.ProductID], dbLayer)
+	}
+
+	valuations := make([]*inventory.StockValuation, 0, len(dbValuations))
+	for _, dbValuation := range dbValuations {
+		valuations = append(valuations, r.toDomainValuation(dbValuation, layersByProduct[dbValuation.ProductID]))
+	}
+	return valuations, nil
+}
+
+// toDomainValuation converts database rows to a domain valuation
+func (r *ValuationRepositoryImpl) toDomainValuation(dbValuation sqlitegen.InventoryValuation, dbLayers []sqlitegen.InventoryCostLayer) *inventory.StockValuation {
+	layers := make([]*inventory.CostLayer, 0, len(dbLayers))
+	for _, dbLayer := range dbLayers {
+		layers = append(layers, inventory.ReconstructCostLayer(
+			dbLayer.ID,
+			int(dbLayer.ReceivedQuantity),
+			int(dbLayer.RemainingQuantity),
+			dbLayer.UnitCost,
+			dbLayer.ReceivedAt,
+		))
+	}
+
+	return inventory.ReconstructStockValuation(
+		dbValuation.ProductID,
+		inventory.CostingMethod(dbValuation.CostingMethod),
+		dbValuation.Currency,
+		int(dbValuation.Quantity),
+		dbValuation.TotalCost,
+		dbValuation.ConsumedCost,
+		layers,
+		dbValuation.UpdatedAt,
+	)
+}
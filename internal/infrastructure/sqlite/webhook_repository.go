@@ -0,0 +1,309 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/sqlite/sqlitegen"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/tenant"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/webhook"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// WebhookRepositoryImpl implements the webhook.Store interface on SQLite
+type WebhookRepositoryImpl struct {
+	queries *sqlitegen.Queries
+}
+
+// NewWebhookStore creates a new instance of WebhookRepositoryImpl
+func NewWebhookStore(db *sql.DB) webhook.Store {
+	return &WebhookRepositoryImpl{
+		queries: sqlitegen.New(db),
+	}
+}
+
+// CreateSubscription stores a new subscription for the tenant of the context
+func (r *WebhookRepositoryImpl) CreateSubscription(ctx context.Context, s *webhook.Subscription) error {
+	eventTypes, err := json.Marshal(s.EventTypes)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.CodeInternalError, "failed to encode event types")
+	}
+	err = r.queries.CreateWebhookSubscription(ctx, sqlitegen.CreateWebhookSubscriptionParams{
+		ID:                  s.ID,
+		TenantID:            tenant.ID(ctx),
+		Url:                 s.URL,
+		EventTypes:          string(eventTypes),
+		Secret:              s.Secret,
+		Active:              s.Active,
+		ConsecutiveFailures: int64(s.ConsecutiveFailures),
+		DisabledAt:          toNullTime(s.DisabledAt),
+		CreatedAt:           s.CreatedAt,
+		UpdatedAt:           s.UpdatedAt,
+	})
+	return wrapError(err)
+}
+
+// GetSubscription returns a subscription of the tenant of the context, or nil if there is none
+func (r *WebhookRepositoryImpl) GetSubscription(ctx context.Context, id string) (*webhook.Subscription, error) {
+	dbSubscription, err := r.queries.GetWebhookSubscription(ctx, sqlitegen.GetWebhookSubscriptionParams{
+		TenantID: tenant.ID(ctx),
+		ID:       id,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Subscription not found
+		}
+		return nil, wrapError(err)
+	}
+	return toWebhookSubscription(dbSubscription)
+}
+
+// ListSubscriptions returns the subscriptions of the tenant of the context, oldest first
+func (r *WebhookRepositoryImpl) ListSubscriptions(ctx context.Context) ([]*webhook.Subscription, error) {
+	dbSubscriptions, err := r.queries.ListWebhookSubscriptions(ctx, tenant.ID(ctx))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	subscriptions := make([]*webhook.Subscription, 0, len(dbSubscriptions))
+	for _, dbSubscription := range dbSubscriptions {
+		s, err := toWebhookSubscription(dbSubscription)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, s)
+	}
+	return subscriptions, nil
+}
+
+// UpdateSubscription stores the changeable fields of a subscription
+func (r *WebhookRepositoryImpl) UpdateSubscription(ctx context.Context, s *webhook.Subscription) error {
+	eventTypes, err := json.Marshal(s.EventTypes)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.CodeInternalError, "failed to encode event types")
+	}
+	err = r.queries.UpdateWebhookSubscription(ctx, sqlitegen.UpdateWebhookSubscriptionParams{
+		TenantID:            tenant.ID(ctx),
+		ID:                  s.ID,
+		Url:                 s.URL,
+		EventTypes:          string(eventTypes),
+		Secret:              s.Secret,
+		Active:              s.Active,
+		ConsecutiveFailures: int64(s.ConsecutiveFailures),
+		DisabledAt:          toNullTime(s.DisabledAt),
+		UpdatedAt:           s.UpdatedAt,
+	})
+	return wrapError(err)
+}
+
+// DeleteSubscription removes a subscription with its deliveries
+func (r *WebhookRepositoryImpl) DeleteSubscription(ctx context.Context, id string) (bool, error) {
+	rows, err := r.queries.DeleteWebhookSubscription(ctx, sqlitegen.DeleteWebhookSubscriptionParams{
+		TenantID: tenant.ID(ctx),
+		ID:       id,
+	})
+	if err != nil {
+		return false, wrapError(err)
+	}
+	return rows > 0, nil
+}
+
+// Enqueue stores pending deliveries, skipping messages their subscription already has
+func (r *WebhookRepositoryImpl) Enqueue(ctx context.Context, deliveries []*webhook.Delivery) error {
+	for _, d := range deliveries {
+		err := r.queries.InsertWebhookDelivery(ctx, sqlitegen.InsertWebhookDeliveryParams{
+			TenantID:       tenant.ID(ctx),
+			SubscriptionID: d.SubscriptionID,
+			MessageID:      d.MessageID,
+			EventName:      d.EventName,
+			Payload:        string(d.Payload),
+			Status:         string(webhook.DeliveryPending),
+			NextAttemptAt:  d.NextAttemptAt.UTC(),
+			CreatedAt:      d.CreatedAt.UTC(),
+		})
+		if err != nil {
+			return wrapError(err)
+		}
+	}
+	return nil
+}
+
+// Claim leases due deliveries of active subscriptions, oldest first
+func (r *WebhookRepositoryImpl) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*webhook.Delivery, error) {
+	dbDeliveries, err := r.queries.ClaimWebhookDeliveries(ctx, sqlitegen.ClaimWebhookDeliveriesParams{
+		LockedUntil: sql.NullTime{Time: now.Add(lease).UTC(), Valid: true},
+		Now:         now.UTC(),
+		BatchSize:   int64(limit),
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	deliveries := toWebhookDeliveries(dbDeliveries)
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	return deliveries, nil
+}
+
+// MarkDelivered records the successful attempt of a claimed delivery
+func (r *WebhookRepositoryImpl) MarkDelivered(ctx context.Context, id int64, attempts int, attempt webhook.Attempt) error {
+	err := r.queries.MarkWebhookDeliveryDelivered(ctx, sqlitegen.MarkWebhookDeliveryDeliveredParams{
+		ID:             id,
+		Attempts:       int64(attempts),
+		LastStatusCode: int64(attempt.StatusCode),
+		LastAttemptAt:  sql.NullTime{Time: attempt.At.UTC(), Valid: true},
+		DeliveredAt:    sql.NullTime{Time: attempt.At.UTC(), Valid: true},
+	})
+	return wrapError(err)
+}
+
+// MarkFailed records a failed attempt of a claimed delivery
+func (r *WebhookRepositoryImpl) MarkFailed(ctx context.Context, id int64, attempts int, attempt webhook.Attempt, nextAttemptAt time.Time, failed bool) error {
+	status := webhook.DeliveryPending
+	if failed {
+		status = webhook.DeliveryFailed
+	}
+	err := r.queries.MarkWebhookDeliveryFailed(ctx, sqlitegen.MarkWebhookDeliveryFailedParams{
+		ID:             id,
+		Status:         string(status),
+		Attempts:       int64(attempts),
+		LastStatusCode: int64(attempt.StatusCode),
+		LastError:      attempt.Error,
+		LastAttemptAt:  sql.NullTime{Time: attempt.At.UTC(), Valid: true},
+		NextAttemptAt:  nextAttemptAt.UTC(),
+	})
+	return wrapError(err)
+}
+
+// RecordSuccess resets the consecutive failures of a subscription
+func (r *WebhookRepositoryImpl) RecordSuccess(ctx context.Context, subscriptionID string) error {
+	err := r.queries.ResetWebhookSubscriptionFailures(ctx, sqlitegen.ResetWebhookSubscriptionFailuresParams{
+		TenantID: tenant.ID(ctx),
+		ID:       subscriptionID,
+	})
+	return wrapError(err)
+}
+
+// RecordFailure counts a failed attempt and disables the subscription after disableAfter in a row
+func (r *WebhookRepositoryImpl) RecordFailure(ctx context.Context, subscriptionID string, disableAfter int, now time.Time) (bool, error) {
+	row, err := r.queries.IncrementWebhookSubscriptionFailures(ctx, sqlitegen.IncrementWebhookSubscriptionFailuresParams{
+		TenantID: tenant.ID(ctx),
+		ID:       subscriptionID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil // Subscription deleted meanwhile
+		}
+		return false, wrapError(err)
+	}
+	if !row.Active || disableAfter <= 0 || int(row.ConsecutiveFailures) < disableAfter {
+		return false, nil
+	}
+	// Only the failure that flips the subscription reports disabling it
+	rows, err := r.queries.DisableWebhookSubscription(ctx, sqlitegen.DisableWebhookSubscriptionParams{
+		TenantID:   tenant.ID(ctx),
+		ID:         subscriptionID,
+		DisabledAt: sql.NullTime{Time: now.UTC(), Valid: true},
+		UpdatedAt:  now.UTC(),
+	})
+	if err != nil {
+		return false, wrapError(err)
+	}
+	return rows > 0, nil
+}
+
+// ListDeliveries returns the delivery log of a subscription, newest first
+func (r *WebhookRepositoryImpl) ListDeliveries(ctx context.Context, subscriptionID string, filter webhook.DeliveryFilter) ([]*webhook.Delivery, error) {
+	dbDeliveries, err := r.queries.ListWebhookDeliveries(ctx, sqlitegen.ListWebhookDeliveriesParams{
+		TenantID:       tenant.ID(ctx),
+		SubscriptionID: subscriptionID,
+		Status:         toNullString(string(filter.Status)),
+		RowOffset:      int64(filter.Offset),
+		RowLimit:       int64(filter.Limit),
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return toWebhookDeliveries(dbDeliveries), nil
+}
+
+// CountDeliveries returns how many deliveries of a subscription match the filter
+func (r *WebhookRepositoryImpl) CountDeliveries(ctx context.Context, subscriptionID string, filter webhook.DeliveryFilter) (int, error) {
+	count, err := r.queries.CountWebhookDeliveries(ctx, sqlitegen.CountWebhookDeliveriesParams{
+		TenantID:       tenant.ID(ctx),
+		SubscriptionID: subscriptionID,
+		Status:         toNullString(string(filter.Status)),
+	})
+	if err != nil {
+		return 0, wrapError(err)
+	}
+	return int(count), nil
+}
+
+// Redeliver makes a delivery of a subscription pending again
+func (r *WebhookRepositoryImpl) Redeliver(ctx context.Context, subscriptionID string, id int64, now time.Time) (bool, error) {
+	rows, err := r.queries.RedeliverWebhookDelivery(ctx, sqlitegen.RedeliverWebhookDeliveryParams{
+		TenantID:       tenant.ID(ctx),
+		SubscriptionID: subscriptionID,
+		ID:             id,
+		NextAttemptAt:  now.UTC(),
+	})
+	if err != nil {
+		return false, wrapError(err)
+	}
+	return rows > 0, nil
+}
+
+// DeleteDelivered removes deliveries delivered before the given time
+func (r *WebhookRepositoryImpl) DeleteDelivered(ctx context.Context, before time.Time) (int, error) {
+	rows, err := r.queries.DeleteDeliveredWebhookDeliveries(ctx, sql.NullTime{Time: before.UTC(), Valid: true})
+	if err != nil {
+		return 0, wrapError(err)
+	}
+	return int(rows), nil
+}
+
+// toWebhookSubscription converts a database subscription row to a subscription
+func toWebhookSubscription(dbSubscription sqlitegen.WebhookSubscription) (*webhook.Subscription, error) {
+	var eventTypes []string
+	if err := json.Unmarshal([]byte(dbSubscription.EventTypes), &eventTypes); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.CodeInternalError, "failed to decode event types")
+	}
+	return &webhook.Subscription{
+		ID:                  dbSubscription.ID,
+		TenantID:            dbSubscription.TenantID,
+		URL:                 dbSubscription.Url,
+		EventTypes:          eventTypes,
+		Secret:              dbSubscription.Secret,
+		Active:              dbSubscription.Active,
+		ConsecutiveFailures: int(dbSubscription.ConsecutiveFailures),
+		DisabledAt:          dbSubscription.DisabledAt.Time,
+		CreatedAt:           dbSubscription.CreatedAt,
+		UpdatedAt:           dbSubscription.UpdatedAt,
+	}, nil
+}
+
+// toWebhookDeliveries converts database delivery rows to deliveries
+func toWebhookDeliveries(dbDeliveries []sqlitegen.WebhookDelivery) []*webhook.Delivery {
+	deliveries := make([]*webhook.Delivery, 0, len(dbDeliveries))
+	for _, d := range dbDeliveries {
+		deliveries = append(deliveries, &webhook.Delivery{
+			ID:             d.ID,
+			TenantID:       d.TenantID,
+			SubscriptionID: d.SubscriptionID,
+			MessageID:      d.MessageID,
+			EventName:      d.EventName,
+			Payload:        json.RawMessage(d.Payload),
+			Status:         webhook.DeliveryStatus(d.Status),
+			Attempts:       int(d.Attempts),
+			NextAttemptAt:  d.NextAttemptAt,
+			LastStatusCode: int(d.LastStatusCode),
+			LastError:      d.LastError,
+			LastAttemptAt:  d.LastAttemptAt.Time,
+			DeliveredAt:    d.DeliveredAt.Time,
+			CreatedAt:      d.CreatedAt,
+		})
+	}
+	return deliveries
+}
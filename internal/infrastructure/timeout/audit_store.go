@@ -0,0 +1,39 @@
+package timeout
+
+import (
+	"context"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/audit"
+)
+
+// AuditStore bounds every call of an audit store
+type AuditStore struct {
+	store   audit.Store
+	timeout time.Duration
+}
+
+// NewAuditStore wraps store so each call fails with CodeQueryTimeout after timeout
+func NewAuditStore(store audit.Store, timeout time.Duration) audit.Store {
+	return &AuditStore{store: store, timeout: timeout}
+}
+
+// Append links the entry to the end of the tenant's chain and stores it
+func (s *AuditStore) Append(ctx context.Context, entry *audit.Entry) error {
+	return call(ctx, s.timeout, func(ctx context.Context) error { return s.store.Append(ctx, entry) })
+}
+
+// List returns a page of the entries matching the filter
+func (s *AuditStore) List(ctx context.Context, filter audit.Filter) ([]*audit.Entry, error) {
+	return query(ctx, s.timeout, func(ctx context.Context) ([]*audit.Entry, error) { return s.store.List(ctx, filter) })
+}
+
+// Count returns how many entries match the filter
+func (s *AuditStore) Count(ctx context.Context, filter audit.Filter) (int, error) {
+	return query(ctx, s.timeout, func(ctx context.Context) (int, error) { return s.store.Count(ctx, filter) })
+}
+
+// Chain returns up to limit entries following the given sequence
+func (s *AuditStore) Chain(ctx context.Context, afterSequence int64, limit int) ([]*audit.Entry, error) {
+	return query(ctx, s.timeout, func(ctx context.Context) ([]*audit.Entry, error) { return s.store.Chain(ctx, afterSequence, limit) })
+}
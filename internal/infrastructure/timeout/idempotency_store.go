@@ -0,0 +1,41 @@
+package timeout
+
+import (
+	"context"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/idempotency"
+)
+
+// IdempotencyStore bounds every call of an idempotency store
+type IdempotencyStore struct {
+	store   idempotency.Store
+	timeout time.Duration
+}
+
+// NewIdempotencyStore wraps store so each call fails with CodeQueryTimeout after timeout
+func NewIdempotencyStore(store idempotency.Store, timeout time.Duration) idempotency.Store {
+	return &IdempotencyStore{store: store, timeout: timeout}
+}
+
+// Reserve claims the key for a new request
+func (s *IdempotencyStore) Reserve(ctx context.Context, record *idempotency.Record) (bool, error) {
+	return query(ctx, s.timeout, func(ctx context.Context) (bool, error) { return s.store.Reserve(ctx, record) })
+}
+
+// Get retrieves the unexpired record for a key
+func (s *IdempotencyStore) Get(ctx context.Context, key string) (*idempotency.Record, error) {
+	return query(ctx, s.timeout, func(ctx context.Context) (*idempotency.Record, error) { return s.store.Get(ctx, key) })
+}
+
+// Complete stores the response of a reserved request
+func (s *IdempotencyStore) Complete(ctx context.Context, key string, statusCode int, contentType string, responseBody []byte) error {
+	return call(ctx, s.timeout, func(ctx context.Context) error {
+		return s.store.Complete(ctx, key, statusCode, contentType, responseBody)
+	})
+}
+
+// Release removes a reservation
+func (s *IdempotencyStore) Release(ctx context.Context, key string) error {
+	return call(ctx, s.timeout, func(ctx context.Context) error { return s.store.Release(ctx, key) })
+}
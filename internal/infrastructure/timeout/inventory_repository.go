@@ -0,0 +1,82 @@
+package timeout
+
+import (
+	"context"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+)
+
+// InventoryCommandRepository bounds every call of an inventory command repository
+type InventoryCommandRepository struct {
+	repo    inventory.InventoryCommandRepository
+	timeout time.Duration
+}
+
+// NewInventoryCommandRepository wraps repo so each call fails with CodeQueryTimeout after timeout
+func NewInventoryCommandRepository(repo inventory.InventoryCommandRepository, timeout time.Duration) inventory.InventoryCommandRepository {
+	return &InventoryCommandRepository{repo: repo, timeout: timeout}
+}
+
+// Create stores a new inventory
+func (r *InventoryCommandRepository) Create(ctx context.Context, inv *inventory.Inventory) error {
+	return call(ctx, r.timeout, func(ctx context.Context) error { return r.repo.Create(ctx, inv) })
+}
+
+// Update stores changes to an inventory
+func (r *InventoryCommandRepository) Update(ctx context.Context, inv *inventory.Inventory) error {
+	return call(ctx, r.timeout, func(ctx context.Context) error { return r.repo.Update(ctx, inv) })
+}
+
+// UpdateBatch stores changes to several inventories
+func (r *InventoryCommandRepository) UpdateBatch(ctx context.Context, inventories []*inventory.Inventory) error {
+	return call(ctx, r.timeout, func(ctx context.Context) error { return r.repo.UpdateBatch(ctx, inventories) })
+}
+
+// Delete removes the inventory of a product
+func (r *InventoryCommandRepository) Delete(ctx context.Context, inv *inventory.Inventory) error {
+	return call(ctx, r.timeout, func(ctx context.Context) error { return r.repo.Delete(ctx, inv) })
+}
+
+// AdjustStock changes the quantity of a product's inventory
+func (r *InventoryCommandRepository) AdjustStock(ctx context.Context, productID string, adjustment int) error {
+	return call(ctx, r.timeout, func(ctx context.Context) error { return r.repo.AdjustStock(ctx, productID, adjustment) })
+}
+
+// InventoryQueryRepository bounds every call of an inventory query repository
+type InventoryQueryRepository struct {
+	repo    inventory.InventoryQueryRepository
+	timeout time.Duration
+}
+
+// NewInventoryQueryRepository wraps repo so each call fails with CodeQueryTimeout after timeout
+func NewInventoryQueryRepository(repo inventory.InventoryQueryRepository, timeout time.Duration) inventory.InventoryQueryRepository {
+	return &InventoryQueryRepository{repo: repo, timeout: timeout}
+}
+
+// GetByProductID retrieves the inventory of a product
+func (r *InventoryQueryRepository) GetByProductID(ctx context.Context, productID string) (*inventory.Inventory, error) {
+	return query(ctx, r.timeout, func(ctx context.Context) (*inventory.Inventory, error) { return r.repo.GetByProductID(ctx, productID) })
+}
+
+// GetByProductIDs retrieves the inventories of several products
+func (r *InventoryQueryRepository) GetByProductIDs(ctx context.Context, productIDs []string) ([]*inventory.Inventory, error) {
+	return query(ctx, r.timeout, func(ctx context.Context) ([]*inventory.Inventory, error) {
+		return r.repo.GetByProductIDs(ctx, productIDs)
+	})
+}
+
+// ListByLocation retrieves the inventories at a location
+func (r *InventoryQueryRepository) ListByLocation(ctx context.Context, location string) ([]*inventory.Inventory, error) {
+	return query(ctx, r.timeout, func(ctx context.Context) ([]*inventory.Inventory, error) { return r.repo.ListByLocation(ctx, location) })
+}
+
+// List retrieves the inventories matching a filter
+func (r *InventoryQueryRepository) List(ctx context.Context, filter inventory.InventoryFilter) ([]*inventory.Inventory, error) {
+	return query(ctx, r.timeout, func(ctx context.Context) ([]*inventory.Inventory, error) { return r.repo.List(ctx, filter) })
+}
+
+// Count counts the inventories matching a filter
+func (r *InventoryQueryRepository) Count(ctx context.Context, filter inventory.InventoryFilter) (int, error) {
+	return query(ctx, r.timeout, func(ctx context.Context) (int, error) { return r.repo.Count(ctx, filter) })
+}
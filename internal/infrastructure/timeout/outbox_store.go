@@ -0,0 +1,56 @@
+package timeout
+
+import (
+	"context"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/outbox"
+)
+
+// OutboxStore bounds every call of an outbox store
+type OutboxStore struct {
+	store   outbox.Store
+	timeout time.Duration
+}
+
+// NewOutboxStore wraps store so each call fails with CodeQueryTimeout after timeout
+func NewOutboxStore(store outbox.Store, timeout time.Duration) outbox.Store {
+	return &OutboxStore{store: store, timeout: timeout}
+}
+
+// Claim leases up to limit due messages
+func (s *OutboxStore) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*outbox.Message, error) {
+	return query(ctx, s.timeout, func(ctx context.Context) ([]*outbox.Message, error) { return s.store.Claim(ctx, now, lease, limit) })
+}
+
+// MarkPublished records that a claimed message was published
+func (s *OutboxStore) MarkPublished(ctx context.Context, id int64, at time.Time) error {
+	return call(ctx, s.timeout, func(ctx context.Context) error { return s.store.MarkPublished(ctx, id, at) })
+}
+
+// MarkFailed records a failed attempt to publish a claimed message
+func (s *OutboxStore) MarkFailed(ctx context.Context, id int64, attempts int, nextAttemptAt time.Time, lastError string, dead bool) error {
+	return call(ctx, s.timeout, func(ctx context.Context) error {
+		return s.store.MarkFailed(ctx, id, attempts, nextAttemptAt, lastError, dead)
+	})
+}
+
+// List returns the messages of the tenant of the context
+func (s *OutboxStore) List(ctx context.Context, filter outbox.Filter) ([]*outbox.Message, error) {
+	return query(ctx, s.timeout, func(ctx context.Context) ([]*outbox.Message, error) { return s.store.List(ctx, filter) })
+}
+
+// Count returns how many messages of the tenant of the context match the filter
+func (s *OutboxStore) Count(ctx context.Context, filter outbox.Filter) (int, error) {
+	return query(ctx, s.timeout, func(ctx context.Context) (int, error) { return s.store.Count(ctx, filter) })
+}
+
+// Replay makes a dead message pending again
+func (s *OutboxStore) Replay(ctx context.Context, id int64, now time.Time) (bool, error) {
+	return query(ctx, s.timeout, func(ctx context.Context) (bool, error) { return s.store.Replay(ctx, id, now) })
+}
+
+// DeletePublished removes messages published before the given time
+func (s *OutboxStore) DeletePublished(ctx context.Context, before time.Time) (int, error) {
+	return query(ctx, s.timeout, func(ctx context.Context) (int, error) { return s.store.DeletePublished(ctx, before) })
+}
@@ -0,0 +1,76 @@
+package timeout
+
+import (
+	"context"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+)
+
+// ProductCommandRepository bounds every call of a product command repository
+type ProductCommandRepository struct {
+	repo    product.ProductCommandRepository
+	timeout time.Duration
+}
+
+// NewProductCommandRepository wraps repo so each call fails with CodeQueryTimeout after timeout
+func NewProductCommandRepository(repo product.ProductCommandRepository, timeout time.Duration) product.ProductCommandRepository {
+	return &ProductCommandRepository{repo: repo, timeout: timeout}
+}
+
+// Create stores a new product
+func (r *ProductCommandRepository) Create(ctx context.Context, p *product.Product) error {
+	return call(ctx, r.timeout, func(ctx context.Context) error { return r.repo.Create(ctx, p) })
+}
+
+// Update stores changes to a product
+func (r *ProductCommandRepository) Update(ctx context.Context, p *product.Product) error {
+	return call(ctx, r.timeout, func(ctx context.Context) error { return r.repo.Update(ctx, p) })
+}
+
+// Delete removes a product
+func (r *ProductCommandRepository) Delete(ctx context.Context, id string) error {
+	return call(ctx, r.timeout, func(ctx context.Context) error { return r.repo.Delete(ctx, id) })
+}
+
+// ProductQueryRepository bounds every call of a product query repository
+type ProductQueryRepository struct {
+	repo    product.ProductQueryRepository
+	timeout time.Duration
+}
+
+// NewProductQueryRepository wraps repo so each call fails with CodeQueryTimeout after timeout
+func NewProductQueryRepository(repo product.ProductQueryRepository, timeout time.Duration) product.ProductQueryRepository {
+	return &ProductQueryRepository{repo: repo, timeout: timeout}
+}
+
+// GetByID retrieves a product by its ID
+func (r *ProductQueryRepository) GetByID(ctx context.Context, id string) (*product.Product, error) {
+	return query(ctx, r.timeout, func(ctx context.Context) (*product.Product, error) { return r.repo.GetByID(ctx, id) })
+}
+
+// GetByIDs retrieves the products with the given IDs
+func (r *ProductQueryRepository) GetByIDs(ctx context.Context, ids []string) ([]*product.Product, error) {
+	return query(ctx, r.timeout, func(ctx context.Context) ([]*product.Product, error) { return r.repo.GetByIDs(ctx, ids) })
+}
+
+// List retrieves a page of products
+func (r *ProductQueryRepository) List(ctx context.Context, limit, offset int) ([]*product.Product, error) {
+	return query(ctx, r.timeout, func(ctx context.Context) ([]*product.Product, error) { return r.repo.List(ctx, limit, offset) })
+}
+
+// CatalogQueryRepository bounds every call of a catalog query repository
+type CatalogQueryRepository struct {
+	repo    product.CatalogQueryRepository
+	timeout time.Duration
+}
+
+// NewCatalogQueryRepository wraps repo so each call fails with CodeQueryTimeout after timeout
+func NewCatalogQueryRepository(repo product.CatalogQueryRepository, timeout time.Duration) product.CatalogQueryRepository {
+	return &CatalogQueryRepository{repo: repo, timeout: timeout}
+}
+
+// GetByProductID retrieves the catalog entry of a product
+func (r *CatalogQueryRepository) GetByProductID(ctx context.Context, productID string) (*product.CatalogEntry, error) {
+	return query(ctx, r.timeout, func(ctx context.Context) (*product.CatalogEntry, error) { return r.repo.GetByProductID(ctx, productID) })
+}
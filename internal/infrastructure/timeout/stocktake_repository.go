@@ -0,0 +1,50 @@
+package timeout
+
+import (
+	"context"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+)
+
+// StocktakeCommandRepository bounds every call of a stocktake command repository
+type StocktakeCommandRepository struct {
+	repo    inventory.StocktakeCommandRepository
+	timeout time.Duration
+}
+
+// NewStocktakeCommandRepository wraps repo so each call fails with CodeQueryTimeout after timeout
+func NewStocktakeCommandRepository(repo inventory.StocktakeCommandRepository, timeout time.Duration) inventory.StocktakeCommandRepository {
+	return &StocktakeCommandRepository{repo: repo, timeout: timeout}
+}
+
+// Create stores a new stocktake session
+func (r *StocktakeCommandRepository) Create(ctx context.Context, st *inventory.Stocktake) error {
+	return call(ctx, r.timeout, func(ctx context.Context) error { return r.repo.Create(ctx, st) })
+}
+
+// Update stores changes to a stocktake session
+func (r *StocktakeCommandRepository) Update(ctx context.Context, st *inventory.Stocktake) error {
+	return call(ctx, r.timeout, func(ctx context.Context) error { return r.repo.Update(ctx, st) })
+}
+
+// Apply posts the approved variances of a stocktake session
+func (r *StocktakeCommandRepository) Apply(ctx context.Context, st *inventory.Stocktake) error {
+	return call(ctx, r.timeout, func(ctx context.Context) error { return r.repo.Apply(ctx, st) })
+}
+
+// StocktakeQueryRepository bounds every call of a stocktake query repository
+type StocktakeQueryRepository struct {
+	repo    inventory.StocktakeQueryRepository
+	timeout time.Duration
+}
+
+// NewStocktakeQueryRepository wraps repo so each call fails with CodeQueryTimeout after timeout
+func NewStocktakeQueryRepository(repo inventory.StocktakeQueryRepository, timeout time.Duration) inventory.StocktakeQueryRepository {
+	return &StocktakeQueryRepository{repo: repo, timeout: timeout}
+}
+
+// GetByID retrieves a stocktake session
+func (r *StocktakeQueryRepository) GetByID(ctx context.Context, id string) (*inventory.Stocktake, error) {
+	return query(ctx, r.timeout, func(ctx context.Context) (*inventory.Stocktake, error) { return r.repo.GetByID(ctx, id) })
+}
@@ -0,0 +1,36 @@
+package timeout
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// call runs fn with a context that expires after timeout
+// A zero timeout runs fn with ctx unchanged. An error caused by the expiry is
+// reported as CodeQueryTimeout, whatever the backend made of the cancellation.
+func call(ctx context.Context, timeout time.Duration, fn func(ctx context.Context) error) error {
+	_, err := query(ctx, timeout, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// query is call for repository methods that return a value
+func query[T any](ctx context.Context, timeout time.Duration, fn func(ctx context.Context) (T, error)) (T, error) {
+	if timeout <= 0 {
+		return fn(ctx)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result, err := fn(callCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		var zero T
+		return zero, apperrors.Wrapf(err, apperrors.CodeQueryTimeout, "Database query timed out after %v", timeout)
+	}
+	return result, err
+}
@@ -0,0 +1,84 @@
+package timeout_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/memory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/infrastructure/timeout"
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// slowProducts blocks every lookup until its context is done
+type slowProducts struct {
+	product.ProductQueryRepository
+}
+
+func (slowProducts) GetByID(ctx context.Context, id string) (*product.Product, error) {
+	<-ctx.Done()
+	return nil, apperrors.WrapDatabaseError(context.Canceled)
+}
+
+func TestQueryRepository_ExpiredCallIsQueryTimeout(t *testing.T) {
+	repo := timeout.NewProductQueryRepository(slowProducts{}, 20*time.Millisecond)
+
+	_, err := repo.GetByID(context.Background(), "p-1")
+	if !apperrors.Is(err, apperrors.CodeQueryTimeout) {
+		t.Fatalf("GetByID() error = %v (%s), want %s", err, apperrors.GetCode(err), apperrors.CodeQueryTimeout)
+	}
+}
+
+func TestQueryRepository_CallerCancellationIsKept(t *testing.T) {
+	repo := timeout.NewProductQueryRepository(slowProducts{}, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	_, err := repo.GetByID(ctx, "p-1")
+	if !apperrors.Is(err, apperrors.CodeQueryCanceled) {
+		t.Fatalf("GetByID() error = %v (%s), want %s", err, apperrors.GetCode(err), apperrors.CodeQueryCanceled)
+	}
+}
+
+func TestQueryRepository_FastCallsPassThrough(t *testing.T) {
+	db := memory.NewDatabase()
+	price, err := product.NewPrice(10, "USD")
+	if err != nil {
+		t.Fatalf("NewPrice() error = %v", err)
+	}
+	p, err := product.NewProduct("p-1", "Widget", price)
+	if err != nil {
+		t.Fatalf("NewProduct() error = %v", err)
+	}
+	if err := timeout.NewProductCommandRepository(memory.NewProductCommandRepository(db), time.Second).Create(context.Background(), p); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	for _, d := range []time.Duration{0, time.Second} {
+		got, err := timeout.NewProductQueryRepository(memory.NewProductQueryRepository(db), d).GetByID(context.Background(), "p-1")
+		if err != nil || got == nil || got.ID() != "p-1" {
+			t.Fatalf("GetByID() with timeout %v = %v, %v", d, got, err)
+		}
+	}
+}
+
+func TestUnitOfWork_BoundsTransactionRepositories(t *testing.T) {
+	uow := timeout.NewUnitOfWork(memory.NewUnitOfWork(memory.NewDatabase()), time.Second)
+
+	err := uow.Do(context.Background(), func(ctx context.Context, repos inventory.TxRepositories) error {
+		if _, ok := repos.InventoryCommands.(*timeout.InventoryCommandRepository); !ok {
+			t.Errorf("InventoryCommands = %T, want a timeout repository", repos.InventoryCommands)
+		}
+		inv, err := repos.InventoryQueries.GetByProductID(ctx, "missing")
+		if err != nil || inv != nil {
+			t.Errorf("GetByProductID() = %v, %v; want nil, nil", inv, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package timeout
+
+import (
+	"context"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/product"
+)
+
+// UnitOfWork bounds every repository call made inside a unit of work
+// The transaction as a whole is not bounded, so a unit making many calls can outlast the timeout
+type UnitOfWork struct {
+	uow     inventory.UnitOfWork
+	timeout time.Duration
+}
+
+// NewUnitOfWork wraps uow so its transaction-scoped repositories time out like the others
+func NewUnitOfWork(uow inventory.UnitOfWork, timeout time.Duration) inventory.UnitOfWork {
+	return &UnitOfWork{uow: uow, timeout: timeout}
+}
+
+// Do runs fn with the configured default isolation level
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos inventory.TxRepositories) error) error {
+	return u.uow.Do(ctx, u.bound(fn))
+}
+
+// DoWithIsolation runs fn with the given isolation level
+func (u *UnitOfWork) DoWithIsolation(ctx context.Context, level inventory.IsolationLevel, fn func(ctx context.Context, repos inventory.TxRepositories) error) error {
+	return u.uow.DoWithIsolation(ctx, level, u.bound(fn))
+}
+
+// bound hands fn transaction-scoped repositories that time out
+func (u *UnitOfWork) bound(fn func(ctx context.Context, repos inventory.TxRepositories) error) func(ctx context.Context, repos inventory.TxRepositories) error {
+	return func(ctx context.Context, repos inventory.TxRepositories) error {
+		return fn(ctx, inventory.TxRepositories{
+			InventoryCommands: NewInventoryCommandRepository(repos.InventoryCommands, u.timeout),
+			InventoryQueries:  NewInventoryQueryRepository(repos.InventoryQueries, u.timeout),
+			StocktakeCommands: NewStocktakeCommandRepository(repos.StocktakeCommands, u.timeout),
+			StocktakeQueries:  NewStocktakeQueryRepository(repos.StocktakeQueries, u.timeout),
+			ValuationCommands: NewValuationCommandRepository(repos.ValuationCommands, u.timeout),
+			ValuationQueries:  NewValuationQueryRepository(repos.ValuationQueries, u.timeout),
+			Audit:             NewAuditStore(repos.Audit, u.timeout),
+		})
+	}
+}
+
+// ProductUnitOfWork bounds every repository call made inside a product unit of work
+type ProductUnitOfWork struct {
+	uow     product.UnitOfWork
+	timeout time.Duration
+}
+
+// NewProductUnitOfWork wraps uow so its transaction-scoped repositories time out like the others
+func NewProductUnitOfWork(uow product.UnitOfWork, timeout time.Duration) product.UnitOfWork {
+	return &ProductUnitOfWork{uow: uow, timeout: timeout}
+}
+
+// Do runs fn with transaction-scoped repositories that time out
+func (u *ProductUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos product.TxRepositories) error) error {
+	return u.uow.Do(ctx, func(ctx context.Context, repos product.TxRepositories) error {
+		return fn(ctx, product.TxRepositories{
+			ProductCommands: NewProductCommandRepository(repos.ProductCommands, u.timeout),
+			Audit:           NewAuditStore(repos.Audit, u.timeout),
+		})
+	})
+}
@@ -0,0 +1,52 @@
+package timeout
+
+import (
+	"context"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/inventory"
+)
+
+// ValuationCommandRepository bounds every call of a valuation command repository
+type ValuationCommandRepository struct {
+	repo    inventory.ValuationCommandRepository
+	timeout time.Duration
+}
+
+// NewValuationCommandRepository wraps repo so each call fails with CodeQueryTimeout after timeout
+func NewValuationCommandRepository(repo inventory.ValuationCommandRepository, timeout time.Duration) inventory.ValuationCommandRepository {
+	return &ValuationCommandRepository{repo: repo, timeout: timeout}
+}
+
+// Save stores the valuation of a product
+func (r *ValuationCommandRepository) Save(ctx context.Context, v *inventory.StockValuation) error {
+	return call(ctx, r.timeout, func(ctx context.Context) error { return r.repo.Save(ctx, v) })
+}
+
+// Delete removes the valuation of a product
+func (r *ValuationCommandRepository) Delete(ctx context.Context, productID string) error {
+	return call(ctx, r.timeout, func(ctx context.Context) error { return r.repo.Delete(ctx, productID) })
+}
+
+// ValuationQueryRepository bounds every call of a valuation query repository
+type ValuationQueryRepository struct {
+	repo    inventory.ValuationQueryRepository
+	timeout time.Duration
+}
+
+// NewValuationQueryRepository wraps repo so each call fails with CodeQueryTimeout after timeout
+func NewValuationQueryRepository(repo inventory.ValuationQueryRepository, timeout time.Duration) inventory.ValuationQueryRepository {
+	return &ValuationQueryRepository{repo: repo, timeout: timeout}
+}
+
+// GetByProductID retrieves the valuation of a product
+func (r *ValuationQueryRepository) GetByProductID(ctx context.Context, productID string) (*inventory.StockValuation, error) {
+	return query(ctx, r.timeout, func(ctx context.Context) (*inventory.StockValuation, error) {
+		return r.repo.GetByProductID(ctx, productID)
+	})
+}
+
+// List retrieves every product valuation
+func (r *ValuationQueryRepository) List(ctx context.Context) ([]*inventory.StockValuation, error) {
+	return query(ctx, r.timeout, func(ctx context.Context) ([]*inventory.StockValuation, error) { return r.repo.List(ctx) })
+}
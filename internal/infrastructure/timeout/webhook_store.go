@@ -0,0 +1,100 @@
+package timeout
+
+import (
+	"context"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/shared/webhook"
+)
+
+// WebhookStore bounds every call of a webhook store
+type WebhookStore struct {
+	store   webhook.Store
+	timeout time.Duration
+}
+
+// NewWebhookStore wraps store so each call fails with CodeQueryTimeout after timeout
+func NewWebhookStore(store webhook.Store, timeout time.Duration) webhook.Store {
+	return &WebhookStore{store: store, timeout: timeout}
+}
+
+// CreateSubscription stores a new subscription
+func (s *WebhookStore) CreateSubscription(ctx context.Context, sub *webhook.Subscription) error {
+	return call(ctx, s.timeout, func(ctx context.Context) error { return s.store.CreateSubscription(ctx, sub) })
+}
+
+// GetSubscription returns a subscription, or nil if there is none
+func (s *WebhookStore) GetSubscription(ctx context.Context, id string) (*webhook.Subscription, error) {
+	return query(ctx, s.timeout, func(ctx context.Context) (*webhook.Subscription, error) { return s.store.GetSubscription(ctx, id) })
+}
+
+// ListSubscriptions returns the subscriptions of the tenant of the context
+func (s *WebhookStore) ListSubscriptions(ctx context.Context) ([]*webhook.Subscription, error) {
+	return query(ctx, s.timeout, func(ctx context.Context) ([]*webhook.Subscription, error) { return s.store.ListSubscriptions(ctx) })
+}
+
+// UpdateSubscription stores the changeable fields of a subscription
+func (s *WebhookStore) UpdateSubscription(ctx context.Context, sub *webhook.Subscription) error {
+	return call(ctx, s.timeout, func(ctx context.Context) error { return s.store.UpdateSubscription(ctx, sub) })
+}
+
+// DeleteSubscription removes a subscription with its deliveries
+func (s *WebhookStore) DeleteSubscription(ctx context.Context, id string) (bool, error) {
+	return query(ctx, s.timeout, func(ctx context.Context) (bool, error) { return s.store.DeleteSubscription(ctx, id) })
+}
+
+// Enqueue stores pending deliveries
+func (s *WebhookStore) Enqueue(ctx context.Context, deliveries []*webhook.Delivery) error {
+	return call(ctx, s.timeout, func(ctx context.Context) error { return s.store.Enqueue(ctx, deliveries) })
+}
+
+// Claim leases up to limit due deliveries
+func (s *WebhookStore) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*webhook.Delivery, error) {
+	return query(ctx, s.timeout, func(ctx context.Context) ([]*webhook.Delivery, error) { return s.store.Claim(ctx, now, lease, limit) })
+}
+
+// MarkDelivered records the successful attempt of a claimed delivery
+func (s *WebhookStore) MarkDelivered(ctx context.Context, id int64, attempts int, attempt webhook.Attempt) error {
+	return call(ctx, s.timeout, func(ctx context.Context) error { return s.store.MarkDelivered(ctx, id, attempts, attempt) })
+}
+
+// MarkFailed records a failed attempt of a claimed delivery
+func (s *WebhookStore) MarkFailed(ctx context.Context, id int64, attempts int, attempt webhook.Attempt, nextAttemptAt time.Time, failed bool) error {
+	return call(ctx, s.timeout, func(ctx context.Context) error {
+		return s.store.MarkFailed(ctx, id, attempts, attempt, nextAttemptAt, failed)
+	})
+}
+
+// RecordSuccess resets the consecutive failures of a subscription
+func (s *WebhookStore) RecordSuccess(ctx context.Context, subscriptionID string) error {
+	return call(ctx, s.timeout, func(ctx context.Context) error { return s.store.RecordSuccess(ctx, subscriptionID) })
+}
+
+// RecordFailure counts a failed attempt against a subscription
+func (s *WebhookStore) RecordFailure(ctx context.Context, subscriptionID string, disableAfter int, now time.Time) (bool, error) {
+	return query(ctx, s.timeout, func(ctx context.Context) (bool, error) {
+		return s.store.RecordFailure(ctx, subscriptionID, disableAfter, now)
+	})
+}
+
+// ListDeliveries returns the delivery log of a subscription
+func (s *WebhookStore) ListDeliveries(ctx context.Context, subscriptionID string, filter webhook.DeliveryFilter) ([]*webhook.Delivery, error) {
+	return query(ctx, s.timeout, func(ctx context.Context) ([]*webhook.Delivery, error) {
+		return s.store.ListDeliveries(ctx, subscriptionID, filter)
+	})
+}
+
+// CountDeliveries returns how many deliveries of a subscription match the filter
+func (s *WebhookStore) CountDeliveries(ctx context.Context, subscriptionID string, filter webhook.DeliveryFilter) (int, error) {
+	return query(ctx, s.timeout, func(ctx context.Context) (int, error) { return s.store.CountDeliveries(ctx, subscriptionID, filter) })
+}
+
+// Redeliver makes a delivery pending again
+func (s *WebhookStore) Redeliver(ctx context.Context, subscriptionID string, id int64, now time.Time) (bool, error) {
+	return query(ctx, s.timeout, func(ctx context.Context) (bool, error) { return s.store.Redeliver(ctx, subscriptionID, id, now) })
+}
+
+// DeleteDelivered removes deliveries delivered before the given time
+func (s *WebhookStore) DeleteDelivered(ctx context.Context, before time.Time) (int, error) {
+	return query(ctx, s.timeout, func(ctx context.Context) (int, error) { return s.store.DeleteDelivered(ctx, before) })
+}
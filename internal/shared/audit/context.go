@@ -0,0 +1,30 @@
+package audit
+
+import "context"
+
+// SystemActor is recorded for commands run without a caller, such as administrative commands
+const SystemActor = "system"
+
+// Origin identifies who issued a command and the request that carried it
+type Origin struct {
+	Actor     string
+	RequestID string
+}
+
+// originKey marks the origin of the commands run with a context
+type originKey struct{}
+
+// NewContext returns a context whose commands are recorded as issued from origin
+func NewContext(ctx context.Context, origin Origin) context.Context {
+	return context.WithValue(ctx, originKey{}, origin)
+}
+
+// FromContext returns the origin of the commands run with the context
+// Contexts without an origin are attributed to SystemActor
+func FromContext(ctx context.Context) Origin {
+	origin, _ := ctx.Value(originKey{}).(Origin)
+	if origin.Actor == "" {
+		origin.Actor = SystemActor
+	}
+	return origin
+}
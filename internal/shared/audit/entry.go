@@ -0,0 +1,92 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// Entry is one command recorded in a tenant's audit trail
+// Entries of a tenant form a hash chain: each Hash covers the entry's content and the
+// Hash of the entry before it, so changing or removing a stored entry breaks every later link.
+type Entry struct {
+	Sequence      int64
+	Actor         string
+	Command       string
+	AggregateType string
+	AggregateID   string
+	Input         json.RawMessage
+	Before        json.RawMessage
+	After         json.RawMessage
+	RequestID     string
+	OccurredAt    time.Time
+	PreviousHash  string
+	Hash          string
+}
+
+// Link places the entry after the last entry of its chain and computes its hash
+// last is nil for the first entry of a chain
+func (e *Entry) Link(last *Entry) {
+	e.Sequence = 1
+	e.PreviousHash = ""
+	if last != nil {
+		e.Sequence = last.Sequence + 1
+		e.PreviousHash = last.Hash
+	}
+	e.Hash = e.ComputeHash()
+}
+
+// ComputeHash returns the SHA-256 hash of the entry's content and previous hash, hex encoded
+// Every field is length-prefixed so no two different entries hash the same input.
+// OccurredAt is hashed in UTC at microsecond precision, the precision the databases keep.
+func (e *Entry) ComputeHash() string {
+	hash := sha256.New()
+	var sequence [8]byte
+	binary.BigEndian.PutUint64(sequence[:], uint64(e.Sequence))
+	hash.Write(sequence[:])
+	for _, field := range [][]byte{
+		[]byte(e.PreviousHash),
+		[]byte(e.Actor),
+		[]byte(e.Command),
+		[]byte(e.AggregateType),
+		[]byte(e.AggregateID),
+		e.Input,
+		e.Before,
+		e.After,
+		[]byte(e.RequestID),
+		[]byte(e.OccurredAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)),
+	} {
+		var length [8]byte
+		binary.BigEndian.PutUint64(length[:], uint64(len(field)))
+		hash.Write(length[:])
+		hash.Write(field)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Verify checks that entries continue the chain after last, oldest first
+// last is nil when entries start the chain. The first entry that was altered,
+// removed or inserted out of order is reported with CodeAuditChainBroken.
+func Verify(last *Entry, entries []*Entry) error {
+	for _, e := range entries {
+		wantSequence, wantPrevious := int64(1), ""
+		if last != nil {
+			wantSequence, wantPrevious = last.Sequence+1, last.Hash
+		}
+		if e.Sequence != wantSequence {
+			return apperrors.Newf(apperrors.CodeAuditChainBroken, "audit entry %d follows entry %d", e.Sequence, wantSequence-1)
+		}
+		if e.PreviousHash != wantPrevious {
+			return apperrors.Newf(apperrors.CodeAuditChainBroken, "audit entry %d does not link to the entry before it", e.Sequence)
+		}
+		if e.Hash != e.ComputeHash() {
+			return apperrors.Newf(apperrors.CodeAuditChainBroken, "audit entry %d was modified after it was recorded", e.Sequence)
+		}
+		last = e
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// newChain links n entries into a fresh chain, oldest first
+func newChain(n int) []*Entry {
+	at := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	var last *Entry
+	entries := make([]*Entry, 0, n)
+	for i := 0; i < n; i++ {
+		e := &Entry{
+			Actor:         "alice",
+			Command:       "AdjustInventory",
+			AggregateType: "inventory",
+			AggregateID:   fmt.Sprintf("prod-%d", i),
+			Input:         json.RawMessage(`{"adjustment":5}`),
+			Before:        json.RawMessage(`{"quantity":10}`),
+			After:         json.RawMessage(`{"quantity":15}`),
+			RequestID:     fmt.Sprintf("req-%d", i),
+			OccurredAt:    at.Add(time.Duration(i) * time.Minute),
+		}
+		e.Link(last)
+		entries = append(entries, e)
+		last = e
+	}
+	return entries
+}
+
+func TestLink_StartsAndContinuesTheChain(t *testing.T) {
+	chain := newChain(3)
+
+	if chain[0].Sequence != 1 || chain[0].PreviousHash != "" {
+		t.Errorf("first entry = sequence %d after %q, want sequence 1 starting the chain", chain[0].Sequence, chain[0].PreviousHash)
+	}
+	if chain[2].Sequence != 3 || chain[2].PreviousHash != chain[1].Hash {
+		t.Errorf("third entry = sequence %d after %q, want sequence 3 after %q", chain[2].Sequence, chain[2].PreviousHash, chain[1].Hash)
+	}
+	if err := Verify(nil, chain); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if err := Verify(chain[0], chain[1:]); err != nil {
+		t.Errorf("Verify(after entry 1) error = %v", err)
+	}
+}
+
+func TestComputeHash_CoversEveryField(t *testing.T) {
+	base := newChain(1)[0]
+	tests := map[string]func(e *Entry){
+		"sequence":       func(e *Entry) { e.Sequence++ },
+		"previous hash":  func(e *Entry) { e.PreviousHash = "00" },
+		"actor":          func(e *Entry) { e.Actor = "mallory" },
+		"command":        func(e *Entry) { e.Command = "DeleteInventory" },
+		"aggregate type": func(e *Entry) { e.AggregateType = "product" },
+		"aggregate id":   func(e *Entry) { e.AggregateID = "prod-9" },
+		"input":          func(e *Entry) { e.Input = json.RawMessage(`{"adjustment":500}`) },
+		"before":         func(e *Entry) { e.Before = json.RawMessage(`{"quantity":0}`) },
+		"after":          func(e *Entry) { e.After = json.RawMessage(`{"quantity":500}`) },
+		"request id":     func(e *Entry) { e.RequestID = "req-9" },
+		"occurred at":    func(e *Entry) { e.OccurredAt = e.OccurredAt.Add(time.Microsecond) },
+		// Length prefixes keep a byte moved between adjacent fields from hashing the same
+		"field boundary": func(e *Entry) { e.Actor, e.Command = e.Actor+"A", e.Command[1:] },
+	}
+	for name, change := range tests {
+		e := *base
+		change(&e)
+		if e.ComputeHash() == base.Hash {
+			t.Errorf("%s: ComputeHash() unchanged after the field changed", name)
+		}
+	}
+
+	// The time zone and sub-microsecond digits the databases drop do not change the hash
+	e := *base
+	e.OccurredAt = base.OccurredAt.In(time.FixedZone("WIB", 7*60*60)).Add(500 * time.Nanosecond)
+	if e.ComputeHash() != base.Hash {
+		t.Errorf("ComputeHash() changed with the time zone or sub-microsecond precision")
+	}
+}
+
+func TestVerify_ReportsTamperedChains(t *testing.T) {
+	tests := map[string]func(chain []*Entry) []*Entry{
+		"changed field": func(chain []*Entry) []*Entry {
+			chain[1].After = json.RawMessage(`{"quantity":1000}`)
+			return chain
+		},
+		"changed field with its hash recomputed": func(chain []*Entry) []*Entry {
+			chain[1].Actor = "mallory"
+			chain[1].Hash = chain[1].ComputeHash()
+			return chain
+		},
+		"removed entry": func(chain []*Entry) []*Entry {
+			return []*Entry{chain[0], chain[2]}
+		},
+		"removed first entry": func(chain []*Entry) []*Entry {
+			return chain[1:]
+		},
+		"reordered entries": func(chain []*Entry) []*Entry {
+			return []*Entry{chain[0], chain[2], chain[1]}
+		},
+		"renumbered after a removal": func(chain []*Entry) []*Entry {
+			chain[2].Sequence = 2
+			chain[2].Hash = chain[2].ComputeHash()
+			return []*Entry{chain[0], chain[2]}
+		},
+		"broken previous hash": func(chain []*Entry) []*Entry {
+			chain[2].PreviousHash = chain[0].Hash
+			return chain
+		},
+		"previous hash and hash rewritten": func(chain []*Entry) []*Entry {
+			chain[2].PreviousHash = chain[0].Hash
+			chain[2].Hash = chain[2].ComputeHash()
+			return chain
+		},
+	}
+	for name, tamper := range tests {
+		err := Verify(nil, tamper(newChain(3)))
+		if !apperrors.Is(err, apperrors.CodeAuditChainBroken) {
+			t.Errorf("%s: Verify() error = %v, want %s", name, err, apperrors.CodeAuditChainBroken)
+		}
+	}
+}
+
+func TestVerify_ChecksTheLinkToTheLastVerifiedEntry(t *testing.T) {
+	chain := newChain(3)
+	other := newChain(1)
+	other[0].Actor = "bob"
+	other[0].Link(nil)
+
+	// The entries continue the sequence but not the chain that was verified so far
+	err := Verify(other[0], chain[1:])
+	if !apperrors.Is(err, apperrors.CodeAuditChainBroken) {
+		t.Errorf("Verify() after another chain's entry error = %v, want %s", err, apperrors.CodeAuditChainBroken)
+	}
+}
@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// Change describes how a command changed one aggregate
+// Before is nil for commands that create the aggregate and After for commands that remove it.
+type Change struct {
+	Command       string
+	Input         any
+	AggregateType string
+	AggregateID   string
+	Before        any
+	After         any
+}
+
+// Recorder appends the changes made by commands to the audit trail
+// A nil Recorder records nothing.
+type Recorder struct {
+	store Store
+}
+
+// NewRecorder creates a new instance of Recorder
+func NewRecorder(store Store) *Recorder {
+	return &Recorder{store: store}
+}
+
+// InTx returns a recorder that appends through a unit of work's audit store,
+// so each entry commits or rolls back with the change it records
+func (r *Recorder) InTx(store Store) *Recorder {
+	if r == nil {
+		return nil
+	}
+	return &Recorder{store: store}
+}
+
+// Record appends an entry for a change a command is making
+// Commands record inside their transaction, so a failure to record fails the command
+// and a command that fails leaves no entry.
+func (r *Recorder) Record(ctx context.Context, change Change) error {
+	if r == nil {
+		return nil
+	}
+
+	entry, err := newEntry(ctx, change)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.CodeInternalError, "failed to encode audit entry")
+	}
+	return r.store.Append(ctx, entry)
+}
+
+// newEntry builds the unlinked entry for a change made with ctx
+func newEntry(ctx context.Context, change Change) (*Entry, error) {
+	input, err := json.Marshal(change.Input)
+	if err != nil {
+		return nil, err
+	}
+	before, err := json.Marshal(change.Before)
+	if err != nil {
+		return nil, err
+	}
+	after, err := json.Marshal(change.After)
+	if err != nil {
+		return nil, err
+	}
+
+	origin := FromContext(ctx)
+	return &Entry{
+		Actor:         origin.Actor,
+		Command:       change.Command,
+		AggregateType: change.AggregateType,
+		AggregateID:   change.AggregateID,
+		Input:         input,
+		Before:        before,
+		After:         after,
+		RequestID:     origin.RequestID,
+		OccurredAt:    time.Now().UTC().Truncate(time.Microsecond),
+	}, nil
+}
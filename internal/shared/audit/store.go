@@ -0,0 +1,31 @@
+package audit
+
+import "context"
+
+// Filter selects audit entries; empty fields match every entry
+type Filter struct {
+	AggregateType string
+	AggregateID   string
+	Actor         string
+	Offset        int
+	Limit         int
+}
+
+// Store persists the audit trail of each tenant
+// The trail is append-only: stores offer no way to change or remove an entry,
+// and the SQL schemas reject updates and deletes of stored entries.
+type Store interface {
+	// Append links the entry to the end of the tenant's chain and stores it
+	// It sets the entry's Sequence, PreviousHash and Hash. Implementations must make
+	// Append atomic so that concurrent appends cannot fork the chain.
+	Append(ctx context.Context, entry *Entry) error
+
+	// List returns a page of the entries matching the filter, newest first
+	List(ctx context.Context, filter Filter) ([]*Entry, error)
+
+	// Count returns how many entries match the filter, ignoring its paging
+	Count(ctx context.Context, filter Filter) (int, error)
+
+	// Chain returns up to limit entries following the given sequence, oldest first
+	Chain(ctx context.Context, afterSequence int64, limit int) ([]*Entry, error)
+}
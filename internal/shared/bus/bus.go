@@ -0,0 +1,124 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// ErrNoHandler is returned when a message is sent that no module has registered a handler for
+var ErrNoHandler = errors.New("no handler registered")
+
+// Message is a contract message one module sends to another
+// Its name identifies the message on the bus, so it must be unique across modules.
+type Message interface {
+	MessageName() string
+}
+
+// Handler answers a message; commands answer with a nil result
+type Handler func(ctx context.Context, msg Message) (any, error)
+
+// Middleware wraps every handler on the bus, e.g. for logging, tracing or timeouts
+type Middleware func(next Handler) Handler
+
+// Bus routes query and command messages between modules, in process
+// Each message has exactly one handler, registered by the module that owns it, so a module
+// only needs the contract messages of another module and not its constructors.
+// A nil Bus has no handlers.
+type Bus struct {
+	mu         sync.RWMutex
+	handlers   map[string]Handler
+	middleware []Middleware
+}
+
+// New creates a new instance of Bus
+func New() *Bus {
+	return &Bus{handlers: make(map[string]Handler)}
+}
+
+// Use adds middleware around every handler, the first added being the outermost
+// It applies to handlers registered before and after the call.
+func (b *Bus) Use(middleware ...Middleware) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.middleware = append(b.middleware, middleware...)
+}
+
+// HandleQuery registers the handler answering queries of type Q with a result of type R
+// Registering a second handler for the same message is a wiring mistake and panics.
+func HandleQuery[Q Message, R any](b *Bus, handler func(ctx context.Context, query Q) (R, error)) {
+	var query Q
+	b.register(query.MessageName(), func(ctx context.Context, msg Message) (any, error) {
+		return handler(ctx, msg.(Q))
+	})
+}
+
+// HandleCommand registers the handler for commands of type C
+// Registering a second handler for the same message is a wiring mistake and panics.
+func HandleCommand[C Message](b *Bus, handler func(ctx context.Context, command C) error) {
+	var command C
+	b.register(command.MessageName(), func(ctx context.Context, msg Message) (any, error) {
+		return nil, handler(ctx, msg.(C))
+	})
+}
+
+// Ask sends query to its handler and returns the handler's result
+func Ask[R any](ctx context.Context, b *Bus, query Message) (R, error) {
+	var zero R
+	result, err := b.dispatch(ctx, query)
+	if err != nil {
+		return zero, err
+	}
+	if result == nil {
+		return zero, nil
+	}
+	typed, ok := result.(R)
+	if !ok {
+		return zero, apperrors.Newf(apperrors.CodeInternalError, "handler for %s returned %T, not %T", query.MessageName(), result, zero)
+	}
+	return typed, nil
+}
+
+// Send sends command to its handler
+func Send(ctx context.Context, b *Bus, command Message) error {
+	_, err := b.dispatch(ctx, command)
+	return err
+}
+
+// register adds the handler for the message named name
+func (b *Bus) register(name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, exists := b.handlers[name]; exists {
+		panic(fmt.Sprintf("bus: a handler for %s is already registered", name))
+	}
+	b.handlers[name] = handler
+}
+
+// dispatch runs the handler for msg through the middleware
+func (b *Bus) dispatch(ctx context.Context, msg Message) (any, error) {
+	if b == nil {
+		return nil, noHandler(msg)
+	}
+
+	b.mu.RLock()
+	handler, ok := b.handlers[msg.MessageName()]
+	middleware := b.middleware
+	b.mu.RUnlock()
+	if !ok {
+		return nil, noHandler(msg)
+	}
+
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler(ctx, msg)
+}
+
+// noHandler reports that nothing handles msg, which means a module was not wired
+func noHandler(msg Message) error {
+	return apperrors.Wrapf(ErrNoHandler, apperrors.CodeInternalError, "no handler registered for %s; is the module that owns it wired?", msg.MessageName())
+}
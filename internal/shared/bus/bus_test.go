@@ -0,0 +1,126 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// getGreeting is a test query
+type getGreeting struct{ name string }
+
+func (getGreeting) MessageName() string { return "test.get_greeting" }
+
+// recordVisit is a test command
+type recordVisit struct{ name string }
+
+func (recordVisit) MessageName() string { return "test.record_visit" }
+
+func TestBus_AskReturnsTheHandlersResult(t *testing.T) {
+	b := New()
+	HandleQuery(b, func(ctx context.Context, q getGreeting) (string, error) {
+		return "hello " + q.name, nil
+	})
+
+	got, err := Ask[string](context.Background(), b, getGreeting{name: "ada"})
+	if err != nil {
+		t.Fatalf("Ask: %v", err)
+	}
+	if got != "hello ada" {
+		t.Errorf("got %q, want %q", got, "hello ada")
+	}
+}
+
+func TestBus_SendRunsTheCommandHandler(t *testing.T) {
+	b := New()
+	var visits []string
+	HandleCommand(b, func(ctx context.Context, c recordVisit) error {
+		if c.name == "" {
+			return errors.New("name is required")
+		}
+		visits = append(visits, c.name)
+		return nil
+	})
+
+	if err := Send(context.Background(), b, recordVisit{name: "ada"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if err := Send(context.Background(), b, recordVisit{}); err == nil {
+		t.Error("expected the handler's error")
+	}
+	if len(visits) != 1 || visits[0] != "ada" {
+		t.Errorf("visits = %v, want [ada]", visits)
+	}
+}
+
+func TestBus_MissingHandlerIsAClearError(t *testing.T) {
+	for name, b := range map[string]*Bus{"empty": New(), "nil": nil} {
+		_, err := Ask[string](context.Background(), b, getGreeting{})
+		if !errors.Is(err, ErrNoHandler) {
+			t.Fatalf("%s: err = %v, want ErrNoHandler", name, err)
+		}
+		if !apperrors.Is(err, apperrors.CodeInternalError) {
+			t.Errorf("%s: code = %s, want %s", name, apperrors.GetCode(err), apperrors.CodeInternalError)
+		}
+		if !strings.Contains(err.Error(), "test.get_greeting") {
+			t.Errorf("%s: error %q does not name the message", name, err)
+		}
+	}
+}
+
+func TestBus_WrongResultTypeIsAnError(t *testing.T) {
+	b := New()
+	HandleQuery(b, func(ctx context.Context, q getGreeting) (int, error) {
+		return 1, nil
+	})
+
+	if _, err := Ask[string](context.Background(), b, getGreeting{}); err == nil {
+		t.Error("expected an error for a result of the wrong type")
+	}
+}
+
+func TestBus_DuplicateHandlerPanics(t *testing.T) {
+	b := New()
+	HandleCommand(b, func(ctx context.Context, c recordVisit) error { return nil })
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a second handler to panic")
+		}
+	}()
+	HandleCommand(b, func(ctx context.Context, c recordVisit) error { return nil })
+}
+
+func TestBus_MiddlewareWrapsHandlersInOrder(t *testing.T) {
+	b := New()
+	var calls []string
+	trace := func(label string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, msg Message) (any, error) {
+				calls = append(calls, label+">"+msg.MessageName())
+				result, err := next(ctx, msg)
+				calls = append(calls, label+"<")
+				return result, err
+			}
+		}
+	}
+	b.Use(trace("outer"))
+	HandleQuery(b, func(ctx context.Context, q getGreeting) (string, error) {
+		calls = append(calls, "handler")
+		return "hi", nil
+	})
+	// Middleware added after a handler is registered still applies to it
+	b.Use(trace("inner"))
+
+	if _, err := Ask[string](context.Background(), b, getGreeting{}); err != nil {
+		t.Fatalf("Ask: %v", err)
+	}
+
+	want := []string{"outer>test.get_greeting", "inner>test.get_greeting", "handler", "inner<", "outer<"}
+	if strings.Join(calls, ",") != strings.Join(want, ",") {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
@@ -0,0 +1,106 @@
+package eventbus
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/event"
+)
+
+// AllEvents subscribes a handler to every event regardless of its name
+const AllEvents = "*"
+
+// Handler reacts to a dispatched event
+type Handler func(ctx context.Context, e event.Event) error
+
+// subscription is a handler and how it is run
+type subscription struct {
+	handler Handler
+	async   bool
+}
+
+// Dispatcher delivers domain events to the handlers subscribed to them, in process
+// Synchronous handlers run in order before Dispatch returns; asynchronous handlers
+// run on their own goroutine. Events are dispatched after the changes that raised
+// them are stored, so a handler's failure is logged and does not undo the change.
+// A nil Dispatcher drops every event.
+type Dispatcher struct {
+	mu            sync.RWMutex
+	subscriptions map[string][]subscription
+	inFlight      sync.WaitGroup
+}
+
+// NewDispatcher creates a new instance of Dispatcher
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{subscriptions: make(map[string][]subscription)}
+}
+
+// Subscribe runs handler for every event named name, before Dispatch returns
+func (d *Dispatcher) Subscribe(name string, handler Handler) {
+	d.subscribe(name, subscription{handler: handler})
+}
+
+// SubscribeAsync runs handler for every event named name on a separate goroutine
+// The handler's context is not cancelled when the dispatching request ends.
+func (d *Dispatcher) SubscribeAsync(name string, handler Handler) {
+	d.subscribe(name, subscription{handler: handler, async: true})
+}
+
+// subscribe adds a subscription for name
+func (d *Dispatcher) subscribe(name string, sub subscription) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.subscriptions[name] = append(d.subscriptions[name], sub)
+}
+
+// Dispatch delivers events, in order, to the handlers subscribed to them
+func (d *Dispatcher) Dispatch(ctx context.Context, events ...event.Event) {
+	if d == nil {
+		return
+	}
+
+	for _, e := range events {
+		for _, sub := range d.handlersFor(e.EventName()) {
+			if !sub.async {
+				d.handle(ctx, sub.handler, e)
+				continue
+			}
+			d.inFlight.Add(1)
+			go func(handler Handler, e event.Event) {
+				defer d.inFlight.Done()
+				d.handle(context.WithoutCancel(ctx), handler, e)
+			}(sub.handler, e)
+		}
+	}
+}
+
+// Wait blocks until every asynchronous handler started so far has returned
+// It is called on shutdown so events dispatched before it are not lost.
+func (d *Dispatcher) Wait() {
+	if d == nil {
+		return
+	}
+	d.inFlight.Wait()
+}
+
+// handlersFor returns the subscriptions to an event name, followed by those to every event
+func (d *Dispatcher) handlersFor(name string) []subscription {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	subs := make([]subscription, 0, len(d.subscriptions[name])+len(d.subscriptions[AllEvents]))
+	subs = append(subs, d.subscriptions[name]...)
+	return append(subs, d.subscriptions[AllEvents]...)
+}
+
+// handle runs handler, logging its error or panic
+func (d *Dispatcher) handle(ctx context.Context, handler Handler, e event.Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Handler for %s of %q panicked: %v", e.EventName(), e.AggregateID(), r)
+		}
+	}()
+	if err := handler(ctx, e); err != nil {
+		log.Printf("Handler for %s of %q failed: %v", e.EventName(), e.AggregateID(), err)
+	}
+}
@@ -0,0 +1,86 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/event"
+)
+
+// testEvent is a minimal event
+type testEvent struct {
+	name string
+	id   string
+}
+
+func (e testEvent) EventName() string     { return e.name }
+func (e testEvent) AggregateID() string   { return e.id }
+func (e testEvent) OccurredAt() time.Time { return time.Time{} }
+
+func TestDispatcher_SyncHandlersRunInOrder(t *testing.T) {
+	d := NewDispatcher()
+	var got []string
+	d.Subscribe("a", func(ctx context.Context, e event.Event) error {
+		got = append(got, "a:"+e.AggregateID())
+		return errors.New("failed")
+	})
+	d.Subscribe(AllEvents, func(ctx context.Context, e event.Event) error {
+		got = append(got, "*:"+e.AggregateID())
+		return nil
+	})
+	d.Subscribe("b", func(ctx context.Context, e event.Event) error {
+		panic("boom")
+	})
+
+	d.Dispatch(context.Background(), testEvent{name: "a", id: "1"}, testEvent{name: "b", id: "2"}, testEvent{name: "c", id: "3"})
+
+	// A failing or panicking handler does not stop the others
+	want := []string{"a:1", "*:1", "*:2", "*:3"}
+	if len(got) != len(want) {
+		t.Fatalf("handled %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("handled %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestDispatcher_AsyncHandlersOutliveTheRequest(t *testing.T) {
+	d := NewDispatcher()
+	var (
+		mu      sync.Mutex
+		handled []string
+	)
+	release := make(chan struct{})
+	d.SubscribeAsync("a", func(ctx context.Context, e event.Event) error {
+		<-release
+		if ctx.Err() != nil {
+			t.Errorf("async handler context is done: %v", ctx.Err())
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, e.AggregateID())
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.Dispatch(ctx, testEvent{name: "a", id: "1"}, testEvent{name: "a", id: "2"})
+	cancel()
+	close(release)
+	d.Wait()
+
+	if len(handled) != 2 {
+		t.Errorf("handled %v, want both events", handled)
+	}
+}
+
+func TestDispatcher_Nil(t *testing.T) {
+	var d *Dispatcher
+	d.Dispatch(context.Background(), testEvent{name: "a"})
+	d.Wait()
+}
@@ -0,0 +1,42 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+// Record is a stored request identified by its idempotency key
+// A record without a status code belongs to a request that is still being processed
+type Record struct {
+	Key          string
+	Fingerprint  string
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+// IsCompleted reports whether the response of the request has been stored
+func (r *Record) IsCompleted() bool {
+	return r.StatusCode != 0
+}
+
+// Store persists idempotency records
+// Implementations must make Reserve atomic so that concurrent requests with
+// the same key cannot both be processed
+type Store interface {
+	// Reserve claims the key for a new request
+	// Returns false without error if an unexpired record already holds the key
+	Reserve(ctx context.Context, record *Record) (bool, error)
+
+	// Get retrieves the unexpired record for a key
+	// Returns nil if no such record exists
+	Get(ctx context.Context, key string) (*Record, error)
+
+	// Complete stores the response of a reserved request
+	Complete(ctx context.Context, key string, statusCode int, contentType string, responseBody []byte) error
+
+	// Release removes a reservation so the request can be retried
+	Release(ctx context.Context, key string) error
+}
@@ -0,0 +1,63 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/JoshuaPangaribuan/clean-arch-ddd/internal/domain/event"
+)
+
+// Status is where a message is in its delivery
+type Status string
+
+const (
+	// StatusPending messages wait to be published, possibly after failed attempts
+	StatusPending Status = "pending"
+	// StatusPublished messages were accepted by the publisher
+	StatusPublished Status = "published"
+	// StatusDead messages failed every attempt and wait for a replay
+	StatusDead Status = "dead"
+)
+
+// Message is an event stored in the outbox for publication
+// ID increases in the order messages are stored, across tenants.
+type Message struct {
+	ID            int64
+	TenantID      string
+	AggregateType string
+	AggregateID   string
+	EventName     string
+	Payload       json.RawMessage
+	OccurredAt    time.Time
+	Status        Status
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	CreatedAt     time.Time
+	// PublishedAt is zero until the message is published
+	PublishedAt time.Time
+}
+
+// NewMessages converts events raised by an aggregate of the given type into pending messages
+// The store assigns their ID; they are due as soon as they are stored.
+func NewMessages(tenantID, aggregateType string, events []event.Event, now time.Time) ([]*Message, error) {
+	messages := make([]*Message, 0, len(events))
+	for _, e := range events {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, &Message{
+			TenantID:      tenantID,
+			AggregateType: aggregateType,
+			AggregateID:   e.AggregateID(),
+			EventName:     e.EventName(),
+			Payload:       payload,
+			OccurredAt:    e.OccurredAt().UTC().Truncate(time.Microsecond),
+			Status:        StatusPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+	}
+	return messages, nil
+}
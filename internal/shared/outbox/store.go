@@ -0,0 +1,45 @@
+package outbox
+
+import (
+	"context"
+	"time"
+)
+
+// Filter narrows the messages of a tenant that are listed
+type Filter struct {
+	// Status only lists messages in this state; empty lists every message
+	Status Status
+	Offset int
+	Limit  int
+}
+
+// Store gives the relay and administrators access to the outbox
+// Messages are written by the repositories, in the transactions of the changes that raise them.
+type Store interface {
+	// Claim leases up to limit due messages to the caller until now+lease
+	// Only the oldest unpublished message of each aggregate is claimed, so an aggregate's
+	// messages are published one at a time and in order; a dead message holds back the
+	// aggregate's later messages until it is replayed. Claims span every tenant.
+	Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*Message, error)
+
+	// MarkPublished records that a claimed message was published
+	MarkPublished(ctx context.Context, id int64, at time.Time) error
+
+	// MarkFailed records a failed attempt to publish a claimed message
+	// The message is retried at nextAttemptAt, or becomes dead when dead is set.
+	MarkFailed(ctx context.Context, id int64, attempts int, nextAttemptAt time.Time, lastError string, dead bool) error
+
+	// List returns the messages of the tenant of the context, oldest first
+	List(ctx context.Context, filter Filter) ([]*Message, error)
+
+	// Count returns how many messages of the tenant of the context match the filter
+	Count(ctx context.Context, filter Filter) (int, error)
+
+	// Replay makes a dead message of the tenant of the context pending again, due at now,
+	// with its attempts reset. Returns false if the tenant has no dead message with that ID.
+	Replay(ctx context.Context, id int64, now time.Time) (bool, error)
+
+	// DeletePublished removes messages of every tenant published before the given time
+	// and returns how many were removed
+	DeletePublished(ctx context.Context, before time.Time) (int, error)
+}
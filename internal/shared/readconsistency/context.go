@@ -0,0 +1,18 @@
+package readconsistency
+
+import "context"
+
+// primaryKey marks contexts whose reads must see the primary database
+type primaryKey struct{}
+
+// WithPrimary returns a context whose reads are served by the primary database
+// Used after writes so a client reads its own changes instead of a lagging replica
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// RequiresPrimary reports whether reads in the context must be served by the primary database
+func RequiresPrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryKey{}).(bool)
+	return primary
+}
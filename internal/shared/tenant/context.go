@@ -0,0 +1,43 @@
+package tenant
+
+import "context"
+
+// DefaultID is the tenant of requests that name none and of data written before tenants existed
+const DefaultID = "default"
+
+// Tenant identifies the business unit a request acts for, with its configuration
+type Tenant struct {
+	ID       string
+	Settings Settings
+}
+
+// tenantKey marks the tenant a context acts for
+type tenantKey struct{}
+
+// NewContext returns a context acting for the tenant
+// Repositories scope every read and write to the tenant of the context
+func NewContext(ctx context.Context, t Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, t)
+}
+
+// FromContext returns the tenant the context acts for
+func FromContext(ctx context.Context) (Tenant, bool) {
+	t, ok := ctx.Value(tenantKey{}).(Tenant)
+	return t, ok
+}
+
+// ID returns the ID of the tenant the context acts for
+// Contexts without a tenant, such as those of administrative commands, act for DefaultID
+func ID(ctx context.Context) string {
+	if t, ok := FromContext(ctx); ok {
+		return t.ID
+	}
+	return DefaultID
+}
+
+// SettingsFromContext returns the configuration of the tenant the context acts for
+// Contexts without a tenant get zero settings
+func SettingsFromContext(ctx context.Context) Settings {
+	t, _ := FromContext(ctx)
+	return t.Settings
+}
@@ -0,0 +1,60 @@
+package tenant
+
+import (
+	apperrors "github.com/JoshuaPangaribuan/clean-arch-ddd/pkg/errors"
+)
+
+// MaxIDLength is the longest tenant ID the tenant_id columns hold
+const MaxIDLength = 64
+
+// Settings is the configuration that may differ between tenants
+type Settings struct {
+	// DefaultCurrency is the currency of prices given without one
+	DefaultCurrency string `json:"default_currency"`
+}
+
+// merge returns s with its empty fields taken from defaults
+func (s Settings) merge(defaults Settings) Settings {
+	if s.DefaultCurrency == "" {
+		s.DefaultCurrency = defaults.DefaultCurrency
+	}
+	return s
+}
+
+// Registry resolves tenant IDs to tenants with their settings
+type Registry struct {
+	defaults Settings
+	tenants  map[string]Settings
+}
+
+// NewRegistry creates a registry of the configured tenants
+// Settings a tenant leaves empty fall back to defaults. Without configured tenants any
+// valid tenant ID is accepted; with them, only those tenants and DefaultID are.
+func NewRegistry(defaults Settings, tenants map[string]Settings) *Registry {
+	return &Registry{defaults: defaults, tenants: tenants}
+}
+
+// Resolve validates a tenant ID and returns the tenant with its settings
+func (r *Registry) Resolve(id string) (Tenant, error) {
+	if err := ValidateID(id); err != nil {
+		return Tenant{}, err
+	}
+	settings, ok := r.tenants[id]
+	if !ok && len(r.tenants) > 0 && id != DefaultID {
+		return Tenant{}, apperrors.Newf(apperrors.CodeUnknownTenant, "tenant %q is not known", id)
+	}
+	return Tenant{ID: id, Settings: settings.merge(r.defaults)}, nil
+}
+
+// ValidateID checks that a tenant ID is 1 to MaxIDLength lowercase letters, digits, '-' or '_'
+func ValidateID(id string) error {
+	if id == "" || len(id) > MaxIDLength {
+		return apperrors.Newf(apperrors.CodeInvalidTenantID, "tenant ID must be 1 to %d characters", MaxIDLength)
+	}
+	for _, r := range id {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return apperrors.New(apperrors.CodeInvalidTenantID, "tenant ID may only contain lowercase letters, digits, '-' and '_'")
+		}
+	}
+	return nil
+}
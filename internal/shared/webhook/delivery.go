@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DeliveryStatus is where a delivery is in its attempts
+type DeliveryStatus string
+
+const (
+	// DeliveryPending deliveries wait to be sent, possibly after failed attempts
+	DeliveryPending DeliveryStatus = "pending"
+	// DeliveryDelivered deliveries were accepted with a 2xx response
+	DeliveryDelivered DeliveryStatus = "delivered"
+	// DeliveryFailed deliveries failed every attempt and wait for a manual redelivery
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// Delivery is an event sent, or to be sent, to a subscription
+// Together the deliveries of a subscription form its delivery log.
+type Delivery struct {
+	ID             int64
+	TenantID       string
+	SubscriptionID string
+	// MessageID is the outbox message the delivery carries; a subscription gets each message once
+	MessageID int64
+	EventName string
+	// Payload is the request body
+	Payload       json.RawMessage
+	Status        DeliveryStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	// LastStatusCode is the HTTP status of the last attempt, or 0 if it got no response
+	LastStatusCode int
+	LastError      string
+	// LastAttemptAt and DeliveredAt are zero until they happen
+	LastAttemptAt time.Time
+	DeliveredAt   time.Time
+	CreatedAt     time.Time
+}
+
+// Attempt is the outcome of sending a delivery once
+type Attempt struct {
+	At         time.Time
+	StatusCode int
+	// Error is empty when the attempt succeeded
+	Error string
+}
@@ -0,0 +1,37 @@
+package webhook
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestIsPublicAddress(t *testing.T) {
+	tests := map[string]bool{
+		"93.184.216.34":        true,
+		"8.8.8.8":              true,
+		"2606:4700::1111":      true,
+		"127.0.0.1":            false,
+		"::1":                  false,
+		"0.0.0.0":              false,
+		"::":                   false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"fd00::1":              false,
+		"169.254.169.254":      false,
+		"fe80::1":              false,
+		"100.64.0.1":           false,
+		"224.0.0.1":            false,
+		"255.255.255.255":      false,
+		"::ffff:127.0.0.1":     false,
+		"::ffff:169.254.1.1":   false,
+		"64:ff9b::a9fe:a9fe":   false,
+		"2002:a9fe:a9fe::1":    false,
+		"::ffff:93.184.216.34": true,
+	}
+	for address, want := range tests {
+		if got := IsPublicAddress(netip.MustParseAddr(address)); got != want {
+			t.Errorf("IsPublicAddress(%s) = %v, want %v", address, got, want)
+		}
+	}
+}
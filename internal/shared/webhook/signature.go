@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Headers sent with every delivery
+const (
+	// HeaderDeliveryID identifies the delivery; it is the same for every attempt
+	HeaderDeliveryID = "X-Webhook-Delivery"
+	// HeaderEvent is the name of the delivered event
+	HeaderEvent = "X-Webhook-Event"
+	// HeaderTimestamp is the Unix time, in seconds, the attempt was signed at
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	// HeaderSignature is "sha256=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// signaturePrefix names the algorithm of a signature
+const signaturePrefix = "sha256="
+
+var (
+	// ErrInvalidSignature is returned when a signature does not match the body
+	ErrInvalidSignature = errors.New("webhook signature does not match")
+	// ErrStaleTimestamp is returned when a signature is older than the tolerance allows
+	ErrStaleTimestamp = errors.New("webhook timestamp is outside the tolerance")
+)
+
+// Sign returns the signature of body sent at timestamp
+// The timestamp is signed with the body, so a captured request cannot be replayed later.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	return signaturePrefix + hex.EncodeToString(mac(secret, strconv.FormatInt(timestamp.Unix(), 10), body))
+}
+
+// Verify checks the timestamp and signature headers of a delivery against its body
+// Receivers reject deliveries signed more than tolerance away from now.
+func Verify(secret, timestamp, signature string, body []byte, now time.Time, tolerance time.Duration) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return ErrStaleTimestamp
+	}
+	if len(signature) <= len(signaturePrefix) || signature[:len(signaturePrefix)] != signaturePrefix {
+		return ErrInvalidSignature
+	}
+	got, err := hex.DecodeString(signature[len(signaturePrefix):])
+	if err != nil || !hmac.Equal(got, mac(secret, timestamp, body)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// mac computes the HMAC-SHA256 of "<timestamp>.<body>"
+func mac(secret, timestamp string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(timestamp))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
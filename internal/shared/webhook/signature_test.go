@@ -0,0 +1,61 @@
+package webhook
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSign_VerifiesWithTheSameSecretAndBody(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	body := []byte(`{"id":1}`)
+	signature := Sign("whsec_test", at, body)
+	if !strings.HasPrefix(signature, "sha256=") {
+		t.Fatalf("Sign() = %q, want a sha256= signature", signature)
+	}
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+
+	if err := Verify("whsec_test", timestamp, signature, body, at.Add(time.Minute), 5*time.Minute); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	tests := map[string]struct {
+		secret, timestamp, signature string
+		body                         []byte
+		want                         error
+	}{
+		"other secret":    {"whsec_other", timestamp, signature, body, ErrInvalidSignature},
+		"other body":      {"whsec_test", timestamp, signature, []byte(`{"id":2}`), ErrInvalidSignature},
+		"other timestamp": {"whsec_test", strconv.FormatInt(at.Unix()+1, 10), signature, body, ErrInvalidSignature},
+		"no prefix":       {"whsec_test", timestamp, strings.TrimPrefix(signature, "sha256="), body, ErrInvalidSignature},
+		"bad timestamp":   {"whsec_test", "yesterday", signature, body, ErrInvalidSignature},
+	}
+	for name, tt := range tests {
+		if err := Verify(tt.secret, tt.timestamp, tt.signature, tt.body, at, 5*time.Minute); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Verify() error = %v, want %v", name, err, tt.want)
+		}
+	}
+}
+
+func TestVerify_RejectsStaleTimestamps(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	body := []byte(`{"id":1}`)
+	signature := Sign("whsec_test", at, body)
+
+	err := Verify("whsec_test", strconv.FormatInt(at.Unix(), 10), signature, body, at.Add(10*time.Minute), 5*time.Minute)
+	if !errors.Is(err, ErrStaleTimestamp) {
+		t.Errorf("Verify() of a replayed request error = %v, want ErrStaleTimestamp", err)
+	}
+}
+
+func TestSubscription_Matches(t *testing.T) {
+	sub := &Subscription{EventTypes: []string{"product.created"}}
+	if !sub.Matches("product.created") || sub.Matches("product.price_changed") {
+		t.Errorf("Matches() does not follow the event types %v", sub.EventTypes)
+	}
+	all := &Subscription{EventTypes: []string{AllEvents}}
+	if !all.Matches("inventory.stock_adjusted") {
+		t.Error("Matches() of a subscription to every event = false")
+	}
+}
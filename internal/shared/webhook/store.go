@@ -0,0 +1,74 @@
+package webhook
+
+import (
+	"context"
+	"time"
+)
+
+// DeliveryFilter narrows the deliveries of a subscription that are listed
+type DeliveryFilter struct {
+	// Status only lists deliveries in this state; empty lists every delivery
+	Status DeliveryStatus
+	Offset int
+	Limit  int
+}
+
+// Store keeps the webhook subscriptions and their deliveries
+// Subscriptions and the delivery log are scoped to the tenant of the context; the
+// dispatcher claims and records deliveries of every tenant.
+type Store interface {
+	// CreateSubscription stores a new subscription for the tenant of the context
+	CreateSubscription(ctx context.Context, s *Subscription) error
+
+	// GetSubscription returns a subscription of the tenant of the context, or nil if there is none
+	GetSubscription(ctx context.Context, id string) (*Subscription, error)
+
+	// ListSubscriptions returns the subscriptions of the tenant of the context, oldest first
+	ListSubscriptions(ctx context.Context) ([]*Subscription, error)
+
+	// UpdateSubscription stores the URL, event types, secret, state and failure count of a subscription
+	UpdateSubscription(ctx context.Context, s *Subscription) error
+
+	// DeleteSubscription removes a subscription with its deliveries
+	// Returns false if the tenant of the context has no subscription with that ID.
+	DeleteSubscription(ctx context.Context, id string) (bool, error)
+
+	// Enqueue stores pending deliveries for the tenant of the context
+	// A delivery of a message its subscription already has is skipped, so a message
+	// handed over more than once is still delivered once.
+	Enqueue(ctx context.Context, deliveries []*Delivery) error
+
+	// Claim leases up to limit due deliveries of active subscriptions to the caller
+	// until now+lease, oldest first. Claims span every tenant.
+	Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*Delivery, error)
+
+	// MarkDelivered records the successful attempt of a claimed delivery
+	MarkDelivered(ctx context.Context, id int64, attempts int, attempt Attempt) error
+
+	// MarkFailed records a failed attempt of a claimed delivery
+	// The delivery is retried at nextAttemptAt, or fails for good when failed is set.
+	MarkFailed(ctx context.Context, id int64, attempts int, attempt Attempt, nextAttemptAt time.Time, failed bool) error
+
+	// RecordSuccess resets the consecutive failures of a subscription of the tenant of the context
+	RecordSuccess(ctx context.Context, subscriptionID string) error
+
+	// RecordFailure counts a failed attempt against a subscription of the tenant of the context
+	// and disables it, at now, once disableAfter attempts in a row have failed.
+	// Returns true if this failure disabled the subscription.
+	RecordFailure(ctx context.Context, subscriptionID string, disableAfter int, now time.Time) (bool, error)
+
+	// ListDeliveries returns the delivery log of a subscription of the tenant of the context, newest first
+	ListDeliveries(ctx context.Context, subscriptionID string, filter DeliveryFilter) ([]*Delivery, error)
+
+	// CountDeliveries returns how many deliveries of a subscription match the filter
+	CountDeliveries(ctx context.Context, subscriptionID string, filter DeliveryFilter) (int, error)
+
+	// Redeliver makes a delivery of a subscription of the tenant of the context pending
+	// again, due at now, with its attempts reset, whatever its status.
+	// Returns false if the subscription has no delivery with that ID.
+	Redeliver(ctx context.Context, subscriptionID string, id int64, now time.Time) (bool, error)
+
+	// DeleteDelivered removes deliveries of every tenant delivered before the given time
+	// and returns how many were removed
+	DeleteDelivered(ctx context.Context, before time.Time) (int, error)
+}
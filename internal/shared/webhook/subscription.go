@@ -0,0 +1,54 @@
+// Package webhook holds the subscriptions partner systems register to have domain
+// events POSTed to them, and the log of those deliveries
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// AllEvents subscribes to every event regardless of its name
+const AllEvents = "*"
+
+// secretPrefix marks generated signing secrets
+const secretPrefix = "whsec_"
+
+// Subscription is an endpoint that receives the events of the given types
+// A subscription that fails DisableAfter attempts in a row is disabled; its
+// pending deliveries wait until it is enabled again.
+type Subscription struct {
+	ID       string
+	TenantID string
+	URL      string
+	// EventTypes are the event names delivered, or AllEvents
+	EventTypes []string
+	// Secret signs every delivery; receivers use it to verify the signature
+	Secret string
+	Active bool
+	// ConsecutiveFailures counts the failed attempts since the last successful one
+	ConsecutiveFailures int
+	// DisabledAt is zero unless the subscription was disabled after repeated failures
+	DisabledAt time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Matches reports whether events named eventName are delivered to the subscription
+func (s *Subscription) Matches(eventName string) bool {
+	for _, eventType := range s.EventTypes {
+		if eventType == AllEvents || eventType == eventName {
+			return true
+		}
+	}
+	return false
+}
+
+// NewSecret generates a random signing secret
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return secretPrefix + hex.EncodeToString(b), nil
+}
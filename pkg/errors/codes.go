@@ -14,6 +14,30 @@ const (
 	CodeForbidden     ErrorCode = "FORBIDDEN"
 	CodeValidation    ErrorCode = "VALIDATION_ERROR"
 
+	// Concurrency errors
+	CodeConcurrencyConflict ErrorCode = "CONCURRENCY_CONFLICT"
+
+	// Idempotency errors
+	CodeIdempotencyKeyReused  ErrorCode = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyInProgress ErrorCode = "IDEMPOTENCY_REQUEST_IN_PROGRESS"
+	CodeInvalidIdempotencyKey ErrorCode = "INVALID_IDEMPOTENCY_KEY"
+
+	// Tenant errors
+	CodeTenantRequired  ErrorCode = "TENANT_REQUIRED"
+	CodeInvalidTenantID ErrorCode = "INVALID_TENANT_ID"
+	CodeUnknownTenant   ErrorCode = "UNKNOWN_TENANT"
+
+	// Audit errors
+	CodeAuditChainBroken ErrorCode = "AUDIT_CHAIN_BROKEN"
+
+	// Outbox errors
+	CodeOutboxMessageNotFound ErrorCode = "OUTBOX_MESSAGE_NOT_FOUND"
+
+	// Webhook errors
+	CodeWebhookSubscriptionNotFound ErrorCode = "WEBHOOK_SUBSCRIPTION_NOT_FOUND"
+	CodeWebhookDeliveryNotFound     ErrorCode = "WEBHOOK_DELIVERY_NOT_FOUND"
+	CodeWebhookSubscriptionDisabled ErrorCode = "WEBHOOK_SUBSCRIPTION_DISABLED"
+
 	// Domain-specific errors - Product
 	CodeProductNotFound      ErrorCode = "PRODUCT_NOT_FOUND"
 	CodeProductAlreadyExists ErrorCode = "PRODUCT_ALREADY_EXISTS"
@@ -22,17 +46,24 @@ const (
 	CodeInvalidPrice         ErrorCode = "INVALID_PRICE"
 
 	// Domain-specific errors - Inventory
-	CodeInventoryNotFound ErrorCode = "INVENTORY_NOT_FOUND"
-	CodeInventoryExists   ErrorCode = "INVENTORY_ALREADY_EXISTS"
-	CodeInsufficientStock ErrorCode = "INSUFFICIENT_STOCK"
-	CodeInvalidQuantity   ErrorCode = "INVALID_QUANTITY"
-	CodeInvalidAdjustment ErrorCode = "INVALID_ADJUSTMENT"
+	CodeInventoryNotFound    ErrorCode = "INVENTORY_NOT_FOUND"
+	CodeInventoryExists      ErrorCode = "INVENTORY_ALREADY_EXISTS"
+	CodeInventoryReserved    ErrorCode = "INVENTORY_RESERVED"
+	CodeInsufficientStock    ErrorCode = "INSUFFICIENT_STOCK"
+	CodeInvalidQuantity      ErrorCode = "INVALID_QUANTITY"
+	CodeInvalidAdjustment    ErrorCode = "INVALID_ADJUSTMENT"
+	CodeInvalidStockPolicy   ErrorCode = "INVALID_STOCK_POLICY"
+	CodeInvalidCostingMethod ErrorCode = "INVALID_COSTING_METHOD"
+	CodeStocktakeNotFound    ErrorCode = "STOCKTAKE_NOT_FOUND"
+	CodeStocktakeNotOpen     ErrorCode = "STOCKTAKE_NOT_OPEN"
 
 	// Persistence errors
 	CodeDatabaseError      ErrorCode = "DATABASE_ERROR"
 	CodeDatabaseConnection ErrorCode = "DATABASE_CONNECTION_ERROR"
 	CodeQueryFailed        ErrorCode = "QUERY_FAILED"
 	CodeTransactionFailed  ErrorCode = "TRANSACTION_FAILED"
+	CodeQueryCanceled      ErrorCode = "QUERY_CANCELED"
+	CodeQueryTimeout       ErrorCode = "QUERY_TIMEOUT"
 )
 
 // ErrorCodeRegistry holds metadata for error codes
@@ -107,6 +138,30 @@ func registerDefaultCodes(registry *ErrorCodeRegistry) {
 	registry.Register(CodeForbidden, 403, "Forbidden access")
 	registry.Register(CodeValidation, 400, "Validation error")
 
+	// Concurrency errors
+	registry.Register(CodeConcurrencyConflict, 409, "Resource was modified concurrently")
+
+	// Idempotency errors
+	registry.Register(CodeIdempotencyKeyReused, 422, "Idempotency key was used with a different request")
+	registry.Register(CodeIdempotencyInProgress, 409, "Request with this idempotency key is still in progress")
+	registry.Register(CodeInvalidIdempotencyKey, 400, "Invalid idempotency key")
+
+	// Tenant errors
+	registry.Register(CodeTenantRequired, 400, "Tenant is required")
+	registry.Register(CodeInvalidTenantID, 400, "Invalid tenant ID")
+	registry.Register(CodeUnknownTenant, 403, "Tenant is not known")
+
+	// Audit errors
+	registry.Register(CodeAuditChainBroken, 500, "Audit trail hash chain does not verify")
+
+	// Outbox errors
+	registry.Register(CodeOutboxMessageNotFound, 404, "Dead outbox message not found")
+
+	// Webhook errors
+	registry.Register(CodeWebhookSubscriptionNotFound, 404, "Webhook subscription not found")
+	registry.Register(CodeWebhookDeliveryNotFound, 404, "Webhook delivery not found")
+	registry.Register(CodeWebhookSubscriptionDisabled, 409, "Webhook subscription is disabled")
+
 	// Product domain errors
 	registry.Register(CodeProductNotFound, 404, "Product not found")
 	registry.Register(CodeProductAlreadyExists, 409, "Product already exists")
@@ -117,13 +172,20 @@ func registerDefaultCodes(registry *ErrorCodeRegistry) {
 	// Inventory domain errors
 	registry.Register(CodeInventoryNotFound, 404, "Inventory not found")
 	registry.Register(CodeInventoryExists, 409, "Inventory already exists")
+	registry.Register(CodeInventoryReserved, 409, "Inventory has reserved stock")
 	registry.Register(CodeInsufficientStock, 400, "Insufficient stock available")
 	registry.Register(CodeInvalidQuantity, 400, "Invalid quantity")
 	registry.Register(CodeInvalidAdjustment, 400, "Invalid adjustment amount")
+	registry.Register(CodeInvalidStockPolicy, 400, "Invalid stock policy")
+	registry.Register(CodeInvalidCostingMethod, 400, "Invalid costing method")
+	registry.Register(CodeStocktakeNotFound, 404, "Stocktake not found")
+	registry.Register(CodeStocktakeNotOpen, 409, "Stocktake is no longer open")
 
 	// Persistence errors
 	registry.Register(CodeDatabaseError, 500, "Database error")
 	registry.Register(CodeDatabaseConnection, 503, "Database connection error")
 	registry.Register(CodeQueryFailed, 500, "Query execution failed")
 	registry.Register(CodeTransactionFailed, 500, "Transaction failed")
+	registry.Register(CodeQueryCanceled, 503, "Database query was canceled")
+	registry.Register(CodeQueryTimeout, 504, "Database query timed out")
 }
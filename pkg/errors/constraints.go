@@ -0,0 +1,89 @@
+package errors
+
+import "sync"
+
+// ConstraintMapping is the error reported when a database constraint is violated
+type ConstraintMapping struct {
+	Code    ErrorCode
+	Message string
+}
+
+var (
+	constraintsMu sync.RWMutex
+	constraints   = make(map[string]ConstraintMapping)
+)
+
+func init() {
+	registerDefaultConstraints()
+}
+
+// RegisterConstraint maps a database constraint name to a precise error code and message
+// Violations of unregistered constraints get the generic code of their violation type
+func RegisterConstraint(constraint string, code ErrorCode, message string) {
+	constraintsMu.Lock()
+	defer constraintsMu.Unlock()
+	constraints[constraint] = ConstraintMapping{Code: code, Message: message}
+}
+
+// LookupConstraint returns the mapping registered for a constraint name
+func LookupConstraint(constraint string) (ConstraintMapping, bool) {
+	constraintsMu.RLock()
+	defer constraintsMu.RUnlock()
+	mapping, ok := constraints[constraint]
+	return mapping, ok
+}
+
+// ConstraintError builds the error for a violated database constraint
+// Registered constraints get their own code and message, others get fallback and message.
+// cause may be nil for stores that enforce the constraints themselves.
+func ConstraintError(cause error, constraint, column string, fallback ErrorCode, message string) *AppError {
+	code := fallback
+	if mapping, ok := LookupConstraint(constraint); ok {
+		code, message = mapping.Code, mapping.Message
+	}
+
+	return &AppError{
+		Code:       code,
+		Message:    message,
+		HTTPStatus: GetDefaultRegistry().GetHTTPStatus(code),
+		Err:        cause,
+		Stack:      captureStack(2),
+		Constraint: constraint,
+		Column:     column,
+	}
+}
+
+// registerDefaultConstraints maps the constraints of the application schema
+func registerDefaultConstraints() {
+	// Unique keys
+	RegisterConstraint("products_pkey", CodeProductAlreadyExists, "Product already exists")
+	RegisterConstraint("inventory_product_id_key", CodeInventoryExists, "Inventory already exists for this product")
+	RegisterConstraint("inventory_tenant_id_product_id_key", CodeInventoryExists, "Inventory already exists for this product")
+
+	// Foreign keys to products
+	for _, constraint := range []string{
+		"fk_product",
+		"fk_stocktake_product",
+		"fk_inventory_valuations_product",
+		"fk_product_catalog_view_product",
+	} {
+		RegisterConstraint(constraint, CodeProductNotFound, "Product not found")
+	}
+	RegisterConstraint("fk_stocktake", CodeStocktakeNotFound, "Stocktake not found")
+
+	// Check constraints
+	RegisterConstraint("check_reserved_lte_quantity", CodeInsufficientStock, "Insufficient stock available")
+	RegisterConstraint("check_quantity_positive", CodeInsufficientStock, "Stock quantity cannot become negative")
+	RegisterConstraint("check_reserved_positive", CodeInvalidQuantity, "Reserved quantity cannot be negative")
+	RegisterConstraint("check_backordered_positive", CodeInvalidQuantity, "Backordered quantity cannot be negative")
+	RegisterConstraint("check_counted_quantity_positive", CodeInvalidQuantity, "Counted quantity cannot be negative")
+	RegisterConstraint("check_valuation_quantity_positive", CodeInvalidQuantity, "Valued quantity cannot be negative")
+	RegisterConstraint("check_layer_remaining", CodeInvalidQuantity, "Cost layer remaining quantity is out of range")
+	RegisterConstraint("check_valuation_total_cost_positive", CodeInvalidInput, "Total cost cannot be negative")
+	RegisterConstraint("check_layer_unit_cost_positive", CodeInvalidInput, "Unit cost cannot be negative")
+	RegisterConstraint("check_stock_policy", CodeInvalidStockPolicy, "Invalid stock policy")
+	RegisterConstraint("check_backorder_limit_positive", CodeInvalidStockPolicy, "Backorder limit cannot be negative")
+	RegisterConstraint("check_costing_method", CodeInvalidCostingMethod, "Invalid costing method")
+	RegisterConstraint("check_stocktake_status", CodeInvalidInput, "Invalid stocktake status")
+	RegisterConstraint("products_price_amount_check", CodeInvalidPrice, "Price cannot be negative")
+}
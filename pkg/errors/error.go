@@ -1,6 +1,7 @@
 package errors
 
 import (
+	"errors"
 	"fmt"
 	"runtime"
 	"strings"
@@ -13,6 +14,9 @@ type AppError struct {
 	HTTPStatus int
 	Err        error
 	Stack      []Frame
+	// Constraint and Column name what the database rejected, when it reported them
+	Constraint string
+	Column     string
 }
 
 // Frame represents a single stack frame
@@ -213,6 +217,26 @@ func GetMessage(err error) string {
 	return err.Error()
 }
 
+// GetConstraint returns the database constraint reported anywhere in the error chain
+func GetConstraint(err error) string {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if appErr, ok := err.(*AppError); ok && appErr.Constraint != "" {
+			return appErr.Constraint
+		}
+	}
+	return ""
+}
+
+// GetColumn returns the database column reported anywhere in the error chain
+func GetColumn(err error) string {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if appErr, ok := err.(*AppError); ok && appErr.Column != "" {
+			return appErr.Column
+		}
+	}
+	return ""
+}
+
 // StackString returns a formatted stack trace string
 func (e *AppError) StackString() string {
 	var builder strings.Builder
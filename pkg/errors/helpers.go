@@ -1,32 +1,65 @@
 package errors
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
+	"net"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// PostgreSQL SQLSTATE codes classified by WrapDatabaseError
+const (
+	sqlStateNotNullViolation     = "23502"
+	sqlStateForeignKeyViolation  = "23503"
+	sqlStateUniqueViolation      = "23505"
+	sqlStateCheckViolation       = "23514"
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+	sqlStateTooManyConnections   = "53300"
+	sqlStateQueryCanceled        = "57014"
+	sqlStateConnectionException  = "08"  // class
+	sqlStateOperatorIntervention = "57P" // admin/crash shutdown, cannot connect now
+
+	// statementTimeoutMessage is what PostgreSQL reports when statement_timeout cancels a query
+	statementTimeoutMessage = "canceling statement due to statement timeout"
 )
 
 // WrapDatabaseError wraps database errors with appropriate error codes
+// PostgreSQL errors are classified by their SQLSTATE code; violated constraints are
+// mapped through RegisterConstraint and attached to the AppError with their column.
+// Expired deadlines and statement_timeout become CodeQueryTimeout.
 func WrapDatabaseError(err error) error {
 	if err == nil {
 		return nil
 	}
 
+	// Errors already classified by a repository keep their code
+	if _, ok := err.(*AppError); ok {
+		return err
+	}
+
 	// Check for common database errors
 	if errors.Is(err, sql.ErrNoRows) {
 		return WithCode(err, CodeNotFound)
 	}
 
-	// Check for constraint violations (PostgreSQL specific patterns)
-	errStr := err.Error()
-	if containsAny(errStr, "duplicate key", "unique constraint", "violates unique constraint") {
-		return Wrap(err, CodeConflict, "Resource already exists")
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return wrapPostgresError(err, pqErr)
 	}
 
-	if containsAny(errStr, "foreign key constraint", "violates foreign key constraint") {
-		return Wrap(err, CodeInvalidInput, "Referenced resource does not exist")
+	if errors.Is(err, context.DeadlineExceeded) {
+		return Wrap(err, CodeQueryTimeout, "Database query timed out")
+	}
+	if errors.Is(err, context.Canceled) {
+		return Wrap(err, CodeQueryCanceled, "Database query was canceled")
 	}
 
-	if containsAny(errStr, "connection", "network", "timeout", "dial tcp") {
+	if IsConnectionError(err) {
 		return Wrap(err, CodeDatabaseConnection, "Database connection error")
 	}
 
@@ -34,25 +67,59 @@ func WrapDatabaseError(err error) error {
 	return Wrap(err, CodeDatabaseError, "Database operation failed")
 }
 
-// WrapValidationError wraps validation errors
-func WrapValidationError(err error) error {
-	if err == nil {
-		return nil
+// wrapPostgresError classifies a PostgreSQL error by its SQLSTATE code
+func wrapPostgresError(err error, pqErr *pq.Error) *AppError {
+	switch string(pqErr.Code) {
+	case sqlStateUniqueViolation:
+		return ConstraintError(err, pqErr.Constraint, pqErr.Column, CodeConflict, "Resource already exists")
+	case sqlStateForeignKeyViolation:
+		return ConstraintError(err, pqErr.Constraint, pqErr.Column, CodeInvalidInput, "Referenced resource does not exist")
+	case sqlStateCheckViolation:
+		return ConstraintError(err, pqErr.Constraint, pqErr.Column, CodeInvalidInput, "Value violates a data rule")
+	case sqlStateNotNullViolation:
+		return ConstraintError(err, pqErr.Constraint, pqErr.Column, CodeInvalidInput, "Required value is missing")
+	case sqlStateSerializationFailure, sqlStateDeadlockDetected:
+		return Wrap(err, CodeConcurrencyConflict, "Transaction conflicted with a concurrent update")
+	case sqlStateQueryCanceled:
+		// statement_timeout and cancel requests share a SQLSTATE; only the message tells them apart
+		if pqErr.Message == statementTimeoutMessage {
+			return Wrap(err, CodeQueryTimeout, "Database query timed out")
+		}
+		return Wrap(err, CodeQueryCanceled, "Database query was canceled")
 	}
-	return Wrap(err, CodeValidation, "Validation failed")
+
+	if IsConnectionError(err) {
+		return Wrap(err, CodeDatabaseConnection, "Database connection error")
+	}
+	return Wrap(err, CodeDatabaseError, "Database operation failed")
 }
 
-// containsAny checks if a string contains any of the given substrings
-func containsAny(s string, substrings ...string) bool {
-	for _, substr := range substrings {
-		if len(s) >= len(substr) {
-			for i := 0; i <= len(s)-len(substr); i++ {
-				if s[i:i+len(substr)] == substr {
-					return true
-				}
-			}
-		}
+// IsConnectionError reports whether err means the database could not be reached,
+// as opposed to a statement that was rejected
+func IsConnectionError(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		code := string(pqErr.Code)
+		return strings.HasPrefix(code, sqlStateConnectionException) ||
+			strings.HasPrefix(code, sqlStateOperatorIntervention) ||
+			code == sqlStateTooManyConnections
 	}
 	return false
 }
 
+// WrapValidationError wraps validation errors
+func WrapValidationError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return Wrap(err, CodeValidation, "Validation failed")
+}
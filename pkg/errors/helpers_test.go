@@ -1,10 +1,13 @@
 package errors
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"testing"
 
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -18,29 +21,57 @@ func TestWrapDatabaseError(t *testing.T) {
 	assert.NotNil(t, wrapped)
 	assert.True(t, Is(wrapped, CodeNotFound))
 
-	// Test duplicate key error
-	dupErr := errors.New("duplicate key value violates unique constraint")
+	// Test unique violation of a registered constraint
+	dupErr := &pq.Error{Code: "23505", Constraint: "products_pkey", Message: "duplicate key value violates unique constraint"}
 	wrapped = WrapDatabaseError(dupErr)
-	assert.NotNil(t, wrapped)
+	assert.True(t, Is(wrapped, CodeProductAlreadyExists))
+	assert.Equal(t, "products_pkey", GetConstraint(wrapped))
+
+	// Test unique violation of an unregistered constraint
+	wrapped = WrapDatabaseError(&pq.Error{Code: "23505", Constraint: "unknown_key"})
 	assert.True(t, Is(wrapped, CodeConflict))
 
-	// Test foreign key error
-	fkErr := errors.New("violates foreign key constraint")
-	wrapped = WrapDatabaseError(fkErr)
-	assert.NotNil(t, wrapped)
-	assert.True(t, Is(wrapped, CodeInvalidInput))
+	// Test foreign key violation
+	wrapped = WrapDatabaseError(&pq.Error{Code: "23503", Constraint: "fk_product"})
+	assert.True(t, Is(wrapped, CodeProductNotFound))
+
+	// Test check violation keeps the column
+	wrapped = WrapDatabaseError(&pq.Error{Code: "23514", Constraint: "check_reserved_lte_quantity", Column: "reserved_quantity"})
+	assert.True(t, Is(wrapped, CodeInsufficientStock))
+	assert.Equal(t, "reserved_quantity", GetColumn(wrapped))
+
+	// Test serialization failure and deadlock
+	assert.True(t, Is(WrapDatabaseError(&pq.Error{Code: "40001"}), CodeConcurrencyConflict))
+	assert.True(t, Is(WrapDatabaseError(&pq.Error{Code: "40P01"}), CodeConcurrencyConflict))
+
+	// Test statement cancellation
+	assert.True(t, Is(WrapDatabaseError(&pq.Error{Code: "57014"}), CodeQueryCanceled))
+	assert.True(t, Is(WrapDatabaseError(context.Canceled), CodeQueryCanceled))
+
+	// Test timeouts get their own code
+	timeoutErr := &pq.Error{Code: "57014", Message: "canceling statement due to statement timeout"}
+	assert.True(t, Is(WrapDatabaseError(timeoutErr), CodeQueryTimeout))
+	assert.True(t, Is(WrapDatabaseError(context.DeadlineExceeded), CodeQueryTimeout))
 
 	// Test connection error
-	connErr := errors.New("connection timeout")
-	wrapped = WrapDatabaseError(connErr)
-	assert.NotNil(t, wrapped)
+	wrapped = WrapDatabaseError(&pq.Error{Code: "08006"})
 	assert.True(t, Is(wrapped, CodeDatabaseConnection))
+	assert.True(t, Is(WrapDatabaseError(driver.ErrBadConn), CodeDatabaseConnection))
+
+	// Test messages that merely mention a connection are not misclassified
+	wrapped = WrapDatabaseError(errors.New("connection timeout column is invalid"))
+	assert.True(t, Is(wrapped, CodeDatabaseError))
 
 	// Test generic database error
 	genericErr := errors.New("some database error")
 	wrapped = WrapDatabaseError(genericErr)
 	assert.NotNil(t, wrapped)
 	assert.True(t, Is(wrapped, CodeDatabaseError))
+
+	// Test already classified error keeps its code
+	conflictErr := New(CodeConcurrencyConflict, "modified concurrently")
+	wrapped = WrapDatabaseError(conflictErr)
+	assert.True(t, Is(wrapped, CodeConcurrencyConflict))
 }
 
 func TestWrapValidationError(t *testing.T) {